@@ -327,6 +327,8 @@ func getEventDisplay(e rpc.MutationEvent) (symbol, message string) {
 		return "◉", fmt.Sprintf("%s SQUASHED%s", e.IssueID, context)
 	case rpc.MutationBurned:
 		return "🔥", fmt.Sprintf("%s burned%s", e.IssueID, context)
+	case rpc.MutationReaped:
+		return "✗", fmt.Sprintf("%s reaped (no heartbeat)%s", e.IssueID, context)
 	case rpc.MutationStatus:
 		// Status change with transition info
 		if e.NewStatus == "in_progress" {
@@ -389,7 +391,7 @@ func printEvent(e rpc.MutationEvent) {
 		coloredSymbol = ui.RenderPass(symbol)
 	case rpc.MutationUpdate:
 		coloredSymbol = ui.RenderWarn(symbol)
-	case rpc.MutationDelete, rpc.MutationBurned:
+	case rpc.MutationDelete, rpc.MutationBurned, rpc.MutationReaped:
 		coloredSymbol = ui.RenderFail(symbol)
 	case rpc.MutationComment:
 		coloredSymbol = ui.RenderAccent(symbol)
@@ -46,7 +46,12 @@ States:
 Examples:
   bd agent state gt-emma running     # Set emma's state to running
   bd agent heartbeat gt-emma         # Update emma's last_activity timestamp
-  bd agent show gt-emma              # Show emma's agent details`,
+  bd agent show gt-emma              # Show emma's agent details
+
+In jj repositories, agents can also work on their own bookmarks:
+  bd agent spawn gt-emma --hook bd-123   # Bookmark agent-gt-emma, hooked on bd-123
+  bd agent complete gt-emma              # Merge into main and close bd-123
+  bd agent list                          # Agents with state and bookmark`,
 }
 
 var agentStateCmd = &cobra.Command{
@@ -0,0 +1,626 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/turso/agent"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/utils"
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+// AgentListEntry is one row of 'bd agent list': an agent bead, its jj
+// bookmark, or both.
+type AgentListEntry struct {
+	Agent        string `json:"agent,omitempty"`
+	Title        string `json:"title,omitempty"`
+	State        string `json:"agent_state,omitempty"`
+	HookBead     string `json:"hook_bead,omitempty"`
+	LastActivity string `json:"last_activity,omitempty"`
+	Bookmark     string `json:"bookmark,omitempty"`
+	BookmarkID   string `json:"bookmark_change,omitempty"`
+	Archived     bool   `json:"archived,omitempty"`
+}
+
+var agentSpawnCmd = &cobra.Command{
+	Use:   "spawn <agent>",
+	Short: "Create a jj bookmark for an agent and hook its work",
+	Long: `Start a new change on top of the base bookmark and create the agent's
+bookmark (agent-<agent>) on it, following docs/jj-agent-conventions.md.
+
+With --hook, the issue is set as the agent's hook_bead, marked hooked and
+assigned to the agent. The agent's state becomes spawning.
+
+Requires a jj repository (colocated with git is fine).
+
+Examples:
+  bd agent spawn gt-emma --hook bd-123         # agent-gt-emma from main, hooked on bd-123
+  bd agent spawn gt-emma --base staging        # Spawn from the staging bookmark`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentSpawn,
+}
+
+var agentHandoffCmd = &cobra.Command{
+	Use:   "handoff <from-agent> <to-agent>",
+	Short: "Hand an agent's bookmark and hooked work to another agent",
+	Long: `Fork the new agent's bookmark from the current agent's bookmark and move
+the hooked issue to the new agent. The old agent is marked stopped.
+
+Examples:
+  bd agent handoff gt-emma gt-max --reason "context limit"
+  bd agent handoff gt-emma gt-max --archive   # Keep archive/agent-gt-emma`,
+	Args: cobra.ExactArgs(2),
+	RunE: runAgentHandoff,
+}
+
+var agentCompleteCmd = &cobra.Command{
+	Use:   "complete <agent>",
+	Short: "Merge an agent's bookmark and close its hooked issue",
+	Long: `Rebase the agent's bookmark onto the target bookmark, move the target
+forward to include the work, and remove (or archive) the agent bookmark.
+
+The agent's hooked issue is closed, its hook is cleared and its state
+becomes done.
+
+Examples:
+  bd agent complete gt-emma                    # Merge into main
+  bd agent complete gt-emma --archive          # Keep archive/agent-gt-emma
+  bd agent complete gt-emma --target staging   # Merge into staging`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentComplete,
+}
+
+var agentRecoverCmd = &cobra.Command{
+	Use:   "recover <agent>",
+	Short: "Restore a crashed agent's change from the jj operation log",
+	Long: `Search the jj operation log for the last operation involving the agent's
+bookmark and recreate the bookmark at the change it pointed to then.
+
+If the agent's bookmark is gone it is recreated under its own name;
+otherwise the recovered change gets agent-<agent>-recovered. Use --to to
+pick the name and --op to recover from a specific operation.
+
+Examples:
+  bd agent recover gt-emma
+  bd agent recover gt-emma --op 3f2a1b --to gt-emma-2`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentRecover,
+}
+
+var agentListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List agent beads with their jj bookmark status",
+	Long: `List agent beads (gt:agent) with state, hook and last activity, next to
+the agent's jj bookmark. Agent bookmarks without a bead are listed too.
+
+Archived bookmarks (archive/agent-*) are shown with --all.
+
+Examples:
+  bd agent list
+  bd agent list --all --json`,
+	Args: cobra.NoArgs,
+	RunE: runAgentList,
+}
+
+func init() {
+	agentSpawnCmd.Flags().String("hook", "", "Issue to hook the agent on")
+	agentSpawnCmd.Flags().String("base", agent.MainBookmark, "Bookmark to spawn from")
+	agentHandoffCmd.Flags().String("reason", "", "Why the work is being handed off")
+	agentHandoffCmd.Flags().Bool("archive", false, "Archive the old agent's bookmark instead of leaving it")
+	agentCompleteCmd.Flags().String("target", agent.MainBookmark, "Bookmark to merge the agent's work into")
+	agentCompleteCmd.Flags().Bool("archive", false, "Archive the agent bookmark instead of deleting it")
+	agentCompleteCmd.Flags().Bool("keep-bookmark", false, "Leave the agent bookmark in place")
+	agentCompleteCmd.Flags().String("reason", "", "Close reason for the hooked issue")
+	agentRecoverCmd.Flags().String("to", "", "Name for the recovered bookmark (default: reuse the agent's if it is gone)")
+	agentRecoverCmd.Flags().String("op", "", "jj operation ID to recover from (default: last operation involving the agent)")
+	agentListCmd.Flags().Bool("all", false, "Include archived agent bookmarks")
+
+	agentCmd.AddCommand(agentSpawnCmd)
+	agentCmd.AddCommand(agentHandoffCmd)
+	agentCmd.AddCommand(agentCompleteCmd)
+	agentCmd.AddCommand(agentRecoverCmd)
+	agentCmd.AddCommand(agentListCmd)
+}
+
+// openAgentVCS opens the repository for agent bookmark commands. Agent
+// bookmarks are a jj feature, so git-only repositories are rejected.
+func openAgentVCS() (vcs.VCS, error) {
+	v, err := vcs.NewFactory(vcs.WithCache(false)).Create(".")
+	if err != nil {
+		return nil, fmt.Errorf("not in a jj repository: %w", err)
+	}
+	if v.Name() == vcs.TypeGit {
+		return nil, fmt.Errorf("agent bookmarks require jj; run 'jj git init --colocate' to use jj in this git repository")
+	}
+	return v, nil
+}
+
+// loadAgentBead resolves and loads an agent bead, checking the gt:agent label.
+func loadAgentBead(ctx context.Context, s storage.Storage, arg string) (*types.Issue, error) {
+	id, err := utils.ResolvePartialID(ctx, s, arg)
+	if err != nil {
+		return nil, fmt.Errorf("agent '%s' not found: %w", arg, err)
+	}
+	issue, err := s.GetIssue(ctx, id)
+	if err != nil || issue == nil {
+		return nil, fmt.Errorf("agent bead not found: %s", id)
+	}
+	labels, _ := s.GetLabels(ctx, id)
+	if !isAgentBead(labels) {
+		return nil, fmt.Errorf("%s is not an agent bead (missing gt:agent label)", id)
+	}
+	return issue, nil
+}
+
+// listAgentBeads returns every agent bead that is not a tombstone, with its
+// agent fields loaded. Closed agents are included; callers that only want
+// live agents skip them.
+func listAgentBeads(ctx context.Context, s storage.Storage) ([]*types.Issue, error) {
+	found, err := s.SearchIssues(ctx, "", types.IssueFilter{Labels: []string{"gt:agent"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	agents := make([]*types.Issue, 0, len(found))
+	for _, f := range found {
+		if f.Status == types.StatusTombstone {
+			continue
+		}
+		// Search results do not carry the agent fields; load the full bead
+		issue, err := s.GetIssue(ctx, f.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get agent %s: %w", f.ID, err)
+		}
+		if issue != nil {
+			agents = append(agents, issue)
+		}
+	}
+	return agents, nil
+}
+
+func runAgentSpawn(cmd *cobra.Command, args []string) error {
+	CheckReadonly("agent spawn")
+	if err := ensureDirectMode("agent spawn runs against the database directly"); err != nil {
+		return err
+	}
+	hookArg, _ := cmd.Flags().GetString("hook")
+	base, _ := cmd.Flags().GetString("base")
+
+	ctx := rootCtx
+	agentBead, err := loadAgentBead(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+
+	var hooked *types.Issue
+	if hookArg != "" {
+		if agentBead.HookBead != "" {
+			return fmt.Errorf("hook slot already occupied by %s; use 'bd slot clear %s hook' first", agentBead.HookBead, agentBead.ID)
+		}
+		hookID, err := utils.ResolvePartialID(ctx, store, hookArg)
+		if err != nil {
+			return fmt.Errorf("issue '%s' not found: %w", hookArg, err)
+		}
+		hooked, err = store.GetIssue(ctx, hookID)
+		if err != nil || hooked == nil {
+			return fmt.Errorf("issue not found: %s", hookID)
+		}
+		if hooked.Status == types.StatusClosed || hooked.Status == types.StatusTombstone {
+			return fmt.Errorf("cannot hook %s: issue is %s", hooked.ID, hooked.Status)
+		}
+		if err := checkClaimBudget(ctx, hooked.ID); err != nil {
+			return fmt.Errorf("cannot hook %s: %w", hooked.ID, err)
+		}
+	}
+
+	v, err := openAgentVCS()
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("%s: spawned", agentBead.ID)
+	if hooked != nil {
+		description = fmt.Sprintf("%s: %s", hooked.ID, hooked.Title)
+	}
+	spawned, err := agent.Spawn(ctx, v, agent.SpawnOptions{
+		AgentID:     agentBead.ID,
+		BaseBranch:  base,
+		Description: description,
+	})
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"agent_state":   string(types.StateSpawning),
+		"last_activity": time.Now(),
+	}
+	if hooked != nil {
+		updates["hook_bead"] = hooked.ID
+		if err := store.UpdateIssue(ctx, hooked.ID, map[string]interface{}{
+			"status":   string(types.StatusHooked),
+			"assignee": agentBead.ID,
+		}, actor); err != nil {
+			return fmt.Errorf("failed to hook %s: %w", hooked.ID, err)
+		}
+	}
+	if err := store.UpdateIssue(ctx, agentBead.ID, updates, actor); err != nil {
+		return fmt.Errorf("failed to update agent: %w", err)
+	}
+	markDirtyAndScheduleFlush()
+
+	hookID := ""
+	if hooked != nil {
+		hookID = hooked.ID
+	}
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"agent":       agentBead.ID,
+			"bookmark":    spawned.Bookmark,
+			"based_on":    spawned.BasedOn,
+			"hook_bead":   emptyToNil(hookID),
+			"agent_state": string(types.StateSpawning),
+		})
+		return nil
+	}
+
+	fmt.Printf("%s Spawned %s on bookmark %s (from %s)\n", ui.RenderPass("✓"), ui.RenderID(agentBead.ID), spawned.Bookmark, spawned.BasedOn)
+	if hookID != "" {
+		fmt.Printf("  hook: %s\n", hookID)
+	}
+	return nil
+}
+
+func runAgentHandoff(cmd *cobra.Command, args []string) error {
+	CheckReadonly("agent handoff")
+	if err := ensureDirectMode("agent handoff runs against the database directly"); err != nil {
+		return err
+	}
+	reason, _ := cmd.Flags().GetString("reason")
+	archive, _ := cmd.Flags().GetBool("archive")
+
+	ctx := rootCtx
+	from, err := loadAgentBead(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+	to, err := loadAgentBead(ctx, store, args[1])
+	if err != nil {
+		return err
+	}
+	if from.ID == to.ID {
+		return fmt.Errorf("cannot hand off %s to itself", from.ID)
+	}
+	if to.HookBead != "" && from.HookBead != "" {
+		return fmt.Errorf("%s is already hooked on %s", to.ID, to.HookBead)
+	}
+	if from.HookBead != "" {
+		// The new agent claims the hooked work
+		if err := checkClaimBudget(ctx, from.HookBead); err != nil {
+			return fmt.Errorf("cannot hand off %s: %w", from.HookBead, err)
+		}
+	}
+
+	v, err := openAgentVCS()
+	if err != nil {
+		return err
+	}
+
+	if reason == "" {
+		reason = "handoff"
+	}
+	handed, err := agent.Handoff(ctx, v, agent.HandoffOptions{
+		FromAgentID: from.ID,
+		ToAgentID:   to.ID,
+		Reason:      reason,
+		ArchiveOld:  archive,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	toUpdates := map[string]interface{}{
+		"agent_state":   string(types.StateSpawning),
+		"last_activity": now,
+	}
+	if from.HookBead != "" {
+		toUpdates["hook_bead"] = from.HookBead
+		hooked, err := store.GetIssue(ctx, from.HookBead)
+		if err != nil {
+			return fmt.Errorf("failed to get hooked bead %s: %w", from.HookBead, err)
+		}
+		if hooked != nil && hooked.Assignee == from.ID {
+			if err := store.UpdateIssue(ctx, hooked.ID, map[string]interface{}{"assignee": to.ID}, actor); err != nil {
+				return fmt.Errorf("failed to reassign %s: %w", hooked.ID, err)
+			}
+		}
+		if hooked != nil {
+			if _, err := store.AddIssueComment(ctx, hooked.ID, actor, fmt.Sprintf("Handed off from %s to %s: %s", from.ID, to.ID, reason)); err != nil {
+				return fmt.Errorf("failed to comment on %s: %w", hooked.ID, err)
+			}
+		}
+	}
+	if err := store.UpdateIssue(ctx, to.ID, toUpdates, actor); err != nil {
+		return fmt.Errorf("failed to update %s: %w", to.ID, err)
+	}
+	if err := store.UpdateIssue(ctx, from.ID, map[string]interface{}{
+		"agent_state":   string(types.StateStopped),
+		"hook_bead":     "",
+		"last_activity": now,
+	}, actor); err != nil {
+		return fmt.Errorf("failed to update %s: %w", from.ID, err)
+	}
+	markDirtyAndScheduleFlush()
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"from":      from.ID,
+			"to":        to.ID,
+			"bookmark":  handed.Bookmark,
+			"based_on":  handed.BasedOn,
+			"hook_bead": emptyToNil(from.HookBead),
+			"archived":  archive,
+		})
+		return nil
+	}
+
+	fmt.Printf("%s Handed off %s → %s on bookmark %s\n", ui.RenderPass("✓"), ui.RenderID(from.ID), ui.RenderID(to.ID), handed.Bookmark)
+	if from.HookBead != "" {
+		fmt.Printf("  hook: %s\n", from.HookBead)
+	}
+	return nil
+}
+
+func runAgentComplete(cmd *cobra.Command, args []string) error {
+	CheckReadonly("agent complete")
+	if err := ensureDirectMode("agent complete runs against the database directly"); err != nil {
+		return err
+	}
+	target, _ := cmd.Flags().GetString("target")
+	archive, _ := cmd.Flags().GetBool("archive")
+	keep, _ := cmd.Flags().GetBool("keep-bookmark")
+	reason, _ := cmd.Flags().GetString("reason")
+
+	ctx := rootCtx
+	agentBead, err := loadAgentBead(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+
+	v, err := openAgentVCS()
+	if err != nil {
+		return err
+	}
+
+	bookmark := agent.BookmarkName(agentBead.ID)
+	if err := agent.Complete(ctx, v, agent.CompleteOptions{
+		AgentID:         agentBead.ID,
+		TargetBookmark:  target,
+		DeleteBookmark:  !keep,
+		ArchiveBookmark: archive,
+	}); err != nil {
+		return err
+	}
+
+	var closed string
+	if agentBead.HookBead != "" {
+		hooked, err := store.GetIssue(ctx, agentBead.HookBead)
+		if err != nil {
+			return fmt.Errorf("failed to get hooked bead %s: %w", agentBead.HookBead, err)
+		}
+		if hooked != nil && hooked.Status != types.StatusClosed && hooked.Status != types.StatusTombstone {
+			if reason == "" {
+				reason = fmt.Sprintf("Completed by %s (merged %s into %s)", agentBead.ID, bookmark, target)
+			}
+			if err := store.CloseIssue(ctx, hooked.ID, reason, actor, ""); err != nil {
+				return fmt.Errorf("failed to close %s: %w", hooked.ID, err)
+			}
+			closed = hooked.ID
+		}
+	}
+	if err := store.UpdateIssue(ctx, agentBead.ID, map[string]interface{}{
+		"agent_state":   string(types.StateDone),
+		"hook_bead":     "",
+		"last_activity": time.Now(),
+	}, actor); err != nil {
+		return fmt.Errorf("failed to update agent: %w", err)
+	}
+	markDirtyAndScheduleFlush()
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"agent":    agentBead.ID,
+			"bookmark": bookmark,
+			"target":   target,
+			"closed":   emptyToNil(closed),
+			"archived": archive,
+		})
+		return nil
+	}
+
+	fmt.Printf("%s Merged %s into %s\n", ui.RenderPass("✓"), bookmark, target)
+	if closed != "" {
+		fmt.Printf("  closed: %s\n", closed)
+	}
+	return nil
+}
+
+func runAgentRecover(cmd *cobra.Command, args []string) error {
+	CheckReadonly("agent recover")
+	if err := ensureDirectMode("agent recover runs against the database directly"); err != nil {
+		return err
+	}
+	to, _ := cmd.Flags().GetString("to")
+	opID, _ := cmd.Flags().GetString("op")
+
+	ctx := rootCtx
+	agentBead, err := loadAgentBead(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+
+	v, err := openAgentVCS()
+	if err != nil {
+		return err
+	}
+
+	// A crashed agent whose bookmark is gone gets it back under its own name
+	bookmark := agent.BookmarkName(agentBead.ID)
+	if to == "" && !v.RefExists(bookmark) {
+		to = bookmark
+	}
+
+	recovered, err := agent.Recover(ctx, v, agent.RecoverOptions{
+		AgentID:     agentBead.ID,
+		RecoverToID: to,
+		OperationID: opID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := store.AddComment(ctx, agentBead.ID, actor, fmt.Sprintf("Recovered bookmark %s from jj operation %s", recovered.Bookmark, recovered.RecoveredFrom)); err != nil {
+		return fmt.Errorf("failed to comment on %s: %w", agentBead.ID, err)
+	}
+	markDirtyAndScheduleFlush()
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"agent":     agentBead.ID,
+			"bookmark":  recovered.Bookmark,
+			"operation": recovered.RecoveredFrom,
+		})
+		return nil
+	}
+
+	fmt.Printf("%s Recovered %s as bookmark %s (from operation %s)\n", ui.RenderPass("✓"), ui.RenderID(agentBead.ID), recovered.Bookmark, recovered.RecoveredFrom)
+	fmt.Printf("  Resume with: jj new %s\n", recovered.Bookmark)
+	return nil
+}
+
+func runAgentList(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	if err := ensureDirectMode("agent list runs against the database directly"); err != nil {
+		return err
+	}
+
+	ctx := rootCtx
+	var bookmarks []agent.BookmarkStatus
+	if v, err := openAgentVCS(); err == nil {
+		bookmarks, err = agent.List(ctx, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries, err := buildAgentList(ctx, store, bookmarks, all)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		outputJSON(entries)
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No agents found")
+		return nil
+	}
+	for _, e := range entries {
+		name := e.Agent
+		if name == "" {
+			name = "(no bead)"
+		}
+		state := e.State
+		if state == "" && e.Agent != "" {
+			state = "-"
+		}
+		bookmark := "no bookmark"
+		if e.Bookmark != "" {
+			bookmark = e.Bookmark
+			if e.BookmarkID != "" {
+				bookmark += " @ " + e.BookmarkID
+			}
+			if e.Archived {
+				bookmark += " (archived)"
+			}
+		}
+		line := fmt.Sprintf("%-20s %-9s %s", ui.RenderID(name), state, bookmark)
+		if e.HookBead != "" {
+			line += "  hook: " + e.HookBead
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// buildAgentList pairs agent beads with their bookmarks. Bookmarks that do
+// not belong to an agent bead get an entry of their own; archived bookmarks
+// are included only when all is set.
+func buildAgentList(ctx context.Context, s storage.Storage, bookmarks []agent.BookmarkStatus, all bool) ([]AgentListEntry, error) {
+	byName := make(map[string]agent.BookmarkStatus, len(bookmarks))
+	for _, b := range bookmarks {
+		byName[b.Bookmark] = b
+	}
+
+	agents, err := listAgentBeads(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []AgentListEntry{}
+	used := make(map[string]bool)
+	for _, a := range agents {
+		entry := AgentListEntry{
+			Agent:    a.ID,
+			Title:    a.Title,
+			State:    string(a.AgentState),
+			HookBead: a.HookBead,
+		}
+		if a.LastActivity != nil {
+			entry.LastActivity = a.LastActivity.Format(time.RFC3339)
+		}
+		name := agent.BookmarkName(a.ID)
+		if b, ok := byName[name]; ok {
+			entry.Bookmark = b.Bookmark
+			entry.BookmarkID = b.ChangeID
+			used[name] = true
+		} else if b, ok := byName[agent.ArchiveBookmarkPrefix+name]; ok && all {
+			entry.Bookmark = b.Bookmark
+			entry.BookmarkID = b.ChangeID
+			entry.Archived = true
+			used[b.Bookmark] = true
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, b := range bookmarks {
+		if used[b.Bookmark] || (b.IsArchived && !all) {
+			continue
+		}
+		entries = append(entries, AgentListEntry{
+			Bookmark:   b.Bookmark,
+			BookmarkID: b.ChangeID,
+			Archived:   b.IsArchived,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		ki, kj := entries[i].Agent, entries[j].Agent
+		if ki == "" {
+			ki = entries[i].Bookmark
+		}
+		if kj == "" {
+			kj = entries[j].Bookmark
+		}
+		return ki < kj
+	})
+	return entries, nil
+}
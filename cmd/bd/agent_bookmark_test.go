@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/audit"
+	"github.com/steveyegge/beads/internal/turso/agent"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/vcs"
+	"github.com/steveyegge/beads/internal/vcs/jj"
+)
+
+func TestBuildAgentList(t *testing.T) {
+	tmpDir := t.TempDir()
+	s := newTestStore(t, filepath.Join(tmpDir, ".beads", "beads.db"))
+	ctx := context.Background()
+
+	newAgent := func(title string, state types.AgentState) *types.Issue {
+		t.Helper()
+		a := &types.Issue{Title: title, Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+		if err := s.CreateIssue(ctx, a, "test"); err != nil {
+			t.Fatalf("CreateIssue: %v", err)
+		}
+		if err := s.UpdateIssue(ctx, a.ID, map[string]interface{}{"agent_state": string(state)}, "test"); err != nil {
+			t.Fatalf("UpdateIssue: %v", err)
+		}
+		if err := s.AddLabel(ctx, a.ID, "gt:agent", "test"); err != nil {
+			t.Fatalf("AddLabel: %v", err)
+		}
+		return a
+	}
+	working := newAgent("working", types.StateWorking)
+	finished := newAgent("finished", types.StateDone)
+
+	bookmarks := []agent.BookmarkStatus{
+		{Bookmark: agent.BookmarkName(working.ID), ChangeID: "abc123", Exists: true},
+		{Bookmark: agent.ArchiveBookmarkPrefix + agent.BookmarkName(finished.ID), ChangeID: "def456", Exists: true, IsArchived: true},
+		{Bookmark: "agent-orphan", ChangeID: "fed987", Exists: true},
+	}
+
+	entries, err := buildAgentList(ctx, s, bookmarks, false)
+	if err != nil {
+		t.Fatalf("buildAgentList: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries = %+v, want 2 agents and the orphan bookmark", entries)
+	}
+	byAgent := make(map[string]AgentListEntry)
+	for _, e := range entries {
+		byAgent[e.Agent] = e
+	}
+	if e := byAgent[working.ID]; e.State != string(types.StateWorking) || e.BookmarkID != "abc123" {
+		t.Errorf("working agent = %+v, want state working at abc123", e)
+	}
+	if e := byAgent[finished.ID]; e.Bookmark != "" {
+		t.Errorf("finished agent = %+v, want archived bookmark hidden without --all", e)
+	}
+	if e, ok := byAgent[""]; !ok || e.Bookmark != "agent-orphan" {
+		t.Errorf("orphan entry = %+v, want agent-orphan", e)
+	}
+
+	entries, err = buildAgentList(ctx, s, bookmarks, true)
+	if err != nil {
+		t.Fatalf("buildAgentList --all: %v", err)
+	}
+	for _, e := range entries {
+		if e.Agent == finished.ID && (!e.Archived || e.BookmarkID != "def456") {
+			t.Errorf("finished agent with --all = %+v, want archived bookmark", e)
+		}
+	}
+}
+
+func TestAgentSpawnHandoffComplete(t *testing.T) {
+	if !vcs.IsJJAvailable() {
+		t.Skip("jj not available")
+	}
+	t.Setenv("JJ_USER", "Test Agent")
+	t.Setenv("JJ_EMAIL", "agent@example.com")
+
+	repoDir := t.TempDir()
+	repo, err := jj.Init(repoDir, false)
+	if err != nil {
+		t.Fatalf("jj init: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := repo.Exec(ctx, "commit", "-m", "initial"); err != nil {
+		t.Fatalf("jj commit: %v", err)
+	}
+	if err := repo.CreateRef(agent.MainBookmark, "@-"); err != nil {
+		t.Fatalf("CreateRef main: %v", err)
+	}
+	t.Chdir(repoDir)
+
+	s := newTestStore(t, filepath.Join(t.TempDir(), ".beads", "beads.db"))
+	oldStore, oldActive, oldActor, oldCtx := store, storeActive, actor, rootCtx
+	store, storeActive, actor, rootCtx = s, true, "tester", ctx
+	defer func() { store, storeActive, actor, rootCtx = oldStore, oldActive, oldActor, oldCtx }()
+
+	create := func(title string, labels ...string) *types.Issue {
+		t.Helper()
+		issue := &types.Issue{Title: title, Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+		if err := s.CreateIssue(ctx, issue, "test"); err != nil {
+			t.Fatalf("CreateIssue: %v", err)
+		}
+		for _, l := range labels {
+			if err := s.AddLabel(ctx, issue.ID, l, "test"); err != nil {
+				t.Fatalf("AddLabel: %v", err)
+			}
+		}
+		return issue
+	}
+	emma := create("emma", "gt:agent")
+	maxAgent := create("max", "gt:agent")
+	work := create("Fix the thing")
+	get := func(id string) *types.Issue {
+		t.Helper()
+		issue, err := s.GetIssue(ctx, id)
+		if err != nil || issue == nil {
+			t.Fatalf("GetIssue %s: %v", id, err)
+		}
+		return issue
+	}
+	setFlag := func(cmd *cobra.Command, name, value string) {
+		t.Helper()
+		if err := cmd.Flags().Set(name, value); err != nil {
+			t.Fatalf("set --%s: %v", name, err)
+		}
+		t.Cleanup(func() { _ = cmd.Flags().Set(name, cmd.Flags().Lookup(name).DefValue) })
+	}
+
+	setFlag(agentSpawnCmd, "hook", work.ID)
+	if err := runAgentSpawn(agentSpawnCmd, []string{emma.ID}); err != nil {
+		t.Fatalf("agent spawn: %v", err)
+	}
+	if a := get(emma.ID); a.AgentState != types.StateSpawning || a.HookBead != work.ID {
+		t.Errorf("after spawn: state=%q hook=%q, want spawning hooked on %s", a.AgentState, a.HookBead, work.ID)
+	}
+	if w := get(work.ID); w.Status != types.StatusHooked || w.Assignee != emma.ID {
+		t.Errorf("after spawn: work status=%s assignee=%q, want hooked to %s", w.Status, w.Assignee, emma.ID)
+	}
+	if !repo.RefExists(agent.BookmarkName(emma.ID)) {
+		t.Fatalf("bookmark %s not created", agent.BookmarkName(emma.ID))
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "fix.txt"), []byte("fixed\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	setFlag(agentHandoffCmd, "reason", "context limit")
+	if err := runAgentHandoff(agentHandoffCmd, []string{emma.ID, maxAgent.ID}); err != nil {
+		t.Fatalf("agent handoff: %v", err)
+	}
+	if a := get(emma.ID); a.AgentState != types.StateStopped || a.HookBead != "" {
+		t.Errorf("after handoff: %s state=%q hook=%q, want stopped and unhooked", emma.ID, a.AgentState, a.HookBead)
+	}
+	if a := get(maxAgent.ID); a.AgentState != types.StateSpawning || a.HookBead != work.ID {
+		t.Errorf("after handoff: %s state=%q hook=%q, want spawning hooked on %s", maxAgent.ID, a.AgentState, a.HookBead, work.ID)
+	}
+	if w := get(work.ID); w.Assignee != maxAgent.ID {
+		t.Errorf("after handoff: work assignee=%q, want %s", w.Assignee, maxAgent.ID)
+	}
+	if !repo.RefExists(agent.BookmarkName(maxAgent.ID)) {
+		t.Fatalf("bookmark %s not created", agent.BookmarkName(maxAgent.ID))
+	}
+
+	if err := runAgentComplete(agentCompleteCmd, []string{maxAgent.ID}); err != nil {
+		t.Fatalf("agent complete: %v", err)
+	}
+	if a := get(maxAgent.ID); a.AgentState != types.StateDone || a.HookBead != "" {
+		t.Errorf("after complete: state=%q hook=%q, want done and unhooked", a.AgentState, a.HookBead)
+	}
+	if w := get(work.ID); w.Status != types.StatusClosed {
+		t.Errorf("after complete: work status=%s, want closed", w.Status)
+	}
+	if repo.RefExists(agent.BookmarkName(maxAgent.ID)) {
+		t.Errorf("bookmark %s should be deleted after complete", agent.BookmarkName(maxAgent.ID))
+	}
+	files, err := repo.Exec(ctx, "file", "list", "-r", agent.MainBookmark)
+	if err != nil {
+		t.Fatalf("jj file list: %v", err)
+	}
+	if !strings.Contains(string(files), "fix.txt") {
+		t.Errorf("main files = %q, want the agent's fix.txt", files)
+	}
+}
+
+func TestAgentSpawn_BudgetBlocksHook(t *testing.T) {
+	beadsDir := filepath.Join(t.TempDir(), ".beads")
+	t.Setenv("BEADS_DIR", beadsDir)
+	s := newTestStore(t, filepath.Join(beadsDir, "beads.db"))
+	ctx := context.Background()
+	oldStore, oldActive, oldActor, oldCtx := store, storeActive, actor, rootCtx
+	store, storeActive, actor, rootCtx = s, true, "tester", ctx
+	defer func() { store, storeActive, actor, rootCtx = oldStore, oldActive, oldActor, oldCtx }()
+	setBudgetEnforcement(t, "block")
+
+	budget := 1.0
+	work := &types.Issue{Title: "Fix the thing", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask, BudgetUSD: &budget}
+	emma := &types.Issue{Title: "emma", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	for _, issue := range []*types.Issue{work, emma} {
+		if err := s.CreateIssue(ctx, issue, "test"); err != nil {
+			t.Fatalf("CreateIssue: %v", err)
+		}
+	}
+	if err := s.AddLabel(ctx, emma.ID, "gt:agent", "test"); err != nil {
+		t.Fatalf("AddLabel: %v", err)
+	}
+	if _, err := audit.Append(&audit.Entry{Kind: audit.KindLLMCall, IssueID: work.ID, CostUSD: 1.5}); err != nil {
+		t.Fatalf("audit.Append: %v", err)
+	}
+
+	if err := agentSpawnCmd.Flags().Set("hook", work.ID); err != nil {
+		t.Fatalf("set --hook: %v", err)
+	}
+	defer func() { _ = agentSpawnCmd.Flags().Set("hook", "") }()
+
+	// The budget is checked before any VCS work, so no repository is needed
+	err := runAgentSpawn(agentSpawnCmd, []string{emma.ID})
+	if err == nil || !strings.Contains(err.Error(), "budget exceeded") {
+		t.Fatalf("agent spawn over budget: err = %v, want budget exceeded", err)
+	}
+	if w, _ := s.GetIssue(ctx, work.ID); w.Status != types.StatusOpen || w.Assignee != "" {
+		t.Errorf("work status=%s assignee=%q, want open and unassigned", w.Status, w.Assignee)
+	}
+	if a, _ := s.GetIssue(ctx, emma.ID); a.HookBead != "" {
+		t.Errorf("agent hook_bead = %q, want empty", a.HookBead)
+	}
+}
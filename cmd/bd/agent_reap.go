@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/rpc"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// ReapCandidate is an agent whose heartbeat is older than the liveness policy
+// allows, with the work that would be returned to open.
+type ReapCandidate struct {
+	Agent        string    `json:"agent"`
+	State        string    `json:"state,omitempty"`
+	LastActivity time.Time `json:"last_activity"`
+	Silent       string    `json:"silent_for"`
+	Work         []string  `json:"work"` // Hooked or in_progress issues held by the agent
+}
+
+var agentReapCmd = &cobra.Command{
+	Use:   "reap",
+	Short: "Mark agents without a recent heartbeat dead and free their work",
+	Long: `Apply the agent liveness policy.
+
+An agent bead whose last_activity is older than agents.dead-after (or
+--dead-after) is moved to the dead state. Its hooked bead, and any
+in_progress or hooked issue assigned to it, goes back to open with a comment
+explaining why, and the agent's hook is cleared.
+
+Agents that never reported activity, and agents already dead or stopped, are
+left alone.
+
+The daemon applies the same policy every agents.reap-interval when
+agents.dead-after is set in .beads/config.yaml:
+
+  agents:
+    dead-after: 15m
+
+Examples:
+  bd agent reap --dry-run                 # Show which agents would be reaped
+  bd agent reap --dead-after 30m          # Reap agents silent for 30 minutes`,
+	Args: cobra.NoArgs,
+	RunE: runAgentReap,
+}
+
+func init() {
+	agentReapCmd.Flags().Bool("dry-run", false, "Report agents that would be reaped without changing anything")
+	agentReapCmd.Flags().Duration("dead-after", 0, "Heartbeat age after which an agent is dead (default: agents.dead-after)")
+	agentCmd.AddCommand(agentReapCmd)
+}
+
+func runAgentReap(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	deadAfter, _ := cmd.Flags().GetDuration("dead-after")
+	if !cmd.Flags().Changed("dead-after") {
+		deadAfter = config.GetDuration("agents.dead-after")
+	}
+	if deadAfter <= 0 {
+		return fmt.Errorf("no liveness policy: set agents.dead-after in config.yaml or pass --dead-after")
+	}
+	if !dryRun {
+		CheckReadonly("agent reap")
+	}
+	if err := ensureDirectMode("agent reap runs against the database directly"); err != nil {
+		return err
+	}
+
+	ctx := rootCtx
+	candidates, err := findDeadAgents(ctx, store, deadAfter, time.Now())
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		for _, c := range candidates {
+			if err := reapAgent(ctx, store, c, deadAfter, actor); err != nil {
+				return err
+			}
+		}
+		if len(candidates) > 0 {
+			markDirtyAndScheduleFlush()
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"dry_run":    dryRun,
+			"dead_after": deadAfter.String(),
+			"reaped":     candidates,
+		})
+		return nil
+	}
+
+	if len(candidates) == 0 {
+		fmt.Printf("No agents silent for more than %s\n", deadAfter)
+		return nil
+	}
+	verb := "Reaped"
+	if dryRun {
+		verb = "Would reap"
+	}
+	for _, c := range candidates {
+		fmt.Printf("%s %s %s (no heartbeat for %s)\n", ui.RenderFail("✗"), verb, ui.RenderID(c.Agent), c.Silent)
+		for _, id := range c.Work {
+			fmt.Printf("    %s → open\n", id)
+		}
+	}
+	return nil
+}
+
+// findDeadAgents returns the agents whose last heartbeat is older than
+// deadAfter, sorted by ID.
+func findDeadAgents(ctx context.Context, s storage.Storage, deadAfter time.Duration, now time.Time) ([]ReapCandidate, error) {
+	agents, err := listAgentBeads(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []ReapCandidate{}
+	for _, agent := range agents {
+		if agent.Status == types.StatusClosed {
+			continue
+		}
+		if agent.AgentState == types.StateDead || agent.AgentState == types.StateStopped {
+			continue
+		}
+		if agent.LastActivity == nil || now.Sub(*agent.LastActivity) <= deadAfter {
+			continue
+		}
+		work, err := agentHeldWork(ctx, s, agent)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, ReapCandidate{
+			Agent:        agent.ID,
+			State:        string(agent.AgentState),
+			LastActivity: *agent.LastActivity,
+			Silent:       now.Sub(*agent.LastActivity).Round(time.Second).String(),
+			Work:         work,
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Agent < candidates[j].Agent })
+	return candidates, nil
+}
+
+// agentHeldWork returns the agent's hooked bead plus the in_progress and
+// hooked issues assigned to it, skipping anything already closed.
+func agentHeldWork(ctx context.Context, s storage.Storage, agent *types.Issue) ([]string, error) {
+	seen := make(map[string]bool)
+	var work []string
+	if agent.HookBead != "" {
+		hooked, err := s.GetIssue(ctx, agent.HookBead)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hooked bead %s: %w", agent.HookBead, err)
+		}
+		if hooked != nil && hooked.Status != types.StatusClosed && hooked.Status != types.StatusTombstone {
+			seen[hooked.ID] = true
+			work = append(work, hooked.ID)
+		}
+	}
+	assignee := agent.ID
+	for _, status := range []types.Status{types.StatusInProgress, types.StatusHooked} {
+		issues, err := s.SearchIssues(ctx, "", types.IssueFilter{Assignee: &assignee, Status: &status})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list work for %s: %w", agent.ID, err)
+		}
+		for _, issue := range issues {
+			if !seen[issue.ID] {
+				seen[issue.ID] = true
+				work = append(work, issue.ID)
+			}
+		}
+	}
+	sort.Strings(work)
+	return work, nil
+}
+
+// reapAgent marks an agent dead, clears its hook and returns its work to open.
+// Each freed issue gets a comment naming the policy that triggered it, and the
+// agent gets a "Marked dead" event.
+func reapAgent(ctx context.Context, s storage.Storage, c ReapCandidate, deadAfter time.Duration, reaper string) error {
+	why := fmt.Sprintf("no heartbeat for %s (agents.dead-after=%s)", c.Silent, deadAfter)
+
+	for _, id := range c.Work {
+		issue, err := s.GetIssue(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", id, err)
+		}
+		if issue == nil {
+			continue
+		}
+		updates := map[string]interface{}{}
+		if issue.Status == types.StatusInProgress || issue.Status == types.StatusHooked {
+			updates["status"] = string(types.StatusOpen)
+		}
+		if issue.Assignee == c.Agent {
+			updates["assignee"] = ""
+		}
+		if len(updates) > 0 {
+			if err := s.UpdateIssue(ctx, id, updates, reaper); err != nil {
+				return fmt.Errorf("failed to return %s to open: %w", id, err)
+			}
+		}
+		if _, err := s.AddIssueComment(ctx, id, reaper, fmt.Sprintf("Returned to open: agent %s marked dead, %s", c.Agent, why)); err != nil {
+			return fmt.Errorf("failed to comment on %s: %w", id, err)
+		}
+	}
+
+	if err := s.UpdateIssue(ctx, c.Agent, map[string]interface{}{
+		"agent_state": string(types.StateDead),
+		"hook_bead":   "",
+	}, reaper); err != nil {
+		return fmt.Errorf("failed to mark %s dead: %w", c.Agent, err)
+	}
+	return s.AddComment(ctx, c.Agent, reaper, "Marked dead: "+why)
+}
+
+// startAgentReaper applies the liveness policy from the daemon every
+// agents.reap-interval. It does nothing unless agents.dead-after is set.
+func startAgentReaper(ctx context.Context, s storage.Storage, server *rpc.Server, log daemonLogger) {
+	deadAfter := config.GetDuration("agents.dead-after")
+	if deadAfter <= 0 {
+		return
+	}
+	interval := config.GetDuration("agents.reap-interval")
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	log.Info("agent reaper enabled", "dead_after", deadAfter, "interval", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reapDeadAgentsOnce(ctx, s, server, deadAfter, log)
+			}
+		}
+	}()
+}
+
+// reapDeadAgentsOnce runs one daemon reaper pass.
+func reapDeadAgentsOnce(ctx context.Context, s storage.Storage, server *rpc.Server, deadAfter time.Duration, log daemonLogger) {
+	candidates, err := findDeadAgents(ctx, s, deadAfter, time.Now())
+	if err != nil {
+		log.Warn("agent reaper: failed to find dead agents", "error", err)
+		return
+	}
+	for _, c := range candidates {
+		if err := reapAgent(ctx, s, c, deadAfter, "daemon"); err != nil {
+			log.Warn("agent reaper: failed to reap agent", "agent", c.Agent, "error", err)
+			continue
+		}
+		log.Info("agent reaper: marked agent dead", "agent", c.Agent, "silent_for", c.Silent, "work", c.Work)
+		server.EmitMutation(rpc.MutationEvent{
+			Type:      rpc.MutationReaped,
+			IssueID:   c.Agent,
+			Actor:     "daemon",
+			OldStatus: c.State,
+			NewStatus: string(types.StateDead),
+		})
+		for _, id := range c.Work {
+			server.EmitMutation(rpc.MutationEvent{
+				Type:      rpc.MutationStatus,
+				IssueID:   id,
+				Actor:     "daemon",
+				NewStatus: string(types.StatusOpen),
+			})
+		}
+	}
+}
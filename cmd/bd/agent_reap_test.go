@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestAgentReap(t *testing.T) {
+	tmpDir := t.TempDir()
+	s := newTestStore(t, filepath.Join(tmpDir, ".beads", "beads.db"))
+	ctx := context.Background()
+	now := time.Now()
+
+	newAgent := func(title string, state types.AgentState, lastActivity *time.Time) *types.Issue {
+		t.Helper()
+		agent := &types.Issue{Title: title, Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+		if err := s.CreateIssue(ctx, agent, "test"); err != nil {
+			t.Fatalf("CreateIssue: %v", err)
+		}
+		updates := map[string]interface{}{"agent_state": string(state)}
+		if lastActivity != nil {
+			updates["last_activity"] = *lastActivity
+		}
+		if err := s.UpdateIssue(ctx, agent.ID, updates, "test"); err != nil {
+			t.Fatalf("UpdateIssue: %v", err)
+		}
+		if err := s.AddLabel(ctx, agent.ID, "gt:agent", "test"); err != nil {
+			t.Fatalf("AddLabel: %v", err)
+		}
+		return agent
+	}
+	stale := now.Add(-time.Hour)
+	fresh := now.Add(-time.Minute)
+	silent := newAgent("silent", types.StateWorking, &stale)
+	alive := newAgent("alive", types.StateWorking, &fresh)
+	newAgent("already dead", types.StateDead, &stale)
+	newAgent("never reported", types.StateIdle, nil)
+
+	hooked := &types.Issue{Title: "hooked work", Status: types.StatusInProgress, Priority: 2, IssueType: types.TypeTask, Assignee: silent.ID}
+	assigned := &types.Issue{Title: "assigned work", Status: types.StatusInProgress, Priority: 2, IssueType: types.TypeTask, Assignee: silent.ID}
+	for _, issue := range []*types.Issue{hooked, assigned} {
+		if err := s.CreateIssue(ctx, issue, "test"); err != nil {
+			t.Fatalf("CreateIssue: %v", err)
+		}
+	}
+	if err := s.UpdateIssue(ctx, silent.ID, map[string]interface{}{"hook_bead": hooked.ID}, "test"); err != nil {
+		t.Fatalf("UpdateIssue: %v", err)
+	}
+
+	candidates, err := findDeadAgents(ctx, s, 15*time.Minute, now)
+	if err != nil {
+		t.Fatalf("findDeadAgents: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Agent != silent.ID {
+		t.Fatalf("candidates = %+v, want only %s", candidates, silent.ID)
+	}
+	if len(candidates[0].Work) != 2 {
+		t.Errorf("work = %v, want the hooked and the assigned issue", candidates[0].Work)
+	}
+
+	if err := reapAgent(ctx, s, candidates[0], 15*time.Minute, "test"); err != nil {
+		t.Fatalf("reapAgent: %v", err)
+	}
+
+	got, _ := s.GetIssue(ctx, silent.ID)
+	if got.AgentState != types.StateDead || got.HookBead != "" {
+		t.Errorf("agent state=%q hook=%q, want dead with no hook", got.AgentState, got.HookBead)
+	}
+	for _, issue := range []*types.Issue{hooked, assigned} {
+		got, _ := s.GetIssue(ctx, issue.ID)
+		if got.Status != types.StatusOpen || got.Assignee != "" {
+			t.Errorf("%s status=%q assignee=%q, want open and unassigned", issue.ID, got.Status, got.Assignee)
+		}
+		comments, _ := s.GetIssueComments(ctx, issue.ID)
+		if len(comments) != 1 || !strings.Contains(comments[0].Text, silent.ID) {
+			t.Errorf("%s comments = %+v, want one explaining the reap", issue.ID, comments)
+		}
+	}
+	if got, _ := s.GetIssue(ctx, alive.ID); got.AgentState != types.StateWorking {
+		t.Errorf("live agent state = %q, want working", got.AgentState)
+	}
+
+	// A second pass finds nothing: dead agents are not reaped again
+	candidates, err = findDeadAgents(ctx, s, 15*time.Minute, now)
+	if err != nil {
+		t.Fatalf("findDeadAgents: %v", err)
+	}
+	if len(candidates) != 0 {
+		t.Errorf("second pass candidates = %+v, want none", candidates)
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/blobs"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/utils"
+)
+
+var attachCmd = &cobra.Command{
+	Use:     "attach",
+	GroupID: "issues",
+	Short:   "Attach files (logs, screenshots, patches) to issues",
+	Long: `Attach files to issues instead of pasting them into notes.
+
+File contents are stored once per unique content under .beads/blobs/, named
+by their SHA256. Issues only carry a reference (name, size, sha256, MIME
+type), which travels in issues.jsonl like labels and comments. Blobs no
+attachment refers to any more are removed by 'bd compact'.
+
+Set attachments.mode in .beads/config.yaml to control how blobs reach git:
+  commit  commit .beads/blobs/ like the rest of .beads/ (default)
+  ignore  git-ignore .beads/blobs/; only the references are shared
+  lfs     track .beads/blobs/ with Git LFS
+
+Examples:
+  bd attach add bd-42 build.log
+  bd attach add bd-42 - --name trace.txt < trace.txt
+  bd attach list bd-42
+  bd attach get bd-42 build.log -o /tmp/build.log
+  bd attach rm bd-42 build.log`,
+}
+
+var attachAddCmd = &cobra.Command{
+	Use:   "add <issue-id> <file>",
+	Short: "Attach a file to an issue ('-' reads stdin)",
+	Long: `Attach a file to an issue. Attaching a name that already exists on the
+issue replaces it. Use '-' as the file to read stdin (requires --name).`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("attach add")
+		name, _ := cmd.Flags().GetString("name")
+		mimeType, _ := cmd.Flags().GetString("mime")
+
+		path := args[1]
+		var src io.Reader
+		if path == "-" {
+			if name == "" {
+				FatalError("--name is required when reading from stdin")
+			}
+			src = os.Stdin
+		} else {
+			f, err := os.Open(path) // #nosec G304 -- user-provided file to attach
+			if err != nil {
+				FatalError("opening %s: %v", path, err)
+			}
+			defer func() { _ = f.Close() }()
+			if info, err := f.Stat(); err == nil && info.IsDir() {
+				FatalError("%s is a directory", path)
+			}
+			src = f
+			if name == "" {
+				name = filepath.Base(path)
+			}
+		}
+		if err := validateAttachmentName(name); err != nil {
+			FatalError("%v", err)
+		}
+
+		ctx := rootCtx
+		issueID := resolveAttachIssue(args[0])
+		beadsDir := attachmentsBeadsDir()
+
+		// Sniff the first bytes for the MIME type while storing the blob
+		buffered := bufio.NewReader(src)
+		head, _ := buffered.Peek(512)
+		if mimeType == "" {
+			mimeType = blobs.DetectMimeType(name, head)
+		}
+		sum, size, err := blobs.New(beadsDir).Put(buffered)
+		if err != nil {
+			FatalError("storing %s: %v", path, err)
+		}
+		if err := blobs.ConfigureTracking(beadsDir, config.GetString("attachments.mode")); err != nil {
+			FatalError("%v", err)
+		}
+
+		attachment := &types.Attachment{
+			IssueID:  issueID,
+			Name:     name,
+			Size:     size,
+			SHA256:   sum,
+			MimeType: mimeType,
+		}
+		if err := store.AddAttachment(ctx, attachment, actor); err != nil {
+			FatalErrorRespectJSON("attaching %s: %v", name, err)
+		}
+		markDirtyAndScheduleFlush()
+
+		if jsonOutput {
+			outputJSON(attachment)
+			return
+		}
+		fmt.Printf("%s Attached %s to %s (%s, %s)\n", ui.RenderPass("✓"), name, issueID, formatBytes(size), mimeType)
+	},
+}
+
+var attachListCmd = &cobra.Command{
+	Use:   "list <issue-id>",
+	Short: "List an issue's attachments",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := rootCtx
+		issueID := resolveAttachIssue(args[0])
+
+		attachments, err := store.GetAttachments(ctx, issueID)
+		if err != nil {
+			FatalErrorRespectJSON("listing attachments: %v", err)
+		}
+		if attachments == nil {
+			attachments = make([]*types.Attachment, 0)
+		}
+
+		if jsonOutput {
+			outputJSON(attachments)
+			return
+		}
+		if len(attachments) == 0 {
+			fmt.Printf("No attachments on %s\n", issueID)
+			return
+		}
+		blobStore := blobs.New(attachmentsBeadsDir())
+		fmt.Printf("\nAttachments on %s:\n\n", issueID)
+		for _, a := range attachments {
+			missing := ""
+			if !blobStore.Has(a.SHA256) {
+				missing = ui.RenderWarn(" (content not available locally)")
+			}
+			fmt.Printf("  %s  %s  %s  %s%s\n", a.Name, formatBytes(a.Size), a.MimeType, a.SHA256[:12], missing)
+		}
+		fmt.Println()
+	},
+}
+
+var attachGetCmd = &cobra.Command{
+	Use:   "get <issue-id> <name>",
+	Short: "Write an attachment's content to a file or stdout",
+	Long: `Write an attachment's content to a file. By default the file is written to
+the current directory under the attachment's name; use -o - for stdout.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := rootCtx
+		issueID := resolveAttachIssue(args[0])
+		name := args[1]
+		output, _ := cmd.Flags().GetString("output")
+
+		attachment := findAttachment(ctx, issueID, name)
+		blob, err := blobs.New(attachmentsBeadsDir()).Open(attachment.SHA256)
+		if err != nil {
+			FatalError("%v", err)
+		}
+		defer func() { _ = blob.Close() }()
+
+		if output == "-" {
+			if _, err := io.Copy(os.Stdout, blob); err != nil {
+				FatalError("writing %s: %v", name, err)
+			}
+			return
+		}
+		if output == "" {
+			output = filepath.Base(name)
+		}
+		dst, err := os.Create(output) // #nosec G304 -- user-provided output path
+		if err != nil {
+			FatalError("creating %s: %v", output, err)
+		}
+		if _, err := io.Copy(dst, blob); err != nil {
+			_ = dst.Close()
+			FatalError("writing %s: %v", output, err)
+		}
+		if err := dst.Close(); err != nil {
+			FatalError("writing %s: %v", output, err)
+		}
+
+		if jsonOutput {
+			outputJSON(map[string]interface{}{"issue_id": issueID, "name": name, "path": output, "size": attachment.Size})
+			return
+		}
+		fmt.Printf("%s Wrote %s (%s) to %s\n", ui.RenderPass("✓"), name, formatBytes(attachment.Size), output)
+	},
+}
+
+var attachRmCmd = &cobra.Command{
+	Use:   "rm <issue-id> <name>",
+	Short: "Remove an attachment from an issue",
+	Long: `Remove an attachment from an issue. The content stays in .beads/blobs/
+until 'bd compact' collects blobs that nothing references.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("attach rm")
+		ctx := rootCtx
+		issueID := resolveAttachIssue(args[0])
+		name := args[1]
+
+		if err := store.RemoveAttachment(ctx, issueID, name, actor); err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		markDirtyAndScheduleFlush()
+
+		if jsonOutput {
+			outputJSON(map[string]interface{}{"issue_id": issueID, "name": name, "removed": true})
+			return
+		}
+		fmt.Printf("%s Removed %s from %s\n", ui.RenderPass("✓"), name, issueID)
+	},
+}
+
+// resolveAttachIssue switches to direct mode (attachments have no daemon
+// RPC) and resolves a possibly partial issue ID.
+func resolveAttachIssue(id string) string {
+	if err := ensureDirectMode("daemon does not support attachments"); err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	fullID, err := utils.ResolvePartialID(rootCtx, store, id)
+	if err != nil {
+		FatalErrorRespectJSON("resolving %s: %v", id, err)
+	}
+	return fullID
+}
+
+// attachmentsBeadsDir returns the .beads directory holding the blob store.
+func attachmentsBeadsDir() string {
+	beadsDir := beads.FindBeadsDir()
+	if beadsDir == "" {
+		FatalErrorWithHint("no .beads directory found", "run 'bd init' first")
+	}
+	return beadsDir
+}
+
+// findAttachment returns the named attachment of an issue, exiting if absent.
+func findAttachment(ctx context.Context, issueID, name string) *types.Attachment {
+	attachments, err := store.GetAttachments(ctx, issueID)
+	if err != nil {
+		FatalErrorRespectJSON("listing attachments: %v", err)
+	}
+	for _, a := range attachments {
+		if a.Name == name {
+			return a
+		}
+	}
+	FatalErrorRespectJSON("attachment %q not found on %s", name, issueID)
+	return nil
+}
+
+// validateAttachmentName rejects names that cannot round-trip as file names.
+func validateAttachmentName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("attachment name is empty")
+	case strings.ContainsAny(name, "/\\"):
+		return fmt.Errorf("attachment name %q must not contain path separators", name)
+	case name == "." || name == "..":
+		return fmt.Errorf("invalid attachment name %q", name)
+	}
+	return nil
+}
+
+// formatBytes renders a size for humans (e.g. "1.5 KB").
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	attachAddCmd.Flags().String("name", "", "Attachment name (default: the file's base name)")
+	attachAddCmd.Flags().String("mime", "", "MIME type (default: detected from name and content)")
+	attachGetCmd.Flags().StringP("output", "o", "", "Output path ('-' for stdout; default: ./<name>)")
+	for _, c := range []*cobra.Command{attachAddCmd, attachListCmd, attachGetCmd, attachRmCmd} {
+		c.Flags().BoolVar(&jsonOutput, "json", false, "Output JSON format")
+		attachCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(attachCmd)
+}
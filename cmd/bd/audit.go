@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/beads/internal/audit"
+	"github.com/steveyegge/beads/internal/types"
 )
 
 var (
@@ -21,8 +26,17 @@ var (
 	auditRecordError    string
 	auditRecordStdin    bool
 
+	auditRecordInputTokens  int64
+	auditRecordOutputTokens int64
+	auditRecordCost         float64
+	auditRecordDuration     time.Duration
+
 	auditLabelValue  string
 	auditLabelReason string
+
+	auditSince         string
+	auditDatasetOutput string
+	auditDatasetLabel  string
 )
 
 var auditCmd = &cobra.Command{
@@ -34,7 +48,15 @@ Each line is one event. This file is intended to be versioned in git and used fo
 - auditing ("why did the agent do that?")
 - dataset generation (SFT/RL fine-tuning)
 
-Entries are append-only. Labeling creates a new "label" entry that references a parent entry.`,
+Entries are append-only. Labeling creates a new "label" entry that references a parent entry.
+
+Use 'bd audit report' to aggregate the log and issue history per actor and
+model, and 'bd audit dataset' to export labeled prompt/response pairs.
+
+Calls recorded with --issue-id and usage (--input-tokens, --output-tokens,
+--cost, --duration) roll up into 'bd show', 'bd epic status' and
+'bd mol progress', and are checked against issue budgets (--budget) when
+work is claimed.`,
 }
 
 var auditRecordCmd = &cobra.Command{
@@ -54,7 +76,11 @@ var auditRecordCmd = &cobra.Command{
 			auditRecordIssueID == "" &&
 			auditRecordToolName == "" &&
 			auditRecordExitCode < 0 &&
-			auditRecordError == ""
+			auditRecordError == "" &&
+			auditRecordInputTokens == 0 &&
+			auditRecordOutputTokens == 0 &&
+			auditRecordCost == 0 &&
+			auditRecordDuration == 0
 
 		if auditRecordStdin || (stdinPiped && noFieldsProvided) {
 			b, err := io.ReadAll(os.Stdin)
@@ -84,6 +110,11 @@ var auditRecordCmd = &cobra.Command{
 				Response: auditRecordResponse,
 				ToolName: auditRecordToolName,
 				Error:    auditRecordError,
+
+				InputTokens:  auditRecordInputTokens,
+				OutputTokens: auditRecordOutputTokens,
+				CostUSD:      auditRecordCost,
+				DurationMs:   auditRecordDuration.Milliseconds(),
 			}
 			if auditRecordExitCode >= 0 {
 				exit := auditRecordExitCode
@@ -146,6 +177,230 @@ var auditLabelCmd = &cobra.Command{
 	},
 }
 
+var auditReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize agent performance from the audit log and issue events",
+	Long: `Aggregate .beads/interactions.jsonl and the issue event history.
+
+Per actor:  LLM/tool calls, error rate, label counts and good ratio, issues
+            claimed and closed, median time from claim to close, and the
+            share of closed issues that were later reopened
+Per model:  LLM calls, error rate, label counts and good ratio
+
+A call counts as an error when it recorded an error or a non-zero exit code.
+The good ratio is good / (good + bad), using each entry's latest label.
+
+Examples:
+  bd audit report
+  bd audit report --since 7d
+  bd audit report --since 2025-01-01 --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		since := parseAuditSince()
+		entries := readAuditEntries()
+
+		if err := ensureDirectMode("daemon does not expose event history"); err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		events, err := collectAllEvents(rootCtx)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+
+		report := audit.BuildReport(entries, events, since)
+		if jsonOutput {
+			outputJSON(report)
+			return
+		}
+		renderAuditReport(report)
+	},
+}
+
+var auditDatasetCmd = &cobra.Command{
+	Use:   "dataset",
+	Short: "Export labeled LLM calls as a JSONL dataset",
+	Long: `Export labeled LLM calls as JSONL, one {prompt, response, label} record per
+line, for fine-tuning or evaluation pipelines. Each call uses its latest
+label; unlabeled calls are skipped.
+
+Examples:
+  bd audit dataset > dataset.jsonl
+  bd audit dataset --label good --since 30d -o good.jsonl`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		since := parseAuditSince()
+		records := audit.Dataset(readAuditEntries(), since, auditDatasetLabel)
+
+		out := io.Writer(os.Stdout)
+		if auditDatasetOutput != "" && auditDatasetOutput != "-" {
+			f, err := os.Create(auditDatasetOutput) // #nosec G304 -- user-provided output path
+			if err != nil {
+				FatalError("creating %s: %v", auditDatasetOutput, err)
+			}
+			defer func() { _ = f.Close() }()
+			out = f
+		}
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				FatalError("writing dataset: %v", err)
+			}
+		}
+		if out != os.Stdout {
+			fmt.Fprintf(os.Stderr, "Wrote %d record(s) to %s\n", len(records), auditDatasetOutput)
+		}
+	},
+}
+
+// parseAuditSince parses --since as a duration ago (7d, 12h) or a time.
+func parseAuditSince() time.Time {
+	if auditSince == "" {
+		return time.Time{}
+	}
+	if d, err := parseDurationString(auditSince); err == nil {
+		return time.Now().Add(-d)
+	}
+	t, err := parseTimeFlag(auditSince)
+	if err != nil {
+		FatalErrorRespectJSON("invalid --since %q: %v", auditSince, err)
+	}
+	return t
+}
+
+// readAuditEntries reads the interactions log of the current workspace.
+func readAuditEntries() []*audit.Entry {
+	p, err := audit.Path()
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	entries, err := audit.ReadAll(p)
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	return entries
+}
+
+// collectAllEvents returns the event history of every issue, in one query
+// when the storage backend can list events across issues.
+func collectAllEvents(ctx context.Context) ([]*types.Event, error) {
+	if lister, ok := store.(recentEventLister); ok {
+		events, err := lister.GetRecentEvents(ctx, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get events: %w", err)
+		}
+		return events, nil
+	}
+
+	issues, err := store.SearchIssues(ctx, "", types.IssueFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	var events []*types.Event
+	for _, issue := range issues {
+		history, err := store.GetEvents(ctx, issue.ID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get events for %s: %w", issue.ID, err)
+		}
+		events = append(events, history...)
+	}
+	return events, nil
+}
+
+// renderAuditReport prints a report as two tables.
+func renderAuditReport(r *audit.Report) {
+	if r.Since != nil {
+		fmt.Printf("\nAgent performance since %s\n", r.Since.Local().Format("2006-01-02 15:04"))
+	} else {
+		fmt.Printf("\nAgent performance\n")
+	}
+
+	fmt.Printf("\nBy actor:\n")
+	if len(r.Actors) == 0 {
+		fmt.Printf("  (no activity)\n")
+	} else {
+		fmt.Printf("  %-20s %6s %6s %7s %-16s %6s %7s %6s %8s %7s\n",
+			"ACTOR", "LLM", "TOOLS", "ERRORS", "LABELS", "GOOD", "CLAIMED", "CLOSED", "MEDIAN", "REOPEN")
+		for _, a := range r.Actors {
+			median := "-"
+			if a.MedianClaimToClose != nil {
+				median = formatAuditDuration(time.Duration(*a.MedianClaimToClose * float64(time.Second)))
+			}
+			reopen := "-"
+			if a.Closed > 0 {
+				reopen = formatPercent(a.ReopenRate)
+			}
+			fmt.Printf("  %-20s %6d %6d %7s %-16s %6s %7d %6d %8s %7s\n",
+				truncateString(a.Actor, 20), a.LLMCalls, a.ToolCalls, formatErrorRate(a.InteractStats),
+				formatLabelCounts(a.Labels), formatGoodRatio(a.GoodRatio), a.Claimed, a.Closed, median, reopen)
+		}
+	}
+
+	fmt.Printf("\nBy model:\n")
+	if len(r.Models) == 0 {
+		fmt.Printf("  (no LLM calls)\n")
+	} else {
+		fmt.Printf("  %-28s %6s %7s %-16s %6s\n", "MODEL", "CALLS", "ERRORS", "LABELS", "GOOD")
+		for _, m := range r.Models {
+			fmt.Printf("  %-28s %6d %7s %-16s %6s\n",
+				truncateString(m.Model, 28), m.LLMCalls, formatErrorRate(m.InteractStats),
+				formatLabelCounts(m.Labels), formatGoodRatio(m.GoodRatio))
+		}
+	}
+
+	t := r.Totals
+	fmt.Printf("\nTotal: %d LLM call(s), %d tool call(s), %s errors\n\n", t.LLMCalls, t.ToolCalls, formatErrorRate(*t))
+}
+
+func formatErrorRate(s audit.InteractStats) string {
+	if s.LLMCalls+s.ToolCalls == 0 {
+		return "-"
+	}
+	return formatPercent(s.ErrorRate)
+}
+
+func formatGoodRatio(ratio *float64) string {
+	if ratio == nil {
+		return "-"
+	}
+	return formatPercent(*ratio)
+}
+
+func formatPercent(f float64) string {
+	return fmt.Sprintf("%.0f%%", f*100)
+}
+
+// formatLabelCounts renders label counts like "good:3 bad:1".
+func formatLabelCounts(labels map[string]int) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s:%d", name, labels[name])
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatAuditDuration renders a duration compactly (45s, 12m, 3.5h, 2.0d).
+func formatAuditDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%.0fs", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%.0fm", d.Minutes())
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	default:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	}
+}
+
 func init() {
 	auditRecordCmd.Flags().StringVar(&auditRecordKind, "kind", "", "Entry kind (e.g. llm_call, tool_call, label)")
 	auditRecordCmd.Flags().StringVar(&auditRecordModel, "model", "", "Model name (llm_call)")
@@ -155,6 +410,10 @@ func init() {
 	auditRecordCmd.Flags().StringVar(&auditRecordToolName, "tool-name", "", "Tool name (tool_call)")
 	auditRecordCmd.Flags().IntVar(&auditRecordExitCode, "exit-code", -1, "Exit code (tool_call)")
 	auditRecordCmd.Flags().StringVar(&auditRecordError, "error", "", "Error string (llm_call/tool_call)")
+	auditRecordCmd.Flags().Int64Var(&auditRecordInputTokens, "input-tokens", 0, "Input (prompt) tokens used (llm_call)")
+	auditRecordCmd.Flags().Int64Var(&auditRecordOutputTokens, "output-tokens", 0, "Output (completion) tokens used (llm_call)")
+	auditRecordCmd.Flags().Float64Var(&auditRecordCost, "cost", 0, "Cost of the call in USD")
+	auditRecordCmd.Flags().DurationVar(&auditRecordDuration, "duration", 0, "Wall-clock duration of the call (e.g. 2.5s)")
 	auditRecordCmd.Flags().BoolVar(&auditRecordStdin, "stdin", false, "Read a JSON object from stdin (must match audit.Entry schema)")
 
 	auditLabelCmd.Flags().StringVar(&auditLabelValue, "label", "", `Label value (e.g. "good" or "bad")`)
@@ -163,7 +422,14 @@ func init() {
 	// Issue ID completions
 	auditCmd.ValidArgsFunction = issueIDCompletion
 
+	auditReportCmd.Flags().StringVar(&auditSince, "since", "", "Only include activity since a duration ago (7d, 12h) or a date")
+	auditDatasetCmd.Flags().StringVar(&auditSince, "since", "", "Only include calls since a duration ago (7d, 12h) or a date")
+	auditDatasetCmd.Flags().StringVarP(&auditDatasetOutput, "output", "o", "", "Write the dataset to a file (default: stdout)")
+	auditDatasetCmd.Flags().StringVar(&auditDatasetLabel, "label", "", "Only export calls with this label (e.g. good)")
+
 	auditCmd.AddCommand(auditRecordCmd)
 	auditCmd.AddCommand(auditLabelCmd)
+	auditCmd.AddCommand(auditReportCmd)
+	auditCmd.AddCommand(auditDatasetCmd)
 	rootCmd.AddCommand(auditCmd)
 }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestCollectAllEvents(t *testing.T) {
+	testStore := newTestStore(t, filepath.Join(t.TempDir(), ".beads", "beads.db"))
+	ctx := context.Background()
+	oldStore := store
+	store = testStore
+	defer func() { store = oldStore }()
+
+	var ids []string
+	for _, title := range []string{"first", "second"} {
+		issue := &types.Issue{Title: title, Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+		if err := testStore.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("CreateIssue: %v", err)
+		}
+		if err := testStore.CloseIssue(ctx, issue.ID, "done", "tester", ""); err != nil {
+			t.Fatalf("CloseIssue: %v", err)
+		}
+		ids = append(ids, issue.ID)
+	}
+
+	events, err := collectAllEvents(ctx)
+	if err != nil {
+		t.Fatalf("collectAllEvents: %v", err)
+	}
+	byIssue := make(map[string]int)
+	for _, e := range events {
+		byIssue[e.IssueID]++
+	}
+	for _, id := range ids {
+		want, err := testStore.GetEvents(ctx, id, 0)
+		if err != nil {
+			t.Fatalf("GetEvents: %v", err)
+		}
+		if len(want) == 0 || byIssue[id] != len(want) {
+			t.Errorf("%s: collected %d events, want %d", id, byIssue[id], len(want))
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/capabilities"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// ProjectCapabilities is one project's entry in bd capabilities list.
+type ProjectCapabilities struct {
+	Project      string                    `json:"project"`
+	Local        bool                      `json:"local,omitempty"`
+	Error        string                    `json:"error,omitempty"`
+	Capabilities []capabilities.Capability `json:"capabilities"`
+}
+
+var capabilitiesCmd = &cobra.Command{
+	Use:     "capabilities",
+	Aliases: []string{"caps"},
+	GroupID: "deps",
+	Short:   "Browse capabilities shipped across projects",
+	Long: `Browse the capability registry used for cross-project dependencies.
+
+A capability is declared with an export:<name> label and shipped with
+bd ship, which adds provides:<name> (provides:<name>@<version> with
+--version) to the closed issue. Other projects depend on it with
+external:<project>:<name>, optionally constrained by version:
+
+  external:beads:api          # Any shipped version
+  external:beads:api@2        # Any 2.x
+  external:beads:api@>=2.1    # 2.1.0 or later
+  external:beads:api@^1.4     # >=1.4.0, <2.0.0
+  external:beads:api@>=2,<3   # Comma-separated terms must all hold
+
+External projects are read from the paths in external_projects config. Each
+project's capabilities are cached (in ~/.cache/beads/capabilities) until its
+database changes, so bd ready and bd blocked don't reopen every project.`,
+}
+
+var capabilitiesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List capabilities of this project and all external projects",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := ensureDirectMode("capabilities list reads labels across projects"); err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		ctx := rootCtx
+		project, _ := cmd.Flags().GetString("project")
+		shippedOnly, _ := cmd.Flags().GetBool("shipped")
+
+		projects, err := listCapabilities(ctx, project)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		if shippedOnly {
+			for _, p := range projects {
+				var shipped []capabilities.Capability
+				for _, c := range p.Capabilities {
+					if c.Shipped {
+						shipped = append(shipped, c)
+					}
+				}
+				p.Capabilities = shipped
+			}
+		}
+		for _, p := range projects {
+			if p.Capabilities == nil {
+				p.Capabilities = []capabilities.Capability{}
+			}
+		}
+
+		if jsonOutput {
+			outputJSON(projects)
+			return
+		}
+		if len(projects) == 0 {
+			fmt.Println("No projects found")
+			return
+		}
+		for i, p := range projects {
+			if i > 0 {
+				fmt.Println()
+			}
+			name := ui.RenderBold(p.Project)
+			if p.Local {
+				name += ui.RenderMuted(" (this project)")
+			}
+			fmt.Println(name)
+			if p.Error != "" {
+				fmt.Printf("  %s %s\n", ui.RenderFail("✗"), p.Error)
+				continue
+			}
+			if len(p.Capabilities) == 0 {
+				fmt.Printf("  %s\n", ui.RenderMuted("no capabilities"))
+				continue
+			}
+			for _, c := range p.Capabilities {
+				fmt.Printf("  %s\n", formatCapability(c))
+			}
+		}
+	},
+}
+
+var capabilitiesCheckCmd = &cobra.Command{
+	Use:   "check <external:project:capability[@constraint]>...",
+	Short: "Explain how external dependency references resolve",
+	Example: `  bd capabilities check external:beads:api@>=2
+  bd capabilities check external:beads:api external:gastown:mail`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resolved := capabilities.Default().ResolveAll(rootCtx, args)
+		results := make([]*capabilities.Resolution, 0, len(args))
+		unsatisfied := false
+		for _, ref := range args {
+			results = append(results, resolved[ref])
+			if !resolved[ref].Satisfied {
+				unsatisfied = true
+			}
+		}
+
+		if jsonOutput {
+			outputJSON(results)
+		} else {
+			for _, res := range results {
+				if res.Satisfied {
+					fmt.Printf("%s %s: %s\n", ui.RenderPass("✓"), res.Ref, res.Reason)
+					fmt.Printf("  %s\n", formatCapability(*res.Provider))
+				} else {
+					fmt.Printf("%s %s: %s\n", ui.RenderFail("✗"), res.Ref, res.Reason)
+				}
+			}
+		}
+		if unsatisfied {
+			os.Exit(1)
+		}
+	},
+}
+
+// listCapabilities collects capabilities from this project and every
+// external project, or only the named one. Unreadable external projects are
+// reported in their Error field rather than failing the whole listing.
+func listCapabilities(ctx context.Context, only string) ([]*ProjectCapabilities, error) {
+	var projects []*ProjectCapabilities
+
+	local := localProjectName()
+	if only == "" || only == local {
+		caps, err := localCapabilities(ctx, local)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, &ProjectCapabilities{Project: local, Local: true, Capabilities: caps})
+	}
+
+	external := config.GetExternalProjects()
+	names := make([]string, 0, len(external))
+	for name := range external {
+		if only == "" || only == name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := &ProjectCapabilities{Project: name}
+		caps, err := capabilities.Default().Project(ctx, name)
+		if err != nil {
+			p.Error = err.Error()
+		}
+		p.Capabilities = caps
+		projects = append(projects, p)
+	}
+
+	if only != "" && len(projects) == 0 {
+		return nil, fmt.Errorf("unknown project %q (not this project and not in external_projects)", only)
+	}
+	return projects, nil
+}
+
+// localCapabilities reads capabilities from the current store, whatever its
+// backend.
+func localCapabilities(ctx context.Context, project string) ([]capabilities.Capability, error) {
+	issues, err := store.SearchIssues(ctx, "", types.IssueFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	ids := make([]string, len(issues))
+	for i, issue := range issues {
+		ids[i] = issue.ID
+	}
+	labels, err := store.GetLabelsForIssues(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels: %w", err)
+	}
+
+	var caps []capabilities.Capability
+	for _, issue := range issues {
+		for _, c := range capabilities.FromIssue(issue.ID, issue.Title, string(issue.Status), labels[issue.ID]) {
+			c.Project = project
+			caps = append(caps, c)
+		}
+	}
+	capabilities.Sort(caps)
+	return caps, nil
+}
+
+// localProjectName names this project after the directory holding .beads.
+func localProjectName() string {
+	if dbPath == "" {
+		return "local"
+	}
+	return filepath.Base(filepath.Dir(filepath.Dir(dbPath)))
+}
+
+// formatCapability renders one capability line: name@version, issue, state.
+func formatCapability(c capabilities.Capability) string {
+	name := c.Name
+	if c.Version != "" {
+		name += "@" + c.Version
+	}
+	if c.Shipped {
+		return fmt.Sprintf("%s %s  %s %s", ui.RenderPass("✓"), name, ui.RenderID(c.IssueID), c.Title)
+	}
+	return fmt.Sprintf("%s %s  %s %s %s", ui.RenderWarn("○"), name, ui.RenderID(c.IssueID), c.Title,
+		ui.RenderMuted("["+c.Status+", not shipped]"))
+}
+
+func init() {
+	capabilitiesListCmd.Flags().String("project", "", "Only list this project")
+	capabilitiesListCmd.Flags().Bool("shipped", false, "Only list shipped capabilities")
+
+	capabilitiesCmd.AddCommand(capabilitiesListCmd)
+	capabilitiesCmd.AddCommand(capabilitiesCheckCmd)
+	rootCmd.AddCommand(capabilitiesCmd)
+}
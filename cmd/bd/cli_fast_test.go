@@ -595,6 +595,34 @@ func TestCLI_EndToEnd(t *testing.T) {
 	}
 }
 
+// TestCLI_MailDelegate checks that a configured mail delegate receives
+// arguments and --help the native mail commands would reject or handle.
+func TestCLI_MailDelegate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping slow CLI test in short mode")
+	}
+
+	tmpDir := createTempDirWithCleanup(t)
+	runBDExec(t, tmpDir, "init", "--prefix", "test", "--quiet")
+	runBDExec(t, tmpDir, "config", "set", "mail.delegate", "echo delegated:")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"mail", "inbox", "gt-emma"}, "delegated: inbox gt-emma"},
+		{[]string{"mail", "read"}, "delegated: read"},
+		{[]string{"mail", "send", "--help"}, "delegated: send --help"},
+		{[]string{"--actor", "mail", "mail", "inbox"}, "delegated: inbox"},
+	}
+	for _, tt := range tests {
+		out := runBDExec(t, tmpDir, tt.args...)
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("bd %v = %q, want %q", tt.args, out, tt.want)
+		}
+	}
+}
+
 func TestCLI_Labels(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping slow CLI test in short mode")
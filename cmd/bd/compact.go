@@ -26,6 +26,7 @@ var (
 	compactAuto            bool
 	compactPrune           bool
 	compactPurgeTombstones bool
+	compactGCBlobs         bool
 	compactSummary         string
 	compactActor           string
 	compactLimit           int
@@ -42,6 +43,7 @@ actively referenced. This is permanent graceful decay - original content is disc
 
 Modes:
   - Prune: Remove expired tombstones from issues.jsonl (no API key needed)
+  - GC blobs: Remove attachment blobs nothing references (no API key needed)
   - Analyze: Export candidates for agent review (no API key needed)
   - Apply: Accept agent-provided summary (no API key needed)
   - Auto: AI-powered compaction (requires ANTHROPIC_API_KEY, legacy)
@@ -60,6 +62,9 @@ Tombstone Cleanup:
            removes any tombstone that no open issues depend on, regardless of age.
            Also cleans stale deps from closed issues to tombstones.
 
+  Both also remove attachment blobs in .beads/blobs/ that no issue references
+  any more; --gc-blobs does only that.
+
 Examples:
   # Age-based pruning
   bd compact --prune                       # Remove tombstones older than 30 days
@@ -70,6 +75,10 @@ Examples:
   bd compact --purge-tombstones --dry-run  # Preview what would be purged
   bd compact --purge-tombstones            # Remove tombstones with no open deps
 
+  # Attachment blob cleanup
+  bd compact --gc-blobs --dry-run          # Preview unreferenced blobs
+  bd compact --gc-blobs                    # Remove unreferenced blobs
+
   # Agent-driven workflow (recommended)
   bd compact --analyze --json              # Get candidates with full content
   bd compact --apply --id bd-42 --summary summary.txt
@@ -117,6 +126,12 @@ Examples:
 			return
 		}
 
+		// Handle gc-blobs mode (attachment blob cleanup only)
+		if compactGCBlobs {
+			runCompactBlobGC(ctx)
+			return
+		}
+
 		// Count active modes
 		activeModes := 0
 		if compactAnalyze {
@@ -131,7 +146,7 @@ Examples:
 
 		// Check for exactly one mode
 		if activeModes == 0 {
-			fmt.Fprintf(os.Stderr, "Error: must specify one mode: --prune, --purge-tombstones, --analyze, --apply, or --auto\n")
+			fmt.Fprintf(os.Stderr, "Error: must specify one mode: --prune, --purge-tombstones, --gc-blobs, --analyze, --apply, or --auto\n")
 			os.Exit(1)
 		}
 		if activeModes > 1 {
@@ -784,6 +799,7 @@ func init() {
 	compactCmd.Flags().BoolVar(&compactPrune, "prune", false, "Prune mode: remove expired tombstones from issues.jsonl (by age)")
 	compactCmd.Flags().IntVar(&compactOlderThan, "older-than", -1, "Prune tombstones older than N days (0=all, default: 30)")
 	compactCmd.Flags().BoolVar(&compactPurgeTombstones, "purge-tombstones", false, "Purge mode: remove tombstones with no open deps (by dependency analysis)")
+	compactCmd.Flags().BoolVar(&compactGCBlobs, "gc-blobs", false, "GC mode: remove attachment blobs no issue references")
 	compactCmd.Flags().StringVar(&compactSummary, "summary", "", "Path to summary file (use '-' for stdin)")
 	compactCmd.Flags().StringVar(&compactActor, "actor", "agent", "Actor name for audit trail")
 	compactCmd.Flags().IntVar(&compactLimit, "limit", 0, "Limit number of candidates (0 = no limit)")
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/blobs"
+)
+
+// referencedBlobs returns the SHA256 of every blob an attachment refers to,
+// in the database or in issues.jsonl. Taking the union means a blob is never
+// collected while a reference to it has not been flushed or imported yet.
+func referencedBlobs(ctx context.Context) (map[string]bool, error) {
+	referenced := make(map[string]bool)
+
+	if err := ensureStoreActive(); err != nil {
+		return nil, err
+	}
+	all, err := store.GetAllAttachments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	for _, attachments := range all {
+		for _, a := range attachments {
+			referenced[a.SHA256] = true
+		}
+	}
+
+	if jsonlPath := findJSONLPath(); jsonlPath != "" {
+		issues, err := loadIssuesFromJSONL(jsonlPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", jsonlPath, err)
+		}
+		for _, issue := range issues {
+			for _, a := range issue.Attachments {
+				referenced[a.SHA256] = true
+			}
+		}
+	}
+	return referenced, nil
+}
+
+// gcAttachmentBlobs removes blobs no attachment refers to any more. Issues
+// and attachments removed by compaction leave their blobs behind, so every
+// cleanup mode of bd compact ends with this step.
+func gcAttachmentBlobs(ctx context.Context, dryRun bool) (*blobs.GCResult, error) {
+	beadsDir := beads.FindBeadsDir()
+	if beadsDir == "" {
+		return &blobs.GCResult{Removed: []string{}}, nil
+	}
+	referenced, err := referencedBlobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return blobs.New(beadsDir).GC(referenced, dryRun)
+}
+
+// runCompactBlobGC runs blob garbage collection on its own (--gc-blobs).
+func runCompactBlobGC(ctx context.Context) {
+	result, err := gcAttachmentBlobs(ctx, compactDryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to collect attachment blobs: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"dry_run":       compactDryRun,
+			"blobs_removed": result.Removed,
+			"bytes_freed":   result.Bytes,
+		})
+		return
+	}
+
+	if len(result.Removed) == 0 {
+		fmt.Println("No unreferenced attachment blobs")
+		return
+	}
+	if compactDryRun {
+		fmt.Printf("DRY RUN - %d unreferenced attachment blob(s) would be removed (%s)\n", len(result.Removed), formatBytes(result.Bytes))
+		return
+	}
+	fmt.Printf("✓ Removed %d unreferenced attachment blob(s) (%s)\n", len(result.Removed), formatBytes(result.Bytes))
+}
+
+// collectBlobsAfterCleanup runs blob GC after a tombstone cleanup mode. A
+// failure only warns: the cleanup itself already succeeded.
+func collectBlobsAfterCleanup(ctx context.Context) *blobs.GCResult {
+	result, err := gcAttachmentBlobs(ctx, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to collect attachment blobs: %v\n", err)
+		return &blobs.GCResult{Removed: []string{}}
+	}
+	return result
+}
+
+// printBlobGC reports collected blobs in text output.
+func printBlobGC(result *blobs.GCResult) {
+	if len(result.Removed) > 0 {
+		fmt.Printf("✓ Removed %d unreferenced attachment blob(s) (%s)\n", len(result.Removed), formatBytes(result.Bytes))
+	}
+}
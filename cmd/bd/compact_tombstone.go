@@ -231,6 +231,7 @@ func runCompactPrune() {
 		os.Exit(1)
 	}
 
+	blobResult := collectBlobsAfterCleanup(rootCtx)
 	elapsed := time.Since(start)
 
 	if jsonOutput {
@@ -239,12 +240,14 @@ func runCompactPrune() {
 			"pruned_count":  result.PrunedCount,
 			"ttl_days":      result.TTLDays,
 			"tombstone_ids": result.PrunedIDs,
+			"blobs_removed": len(blobResult.Removed),
 			"elapsed_ms":    elapsed.Milliseconds(),
 		}
 		outputJSON(output)
 		return
 	}
 
+	printBlobGC(blobResult)
 	if result.PrunedCount == 0 {
 		fmt.Printf("No expired tombstones to prune (TTL: %d days)\n", result.TTLDays)
 		return
@@ -481,6 +484,7 @@ func runCompactPurgeTombstones() {
 		os.Exit(1)
 	}
 
+	blobResult := collectBlobsAfterCleanup(rootCtx)
 	elapsed := time.Since(start)
 
 	if jsonOutput {
@@ -490,12 +494,14 @@ func runCompactPurgeTombstones() {
 			"tombstones_deleted":  result.TombstonesDeleted,
 			"tombstones_kept":     result.TombstonesKept,
 			"deps_removed":        result.DepsRemoved,
+			"blobs_removed":       len(blobResult.Removed),
 			"elapsed_ms":          elapsed.Milliseconds(),
 		}
 		outputJSON(output)
 		return
 	}
 
+	printBlobGC(blobResult)
 	if result.TombstonesDeleted == 0 {
 		fmt.Printf("No tombstones to purge (all %d have open deps)\n", result.TombstonesBefore)
 		return
@@ -69,11 +69,17 @@ proto multiple times without re-cooking.
 For most workflows, prefer ephemeral protos: pour and wisp commands
 accept formula names directly and cook inline.
 
+Gate and loop conditions can use providers such as file.exists, env,
+vcs.clean, vcs.branch, bead.status, label.has, json.path and cmd.exit.
+--dry-run validates every condition and previews those that don't depend
+on step state. cmd.exit runs shell commands, so it is disabled unless the
+formula is trusted (--trust, or listed in formula.trusted in your user config).
+
 Examples:
   bd cook mol-feature.formula.json                    # Compile-time: keep {{vars}}
   bd cook mol-feature --var name=auth                 # Runtime: substitute vars
   bd cook mol-feature --mode=runtime --var name=auth  # Explicit runtime mode
-  bd cook mol-feature --dry-run                       # Preview steps
+  bd cook mol-feature --dry-run                       # Preview steps and conditions
   bd cook mol-release.formula.json --persist          # Write to database
   bd cook mol-release.formula.json --persist --force  # Replace existing
 
@@ -109,6 +115,7 @@ type cookFlags struct {
 	inputVars   map[string]string
 	runtimeMode bool
 	formulaPath string
+	trust       bool
 }
 
 // parseCookFlags parses and validates cook command flags
@@ -120,6 +127,7 @@ func parseCookFlags(cmd *cobra.Command, args []string) (*cookFlags, error) {
 	prefix, _ := cmd.Flags().GetString("prefix")
 	varFlags, _ := cmd.Flags().GetStringArray("var")
 	mode, _ := cmd.Flags().GetString("mode")
+	trust, _ := cmd.Flags().GetBool("trust")
 
 	// Parse variables
 	inputVars := make(map[string]string)
@@ -148,6 +156,7 @@ func parseCookFlags(cmd *cobra.Command, args []string) (*cookFlags, error) {
 		inputVars:   inputVars,
 		runtimeMode: runtimeMode,
 		formulaPath: args[0],
+		trust:       trust,
 	}, nil
 }
 
@@ -155,7 +164,10 @@ func parseCookFlags(cmd *cobra.Command, args []string) (*cookFlags, error) {
 // It first tries to load by name from the formula registry (.beads/formulas/),
 // and falls back to parsing as a file path if that fails.
 func loadAndResolveFormula(formulaPath string, searchPaths []string) (*formula.Formula, error) {
-	parser := formula.NewParser(searchPaths...)
+	parser, err := newFormulaParser(searchPaths)
+	if err != nil {
+		return nil, err
+	}
 
 	// Try to load by name first (from .beads/formulas/ registry)
 	f, err := parser.LoadByName(formulaPath)
@@ -405,6 +417,9 @@ func runCook(cmd *cobra.Command, args []string) {
 	// Handle dry-run mode
 	if flags.dryRun {
 		outputCookDryRun(resolved, protoID, flags.runtimeMode, flags.inputVars, vars, bondPoints)
+		if !outputCookConditions(resolved, flags.inputVars, flags.trust) {
+			os.Exit(1)
+		}
 		return
 	}
 
@@ -671,8 +686,11 @@ func resolveAndCookFormula(formulaName string, searchPaths []string) (*TemplateS
 // If conditionVars is provided, steps with conditions that evaluate to false are excluded.
 // Pass nil for conditionVars to include all steps (condition filtering skipped).
 func resolveAndCookFormulaWithVars(formulaName string, searchPaths []string, conditionVars map[string]string) (*TemplateSubgraph, error) {
-	// Create parser with search paths
-	parser := formula.NewParser(searchPaths...)
+	// Create parser with search paths, pinned to .beads/formulas.lock
+	parser, err := newFormulaParser(searchPaths)
+	if err != nil {
+		return nil, err
+	}
 
 	// Load formula by name
 	f, err := parser.LoadByName(formulaName)
@@ -783,12 +801,6 @@ func cookFormula(ctx context.Context, s storage.Storage, f *formula.Formula, pro
 		return nil, fmt.Errorf("no database connection")
 	}
 
-	// Check for SQLite store (needed for batch create with skip prefix)
-	sqliteStore, ok := s.(*sqlite.SQLiteStorage)
-	if !ok {
-		return nil, fmt.Errorf("cook requires SQLite storage")
-	}
-
 	// Map step ID -> created issue ID
 	idMapping := make(map[string]string)
 
@@ -837,11 +849,17 @@ func cookFormula(ctx context.Context, s storage.Storage, f *formula.Formula, pro
 		collectDependencies(step, idMapping, &deps)
 	}
 
-	// Create all issues using batch with skip prefix validation
-	opts := sqlite.BatchCreateOptions{
-		SkipPrefixValidation: true, // Molecules use mol-* prefix
-	}
-	if err := sqliteStore.CreateIssuesWithFullOptions(ctx, issues, actor, opts); err != nil {
+	// Create all issues in one batch. SQLite needs prefix validation skipped
+	// (molecules use mol-* prefix); other backends, like the in-memory store
+	// used by 'bd formula test', take the IDs as given.
+	if sqliteStore, ok := s.(*sqlite.SQLiteStorage); ok {
+		opts := sqlite.BatchCreateOptions{
+			SkipPrefixValidation: true, // Molecules use mol-* prefix
+		}
+		if err := sqliteStore.CreateIssuesWithFullOptions(ctx, issues, actor, opts); err != nil {
+			return nil, fmt.Errorf("failed to create issues: %w", err)
+		}
+	} else if err := s.CreateIssues(ctx, issues, actor); err != nil {
 		return nil, fmt.Errorf("failed to create issues: %w", err)
 	}
 
@@ -1057,6 +1075,7 @@ func init() {
 	cookCmd.Flags().String("prefix", "", "Prefix to prepend to proto ID (e.g., 'gt-' creates 'gt-mol-feature')")
 	cookCmd.Flags().StringArray("var", []string{}, "Variable substitution (key=value), enables runtime mode")
 	cookCmd.Flags().String("mode", "", "Cooking mode: compile (keep placeholders) or runtime (substitute vars)")
+	cookCmd.Flags().Bool("trust", false, "Trust this formula's shell conditions (cmd.exit), like formula.trusted config")
 
 	rootCmd.AddCommand(cookCmd)
 }
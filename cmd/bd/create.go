@@ -277,6 +277,18 @@ var createCmd = &cobra.Command{
 			estimatedMinutes = &est
 		}
 
+		// Get budget if provided
+		var budgetUSD *float64
+		if cmd.Flags().Changed("budget") {
+			budget, _ := cmd.Flags().GetFloat64("budget")
+			if budget < 0 {
+				FatalError("budget must be a non-negative amount in USD")
+			}
+			if budget > 0 {
+				budgetUSD = &budget
+			}
+		}
+
 		// Validate template based on --validate flag or config
 		validateTemplate, _ := cmd.Flags().GetBool("validate")
 		if validateTemplate {
@@ -417,6 +429,7 @@ var createCmd = &cobra.Command{
 				Assignee:           assignee,
 				ExternalRef:        externalRef,
 				EstimatedMinutes:   estimatedMinutes,
+				BudgetUSD:          budgetUSD,
 				Labels:             labels,
 				Dependencies:       deps,
 				WaitsFor:           waitsFor,
@@ -480,6 +493,7 @@ var createCmd = &cobra.Command{
 			Assignee:           assignee,
 			ExternalRef:        externalRefPtr,
 			EstimatedMinutes:   estimatedMinutes,
+			BudgetUSD:          budgetUSD,
 			Ephemeral:          wisp,
 			CreatedBy:          getActorWithGit(),
 			MolType:            molType,
@@ -699,6 +713,7 @@ func init() {
 	createCmd.Flags().String("rig", "", "Create issue in a different rig (e.g., --rig beads)")
 	createCmd.Flags().String("prefix", "", "Create issue in rig by prefix (e.g., --prefix bd- or --prefix bd or --prefix beads)")
 	createCmd.Flags().IntP("estimate", "e", 0, "Time estimate in minutes (e.g., 60 for 1 hour)")
+	createCmd.Flags().Float64("budget", 0, "Spend limit in USD for this issue and its descendants (see budget.enforcement)")
 	createCmd.Flags().Bool("ephemeral", false, "Create as ephemeral (ephemeral, not exported to JSONL)")
 	createCmd.Flags().String("mol-type", "", "Molecule type: swarm (multi-polecat), patrol (recurring ops), work (default)")
 	createCmd.Flags().Bool("validate", false, "Validate description contains required sections for issue type")
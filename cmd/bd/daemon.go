@@ -166,7 +166,7 @@ Run 'bd daemon' with no flags to see available options.`,
 		}
 
 		// Validate we're in a git repo (skip in local mode)
-		if !localMode && !isGitRepo() {
+		if !localMode && !isVCSRepo() {
 			fmt.Fprintf(os.Stderr, "Error: not in a git repository\n")
 			fmt.Fprintf(os.Stderr, "Hint: run 'git init' to initialize a repository, or use --local for local-only mode\n")
 			os.Exit(1)
@@ -460,6 +460,12 @@ func runDaemonLoop(interval time.Duration, autoCommit, autoPush, autoPull, local
 		return
 	}
 
+	// Apply the agent liveness policy (no-op unless agents.dead-after is set)
+	startAgentReaper(serverCtx, store, server, log)
+
+	// Revert state values past their schema ttl (no-op without a ttl)
+	startStateExpirer(serverCtx, store, server, log)
+
 	// Choose event loop based on BEADS_DAEMON_MODE (need to determine early for SetConfig)
 	daemonMode := os.Getenv("BEADS_DAEMON_MODE")
 	if daemonMode == "" {
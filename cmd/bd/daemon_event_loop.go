@@ -57,20 +57,45 @@ func runEventDrivenLoop(
 	})
 	defer importDebouncer.Cancel()
 
+	// In a jj repo, jj's operation log says exactly when beads data changed
+	// (fetch, rebase, undo, workspace update), so it replaces file polling.
+	// Colocated repos keep the file watcher too, since plain git commands
+	// change the working copy without a jj operation.
+	useFileWatcher := true
+	if jjRoot, colocated := detectJJRepo(jsonlPath); jjRoot != "" {
+		lastOp, _ := store.GetMetadata(ctx, jjLastOpMetadataKey)
+		opWatcher, err := NewOpLogWatcher(jjRoot, jsonlPath, lastOp, func(opID string) {
+			// The import records the operation once it has applied it
+			setPendingImportOp(opID)
+			importDebouncer.Trigger()
+		})
+		if err != nil {
+			log.log("WARNING: jj operation log watcher unavailable (%v), using file watcher", err)
+		} else {
+			opWatcher.Start(ctx, log)
+			defer func() { _ = opWatcher.Close() }()
+			useFileWatcher = colocated
+		}
+	}
+
 	// Start file watcher for JSONL changes
-	watcher, err := NewFileWatcher(jsonlPath, func() {
-		importDebouncer.Trigger()
-	})
+	var watcher *FileWatcher
 	var fallbackTicker *time.Ticker
-	if err != nil {
-		log.log("WARNING: File watcher unavailable (%v), using 60s polling fallback", err)
-		watcher = nil
-		// Fallback ticker to check for remote changes when watcher unavailable
-		fallbackTicker = time.NewTicker(60 * time.Second)
-		defer fallbackTicker.Stop()
-	} else {
-		watcher.Start(ctx, log)
-		defer func() { _ = watcher.Close() }()
+	if useFileWatcher {
+		var err error
+		watcher, err = NewFileWatcher(jsonlPath, func() {
+			importDebouncer.Trigger()
+		})
+		if err != nil {
+			log.log("WARNING: File watcher unavailable (%v), using 60s polling fallback", err)
+			watcher = nil
+			// Fallback ticker to check for remote changes when watcher unavailable
+			fallbackTicker = time.NewTicker(60 * time.Second)
+			defer fallbackTicker.Stop()
+		} else {
+			watcher.Start(ctx, log)
+			defer func() { _ = watcher.Close() }()
+		}
 	}
 
 	// Handle mutation events from RPC server
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	opdaemon "github.com/steveyegge/beads/internal/turso/daemon"
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+const (
+	// jjLastOpMetadataKey stores the last jj operation the daemon imported,
+	// so operations that happen while the daemon is down (or whose import
+	// failed) are picked up again.
+	jjLastOpMetadataKey = "jj_last_op"
+)
+
+// OpLogWatcher triggers imports from jj's operation log instead of
+// filesystem events. Fetches, rebases, undo and workspace updates all show up
+// as operations, and only those that touch the beads directory fire.
+type OpLogWatcher struct {
+	repoRoot     string
+	beadsRelDir  string // Slash-separated, relative to repoRoot
+	lastOpID     string
+	pollInterval time.Duration
+	onOperation  func(opID string)
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// detectJJRepo returns the jj repository root containing jsonlPath, or ""
+// when the repository is not used through jj (BD_VCS=git picks git for
+// colocated repos). colocated reports whether git shares the working copy.
+func detectJJRepo(jsonlPath string) (root string, colocated bool) {
+	v, err := vcs.NewFactory(vcs.WithCache(false), vcs.WithPreferredType(vcs.PreferredVCS())).Create(filepath.Dir(jsonlPath))
+	if err != nil || v.Name() == vcs.TypeGit {
+		return "", false
+	}
+	root, err = v.RepoRoot()
+	if err != nil {
+		return "", false
+	}
+	return root, v.Name() == vcs.TypeColocate
+}
+
+// NewOpLogWatcher creates a watcher for the jj repository at repoRoot.
+// onOperation is called with the ID of each operation that changed files
+// under the JSONL's directory. Watching resumes after lastOpID when it is
+// still in the op log, otherwise it starts from the current operation.
+func NewOpLogWatcher(repoRoot, jsonlPath, lastOpID string, onOperation func(opID string)) (*OpLogWatcher, error) {
+	rel, err := filepath.Rel(repoRoot, filepath.Dir(jsonlPath))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("JSONL %s is outside the jj repository %s", jsonlPath, repoRoot)
+	}
+	if lastOpID == "" {
+		lastOpID, err = opdaemon.GetLatestOperationID(context.Background(), repoRoot)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &OpLogWatcher{
+		repoRoot:     repoRoot,
+		beadsRelDir:  filepath.ToSlash(rel),
+		lastOpID:     lastOpID,
+		pollInterval: 500 * time.Millisecond,
+		onOperation:  onOperation,
+	}, nil
+}
+
+// touchesBeads reports whether a changed path is inside the beads directory.
+func (w *OpLogWatcher) touchesBeads(path string) bool {
+	return w.beadsRelDir == "." || strings.HasPrefix(path, w.beadsRelDir+"/")
+}
+
+// Start begins polling the operation log in a background goroutine until
+// the context is canceled or Close is called.
+func (w *OpLogWatcher) Start(ctx context.Context, log daemonLogger) {
+	ctx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+
+	log.Info("watching jj operation log", "repo", w.repoRoot, "beads_dir", w.beadsRelDir, "since", opdaemon.ShortOpID(w.lastOpID))
+	cfg := opdaemon.OpLogWatcherConfig{
+		RepoPath:     w.repoRoot,
+		PollInterval: w.pollInterval,
+		LastOpID:     w.lastOpID,
+		MatchFile:    w.touchesBeads,
+		Logf: func(format string, args ...interface{}) {
+			log.Warn(fmt.Sprintf(format, args...))
+		},
+		// The import always reads the whole JSONL, so an operation that can't
+		// be replayed is caught up on by importing at the newest one
+		Resync: func(latest opdaemon.OpLogEntry) error {
+			w.onOperation(latest.ID)
+			return nil
+		},
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		_ = opdaemon.WatchOpLog(ctx, cfg, func(entries []opdaemon.OpLogEntry) error {
+			for _, entry := range entries {
+				if len(entry.AffectedFiles) == 0 {
+					continue
+				}
+				log.Info("jj operation touched beads data", "op", opdaemon.ShortOpID(entry.ID), "description", entry.Description)
+				w.onOperation(entry.ID)
+			}
+			return nil
+		})
+	}()
+}
+
+// Close stops the watcher and waits for the polling goroutine to exit.
+func (w *OpLogWatcher) Close() error {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+	return nil
+}
+
+// pendingImportOp holds the jj operation behind the next auto-import. The
+// op-log watcher sets it before triggering the debounced import, and the
+// import consumes it to tag its events with the operation.
+var pendingImportOp struct {
+	mu sync.Mutex
+	id string
+}
+
+func setPendingImportOp(id string) {
+	pendingImportOp.mu.Lock()
+	defer pendingImportOp.mu.Unlock()
+	pendingImportOp.id = id
+}
+
+func takePendingImportOp() string {
+	pendingImportOp.mu.Lock()
+	defer pendingImportOp.mu.Unlock()
+	id := pendingImportOp.id
+	pendingImportOp.id = ""
+	return id
+}
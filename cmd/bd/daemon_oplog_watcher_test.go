@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestPendingImportOp(t *testing.T) {
+	if got := takePendingImportOp(); got != "" {
+		t.Fatalf("expected no pending op, got %q", got)
+	}
+
+	setPendingImportOp("aaa")
+	setPendingImportOp("bbb")
+	if got := takePendingImportOp(); got != "bbb" {
+		t.Errorf("takePendingImportOp() = %q, want latest op %q", got, "bbb")
+	}
+	if got := takePendingImportOp(); got != "" {
+		t.Errorf("pending op should be cleared after take, got %q", got)
+	}
+}
+
+func TestImportRecordsSourceOp(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), ".beads", "beads.db")
+	testStore := newTestStore(t, dbPath)
+
+	opID := "0123456789abcdef0123"
+	ctx := storage.WithSourceOp(context.Background(), opID)
+	issues := []*types.Issue{{ID: "test-1", Title: "Imported", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}}
+	if _, err := importIssuesCore(ctx, dbPath, testStore, issues, ImportOptions{}); err != nil {
+		t.Fatalf("importIssuesCore failed: %v", err)
+	}
+
+	events, err := testStore.GetEvents(context.Background(), "test-1", 10)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	if len(events) == 0 {
+		t.Fatal("expected import events")
+	}
+	for _, e := range events {
+		if e.Actor != "import" || e.SourceOp != opID {
+			t.Errorf("event %s: actor=%q source_op=%q, want import and %q", e.EventType, e.Actor, e.SourceOp, opID)
+		}
+	}
+}
+
+func TestOpLogWatcherTouchesBeads(t *testing.T) {
+	w := &OpLogWatcher{beadsRelDir: ".beads"}
+	tests := map[string]bool{
+		".beads/issues.jsonl":    true,
+		".beads/deps.jsonl":      true,
+		".beadsx/issues.jsonl":   false,
+		"src/.beads/issue.jsonl": false,
+		"README.md":              false,
+	}
+	for path, want := range tests {
+		if got := w.touchesBeads(path); got != want {
+			t.Errorf("touchesBeads(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	root := &OpLogWatcher{beadsRelDir: "."}
+	if !root.touchesBeads("issues.jsonl") {
+		t.Error("beads dir at repo root should match every path")
+	}
+}
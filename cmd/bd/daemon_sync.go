@@ -16,6 +16,7 @@ import (
 	"github.com/steveyegge/beads/internal/config"
 	"github.com/steveyegge/beads/internal/storage"
 	"github.com/steveyegge/beads/internal/storage/sqlite"
+	opdaemon "github.com/steveyegge/beads/internal/turso/daemon"
 	"github.com/steveyegge/beads/internal/types"
 )
 
@@ -91,6 +92,15 @@ func exportToJSONLWithStore(ctx context.Context, store storage.Storage, jsonlPat
 		issue.Comments = comments
 	}
 
+	// Populate attachment references for all issues
+	allAttachments, err := store.GetAllAttachments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get attachments: %w", err)
+	}
+	for _, issue := range issues {
+		issue.Attachments = allAttachments[issue.ID]
+	}
+
 	// Create temp file for atomic write
 	dir := filepath.Dir(jsonlPath)
 	base := filepath.Base(jsonlPath)
@@ -548,6 +558,23 @@ func performAutoImport(ctx context.Context, store storage.Storage, skipGit bool,
 			mode = "local auto-import"
 		}
 
+		// Tag events with the jj operation that triggered this import, if
+		// any, and remember it as processed only once the import succeeds
+		opID := takePendingImportOp()
+		imported := false
+		if opID != "" {
+			importCtx = storage.WithSourceOp(importCtx, opID)
+			mode += " (jj op " + opdaemon.ShortOpID(opID) + ")"
+			defer func() {
+				if !imported {
+					return
+				}
+				if err := store.SetMetadata(ctx, jjLastOpMetadataKey, opID); err != nil {
+					log.Warn("failed to record jj operation", "op", opdaemon.ShortOpID(opID), "error", err)
+				}
+			}()
+		}
+
 		// Check backoff before attempting sync (skip for local mode)
 		if !skipGit {
 			jsonlPath := findJSONLPath()
@@ -589,6 +616,7 @@ func performAutoImport(ctx context.Context, store storage.Storage, skipGit bool,
 		repoKey := getRepoKeyForPath(jsonlPath)
 		if !hasJSONLChanged(importCtx, store, jsonlPath, repoKey) {
 			log.log("Skipping %s: JSONL content unchanged", mode)
+			imported = true
 			return
 		}
 		log.log("JSONL content changed, proceeding with %s...", mode)
@@ -653,6 +681,7 @@ func performAutoImport(ctx context.Context, store storage.Storage, skipGit bool,
 			return
 		}
 
+		imported = true
 		if skipGit {
 			log.log("Local auto-import complete")
 		} else {
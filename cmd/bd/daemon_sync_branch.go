@@ -3,8 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
+	"errors"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -12,6 +12,7 @@ import (
 	"github.com/steveyegge/beads/internal/git"
 	"github.com/steveyegge/beads/internal/storage"
 	"github.com/steveyegge/beads/internal/syncbranch"
+	"github.com/steveyegge/beads/internal/vcs"
 )
 
 // syncBranchCommitAndPush commits JSONL to the sync branch using a worktree.
@@ -45,30 +46,21 @@ func syncBranchCommitAndPushWithOptions(ctx context.Context, store storage.Stora
 	log.log("Using sync branch: %s", syncBranch)
 	
 	// Get main repo root (for worktrees, this is the main repo, not worktree)
-	repoRoot, err := git.GetMainRepoRoot()
+	repoRoot, err := getSyncRepoRoot()
 	if err != nil {
 		return false, fmt.Errorf("failed to get main repo root: %w", err)
 	}
 	
-	// Use worktree-aware git directory detection
-	gitDir, err := git.GetGitDir()
+	// Ensure the sync worktree (git) or workspace (jj) exists and is healthy.
+	// Unhealthy worktrees are removed and recreated automatically.
+	worktreePath, err := syncbranch.EnsureSyncWorkspace(ctx, repoRoot, syncBranch)
 	if err != nil {
-		return false, fmt.Errorf("not a git repository: %w", err)
+		return false, err
 	}
 	
-	// Worktree path is under .git/beads-worktrees/<branch>
-	worktreePath := filepath.Join(gitDir, "beads-worktrees", syncBranch)
-	
 	// Initialize worktree manager
 	wtMgr := git.NewWorktreeManager(repoRoot)
 	
-	// Ensure worktree exists and is healthy
-	// CreateBeadsWorktree now performs a full health check internally and
-	// automatically repairs unhealthy worktrees by removing and recreating them
-	if err := wtMgr.CreateBeadsWorktree(syncBranch, worktreePath); err != nil {
-		return false, fmt.Errorf("failed to create worktree: %w", err)
-	}
-	
 	// Sync JSONL file to worktree
 	// Get the actual JSONL path
 	jsonlPath := findJSONLPath()
@@ -125,14 +117,39 @@ func syncBranchCommitAndPushWithOptions(ctx context.Context, store storage.Stora
 	return true, nil
 }
 
-// getGitRoot returns the git repository root directory
+// getSyncRepoRoot returns the main repository root. For git worktrees this
+// is the main repo, not the worktree; jj-only repos fall back to the
+// workspace root.
+func getSyncRepoRoot() (string, error) {
+	if root, err := git.GetMainRepoRoot(); err == nil {
+		return root, nil
+	}
+	v, err := openSyncVCS(".")
+	if err != nil {
+		return "", err
+	}
+	return v.RepoRoot()
+}
+
+// getGitRoot returns the repository root directory
 func getGitRoot(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
+	v, err := openSyncVCS(".")
 	if err != nil {
 		return "", fmt.Errorf("failed to get git root: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return v.RepoRoot()
+}
+
+// syncRemoteForBranch picks the remote for the sync branch: the configured
+// sync.remote, then the branch's tracking remote, then "origin".
+func syncRemoteForBranch(v vcs.VCS, branch, configuredRemote string) string {
+	if configuredRemote != "" {
+		return configuredRemote
+	}
+	if remote, err := v.Upstream(branch); err == nil && remote != "" {
+		return remote
+	}
+	return "origin"
 }
 
 // gitHasChangesInWorktree checks if there are changes in the worktree
@@ -143,12 +160,15 @@ func gitHasChangesInWorktree(ctx context.Context, worktreePath, filePath string)
 		return false, fmt.Errorf("failed to make path relative: %w", err)
 	}
 	
-	cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "status", "--porcelain", relPath) // #nosec G204 - worktreePath and relPath are derived from trusted git operations
-	output, err := cmd.Output()
+	v, err := openSyncVCS(worktreePath)
+	if err != nil {
+		return false, err
+	}
+	hasChanges, err := v.HasChanges(relPath)
 	if err != nil {
-		return false, fmt.Errorf("git status failed in worktree: %w", err)
+		return false, fmt.Errorf("status failed in worktree: %w", err)
 	}
-	return len(strings.TrimSpace(string(output))) > 0, nil
+	return hasChanges, nil
 }
 
 // gitCommitInWorktree commits changes in the worktree
@@ -159,18 +179,20 @@ func gitCommitInWorktree(ctx context.Context, worktreePath, filePath, message st
 		return fmt.Errorf("failed to make path relative: %w", err)
 	}
 	
-	// Stage the file
-	addCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "add", relPath) // #nosec G204 - worktreePath and relPath are derived from trusted git operations
-	if err := addCmd.Run(); err != nil {
-		return fmt.Errorf("git add failed in worktree: %w", err)
+	v, err := openSyncVCS(worktreePath)
+	if err != nil {
+		return err
 	}
 	
-	// Commit with --no-verify to skip hooks (pre-commit hook would fail in worktree context)
+	// Commit with NoVerify to skip hooks (pre-commit hook would fail in worktree context)
 	// The worktree is internal to bd sync, so we don't need to run bd's pre-commit hook
-	commitCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "commit", "--no-verify", "-m", message)
-	output, err := commitCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git commit failed in worktree: %w\n%s", err, output)
+	if err := v.Commit(ctx, vcs.CommitOptions{
+		Message:   message,
+		Paths:     []string{relPath},
+		NoVerify:  true,
+		CreateNew: true,
+	}); err != nil {
+		return fmt.Errorf("commit failed in worktree: %w", err)
 	}
 	
 	return nil
@@ -180,49 +202,37 @@ func gitCommitInWorktree(ctx context.Context, worktreePath, filePath, message st
 // If push fails due to remote having newer commits, it will fetch, rebase, and retry.
 // The configuredRemote parameter allows passing the bd config sync.remote value.
 func gitPushFromWorktree(ctx context.Context, worktreePath, branch, configuredRemote string) error {
-	// Use configured remote if provided, otherwise check git branch config
-	remote := configuredRemote
-	if remote == "" {
-		remoteCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "config", "--get", fmt.Sprintf("branch.%s.remote", branch)) // #nosec G204 - worktreePath and branch are from config
-		remoteOutput, err := remoteCmd.Output()
-		if err != nil {
-			// If no remote configured, default to "origin" and set up tracking
-			remoteOutput = []byte("origin\n")
-		}
-		remote = strings.TrimSpace(string(remoteOutput))
+	v, err := openSyncVCS(worktreePath)
+	if err != nil {
+		return err
 	}
 	
+	// Use configured remote if provided, otherwise check branch tracking config
+	remote := syncRemoteForBranch(v, branch, configuredRemote)
+	
 	// Push with explicit remote and branch, set upstream if not set
-	cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "push", "--set-upstream", remote, branch) // #nosec G204 - worktreePath, remote, and branch are from config
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Check if push failed due to remote having newer commits
-		outputStr := string(output)
-		if strings.Contains(outputStr, "fetch first") || strings.Contains(outputStr, "non-fast-forward") {
-			// Fetch and rebase, then retry push
-			fetchCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "fetch", remote, branch) // #nosec G204
-			if fetchOutput, fetchErr := fetchCmd.CombinedOutput(); fetchErr != nil {
-				return fmt.Errorf("git fetch failed in worktree: %w\n%s", fetchErr, fetchOutput)
-			}
-			
-			// Rebase local commits on top of remote
-			rebaseCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "rebase", remote+"/"+branch) // #nosec G204
-			if rebaseOutput, rebaseErr := rebaseCmd.CombinedOutput(); rebaseErr != nil {
-				// If rebase fails (conflict), abort and return error
-				abortCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "rebase", "--abort") // #nosec G204
-				_ = abortCmd.Run()
-				return fmt.Errorf("git rebase failed in worktree (sync branch may have conflicts): %w\n%s", rebaseErr, rebaseOutput)
-			}
-			
-			// Retry push after successful rebase
-			retryCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "push", "--set-upstream", remote, branch) // #nosec G204
-			if retryOutput, retryErr := retryCmd.CombinedOutput(); retryErr != nil {
-				return fmt.Errorf("git push failed after rebase: %w\n%s", retryErr, retryOutput)
-			}
-			
-			return nil
+	pushOpts := vcs.PushOptions{Remote: remote, Ref: branch, SetUpstream: true}
+	err = v.Push(ctx, pushOpts)
+	if err == nil {
+		return nil
+	}
+	
+	// Check if push failed due to remote having newer commits
+	if !errors.Is(err, vcs.ErrPushRejected) && !strings.Contains(err.Error(), "fetch first") {
+		return fmt.Errorf("push failed from worktree: %w", err)
+	}
+	
+	// Fetch and rebase local commits on top of remote, then retry push
+	if pullErr := v.Pull(ctx, vcs.PullOptions{Remote: remote, Ref: branch, Rebase: true}); pullErr != nil {
+		// If rebase fails (conflict), abort and return error
+		if v.Name() == vcs.TypeGit {
+			_, _ = v.Exec(ctx, "rebase", "--abort")
 		}
-		return fmt.Errorf("git push failed from worktree: %w\n%s", err, output)
+		return fmt.Errorf("rebase failed in worktree (sync branch may have conflicts): %w", pullErr)
+	}
+	
+	if retryErr := v.Push(ctx, pushOpts); retryErr != nil {
+		return fmt.Errorf("push failed after rebase: %w", retryErr)
 	}
 	
 	return nil
@@ -248,47 +258,29 @@ func syncBranchPull(ctx context.Context, store storage.Storage, log daemonLogger
 	}
 	
 	// Get main repo root (for worktrees, this is the main repo, not worktree)
-	repoRoot, err := git.GetMainRepoRoot()
+	repoRoot, err := getSyncRepoRoot()
 	if err != nil {
 		return false, fmt.Errorf("failed to get main repo root: %w", err)
 	}
 	
-	// Use worktree-aware git directory detection
-	gitDir, err := git.GetGitDir()
+	// Ensure worktree (git) or workspace (jj) exists
+	worktreePath, err := syncbranch.EnsureSyncWorkspace(ctx, repoRoot, syncBranch)
 	if err != nil {
-		return false, fmt.Errorf("not a git repository: %w", err)
+		return false, err
 	}
 	
-	// Worktree path is under .git/beads-worktrees/<branch>
-	worktreePath := filepath.Join(gitDir, "beads-worktrees", syncBranch)
-	
-	// Initialize worktree manager
-	wtMgr := git.NewWorktreeManager(repoRoot)
-	
-	// Ensure worktree exists
-	if err := wtMgr.CreateBeadsWorktree(syncBranch, worktreePath); err != nil {
-		return false, fmt.Errorf("failed to create worktree: %w", err)
-	}
-	
-	// Get remote name - check bd config first, then git branch config, then default to "origin"
-	remote := ""
-	if configuredRemote, err := store.GetConfig(ctx, "sync.remote"); err == nil && configuredRemote != "" {
-		remote = configuredRemote
-	} else {
-		remoteCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "config", "--get", fmt.Sprintf("branch.%s.remote", syncBranch)) // #nosec G204 - worktreePath and syncBranch are from config
-		remoteOutput, err := remoteCmd.Output()
-		if err != nil {
-			// If no remote configured, default to "origin"
-			remoteOutput = []byte("origin\n")
-		}
-		remote = strings.TrimSpace(string(remoteOutput))
+	v, err := openSyncVCS(worktreePath)
+	if err != nil {
+		return false, err
 	}
 	
+	// Get remote name - check bd config first, then branch tracking config, then default to "origin"
+	configuredRemote, _ := store.GetConfig(ctx, "sync.remote")
+	remote := syncRemoteForBranch(v, syncBranch, configuredRemote)
+	
 	// Pull in worktree
-	cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "pull", remote, syncBranch) // #nosec G204 - worktreePath, remote, and syncBranch are from config
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return false, fmt.Errorf("git pull failed in worktree: %w\n%s", err, output)
+	if err := v.Pull(ctx, vcs.PullOptions{Remote: remote, Ref: syncBranch}); err != nil {
+		return false, fmt.Errorf("pull failed in worktree: %w", err)
 	}
 	
 	log.log("Pulled sync branch %s", syncBranch)
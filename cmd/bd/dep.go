@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/capabilities"
 	"github.com/steveyegge/beads/internal/routing"
 	"github.com/steveyegge/beads/internal/rpc"
 	"github.com/steveyegge/beads/internal/storage"
@@ -631,7 +632,10 @@ Examples:
   bd dep tree gt-0iqq                    # Show what blocks gt-0iqq
   bd dep tree gt-0iqq --direction=up     # Show what gt-0iqq blocks
   bd dep tree gt-0iqq --status=open      # Only show open issues
-  bd dep tree gt-0iqq --depth=3          # Limit to 3 levels deep`,
+  bd dep tree gt-0iqq --depth=3          # Limit to 3 levels deep
+  bd dep tree gt-0iqq --format=dot       # Graphviz, one edge style per dependency type
+
+--format also accepts mermaid, d2 and json-graph (see bd graph).`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := rootCtx
@@ -726,6 +730,19 @@ Examples:
 			outputMermaidTree(tree, args[0])
 			return
 		}
+		if formatStr != "" {
+			if !isGraphFormat(formatStr) {
+				FatalErrorRespectJSON("invalid --format %q (valid: %s)", formatStr, strings.Join(graphFormats, ", "))
+			}
+			g, err := loadTreeGraph(ctx, store, tree, fullID)
+			if err != nil {
+				FatalErrorRespectJSON("%v", err)
+			}
+			if err := writeGraph(os.Stdout, g, formatStr); err != nil {
+				FatalErrorRespectJSON("%v", err)
+			}
+			return
+		}
 
 		if jsonOutput {
 			// Always output array, even if empty
@@ -1106,7 +1123,7 @@ func mergeBidirectionalTrees(downTree, upTree []*types.TreeNode, rootID string)
 }
 
 // validateExternalRef validates the format of an external dependency reference.
-// Valid format: external:<project>:<capability>
+// Valid format: external:<project>:<capability>[@<constraint>]
 func validateExternalRef(ref string) error {
 	if !strings.HasPrefix(ref, "external:") {
 		return fmt.Errorf("external reference must start with 'external:'")
@@ -1126,6 +1143,14 @@ func validateExternalRef(ref string) error {
 	if capability == "" {
 		return fmt.Errorf("external reference missing capability name")
 	}
+	if name, constraint, ok := strings.Cut(capability, "@"); ok {
+		if name == "" {
+			return fmt.Errorf("external reference missing capability name")
+		}
+		if _, err := capabilities.ParseConstraint(constraint); err != nil {
+			return fmt.Errorf("external reference has %v", err)
+		}
+	}
 
 	return nil
 }
@@ -1161,7 +1186,7 @@ func init() {
 	depTreeCmd.Flags().Bool("reverse", false, "Show dependent tree (deprecated: use --direction=up)")
 	depTreeCmd.Flags().String("direction", "", "Tree direction: 'down' (dependencies), 'up' (dependents), or 'both'")
 	depTreeCmd.Flags().String("status", "", "Filter to only show issues with this status (open, in_progress, blocked, deferred, closed)")
-	depTreeCmd.Flags().String("format", "", "Output format: 'mermaid' for Mermaid.js flowchart")
+	depTreeCmd.Flags().String("format", "", "Output format: 'mermaid' for Mermaid.js flowchart, or 'dot', 'd2', 'json-graph'")
 	depTreeCmd.Flags().StringP("type", "t", "", "Filter to only show dependencies of this type (e.g., tracks, blocks, parent-child)")
 
 	depListCmd.Flags().String("direction", "down", "Direction: 'down' (dependencies), 'up' (dependents)")
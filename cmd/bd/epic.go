@@ -50,6 +50,10 @@ var epicStatusCmd = &cobra.Command{
 				epics = filtered
 			}
 		}
+		usage := loadUsageForDisplay(rootCtx)
+		for _, epicStatus := range epics {
+			epicStatus.Usage = usage.rollup(epicStatus.Epic.ID)
+		}
 		if jsonOutput {
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
@@ -80,6 +84,12 @@ var epicStatusCmd = &cobra.Command{
 			fmt.Printf("%s %s %s\n", statusIcon, ui.RenderAccent(epic.ID), ui.RenderBold(epic.Title))
 			fmt.Printf("   Progress: %d/%d children closed (%d%%)\n",
 				epicStatus.ClosedChildren, epicStatus.TotalChildren, percentage)
+			if epic.BudgetUSD != nil {
+				fmt.Printf("   Budget: %s\n", formatBudget(epic, epicStatus.Usage))
+			}
+			if epicStatus.Usage != nil {
+				fmt.Printf("   Usage: %s\n", formatUsage(epicStatus.Usage))
+			}
 			if epicStatus.EligibleForClose {
 				fmt.Printf("   %s\n", ui.RenderPass("Eligible for closure"))
 			}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/beads/internal/debug"
+	"github.com/steveyegge/beads/internal/formats"
 	"github.com/steveyegge/beads/internal/storage/sqlite"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/util"
@@ -114,21 +115,43 @@ func validateExportPath(path string) error {
 var exportCmd = &cobra.Command{
 	Use:     "export",
 	GroupID: "sync",
-	Short:   "Export issues to JSONL or Obsidian format",
-	Long: `Export all issues to JSON Lines or Obsidian Tasks markdown format.
+	Short:   "Export issues to JSONL, Obsidian or a static HTML site",
+	Long: `Export all issues to JSON Lines, Obsidian Tasks markdown format, or a static HTML site.
 Issues are sorted by ID for consistent diffs.
 
 Output to stdout by default, or use -o flag for file output.
 For obsidian format, defaults to ai_docs/changes-log.md
+For html format, -o names a directory (default: site)
+For markdown-dir format, -o names a directory (default: issues)
 
 Formats:
   jsonl     - JSON Lines format (one JSON object per line) [default]
   obsidian  - Obsidian Tasks markdown format with checkboxes, priorities, dates
+  html      - Self-contained read-only site: filterable index, one page per
+              issue with dependencies, comments and history, epic progress,
+              and client-side search. Open index.html or publish the directory.
+  csv       - Spreadsheet with a header row; pick columns with --columns
+  markdown-dir - One .md file per issue with YAML front matter (status,
+              priority, labels, deps) and description/design/acceptance/notes
+              sections. Edit the files and apply them with
+              'bd import --format markdown-dir -i <dir>'.
+
+More formats can be added without changing bd: an executable named
+bd-format-<name> on PATH provides --format <name>. It is run as
+'bd-format-<name> export' with JSONL issues on stdin and writes the output
+to stdout; --param key=value settings are passed as arguments, and
+'bd-format-<name> capabilities' prints its capabilities as JSON.
+--list-formats shows every available format.
 
 Examples:
   bd export --status open -o open-issues.jsonl
   bd export --format obsidian                    # outputs to ai_docs/changes-log.md
   bd export --format obsidian -o custom.md       # outputs to custom.md
+  bd export --format html -o site/               # static site in site/
+  bd export --format html --status open -o public/
+  bd export --format markdown-dir -o issues/
+  bd export --format csv --columns id,title,status,assignee,labels -o issues.csv
+  bd export --list-formats
   bd export --type bug --priority-max 1
   bd export --created-after 2025-01-01 --assignee alice`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -152,15 +175,46 @@ Examples:
 
 		debug.Logf("Debug: export flags - output=%q, force=%v\n", output, force)
 
-		if format != "jsonl" && format != "obsidian" {
-			fmt.Fprintf(os.Stderr, "Error: format must be 'jsonl' or 'obsidian'\n")
+		if listFormats, _ := cmd.Flags().GetBool("list-formats"); listFormats {
+			printFormats(formats.Available())
+			return
+		}
+		f, err := formats.LookupExporter(format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		params, err := formatParams(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if csvColumns, _ := cmd.Flags().GetStringSlice("columns"); len(csvColumns) > 0 {
+			if err := validateCSVColumns(csvColumns); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			params["columns"] = strings.Join(csvColumns, ",")
+		}
 
 		// Default output path for obsidian format
 		if format == "obsidian" && output == "" {
 			output = "ai_docs/changes-log.md"
 		}
+		// HTML builds a site directory, never stdout
+		if format == "html" && output == "" {
+			output = "site"
+		}
+		if format == "markdown-dir" && output == "" {
+			output = "issues"
+		}
+		if !f.Capabilities().Streaming && output == "" {
+			fmt.Fprintf(os.Stderr, "Error: --format %s writes a directory, use -o <dir>\n", format)
+			os.Exit(1)
+		}
+		// Only JSONL replaces the sync file, so the safety checks and dirty
+		// tracking below apply to it alone
+		syncFormat := format == "jsonl"
 
 		// Export command requires direct database access for consistent snapshot
 		// If daemon is connected, close it and open direct connection
@@ -294,7 +348,7 @@ Examples:
 		}
 
 		// Safety check: prevent exporting empty database over non-empty JSONL
-		if len(issues) == 0 && output != "" && !force {
+		if len(issues) == 0 && output != "" && syncFormat && !force {
 			existingCount, err := countIssuesInJSONL(output)
 			if err != nil {
 				// If we can't read the file, it might not exist yet, which is fine
@@ -313,7 +367,7 @@ Examples:
 		}
 
 		// Safety check: prevent exporting stale database that would lose issues
-		if output != "" && !force {
+		if output != "" && syncFormat && !force {
 			debug.Logf("Debug: checking staleness - output=%s, force=%v\n", output, force)
 
 			// Read existing JSONL to get issue IDs
@@ -406,6 +460,23 @@ Examples:
 			issue.Labels = labels
 		}
 
+		// Populate attachment references for all issues
+		allAttachments, err := store.GetAllAttachments(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting attachments: %v\n", err)
+			os.Exit(1)
+		}
+		for _, issue := range issues {
+			issue.Attachments = allAttachments[issue.ID]
+		}
+
+		formatOpts := formats.Options{Store: store, Params: params}
+		formatOpts.Prefix, _ = store.GetConfig(ctx, "issue_prefix")
+		if !f.Capabilities().Streaming {
+			exportToDir(ctx, f, issues, output, formatOpts)
+			return
+		}
+
 		// Open output
 		out := os.Stdout
 		var tempFile *os.File
@@ -452,25 +523,12 @@ Examples:
 		exportedIDs := make([]string, 0, len(issues))
 		skippedCount := 0
 
-		if format == "obsidian" {
-			// Write Obsidian Tasks markdown format
-			if err := writeObsidianExport(out, issues); err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing Obsidian export: %v\n", err)
-				os.Exit(1)
-			}
-			for _, issue := range issues {
-				exportedIDs = append(exportedIDs, issue.ID)
-			}
-		} else {
-			// Write JSONL (timestamp-only deduplication DISABLED due to bd-160)
-			encoder := json.NewEncoder(out)
-			for _, issue := range issues {
-				if err := encoder.Encode(issue); err != nil {
-					fmt.Fprintf(os.Stderr, "Error encoding issue %s: %v\n", issue.ID, err)
-					os.Exit(1)
-				}
-				exportedIDs = append(exportedIDs, issue.ID)
-			}
+		if err := formats.Encode(ctx, f, out, issues, formatOpts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s export: %v\n", format, err)
+			os.Exit(1)
+		}
+		for _, issue := range issues {
+			exportedIDs = append(exportedIDs, issue.ID)
 		}
 
 		// Report skipped issues if any (helps debugging bd-159)
@@ -480,7 +538,7 @@ Examples:
 
 		// Only clear dirty issues and auto-flush state if exporting to the default JSONL path
 		// This prevents clearing dirty flags when exporting to custom paths (e.g., bd export -o backup.jsonl)
-		if output == "" || output == findJSONLPath() {
+		if syncFormat && (output == "" || output == findJSONLPath()) {
 			// Clear only the issues that were actually exported (fixes bd-52 race condition)
 			if err := store.ClearDirtyIssuesByID(ctx, exportedIDs); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to clear dirty issues: %v\n", err)
@@ -546,7 +604,7 @@ Examples:
 			// Update database mtime to be >= JSONL mtime (fixes #278, #301, #321)
 			// Only do this when exporting to default JSONL path (not arbitrary outputs)
 			// This prevents validatePreExport from incorrectly blocking on next export
-			if output == "" || output == findJSONLPath() {
+			if syncFormat && (output == "" || output == findJSONLPath()) {
 				beadsDir := filepath.Dir(finalPath)
 				dbPath := filepath.Join(beadsDir, "beads.db")
 				if err := TouchDatabaseFile(dbPath, finalPath); err != nil {
@@ -574,8 +632,11 @@ Examples:
 }
 
 func init() {
-	exportCmd.Flags().StringP("format", "f", "jsonl", "Export format: jsonl, obsidian")
-	exportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	exportCmd.Flags().StringP("format", "f", "jsonl", "Export format: jsonl, obsidian, html, markdown-dir, csv, or an external bd-format-<name> (see --list-formats)")
+	exportCmd.Flags().StringSlice("columns", nil, "Columns for --format csv (default: "+strings.Join(defaultCSVColumns, ",")+")")
+	exportCmd.Flags().StringArray("param", nil, "Format parameter as key=value (repeatable)")
+	exportCmd.Flags().Bool("list-formats", false, "List available formats and their capabilities")
+	exportCmd.Flags().StringP("output", "o", "", "Output file, or directory for html and markdown-dir (default: stdout)")
 	exportCmd.Flags().StringP("status", "s", "", "Filter by status")
 	exportCmd.Flags().Bool("force", false, "Force export even if database is empty")
 	exportCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output export statistics in JSON format")
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// defaultCSVColumns is the column set of bd export --format csv.
+var defaultCSVColumns = []string{
+	"id", "title", "status", "priority", "type", "assignee", "labels", "parent", "external_ref", "created_at", "updated_at",
+}
+
+// csvExportColumns are the columns bd export --format csv can write.
+var csvExportColumns = append(append([]string(nil), csvFields...), "updated_at", "closed_at", "close_reason", "dependencies")
+
+// validateCSVColumns checks --columns against the known columns.
+func validateCSVColumns(columns []string) error {
+	for _, c := range columns {
+		known := false
+		for _, k := range csvExportColumns {
+			if c == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown column %q (valid: %s)", c, strings.Join(csvExportColumns, ", "))
+		}
+	}
+	return nil
+}
+
+// writeCSVExport writes issues as CSV with a header row. Issues must have
+// labels and dependency records populated. The column names are the field
+// names bd import --format csv understands, so an export re-imports as is.
+func writeCSVExport(w io.Writer, issues []*types.Issue, columns []string) error {
+	if len(columns) == 0 {
+		columns = defaultCSVColumns
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = csvCell(issue, c)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvCell(issue *types.Issue, column string) string {
+	formatTime := func(t *time.Time) string {
+		if t == nil || t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	switch column {
+	case "id":
+		return issue.ID
+	case "title":
+		return issue.Title
+	case "description":
+		return issue.Description
+	case "design":
+		return issue.Design
+	case "acceptance_criteria":
+		return issue.AcceptanceCriteria
+	case "notes":
+		return issue.Notes
+	case "status":
+		return string(issue.Status)
+	case "priority":
+		return strconv.Itoa(issue.Priority)
+	case "type":
+		return string(issue.IssueType)
+	case "assignee":
+		return issue.Assignee
+	case "labels":
+		return strings.Join(issue.Labels, ",")
+	case "parent":
+		for _, dep := range issue.Dependencies {
+			if dep.Type == types.DepParentChild {
+				return dep.DependsOnID
+			}
+		}
+		return ""
+	case "dependencies":
+		var deps []string
+		for _, dep := range issue.Dependencies {
+			if dep.Type != types.DepParentChild {
+				deps = append(deps, string(dep.Type)+":"+dep.DependsOnID)
+			}
+		}
+		return strings.Join(deps, ",")
+	case "external_ref":
+		if issue.ExternalRef != nil {
+			return *issue.ExternalRef
+		}
+		return ""
+	case "created_at":
+		return formatTime(&issue.CreatedAt)
+	case "updated_at":
+		return formatTime(&issue.UpdatedAt)
+	case "closed_at":
+		return formatTime(issue.ClosedAt)
+	case "close_reason":
+		return issue.CloseReason
+	}
+	return ""
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+//go:embed templates/site/*
+var siteFS embed.FS
+
+// htmlSite is the data behind a static HTML export: every page is rendered
+// from it, so the site can be built anywhere the database can be read (CI).
+type htmlSite struct {
+	Name      string
+	Generated string
+	Count     int
+
+	issues     []*types.Issue
+	byID       map[string]*types.Issue
+	dependents map[string][]*types.Dependency
+	children   map[string][]string
+	comments   map[string][]*types.Comment
+	history    map[string][]*types.Event
+}
+
+// htmlPage is the data passed to one page template.
+type htmlPage struct {
+	Site  *htmlSite
+	Title string
+	Root  string // relative path back to the site root
+
+	// index
+	Issues     []*types.Issue
+	Statuses   []types.Status
+	Types      []types.IssueType
+	Priorities []int
+	Labels     []string
+	Assignees  []string
+
+	// epics
+	Epics []*htmlEpic
+
+	// issue
+	Issue      *types.Issue
+	Epic       *htmlEpic
+	DependsOn  []htmlRef
+	Dependents []htmlRef
+	Comments   []*types.Comment
+	History    []*types.Event
+}
+
+// htmlRef is a link to another issue, which may not be part of the site
+// (filtered out, or in another repo).
+type htmlRef struct {
+	ID     string
+	Title  string
+	Href   string
+	Linked bool
+	Status types.Status
+	Type   types.DependencyType
+}
+
+// htmlEpic is an epic with its direct children and how many are closed.
+type htmlEpic struct {
+	Issue    *types.Issue
+	Children []htmlRef
+	Closed   int
+	Total    int
+	Percent  int
+}
+
+// searchDoc is one entry of the client-side search index.
+type searchDoc struct {
+	ID     string       `json:"id"`
+	Title  string       `json:"title"`
+	Status types.Status `json:"status"`
+	URL    string       `json:"url"`
+	Text   string       `json:"text"`
+}
+
+// buildHTMLSite loads comments, history and reverse dependencies for issues.
+// Issues must already carry their labels and dependency records, as the
+// export command populates them.
+func buildHTMLSite(ctx context.Context, s storage.Storage, name string, issues []*types.Issue) (*htmlSite, error) {
+	site := &htmlSite{
+		Name:       name,
+		Generated:  time.Now().UTC().Format("2006-01-02 15:04 UTC"),
+		Count:      len(issues),
+		issues:     issues,
+		byID:       make(map[string]*types.Issue, len(issues)),
+		dependents: make(map[string][]*types.Dependency),
+		children:   make(map[string][]string),
+		history:    make(map[string][]*types.Event),
+	}
+	ids := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		site.byID[issue.ID] = issue
+		ids = append(ids, issue.ID)
+	}
+	for _, issue := range issues {
+		for _, dep := range issue.Dependencies {
+			site.dependents[dep.DependsOnID] = append(site.dependents[dep.DependsOnID], dep)
+			if dep.Type == types.DepParentChild {
+				site.children[dep.DependsOnID] = append(site.children[dep.DependsOnID], issue.ID)
+			}
+		}
+	}
+
+	comments, err := s.GetCommentsForIssues(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load comments: %w", err)
+	}
+	site.comments = comments
+	for _, id := range ids {
+		events, err := s.GetEvents(ctx, id, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load history of %s: %w", id, err)
+		}
+		// Newest first; events from the same second keep insertion order
+		sort.SliceStable(events, func(i, j int) bool {
+			if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+				return events[i].CreatedAt.After(events[j].CreatedAt)
+			}
+			return events[i].ID > events[j].ID
+		})
+		site.history[id] = events
+	}
+	return site, nil
+}
+
+// sitePageName is the file name of an issue page. IDs are already
+// filename-safe in practice; anything unexpected is replaced.
+func sitePageName(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, id) + ".html"
+}
+
+func (site *htmlSite) ref(id string, depType types.DependencyType, root string) htmlRef {
+	r := htmlRef{ID: id, Type: depType}
+	if issue := site.byID[id]; issue != nil {
+		r.Title = issue.Title
+		r.Status = issue.Status
+		r.Href = root + "issues/" + sitePageName(id)
+		r.Linked = true
+	}
+	return r
+}
+
+func (site *htmlSite) epic(issue *types.Issue, root string) *htmlEpic {
+	e := &htmlEpic{Issue: issue}
+	childIDs := append([]string(nil), site.children[issue.ID]...)
+	sort.Strings(childIDs)
+	for _, id := range childIDs {
+		ref := site.ref(id, types.DepParentChild, root)
+		e.Children = append(e.Children, ref)
+		e.Total++
+		if ref.Status == types.StatusClosed {
+			e.Closed++
+		}
+	}
+	if e.Total > 0 {
+		e.Percent = e.Closed * 100 / e.Total
+	}
+	return e
+}
+
+// write renders the site into dir: index.html, epics.html, one page per
+// issue under issues/, the search index and the static assets. Pages for
+// issues that no longer exist are removed.
+func (site *htmlSite) write(dir string) error {
+	tmpl, err := template.New("site").Funcs(template.FuncMap{
+		"join":      strings.Join,
+		"lower":     strings.ToLower,
+		"pageName":  sitePageName,
+		"date":      siteDate,
+		"eventText": siteEventText,
+	}).ParseFS(siteFS, "templates/site/*.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse site templates: %w", err)
+	}
+
+	issuesDir := filepath.Join(dir, "issues")
+	if err := os.MkdirAll(issuesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", issuesDir, err)
+	}
+	render := func(name, path string, page *htmlPage) error {
+		page.Site = site
+		var b strings.Builder
+		if err := tmpl.ExecuteTemplate(&b, name, page); err != nil {
+			return fmt.Errorf("failed to render %s: %w", path, err)
+		}
+		// #nosec G306 -- the site is meant to be published
+		return os.WriteFile(path, []byte(b.String()), 0644)
+	}
+
+	if err := render("index.html.tmpl", filepath.Join(dir, "index.html"), site.indexPage()); err != nil {
+		return err
+	}
+
+	epicsPage := &htmlPage{Title: "Epics"}
+	for _, issue := range site.issues {
+		if issue.IssueType == types.TypeEpic {
+			epicsPage.Epics = append(epicsPage.Epics, site.epic(issue, ""))
+		}
+	}
+	if err := render("epics.html.tmpl", filepath.Join(dir, "epics.html"), epicsPage); err != nil {
+		return err
+	}
+
+	written := make(map[string]bool, len(site.issues))
+	for _, issue := range site.issues {
+		name := sitePageName(issue.ID)
+		written[name] = true
+		if err := render("issue.html.tmpl", filepath.Join(issuesDir, name), site.issuePage(issue)); err != nil {
+			return err
+		}
+	}
+	if entries, err := os.ReadDir(issuesDir); err == nil {
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".html") && !written[entry.Name()] {
+				_ = os.Remove(filepath.Join(issuesDir, entry.Name()))
+			}
+		}
+	}
+
+	if err := site.writeSearchIndex(filepath.Join(dir, "search-index.js")); err != nil {
+		return err
+	}
+	for _, asset := range []string{"style.css", "app.js"} {
+		data, err := siteFS.ReadFile("templates/site/" + asset)
+		if err != nil {
+			return err
+		}
+		// #nosec G306 -- the site is meant to be published
+		if err := os.WriteFile(filepath.Join(dir, asset), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (site *htmlSite) indexPage() *htmlPage {
+	page := &htmlPage{Title: "Issues", Issues: site.issues}
+	statuses := make(map[types.Status]bool)
+	issueTypes := make(map[types.IssueType]bool)
+	priorities := make(map[int]bool)
+	labels := make(map[string]bool)
+	assignees := make(map[string]bool)
+	for _, issue := range site.issues {
+		statuses[issue.Status] = true
+		issueTypes[issue.IssueType] = true
+		priorities[issue.Priority] = true
+		for _, l := range issue.Labels {
+			labels[l] = true
+		}
+		if issue.Assignee != "" {
+			assignees[issue.Assignee] = true
+		}
+	}
+	for s := range statuses {
+		page.Statuses = append(page.Statuses, s)
+	}
+	for t := range issueTypes {
+		page.Types = append(page.Types, t)
+	}
+	for p := range priorities {
+		page.Priorities = append(page.Priorities, p)
+	}
+	for l := range labels {
+		page.Labels = append(page.Labels, l)
+	}
+	for a := range assignees {
+		page.Assignees = append(page.Assignees, a)
+	}
+	sort.Slice(page.Statuses, func(i, j int) bool { return page.Statuses[i] < page.Statuses[j] })
+	sort.Slice(page.Types, func(i, j int) bool { return page.Types[i] < page.Types[j] })
+	sort.Ints(page.Priorities)
+	sort.Strings(page.Labels)
+	sort.Strings(page.Assignees)
+	return page
+}
+
+func (site *htmlSite) issuePage(issue *types.Issue) *htmlPage {
+	const root = "../"
+	page := &htmlPage{
+		Title:    issue.ID + " " + issue.Title,
+		Root:     root,
+		Issue:    issue,
+		Comments: site.comments[issue.ID],
+		History:  site.history[issue.ID],
+	}
+	if issue.IssueType == types.TypeEpic || len(site.children[issue.ID]) > 0 {
+		page.Epic = site.epic(issue, root)
+	}
+	for _, dep := range issue.Dependencies {
+		page.DependsOn = append(page.DependsOn, site.ref(dep.DependsOnID, dep.Type, root))
+	}
+	for _, dep := range site.dependents[issue.ID] {
+		if dep.Type == types.DepParentChild {
+			continue // listed under progress
+		}
+		page.Dependents = append(page.Dependents, site.ref(dep.IssueID, dep.Type, root))
+	}
+	sort.Slice(page.Dependents, func(i, j int) bool { return page.Dependents[i].ID < page.Dependents[j].ID })
+	return page
+}
+
+// writeSearchIndex writes the search index as a script rather than JSON so
+// the site works from file:// URLs, where fetch is not allowed.
+func (site *htmlSite) writeSearchIndex(path string) error {
+	docs := make([]searchDoc, 0, len(site.issues))
+	for _, issue := range site.issues {
+		text := []string{issue.ID, issue.Title, issue.Description, issue.Notes, issue.Assignee, strings.Join(issue.Labels, " ")}
+		docs = append(docs, searchDoc{
+			ID:     issue.ID,
+			Title:  issue.Title,
+			Status: issue.Status,
+			URL:    "issues/" + sitePageName(issue.ID),
+			Text:   strings.ToLower(strings.Join(text, " ")),
+		})
+	}
+	data, err := json.Marshal(docs)
+	if err != nil {
+		return err
+	}
+	content := "window.BD_SEARCH_INDEX = " + string(data) + ";\n"
+	// #nosec G306 -- the site is meant to be published
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// siteDate formats a time or *time.Time for display.
+func siteDate(v interface{}) string {
+	switch t := v.(type) {
+	case time.Time:
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format("2006-01-02 15:04")
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return siteDate(*t)
+	default:
+		return ""
+	}
+}
+
+// siteEventText describes a history event in one line.
+func siteEventText(e *types.Event) string {
+	value := func(p *string) string {
+		if p == nil {
+			return ""
+		}
+		return *p
+	}
+	switch e.EventType {
+	case types.EventCreated:
+		return "created the issue"
+	case types.EventStatusChanged:
+		return fmt.Sprintf("changed status from %s to %s", value(e.OldValue), value(e.NewValue))
+	case types.EventClosed:
+		if reason := value(e.Comment); reason != "" {
+			return "closed the issue: " + reason
+		}
+		return "closed the issue"
+	case types.EventReopened:
+		return "reopened the issue"
+	case types.EventCommented:
+		return "commented"
+	default:
+		// Dependency and label events carry their own description
+		if text := value(e.Comment); text != "" {
+			return text
+		}
+		return strings.ReplaceAll(string(e.EventType), "_", " ")
+	}
+}
+
+// writeHTMLSite builds the site for issues into dir. Tombstones are
+// dropped: the site is for people reading the tracker, not for sync.
+func writeHTMLSite(ctx context.Context, s storage.Storage, prefix string, issues []*types.Issue, dir string) error {
+	visible := make([]*types.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Status != types.StatusTombstone {
+			visible = append(visible, issue)
+		}
+	}
+
+	name := "Issues"
+	if prefix != "" {
+		name = prefix + " issues"
+	}
+	site, err := buildHTMLSite(ctx, s, name, visible)
+	if err != nil {
+		return fmt.Errorf("failed to build site: %w", err)
+	}
+	return site.write(dir)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestHTMLSiteExport(t *testing.T) {
+	tmpDir := t.TempDir()
+	s := newTestStore(t, filepath.Join(tmpDir, ".beads", "beads.db"))
+	ctx := context.Background()
+
+	epic := &types.Issue{Title: "Launch", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeEpic}
+	child := &types.Issue{Title: "Write <docs>", Status: types.StatusClosed, Priority: 2, IssueType: types.TypeTask}
+	other := &types.Issue{Title: "Polish", Status: types.StatusOpen, Priority: 3, IssueType: types.TypeTask}
+	for _, issue := range []*types.Issue{epic, child, other} {
+		if err := s.CreateIssue(ctx, issue, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dep := range []*types.Dependency{
+		{IssueID: child.ID, DependsOnID: epic.ID, Type: types.DepParentChild},
+		{IssueID: other.ID, DependsOnID: epic.ID, Type: types.DepParentChild},
+		{IssueID: other.ID, DependsOnID: child.ID, Type: types.DepBlocks},
+	} {
+		if err := s.AddDependency(ctx, dep, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.AddIssueComment(ctx, other.ID, "alice", "Needs <script>review</script>"); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []*types.Issue{epic, child, other}
+	allDeps, err := s.GetAllDependencyRecords(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		issue.Dependencies = allDeps[issue.ID]
+	}
+
+	site, err := buildHTMLSite(ctx, s, "test issues", issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(tmpDir, "site")
+	stale := filepath.Join(out, "issues", "test-gone.html")
+	if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := site.write(out); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(rel string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(out, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	if got := strings.Count(index, "<tr data-status"); got != 3 {
+		t.Errorf("index has %d issue rows, want 3", got)
+	}
+	if !strings.Contains(index, "Write &lt;docs&gt;") {
+		t.Error("index does not escape titles")
+	}
+
+	page := read(filepath.Join("issues", sitePageName(other.ID)))
+	for _, want := range []string{
+		`href="../issues/` + sitePageName(child.ID) + `"`,
+		"Needs &lt;script&gt;review&lt;/script&gt;",
+		"created the issue",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("issue page is missing %q", want)
+		}
+	}
+
+	if epics := read("epics.html"); !strings.Contains(epics, "1/2 closed (50%)") {
+		t.Error("epics page does not show progress")
+	}
+	if idx := read("search-index.js"); !strings.HasPrefix(idx, "window.BD_SEARCH_INDEX = [") || !strings.Contains(idx, `"title":"Polish"`) {
+		t.Errorf("search index = %.100s", idx)
+	}
+	for _, asset := range []string{"style.css", "app.js"} {
+		read(asset)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("page for a removed issue was not cleaned up")
+	}
+}
+
+func TestSitePageName(t *testing.T) {
+	if got := sitePageName("bd-a1b.2"); got != "bd-a1b.2.html" {
+		t.Errorf("sitePageName = %q", got)
+	}
+	if got := sitePageName("x/../y"); strings.Contains(got, "/") {
+		t.Errorf("sitePageName kept a path separator: %q", got)
+	}
+}
@@ -50,6 +50,21 @@ func TestValidateExternalRef(t *testing.T) {
 			ref:     "bd-xyz",
 			wantErr: true,
 		},
+		{
+			name:    "version constraint",
+			ref:     "external:beads:api@>=2,<3",
+			wantErr: false,
+		},
+		{
+			name:    "invalid version constraint",
+			ref:     "external:beads:api@latest",
+			wantErr: true,
+		},
+		{
+			name:    "constraint without capability",
+			ref:     "external:beads:@2",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
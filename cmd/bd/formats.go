@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/formats"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// The formats implemented in this package. jsonl lives in internal/formats.
+func init() {
+	formats.Register(obsidianFormat{})
+	formats.Register(htmlFormat{})
+	formats.Register(markdownDirFormat{})
+	formats.Register(csvFormat{})
+}
+
+// formatParams reads the repeatable --param key=value flag.
+func formatParams(cmd *cobra.Command) (map[string]string, error) {
+	raw, _ := cmd.Flags().GetStringArray("param")
+	params := make(map[string]string, len(raw))
+	for _, p := range raw {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --param %q (expected key=value)", p)
+		}
+		params[strings.TrimSpace(key)] = value
+	}
+	return params, nil
+}
+
+// printFormats lists formats for --list-formats.
+func printFormats(list []formats.Format) {
+	type formatInfo struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		External    string `json:"external,omitempty"`
+		formats.Capabilities
+	}
+	if jsonOutput {
+		infos := make([]formatInfo, 0, len(list))
+		for _, f := range list {
+			info := formatInfo{Name: f.Name(), Description: f.Description(), Capabilities: f.Capabilities()}
+			if ext, ok := f.(*formats.External); ok {
+				info.External = ext.Path()
+			}
+			infos = append(infos, info)
+		}
+		outputJSON(infos)
+		return
+	}
+	for _, f := range list {
+		caps := f.Capabilities()
+		var flags []string
+		if caps.Export {
+			flags = append(flags, "export")
+		}
+		if caps.Import {
+			flags = append(flags, "import")
+		}
+		if caps.Streaming {
+			flags = append(flags, "stream")
+		} else {
+			flags = append(flags, "directory")
+		}
+		if caps.RoundTrip {
+			flags = append(flags, "round-trip")
+		}
+		if caps.Lossy {
+			flags = append(flags, "lossy")
+		}
+		fmt.Printf("%-14s %-52s %s\n", f.Name(), f.Description(), strings.Join(flags, ", "))
+	}
+}
+
+// exportToDir is the bd export branch for directory formats.
+func exportToDir(ctx context.Context, f formats.Format, issues []*types.Issue, dir string, opts formats.Options) {
+	if err := validateExportPath(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := formats.EncodeDir(ctx, f, dir, issues, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s export: %v\n", f.Name(), err)
+		os.Exit(1)
+	}
+	exported := 0
+	for _, issue := range issues {
+		if issue.Status != types.StatusTombstone {
+			exported++
+		}
+	}
+	if jsonOutput {
+		data, _ := json.MarshalIndent(map[string]interface{}{
+			"success":    true,
+			"exported":   exported,
+			"output_dir": dir,
+		}, "", "  ")
+		fmt.Fprintln(os.Stderr, string(data))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d issues to %s/\n", exported, strings.TrimSuffix(dir, "/"))
+}
+
+// obsidianFormat is a changelog in Obsidian Tasks markdown.
+type obsidianFormat struct{}
+
+func (obsidianFormat) Name() string        { return "obsidian" }
+func (obsidianFormat) Description() string { return "Obsidian Tasks markdown changelog" }
+
+func (obsidianFormat) Capabilities() formats.Capabilities {
+	return formats.Capabilities{Export: true, Streaming: true, Lossy: true}
+}
+
+func (obsidianFormat) Encode(_ context.Context, w io.Writer, issues []*types.Issue, _ formats.Options) error {
+	return writeObsidianExport(w, issues)
+}
+
+// htmlFormat is a static site: index, one page per issue, epics, search.
+type htmlFormat struct{}
+
+func (htmlFormat) Name() string        { return "html" }
+func (htmlFormat) Description() string { return "Static HTML site with search (directory)" }
+
+func (htmlFormat) Capabilities() formats.Capabilities {
+	return formats.Capabilities{Export: true, Lossy: true}
+}
+
+func (htmlFormat) EncodeDir(ctx context.Context, dir string, issues []*types.Issue, opts formats.Options) error {
+	if opts.Store == nil {
+		return fmt.Errorf("html export needs the database for comments and history")
+	}
+	return writeHTMLSite(ctx, opts.Store, opts.Prefix, issues, dir)
+}
+
+// markdownDirFormat is one markdown file per issue. bd import applies these
+// files itself (importMarkdownDir) to detect conflicts and rewrite them;
+// DecodeDir is the plain reading used by the daemon.
+type markdownDirFormat struct{}
+
+func (markdownDirFormat) Name() string        { return "markdown-dir" }
+func (markdownDirFormat) Description() string { return "One markdown file per issue (directory)" }
+
+func (markdownDirFormat) Capabilities() formats.Capabilities {
+	return formats.Capabilities{Export: true, Import: true, Lossy: true}
+}
+
+func (markdownDirFormat) EncodeDir(_ context.Context, dir string, issues []*types.Issue, _ formats.Options) error {
+	_, err := writeMarkdownDir(dir, issues)
+	return err
+}
+
+// DecodeDir reads every *.md file in dir. A file's updated_at becomes the
+// issue's, so the importer only applies files newer than the database.
+func (markdownDirFormat) DecodeDir(ctx context.Context, dir string, opts formats.Options) ([]*types.Issue, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	var issues []*types.Issue
+	deps := make(map[*types.Issue][]markdownDep)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		// #nosec G304 -- reading the directory being imported
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m, err := parseMarkdownIssue(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		issue, err := m.toIssue()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		issue.Labels = m.Labels
+		if t, err := time.Parse(time.RFC3339Nano, m.UpdatedAt); err == nil {
+			issue.UpdatedAt = t
+		}
+		deps[issue] = m.Deps
+		issues = append(issues, issue)
+	}
+	if err := formats.AssignIDs(ctx, issues, opts, "markdown-import"); err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		for _, d := range deps[issue] {
+			issue.Dependencies = append(issue.Dependencies, &types.Dependency{
+				IssueID:     issue.ID,
+				DependsOnID: d.ID,
+				Type:        d.Type,
+				CreatedAt:   issue.UpdatedAt,
+			})
+		}
+	}
+	return issues, nil
+}
+
+// csvFormat is a spreadsheet with a header row. Params: "columns" (comma
+// separated) for export, "mapping" (a mapping file path) for import.
+type csvFormat struct{}
+
+func (csvFormat) Name() string        { return "csv" }
+func (csvFormat) Description() string { return "CSV spreadsheet with column mapping" }
+
+func (csvFormat) Capabilities() formats.Capabilities {
+	return formats.Capabilities{Export: true, Import: true, Streaming: true, Lossy: true}
+}
+
+// Encode writes the non-tombstone issues: spreadsheets are for people, not
+// sync.
+func (csvFormat) Encode(_ context.Context, w io.Writer, issues []*types.Issue, opts formats.Options) error {
+	var columns []string
+	if c := opts.Params["columns"]; c != "" {
+		columns = strings.Split(c, ",")
+		if err := validateCSVColumns(columns); err != nil {
+			return err
+		}
+	}
+	visible := make([]*types.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Status != types.StatusTombstone {
+			visible = append(visible, issue)
+		}
+	}
+	return writeCSVExport(w, visible, columns)
+}
+
+func (csvFormat) Decode(ctx context.Context, r io.Reader, opts formats.Options) ([]*types.Issue, error) {
+	var mapping *csvMapping
+	if path := opts.Params["mapping"]; path != "" {
+		var err error
+		if mapping, err = loadCSVMapping(path); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := parseCSVIssues(r, mapping)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Store == nil {
+		return nil, fmt.Errorf("csv import needs the database to resolve ids")
+	}
+	if err := rows.resolve(ctx, opts); err != nil {
+		return nil, err
+	}
+	return rows.issues, nil
+}
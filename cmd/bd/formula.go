@@ -31,12 +31,17 @@ The Rig → Cook → Run lifecycle:
 
 Search paths (in order):
   1. .beads/formulas/ (project)
-  2. ~/.beads/formulas/ (user)
-  3. $GT_ROOT/.beads/formulas/ (orchestrator, if GT_ROOT set)
+  2. .beads/formulas/packages/*/ (installed packages, see 'bd formula install')
+  3. ~/.beads/formulas/ (user)
+  4. $GT_ROOT/.beads/formulas/ (orchestrator, if GT_ROOT set)
 
 Commands:
-  list   List available formulas from all search paths
-  show   Show formula details, steps, and composition rules`,
+  list     List available formulas from all search paths
+  show     Show formula details, steps, and composition rules
+  install  Install a formula package and pin it in .beads/formulas.lock
+  update   Reinstall packages from their sources and re-pin them
+  verify   Check installed packages against .beads/formulas.lock
+  test     Cook formula test cases and compare the resulting issue graphs`,
 }
 
 // formulaListCmd lists all available formulas.
@@ -47,8 +52,9 @@ var formulaListCmd = &cobra.Command{
 
 Search paths (in order of priority):
   1. .beads/formulas/ (project - highest priority)
-  2. ~/.beads/formulas/ (user)
-  3. $GT_ROOT/.beads/formulas/ (orchestrator, if GT_ROOT set)
+  2. .beads/formulas/packages/*/ (installed packages)
+  3. ~/.beads/formulas/ (user)
+  4. $GT_ROOT/.beads/formulas/ (orchestrator, if GT_ROOT set)
 
 Formulas in earlier paths shadow those with the same name in later paths.
 
@@ -182,8 +188,12 @@ func runFormulaList(cmd *cobra.Command, args []string) {
 func runFormulaShow(cmd *cobra.Command, args []string) {
 	name := args[0]
 
-	// Create parser with default search paths
-	parser := formula.NewParser()
+	// Create parser with default search paths, pinned to .beads/formulas.lock
+	parser, err := newFormulaParser(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Try to load the formula
 	f, err := parser.LoadByName(name)
@@ -354,6 +364,9 @@ func getFormulaSearchPaths() []string {
 	// Project-level formulas
 	if cwd, err := os.Getwd(); err == nil {
 		paths = append(paths, filepath.Join(cwd, ".beads", "formulas"))
+
+		// Installed formula packages
+		paths = append(paths, formula.PackageSearchPaths(filepath.Join(cwd, ".beads"))...)
 	}
 
 	// User-level formulas
@@ -764,6 +777,7 @@ func fixIntegerFields(m map[string]interface{}) {
 
 func init() {
 	formulaListCmd.Flags().String("type", "", "Filter by type (workflow, expansion, aspect)")
+	formulaTestCmd.Flags().Bool("update", false, "Regenerate golden files from the current output")
 	formulaConvertCmd.Flags().BoolVar(&convertAll, "all", false, "Convert all JSON formulas")
 	formulaConvertCmd.Flags().BoolVar(&convertDelete, "delete", false, "Delete JSON file after conversion")
 	formulaConvertCmd.Flags().BoolVar(&convertStdout, "stdout", false, "Print TOML to stdout instead of file")
@@ -771,5 +785,9 @@ func init() {
 	formulaCmd.AddCommand(formulaListCmd)
 	formulaCmd.AddCommand(formulaShowCmd)
 	formulaCmd.AddCommand(formulaConvertCmd)
+	formulaCmd.AddCommand(formulaInstallCmd)
+	formulaCmd.AddCommand(formulaUpdateCmd)
+	formulaCmd.AddCommand(formulaVerifyCmd)
+	formulaCmd.AddCommand(formulaTestCmd)
 	rootCmd.AddCommand(formulaCmd)
 }
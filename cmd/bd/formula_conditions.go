@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+// conditionBeads gives bead.* and label.* formula conditions read access to
+// the store.
+type conditionBeads struct {
+	ctx context.Context
+	s   storage.Storage
+}
+
+func (b conditionBeads) BeadStatus(id string) (string, error) {
+	issue, err := b.s.GetIssue(b.ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if issue == nil {
+		return "", fmt.Errorf("issue %s not found", id)
+	}
+	return string(issue.Status), nil
+}
+
+func (b conditionBeads) BeadLabels(id string) ([]string, error) {
+	return b.s.GetLabels(b.ctx, id)
+}
+
+// formulaTrusted reports whether formula.trusted in the user's own config
+// lists the formula by name or glob pattern. The project config is not
+// consulted, since a repository could otherwise trust its own formulas.
+func formulaTrusted(name string) bool {
+	for _, pattern := range config.GetUserStringSlice("formula.trusted") {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// newConditionContext builds the context formula conditions are evaluated
+// in outside a running molecule: the working directory, the store (if
+// open) and the repository (if any).
+func newConditionContext(ctx context.Context, vars map[string]string, trusted bool) *formula.ConditionContext {
+	cc := &formula.ConditionContext{Vars: vars, Trusted: trusted}
+	if wd, err := os.Getwd(); err == nil {
+		cc.WorkDir = wd
+	}
+	if store != nil {
+		cc.Beads = conditionBeads{ctx: ctx, s: store}
+	}
+	if v, err := vcs.NewFactory(vcs.WithCache(false), vcs.WithPreferredType(vcs.PreferredVCS())).Create("."); err == nil {
+		cc.VCS = v
+	}
+	return cc
+}
+
+// outputCookConditions prints the gate and loop conditions of a cooked
+// formula for bd cook --dry-run. Returns false if any condition is invalid
+// or needs trust it doesn't have.
+func outputCookConditions(resolved *formula.Formula, vars map[string]string, trust bool) bool {
+	trusted := trust || formulaTrusted(resolved.Formula)
+	checks := formula.CheckConditions(resolved.Steps, newConditionContext(rootCtx, vars, trusted))
+	if len(checks) == 0 {
+		return true
+	}
+
+	valid := true
+	fmt.Printf("\nConditions (%d):\n", len(checks))
+	for _, check := range checks {
+		where := fmt.Sprintf("%s on %s", check.Kind, check.StepID)
+		switch {
+		case check.Err != nil:
+			valid = false
+			fmt.Printf("  %s %s: %s\n", ui.RenderFail("✗"), where, check.Expr)
+			fmt.Printf("      error: %v\n", check.Err)
+		case check.EvalErr != nil:
+			fmt.Printf("  %s %s: %s\n", ui.RenderWarn("!"), where, check.Expr)
+			fmt.Printf("      cannot preview: %v\n", check.EvalErr)
+		case check.Preview != nil:
+			state := "not satisfied"
+			if check.Preview.Satisfied {
+				state = "satisfied"
+			}
+			fmt.Printf("  %s %s: %s\n", ui.RenderPass("✓"), where, check.Expr)
+			fmt.Printf("      now: %s (%s)\n", state, check.Preview.Reason)
+		default:
+			fmt.Printf("  %s %s: %s %s\n", ui.RenderPass("✓"), where, check.Expr, ui.RenderMuted("(evaluated at runtime)"))
+		}
+	}
+	if !valid {
+		fmt.Printf("\n%s Some conditions are invalid; fix them before pouring this formula\n", ui.RenderFail("✗"))
+	}
+	return valid
+}
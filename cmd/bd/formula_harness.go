@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/memory"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+var formulaTestCmd = &cobra.Command{
+	Use:   "test [formula | test-file]...",
+	Short: "Run formula test cases",
+	Long: `Cook formulas with test inputs and check the resulting issue graph.
+
+Test cases live next to the formula in <name>.formula.test.toml:
+
+  [[case]]
+  name = "with-review"
+  vars = { component = "api", review = "true" }
+  steps = ["design", "implement", "review"]   # exact set of step IDs
+
+  [case.deps]
+  review = ["implement"]                      # blocking deps of a step
+
+  [case.labels]
+  review = ["needs-review"]
+
+Each case applies its vars (plus formula defaults), drops steps whose
+conditions are false, and cooks the formula into an in-memory database.
+The resulting graph is checked against the inline expectations and against
+the golden file <name>.<case>.golden.json next to the test file, if present.
+
+With no arguments, runs every test file in .beads/formulas/.
+
+Examples:
+  bd formula test
+  bd formula test release
+  bd formula test path/to/release.formula.test.toml
+  bd formula test release --update   # regenerate golden files`,
+	Run: runFormulaTest,
+}
+
+// formulaTestResult is the outcome of one test case.
+type formulaTestResult struct {
+	Formula  string   `json:"formula"`
+	Case     string   `json:"case"`
+	Passed   bool     `json:"passed"`
+	Updated  bool     `json:"updated,omitempty"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+func runFormulaTest(cmd *cobra.Command, args []string) {
+	update, _ := cmd.Flags().GetBool("update")
+
+	paths, err := findFormulaTestFiles(args)
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	if len(paths) == 0 {
+		FatalErrorRespectJSON("no formula test files (*%s) found", formula.TestFileExt)
+	}
+
+	var results []formulaTestResult
+	for _, path := range paths {
+		suite, err := formula.LoadTestSuite(path)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		for _, c := range suite.Cases {
+			results = append(results, runFormulaTestCase(rootCtx, suite, c, update))
+		}
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"passed":  len(results) - failed,
+			"failed":  failed,
+			"results": results,
+		})
+	} else {
+		for _, r := range results {
+			switch {
+			case !r.Passed:
+				fmt.Printf("%s %s/%s\n", ui.RenderFail("✗"), r.Formula, r.Case)
+				for _, f := range r.Failures {
+					fmt.Printf("    %s\n", f)
+				}
+			case r.Updated:
+				fmt.Printf("%s %s/%s %s\n", ui.RenderPass("✓"), r.Formula, r.Case, ui.RenderMuted("(golden updated)"))
+			default:
+				fmt.Printf("%s %s/%s\n", ui.RenderPass("✓"), r.Formula, r.Case)
+			}
+		}
+		fmt.Printf("\n%d passed, %d failed\n", len(results)-failed, failed)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// findFormulaTestFiles resolves arguments to test files. Arguments are test
+// file paths or formula names looked up in the formula search paths; with no
+// arguments, every test file in the project formulas directory is used.
+func findFormulaTestFiles(args []string) ([]string, error) {
+	searchPaths := getFormulaSearchPaths()
+	if len(args) == 0 {
+		if len(searchPaths) == 0 {
+			return nil, nil
+		}
+		matches, err := filepath.Glob(filepath.Join(searchPaths[0], "*"+formula.TestFileExt))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		return matches, nil
+	}
+
+	var paths []string
+	for _, arg := range args {
+		if strings.HasSuffix(arg, formula.TestFileExt) {
+			paths = append(paths, arg)
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(arg), formula.FormulaExtTOML), formula.FormulaExtJSON)
+		dirs := searchPaths
+		if filepath.Base(arg) != arg {
+			dirs = []string{filepath.Dir(arg)}
+		}
+		found := ""
+		for _, dir := range dirs {
+			candidate := formula.TestFilePath(dir, name)
+			if _, err := os.Stat(candidate); err == nil {
+				found = candidate
+				break
+			}
+		}
+		if found == "" {
+			return nil, fmt.Errorf("no test file %s%s found for %q", name, formula.TestFileExt, arg)
+		}
+		paths = append(paths, found)
+	}
+	return paths, nil
+}
+
+// runFormulaTestCase cooks one case into an in-memory store and checks it.
+func runFormulaTestCase(ctx context.Context, suite *formula.TestSuite, c *formula.TestCase, update bool) formulaTestResult {
+	result := formulaTestResult{Formula: suite.Formula, Case: c.Name}
+	fail := func(format string, args ...interface{}) formulaTestResult {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+		return result
+	}
+
+	got, err := cookFormulaTestGraph(ctx, suite, c)
+	if err != nil {
+		return fail("%v", err)
+	}
+	result.Failures = c.Check(got)
+
+	goldenPath := suite.GoldenPath(c)
+	if update {
+		if err := formula.WriteGolden(goldenPath, got); err != nil {
+			return fail("%v", err)
+		}
+		result.Updated = true
+	} else {
+		want, err := formula.LoadGolden(goldenPath)
+		if err != nil {
+			return fail("%v", err)
+		}
+		switch {
+		case want != nil:
+			for _, d := range formula.DiffGraphs(want, got) {
+				result.Failures = append(result.Failures, "golden: "+d)
+			}
+		case c.Steps == nil && c.Deps == nil && c.Labels == nil:
+			return fail("no expectations and no golden file %s (run with --update to create it)", filepath.Base(goldenPath))
+		}
+	}
+
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+// cookFormulaTestGraph runs the cook pipeline for a test case against an
+// in-memory store and reads the resulting issue graph back out of it.
+func cookFormulaTestGraph(ctx context.Context, suite *formula.TestSuite, c *formula.TestCase) (*formula.TestGraph, error) {
+	// Formulas next to the test file take precedence, then the usual search paths
+	searchPaths := append([]string{filepath.Dir(suite.Path)}, getFormulaSearchPaths()...)
+	resolved, err := loadAndResolveFormula(suite.Formula, searchPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply defaults, then the case's vars, as pour would
+	vars := make(map[string]string)
+	for name, def := range resolved.Vars {
+		if def != nil && def.Default != "" {
+			vars[name] = def.Default
+		}
+	}
+	for k, v := range c.Vars {
+		vars[k] = v
+	}
+
+	steps, err := formula.FilterStepsByCondition(resolved.Steps, vars)
+	if err != nil {
+		return nil, fmt.Errorf("filtering steps by condition: %w", err)
+	}
+	resolved.Steps = steps
+	substituteFormulaVars(resolved, vars)
+
+	mem := memory.New("")
+	defer func() { _ = mem.Close() }()
+	protoID := resolved.Formula
+	if _, err := cookFormula(ctx, mem, resolved, protoID); err != nil {
+		return nil, err
+	}
+	return readFormulaTestGraph(ctx, mem, protoID, c.Vars)
+}
+
+// readFormulaTestGraph loads a cooked proto from storage and normalizes it
+// so it can be compared across runs.
+func readFormulaTestGraph(ctx context.Context, s storage.Storage, protoID string, vars map[string]string) (*formula.TestGraph, error) {
+	subgraph, err := loadTemplateSubgraph(ctx, s, protoID)
+	if err != nil {
+		return nil, err
+	}
+
+	rel := func(id string) string {
+		return strings.TrimPrefix(id, protoID+".")
+	}
+
+	steps := make(map[string]*formula.TestGraphStep)
+	graph := &formula.TestGraph{Formula: protoID, Vars: vars}
+	for _, issue := range subgraph.Issues {
+		if issue.ID == protoID {
+			continue
+		}
+		labels, err := s.GetLabels(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get labels for %s: %w", issue.ID, err)
+		}
+		step := &formula.TestGraphStep{
+			ID:       rel(issue.ID),
+			Title:    issue.Title,
+			Type:     string(issue.IssueType),
+			Priority: issue.Priority,
+			Labels:   labels,
+		}
+		steps[issue.ID] = step
+		graph.Steps = append(graph.Steps, step)
+	}
+
+	for _, dep := range subgraph.Dependencies {
+		step, ok := steps[dep.IssueID]
+		if !ok {
+			continue
+		}
+		switch dep.Type {
+		case types.DepParentChild:
+			if dep.DependsOnID != protoID {
+				step.Parent = rel(dep.DependsOnID)
+			}
+		case types.DepBlocks:
+			step.Deps = append(step.Deps, rel(dep.DependsOnID))
+		default:
+			step.Deps = append(step.Deps, string(dep.Type)+":"+rel(dep.DependsOnID))
+		}
+	}
+
+	graph.Sort()
+	return graph, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/formula"
+)
+
+const harnessFormula = `
+formula = "ship"
+[vars.env]
+default = "staging"
+
+[[steps]]
+id = "build"
+title = "Build for {{env}}"
+
+[[steps]]
+id = "deploy"
+title = "Deploy to {{env}}"
+needs = ["build"]
+labels = ["deploy"]
+[steps.gate]
+type = "human"
+
+[[steps]]
+id = "verify"
+title = "Verify"
+condition = "{{env}} == prod"
+[[steps.children]]
+id = "smoke"
+title = "Smoke test"
+`
+
+func TestFormulaTestCaseCooksIntoMemory(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "ship.formula.toml"), []byte(harnessFormula), 0600); err != nil {
+		t.Fatal(err)
+	}
+	testFile := filepath.Join(dir, "ship"+formula.TestFileExt)
+	if err := os.WriteFile(testFile, []byte(`
+[[case]]
+name = "staging"
+steps = ["build", "deploy", "gate-deploy"]
+[case.deps]
+deploy = ["build", "gate-deploy"]
+
+[[case]]
+name = "prod"
+vars = { env = "prod" }
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	suite, err := formula.LoadTestSuite(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	staging := runFormulaTestCase(ctx, suite, suite.Cases[0], false)
+	if !staging.Passed {
+		t.Errorf("staging case failed: %v", staging.Failures)
+	}
+
+	// Without a golden file or inline expectations the case fails
+	prod := runFormulaTestCase(ctx, suite, suite.Cases[1], false)
+	if prod.Passed || !strings.Contains(strings.Join(prod.Failures, "\n"), "--update") {
+		t.Errorf("prod case without golden = %+v", prod)
+	}
+
+	// --update writes the golden, which then passes
+	if r := runFormulaTestCase(ctx, suite, suite.Cases[1], true); !r.Passed || !r.Updated {
+		t.Fatalf("update = %+v", r)
+	}
+	golden, err := formula.LoadGolden(suite.GoldenPath(suite.Cases[1]))
+	if err != nil || golden == nil {
+		t.Fatalf("LoadGolden = %v, %v", golden, err)
+	}
+	if s := golden.Step("verify.smoke"); s == nil || s.Parent != "verify" {
+		t.Errorf("nested step = %+v", s)
+	}
+	if s := golden.Step("deploy"); s == nil || s.Title != "Deploy to prod" || len(s.Labels) != 1 || s.Labels[0] != "deploy" {
+		t.Errorf("deploy step = %+v", s)
+	}
+	if r := runFormulaTestCase(ctx, suite, suite.Cases[1], false); !r.Passed {
+		t.Errorf("prod case after update failed: %v", r.Failures)
+	}
+
+	// Changing the formula shows up as a graph diff
+	changed := strings.Replace(harnessFormula, `needs = ["build"]`, `needs = []`, 1)
+	if err := os.WriteFile(filepath.Join(dir, "ship.formula.toml"), []byte(changed), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r := runFormulaTestCase(ctx, suite, suite.Cases[1], false)
+	if r.Passed || len(r.Failures) != 1 || r.Failures[0] != "golden: ~ deploy deps: [build, gate-deploy] → [gate-deploy]" {
+		t.Errorf("changed formula = %+v", r)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+// formulaFetchTimeout bounds a git clone of a formula package.
+const formulaFetchTimeout = 2 * time.Minute
+
+var formulaInstallCmd = &cobra.Command{
+	Use:   "install [dir | git-url[#ref]]",
+	Short: "Install a formula package",
+	Long: `Install a formula package into .beads/formulas/packages/ and pin it.
+
+A formula package is a directory with a formula-package.toml manifest and
+formula files next to it:
+
+  name = "review-kit"
+  version = "1.2.0"
+
+  [dependencies]
+  base-kit = { source = "../base-kit", version = "1.2" }
+
+The source is a local directory or a git URL with an optional #ref (branch,
+tag or commit). Dependencies are installed first. Every installed file is
+pinned by content hash in .beads/formulas.lock, which should be committed.
+
+With no argument, reinstalls locked packages whose files are missing or
+modified, using the exact locked content (like a clean checkout).
+
+Examples:
+  bd formula install ../review-kit
+  bd formula install https://github.com/org/formulas.git#v1.2.0
+  bd formula install`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runFormulaInstall,
+}
+
+var formulaUpdateCmd = &cobra.Command{
+	Use:   "update [package...]",
+	Short: "Update formula packages from their sources",
+	Long: `Reinstall packages from the sources recorded in .beads/formulas.lock and
+pin the new content. Git sources are fetched again, so a branch ref picks up
+new commits. Updates all packages when none are named.
+
+Examples:
+  bd formula update
+  bd formula update review-kit`,
+	Run: runFormulaUpdate,
+}
+
+var formulaVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify installed formula packages against the lockfile",
+	Long: `Check every package in .beads/formulas.lock: files must exist and match
+their locked hashes, no unlocked files or packages may be installed, and
+dependency versions must be satisfied. Exits 1 if anything is wrong.
+
+bd cook runs the same check and refuses to cook while it fails.`,
+	Args: cobra.NoArgs,
+	Run:  runFormulaVerify,
+}
+
+// formulaBeadsDir returns the .beads directory that holds formula packages,
+// matching the project search path used by getFormulaSearchPaths.
+func formulaBeadsDir() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting working directory: %w", err)
+	}
+	return filepath.Join(cwd, ".beads"), nil
+}
+
+// fetchFormulaPackage clones a git formula package and returns the commit it resolved to.
+// Sources and refs come from manifests and lockfiles as well as the command
+// line, so neither may be read by git as an option.
+func fetchFormulaPackage(ctx context.Context, url, ref, dst string) (string, error) {
+	if strings.HasPrefix(url, "-") {
+		return "", fmt.Errorf("invalid git source %q: must not start with '-'", url)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid git ref %q: must not start with '-'", ref)
+	}
+	if !vcs.IsGitAvailable() {
+		return "", vcs.ErrVCSNotAvailable
+	}
+	if _, err := vcs.ExecContext(ctx, formulaFetchTimeout, "", "git", "clone", "--quiet", "--", url, dst); err != nil {
+		return "", err
+	}
+	repo, err := vcs.NewFactory(vcs.WithCache(false), vcs.WithPreferredType(vcs.TypeGit)).Create(dst)
+	if err != nil {
+		return "", err
+	}
+	if ref != "" {
+		commit, err := resolveFormulaRef(ctx, repo, ref)
+		if err != nil {
+			return "", err
+		}
+		if _, err := repo.Exec(ctx, "checkout", "--quiet", "--detach", commit, "--"); err != nil {
+			return "", err
+		}
+	}
+	return repo.GetCommitHash("HEAD")
+}
+
+// resolveFormulaRef resolves a tag, commit or branch in a fresh clone to a
+// commit. Only the default branch exists locally after cloning, so other
+// branches are looked up on origin.
+func resolveFormulaRef(ctx context.Context, repo vcs.VCS, ref string) (string, error) {
+	for _, candidate := range []string{ref, "origin/" + ref} {
+		out, err := repo.Exec(ctx, "rev-parse", "--verify", "--quiet", candidate+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(string(out)), nil
+		}
+	}
+	return "", fmt.Errorf("git ref %q not found", ref)
+}
+
+// newFormulaParser returns a parser that enforces .beads/formulas.lock.
+// It fails if any installed package does not match the lock, so cooking
+// never uses formulas that drifted from what was pinned.
+func newFormulaParser(searchPaths []string) (*formula.Parser, error) {
+	parser := formula.NewParser(searchPaths...)
+	beadsDir, err := formulaBeadsDir()
+	if err != nil {
+		return nil, err
+	}
+	lock, err := formula.LoadLock(beadsDir)
+	if err != nil {
+		return nil, err
+	}
+	if !lock.Exists() {
+		return parser, nil
+	}
+	if problems := lock.Verify(beadsDir); len(problems) > 0 {
+		msgs := make([]string, len(problems))
+		for i, p := range problems {
+			msgs[i] = "  " + p.Error()
+		}
+		return nil, fmt.Errorf("formula packages do not match %s (see 'bd formula verify'):\n%s",
+			formula.LockFileName, strings.Join(msgs, "\n"))
+	}
+	if err := parser.SetLock(lock, beadsDir); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
+
+func newFormulaInstaller() *formula.Installer {
+	beadsDir, err := formulaBeadsDir()
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	if _, err := os.Stat(beadsDir); err != nil {
+		FatalErrorRespectJSON("no .beads directory in the current directory; run 'bd init' first")
+	}
+	installer, err := formula.NewInstaller(beadsDir, fetchFormulaPackage)
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	return installer
+}
+
+func runFormulaInstall(cmd *cobra.Command, args []string) {
+	CheckReadonly("formula install")
+	installer := newFormulaInstaller()
+
+	if len(args) == 0 {
+		restored, err := installer.Restore(rootCtx)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		if jsonOutput {
+			outputJSON(map[string]interface{}{"restored": restored})
+			return
+		}
+		if len(restored) == 0 {
+			fmt.Printf("%s All %d formula packages match %s\n", ui.RenderPass("✓"), len(installer.Lock().Packages), formula.LockFileName)
+			return
+		}
+		for _, name := range restored {
+			pkg := installer.Lock().Packages[name]
+			fmt.Printf("%s Restored %s@%s\n", ui.RenderPass("✓"), name, pkg.Version)
+		}
+		return
+	}
+
+	if _, err := installer.Install(rootCtx, args[0]); err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	printInstalledPackages(installer.Installed, "Installed")
+}
+
+func runFormulaUpdate(cmd *cobra.Command, args []string) {
+	CheckReadonly("formula update")
+	installer := newFormulaInstaller()
+
+	before := make(map[string]string)
+	for name, pkg := range installer.Lock().Packages {
+		before[name] = pkg.Hash
+	}
+	if _, err := installer.Update(rootCtx, args...); err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	if jsonOutput {
+		printInstalledPackages(installer.Installed, "Updated")
+		return
+	}
+	var changed []*formula.LockedPackage
+	for _, pkg := range installer.Installed {
+		if before[pkg.Name] == pkg.Hash {
+			fmt.Printf("  %s@%s unchanged\n", pkg.Name, pkg.Version)
+		} else {
+			changed = append(changed, pkg)
+		}
+	}
+	printInstalledPackages(changed, "Updated")
+}
+
+// installedPackageJSON adds the package name, which the lockfile keeps as the map key.
+type installedPackageJSON struct {
+	Name string `json:"name"`
+	*formula.LockedPackage
+}
+
+func printInstalledPackages(pkgs []*formula.LockedPackage, verb string) {
+	if jsonOutput {
+		out := make([]installedPackageJSON, len(pkgs))
+		for i, pkg := range pkgs {
+			out[i] = installedPackageJSON{Name: pkg.Name, LockedPackage: pkg}
+		}
+		outputJSON(out)
+		return
+	}
+	for _, pkg := range pkgs {
+		formulas := len(pkg.Files) - 1 // manifest
+		noun := "formulas"
+		if formulas == 1 {
+			noun = "formula"
+		}
+		fmt.Printf("%s %s %s@%s (%d %s)", ui.RenderPass("✓"), verb, ui.RenderAccent(pkg.Name), pkg.Version, formulas, noun)
+		if pkg.Resolved != "" {
+			fmt.Printf(" %s", ui.RenderMuted(shortCommit(pkg.Resolved)))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("\nPinned in .beads/%s\n", formula.LockFileName)
+}
+
+func runFormulaVerify(cmd *cobra.Command, args []string) {
+	beadsDir, err := formulaBeadsDir()
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	lock, err := formula.LoadLock(beadsDir)
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	problems := lock.Verify(beadsDir)
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"packages": len(lock.Packages),
+			"ok":       len(problems) == 0,
+			"problems": problems,
+		})
+	} else if len(problems) == 0 {
+		fmt.Printf("%s %d formula packages match %s\n", ui.RenderPass("✓"), len(lock.Packages), formula.LockFileName)
+	} else {
+		for _, p := range problems {
+			fmt.Printf("%s %s\n", ui.RenderFail("✗"), p.Error())
+		}
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+func TestFetchFormulaPackage(t *testing.T) {
+	if !vcs.IsGitAvailable() {
+		t.Skip("git not available")
+	}
+	src := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = src
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(name string) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		git("add", name)
+		git("commit", "--quiet", "-m", name)
+		return git("rev-parse", "HEAD")
+	}
+	git("init", "--quiet")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	first := commit("first.txt")
+	git("tag", "v1")
+	second := commit("second.txt")
+	git("checkout", "--quiet", "-b", "dev")
+	dev := commit("dev.txt")
+	git("checkout", "--quiet", "-")
+	head := git("rev-parse", "HEAD")
+
+	ctx := context.Background()
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"", head},
+		{"v1", first},
+		{second, second},
+		{"dev", dev},
+	}
+	for _, tt := range tests {
+		dst := filepath.Join(t.TempDir(), "pkg")
+		got, err := fetchFormulaPackage(ctx, src, tt.ref, dst)
+		if err != nil {
+			t.Fatalf("fetchFormulaPackage(ref %q): %v", tt.ref, err)
+		}
+		if got != tt.want {
+			t.Errorf("fetchFormulaPackage(ref %q) = %s, want %s", tt.ref, got, tt.want)
+		}
+	}
+
+	if _, err := fetchFormulaPackage(ctx, src, "missing", filepath.Join(t.TempDir(), "pkg")); err == nil {
+		t.Error("expected error for unknown ref")
+	}
+}
+
+func TestFetchFormulaPackage_RejectsOptions(t *testing.T) {
+	ctx := context.Background()
+	marker := filepath.Join(t.TempDir(), "ran")
+	for _, tt := range []struct{ url, ref string }{
+		{"--upload-pack=touch " + marker, ""},
+		{"https://example.com/pkg.git", "--orphan=x"},
+		{"https://example.com/pkg.git", "-b"},
+	} {
+		_, err := fetchFormulaPackage(ctx, tt.url, tt.ref, filepath.Join(t.TempDir(), "pkg"))
+		if err == nil || !strings.Contains(err.Error(), "must not start with '-'") {
+			t.Errorf("fetchFormulaPackage(%q, %q) = %v, want option rejected", tt.url, tt.ref, err)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("git ran an option passed as the source")
+	}
+}
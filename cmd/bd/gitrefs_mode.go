@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/debug"
+	"github.com/steveyegge/beads/internal/storage/gitrefs"
+	"github.com/steveyegge/beads/internal/vcs"
+	_ "github.com/steveyegge/beads/internal/vcs/git" // Registers the git backend
+)
+
+// initializeGitRefsMode sets up storage backed by refs/beads/issues/*. This
+// is called when --git-refs is set (or git-refs: true in config.yaml). Like
+// --no-db, changes are committed back to refs when the command finishes.
+func initializeGitRefsMode() error {
+	v, err := vcs.GetGit()
+	if err != nil {
+		return fmt.Errorf("git refs storage needs a git repository: %w", err)
+	}
+
+	refStore, err := gitrefs.New(rootCtx, v)
+	if err != nil {
+		return fmt.Errorf("failed to open git refs storage: %w", err)
+	}
+
+	// Detect and persist the prefix the same way --no-db does
+	prefix, err := refStore.GetConfig(rootCtx, "issue_prefix")
+	if err != nil {
+		return fmt.Errorf("failed to read prefix: %w", err)
+	}
+	if prefix == "" {
+		prefix, err = detectPrefix(beads.FindBeadsDir(), refStore.MemoryStorage)
+		if err != nil {
+			return fmt.Errorf("failed to detect prefix: %w", err)
+		}
+		if err := refStore.SetConfig(rootCtx, "issue_prefix", prefix); err != nil {
+			return fmt.Errorf("failed to set prefix: %w", err)
+		}
+	}
+
+	debug.Logf("git refs mode: loaded %s in %s, prefix '%s'", gitrefs.IssueRefPrefix, refStore.Path(), prefix)
+
+	lockStore()
+	setStore(refStore)
+	setStoreActive(true)
+	unlockStore()
+	return nil
+}
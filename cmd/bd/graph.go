@@ -34,7 +34,7 @@ type GraphLayout struct {
 }
 
 var graphCmd = &cobra.Command{
-	Use:     "graph <issue-id>",
+	Use:     "graph [issue-id]",
 	GroupID: "deps",
 	Short:   "Display issue dependency graph",
 	Long: `Display an ASCII visualization of an issue's dependency graph.
@@ -51,12 +51,42 @@ Colors indicate status:
 - White: open (ready to work)
 - Yellow: in progress
 - Red: blocked
-- Green: closed`,
-	Args: cobra.ExactArgs(1),
+- Green: closed
+
+Use --format to export the graph for design docs and PR descriptions instead:
+dot (Graphviz), mermaid, d2, or json-graph (plain node/edge lists). Nodes are
+colored by status and each dependency type gets its own edge style. --all
+exports the whole project (closed issues with --include-closed), and
+--cluster groups nodes by epic or label.
+
+Examples:
+  bd graph bd-42
+  bd graph bd-42 --format=mermaid
+  bd graph --all --cluster=epic --format=dot | dot -Tsvg > graph.svg
+  bd graph --all --cluster=label --format=d2`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := rootCtx
 		var issueID string
 
+		format, _ := cmd.Flags().GetString("format")
+		all, _ := cmd.Flags().GetBool("all")
+		clusterBy, _ := cmd.Flags().GetString("cluster")
+		includeClosed, _ := cmd.Flags().GetBool("include-closed")
+		if format != "" && !isGraphFormat(format) {
+			FatalErrorRespectJSON("invalid --format %q (valid: %s)", format, strings.Join(graphFormats, ", "))
+		}
+		if all == (len(args) == 1) {
+			FatalErrorRespectJSON("specify an issue ID or --all")
+		}
+		if all && format == "" {
+			FatalErrorWithHint("--all needs --format", "use --format=dot, mermaid, d2 or json-graph")
+		}
+		if all {
+			runGraphExportAll(ctx, format, clusterBy, includeClosed)
+			return
+		}
+
 		// Resolve the issue ID
 		if daemonClient != nil {
 			resolveArgs := &rpc.ResolveIDArgs{ID: args[0]}
@@ -99,6 +129,17 @@ Colors indicate status:
 			os.Exit(1)
 		}
 
+		if format != "" {
+			g := newExportGraph(subgraph.Root.ID, subgraph.Issues, subgraph.Dependencies)
+			if err := g.cluster(ctx, store, clusterBy); err != nil {
+				FatalErrorRespectJSON("%v", err)
+			}
+			if err := writeGraph(os.Stdout, g, format); err != nil {
+				FatalErrorRespectJSON("%v", err)
+			}
+			return
+		}
+
 		// Compute layout
 		layout := computeLayout(subgraph)
 
@@ -118,9 +159,33 @@ Colors indicate status:
 
 func init() {
 	graphCmd.ValidArgsFunction = issueIDCompletion
+	graphCmd.Flags().String("format", "", "Export format: dot, mermaid, d2, or json-graph (default: terminal rendering)")
+	graphCmd.Flags().Bool("all", false, "Export the whole project instead of one issue (requires --format)")
+	graphCmd.Flags().String("cluster", "", "Group nodes by 'epic' or 'label' in exported graphs")
+	graphCmd.Flags().Bool("include-closed", false, "Include closed issues with --all")
 	rootCmd.AddCommand(graphCmd)
 }
 
+// runGraphExportAll exports every issue in the project.
+func runGraphExportAll(ctx context.Context, format, clusterBy string, includeClosed bool) {
+	s, closeStore, err := openReadStore(ctx)
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	defer closeStore()
+
+	g, err := loadProjectGraph(ctx, s, includeClosed)
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	if err := g.cluster(ctx, s, clusterBy); err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	if err := writeGraph(os.Stdout, g, format); err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+}
+
 // loadGraphSubgraph loads an issue and its subgraph for visualization
 // Unlike template loading, this includes ALL dependency types (not just parent-child)
 func loadGraphSubgraph(ctx context.Context, s storage.Storage, issueID string) (*TemplateSubgraph, error) {
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// Graph export formats accepted by bd graph --format and bd dep tree --format.
+const (
+	graphFormatDOT       = "dot"
+	graphFormatMermaid   = "mermaid"
+	graphFormatD2        = "d2"
+	graphFormatJSONGraph = "json-graph"
+)
+
+var graphFormats = []string{graphFormatDOT, graphFormatMermaid, graphFormatD2, graphFormatJSONGraph}
+
+func isGraphFormat(format string) bool {
+	for _, f := range graphFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// exportGraph is a set of issues and the dependencies between them, ready to
+// be written in one of the graph formats. Edges point from an issue to what
+// it depends on, matching the dependency records.
+type exportGraph struct {
+	RootID   string
+	Nodes    []*types.Issue
+	Edges    []*types.Dependency
+	Clusters []*graphCluster
+	// clusterOf maps issue ID -> cluster ID for clustered nodes
+	clusterOf map[string]string
+}
+
+// graphCluster groups nodes that share an epic or a label.
+type graphCluster struct {
+	ID    string   `json:"id"`
+	Label string   `json:"label"`
+	Nodes []string `json:"nodes"`
+}
+
+// newExportGraph builds a graph from issues and dependency records, keeping
+// only edges with both ends among the issues. Nodes are sorted by ID so the
+// output is stable across runs.
+func newExportGraph(rootID string, issues []*types.Issue, deps []*types.Dependency) *exportGraph {
+	g := &exportGraph{RootID: rootID, clusterOf: make(map[string]string)}
+	present := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		if !present[issue.ID] {
+			present[issue.ID] = true
+			g.Nodes = append(g.Nodes, issue)
+		}
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+
+	seen := make(map[string]bool)
+	for _, dep := range deps {
+		key := dep.IssueID + "\x00" + dep.DependsOnID + "\x00" + string(dep.Type)
+		if seen[key] || !present[dep.IssueID] || !present[dep.DependsOnID] {
+			continue
+		}
+		seen[key] = true
+		g.Edges = append(g.Edges, dep)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.IssueID != b.IssueID {
+			return a.IssueID < b.IssueID
+		}
+		if a.DependsOnID != b.DependsOnID {
+			return a.DependsOnID < b.DependsOnID
+		}
+		return a.Type < b.Type
+	})
+	return g
+}
+
+// loadProjectGraph loads every issue in the project and all dependencies
+// between them. Closed issues are left out unless includeClosed is set.
+func loadProjectGraph(ctx context.Context, s storage.Storage, includeClosed bool) (*exportGraph, error) {
+	filter := types.IssueFilter{}
+	if !includeClosed {
+		filter.ExcludeStatus = []types.Status{types.StatusClosed}
+	}
+	issues, err := s.SearchIssues(ctx, "", filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	records, err := s.GetAllDependencyRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dependencies: %w", err)
+	}
+	var deps []*types.Dependency
+	for _, list := range records {
+		deps = append(deps, list...)
+	}
+	return newExportGraph("", issues, deps), nil
+}
+
+// loadTreeGraph turns a dependency tree into an export graph. The tree only
+// records how each node was reached, so the typed edges between its nodes
+// are read back from storage.
+func loadTreeGraph(ctx context.Context, s storage.Storage, tree []*types.TreeNode, rootID string) (*exportGraph, error) {
+	issues := make([]*types.Issue, 0, len(tree))
+	for _, node := range tree {
+		issue := node.Issue
+		issues = append(issues, &issue)
+	}
+	var deps []*types.Dependency
+	for _, issue := range issues {
+		records, err := s.GetDependencyRecords(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dependencies of %s: %w", issue.ID, err)
+		}
+		deps = append(deps, records...)
+	}
+	return newExportGraph(rootID, issues, deps), nil
+}
+
+// cluster groups nodes by "epic" (nearest epic ancestor via parent-child,
+// with each epic in its own cluster) or "label" (first label
+// alphabetically). Nodes without an epic or label stay unclustered.
+func (g *exportGraph) cluster(ctx context.Context, s storage.Storage, by string) error {
+	switch by {
+	case "":
+		return nil
+	case "epic":
+		g.clusterByEpic()
+	case "label":
+		ids := make([]string, len(g.Nodes))
+		for i, n := range g.Nodes {
+			ids[i] = n.ID
+		}
+		labels, err := s.GetLabelsForIssues(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to load labels: %w", err)
+		}
+		g.clusterByLabel(labels)
+	default:
+		return fmt.Errorf("invalid cluster %q (valid: epic, label)", by)
+	}
+	return nil
+}
+
+func (g *exportGraph) clusterByEpic() {
+	byID := make(map[string]*types.Issue, len(g.Nodes))
+	for _, n := range g.Nodes {
+		byID[n.ID] = n
+	}
+	parents := make(map[string][]string)
+	for _, e := range g.Edges {
+		if e.Type == types.DepParentChild {
+			parents[e.IssueID] = append(parents[e.IssueID], e.DependsOnID)
+		}
+	}
+
+	// Breadth-first up the parent-child edges so the nearest epic wins
+	nearestEpic := func(id string) string {
+		seen := map[string]bool{id: true}
+		queue := []string{id}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			if issue := byID[current]; issue != nil && issue.IssueType == types.TypeEpic {
+				return current
+			}
+			for _, p := range parents[current] {
+				if !seen[p] {
+					seen[p] = true
+					queue = append(queue, p)
+				}
+			}
+		}
+		return ""
+	}
+
+	for _, n := range g.Nodes {
+		if epic := nearestEpic(n.ID); epic != "" {
+			g.addToCluster("epic:"+epic, fmt.Sprintf("%s: %s", epic, byID[epic].Title), n.ID)
+		}
+	}
+}
+
+func (g *exportGraph) clusterByLabel(labels map[string][]string) {
+	for _, n := range g.Nodes {
+		issueLabels := append([]string(nil), labels[n.ID]...)
+		if len(issueLabels) == 0 {
+			continue
+		}
+		sort.Strings(issueLabels)
+		g.addToCluster("label:"+issueLabels[0], issueLabels[0], n.ID)
+	}
+}
+
+func (g *exportGraph) addToCluster(id, label, nodeID string) {
+	g.clusterOf[nodeID] = id
+	for _, c := range g.Clusters {
+		if c.ID == id {
+			c.Nodes = append(c.Nodes, nodeID)
+			return
+		}
+	}
+	g.Clusters = append(g.Clusters, &graphCluster{ID: id, Label: label, Nodes: []string{nodeID}})
+	sort.Slice(g.Clusters, func(i, j int) bool { return g.Clusters[i].ID < g.Clusters[j].ID })
+}
+
+// unclustered returns nodes that belong to no cluster, in node order.
+func (g *exportGraph) unclustered() []*types.Issue {
+	var out []*types.Issue
+	for _, n := range g.Nodes {
+		if _, ok := g.clusterOf[n.ID]; !ok {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func (g *exportGraph) node(id string) *types.Issue {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// statusFillColor is the node fill color for a status.
+func statusFillColor(status types.Status) string {
+	switch status {
+	case types.StatusOpen:
+		return "#ffffff"
+	case types.StatusInProgress:
+		return "#fff3b0"
+	case types.StatusBlocked:
+		return "#f8c4c4"
+	case types.StatusDeferred:
+		return "#d6e4f0"
+	case types.StatusClosed:
+		return "#c8e6c9"
+	case types.StatusHooked:
+		return "#ffe0b2"
+	case types.StatusPinned:
+		return "#e1bee7"
+	default:
+		return "#eeeeee"
+	}
+}
+
+// edgeStyle is how one dependency type is drawn in each format.
+type edgeStyle struct {
+	dot     string // DOT edge attributes
+	mermaid string // Mermaid link syntax
+	d2      string // D2 style block contents
+}
+
+// edgeStyles maps dependency types to their edge styles. Blocking edges are
+// solid and heavy, hierarchy is dashed, and loose links are dotted.
+var edgeStyles = map[types.DependencyType]edgeStyle{
+	types.DepBlocks:            {dot: `style=bold`, mermaid: "==>", d2: `style.stroke-width: 3`},
+	types.DepConditionalBlocks: {dot: `style=bold, color="#e65100"`, mermaid: "==>", d2: `style.stroke-width: 3; style.stroke: "#e65100"`},
+	types.DepWaitsFor:          {dot: `style=bold, color="#6a1b9a"`, mermaid: "==>", d2: `style.stroke-width: 3; style.stroke: "#6a1b9a"`},
+	types.DepParentChild:       {dot: `style=dashed, arrowhead=empty`, mermaid: "-.->", d2: `style.stroke-dash: 5`},
+	types.DepRelated:           {dot: `style=dotted, dir=none`, mermaid: "-.-", d2: `style.stroke-dash: 2; target-arrowhead.shape: none`},
+	types.DepRelatesTo:         {dot: `style=dotted, dir=none`, mermaid: "-.-", d2: `style.stroke-dash: 2; target-arrowhead.shape: none`},
+	types.DepDiscoveredFrom:    {dot: `style=dotted, color="#757575"`, mermaid: "-.->", d2: `style.stroke-dash: 2; style.stroke: "#757575"`},
+}
+
+// defaultEdgeStyle covers the remaining, mostly informational, types.
+var defaultEdgeStyle = edgeStyle{dot: `style=dashed, color="#9e9e9e"`, mermaid: "-.->", d2: `style.stroke-dash: 3; style.stroke: "#9e9e9e"`}
+
+func styleFor(t types.DependencyType) edgeStyle {
+	if s, ok := edgeStyles[t]; ok {
+		return s
+	}
+	return defaultEdgeStyle
+}
+
+// writeGraph writes g in the given format.
+func writeGraph(w io.Writer, g *exportGraph, format string) error {
+	switch format {
+	case graphFormatDOT:
+		return writeGraphDOT(w, g)
+	case graphFormatMermaid:
+		return writeGraphMermaid(w, g)
+	case graphFormatD2:
+		return writeGraphD2(w, g)
+	case graphFormatJSONGraph:
+		return writeGraphJSON(w, g)
+	default:
+		return fmt.Errorf("invalid format %q (valid: %s)", format, strings.Join(graphFormats, ", "))
+	}
+}
+
+func nodeLabel(issue *types.Issue) string {
+	return fmt.Sprintf("%s: %s", issue.ID, issue.Title)
+}
+
+// quoteDOT quotes s as a DOT string.
+func quoteDOT(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func writeGraphDOT(w io.Writer, g *exportGraph) error {
+	var b strings.Builder
+	b.WriteString("digraph beads {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	writeNode := func(indent string, n *types.Issue) {
+		penwidth := ""
+		if n.ID == g.RootID {
+			penwidth = ", penwidth=2"
+		}
+		fmt.Fprintf(&b, "%s%s [label=%s, fillcolor=%s, tooltip=%s%s];\n",
+			indent, quoteDOT(n.ID), quoteDOT(n.ID+"\n"+n.Title), quoteDOT(statusFillColor(n.Status)), quoteDOT(string(n.Status)), penwidth)
+	}
+	for i, c := range g.Clusters {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=%s;\n", quoteDOT(c.Label))
+		b.WriteString("    style=\"rounded,dashed\";\n")
+		for _, id := range c.Nodes {
+			writeNode("    ", g.node(id))
+		}
+		b.WriteString("  }\n")
+	}
+	for _, n := range g.unclustered() {
+		writeNode("  ", n)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s, %s];\n",
+			quoteDOT(e.IssueID), quoteDOT(e.DependsOnID), quoteDOT(string(e.Type)), styleFor(e.Type).dot)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidID maps an issue ID to a Mermaid node ID. Mermaid IDs cannot hold
+// dots (hierarchical IDs), so anything but letters, digits, - and _ becomes _.
+func mermaidID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// quoteMermaid quotes s as a Mermaid label.
+func quoteMermaid(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// mermaidClass maps a status to a classDef name (classDef names cannot hold -).
+func mermaidClass(status types.Status) string {
+	return "status_" + mermaidID(string(status))
+}
+
+func writeGraphMermaid(w io.Writer, g *exportGraph) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	writeNode := func(indent string, n *types.Issue) {
+		fmt.Fprintf(&b, "%s%s[%s]\n", indent, mermaidID(n.ID), quoteMermaid(nodeLabel(n)))
+	}
+	for i, c := range g.Clusters {
+		fmt.Fprintf(&b, "  subgraph cluster_%d[%s]\n", i, quoteMermaid(c.Label))
+		for _, id := range c.Nodes {
+			writeNode("    ", g.node(id))
+		}
+		b.WriteString("  end\n")
+	}
+	for _, n := range g.unclustered() {
+		writeNode("  ", n)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", mermaidID(e.IssueID), styleFor(e.Type).mermaid, e.Type, mermaidID(e.DependsOnID))
+	}
+
+	// Color nodes by status
+	byStatus := make(map[types.Status][]string)
+	var statuses []types.Status
+	for _, n := range g.Nodes {
+		if _, ok := byStatus[n.Status]; !ok {
+			statuses = append(statuses, n.Status)
+		}
+		byStatus[n.Status] = append(byStatus[n.Status], mermaidID(n.ID))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+	for _, s := range statuses {
+		fmt.Fprintf(&b, "  classDef %s fill:%s,stroke:#333\n", mermaidClass(s), statusFillColor(s))
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(byStatus[s], ","), mermaidClass(s))
+	}
+	if g.RootID != "" && g.node(g.RootID) != nil {
+		fmt.Fprintf(&b, "  style %s stroke-width:3px\n", mermaidID(g.RootID))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quoteD2 quotes s as a D2 string.
+func quoteD2(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func writeGraphD2(w io.Writer, g *exportGraph) error {
+	var b strings.Builder
+	b.WriteString("direction: right\n")
+
+	// Nodes inside a container are addressed as container.node
+	ref := func(id string) string {
+		for i, c := range g.Clusters {
+			if c.ID == g.clusterOf[id] {
+				return fmt.Sprintf("cluster_%d.%s", i, quoteD2(id))
+			}
+		}
+		return quoteD2(id)
+	}
+	writeNode := func(indent string, n *types.Issue) {
+		fmt.Fprintf(&b, "%s%s: %s {\n", indent, quoteD2(n.ID), quoteD2(n.ID+"\n"+n.Title))
+		fmt.Fprintf(&b, "%s  style.fill: %s\n", indent, quoteD2(statusFillColor(n.Status)))
+		if n.ID == g.RootID {
+			fmt.Fprintf(&b, "%s  style.stroke-width: 3\n", indent)
+		}
+		fmt.Fprintf(&b, "%s}\n", indent)
+	}
+	for i, c := range g.Clusters {
+		fmt.Fprintf(&b, "cluster_%d: %s {\n", i, quoteD2(c.Label))
+		b.WriteString("  style.stroke-dash: 3\n")
+		for _, id := range c.Nodes {
+			writeNode("  ", g.node(id))
+		}
+		b.WriteString("}\n")
+	}
+	for _, n := range g.unclustered() {
+		writeNode("", n)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "%s -> %s: %s {%s}\n", ref(e.IssueID), ref(e.DependsOnID), quoteD2(string(e.Type)), styleFor(e.Type).d2)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// jsonGraphNode and jsonGraphEdge are the json-graph wire format: a plain
+// node/edge list for tools that want to run their own layout.
+type jsonGraphNode struct {
+	ID       string          `json:"id"`
+	Title    string          `json:"title"`
+	Status   types.Status    `json:"status"`
+	Priority int             `json:"priority"`
+	Type     types.IssueType `json:"type"`
+	Color    string          `json:"color"`
+	Cluster  string          `json:"cluster,omitempty"`
+	Root     bool            `json:"root,omitempty"`
+}
+
+type jsonGraphEdge struct {
+	Source string               `json:"source"`
+	Target string               `json:"target"`
+	Type   types.DependencyType `json:"type"`
+}
+
+type jsonGraph struct {
+	Directed bool            `json:"directed"`
+	Nodes    []jsonGraphNode `json:"nodes"`
+	Edges    []jsonGraphEdge `json:"edges"`
+	Clusters []*graphCluster `json:"clusters,omitempty"`
+}
+
+func writeGraphJSON(w io.Writer, g *exportGraph) error {
+	out := jsonGraph{Directed: true, Nodes: []jsonGraphNode{}, Edges: []jsonGraphEdge{}, Clusters: g.Clusters}
+	for _, n := range g.Nodes {
+		out.Nodes = append(out.Nodes, jsonGraphNode{
+			ID:       n.ID,
+			Title:    n.Title,
+			Status:   n.Status,
+			Priority: n.Priority,
+			Type:     n.IssueType,
+			Color:    statusFillColor(n.Status),
+			Cluster:  g.clusterOf[n.ID],
+			Root:     n.ID == g.RootID,
+		})
+	}
+	for _, e := range g.Edges {
+		out.Edges = append(out.Edges, jsonGraphEdge{Source: e.IssueID, Target: e.DependsOnID, Type: e.Type})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func testExportGraph() *exportGraph {
+	issues := []*types.Issue{
+		{ID: "bd-2.1", Title: "Child", Status: types.StatusInProgress, IssueType: types.TypeTask},
+		{ID: "bd-2", Title: "Epic", Status: types.StatusOpen, IssueType: types.TypeEpic},
+		{ID: "bd-3", Title: `Say "hi"`, Status: types.StatusClosed, IssueType: types.TypeBug},
+	}
+	deps := []*types.Dependency{
+		{IssueID: "bd-2.1", DependsOnID: "bd-2", Type: types.DepParentChild},
+		{IssueID: "bd-2.1", DependsOnID: "bd-3", Type: types.DepBlocks},
+		{IssueID: "bd-3", DependsOnID: "bd-2", Type: types.DepRelated},
+		{IssueID: "bd-3", DependsOnID: "bd-9", Type: types.DepBlocks}, // outside the graph
+	}
+	return newExportGraph("bd-2", issues, deps)
+}
+
+func TestNewExportGraph(t *testing.T) {
+	g := testExportGraph()
+	if len(g.Nodes) != 3 || g.Nodes[0].ID != "bd-2" {
+		t.Errorf("nodes = %v, want 3 sorted by ID", g.Nodes)
+	}
+	if len(g.Edges) != 3 {
+		t.Errorf("edges = %d, want 3 (edge to bd-9 dropped)", len(g.Edges))
+	}
+}
+
+func TestExportGraphClusterByEpic(t *testing.T) {
+	g := testExportGraph()
+	g.clusterByEpic()
+	if len(g.Clusters) != 1 || g.Clusters[0].ID != "epic:bd-2" {
+		t.Fatalf("clusters = %+v, want one for epic bd-2", g.Clusters)
+	}
+	if got := g.Clusters[0].Nodes; len(got) != 2 {
+		t.Errorf("cluster nodes = %v, want the epic and its child", got)
+	}
+	if un := g.unclustered(); len(un) != 1 || un[0].ID != "bd-3" {
+		t.Errorf("unclustered = %v, want bd-3", un)
+	}
+}
+
+func TestExportGraphClusterByLabel(t *testing.T) {
+	g := testExportGraph()
+	g.clusterByLabel(map[string][]string{"bd-2": {"ui", "backend"}, "bd-3": {"backend"}})
+	if len(g.Clusters) != 1 || g.Clusters[0].Label != "backend" || len(g.Clusters[0].Nodes) != 2 {
+		t.Errorf("clusters = %+v, want backend with bd-2 and bd-3", g.Clusters)
+	}
+}
+
+func TestWriteGraphFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{graphFormatDOT, []string{
+			"digraph beads {",
+			`"bd-2.1" -> "bd-3" [label="blocks", style=bold];`,
+			`"bd-3" -> "bd-2" [label="related", style=dotted, dir=none];`,
+			`fillcolor="#c8e6c9"`,
+			`Say \"hi\"`,
+			"subgraph cluster_0 {",
+		}},
+		{graphFormatMermaid, []string{
+			"flowchart LR",
+			"bd-2_1 ==>|blocks| bd-3",
+			"bd-2_1 -.->|parent-child| bd-2",
+			"class bd-2_1 status_in_progress",
+			"#quot;hi#quot;",
+			"subgraph cluster_0",
+		}},
+		{graphFormatD2, []string{
+			"direction: right",
+			`cluster_0."bd-2.1" -> "bd-3": "blocks" {style.stroke-width: 3}`,
+			`style.fill: "#fff3b0"`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			g := testExportGraph()
+			g.clusterByEpic()
+			var buf bytes.Buffer
+			if err := writeGraph(&buf, g, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output is missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteGraphJSON(t *testing.T) {
+	g := testExportGraph()
+	g.clusterByEpic()
+	var buf bytes.Buffer
+	if err := writeGraph(&buf, g, graphFormatJSONGraph); err != nil {
+		t.Fatal(err)
+	}
+	var out jsonGraph
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !out.Directed || len(out.Nodes) != 3 || len(out.Edges) != 3 || len(out.Clusters) != 1 {
+		t.Errorf("graph = %+v", out)
+	}
+	if !out.Nodes[0].Root || out.Nodes[0].Cluster != "epic:bd-2" {
+		t.Errorf("root node = %+v, want root in the epic cluster", out.Nodes[0])
+	}
+}
+
+func TestWriteGraphInvalidFormat(t *testing.T) {
+	if err := writeGraph(&bytes.Buffer{}, testExportGraph(), "png"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	opdaemon "github.com/steveyegge/beads/internal/turso/daemon"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+// HistoryMutation is one row of 'bd history ops': a bd command that
+// 'bd undo --steps Step' would reverse.
+type HistoryMutation struct {
+	Step    int       `json:"step"`
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor"`
+	Summary string    `json:"summary"`
+	Events  int       `json:"events"`
+}
+
+// HistoryOperation is a VCS operation, with the number of issue changes
+// the daemon imported because of it (jj only).
+type HistoryOperation struct {
+	ID          string    `json:"id"`
+	Time        time.Time `json:"time,omitempty"`
+	Description string    `json:"description"`
+	Imported    int       `json:"imported,omitempty"`
+}
+
+// HistoryOps is the output of 'bd history ops'.
+type HistoryOps struct {
+	Mutations  []HistoryMutation  `json:"mutations"`
+	VCS        string             `json:"vcs,omitempty"`
+	Operations []HistoryOperation `json:"operations,omitempty"`
+}
+
+var historyCmd = &cobra.Command{
+	Use:     "history",
+	GroupID: "views",
+	Short:   "Show the history of changes to the issue database",
+}
+
+var historyOpsCmd = &cobra.Command{
+	Use:   "ops",
+	Short: "List recent bd mutations and VCS operations",
+	Long: `List the most recent bd mutations, numbered the way 'bd undo --steps'
+counts them, followed by the VCS operation log (jj operations, or the git
+reflog). jj operations that made the daemon import beads changes show how
+many changes they brought in.
+
+Examples:
+  bd history ops               # Last 10 mutations and operations
+  bd history ops --limit 25
+  bd history ops --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit < 1 {
+			limit = 10
+		}
+
+		if err := ensureDirectMode("history reads the events table directly"); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		ctx := rootCtx
+
+		lister, ok := store.(recentEventLister)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: history is not supported by this storage backend\n")
+			os.Exit(1)
+		}
+		events, err := lister.GetRecentEvents(ctx, undoEventScan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		result := HistoryOps{Mutations: []HistoryMutation{}}
+		for i, m := range groupMutations(events) {
+			if i == limit {
+				break
+			}
+			result.Mutations = append(result.Mutations, HistoryMutation{
+				Step:    i + 1,
+				Time:    m.End,
+				Actor:   m.Actor,
+				Summary: m.Summary(),
+				Events:  len(m.Events),
+			})
+		}
+
+		// Count imported changes per jj operation
+		imported := make(map[string]int)
+		for _, e := range events {
+			if e.SourceOp != "" {
+				imported[opdaemon.ShortOpID(e.SourceOp)]++
+			}
+		}
+
+		if jsonlPath := findJSONLPath(); jsonlPath != "" {
+			v, err := vcs.NewFactory(vcs.WithCache(false), vcs.WithPreferredType(vcs.PreferredVCS())).Create(filepath.Dir(jsonlPath))
+			if err == nil {
+				result.VCS = string(v.Name())
+				ops, err := v.GetOperationLog(limit)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to read %s operation log: %v\n", v.Name(), err)
+				}
+				for _, op := range ops {
+					result.Operations = append(result.Operations, HistoryOperation{
+						ID:          op.ID,
+						Time:        op.Timestamp,
+						Description: op.Description,
+						Imported:    imported[opdaemon.ShortOpID(op.ID)],
+					})
+				}
+			}
+		}
+
+		if jsonOutput {
+			outputJSON(result)
+			return
+		}
+		printHistoryOps(result)
+	},
+}
+
+func init() {
+	historyOpsCmd.Flags().IntP("limit", "n", 10, "Number of mutations and operations to show")
+	historyCmd.AddCommand(historyOpsCmd)
+	rootCmd.AddCommand(historyCmd)
+}
+
+func printHistoryOps(h HistoryOps) {
+	fmt.Printf("\n%s\n", ui.RenderAccent("bd mutations (bd undo --steps N)"))
+	if len(h.Mutations) == 0 {
+		fmt.Printf("  (none)\n")
+	}
+	for _, m := range h.Mutations {
+		fmt.Printf("  %2d  %s  %-16s %s\n", m.Step, m.Time.Local().Format("2006-01-02 15:04:05"), m.Actor, m.Summary)
+	}
+
+	if h.VCS == "" {
+		fmt.Println()
+		return
+	}
+	fmt.Printf("\n%s\n", ui.RenderAccent(h.VCS+" operations"))
+	if len(h.Operations) == 0 {
+		fmt.Printf("  (none)\n")
+	}
+	for _, op := range h.Operations {
+		when := "                   "
+		if !op.Time.IsZero() {
+			when = op.Time.Local().Format("2006-01-02 15:04:05")
+		}
+		line := fmt.Sprintf("  %s  %s  %s", ui.RenderID(opdaemon.ShortOpID(op.ID)), when, op.Description)
+		if op.Imported > 0 {
+			line += ui.RenderMuted(fmt.Sprintf("  (imported %d change(s))", op.Imported))
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"cmp"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/beads/internal/debug"
+	"github.com/steveyegge/beads/internal/formats"
 	"github.com/steveyegge/beads/internal/storage/sqlite"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/utils"
@@ -29,6 +31,37 @@ var importCmd = &cobra.Command{
 
 Reads from stdin by default, or use -i flag for file input.
 
+With --format markdown-dir, -i names a directory written by
+'bd export --format markdown-dir' and edits to its files are applied back.
+Files without an id create issues. An issue that changed since its file was
+exported is reported as a conflict and skipped unless --overwrite is given.
+Applied files are rewritten so they can be edited again.
+
+With --format csv, the first row is the header. Columns are matched to issue
+fields by name (id, title, description, status, priority, type, assignee,
+labels, parent, external_ref, ...) or mapped with --mapping, a YAML file:
+
+  columns:            # column header -> field
+    Key: external_ref
+    Summary: title
+    State: status
+    Tags: labels
+    Epic: parent      # parent id or external_ref -> parent-child dependency
+  values:             # cell value maps, per field
+    status: {"To Do": open, "In Progress": in_progress, Done: closed}
+    priority: {High: 1, Medium: 2, Low: 3}
+  label_separator: ";"
+
+Rows go through the regular importer, so collision detection and prefix
+handling apply, and a row whose external_ref already exists updates that
+issue. Map a stable key column to external_ref to re-import a sheet safely.
+
+Other formats come from the format registry ('bd export --list-formats'),
+including external ones: an executable named bd-format-<name> on PATH
+provides --format <name>. It is run as 'bd-format-<name> import' with the
+input on stdin and must write JSONL issues to stdout; --param key=value
+settings are passed as arguments.
+
 Behavior:
   - Existing issues (same ID) are updated
   - New issues are created
@@ -36,8 +69,9 @@ Behavior:
   - Use --dedupe-after to find and merge content duplicates after import
   - Use --dry-run to preview changes without applying them
 
-NOTE: Import requires direct database access and does not work with daemon mode.
-      The command automatically uses --no-daemon when executed.`,
+NOTE: bd import always opens the database directly (as with --no-daemon) for
+      collision detection and transaction handling. A running daemon picks
+      up the changes; RPC clients can import through the daemon instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		CheckReadonly("import")
 		// Check for positional arguments (common mistake: bd import file.jsonl instead of bd import -i file.jsonl)
@@ -101,6 +135,34 @@ NOTE: Import requires direct database access and does not work with daemon mode.
 		protectLeftSnapshot, _ := cmd.Flags().GetBool("protect-left-snapshot")
 		noGitHistory, _ := cmd.Flags().GetBool("no-git-history")
 		_ = noGitHistory // Accepted for compatibility with bd sync subprocess calls
+		format, _ := cmd.Flags().GetString("format")
+		overwrite, _ := cmd.Flags().GetBool("overwrite")
+
+		mappingPath, _ := cmd.Flags().GetString("mapping")
+
+		f, err := formats.LookupImporter(format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		params, err := formatParams(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if mappingPath != "" {
+			params["mapping"] = mappingPath
+		}
+		if !f.Capabilities().Streaming && input == "" {
+			fmt.Fprintf(os.Stderr, "Error: --format %s needs -i <directory>\n", format)
+			os.Exit(1)
+		}
+		// markdown-dir applies its files itself so it can detect conflicts
+		// and rewrite them; see importMarkdownDir
+		if format == "markdown-dir" {
+			runMarkdownDirImport(rootCtx, input, dryRun, overwrite)
+			return
+		}
 
 		// Check if stdin is being used interactively (not piped)
 		if input == "" && term.IsTerminal(int(os.Stdin.Fd())) {
@@ -116,7 +178,7 @@ NOTE: Import requires direct database access and does not work with daemon mode.
 
 		// Open input
 		in := os.Stdin
-		if input != "" {
+		if input != "" && f.Capabilities().Streaming {
 			// #nosec G304 - user-provided file path is intentional
 			f, err := os.Open(input)
 			if err != nil {
@@ -138,7 +200,29 @@ NOTE: Import requires direct database access and does not work with daemon mode.
 		var allIssues []*types.Issue
 		lineNum := 0
 
-		for scanner.Scan() {
+		// Other formats are decoded in one go and skip the JSONL loop below.
+		// Decoders assign IDs to rows that have none; a database without a
+		// prefix gets one detected from the decoded IDs just before that.
+		if format != "jsonl" {
+			formatOpts := formats.Options{Store: store, Params: params}
+			formatOpts.Prefix, _ = store.GetConfig(ctx, "issue_prefix")
+			formatOpts.ResolvePrefix = func(ctx context.Context, decoded []*types.Issue) (string, error) {
+				ensureImportPrefix(ctx, decoded)
+				return store.GetConfig(ctx, "issue_prefix")
+			}
+			var err error
+			if f.Capabilities().Streaming {
+				allIssues, err = formats.Decode(ctx, f, in, formatOpts)
+			} else {
+				allIssues, err = formats.DecodeDir(ctx, f, input, formatOpts)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading %s input: %v\n", format, err)
+				os.Exit(1)
+			}
+		}
+
+		for format == "jsonl" && scanner.Scan() {
 			lineNum++
 			rawLine := scanner.Bytes()
 			line := string(rawLine)
@@ -218,39 +302,7 @@ NOTE: Import requires direct database access and does not work with daemon mode.
 		}
 
 		// Check if database needs initialization (prefix not set)
-		// Detect prefix from the imported issues
-		initCtx := rootCtx
-		configuredPrefix, err2 := store.GetConfig(initCtx, "issue_prefix")
-		if err2 != nil || strings.TrimSpace(configuredPrefix) == "" {
-			// Database exists but not initialized - detect prefix from issues
-			detectedPrefix := detectPrefixFromIssues(allIssues)
-			prefixSource := "issues"
-			if detectedPrefix == "" {
-				// No issues to import or couldn't detect prefix, use directory name
-				// But avoid using ".beads" as prefix - go up one level
-				cwd, err := os.Getwd()
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
-					os.Exit(1)
-				}
-				dirName := filepath.Base(cwd)
-				if dirName == ".beads" || dirName == "beads" {
-					// Running from inside .beads/ - use parent directory
-					detectedPrefix = filepath.Base(filepath.Dir(cwd))
-				} else {
-					detectedPrefix = dirName
-				}
-				prefixSource = "directory"
-			}
-			detectedPrefix = strings.TrimRight(detectedPrefix, "-")
-
-			if err := store.SetConfig(initCtx, "issue_prefix", detectedPrefix); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: failed to set issue prefix: %v\n", err)
-				os.Exit(1)
-			}
-
-			fmt.Fprintf(os.Stderr, "✓ Initialized database with prefix '%s' (detected from %s)\n", detectedPrefix, prefixSource)
-		}
+		ensureImportPrefix(ctx, allIssues)
 
 		// Phase 2: Use shared import logic
 		opts := ImportOptions{
@@ -383,7 +435,8 @@ NOTE: Import requires direct database access and does not work with daemon mode.
 		// ALWAYS update metadata after successful import, even if no changes were made (fixes staleness check)
 		// This ensures that running `bd import` marks the database as fresh for staleness detection
 		// Renamed from last_import_hash - more accurate since updated on both import AND export
-		if input != "" {
+		// CSV input is not the JSONL file, so it says nothing about JSONL staleness
+		if input != "" && format == "jsonl" {
 			if currentHash, err := computeJSONLHash(input); err == nil {
 				if err := store.SetMetadata(ctx, "jsonl_content_hash", currentHash); err != nil {
 					// Non-fatal warning: Metadata update failures are intentionally non-fatal to prevent blocking
@@ -416,8 +469,10 @@ NOTE: Import requires direct database access and does not work with daemon mode.
 		// 2. Without mtime update, bd sync refuses to export (thinks JSONL is newer)
 		// 3. This can happen after git pull updates JSONL mtime but content is identical
 		// Fix for: refusing to export: JSONL is newer than database (import first to avoid data loss)
-		if err := TouchDatabaseFile(dbPath, input); err != nil {
-			debug.Logf("Warning: failed to update database mtime: %v", err)
+		if format == "jsonl" {
+			if err := TouchDatabaseFile(dbPath, input); err != nil {
+				debug.Logf("Warning: failed to update database mtime: %v", err)
+			}
 		}
 
 		// Print summary
@@ -751,6 +806,43 @@ func attemptAutoMerge(conflictedPath string) error {
 
 // detectPrefixFromIssues extracts the common prefix from issue IDs
 // Uses utils.ExtractIssuePrefix which handles multi-part prefixes correctly
+// ensureImportPrefix initializes an uninitialized database's issue prefix
+// from the imported issues, or else from the directory name.
+func ensureImportPrefix(ctx context.Context, issues []*types.Issue) {
+	configuredPrefix, err := store.GetConfig(ctx, "issue_prefix")
+	if err == nil && strings.TrimSpace(configuredPrefix) != "" {
+		return
+	}
+	// Database exists but not initialized - detect prefix from issues
+	detectedPrefix := detectPrefixFromIssues(issues)
+	prefixSource := "issues"
+	if detectedPrefix == "" {
+		// No issues to import or couldn't detect prefix, use directory name
+		// But avoid using ".beads" as prefix - go up one level
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+			os.Exit(1)
+		}
+		dirName := filepath.Base(cwd)
+		if dirName == ".beads" || dirName == "beads" {
+			// Running from inside .beads/ - use parent directory
+			detectedPrefix = filepath.Base(filepath.Dir(cwd))
+		} else {
+			detectedPrefix = dirName
+		}
+		prefixSource = "directory"
+	}
+	detectedPrefix = strings.TrimRight(detectedPrefix, "-")
+
+	if err := store.SetConfig(ctx, "issue_prefix", detectedPrefix); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to set issue prefix: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Initialized database with prefix '%s' (detected from %s)\n", detectedPrefix, prefixSource)
+}
+
 func detectPrefixFromIssues(issues []*types.Issue) string {
 	if len(issues) == 0 {
 		return ""
@@ -779,7 +871,11 @@ func detectPrefixFromIssues(issues []*types.Issue) string {
 }
 
 func init() {
-	importCmd.Flags().StringP("input", "i", "", "Input file (default: stdin)")
+	importCmd.Flags().StringP("input", "i", "", "Input file, or directory for markdown-dir (default: stdin)")
+	importCmd.Flags().String("format", "jsonl", "Import format: jsonl, csv, markdown-dir, or an external bd-format-<name> (see bd export --list-formats)")
+	importCmd.Flags().String("mapping", "", "YAML column/value mapping for --format csv")
+	importCmd.Flags().StringArray("param", nil, "Format parameter as key=value (repeatable)")
+	importCmd.Flags().Bool("overwrite", false, "With markdown-dir, apply edits even if the issue changed since export")
 	importCmd.Flags().BoolP("skip-existing", "s", false, "Skip existing issues instead of updating them")
 	importCmd.Flags().Bool("strict", false, "Fail on dependency errors instead of treating them as warnings")
 	importCmd.Flags().Bool("dedupe-after", false, "Detect and report content duplicates after import")
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/formats"
+	"github.com/steveyegge/beads/internal/linear"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// csvFields are the issue fields a CSV column can map to. "parent" becomes
+// a parent-child dependency. updated_at is deliberately absent: rows are
+// stamped with the import time so spreadsheet edits win over the database.
+var csvFields = []string{
+	"id", "title", "description", "design", "acceptance_criteria", "notes",
+	"status", "priority", "type", "assignee", "labels", "parent",
+	"external_ref", "created_at",
+}
+
+// csvFieldAliases lets headers use common alternative names when there is
+// no mapping file.
+var csvFieldAliases = map[string]string{
+	"issue_type": "type",
+	"acceptance": "acceptance_criteria",
+	"parent_id":  "parent",
+	"epic":       "parent",
+	"label":      "labels",
+	"owner":      "assignee",
+	"summary":    "title",
+}
+
+// csvMapping describes how a spreadsheet maps onto issues. It is read from
+// the YAML file given with --mapping:
+//
+//	columns:
+//	  Key: external_ref
+//	  Summary: title
+//	  State: status
+//	  Tags: labels
+//	  Epic: parent
+//	values:
+//	  status:
+//	    To Do: open
+//	    Done: closed
+//	  priority:
+//	    High: 1
+//	label_separator: ";"
+type csvMapping struct {
+	// Columns maps column headers to issue fields.
+	Columns map[string]string `yaml:"columns"`
+	// Values maps cell values per field (status, priority, type, ...)
+	// before they are parsed. Matching ignores case.
+	Values map[string]map[string]string `yaml:"values"`
+	// LabelSeparator splits the labels column (default ",").
+	LabelSeparator string `yaml:"label_separator"`
+}
+
+// loadCSVMapping reads a mapping file.
+func loadCSVMapping(path string) (*csvMapping, error) {
+	// #nosec G304 -- user-provided mapping file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping: %w", err)
+	}
+	var m csvMapping
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid mapping %s: %w", path, err)
+	}
+	for column, field := range m.Columns {
+		if !isCSVField(field) {
+			return nil, fmt.Errorf("mapping %s: column %q maps to unknown field %q (valid: %s)", path, column, field, strings.Join(csvFields, ", "))
+		}
+	}
+	return &m, nil
+}
+
+func isCSVField(field string) bool {
+	for _, f := range csvFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldFor returns the field a header maps to, or "" to ignore the column.
+// Without explicit columns, headers are matched to field names.
+func (m *csvMapping) fieldFor(header string) string {
+	if m != nil && len(m.Columns) > 0 {
+		if field, ok := m.Columns[header]; ok {
+			return field
+		}
+		for column, field := range m.Columns {
+			if strings.EqualFold(strings.TrimSpace(column), strings.TrimSpace(header)) {
+				return field
+			}
+		}
+		return ""
+	}
+	name := strings.ToLower(strings.Join(strings.Fields(header), "_"))
+	if alias, ok := csvFieldAliases[name]; ok {
+		return alias
+	}
+	if isCSVField(name) {
+		return name
+	}
+	return ""
+}
+
+// mapValue applies the value map of a field.
+func (m *csvMapping) mapValue(field, value string) string {
+	value = strings.TrimSpace(value)
+	if m == nil {
+		return value
+	}
+	for from, to := range m.Values[field] {
+		if strings.EqualFold(strings.TrimSpace(from), value) {
+			return to
+		}
+	}
+	return value
+}
+
+func (m *csvMapping) labelSeparator() string {
+	if m != nil && m.LabelSeparator != "" {
+		return m.LabelSeparator
+	}
+	return ","
+}
+
+// csvImport is a parsed spreadsheet. Rows reference their parent by the
+// parent's id or external_ref, which may be another row, so IDs are assigned
+// and parents resolved in a second step once the prefix is known.
+type csvImport struct {
+	issues  []*types.Issue
+	parents map[*types.Issue]string // row -> raw parent value
+	mapped  map[string]bool         // fields that have a column
+}
+
+// parseCSVIssues reads a CSV with a header row into issues.
+func parseCSVIssues(r io.Reader, m *csvMapping) (*csvImport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	fields := make([]string, len(header))
+	hasTitle := false
+	for i, h := range header {
+		fields[i] = m.fieldFor(strings.TrimPrefix(h, "\ufeff"))
+		hasTitle = hasTitle || fields[i] == "title"
+	}
+	if !hasTitle {
+		return nil, fmt.Errorf("no column maps to title (headers: %s)", strings.Join(header, ", "))
+	}
+
+	result := &csvImport{parents: make(map[*types.Issue]string), mapped: make(map[string]bool)}
+	for _, f := range fields {
+		if f != "" {
+			result.mapped[f] = true
+		}
+	}
+	now := time.Now()
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		issue := &types.Issue{CreatedAt: now, UpdatedAt: now, Priority: 2}
+		empty := true
+		for i, cell := range record {
+			if i >= len(fields) || fields[i] == "" {
+				continue
+			}
+			value := m.mapValue(fields[i], cell)
+			if value == "" {
+				continue
+			}
+			empty = false
+			if err := setCSVField(issue, fields[i], value, m, result); err != nil {
+				return nil, fmt.Errorf("row %d: %w", row, err)
+			}
+		}
+		if empty {
+			continue // blank spreadsheet rows
+		}
+		if issue.Title == "" {
+			return nil, fmt.Errorf("row %d: title is empty", row)
+		}
+		issue.SetDefaults()
+		if issue.Status == types.StatusClosed && issue.ClosedAt == nil {
+			closedAt := issue.UpdatedAt
+			issue.ClosedAt = &closedAt
+		}
+		result.issues = append(result.issues, issue)
+	}
+	return result, nil
+}
+
+func setCSVField(issue *types.Issue, field, value string, m *csvMapping, result *csvImport) error {
+	switch field {
+	case "id":
+		issue.ID = value
+	case "title":
+		issue.Title = value
+	case "description":
+		issue.Description = value
+	case "design":
+		issue.Design = value
+	case "acceptance_criteria":
+		issue.AcceptanceCriteria = value
+	case "notes":
+		issue.Notes = value
+	case "status":
+		issue.Status = types.Status(strings.ToLower(value))
+	case "priority":
+		p := validation.ParsePriority(value)
+		if p < 0 {
+			return fmt.Errorf("invalid priority %q (map it under values.priority)", value)
+		}
+		issue.Priority = p
+	case "type":
+		t, err := validation.ParseIssueType(strings.ToLower(value))
+		if err != nil {
+			return fmt.Errorf("invalid type %q (map it under values.type)", value)
+		}
+		issue.IssueType = t
+	case "assignee":
+		issue.Assignee = value
+	case "labels":
+		for _, label := range strings.Split(value, m.labelSeparator()) {
+			if label = strings.TrimSpace(label); label != "" {
+				issue.Labels = append(issue.Labels, label)
+			}
+		}
+	case "parent":
+		result.parents[issue] = value
+	case "external_ref":
+		issue.ExternalRef = &value
+	case "created_at":
+		t, err := parseTimeFlag(value)
+		if err != nil {
+			return fmt.Errorf("invalid created_at %q: %w", value, err)
+		}
+		issue.CreatedAt = t
+	}
+	return nil
+}
+
+// resolve assigns IDs and turns parent references into dependencies. Rows
+// whose external_ref is already in the database take that issue's ID, so the
+// importer updates it in place; other rows without an id get a hash ID.
+// Fields without a column keep their database values for existing issues,
+// since the importer replaces every field on update.
+func (c *csvImport) resolve(ctx context.Context, opts formats.Options) error {
+	s := opts.Store
+	existing, err := s.SearchIssues(ctx, "", types.IssueFilter{IncludeTombstones: true})
+	if err != nil {
+		return fmt.Errorf("failed to load existing issues: %w", err)
+	}
+	usedIDs := make(map[string]bool, len(existing))
+	idByRef := make(map[string]string)
+	for _, issue := range existing {
+		usedIDs[issue.ID] = true
+		if issue.ExternalRef != nil && *issue.ExternalRef != "" {
+			idByRef[*issue.ExternalRef] = issue.ID
+		}
+	}
+	for _, issue := range c.issues {
+		if issue.ID == "" && issue.ExternalRef != nil {
+			issue.ID = idByRef[*issue.ExternalRef]
+		}
+	}
+	for _, issue := range c.issues {
+		if issue.ID == "" || !usedIDs[issue.ID] {
+			continue
+		}
+		current, err := s.GetIssue(ctx, issue.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", issue.ID, err)
+		}
+		if current != nil {
+			c.keepUnmapped(issue, current)
+		}
+	}
+	prefix, err := opts.IDPrefix(ctx, c.issues)
+	if err != nil {
+		return err
+	}
+	if err := linear.GenerateIssueIDs(c.issues, prefix, "csv-import", linear.IDGenerationOptions{UsedIDs: usedIDs}); err != nil {
+		return err
+	}
+
+	// Parents may name a row by id or external_ref, or an existing issue
+	for _, issue := range c.issues {
+		if issue.ExternalRef != nil && *issue.ExternalRef != "" {
+			idByRef[*issue.ExternalRef] = issue.ID
+		}
+	}
+	for _, issue := range c.issues {
+		parent, ok := c.parents[issue]
+		if !ok {
+			continue
+		}
+		parentID := parent
+		if id, found := idByRef[parent]; found {
+			parentID = id
+		}
+		if parentID == issue.ID {
+			return fmt.Errorf("%s (%s) is its own parent", issue.ID, issue.Title)
+		}
+		issue.Dependencies = append(issue.Dependencies, &types.Dependency{
+			IssueID:     issue.ID,
+			DependsOnID: parentID,
+			Type:        types.DepParentChild,
+			CreatedAt:   issue.CreatedAt,
+		})
+	}
+	return nil
+}
+
+// keepUnmapped copies fields the spreadsheet has no column for from the
+// stored issue.
+func (c *csvImport) keepUnmapped(issue, current *types.Issue) {
+	if !c.mapped["description"] {
+		issue.Description = current.Description
+	}
+	if !c.mapped["design"] {
+		issue.Design = current.Design
+	}
+	if !c.mapped["acceptance_criteria"] {
+		issue.AcceptanceCriteria = current.AcceptanceCriteria
+	}
+	if !c.mapped["notes"] {
+		issue.Notes = current.Notes
+	}
+	if !c.mapped["status"] {
+		issue.Status = current.Status
+		issue.ClosedAt = current.ClosedAt
+	}
+	if !c.mapped["priority"] {
+		issue.Priority = current.Priority
+	}
+	if !c.mapped["type"] {
+		issue.IssueType = current.IssueType
+	}
+	if !c.mapped["assignee"] {
+		issue.Assignee = current.Assignee
+	}
+	if !c.mapped["external_ref"] {
+		issue.ExternalRef = current.ExternalRef
+	}
+	if !c.mapped["created_at"] {
+		issue.CreatedAt = current.CreatedAt
+	}
+	if issue.Status == types.StatusClosed && current.ClosedAt != nil {
+		issue.ClosedAt = current.ClosedAt
+	}
+	issue.CloseReason = current.CloseReason
+	issue.Pinned = current.Pinned
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/formats"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestParseCSVIssuesWithMapping(t *testing.T) {
+	m := &csvMapping{
+		Columns: map[string]string{"Key": "external_ref", "Summary": "title", "State": "status", "Prio": "priority", "Tags": "labels", "Epic": "parent"},
+		Values: map[string]map[string]string{
+			"status":   {"To Do": "open", "Done": "closed"},
+			"priority": {"High": "1"},
+		},
+		LabelSeparator: ";",
+	}
+	in := "\ufeffKey,Summary,State,Prio,Tags,Epic,Ignored\n" +
+		"J-1,Epic,to do,High,a; b,,x\n" +
+		",,,,,,\n" +
+		"J-2,Child,Done,P3,c,J-1,y\n"
+	parsed, err := parseCSVIssues(strings.NewReader(in), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.issues) != 2 {
+		t.Fatalf("got %d issues, want 2", len(parsed.issues))
+	}
+	epic, child := parsed.issues[0], parsed.issues[1]
+	if epic.Status != types.StatusOpen || epic.Priority != 1 || *epic.ExternalRef != "J-1" {
+		t.Errorf("epic = %+v", epic)
+	}
+	if strings.Join(epic.Labels, "|") != "a|b" {
+		t.Errorf("labels = %v", epic.Labels)
+	}
+	if child.Status != types.StatusClosed || child.ClosedAt == nil || child.Priority != 3 {
+		t.Errorf("child = %+v", child)
+	}
+	if parsed.parents[child] != "J-1" {
+		t.Errorf("parent = %q", parsed.parents[child])
+	}
+
+	if _, err := parseCSVIssues(strings.NewReader("Key,State\nJ-1,open\n"), m); err == nil {
+		t.Error("expected an error without a title column")
+	}
+	if _, err := parseCSVIssues(strings.NewReader("Summary,Prio\nx,Urgent\n"), m); err == nil {
+		t.Error("expected an error for an unmapped priority")
+	}
+}
+
+func TestCSVImportResolve(t *testing.T) {
+	tmpDir := t.TempDir()
+	s := newTestStore(t, filepath.Join(tmpDir, ".beads", "beads.db"))
+	ctx := context.Background()
+
+	ref := "J-1"
+	existing := &types.Issue{Title: "Epic", Description: "keep", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeEpic, ExternalRef: &ref}
+	if err := s.CreateIssue(ctx, existing, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	in := "external_ref,title,parent\nJ-1,Epic renamed,\nJ-2,Child,J-1\n,Orphan,test-missing\n"
+	parsed, err := parseCSVIssues(strings.NewReader(in), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.resolve(ctx, formats.Options{Store: s, Prefix: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	epic, child, orphan := parsed.issues[0], parsed.issues[1], parsed.issues[2]
+	if epic.ID != existing.ID {
+		t.Errorf("external_ref match got ID %q, want %q", epic.ID, existing.ID)
+	}
+	if epic.Description != "keep" || epic.IssueType != types.TypeEpic || epic.Priority != 1 {
+		t.Errorf("unmapped fields were not kept: %+v", epic)
+	}
+	if !strings.HasPrefix(child.ID, "test-") || child.ID == epic.ID {
+		t.Errorf("child ID = %q", child.ID)
+	}
+	if len(child.Dependencies) != 1 || child.Dependencies[0].DependsOnID != epic.ID || child.Dependencies[0].Type != types.DepParentChild {
+		t.Errorf("child deps = %+v", child.Dependencies)
+	}
+	if len(orphan.Dependencies) != 1 || orphan.Dependencies[0].DependsOnID != "test-missing" {
+		t.Errorf("raw parent ID not kept: %+v", orphan.Dependencies)
+	}
+}
+
+func TestWriteCSVExport(t *testing.T) {
+	ref := "J-9"
+	issue := &types.Issue{
+		ID: "test-1", Title: `Say "hi", world`, Status: types.StatusOpen, Priority: 0, IssueType: types.TypeBug,
+		Labels: []string{"a", "b"}, ExternalRef: &ref,
+		Dependencies: []*types.Dependency{
+			{IssueID: "test-1", DependsOnID: "test-0", Type: types.DepParentChild},
+			{IssueID: "test-1", DependsOnID: "test-2", Type: types.DepBlocks},
+		},
+	}
+	var buf bytes.Buffer
+	if err := writeCSVExport(&buf, []*types.Issue{issue}, []string{"id", "title", "labels", "parent", "dependencies", "external_ref"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,title,labels,parent,dependencies,external_ref\n" +
+		`test-1,"Say ""hi"", world","a,b",test-0,blocks:test-2,J-9` + "\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// The export re-imports with the default header matching
+	parsed, err := parseCSVIssues(&buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.issues[0]; got.ID != "test-1" || got.Title != issue.Title || parsed.parents[got] != "test-0" {
+		t.Errorf("round trip = %+v", got)
+	}
+
+	if err := validateCSVColumns([]string{"id", "nope"}); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+}
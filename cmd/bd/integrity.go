@@ -463,6 +463,15 @@ func computeDBHash(ctx context.Context, store storage.Storage) (string, error) {
 		issue.Comments = comments
 	}
 
+	// Populate attachment references
+	allAttachments, err := store.GetAllAttachments(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get attachments: %w", err)
+	}
+	for _, issue := range issues {
+		issue.Attachments = allAttachments[issue.ID]
+	}
+
 	// Serialize to JSON and hash
 	var buf bytes.Buffer
 	encoder := json.NewEncoder(&buf)
@@ -505,6 +505,15 @@ var listCmd = &cobra.Command{
 		if len(labelsAny) > 0 {
 			filter.LabelsAny = labelsAny
 		}
+		stateSpecs, _ := cmd.Flags().GetStringArray("state")
+		if len(stateSpecs) > 0 {
+			states, err := parseStateFilters(stateSpecs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			filter.States = states
+		}
 		if titleSearch != "" {
 			filter.TitleSearch = titleSearch
 		}
@@ -714,6 +723,7 @@ var listCmd = &cobra.Command{
 			if len(labelsAny) > 0 {
 				listArgs.LabelsAny = labelsAny
 			}
+			listArgs.States = filter.States
 			// Forward title search via Query field (searches title/description/id)
 			if titleSearch != "" {
 				listArgs.Query = titleSearch
@@ -997,6 +1007,7 @@ func init() {
 	listCmd.Flags().StringP("type", "t", "", "Filter by type (bug, feature, task, epic, chore, merge-request, molecule, gate, convoy). Aliases: mr→merge-request, feat→feature, mol→molecule")
 	listCmd.Flags().StringSliceP("label", "l", []string{}, "Filter by labels (AND: must have ALL). Can combine with --label-any")
 	listCmd.Flags().StringSlice("label-any", []string{}, "Filter by labels (OR: must have AT LEAST ONE). Can combine with --label")
+	listCmd.Flags().StringArray("state", []string{}, "Filter by state dimension (e.g., health=degraded; repeatable, AND). A schema default also matches issues with no value")
 	listCmd.Flags().String("title", "", "Filter by title text (case-insensitive substring match)")
 	listCmd.Flags().String("id", "", "Filter by specific issue IDs (comma-separated, e.g., bd-1,bd-5,bd-10)")
 	listCmd.Flags().IntP("limit", "n", 50, "Limit results (default 50, use 0 for unlimited)")
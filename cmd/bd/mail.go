@@ -1,88 +1,143 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/storage/sqlite"
 )
 
-// mailCmd delegates to an external mail provider.
-// This enables agents to use 'bd mail' consistently, while the actual
-// mail implementation is provided by the orchestrator.
+// mailCmd sends and reads messages between agents. When a mail delegate is
+// configured, every mail command is passed through to it, so orchestrators
+// can keep providing their own mail implementation. Otherwise the native
+// mailbox built on message issues is used.
 var mailCmd = &cobra.Command{
 	Use:   "mail [subcommand] [args...]",
-	Short: "Delegate to mail provider (e.g., gt mail)",
-	Long: `Delegates mail operations to an external mail provider.
+	Short: "Send and read agent messages",
+	Long: `Send and read messages between agents and humans.
 
-Agents often type 'bd mail' when working with beads, but mail functionality
-is typically provided by the orchestrator. This command bridges that gap
-by delegating to the configured mail provider.
+Messages are issues of type message: the subject is the title, the body is
+the description, and replies are threaded with replies-to dependencies
+(see 'bd show --thread').
 
-Configuration (checked in order):
+Addresses:
+  gt-emma            An agent bead (partial IDs work) or any identity
+  role:<role>        Every agent with that role_type
+  rig:<rig>          Every agent in that rig
+
+Your identity comes from --identity, BEADS_IDENTITY, the identity config
+setting, or git user.name. If your identity is an agent bead, your inbox
+also gets messages sent to its role and rig. Read and archived state is
+kept per recipient.
+
+Delegation (checked in order, takes precedence over the native mailbox):
   1. BEADS_MAIL_DELEGATE or BD_MAIL_DELEGATE environment variable
   2. 'mail.delegate' config setting (bd config set mail.delegate "gt mail")
 
+With a delegate, all arguments after 'bd mail' are passed through unchanged.
+
 Examples:
-  # Configure delegation (one-time setup)
-  export BEADS_MAIL_DELEGATE="gt mail"
-  # or
-  bd config set mail.delegate "gt mail"
-
-  # Then use bd mail as if it were gt mail
-  bd mail inbox                    # Lists inbox
-  bd mail send mayor/ -s "Hi"      # Sends mail
-  bd mail read msg-123             # Reads a message`,
-	DisableFlagParsing: true, // Pass all args through to delegate
+  bd mail send gt-emma -s "Review ready" -m "bd-42 is ready for review"
+  bd mail send role:witness rig:gastown -s "Deploy" -m "Freeze at 5pm"
+  bd mail inbox                    # Messages for you, unread first
+  bd mail read bd-a1b              # Show a message and mark it read
+  bd mail reply bd-a1b -m "On it"  # Reply to the sender
+  bd mail archive bd-a1b           # Remove it from your inbox`,
+	Args:               cobra.ArbitraryArgs,
+	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
 	Run: func(cmd *cobra.Command, args []string) {
-		// Handle --help and -h ourselves since flag parsing is disabled
-		for _, arg := range args {
-			if arg == "--help" || arg == "-h" {
-				_ = cmd.Help()
-				return
-			}
+		if delegateMail() {
+			return
 		}
-
-		// Find the mail delegate command
-		delegate := findMailDelegate()
-		if delegate == "" {
-			fmt.Fprintf(os.Stderr, "Error: no mail delegate configured\n\n")
-			fmt.Fprintf(os.Stderr, "bd mail delegates to an external mail provider.\n")
-			fmt.Fprintf(os.Stderr, "Configure one of:\n")
-			fmt.Fprintf(os.Stderr, "  export BEADS_MAIL_DELEGATE=\"gt mail\"   # Environment variable\n")
-			fmt.Fprintf(os.Stderr, "  bd config set mail.delegate \"gt mail\"  # Per-project config\n")
-			os.Exit(1)
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return
 		}
+		fmt.Fprintf(os.Stderr, "Error: unknown mail command %q\n", args[0])
+		fmt.Fprintf(os.Stderr, "Run 'bd mail --help' for the native commands, or configure a mail delegate.\n")
+		os.Exit(1)
+	},
+}
 
-		// Parse the delegate command (e.g., "gt mail" -> ["gt", "mail"])
-		parts := strings.Fields(delegate)
-		if len(parts) == 0 {
-			fmt.Fprintf(os.Stderr, "Error: invalid mail delegate: %q\n", delegate)
-			os.Exit(1)
+// delegateMail runs the configured mail delegate with the arguments given
+// after 'bd mail' and exits with its status. Returns false when no delegate
+// is configured.
+func delegateMail() bool {
+	delegate := findMailDelegate()
+	if delegate == "" {
+		return false
+	}
+
+	// Parse the delegate command (e.g., "gt mail" -> ["gt", "mail"])
+	parts := strings.Fields(delegate)
+	if len(parts) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid mail delegate: %q\n", delegate)
+		os.Exit(1)
+	}
+
+	// Build the full command with our args appended
+	cmdName := parts[0]
+	cmdArgs := append(parts[1:], rawMailArgs(os.Args)...)
+
+	// Execute the delegate command
+	// #nosec G204 - cmdName comes from user configuration (mail_delegate setting)
+	execCmd := exec.Command(cmdName, cmdArgs...)
+	execCmd.Stdin = os.Stdin
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+
+	if err := execCmd.Run(); err != nil {
+		// Try to preserve the exit code
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
 		}
+		fmt.Fprintf(os.Stderr, "Error running %s: %v\n", delegate, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+	return true
+}
 
-		// Build the full command with our args appended
-		cmdName := parts[0]
-		cmdArgs := append(parts[1:], args...)
-
-		// Execute the delegate command
-		// #nosec G204 - cmdName comes from user configuration (mail_delegate setting)
-		execCmd := exec.Command(cmdName, cmdArgs...)
-		execCmd.Stdin = os.Stdin
-		execCmd.Stdout = os.Stdout
-		execCmd.Stderr = os.Stderr
-
-		if err := execCmd.Run(); err != nil {
-			// Try to preserve the exit code
-			if exitErr, ok := err.(*exec.ExitError); ok {
-				os.Exit(exitErr.ExitCode())
+// rawMailArgs returns the command-line arguments after the mail subcommand,
+// unparsed, so the delegate sees exactly what the user typed. The subcommand
+// is the first positional argument after bd's global flags and their values,
+// so "bd --actor mail mail inbox" passes through "inbox". Returns nil when
+// argv is not a mail command.
+func rawMailArgs(argv []string) []string {
+	for i := 1; i < len(argv); i++ {
+		arg := argv[i]
+		switch {
+		case arg == "--":
+			// Everything after -- is positional to bd itself
+			return nil
+		case strings.HasPrefix(arg, "--"):
+			name := strings.TrimPrefix(arg, "--")
+			if !strings.Contains(name, "=") && rootFlagTakesValue(rootCmd.PersistentFlags().Lookup(name)) {
+				i++
 			}
-			fmt.Fprintf(os.Stderr, "Error running %s: %v\n", delegate, err)
-			os.Exit(1)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// A lone shorthand may take the next argument; -xVALUE does not
+			if len(arg) == 2 && rootFlagTakesValue(rootCmd.PersistentFlags().ShorthandLookup(arg[1:])) {
+				i++
+			}
+		case arg == "mail":
+			return argv[i+1:]
+		default:
+			return nil
 		}
-	},
+	}
+	return nil
+}
+
+// rootFlagTakesValue reports whether f consumes the following argument.
+func rootFlagTakesValue(f *pflag.Flag) bool {
+	return f != nil && f.NoOptDefVal == ""
 }
 
 // findMailDelegate checks for mail delegation configuration
@@ -102,11 +157,38 @@ func findMailDelegate() string {
 		if delegate, err := store.GetConfig(rootCtx, "mail.delegate"); err == nil && delegate != "" {
 			return delegate
 		}
+		return ""
 	}
 
+	// --help and daemon mode get here before or without a direct store
+	path := dbPath
+	if path == "" {
+		path = beads.FindDatabasePath()
+	}
+	if path == "" {
+		return ""
+	}
+	ctx := context.Background()
+	s, err := sqlite.NewReadOnly(ctx, path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = s.Close() }()
+	if delegate, err := s.GetConfig(ctx, "mail.delegate"); err == nil {
+		return delegate
+	}
 	return ""
 }
 
 func init() {
 	rootCmd.AddCommand(mailCmd)
+
+	// Help is shown before any Run, so 'bd mail <cmd> --help' has to check
+	// for a delegate here to reach it
+	mailCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if rawMailArgs(os.Args) != nil && delegateMail() {
+			return
+		}
+		colorizedHelpFunc(cmd, args)
+	})
 }
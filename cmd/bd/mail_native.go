@@ -0,0 +1,555 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/utils"
+)
+
+// Mail labels track per-recipient state, so a message sent to a role or rig
+// can be read and archived by each member separately.
+const (
+	mailReadLabelPrefix     = "read:"
+	mailArchivedLabelPrefix = "archived:"
+	mailRoleAddressPrefix   = "role:"
+	mailRigAddressPrefix    = "rig:"
+)
+
+// MailMessage is a message as shown by the native mailbox commands.
+type MailMessage struct {
+	ID        string    `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Read      bool      `json:"read"`
+	Archived  bool      `json:"archived,omitempty"`
+	RepliesTo string    `json:"replies_to,omitempty"`
+}
+
+var mailSendCmd = &cobra.Command{
+	Use:   "send <to>... -s <subject> -m <body>",
+	Short: "Send a message",
+	Long: `Send a message to one or more addresses. Each address gets its own
+message issue.
+
+Addresses are agent beads (partial IDs work), role:<role>, rig:<rig>, or
+any other identity string.`,
+	Args:               cobra.ArbitraryArgs,
+	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+	Run:                nativeMail(cobra.MinimumNArgs(1), runMailSend),
+}
+
+var mailInboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "List messages addressed to you",
+	Long: `List open messages addressed to you, your agent bead's role, or its rig.
+Unread messages come first and are marked with ●.`,
+	Args:               cobra.ArbitraryArgs,
+	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+	Run:                nativeMail(cobra.NoArgs, runMailInbox),
+}
+
+var mailReadCmd = &cobra.Command{
+	Use:                "read <id>",
+	Short:              "Show a message and mark it read",
+	Args:               cobra.ArbitraryArgs,
+	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+	Run:                nativeMail(cobra.ExactArgs(1), runMailRead),
+}
+
+var mailReplyCmd = &cobra.Command{
+	Use:   "reply <id> -m <body>",
+	Short: "Reply to a message's sender",
+	Long: `Send a reply to the sender of a message. The reply is linked to the
+original with a replies-to dependency, so 'bd show --thread' shows the
+whole conversation.`,
+	Args:               cobra.ArbitraryArgs,
+	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+	Run:                nativeMail(cobra.ExactArgs(1), runMailReply),
+}
+
+var mailArchiveCmd = &cobra.Command{
+	Use:   "archive <id>...",
+	Short: "Remove messages from your inbox",
+	Long: `Archive messages for you. A message sent directly to you is closed; one
+sent to a role or rig stays open for the other recipients.`,
+	Args:               cobra.ArbitraryArgs,
+	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+	Run:                nativeMail(cobra.MinimumNArgs(1), runMailArchive),
+}
+
+func init() {
+	mailCmd.PersistentFlags().String("identity", "", "Act as this identity (default: BEADS_IDENTITY, identity config, git user.name)")
+	mailSendCmd.Flags().StringP("subject", "s", "", "Message subject (required)")
+	mailSendCmd.Flags().StringP("message", "m", "", "Message body")
+	mailSendCmd.Flags().IntP("priority", "p", 2, "Priority (0-4, 0 = urgent)")
+	mailSendCmd.Flags().Bool("ephemeral", false, "Keep the message out of JSONL export (local to this database)")
+	mailInboxCmd.Flags().Bool("unread", false, "Only show unread messages")
+	mailInboxCmd.Flags().Bool("archived", false, "Show archived messages instead")
+	mailReplyCmd.Flags().StringP("message", "m", "", "Reply body (required)")
+	mailReplyCmd.Flags().StringP("subject", "s", "", "Subject (default: Re: <original subject>)")
+
+	mailCmd.AddCommand(mailSendCmd)
+	mailCmd.AddCommand(mailInboxCmd)
+	mailCmd.AddCommand(mailReadCmd)
+	mailCmd.AddCommand(mailReplyCmd)
+	mailCmd.AddCommand(mailArchiveCmd)
+}
+
+// mailIdentity returns the identity mail commands act as.
+func mailIdentity(cmd *cobra.Command) string {
+	flag, _ := cmd.Flags().GetString("identity")
+	return config.GetIdentity(flag)
+}
+
+// nativeMail wraps a native mail command's Run. The subcommands accept any
+// arguments at the cobra level so that delegate syntax the native commands
+// don't support (bd mail inbox <addr>, bd mail read with no ID) still reaches
+// the delegate; the native argument checks run only once delegation is ruled
+// out.
+func nativeMail(validate cobra.PositionalArgs, run func(*cobra.Command, []string)) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		ensureNativeMail(cmd)
+		if err := validate(cmd, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Usage: %s\n", cmd.UseLine())
+			os.Exit(1)
+		}
+		run(cmd, args)
+	}
+}
+
+// ensureNativeMail delegates when a mail provider is configured, and
+// otherwise prepares the store for the native mailbox.
+func ensureNativeMail(cmd *cobra.Command) {
+	if delegateMail() {
+		return
+	}
+	if cmd.Name() == "send" || cmd.Name() == "reply" || cmd.Name() == "archive" {
+		CheckReadonly("mail " + cmd.Name())
+	}
+	if err := ensureDirectMode("native mail runs against the database directly"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runMailSend(cmd *cobra.Command, args []string) {
+	ctx := rootCtx
+
+	subject, _ := cmd.Flags().GetString("subject")
+	body, _ := cmd.Flags().GetString("message")
+	priority, _ := cmd.Flags().GetInt("priority")
+	ephemeral, _ := cmd.Flags().GetBool("ephemeral")
+	if strings.TrimSpace(subject) == "" {
+		fmt.Fprintf(os.Stderr, "Error: --subject is required\n")
+		os.Exit(1)
+	}
+
+	from := mailIdentity(cmd)
+	var sent []*MailMessage
+	for _, to := range args {
+		address, err := resolveMailAddress(ctx, to)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		msg, err := sendMail(ctx, from, address, subject, body, priority, ephemeral, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending to %s: %v\n", address, err)
+			os.Exit(1)
+		}
+		sent = append(sent, msg)
+	}
+	markDirtyAndScheduleFlush()
+
+	if jsonOutput {
+		outputJSON(sent)
+		return
+	}
+	for _, msg := range sent {
+		fmt.Printf("%s Sent %s to %s: %s\n", ui.RenderPass("✓"), ui.RenderID(msg.ID), msg.To, msg.Subject)
+	}
+}
+
+// sendMail creates a message issue from one sender to one address, linked
+// to repliesTo when it is a reply.
+func sendMail(ctx context.Context, from, to, subject, body string, priority int, ephemeral bool, repliesTo string) (*MailMessage, error) {
+	issue := &types.Issue{
+		Title:       subject,
+		Description: body,
+		Status:      types.StatusOpen,
+		Priority:    priority,
+		IssueType:   types.TypeMessage,
+		Sender:      from,
+		Assignee:    to,
+		Ephemeral:   ephemeral,
+	}
+	if err := store.CreateIssue(ctx, issue, actor); err != nil {
+		return nil, err
+	}
+	if repliesTo != "" {
+		dep := &types.Dependency{IssueID: issue.ID, DependsOnID: repliesTo, Type: types.DepRepliesTo}
+		if err := store.AddDependency(ctx, dep, actor); err != nil {
+			return nil, fmt.Errorf("failed to link reply: %w", err)
+		}
+	}
+	return &MailMessage{
+		ID:        issue.ID,
+		From:      from,
+		To:        to,
+		Subject:   subject,
+		Body:      body,
+		CreatedAt: issue.CreatedAt,
+		RepliesTo: repliesTo,
+	}, nil
+}
+
+// resolveMailAddress normalizes a recipient: role: and rig: addresses are
+// kept, issue IDs are expanded to the full agent bead ID, and anything else
+// is taken as an identity.
+func resolveMailAddress(ctx context.Context, to string) (string, error) {
+	for _, prefix := range []string{mailRoleAddressPrefix, mailRigAddressPrefix} {
+		if value, ok := strings.CutPrefix(to, prefix); ok {
+			if value == "" {
+				return "", fmt.Errorf("empty %s address", strings.TrimSuffix(prefix, ":"))
+			}
+			return to, nil
+		}
+	}
+
+	id, err := utils.ResolvePartialID(ctx, store, to)
+	if err != nil || id == "" {
+		// Not an issue: a human or an agent without a bead
+		return to, nil
+	}
+	labels, err := store.GetLabels(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if !isAgentBead(labels) {
+		return "", fmt.Errorf("%s is an issue, not an agent bead", id)
+	}
+	return id, nil
+}
+
+// mailAddresses returns every address that reaches identity: itself, plus
+// the role and rig of its agent bead if it has one.
+func mailAddresses(ctx context.Context, identity string) []string {
+	addresses := []string{identity}
+	agentBead, err := store.GetIssue(ctx, identity)
+	if err != nil || agentBead == nil {
+		return addresses
+	}
+	if labels, err := store.GetLabels(ctx, agentBead.ID); err != nil || !isAgentBead(labels) {
+		return addresses
+	}
+	roleType, rig := agentBead.RoleType, agentBead.Rig
+	if roleType == "" && rig == "" {
+		roleType, rig = parseAgentIDFields(agentBead.ID)
+	}
+	if roleType != "" {
+		addresses = append(addresses, mailRoleAddressPrefix+roleType)
+	}
+	if rig != "" {
+		addresses = append(addresses, mailRigAddressPrefix+rig)
+	}
+	return addresses
+}
+
+// loadMailbox returns the messages for identity, unread first and newest
+// first within each group. Archived messages include closed direct ones.
+func loadMailbox(ctx context.Context, identity string, archived bool) ([]*MailMessage, error) {
+	addresses := make(map[string]bool)
+	for _, a := range mailAddresses(ctx, identity) {
+		addresses[a] = true
+	}
+
+	msgType := types.TypeMessage
+	filter := types.IssueFilter{IssueType: &msgType}
+	if !archived {
+		open := types.StatusOpen
+		filter.Status = &open
+	}
+	issues, err := store.SearchIssues(ctx, "", filter)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, issue := range issues {
+		if addresses[issue.Assignee] {
+			ids = append(ids, issue.ID)
+		}
+	}
+	labelsByID, err := store.GetLabelsForIssues(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []*MailMessage
+	for _, issue := range issues {
+		if !addresses[issue.Assignee] {
+			continue
+		}
+		msg := newMailMessage(issue, labelsByID[issue.ID], identity)
+		if msg.Archived != archived {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].Read != messages[j].Read {
+			return !messages[i].Read
+		}
+		return messages[i].CreatedAt.After(messages[j].CreatedAt)
+	})
+	return messages, nil
+}
+
+func newMailMessage(issue *types.Issue, labels []string, identity string) *MailMessage {
+	return &MailMessage{
+		ID:        issue.ID,
+		From:      issue.Sender,
+		To:        issue.Assignee,
+		Subject:   issue.Title,
+		Body:      issue.Description,
+		CreatedAt: issue.CreatedAt,
+		Read:      containsLabel(labels, mailReadLabelPrefix+identity),
+		Archived:  containsLabel(labels, mailArchivedLabelPrefix+identity) || issue.Status == types.StatusClosed,
+	}
+}
+
+func runMailInbox(cmd *cobra.Command, args []string) {
+	ctx := rootCtx
+
+	unreadOnly, _ := cmd.Flags().GetBool("unread")
+	archived, _ := cmd.Flags().GetBool("archived")
+	identity := mailIdentity(cmd)
+
+	messages, err := loadMailbox(ctx, identity, archived)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if unreadOnly {
+		var unread []*MailMessage
+		for _, msg := range messages {
+			if !msg.Read {
+				unread = append(unread, msg)
+			}
+		}
+		messages = unread
+	}
+
+	if jsonOutput {
+		for _, msg := range messages {
+			msg.Body = ""
+		}
+		if messages == nil {
+			messages = []*MailMessage{}
+		}
+		outputJSON(messages)
+		return
+	}
+
+	unreadCount := 0
+	for _, msg := range messages {
+		if !msg.Read {
+			unreadCount++
+		}
+	}
+	fmt.Printf("\n%s Inbox for %s: %d message(s), %d unread\n\n", ui.RenderAccent("📬"), identity, len(messages), unreadCount)
+	for _, msg := range messages {
+		marker := " "
+		if !msg.Read {
+			marker = ui.RenderAccent("●")
+		}
+		to := ""
+		if msg.To != identity {
+			to = ui.RenderMuted(" (to " + msg.To + ")")
+		}
+		fmt.Printf("  %s %s  %s  %-16s %s%s\n", marker, ui.RenderID(msg.ID), msg.CreatedAt.Local().Format("2006-01-02 15:04"), msg.From, msg.Subject, to)
+	}
+	if len(messages) > 0 {
+		fmt.Println()
+	}
+}
+
+// loadMessage resolves id to a message issue.
+func loadMessage(ctx context.Context, id string) (*types.Issue, error) {
+	fullID, err := utils.ResolvePartialID(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+	issue, err := store.GetIssue(ctx, fullID)
+	if err != nil {
+		return nil, err
+	}
+	if issue == nil {
+		return nil, fmt.Errorf("message %s not found", id)
+	}
+	if issue.IssueType != types.TypeMessage {
+		return nil, fmt.Errorf("%s is not a message (type %s)", issue.ID, issue.IssueType)
+	}
+	return issue, nil
+}
+
+// markMailRead records that identity has read the message.
+func markMailRead(ctx context.Context, issueID, identity string) error {
+	labels, err := store.GetLabels(ctx, issueID)
+	if err != nil {
+		return err
+	}
+	if containsLabel(labels, mailReadLabelPrefix+identity) {
+		return nil
+	}
+	return store.AddLabel(ctx, issueID, mailReadLabelPrefix+identity, actor)
+}
+
+func runMailRead(cmd *cobra.Command, args []string) {
+	ctx := rootCtx
+	identity := mailIdentity(cmd)
+
+	issue, err := loadMessage(ctx, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !readonlyMode {
+		if err := markMailRead(ctx, issue.ID, identity); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to mark %s read: %v\n", issue.ID, err)
+		} else {
+			markDirtyAndScheduleFlush()
+		}
+	}
+
+	labels, _ := store.GetLabels(ctx, issue.ID)
+	msg := newMailMessage(issue, labels, identity)
+	msg.RepliesTo = findRepliesTo(ctx, issue.ID, nil, store)
+
+	if jsonOutput {
+		outputJSON(msg)
+		return
+	}
+	fmt.Printf("\n%s %s\n", ui.RenderID(msg.ID), ui.RenderBold(msg.Subject))
+	fmt.Printf("From: %s\n", msg.From)
+	fmt.Printf("To:   %s\n", msg.To)
+	fmt.Printf("Date: %s\n", msg.CreatedAt.Local().Format("2006-01-02 15:04"))
+	if msg.RepliesTo != "" {
+		fmt.Printf("In reply to: %s\n", msg.RepliesTo)
+	}
+	if msg.Body != "" {
+		fmt.Printf("\n%s\n", msg.Body)
+	}
+	fmt.Println()
+}
+
+func runMailReply(cmd *cobra.Command, args []string) {
+	ctx := rootCtx
+
+	body, _ := cmd.Flags().GetString("message")
+	subject, _ := cmd.Flags().GetString("subject")
+	if strings.TrimSpace(body) == "" {
+		fmt.Fprintf(os.Stderr, "Error: --message is required\n")
+		os.Exit(1)
+	}
+
+	original, err := loadMessage(ctx, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if original.Sender == "" {
+		fmt.Fprintf(os.Stderr, "Error: %s has no sender to reply to\n", original.ID)
+		os.Exit(1)
+	}
+	if subject == "" {
+		subject = replySubject(original.Title)
+	}
+
+	identity := mailIdentity(cmd)
+	msg, err := sendMail(ctx, identity, original.Sender, subject, body, original.Priority, original.Ephemeral, original.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := markMailRead(ctx, original.ID, identity); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to mark %s read: %v\n", original.ID, err)
+	}
+	markDirtyAndScheduleFlush()
+
+	if jsonOutput {
+		outputJSON(msg)
+		return
+	}
+	fmt.Printf("%s Replied %s to %s: %s\n", ui.RenderPass("✓"), ui.RenderID(msg.ID), msg.To, msg.Subject)
+}
+
+// replySubject prefixes "Re: " unless the subject already has it.
+func replySubject(subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), "re:") {
+		return subject
+	}
+	return "Re: " + subject
+}
+
+func runMailArchive(cmd *cobra.Command, args []string) {
+	ctx := rootCtx
+	identity := mailIdentity(cmd)
+
+	var archived []string
+	for _, arg := range args {
+		issue, err := loadMessage(ctx, arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := archiveMail(ctx, issue, identity); err != nil {
+			fmt.Fprintf(os.Stderr, "Error archiving %s: %v\n", issue.ID, err)
+			os.Exit(1)
+		}
+		archived = append(archived, issue.ID)
+	}
+	markDirtyAndScheduleFlush()
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{"archived": archived})
+		return
+	}
+	for _, id := range archived {
+		fmt.Printf("%s Archived %s\n", ui.RenderPass("✓"), ui.RenderID(id))
+	}
+}
+
+// archiveMail closes a message addressed directly to identity, or labels a
+// role or rig message as archived for identity only.
+func archiveMail(ctx context.Context, issue *types.Issue, identity string) error {
+	if err := markMailRead(ctx, issue.ID, identity); err != nil {
+		return err
+	}
+	if issue.Assignee == identity {
+		if issue.Status == types.StatusClosed {
+			return nil
+		}
+		return store.CloseIssue(ctx, issue.ID, "archived", actor, "")
+	}
+	labels, err := store.GetLabels(ctx, issue.ID)
+	if err != nil {
+		return err
+	}
+	if containsLabel(labels, mailArchivedLabelPrefix+identity) {
+		return nil
+	}
+	return store.AddLabel(ctx, issue.ID, mailArchivedLabelPrefix+identity, actor)
+}
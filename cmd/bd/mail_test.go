@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestRawMailArgs(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want []string
+	}{
+		{[]string{"bd", "mail", "send", "mayor", "-s", "hi"}, []string{"send", "mayor", "-s", "hi"}},
+		{[]string{"bd", "--json", "mail", "inbox"}, []string{"inbox"}},
+		{[]string{"bd", "mail"}, []string{}},
+		{[]string{"mail", "inbox"}, nil},
+		{[]string{"bd", "mail", "send", "mail", "-m", "mail"}, []string{"send", "mail", "-m", "mail"}},
+		{[]string{"bd", "--actor", "mail", "mail", "inbox"}, []string{"inbox"}},
+		{[]string{"bd", "--actor=mail", "mail", "inbox"}, []string{"inbox"}},
+		{[]string{"bd", "--no-daemon", "mail", "read"}, []string{"read"}},
+		{[]string{"bd", "show", "mail"}, nil},
+		{[]string{"bd", "--", "mail", "inbox"}, nil},
+	}
+	for _, tt := range tests {
+		got := rawMailArgs(tt.argv)
+		if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("rawMailArgs(%v) = %v, want %v", tt.argv, got, tt.want)
+		}
+	}
+}
+
+func TestMailSubcommandsAcceptDelegateArgs(t *testing.T) {
+	// Native argument checks run after delegation, so cobra itself must
+	// accept whatever a delegate might
+	for _, cmd := range mailCmd.Commands() {
+		for _, args := range [][]string{nil, {"gt-emma"}, {"a", "b", "c"}} {
+			if err := cmd.ValidateArgs(args); err != nil {
+				t.Errorf("bd mail %s %v rejected before delegation: %v", cmd.Name(), args, err)
+			}
+		}
+	}
+}
+
+func TestReplySubject(t *testing.T) {
+	tests := map[string]string{
+		"Status":     "Re: Status",
+		"Re: Status": "Re: Status",
+		"RE: Status": "RE: Status",
+	}
+	for in, want := range tests {
+		if got := replySubject(in); got != want {
+			t.Errorf("replySubject(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNativeMailbox(t *testing.T) {
+	testStore := newTestStore(t, filepath.Join(t.TempDir(), ".beads", "beads.db"))
+	oldStore, oldActor := store, actor
+	store, actor = testStore, "tester"
+	defer func() { store, actor = oldStore, oldActor }()
+
+	ctx := context.Background()
+	agentBead := &types.Issue{
+		Title:     "Witness",
+		Status:    types.StatusOpen,
+		Priority:  2,
+		IssueType: types.TypeTask,
+	}
+	if err := testStore.CreateIssue(ctx, agentBead, "tester"); err != nil {
+		t.Fatalf("CreateIssue failed: %v", err)
+	}
+	if err := testStore.UpdateIssue(ctx, agentBead.ID, map[string]interface{}{"role_type": "witness", "rig": "gastown"}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := testStore.AddLabel(ctx, agentBead.ID, "gt:agent", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	me := agentBead.ID
+
+	addr, err := resolveMailAddress(ctx, me)
+	if err != nil || addr != me {
+		t.Fatalf("resolveMailAddress(%q) = %q, %v", me, addr, err)
+	}
+	if addr, _ := resolveMailAddress(ctx, "role:witness"); addr != "role:witness" {
+		t.Errorf("role address rewritten to %q", addr)
+	}
+	if _, err := resolveMailAddress(ctx, "rig:"); err == nil {
+		t.Error("expected error for empty rig address")
+	}
+	if got := mailAddresses(ctx, me); !reflect.DeepEqual(got, []string{me, "role:witness", "rig:gastown"}) {
+		t.Errorf("mailAddresses() = %v", got)
+	}
+
+	direct, err := sendMail(ctx, "mayor", me, "Direct", "body", 2, false, "")
+	if err != nil {
+		t.Fatalf("sendMail failed: %v", err)
+	}
+	group, err := sendMail(ctx, "mayor", "rig:gastown", "Group", "", 2, false, "")
+	if err != nil {
+		t.Fatalf("sendMail failed: %v", err)
+	}
+	if _, err := sendMail(ctx, "mayor", "rig:other", "Elsewhere", "", 2, false, ""); err != nil {
+		t.Fatalf("sendMail failed: %v", err)
+	}
+
+	inbox, err := loadMailbox(ctx, me, false)
+	if err != nil {
+		t.Fatalf("loadMailbox failed: %v", err)
+	}
+	if len(inbox) != 2 || inbox[0].Read || inbox[1].Read {
+		t.Fatalf("inbox = %+v, want 2 unread messages", inbox)
+	}
+
+	if err := markMailRead(ctx, direct.ID, me); err != nil {
+		t.Fatalf("markMailRead failed: %v", err)
+	}
+	inbox, _ = loadMailbox(ctx, me, false)
+	if inbox[0].ID != group.ID || inbox[1].ID != direct.ID || !inbox[1].Read {
+		t.Errorf("expected unread group message first, got %+v", inbox)
+	}
+	// Read state is per recipient
+	other, _ := loadMailbox(ctx, "role:witness", false)
+	for _, msg := range other {
+		if msg.Read {
+			t.Errorf("%s is read for another recipient", msg.ID)
+		}
+	}
+
+	for _, id := range []string{direct.ID, group.ID} {
+		issue, _ := testStore.GetIssue(ctx, id)
+		if err := archiveMail(ctx, issue, me); err != nil {
+			t.Fatalf("archiveMail(%s) failed: %v", id, err)
+		}
+	}
+	if inbox, _ := loadMailbox(ctx, me, false); len(inbox) != 0 {
+		t.Errorf("inbox after archive = %+v, want empty", inbox)
+	}
+	if archived, _ := loadMailbox(ctx, me, true); len(archived) != 2 {
+		t.Errorf("archived = %+v, want 2 messages", archived)
+	}
+	if issue, _ := testStore.GetIssue(ctx, direct.ID); issue.Status != types.StatusClosed {
+		t.Errorf("direct message status = %s, want closed", issue.Status)
+	}
+	if issue, _ := testStore.GetIssue(ctx, group.ID); issue.Status != types.StatusOpen {
+		t.Errorf("group message status = %s, want open for other recipients", issue.Status)
+	}
+
+	reply, err := sendMail(ctx, me, "mayor", replySubject("Direct"), "ack", 2, false, direct.ID)
+	if err != nil {
+		t.Fatalf("reply failed: %v", err)
+	}
+	if got := findRepliesTo(ctx, reply.ID, nil, testStore); got != direct.ID {
+		t.Errorf("reply replies-to = %q, want %s", got, direct.ID)
+	}
+}
@@ -73,6 +73,8 @@ var (
 	sandboxMode    bool
 	allowStale     bool          // Use --allow-stale: skip staleness check (emergency escape hatch)
 	noDb           bool          // Use --no-db mode: load from JSONL, write back after each command
+	tursoMode      bool          // Use --turso mode: task/dep files + Turso cache as the storage backend
+	gitRefsMode    bool          // Use --git-refs mode: one git ref per issue as the storage backend
 	readonlyMode   bool          // Read-only mode: block write operations (for worker sandboxes)
 	lockTimeout    time.Duration // SQLite busy_timeout (default 30s, 0 = fail immediately)
 	profileEnabled bool
@@ -128,6 +130,8 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&sandboxMode, "sandbox", false, "Sandbox mode: disables daemon and auto-sync")
 	rootCmd.PersistentFlags().BoolVar(&allowStale, "allow-stale", false, "Allow operations on potentially stale data (skip staleness check)")
 	rootCmd.PersistentFlags().BoolVar(&noDb, "no-db", false, "Use no-db mode: load from JSONL, no SQLite")
+	rootCmd.PersistentFlags().BoolVar(&tursoMode, "turso", false, "Use jj-turso mode: tasks/*.json + deps/*.json with the Turso cache")
+	rootCmd.PersistentFlags().BoolVar(&gitRefsMode, "git-refs", false, "Use git refs mode: issues stored under refs/beads/issues/, no JSONL or SQLite")
 	rootCmd.PersistentFlags().BoolVar(&readonlyMode, "readonly", false, "Read-only mode: block write operations (for worker sandboxes)")
 	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 30*time.Second, "SQLite busy timeout (0 = fail immediately if locked)")
 	rootCmd.PersistentFlags().BoolVar(&profileEnabled, "profile", false, "Generate CPU profile for performance analysis")
@@ -174,6 +178,12 @@ var rootCmd = &cobra.Command{
 		// Set up signal-aware context for graceful cancellation
 		rootCtx, rootCancel = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
+		// Tag everything this invocation writes, directly or through the
+		// daemon, so bd undo can tell one command's changes from the next
+		commandID := newCommandID()
+		rootCtx = storage.WithCommandID(rootCtx, commandID)
+		rpc.ClientCommandID = commandID
+
 		// Signal orchestrator daemon about bd activity (best-effort, for exponential backoff)
 		defer signalOrchestratorActivity()
 
@@ -232,6 +242,22 @@ var rootCmd = &cobra.Command{
 				WasSet bool
 			}{noDb, true}
 		}
+		if !cmd.Flags().Changed("turso") {
+			tursoMode = config.GetBool("turso")
+		} else {
+			flagOverrides["turso"] = struct {
+				Value  interface{}
+				WasSet bool
+			}{tursoMode, true}
+		}
+		if !cmd.Flags().Changed("git-refs") {
+			gitRefsMode = config.GetBool("git-refs")
+		} else {
+			flagOverrides["git-refs"] = struct {
+				Value  interface{}
+				WasSet bool
+			}{gitRefsMode, true}
+		}
 		if !cmd.Flags().Changed("readonly") {
 			readonlyMode = config.GetBool("readonly")
 		} else {
@@ -309,6 +335,7 @@ var rootCmd = &cobra.Command{
 			"powershell",
 			"prime",
 			"quickstart",
+			"refs",
 			"repair",
 			"setup",
 			"version",
@@ -390,6 +417,50 @@ var rootCmd = &cobra.Command{
 			return
 		}
 
+		// Handle --turso mode: task/dep files are the database, Turso is the cache
+		if tursoMode {
+			if err := initializeTursoMode(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing --turso mode: %v\n", err)
+				os.Exit(1)
+			}
+
+			// Set actor for audit trail
+			if actor == "" {
+				if bdActor := os.Getenv("BD_ACTOR"); bdActor != "" {
+					actor = bdActor
+				} else if user := os.Getenv("USER"); user != "" {
+					actor = user
+				} else {
+					actor = "unknown"
+				}
+			}
+
+			// Skip daemon and SQLite initialization - writes go straight to task files
+			return
+		}
+
+		// Handle --git-refs mode: refs/beads/issues/* are the database
+		if gitRefsMode {
+			if err := initializeGitRefsMode(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing --git-refs mode: %v\n", err)
+				os.Exit(1)
+			}
+
+			// Set actor for audit trail
+			if actor == "" {
+				if bdActor := os.Getenv("BD_ACTOR"); bdActor != "" {
+					actor = bdActor
+				} else if user := os.Getenv("USER"); user != "" {
+					actor = user
+				} else {
+					actor = "unknown"
+				}
+			}
+
+			// Skip daemon and SQLite initialization - changes are flushed to refs on exit
+			return
+		}
+
 		// Initialize database path
 		if dbPath == "" {
 			// Use public API to find database (same logic as extensions)
@@ -818,6 +889,25 @@ var rootCmd = &cobra.Command{
 			return
 		}
 
+		// Handle --turso mode: every write was already written through
+		if tursoMode {
+			if store != nil {
+				_ = store.Close()
+			}
+			return
+		}
+
+		// Handle --git-refs mode: commit changes to refs
+		if gitRefsMode {
+			if store != nil {
+				if err := store.Close(); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: failed to write issue refs: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			return
+		}
+
 		// Close daemon client if we're using it
 		if daemonClient != nil {
 			_ = daemonClient.Close()
@@ -0,0 +1,629 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// The markdown-dir format stores one issue per file:
+//
+//	---
+//	id: bd-a1b2
+//	status: open
+//	priority: 1
+//	type: feature
+//	labels: [ui]
+//	deps:
+//	  - blocks: bd-c3d4
+//	  - parent-child: bd-e5f6
+//	updated_at: 2025-03-01T12:00:00Z
+//	---
+//
+//	# Title
+//
+//	## Description
+//	...
+//
+// The file is authoritative for every field it carries: a removed label,
+// dependency or section is removed from the issue on import. updated_at is
+// the version the file was exported from and is used to detect conflicts.
+
+// markdownSections are the H2 sections of an issue file, in file order.
+// Only these headings split sections, so other H2s stay part of the text.
+var markdownSections = []string{"Description", "Design", "Acceptance Criteria", "Notes"}
+
+// markdownFrontMatter is the YAML header of an issue file.
+type markdownFrontMatter struct {
+	ID          string        `yaml:"id,omitempty"`
+	Status      string        `yaml:"status,omitempty"`
+	Priority    *int          `yaml:"priority,omitempty"`
+	Type        string        `yaml:"type,omitempty"`
+	Assignee    string        `yaml:"assignee,omitempty"`
+	Labels      []string      `yaml:"labels,omitempty,flow"`
+	Deps        []markdownDep `yaml:"deps,omitempty"`
+	ExternalRef string        `yaml:"external_ref,omitempty"`
+	UpdatedAt   string        `yaml:"updated_at,omitempty"`
+}
+
+// markdownDep is a dependency written as "type: id". A bare "id" means
+// blocks.
+type markdownDep struct {
+	Type types.DependencyType
+	ID   string
+}
+
+func (d markdownDep) MarshalYAML() (interface{}, error) {
+	return map[string]string{string(d.Type): d.ID}, nil
+}
+
+func (d *markdownDep) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		d.Type, d.ID = types.DepBlocks, strings.TrimSpace(node.Value)
+		return nil
+	case yaml.MappingNode:
+		if len(node.Content) != 2 {
+			return fmt.Errorf("line %d: dependency must be a single \"type: id\" pair", node.Line)
+		}
+		d.Type = types.DependencyType(strings.TrimSpace(node.Content[0].Value))
+		d.ID = strings.TrimSpace(node.Content[1].Value)
+		return nil
+	default:
+		return fmt.Errorf("line %d: dependency must be \"type: id\" or an issue ID", node.Line)
+	}
+}
+
+// markdownIssue is a parsed issue file.
+type markdownIssue struct {
+	markdownFrontMatter
+	Title              string
+	Description        string
+	Design             string
+	AcceptanceCriteria string
+	Notes              string
+}
+
+func (m *markdownIssue) section(name string) *string {
+	switch name {
+	case "Description":
+		return &m.Description
+	case "Design":
+		return &m.Design
+	case "Acceptance Criteria":
+		return &m.AcceptanceCriteria
+	case "Notes":
+		return &m.Notes
+	}
+	return nil
+}
+
+// markdownFileName is the file an issue is written to.
+func markdownFileName(id string) string {
+	return strings.TrimSuffix(sitePageName(id), ".html") + ".md"
+}
+
+// renderMarkdownIssue renders an issue, with its labels and dependency
+// records populated, as a markdown-dir file.
+func renderMarkdownIssue(issue *types.Issue) ([]byte, error) {
+	priority := issue.Priority
+	fm := markdownFrontMatter{
+		ID:        issue.ID,
+		Status:    string(issue.Status),
+		Priority:  &priority,
+		Type:      string(issue.IssueType),
+		Assignee:  issue.Assignee,
+		Labels:    append([]string(nil), issue.Labels...),
+		UpdatedAt: issue.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	sort.Strings(fm.Labels)
+	if issue.ExternalRef != nil {
+		fm.ExternalRef = *issue.ExternalRef
+	}
+	for _, dep := range issue.Dependencies {
+		fm.Deps = append(fm.Deps, markdownDep{Type: dep.Type, ID: dep.DependsOnID})
+	}
+	sort.Slice(fm.Deps, func(i, j int) bool {
+		if fm.Deps[i].Type != fm.Deps[j].Type {
+			return fm.Deps[i].Type < fm.Deps[j].Type
+		}
+		return fm.Deps[i].ID < fm.Deps[j].ID
+	})
+
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(fm); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s\n", issue.Title)
+	m := &markdownIssue{
+		Description:        issue.Description,
+		Design:             issue.Design,
+		AcceptanceCriteria: issue.AcceptanceCriteria,
+		Notes:              issue.Notes,
+	}
+	for _, name := range markdownSections {
+		if text := strings.TrimSpace(*m.section(name)); text != "" {
+			fmt.Fprintf(&b, "\n## %s\n\n%s\n", name, text)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// parseMarkdownIssue parses a markdown-dir file.
+func parseMarkdownIssue(data []byte) (*markdownIssue, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, fmt.Errorf("missing front matter (file must start with ---)")
+	}
+	rest := text[len("---\n"):]
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		if strings.HasSuffix(rest, "\n---") {
+			end = len(rest) - len("\n---")
+		} else {
+			return nil, fmt.Errorf("unterminated front matter")
+		}
+	}
+	m := &markdownIssue{}
+	if err := yaml.Unmarshal([]byte(rest[:end]), &m.markdownFrontMatter); err != nil {
+		return nil, fmt.Errorf("invalid front matter: %w", err)
+	}
+	body := ""
+	if end+len("\n---\n") <= len(rest) {
+		body = rest[end+len("\n---\n"):]
+	}
+
+	var current *string
+	var buf []string
+	flush := func() {
+		if current != nil {
+			*current = strings.TrimSpace(strings.Join(buf, "\n"))
+		}
+		buf = nil
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if m.Title == "" && strings.HasPrefix(line, "# ") {
+			m.Title = strings.TrimSpace(line[2:])
+			continue
+		}
+		if strings.HasPrefix(line, "## ") {
+			heading := strings.TrimSpace(line[3:])
+			if target := m.sectionByHeading(heading); target != nil {
+				flush()
+				current = target
+				continue
+			}
+		}
+		if current == nil && strings.TrimSpace(line) != "" {
+			// Text between the title and the first section is the description
+			current = &m.Description
+		}
+		buf = append(buf, line)
+	}
+	flush()
+
+	if m.Title == "" {
+		return nil, fmt.Errorf("missing title (expected a '# Title' line)")
+	}
+	return m, nil
+}
+
+func (m *markdownIssue) sectionByHeading(heading string) *string {
+	for _, name := range markdownSections {
+		if strings.EqualFold(heading, name) {
+			return m.section(name)
+		}
+	}
+	if strings.EqualFold(heading, "Acceptance") {
+		return &m.AcceptanceCriteria
+	}
+	return nil
+}
+
+// writeMarkdownDir writes one file per issue into dir. Issues must have
+// labels and dependency records populated. Files of issues that are not in
+// the export are left alone.
+func writeMarkdownDir(dir string, issues []*types.Issue) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	written := 0
+	for _, issue := range issues {
+		if issue.Status == types.StatusTombstone {
+			continue
+		}
+		data, err := renderMarkdownIssue(issue)
+		if err != nil {
+			return written, fmt.Errorf("failed to render %s: %w", issue.ID, err)
+		}
+		// #nosec G306 -- issue files are meant to be committed and edited
+		if err := os.WriteFile(filepath.Join(dir, markdownFileName(issue.ID)), data, 0644); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// markdownImportOptions controls importMarkdownDir.
+type markdownImportOptions struct {
+	DryRun bool
+	// Overwrite applies edits to issues that changed since the file was
+	// exported instead of reporting a conflict.
+	Overwrite bool
+	Actor     string
+}
+
+// markdownImportResult reports what importMarkdownDir did, by issue ID
+// (or file name for files that failed to parse).
+type markdownImportResult struct {
+	Created   []string          `json:"created"`
+	Updated   []string          `json:"updated"`
+	Unchanged []string          `json:"unchanged"`
+	Conflicts []string          `json:"conflicts"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+func (r *markdownImportResult) fail(name string, err error) {
+	if r.Errors == nil {
+		r.Errors = make(map[string]string)
+	}
+	r.Errors[name] = err.Error()
+}
+
+// importMarkdownDir applies every *.md file in dir to the store. Files
+// without an ID, or with an ID the store does not know, create issues;
+// other files update their issue. An issue that changed after its file was
+// exported (updated_at) is a conflict and is skipped unless Overwrite is
+// set. Applied files are rewritten from the store so they carry the new
+// updated_at and can be edited again.
+func importMarkdownDir(ctx context.Context, s storage.Storage, dir string, opts markdownImportOptions) (*markdownImportResult, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	result := &markdownImportResult{Created: []string{}, Updated: []string{}, Unchanged: []string{}, Conflicts: []string{}}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// #nosec G304 -- reading the directory the user asked to import
+		data, err := os.ReadFile(path)
+		if err != nil {
+			result.fail(entry.Name(), err)
+			continue
+		}
+		m, err := parseMarkdownIssue(data)
+		if err != nil {
+			result.fail(entry.Name(), err)
+			continue
+		}
+		if err := applyMarkdownIssue(ctx, s, path, m, opts, result); err != nil {
+			name := m.ID
+			if name == "" {
+				name = entry.Name()
+			}
+			result.fail(name, err)
+		}
+	}
+	return result, nil
+}
+
+func applyMarkdownIssue(ctx context.Context, s storage.Storage, path string, m *markdownIssue, opts markdownImportOptions, result *markdownImportResult) error {
+	var existing *types.Issue
+	if m.ID != "" {
+		var err error
+		if existing, err = s.GetIssue(ctx, m.ID); err != nil {
+			return err
+		}
+	}
+	if existing != nil && existing.Status == types.StatusTombstone {
+		return fmt.Errorf("issue was deleted")
+	}
+
+	if existing == nil {
+		issue, err := m.toIssue()
+		if err != nil {
+			return err
+		}
+		if opts.DryRun {
+			result.Created = append(result.Created, displayMarkdownID(m, path))
+			return nil
+		}
+		if err := s.CreateIssue(ctx, issue, opts.Actor); err != nil {
+			return err
+		}
+		if err := applyMarkdownRelations(ctx, s, issue.ID, nil, nil, m, opts.Actor); err != nil {
+			return err
+		}
+		result.Created = append(result.Created, issue.ID)
+		return rewriteMarkdownIssue(ctx, s, path, issue.ID)
+	}
+
+	labels, err := s.GetLabels(ctx, existing.ID)
+	if err != nil {
+		return err
+	}
+	deps, err := s.GetDependencyRecords(ctx, existing.ID)
+	if err != nil {
+		return err
+	}
+	updates, err := m.fieldUpdates(existing)
+	if err != nil {
+		return err
+	}
+	addLabels, removeLabels := diffStrings(labels, m.Labels)
+	addDeps, removeDeps := diffMarkdownDeps(deps, m.Deps)
+	if len(updates) == 0 && len(addLabels)+len(removeLabels)+len(addDeps)+len(removeDeps) == 0 {
+		result.Unchanged = append(result.Unchanged, existing.ID)
+		return nil
+	}
+
+	if !opts.Overwrite && m.UpdatedAt != "" {
+		base, err := time.Parse(time.RFC3339Nano, m.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("invalid updated_at %q: %w", m.UpdatedAt, err)
+		}
+		if existing.UpdatedAt.After(base) {
+			result.Conflicts = append(result.Conflicts, existing.ID)
+			return nil
+		}
+	}
+	if opts.DryRun {
+		result.Updated = append(result.Updated, existing.ID)
+		return nil
+	}
+
+	if len(updates) > 0 {
+		if err := s.UpdateIssue(ctx, existing.ID, updates, opts.Actor); err != nil {
+			return err
+		}
+	}
+	if err := applyMarkdownRelations(ctx, s, existing.ID, removeLabels, removeDeps, &markdownIssue{markdownFrontMatter: markdownFrontMatter{Labels: addLabels, Deps: addDeps}}, opts.Actor); err != nil {
+		return err
+	}
+	result.Updated = append(result.Updated, existing.ID)
+	return rewriteMarkdownIssue(ctx, s, path, existing.ID)
+}
+
+func displayMarkdownID(m *markdownIssue, path string) string {
+	if m.ID != "" {
+		return m.ID
+	}
+	return filepath.Base(path)
+}
+
+// toIssue builds a new issue from a file.
+func (m *markdownIssue) toIssue() (*types.Issue, error) {
+	issue := &types.Issue{
+		ID:                 m.ID,
+		Title:              m.Title,
+		Description:        m.Description,
+		Design:             m.Design,
+		AcceptanceCriteria: m.AcceptanceCriteria,
+		Notes:              m.Notes,
+		Status:             types.Status(m.Status),
+		IssueType:          types.IssueType(m.Type),
+		Assignee:           m.Assignee,
+		Priority:           2,
+	}
+	if m.Priority != nil {
+		issue.Priority = *m.Priority
+	}
+	if m.ExternalRef != "" {
+		ref := m.ExternalRef
+		issue.ExternalRef = &ref
+	}
+	issue.SetDefaults()
+	if issue.Status == types.StatusClosed {
+		now := time.Now()
+		issue.ClosedAt = &now
+	}
+	return issue, nil
+}
+
+// fieldUpdates returns the UpdateIssue map that makes existing match the
+// file.
+func (m *markdownIssue) fieldUpdates(existing *types.Issue) (map[string]interface{}, error) {
+	updates := make(map[string]interface{})
+	setString := func(field, current, want string) {
+		if strings.TrimSpace(current) != want {
+			updates[field] = want
+		}
+	}
+	setString("title", existing.Title, m.Title)
+	setString("description", existing.Description, m.Description)
+	setString("design", existing.Design, m.Design)
+	setString("acceptance_criteria", existing.AcceptanceCriteria, m.AcceptanceCriteria)
+	setString("notes", existing.Notes, m.Notes)
+	setString("assignee", existing.Assignee, m.Assignee)
+
+	if m.Status != "" && types.Status(m.Status) != existing.Status {
+		updates["status"] = m.Status
+	}
+	if m.Type != "" && types.IssueType(m.Type) != existing.IssueType {
+		updates["issue_type"] = m.Type
+	}
+	if m.Priority != nil && *m.Priority != existing.Priority {
+		if *m.Priority < 0 || *m.Priority > 4 {
+			return nil, fmt.Errorf("priority must be between 0 and 4 (got %d)", *m.Priority)
+		}
+		updates["priority"] = *m.Priority
+	}
+	currentRef := ""
+	if existing.ExternalRef != nil {
+		currentRef = *existing.ExternalRef
+	}
+	if currentRef != m.ExternalRef {
+		if m.ExternalRef == "" {
+			updates["external_ref"] = nil
+		} else {
+			updates["external_ref"] = m.ExternalRef
+		}
+	}
+	return updates, nil
+}
+
+// applyMarkdownRelations removes and then adds labels and dependencies.
+func applyMarkdownRelations(ctx context.Context, s storage.Storage, id string, removeLabels []string, removeDeps []markdownDep, add *markdownIssue, actor string) error {
+	for _, label := range removeLabels {
+		if err := s.RemoveLabel(ctx, id, label, actor); err != nil {
+			return err
+		}
+	}
+	for _, dep := range removeDeps {
+		if err := s.RemoveDependency(ctx, id, dep.ID, actor); err != nil {
+			return err
+		}
+	}
+	for _, label := range add.Labels {
+		if err := s.AddLabel(ctx, id, label, actor); err != nil {
+			return err
+		}
+	}
+	for _, dep := range add.Deps {
+		if !dep.Type.IsValid() {
+			return fmt.Errorf("invalid dependency type %q", dep.Type)
+		}
+		if err := s.AddDependency(ctx, &types.Dependency{IssueID: id, DependsOnID: dep.ID, Type: dep.Type}, actor); err != nil {
+			return fmt.Errorf("failed to add %s dependency on %s: %w", dep.Type, dep.ID, err)
+		}
+	}
+	return nil
+}
+
+// rewriteMarkdownIssue re-renders an applied file from the store, renaming
+// it to the issue's file name if needed.
+func rewriteMarkdownIssue(ctx context.Context, s storage.Storage, path, id string) error {
+	issue, err := s.GetIssue(ctx, id)
+	if err != nil || issue == nil {
+		return fmt.Errorf("failed to reload %s: %v", id, err)
+	}
+	if issue.Labels, err = s.GetLabels(ctx, id); err != nil {
+		return err
+	}
+	if issue.Dependencies, err = s.GetDependencyRecords(ctx, id); err != nil {
+		return err
+	}
+	data, err := renderMarkdownIssue(issue)
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(filepath.Dir(path), markdownFileName(id))
+	// #nosec G306 -- issue files are meant to be committed and edited
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return err
+	}
+	if target != path {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+// diffStrings returns the items to add to and remove from current to get want.
+func diffStrings(current, want []string) (add, remove []string) {
+	have := make(map[string]bool, len(current))
+	for _, c := range current {
+		have[c] = true
+	}
+	wanted := make(map[string]bool, len(want))
+	for _, w := range want {
+		w = strings.TrimSpace(w)
+		if w == "" || wanted[w] {
+			continue
+		}
+		wanted[w] = true
+		if !have[w] {
+			add = append(add, w)
+		}
+	}
+	for _, c := range current {
+		if !wanted[c] {
+			remove = append(remove, c)
+		}
+	}
+	return add, remove
+}
+
+// diffMarkdownDeps compares dependency records with the file's deps. A type
+// change is a remove followed by an add.
+func diffMarkdownDeps(current []*types.Dependency, want []markdownDep) (add, remove []markdownDep) {
+	have := make(map[markdownDep]bool, len(current))
+	for _, dep := range current {
+		have[markdownDep{Type: dep.Type, ID: dep.DependsOnID}] = true
+	}
+	wanted := make(map[markdownDep]bool, len(want))
+	for _, dep := range want {
+		if dep.ID == "" || wanted[dep] {
+			continue
+		}
+		wanted[dep] = true
+		if !have[dep] {
+			add = append(add, dep)
+		}
+	}
+	for _, dep := range current {
+		key := markdownDep{Type: dep.Type, ID: dep.DependsOnID}
+		if !wanted[key] {
+			remove = append(remove, key)
+		}
+	}
+	return add, remove
+}
+
+// runMarkdownDirImport is the markdown-dir branch of bd import. It exits
+// non-zero when any file conflicted or failed, so CI notices.
+func runMarkdownDirImport(ctx context.Context, dir string, dryRun, overwrite bool) {
+	result, err := importMarkdownDir(ctx, store, dir, markdownImportOptions{DryRun: dryRun, Overwrite: overwrite, Actor: actor})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !dryRun && len(result.Created)+len(result.Updated) > 0 {
+		flushToJSONLWithState(flushState{forceDirty: true})
+	}
+
+	if jsonOutput {
+		outputJSON(result)
+	} else {
+		if dryRun {
+			fmt.Fprintf(os.Stderr, "Would create %d, update %d, %d unchanged\n", len(result.Created), len(result.Updated), len(result.Unchanged))
+		} else {
+			fmt.Fprintf(os.Stderr, "Created %d, updated %d, %d unchanged\n", len(result.Created), len(result.Updated), len(result.Unchanged))
+		}
+		for _, id := range result.Conflicts {
+			fmt.Fprintf(os.Stderr, "Conflict: %s changed since it was exported (skipped)\n", id)
+		}
+		if len(result.Conflicts) > 0 {
+			fmt.Fprintf(os.Stderr, "Re-export to pick up the latest version and redo the edits, or use --overwrite to apply them anyway.\n")
+		}
+		names := make([]string, 0, len(result.Errors))
+		for name := range result.Errors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "Error: %s: %s\n", name, result.Errors[name])
+		}
+	}
+	if len(result.Conflicts) > 0 || len(result.Errors) > 0 {
+		os.Exit(1)
+	}
+}
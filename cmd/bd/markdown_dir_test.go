@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/formats"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestMarkdownIssueRoundTrip(t *testing.T) {
+	ref := "gh-12"
+	issue := &types.Issue{
+		ID:                 "bd-a1",
+		Title:              "Ship it",
+		Description:        "What and why.\n\n## Background\nstays in the description",
+		AcceptanceCriteria: "It ships",
+		Status:             types.StatusInProgress,
+		Priority:           0,
+		IssueType:          types.TypeFeature,
+		Assignee:           "alice",
+		Labels:             []string{"ui", "backend"},
+		ExternalRef:        &ref,
+		UpdatedAt:          time.Date(2025, 3, 1, 12, 0, 0, 123, time.UTC),
+		Dependencies: []*types.Dependency{
+			{IssueID: "bd-a1", DependsOnID: "bd-b2", Type: types.DepParentChild},
+			{IssueID: "bd-a1", DependsOnID: "bd-c3", Type: types.DepBlocks},
+		},
+	}
+	data, err := renderMarkdownIssue(issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"priority: 0\n", "labels: [backend, ui]\n", "  - blocks: bd-c3\n", "# Ship it\n", "## Acceptance Criteria\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("rendered file is missing %q:\n%s", want, data)
+		}
+	}
+
+	m, err := parseMarkdownIssue(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != "bd-a1" || m.Title != "Ship it" || m.Description != issue.Description || m.AcceptanceCriteria != "It ships" || m.Design != "" {
+		t.Errorf("parsed = %+v", m)
+	}
+	if m.Priority == nil || *m.Priority != 0 || m.ExternalRef != "gh-12" || len(m.Deps) != 2 || m.Deps[0].Type != types.DepBlocks {
+		t.Errorf("parsed front matter = %+v", m.markdownFrontMatter)
+	}
+	updates, err := m.fieldUpdates(issue)
+	if err != nil || len(updates) != 0 {
+		t.Errorf("fieldUpdates = %v, %v; want no changes after a round trip", updates, err)
+	}
+}
+
+func TestParseMarkdownIssue(t *testing.T) {
+	m, err := parseMarkdownIssue([]byte("---\r\ndeps:\r\n  - bd-1\r\n  - related: bd-2\r\n---\r\n# New\r\nJust text\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Title != "New" || m.Description != "Just text" {
+		t.Errorf("parsed = %+v", m)
+	}
+	if len(m.Deps) != 2 || m.Deps[0] != (markdownDep{Type: types.DepBlocks, ID: "bd-1"}) || m.Deps[1] != (markdownDep{Type: types.DepRelated, ID: "bd-2"}) {
+		t.Errorf("deps = %+v", m.Deps)
+	}
+
+	for name, input := range map[string]string{
+		"no front matter": "# Title\n",
+		"unterminated":    "---\nid: x\n# Title\n",
+		"no title":        "---\nid: x\n---\nbody\n",
+	} {
+		if _, err := parseMarkdownIssue([]byte(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestImportMarkdownDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	s := newTestStore(t, filepath.Join(tmpDir, ".beads", "beads.db"))
+	ctx := context.Background()
+
+	a := &types.Issue{Title: "A", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	b := &types.Issue{Title: "B", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	for _, issue := range []*types.Issue{a, b} {
+		if err := s.CreateIssue(ctx, issue, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AddLabel(ctx, a.ID, "old", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(tmpDir, "issues")
+	for _, id := range []string{a.ID, b.ID} {
+		issue, _ := s.GetIssue(ctx, id)
+		issue.Labels, _ = s.GetLabels(ctx, id)
+		if _, err := writeMarkdownDir(dir, []*types.Issue{issue}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	edit := func(id string, fn func(string) string) {
+		t.Helper()
+		path := filepath.Join(dir, markdownFileName(id))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(fn(string(data))), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	edit(a.ID, func(s string) string {
+		s = strings.Replace(s, "labels: [old]", "labels: [new]\ndeps:\n  - "+b.ID, 1)
+		return s + "\n## Notes\n\nedited\n"
+	})
+	edit(b.ID, func(s string) string { return strings.Replace(s, "priority: 2", "priority: 1", 1) })
+	if err := os.WriteFile(filepath.Join(dir, "draft.md"), []byte("---\ntype: bug\n---\n# Drafted\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// B changes after export, so its edit conflicts
+	time.Sleep(10 * time.Millisecond)
+	if err := s.UpdateIssue(ctx, b.ID, map[string]interface{}{"title": "B2"}, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := importMarkdownDir(ctx, s, dir, markdownImportOptions{Actor: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Created) != 1 || len(result.Updated) != 1 || result.Updated[0] != a.ID || len(result.Conflicts) != 1 || result.Conflicts[0] != b.ID {
+		t.Fatalf("result = %+v", result)
+	}
+
+	got, _ := s.GetIssue(ctx, a.ID)
+	labels, _ := s.GetLabels(ctx, a.ID)
+	deps, _ := s.GetDependencyRecords(ctx, a.ID)
+	if got.Notes != "edited" || len(labels) != 1 || labels[0] != "new" || len(deps) != 1 || deps[0].DependsOnID != b.ID {
+		t.Errorf("A = notes %q, labels %v, deps %v", got.Notes, labels, deps)
+	}
+	if got, _ := s.GetIssue(ctx, b.ID); got.Priority != 2 {
+		t.Errorf("conflicting edit was applied: priority %d", got.Priority)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "draft.md")); !os.IsNotExist(err) {
+		t.Error("draft.md was not renamed to the new issue's file")
+	}
+	if _, err := os.Stat(filepath.Join(dir, markdownFileName(result.Created[0]))); err != nil {
+		t.Errorf("created issue file: %v", err)
+	}
+
+	// Applied files were rewritten, so importing again changes nothing
+	result, err = importMarkdownDir(ctx, s, dir, markdownImportOptions{Actor: "test", Overwrite: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != b.ID || len(result.Unchanged) != 2 {
+		t.Errorf("second import = %+v, want only the overwritten conflict updated", result)
+	}
+}
+
+func TestMarkdownDirFormatDecodeDir(t *testing.T) {
+	dir := t.TempDir()
+	issue := &types.Issue{
+		ID: "test-a1", Title: "Decode me", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeBug,
+		Labels:    []string{"x"},
+		UpdatedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Dependencies: []*types.Dependency{
+			{IssueID: "test-a1", DependsOnID: "test-b2", Type: types.DepBlocks},
+		},
+	}
+	if _, err := writeMarkdownDir(dir, []*types.Issue{issue}); err != nil {
+		t.Fatal(err)
+	}
+	draft := "---\nstatus: open\n---\n# Draft\n"
+	if err := os.WriteFile(filepath.Join(dir, "draft.md"), []byte(draft), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := markdownDirFormat{}.DecodeDir(context.Background(), dir, formats.Options{Prefix: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("decoded %d issues, want 2", len(issues))
+	}
+	byTitle := map[string]*types.Issue{}
+	for _, i := range issues {
+		byTitle[i.Title] = i
+	}
+	got := byTitle["Decode me"]
+	if got.ID != "test-a1" || !got.UpdatedAt.Equal(issue.UpdatedAt) || len(got.Labels) != 1 {
+		t.Errorf("decoded = %+v", got)
+	}
+	if len(got.Dependencies) != 1 || got.Dependencies[0].DependsOnID != "test-b2" || got.Dependencies[0].IssueID != "test-a1" {
+		t.Errorf("deps = %+v", got.Dependencies)
+	}
+	if d := byTitle["Draft"]; d == nil || !strings.HasPrefix(d.ID, "test-") {
+		t.Errorf("draft got no id: %+v", d)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/steveyegge/beads/internal/formula"
 	"github.com/steveyegge/beads/internal/storage"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/ui"
@@ -557,7 +556,10 @@ func resolveOrDescribe(ctx context.Context, s storage.Storage, operand string) (
 	}
 
 	// Try to load the formula (but don't cook it)
-	parser := formula.NewParser()
+	parser, err := newFormulaParser(nil)
+	if err != nil {
+		return nil, "", err
+	}
 	f, err := parser.LoadByName(operand)
 	if err != nil {
 		return nil, "", fmt.Errorf("'%s' not found as issue or formula: %w", operand, err)
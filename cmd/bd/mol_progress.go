@@ -75,6 +75,7 @@ Example:
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		stats.Usage = loadUsageForDisplay(ctx).rollup(moleculeID)
 
 		if jsonOutput {
 			// Add computed fields for JSON output
@@ -89,6 +90,9 @@ Example:
 			if stats.Total > 0 {
 				output["percent"] = float64(stats.Completed) * 100 / float64(stats.Total)
 			}
+			if stats.Usage != nil {
+				output["usage"] = stats.Usage
+			}
 			if stats.FirstClosed != nil && stats.LastClosed != nil && stats.Completed > 1 {
 				duration := stats.LastClosed.Sub(*stats.FirstClosed)
 				if duration > 0 {
@@ -175,6 +179,11 @@ func printMoleculeProgressStats(stats *types.MoleculeProgressStats) {
 			}
 		}
 	}
+
+	// Spend rolled up from the audit log
+	if stats.Usage != nil {
+		fmt.Printf("Usage: %s\n", formatUsage(stats.Usage))
+	}
 }
 
 // formatNumber formats large numbers with commas (handles millions)
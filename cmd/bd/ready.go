@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/capabilities"
 	"github.com/steveyegge/beads/internal/config"
 	"github.com/steveyegge/beads/internal/rpc"
 	"github.com/steveyegge/beads/internal/storage/sqlite"
@@ -35,14 +37,9 @@ This is useful for agents executing molecules to see which steps can run next.`,
 			return
 		}
 
-		// Check for Turso mode flag
-		tursoMode, _ := cmd.Flags().GetBool("turso")
-		if tursoMode {
-			runReadyWithTurso(cmd)
-			return
-		}
-
-		// Try Turso cache if available (unless explicitly disabled)
+		// In --turso mode the store is backed by the task files and the
+		// regular query path below applies. Otherwise, try the Turso cache
+		// directly if one exists.
 		if !tursoMode && tryReadyWithTursoCache(cmd) {
 			return
 		}
@@ -291,6 +288,9 @@ var blockedCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		for _, issue := range blocked {
+			issue.ExternalBlockers = externalBlockers(ctx, issue)
+		}
 		if jsonOutput {
 			// Always output array, even if empty
 			if blocked == nil {
@@ -314,11 +314,38 @@ var blockedCmd = &cobra.Command{
 			}
 			fmt.Printf("  Blocked by %d open dependencies: %v\n",
 				issue.BlockedByCount, blockedBy)
+			for _, ext := range issue.ExternalBlockers {
+				fmt.Printf("  %s %s: %s\n", ui.RenderWarn("⏳"), ext.Ref, ext.Reason)
+			}
 			fmt.Println()
 		}
 	},
 }
 
+// externalBlockers explains why each external:... blocker of an issue is
+// unsatisfied. Backends that don't resolve external deps themselves leave
+// ExternalBlockers empty, so those refs are resolved here.
+func externalBlockers(ctx context.Context, issue *types.BlockedIssue) []types.ExternalBlocker {
+	if len(issue.ExternalBlockers) > 0 {
+		return issue.ExternalBlockers
+	}
+	var refs []string
+	for _, ref := range issue.BlockedBy {
+		if IsExternalRef(ref) {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	resolved := capabilities.Default().ResolveAll(ctx, refs)
+	var result []types.ExternalBlocker
+	for _, ref := range refs {
+		result = append(result, types.ExternalBlocker{Ref: ref, Reason: resolved[ref].Reason})
+	}
+	return result
+}
+
 // runMoleculeReady shows ready steps within a specific molecule
 func runMoleculeReady(_ *cobra.Command, molIDArg string) {
 	ctx := rootCtx
@@ -562,7 +589,6 @@ func init() {
 	readyCmd.Flags().String("mol-type", "", "Filter by molecule type: swarm, patrol, or work")
 	readyCmd.Flags().Bool("pretty", false, "Display issues in a tree format with status/priority symbols")
 	readyCmd.Flags().Bool("include-deferred", false, "Include issues with future defer_until timestamps")
-	readyCmd.Flags().Bool("turso", false, "Force Turso cache mode (error if cache doesn't exist)")
 	rootCmd.AddCommand(readyCmd)
 	blockedCmd.Flags().String("parent", "", "Filter to descendants of this bead/epic")
 	rootCmd.AddCommand(blockedCmd)
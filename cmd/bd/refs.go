@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/storage/gitrefs"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+var refsCmd = &cobra.Command{
+	Use:     "refs",
+	GroupID: "sync",
+	Short:   "Git refs storage: one ref per issue instead of a tracked JSONL file",
+	Long: `Manage issues stored as git refs.
+
+In git refs mode each issue is a JSON blob under refs/beads/issues/<id>.
+Nothing is written to the working tree or the code branch, so issue edits
+never churn diffs or conflict with code merges, and 'bd sync' fetches and
+pushes each issue ref on its own, three-way merging only issues both sides
+changed.
+
+Run any command with --git-refs (or set 'git-refs: true' in
+.beads/config.yaml) to use the refs as the database. Use 'bd refs import'
+to move existing issues from issues.jsonl into refs.`,
+}
+
+var refsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch, merge and push issue refs",
+	Long: `Exchange refs/beads/issues/* with a remote.
+
+Each issue is reconciled on its own against the value recorded at the last
+sync: unchanged sides take the other side's value, deletions propagate, and
+issues changed on both sides are three-way merged with the same field rules
+as the JSONL merge driver. 'bd sync --git-refs' runs the same sync.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		remote, _ := cmd.Flags().GetString("remote")
+		runGitRefsSync(rootCtx, remote)
+	},
+}
+
+var refsImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Copy issues from a JSONL file into issue refs",
+	Long: `Copy every issue from a JSONL file (default: .beads/issues.jsonl) into
+refs/beads/issues/*. Existing refs for the same IDs are overwritten; other
+refs are left alone.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("refs import")
+		ctx := rootCtx
+
+		path := findJSONLPath()
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if path == "" {
+			FatalError("no JSONL file found (pass one explicitly)")
+		}
+		issues, err := loadIssuesFromJSONL(path)
+		if err != nil {
+			FatalError("reading %s: %v", path, err)
+		}
+
+		refStore := openGitRefsStore(ctx)
+		if err := refStore.LoadFromIssues(issues); err != nil {
+			FatalError("loading issues: %v", err)
+		}
+		if prefix, _ := refStore.GetConfig(ctx, "issue_prefix"); prefix == "" {
+			if prefix := detectPrefixFromIssues(issues); prefix != "" {
+				_ = refStore.SetConfig(ctx, "issue_prefix", prefix)
+			}
+		}
+		if err := refStore.Close(); err != nil {
+			FatalError("writing refs: %v", err)
+		}
+
+		if jsonOutput {
+			outputJSON(map[string]interface{}{"imported": len(issues), "source": path})
+			return
+		}
+		fmt.Printf("%s Imported %d issues from %s into %s*\n",
+			ui.RenderPass("✓"), len(issues), path, gitrefs.IssueRefPrefix)
+	},
+}
+
+// openGitRefsStore opens the git refs storage of the current repository,
+// exiting on error.
+func openGitRefsStore(ctx context.Context) *gitrefs.GitRefsStorage {
+	v, err := vcs.GetGit()
+	if err != nil {
+		FatalErrorWithHint("not in a git repository", "git refs storage lives in the repository's refs")
+	}
+	refStore, err := gitrefs.New(ctx, v)
+	if err != nil {
+		FatalError("%v", err)
+	}
+	return refStore
+}
+
+// runGitRefsSync syncs issue refs with remote. In --git-refs mode pending
+// changes are flushed first and the store is reloaded afterwards, so the rest
+// of the command (and its final flush) sees the merged state.
+func runGitRefsSync(ctx context.Context, remote string) {
+	v, err := vcs.GetGit()
+	if err != nil {
+		FatalErrorWithHint("not in a git repository", "git refs storage lives in the repository's refs")
+	}
+
+	refStore, _ := store.(*gitrefs.GitRefsStorage)
+	if refStore != nil {
+		if err := refStore.Flush(ctx); err != nil {
+			FatalError("writing pending changes: %v", err)
+		}
+	}
+
+	result, err := gitrefs.Sync(ctx, v, remote)
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+
+	if refStore != nil {
+		if err := refStore.Reload(ctx); err != nil {
+			FatalError("reloading issues: %v", err)
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(result)
+		return
+	}
+	total := len(result.Pulled) + len(result.Pushed) + len(result.Merged) + len(result.Deleted) + len(result.Removed)
+	if total == 0 {
+		fmt.Printf("%s Issue refs already in sync\n", ui.RenderPass("✓"))
+		return
+	}
+	fmt.Printf("%s Synced %d issue refs\n", ui.RenderPass("✓"), total)
+	for _, line := range []struct {
+		label string
+		ids   []string
+	}{
+		{"Pulled", result.Pulled},
+		{"Pushed", result.Pushed},
+		{"Merged", result.Merged},
+		{"Deleted locally", result.Deleted},
+		{"Deleted on remote", result.Removed},
+	} {
+		if len(line.ids) > 0 {
+			fmt.Printf("   %s: %s\n", line.label, strings.Join(line.ids, ", "))
+		}
+	}
+}
+
+func init() {
+	refsSyncCmd.Flags().String("remote", "origin", "Remote to sync issue refs with")
+	refsSyncCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output sync result in JSON format")
+	refsImportCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output JSON format")
+	refsCmd.AddCommand(refsSyncCmd)
+	refsCmd.AddCommand(refsImportCmd)
+	rootCmd.AddCommand(refsCmd)
+}
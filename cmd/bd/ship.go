@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/capabilities"
 	"github.com/steveyegge/beads/internal/rpc"
 	"github.com/steveyegge/beads/internal/storage/sqlite"
 	"github.com/steveyegge/beads/internal/types"
@@ -20,16 +22,21 @@ var shipCmd = &cobra.Command{
 This command:
   1. Finds issue with export:<capability> label
   2. Validates issue is closed (or --force to override)
-  3. Adds provides:<capability> label
+  3. Adds provides:<capability> label (provides:<capability>@<version>
+     with --version)
 
 External projects can depend on this capability using:
   bd dep add <issue> external:<project>:<capability>
+  bd dep add <issue> external:<project>:<capability>@>=2   # Versioned
 
 The capability is resolved when the external project has a closed issue
-with the provides:<capability> label.
+with the provides:<capability> label (and a version matching the constraint,
+if one is given). Shipping a new version adds another provides: label, so
+every shipped version stays visible in bd capabilities list.
 
 Examples:
   bd ship mol-run-assignee              # Ship the mol-run-assignee capability
+  bd ship api --version 2.1.0           # Ship version 2.1.0 of the api capability
   bd ship mol-run-assignee --force      # Ship even if issue is not closed
   bd ship mol-run-assignee --dry-run    # Preview without making changes`,
 	Args: cobra.ExactArgs(1),
@@ -42,12 +49,26 @@ func runShip(cmd *cobra.Command, args []string) {
 	capability := args[0]
 	force, _ := cmd.Flags().GetBool("force")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	version, _ := cmd.Flags().GetString("version")
 
 	ctx := rootCtx
 
+	if strings.ContainsAny(capability, ":@") {
+		fmt.Fprintf(os.Stderr, "Error: invalid capability name '%s'\n", capability)
+		fmt.Fprintf(os.Stderr, "Hint: pass the version separately: bd ship <capability> --version <version>\n")
+		os.Exit(1)
+	}
+	if version != "" {
+		if _, err := capabilities.NormalizeVersion(version); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v (expected semver, e.g. 2.1.0)\n", err)
+			os.Exit(1)
+		}
+		version = strings.TrimPrefix(version, "v")
+	}
+
 	// Find issue with export:<capability> label
-	exportLabel := "export:" + capability
-	providesLabel := "provides:" + capability
+	exportLabel := capabilities.ExportPrefix + capability
+	providesLabel := capabilities.ProvidesLabel(capability, version)
 
 	var issues []*types.Issue
 	var err error
@@ -127,11 +148,12 @@ func runShip(cmd *cobra.Command, args []string) {
 			outputJSON(map[string]interface{}{
 				"status":     "already_shipped",
 				"capability": capability,
+				"version":    version,
 				"issue_id":   issue.ID,
 			})
 		} else {
 			fmt.Printf("%s Capability '%s' already shipped (%s)\n",
-				ui.RenderPass("✓"), capability, issue.ID)
+				ui.RenderPass("✓"), strings.TrimPrefix(providesLabel, capabilities.ProvidesPrefix), issue.ID)
 		}
 		return
 	}
@@ -162,6 +184,7 @@ func runShip(cmd *cobra.Command, args []string) {
 		outputJSON(map[string]interface{}{
 			"status":     "shipped",
 			"capability": capability,
+			"version":    version,
 			"issue_id":   issue.ID,
 			"label":      providesLabel,
 		})
@@ -177,6 +200,7 @@ func runShip(cmd *cobra.Command, args []string) {
 func init() {
 	shipCmd.Flags().Bool("force", false, "Ship even if issue is not closed")
 	shipCmd.Flags().Bool("dry-run", false, "Preview without making changes")
+	shipCmd.Flags().String("version", "", "Semver version being shipped (adds provides:<capability>@<version>)")
 
 	rootCmd.AddCommand(shipCmd)
 }
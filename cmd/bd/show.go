@@ -83,6 +83,9 @@ var showCmd = &cobra.Command{
 			return
 		}
 
+		// Usage rollups from the audit log (nil when nothing was recorded)
+		usage := loadUsageForDisplay(ctx)
+
 		// If daemon is running, use RPC (but fall back to direct mode for routed IDs)
 		if daemonClient != nil {
 			allDetails := []interface{}{}
@@ -165,6 +168,7 @@ var showCmd = &cobra.Command{
 								break
 							}
 						}
+						details.Usage = usage.rollup(details.ID)
 						allDetails = append(allDetails, details)
 					}
 				} else {
@@ -220,6 +224,7 @@ var showCmd = &cobra.Command{
 					if issue.EstimatedMinutes != nil {
 						fmt.Printf("Estimated: %d minutes\n", *issue.EstimatedMinutes)
 					}
+					printIssueUsage(issue, usage.rollup(issue.ID))
 					fmt.Printf("Created: %s\n", issue.CreatedAt.Format("2006-01-02 15:04"))
 					if issue.CreatedBy != "" {
 						fmt.Printf("Created by: %s\n", issue.CreatedBy)
@@ -412,6 +417,9 @@ var showCmd = &cobra.Command{
 						break
 					}
 				}
+				if !result.Routed {
+					details.Usage = usage.rollup(issue.ID)
+				}
 				allDetails = append(allDetails, details)
 				result.Close() // Close before continuing to next iteration
 				continue
@@ -449,6 +457,7 @@ var showCmd = &cobra.Command{
 			if issue.EstimatedMinutes != nil {
 				fmt.Printf("Estimated: %d minutes\n", *issue.EstimatedMinutes)
 			}
+			printIssueUsage(issue, usage.rollup(issue.ID))
 			fmt.Printf("Created: %s\n", issue.CreatedAt.Format("2006-01-02 15:04"))
 			if issue.CreatedBy != "" {
 				fmt.Printf("Created by: %s\n", issue.CreatedBy)
@@ -7,9 +7,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/config"
 	"github.com/steveyegge/beads/internal/rpc"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/ui"
@@ -27,7 +29,10 @@ State labels follow the convention <dimension>:<value>, for example:
   mode:degraded
   health:healthy
 
-This command extracts the value for a given dimension.
+This command extracts the value for a given dimension. A dimension with a
+schema in .beads/config.yaml reads as its default when unset.
+
+See also: bd state list, bd state history, bd state schema, bd state expire.
 
 Examples:
   bd state witness-abc patrol     # Output: active
@@ -88,6 +93,15 @@ Examples:
 			}
 		}
 
+		// An unset dimension reads as its schema default
+		isDefault := false
+		if value == "" {
+			if schema, err := config.GetStateSchema(dimension); err == nil && schema != nil && schema.Default != "" {
+				value = schema.Default
+				isDefault = true
+			}
+		}
+
 		if jsonOutput {
 			result := map[string]interface{}{
 				"issue_id":  fullID,
@@ -97,12 +111,17 @@ Examples:
 			if value == "" {
 				result["value"] = nil
 			}
+			if isDefault {
+				result["default"] = true
+			}
 			outputJSON(result)
 			return
 		}
 
 		if value == "" {
 			fmt.Printf("(no %s state set)\n", dimension)
+		} else if isDefault {
+			fmt.Printf("%s (default)\n", value)
 		} else {
 			fmt.Println(value)
 		}
@@ -125,6 +144,10 @@ State labels follow the convention <dimension>:<value>, for example:
   mode:normal, mode:degraded
   health:healthy, health:failing
 
+If the dimension has a schema under states: in .beads/config.yaml, the
+value must be one of its values and the change one of its transitions
+(see 'bd state schema'). --force skips the check.
+
 Examples:
   bd set-state witness-abc patrol=muted --reason "Investigating stuck polecat"
   bd set-state witness-abc mode=degraded --reason "High error rate detected"
@@ -201,6 +224,18 @@ The --reason flag provides context for the event bead (recommended).`,
 
 		newLabel := dimension + ":" + newValue
 
+		// Validate against the dimension's declared schema, if any
+		force, _ := cmd.Flags().GetBool("force")
+		schema, err := config.GetStateSchema(dimension)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		if schema != nil && !force && oldLabel != newLabel {
+			if err := schema.Validate(oldValue, newValue); err != nil {
+				FatalErrorRespectJSON("%v (use --force to override)", err)
+			}
+		}
+
 		// Skip if no change
 		if oldLabel == newLabel {
 			if jsonOutput {
@@ -216,27 +251,22 @@ The --reason flag provides context for the event bead (recommended).`,
 			return
 		}
 
-		// 1. Create event bead recording the state change
-		eventTitle := fmt.Sprintf("State change: %s → %s", dimension, newValue)
-		eventDesc := ""
-		if oldValue != "" {
-			eventDesc = fmt.Sprintf("Changed %s from %s to %s", dimension, oldValue, newValue)
-		} else {
-			eventDesc = fmt.Sprintf("Set %s to %s", dimension, newValue)
-		}
-		if reason != "" {
-			eventDesc += "\n\nReason: " + reason
-		}
-
 		var eventID string
 		if daemonClient != nil {
+			// 1. Create event bead recording the state change
+			eventTitle, eventDesc := stateEventText(dimension, oldValue, newValue, reason)
+			createdBy := getActorWithGit()
 			createArgs := &rpc.CreateArgs{
-				Parent:      fullID,
-				Title:       eventTitle,
-				Description: eventDesc,
-				IssueType:   "event",
-				Priority:    4, // Low priority for events
-				CreatedBy:   getActorWithGit(),
+				Parent:        fullID,
+				Title:         eventTitle,
+				Description:   eventDesc,
+				IssueType:     "event",
+				Priority:      4, // Low priority for events
+				CreatedBy:     createdBy,
+				EventCategory: stateEventKind,
+				EventActor:    createdBy,
+				EventTarget:   fullID,
+				EventPayload:  stateEventPayload(dimension, oldValue, newValue, reason),
 			}
 			resp, err := daemonClient.Create(createArgs)
 			if err != nil {
@@ -247,67 +277,24 @@ The --reason flag provides context for the event bead (recommended).`,
 				FatalErrorRespectJSON("parsing event response: %v", err)
 			}
 			eventID = issue.ID
-		} else {
-			// Get next child ID for the event
-			childID, err := store.GetNextChildID(ctx, fullID)
-			if err != nil {
-				FatalErrorRespectJSON("generating child ID: %v", err)
-			}
-
-			event := &types.Issue{
-				ID:          childID,
-				Title:       eventTitle,
-				Description: eventDesc,
-				Status:      types.StatusClosed, // Events are immediately closed
-				Priority:    4,
-				IssueType:   types.IssueType("event"),
-				CreatedBy:   getActorWithGit(),
-			}
-			if err := store.CreateIssue(ctx, event, actor); err != nil {
-				FatalErrorRespectJSON("creating event: %v", err)
-			}
 
-			// Add parent-child dependency
-			dep := &types.Dependency{
-				IssueID:     childID,
-				DependsOnID: fullID,
-				Type:        types.DepParentChild,
-			}
-			if err := store.AddDependency(ctx, dep, actor); err != nil {
-				WarnError("failed to add parent-child dependency: %v", err)
-			}
-
-			eventID = childID
-		}
-
-		// 2. Remove old label if exists
-		if oldLabel != "" {
-			if daemonClient != nil {
+			// 2. Remove old label if exists
+			if oldLabel != "" {
 				_, err := daemonClient.RemoveLabel(&rpc.LabelRemoveArgs{ID: fullID, Label: oldLabel})
 				if err != nil {
 					WarnError("failed to remove old label %s: %v", oldLabel, err)
 				}
-			} else {
-				if err := store.RemoveLabel(ctx, fullID, oldLabel, actor); err != nil {
-					WarnError("failed to remove old label %s: %v", oldLabel, err)
-				}
 			}
-		}
 
-		// 3. Add new label
-		if daemonClient != nil {
-			_, err := daemonClient.AddLabel(&rpc.LabelAddArgs{ID: fullID, Label: newLabel})
-			if err != nil {
+			// 3. Add new label
+			if _, err := daemonClient.AddLabel(&rpc.LabelAddArgs{ID: fullID, Label: newLabel}); err != nil {
 				FatalErrorRespectJSON("adding label: %v", err)
 			}
 		} else {
-			if err := store.AddLabel(ctx, fullID, newLabel, actor); err != nil {
-				FatalErrorRespectJSON("adding label: %v", err)
+			eventID, err = setStateDirect(ctx, store, fullID, dimension, oldValue, newValue, reason, actor)
+			if err != nil {
+				FatalErrorRespectJSON("%v", err)
 			}
-		}
-
-		// Schedule auto-flush if in direct mode
-		if daemonClient == nil {
 			markDirtyAndScheduleFlush()
 		}
 
@@ -403,11 +390,25 @@ Example:
 			}
 		}
 
+		// Declared dimensions without a label read as their default
+		var defaults []string
+		if schemas, err := config.GetStateSchemas(); err == nil {
+			for _, dimension := range config.SortedStateDimensions(schemas) {
+				if _, ok := states[dimension]; !ok && schemas[dimension].Default != "" {
+					states[dimension] = schemas[dimension].Default
+					defaults = append(defaults, dimension)
+				}
+			}
+		}
+
 		if jsonOutput {
 			result := map[string]interface{}{
 				"issue_id": fullID,
 				"states":   states,
 			}
+			if len(defaults) > 0 {
+				result["defaults"] = defaults
+			}
 			outputJSON(result)
 			return
 		}
@@ -419,6 +420,9 @@ Example:
 
 		fmt.Printf("\n%s State for %s:\n", ui.RenderAccent("📊"), fullID)
 		for dimension, value := range states {
+			if slices.Contains(defaults, dimension) {
+				value += ui.RenderMuted(" (default)")
+			}
 			fmt.Printf("  %s: %s\n", dimension, value)
 		}
 		fmt.Println()
@@ -428,6 +432,7 @@ Example:
 func init() {
 	// set-state flags
 	setStateCmd.Flags().String("reason", "", "Reason for the state change (recorded in event)")
+	setStateCmd.Flags().Bool("force", false, "Set the value even if the dimension's schema does not allow it")
 
 	// Add subcommands
 	stateCmd.AddCommand(stateListCmd)
@@ -0,0 +1,653 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/rpc"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/utils"
+)
+
+// StateInterval is one entry of a state dimension's timeline: the value the
+// dimension held from Start until End (zero End = still current).
+type StateInterval struct {
+	Value    string    `json:"value"`
+	Default  bool      `json:"default,omitempty"` // Unset, so the schema default applied
+	Actor    string    `json:"actor,omitempty"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end,omitempty"`
+	Duration string    `json:"duration"`
+	elapsed  time.Duration
+}
+
+// ExpiredState is a state value that has outlived its dimension's TTL.
+type ExpiredState struct {
+	IssueID   string    `json:"issue_id"`
+	Dimension string    `json:"dimension"`
+	Value     string    `json:"value"`
+	Default   string    `json:"default"`
+	SetAt     time.Time `json:"set_at"`
+}
+
+var stateHistoryCmd = &cobra.Command{
+	Use:   "history <issue-id> <dimension>",
+	Short: "Show the timeline of a state dimension",
+	Long: `Show the values a state dimension has held on an issue, built from the
+state-change event beads recorded by set-state.
+
+With --since, the timeline is clipped to that window and a summary shows how
+long the dimension spent in each value.
+
+Examples:
+  bd state history witness-abc health
+  bd state history witness-abc health --since 24h
+  bd state history witness-abc health --since 24h --json`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		since, _ := cmd.Flags().GetDuration("since")
+		if err := ensureDirectMode("state history queries event beads directly"); err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		ctx := rootCtx
+		dimension := args[1]
+
+		fullID, err := utils.ResolvePartialID(ctx, store, args[0])
+		if err != nil {
+			FatalErrorRespectJSON("resolving %s: %v", args[0], err)
+		}
+		issue, err := store.GetIssue(ctx, fullID)
+		if err != nil || issue == nil {
+			FatalErrorRespectJSON("issue %s not found", fullID)
+		}
+		eventType := types.TypeEvent
+		events, err := store.SearchIssues(ctx, "", types.IssueFilter{ParentID: &fullID, IssueType: &eventType})
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		schema, err := config.GetStateSchema(dimension)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		defaultValue := ""
+		if schema != nil {
+			defaultValue = schema.Default
+		}
+
+		now := time.Now()
+		timeline := stateTimeline(events, dimension, defaultValue, issue.CreatedAt, now)
+		if since > 0 {
+			timeline = clipStateTimeline(timeline, now.Add(-since), now)
+		}
+		totals := stateTotals(timeline)
+
+		if jsonOutput {
+			if timeline == nil {
+				timeline = []StateInterval{}
+			}
+			result := map[string]interface{}{
+				"issue_id":  fullID,
+				"dimension": dimension,
+				"timeline":  timeline,
+			}
+			if since > 0 {
+				summary := make(map[string]string, len(totals))
+				for value, d := range totals {
+					summary[value] = d.Round(time.Second).String()
+				}
+				result["since"] = since.String()
+				result["totals"] = summary
+			}
+			outputJSON(result)
+			return
+		}
+
+		if len(timeline) == 0 {
+			fmt.Printf("\n%s has no %s history\n\n", fullID, dimension)
+			return
+		}
+		fmt.Printf("\n%s %s history for %s:\n\n", ui.RenderAccent("📈"), dimension, fullID)
+		for _, in := range timeline {
+			value := in.Value
+			if value == "" {
+				value = "(unset)"
+			}
+			if in.Default {
+				value += ui.RenderMuted(" (default)")
+			}
+			end := "now"
+			if !in.End.IsZero() {
+				end = in.End.Local().Format("01-02 15:04")
+			}
+			by := ""
+			if in.Actor != "" {
+				by = ui.RenderMuted(" by " + in.Actor)
+			}
+			fmt.Printf("  %s → %-11s %-10s %s%s\n", in.Start.Local().Format("01-02 15:04"), end, in.Duration, value, by)
+		}
+		if since > 0 {
+			fmt.Printf("\nLast %s:\n", since)
+			values := make([]string, 0, len(totals))
+			for value := range totals {
+				values = append(values, value)
+			}
+			sort.Slice(values, func(i, j int) bool { return totals[values[i]] > totals[values[j]] })
+			for _, value := range values {
+				label := value
+				if label == "" {
+					label = "(unset)"
+				}
+				pct := float64(totals[value]) / float64(since) * 100
+				fmt.Printf("  %-12s %s (%.0f%%)\n", label, totals[value].Round(time.Second), pct)
+			}
+		}
+		fmt.Println()
+	},
+}
+
+var stateSchemaCmd = &cobra.Command{
+	Use:   "schema [dimension]",
+	Short: "Show declared state dimension schemas",
+	Long: `Show the state dimensions declared under states: in .beads/config.yaml.
+
+A schema lists the allowed values, the default for issues without a value,
+the allowed transitions, and a TTL after which a non-default value reverts
+to the default:
+
+  states:
+    health:
+      values: [healthy, degraded, failing]
+      default: healthy
+      transitions:
+        healthy: [degraded, failing]
+        degraded: [healthy, failing]
+        failing: [degraded]
+      ttl: 1h
+
+Values without a transitions entry may change to any value. Dimensions
+without a schema accept any value.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		schemas, err := config.GetStateSchemas()
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		dims := config.SortedStateDimensions(schemas)
+		if len(args) == 1 {
+			schema, ok := schemas[strings.ToLower(args[0])]
+			if !ok {
+				FatalErrorRespectJSON("no schema declared for %s", args[0])
+			}
+			dims = []string{schema.Dimension}
+		}
+
+		if jsonOutput {
+			result := make([]*config.StateSchema, 0, len(dims))
+			for _, dim := range dims {
+				result = append(result, schemas[dim])
+			}
+			outputJSON(result)
+			return
+		}
+
+		if len(dims) == 0 {
+			fmt.Println("No state schemas declared (add a states: section to .beads/config.yaml)")
+			return
+		}
+		for _, dim := range dims {
+			s := schemas[dim]
+			fmt.Printf("\n%s\n", ui.RenderAccent(dim))
+			values := "(any)"
+			if len(s.Values) > 0 {
+				values = strings.Join(s.Values, ", ")
+			}
+			fmt.Printf("  values:  %s\n", values)
+			if s.Default != "" {
+				fmt.Printf("  default: %s\n", s.Default)
+			}
+			if s.TTL > 0 {
+				fmt.Printf("  ttl:     %s\n", s.TTL)
+			}
+			if len(s.Transitions) > 0 {
+				fmt.Printf("  transitions:\n")
+				froms := make([]string, 0, len(s.Transitions))
+				for from := range s.Transitions {
+					froms = append(froms, from)
+				}
+				sort.Strings(froms)
+				for _, from := range froms {
+					to := strings.Join(s.Transitions[from], ", ")
+					if to == "" {
+						to = "(none)"
+					}
+					fmt.Printf("    %s → %s\n", from, to)
+				}
+			}
+		}
+		fmt.Println()
+	},
+}
+
+var stateExpireCmd = &cobra.Command{
+	Use:   "expire",
+	Short: "Revert state values that have outlived their TTL",
+	Long: `Revert state values older than their dimension's ttl to the default,
+recording a state change event like set-state does.
+
+The daemon does this automatically while it runs; use this command when
+working without the daemon.
+
+Examples:
+  bd state expire --dry-run
+  bd state expire`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if !dryRun {
+			CheckReadonly("state expire")
+		}
+		if err := ensureDirectMode("state expire reads the events table directly"); err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		schemas, err := config.GetStateSchemas()
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+
+		ctx := rootCtx
+		expired, err := findExpiredStates(ctx, store, schemas, time.Now())
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		if !dryRun {
+			for _, e := range expired {
+				if _, err := expireState(ctx, store, e, schemas[e.Dimension], actor); err != nil {
+					FatalErrorRespectJSON("%v", err)
+				}
+			}
+			if len(expired) > 0 {
+				markDirtyAndScheduleFlush()
+			}
+		}
+
+		if jsonOutput {
+			if expired == nil {
+				expired = []ExpiredState{}
+			}
+			outputJSON(map[string]interface{}{
+				"dry_run": dryRun,
+				"expired": expired,
+			})
+			return
+		}
+		if len(expired) == 0 {
+			fmt.Println("No expired state values")
+			return
+		}
+		verb := "Reverted"
+		if dryRun {
+			verb = "Would revert"
+		}
+		for _, e := range expired {
+			fmt.Printf("%s %s %s %s: %s → %s (set %s ago)\n", ui.RenderWarn("⏱"), verb, ui.RenderID(e.IssueID), e.Dimension,
+				e.Value, e.Default, time.Since(e.SetAt).Round(time.Second))
+		}
+	},
+}
+
+func init() {
+	stateHistoryCmd.Flags().Duration("since", 0, "Only show the window ending now (e.g. 24h) and summarize time per value")
+	stateExpireCmd.Flags().Bool("dry-run", false, "Report expired values without reverting them")
+
+	stateCmd.AddCommand(stateHistoryCmd)
+	stateCmd.AddCommand(stateSchemaCmd)
+	stateCmd.AddCommand(stateExpireCmd)
+}
+
+// stateEventKind is the event_kind of the event beads that record state
+// changes; their payload is a stateChange.
+const stateEventKind = "state.changed"
+
+// stateChange is the payload of a state-change event bead.
+type stateChange struct {
+	Dimension string `json:"dimension"`
+	OldValue  string `json:"old_value,omitempty"`
+	NewValue  string `json:"new_value"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// stateEventPayload returns the payload of the event bead that records a
+// state change.
+func stateEventPayload(dimension, oldValue, newValue, reason string) string {
+	data, _ := json.Marshal(stateChange{Dimension: dimension, OldValue: oldValue, NewValue: newValue, Reason: reason})
+	return string(data)
+}
+
+// stateChangeOf decodes the state change an event bead records. It returns
+// false for other beads.
+func stateChangeOf(event *types.Issue) (stateChange, bool) {
+	var c stateChange
+	if event.EventKind != stateEventKind || json.Unmarshal([]byte(event.Payload), &c) != nil || c.Dimension == "" {
+		return stateChange{}, false
+	}
+	return c, true
+}
+
+// stateEventText returns the title and description of the event bead that
+// records a state change.
+func stateEventText(dimension, oldValue, newValue, reason string) (string, string) {
+	title := fmt.Sprintf("State change: %s → %s", dimension, newValue)
+	desc := ""
+	if oldValue != "" {
+		desc = fmt.Sprintf("Changed %s from %s to %s", dimension, oldValue, newValue)
+	} else {
+		desc = fmt.Sprintf("Set %s to %s", dimension, newValue)
+	}
+	if reason != "" {
+		desc += "\n\nReason: " + reason
+	}
+	return title, desc
+}
+
+// setStateDirect records a state change against the store: an event bead
+// under the issue, then the label swap. It returns the event bead ID.
+func setStateDirect(ctx context.Context, s storage.Storage, issueID, dimension, oldValue, newValue, reason, by string) (string, error) {
+	// 1. Create event bead recording the state change
+	childID, err := s.GetNextChildID(ctx, issueID)
+	if err != nil {
+		return "", fmt.Errorf("generating child ID: %w", err)
+	}
+	title, desc := stateEventText(dimension, oldValue, newValue, reason)
+	event := &types.Issue{
+		ID:          childID,
+		Title:       title,
+		Description: desc,
+		Status:      types.StatusClosed, // Events are immediately closed
+		Priority:    4,
+		IssueType:   types.TypeEvent,
+		CreatedBy:   by,
+		EventKind:   stateEventKind,
+		Actor:       by,
+		Target:      issueID,
+		Payload:     stateEventPayload(dimension, oldValue, newValue, reason),
+	}
+	if err := s.CreateIssue(ctx, event, by); err != nil {
+		return "", fmt.Errorf("creating event: %w", err)
+	}
+	dep := &types.Dependency{
+		IssueID:     childID,
+		DependsOnID: issueID,
+		Type:        types.DepParentChild,
+	}
+	if err := s.AddDependency(ctx, dep, by); err != nil {
+		WarnError("failed to add parent-child dependency: %v", err)
+	}
+
+	// 2. Remove old label if exists
+	if oldValue != "" {
+		oldLabel := dimension + ":" + oldValue
+		if err := s.RemoveLabel(ctx, issueID, oldLabel, by); err != nil {
+			WarnError("failed to remove old label %s: %v", oldLabel, err)
+		}
+	}
+
+	// 3. Add new label
+	if err := s.AddLabel(ctx, issueID, dimension+":"+newValue, by); err != nil {
+		return "", fmt.Errorf("adding label: %w", err)
+	}
+	return childID, nil
+}
+
+// parseStateFilters turns --state dimension=value specs into filters. A
+// value equal to the dimension's default also matches issues with no value.
+func parseStateFilters(specs []string) ([]types.StateFilter, error) {
+	var filters []types.StateFilter
+	for _, spec := range specs {
+		dimension, value, ok := strings.Cut(spec, "=")
+		dimension, value = strings.TrimSpace(dimension), strings.TrimSpace(value)
+		if !ok || dimension == "" || value == "" {
+			return nil, fmt.Errorf("invalid state filter %q, expected <dimension>=<value>", spec)
+		}
+		f := types.StateFilter{Dimension: dimension, Value: value}
+		schema, err := config.GetStateSchema(dimension)
+		if err != nil {
+			return nil, err
+		}
+		if schema != nil {
+			if !schema.Allows(value) {
+				return nil, fmt.Errorf("%q is not a valid %s value (allowed: %s)", value, dimension, strings.Join(schema.Values, ", "))
+			}
+			f.IncludeUnset = value == schema.Default
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// stateTimeline rebuilds the values a dimension has held on an issue from
+// the state-change event beads under it (in any order). Before the first
+// change the dimension held that change's old value, or the default.
+func stateTimeline(events []*types.Issue, dimension, defaultValue string, created, now time.Time) []StateInterval {
+	type change struct {
+		stateChange
+		at    time.Time
+		actor string
+	}
+	var changes []change
+	for _, e := range events {
+		if c, ok := stateChangeOf(e); ok && c.Dimension == dimension {
+			changes = append(changes, change{stateChange: c, at: e.CreatedAt, actor: e.Actor})
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	// Oldest first
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].at.Before(changes[j].at) })
+
+	var timeline []StateInterval
+	if changes[0].at.After(created) {
+		if first := changes[0].OldValue; first != "" {
+			timeline = append(timeline, StateInterval{Value: first, Start: created})
+		} else if defaultValue != "" {
+			timeline = append(timeline, StateInterval{Value: defaultValue, Default: true, Start: created})
+		}
+	}
+	for _, c := range changes {
+		in := StateInterval{Value: c.NewValue, Actor: c.actor, Start: c.at}
+		if n := len(timeline); n > 0 {
+			timeline[n-1].End = c.at
+		}
+		timeline = append(timeline, in)
+	}
+	for i := range timeline {
+		end := timeline[i].End
+		if end.IsZero() {
+			end = now
+		}
+		timeline[i].elapsed = end.Sub(timeline[i].Start)
+		timeline[i].Duration = timeline[i].elapsed.Round(time.Second).String()
+	}
+	return timeline
+}
+
+// clipStateTimeline keeps the part of a timeline inside [from, to].
+func clipStateTimeline(timeline []StateInterval, from, to time.Time) []StateInterval {
+	var clipped []StateInterval
+	for _, in := range timeline {
+		end := in.End
+		if end.IsZero() {
+			end = to
+		}
+		if !end.After(from) {
+			continue
+		}
+		if in.Start.Before(from) {
+			in.Start = from
+		}
+		in.elapsed = end.Sub(in.Start)
+		in.Duration = in.elapsed.Round(time.Second).String()
+		clipped = append(clipped, in)
+	}
+	return clipped
+}
+
+// stateTotals sums the time a timeline spent in each value.
+func stateTotals(timeline []StateInterval) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	for _, in := range timeline {
+		totals[in.Value] += in.elapsed
+	}
+	return totals
+}
+
+// findExpiredStates returns the state values that have outlived their
+// dimension's TTL. The set time is the latest state-change event bead
+// setting the value, falling back to the issue's last update when there is
+// none (e.g. the label was added with bd label).
+func findExpiredStates(ctx context.Context, s storage.Storage, schemas map[string]*config.StateSchema, now time.Time) ([]ExpiredState, error) {
+	var candidates []ExpiredState
+	for _, dim := range config.SortedStateDimensions(schemas) {
+		schema := schemas[dim]
+		if schema.TTL <= 0 {
+			continue
+		}
+		issues, err := s.SearchIssues(ctx, "", types.IssueFilter{States: []types.StateFilter{{Dimension: dim}}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues with %s state: %w", dim, err)
+		}
+		ids := make([]string, len(issues))
+		for i, issue := range issues {
+			ids[i] = issue.ID
+		}
+		labelsByID, err := s.GetLabelsForIssues(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			value := ""
+			for _, label := range labelsByID[issue.ID] {
+				if v, ok := strings.CutPrefix(label, dim+":"); ok {
+					value = v
+					break
+				}
+			}
+			if value == "" || value == schema.Default {
+				continue
+			}
+			candidates = append(candidates, ExpiredState{
+				IssueID:   issue.ID,
+				Dimension: dim,
+				Value:     value,
+				Default:   schema.Default,
+				SetAt:     issue.UpdatedAt,
+			})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// One query for all state-change beads rather than one per issue: this
+	// runs on every daemon tick
+	eventType := types.TypeEvent
+	events, err := s.SearchIssues(ctx, "", types.IssueFilter{IssueType: &eventType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state-change events: %w", err)
+	}
+	setAt := make(map[[3]string]time.Time)
+	for _, e := range events {
+		c, ok := stateChangeOf(e)
+		if !ok {
+			continue
+		}
+		key := [3]string{e.Target, c.Dimension, c.NewValue}
+		if e.CreatedAt.After(setAt[key]) {
+			setAt[key] = e.CreatedAt
+		}
+	}
+
+	var expired []ExpiredState
+	for _, c := range candidates {
+		if at, ok := setAt[[3]string{c.IssueID, c.Dimension, c.Value}]; ok {
+			c.SetAt = at
+		}
+		if schemas[c.Dimension].Expired(c.Value, c.SetAt, now) {
+			expired = append(expired, c)
+		}
+	}
+	return expired, nil
+}
+
+// expireState reverts an expired value to the dimension's default. The
+// revert skips transition rules: the TTL is part of the schema.
+func expireState(ctx context.Context, s storage.Storage, e ExpiredState, schema *config.StateSchema, by string) (string, error) {
+	reason := fmt.Sprintf("%s expired after %s (states.%s.ttl)", e.Value, schema.TTL, e.Dimension)
+	eventID, err := setStateDirect(ctx, s, e.IssueID, e.Dimension, e.Value, e.Default, reason, by)
+	if err != nil {
+		return "", fmt.Errorf("failed to expire %s on %s: %w", e.Dimension, e.IssueID, err)
+	}
+	return eventID, nil
+}
+
+// startStateExpirer reverts expired state values from the daemon. It does
+// nothing unless a state schema declares a ttl.
+func startStateExpirer(ctx context.Context, s storage.Storage, server *rpc.Server, log daemonLogger) {
+	schemas, err := config.GetStateSchemas()
+	if err != nil {
+		log.Warn("state expirer: invalid states config", "error", err)
+		return
+	}
+	var shortest time.Duration
+	for _, schema := range schemas {
+		if schema.TTL > 0 && (shortest == 0 || schema.TTL < shortest) {
+			shortest = schema.TTL
+		}
+	}
+	if shortest == 0 {
+		return
+	}
+	// Check often enough that a value never outlives its TTL by much
+	interval := min(time.Minute, max(shortest/4, time.Second))
+	log.Info("state expirer enabled", "interval", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				expireStatesOnce(ctx, s, server, schemas, log)
+			}
+		}
+	}()
+}
+
+// expireStatesOnce runs one daemon expiry pass.
+func expireStatesOnce(ctx context.Context, s storage.Storage, server *rpc.Server, schemas map[string]*config.StateSchema, log daemonLogger) {
+	expired, err := findExpiredStates(ctx, s, schemas, time.Now())
+	if err != nil {
+		log.Warn("state expirer: failed to find expired states", "error", err)
+		return
+	}
+	for _, e := range expired {
+		eventID, err := expireState(ctx, s, e, schemas[e.Dimension], "daemon")
+		if err != nil {
+			log.Warn("state expirer: failed to revert state", "issue", e.IssueID, "dimension", e.Dimension, "error", err)
+			continue
+		}
+		log.Info("state expirer: reverted state", "issue", e.IssueID, "dimension", e.Dimension, "from", e.Value, "to", e.Default)
+		server.EmitMutation(rpc.MutationEvent{Type: rpc.MutationUpdate, IssueID: e.IssueID, Actor: "daemon"})
+		server.EmitMutation(rpc.MutationEvent{Type: rpc.MutationCreate, IssueID: eventID, Actor: "daemon"})
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestStateTimeline(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ev := func(dimension, oldValue, newValue string, mins int) *types.Issue {
+		return &types.Issue{
+			IssueType: types.TypeEvent,
+			EventKind: stateEventKind,
+			Actor:     "witness",
+			Payload:   stateEventPayload(dimension, oldValue, newValue, ""),
+			CreatedAt: base.Add(time.Duration(mins) * time.Minute),
+		}
+	}
+	events := []*types.Issue{
+		ev("health", "healthy", "degraded", 90),
+		ev("health", "degraded", "healthy", 60),
+		ev("healthcheck", "", "on", 45),
+		ev("health", "", "degraded", 30),
+		{IssueType: types.TypeEvent, Title: "State change: health → healthy", CreatedAt: base.Add(10 * time.Minute)},
+	}
+	now := base.Add(2 * time.Hour)
+
+	timeline := stateTimeline(events, "health", "healthy", base, now)
+	want := []struct {
+		value     string
+		isDefault bool
+		duration  time.Duration
+	}{
+		{"healthy", true, 30 * time.Minute},
+		{"degraded", false, 30 * time.Minute},
+		{"healthy", false, 30 * time.Minute},
+		{"degraded", false, 30 * time.Minute},
+	}
+	if len(timeline) != len(want) {
+		t.Fatalf("stateTimeline() returned %d intervals, want %d: %+v", len(timeline), len(want), timeline)
+	}
+	for i, w := range want {
+		got := timeline[i]
+		if got.Value != w.value || got.Default != w.isDefault || got.elapsed != w.duration {
+			t.Errorf("interval %d = %s default=%v %s, want %s default=%v %s", i, got.Value, got.Default, got.elapsed, w.value, w.isDefault, w.duration)
+		}
+	}
+	if !timeline[3].End.IsZero() {
+		t.Error("last interval should still be current")
+	}
+
+	clipped := clipStateTimeline(timeline, now.Add(-45*time.Minute), now)
+	totals := stateTotals(clipped)
+	if len(clipped) != 2 || totals["healthy"] != 15*time.Minute || totals["degraded"] != 30*time.Minute {
+		t.Errorf("clipped timeline = %+v, totals %v", clipped, totals)
+	}
+
+	if got := stateTimeline(events, "mode", "", base, now); got != nil {
+		t.Errorf("timeline for an unused dimension = %+v, want nil", got)
+	}
+
+	// A value held before the first recorded change is its old value
+	timeline = stateTimeline(events[:1], "health", "healthy", base, now)
+	if len(timeline) != 2 || timeline[0].Value != "healthy" || timeline[0].Default {
+		t.Errorf("timeline from one change = %+v, want healthy (set) then degraded", timeline)
+	}
+}
+
+func TestParseStateFilters(t *testing.T) {
+	filters, err := parseStateFilters([]string{"health=degraded", " mode = normal "})
+	if err != nil {
+		t.Fatalf("parseStateFilters failed: %v", err)
+	}
+	want := []types.StateFilter{{Dimension: "health", Value: "degraded"}, {Dimension: "mode", Value: "normal"}}
+	if !slices.Equal(filters, want) {
+		t.Errorf("parseStateFilters() = %+v, want %+v", filters, want)
+	}
+	for _, bad := range []string{"health", "=x", "health="} {
+		if _, err := parseStateFilters([]string{bad}); err == nil {
+			t.Errorf("parseStateFilters(%q) should fail", bad)
+		}
+	}
+}
+
+func TestExpireStates(t *testing.T) {
+	testStore := newTestStore(t, filepath.Join(t.TempDir(), ".beads", "beads.db"))
+	ctx := context.Background()
+
+	agent := &types.Issue{Title: "Witness", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	healthy := &types.Issue{Title: "Refinery", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	for _, issue := range []*types.Issue{agent, healthy} {
+		if err := testStore.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("CreateIssue failed: %v", err)
+		}
+	}
+	if _, err := setStateDirect(ctx, testStore, agent.ID, "health", "", "degraded", "probe failed", "tester"); err != nil {
+		t.Fatalf("setStateDirect failed: %v", err)
+	}
+	if _, err := setStateDirect(ctx, testStore, healthy.ID, "health", "", "healthy", "", "tester"); err != nil {
+		t.Fatalf("setStateDirect failed: %v", err)
+	}
+
+	schemas := map[string]*config.StateSchema{
+		"health": {Dimension: "health", Values: []string{"healthy", "degraded"}, Default: "healthy", TTL: time.Hour},
+		"mode":   {Dimension: "mode", Default: "normal"},
+	}
+	if expired, err := findExpiredStates(ctx, testStore, schemas, time.Now().Add(30*time.Minute)); err != nil || len(expired) != 0 {
+		t.Fatalf("findExpiredStates before TTL = %+v, %v; want none", expired, err)
+	}
+	expired, err := findExpiredStates(ctx, testStore, schemas, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("findExpiredStates failed: %v", err)
+	}
+	if len(expired) != 1 || expired[0].IssueID != agent.ID || expired[0].Value != "degraded" {
+		t.Fatalf("findExpiredStates() = %+v, want degraded on %s", expired, agent.ID)
+	}
+
+	eventID, err := expireState(ctx, testStore, expired[0], schemas["health"], "daemon")
+	if err != nil {
+		t.Fatalf("expireState failed: %v", err)
+	}
+	labels, _ := testStore.GetLabels(ctx, agent.ID)
+	if !slices.Equal(labels, []string{"health:healthy"}) {
+		t.Errorf("labels after expiry = %v, want [health:healthy]", labels)
+	}
+	event, _ := testStore.GetIssue(ctx, eventID)
+	if event == nil || event.Title != "State change: health → healthy" {
+		t.Fatalf("expiry event = %+v", event)
+	}
+	if c, ok := stateChangeOf(event); !ok || event.Target != agent.ID || c.OldValue != "degraded" || c.NewValue != "healthy" {
+		t.Errorf("expiry event kind=%q target=%q payload=%q, want degraded → healthy on %s", event.EventKind, event.Target, event.Payload, agent.ID)
+	}
+
+	// The revert shows up in the timeline as a regular change
+	eventType := types.TypeEvent
+	events, err := testStore.SearchIssues(ctx, "", types.IssueFilter{ParentID: &agent.ID, IssueType: &eventType})
+	if err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+	timeline := stateTimeline(events, "health", "healthy", agent.CreatedAt, time.Now())
+	if n := len(timeline); n != 3 || timeline[1].Value != "degraded" || timeline[n-1].Value != "healthy" || timeline[n-1].Actor != "daemon" {
+		t.Errorf("timeline after expiry = %+v", timeline)
+	}
+}
@@ -637,46 +637,9 @@ Examples:
 			}
 		}
 
-		// Resolve ID
-		issueID, err := utils.ResolvePartialID(ctx, store, args[0])
+		epic, err := resolveSwarmEpic(ctx, store, args[0])
 		if err != nil {
-			FatalErrorRespectJSON("issue '%s' not found: %v", args[0], err)
-		}
-
-		// Get the issue
-		issue, err := store.GetIssue(ctx, issueID)
-		if err != nil {
-			FatalErrorRespectJSON("failed to get issue: %v", err)
-		}
-		if issue == nil {
-			FatalErrorRespectJSON("issue '%s' not found", issueID)
-		}
-
-		var epic *types.Issue
-
-		// Check if it's a swarm molecule - if so, follow the link to the epic
-		if issue.IssueType == types.TypeMolecule && issue.MolType == types.MolTypeSwarm {
-			// Find linked epic via relates-to dependency
-			deps, err := store.GetDependencyRecords(ctx, issue.ID)
-			if err != nil {
-				FatalErrorRespectJSON("failed to get swarm dependencies: %v", err)
-			}
-			for _, dep := range deps {
-				if dep.Type == types.DepRelatesTo {
-					epic, err = store.GetIssue(ctx, dep.DependsOnID)
-					if err != nil {
-						FatalErrorRespectJSON("failed to get linked epic: %v", err)
-					}
-					break
-				}
-			}
-			if epic == nil {
-				FatalErrorRespectJSON("swarm molecule '%s' has no linked epic", issueID)
-			}
-		} else if issue.IssueType == types.TypeEpic || issue.IssueType == types.TypeMolecule {
-			epic = issue
-		} else {
-			FatalErrorRespectJSON("'%s' is not an epic or swarm molecule (type: %s)", issueID, issue.IssueType)
+			FatalErrorRespectJSON("%v", err)
 		}
 
 		// Get swarm status
@@ -0,0 +1,600 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/sqlite"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/utils"
+)
+
+// DispatchPolicy controls how the swarm dispatcher hands out work.
+type DispatchPolicy struct {
+	MaxPerAgent int           // Issues an agent may hold at once (overridden by a concurrency:N label)
+	Timeout     time.Duration // Agents silent for longer lose their work (0 = never)
+	Role        string        // Only dispatch to agents with this role_type ("" = any)
+	Labels      []string      // Only dispatch to agents carrying all of these labels
+}
+
+// DispatchAgent is an agent bead as seen by the dispatcher.
+type DispatchAgent struct {
+	ID           string
+	RoleType     string
+	State        types.AgentState
+	Labels       []string
+	HookBead     string
+	LastActivity *time.Time
+	Load         int // in_progress issues currently assigned to the agent
+}
+
+// DispatchAction is one change the dispatcher makes (or, in a dry run, would make).
+type DispatchAction struct {
+	Action  string `json:"action"` // "assign" or "reclaim"
+	IssueID string `json:"issue_id"`
+	Agent   string `json:"agent"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// DispatchPlan is the outcome of one dispatcher pass over a swarm.
+type DispatchPlan struct {
+	EpicID     string           `json:"epic_id"`
+	Actions    []DispatchAction `json:"actions"`
+	Waiting    []string         `json:"waiting"` // Ready issues no agent could take
+	Remaining  int              `json:"remaining"`
+	Complete   bool             `json:"complete"`
+	AgentCount int              `json:"agent_count"`
+}
+
+var swarmRunCmd = &cobra.Command{
+	Use:   "run [epic-or-swarm-id]",
+	Short: "Dispatch ready swarm work to idle agents",
+	Long: `Watch a swarm's ready front and assign issues to agents.
+
+Each pass computes the swarm status (like 'bd swarm status') and:
+- Reclaims in_progress issues whose agent is dead or has not reported
+  activity within --timeout, returning them to the ready set
+- Assigns ready issues to available agent beads (labeled gt:agent)
+
+An agent is available when its state is idle (or unset), it is not timed
+out, and it holds fewer issues than its limit. The limit is --max-per-agent
+unless the agent carries a concurrency:N label.
+An issue labeled role_type:<role> or rig:<rig> only goes to agents with the
+same role_type and rig. The least-loaded available agent is preferred.
+
+Assigning sets the issue's assignee and status (in_progress) and hooks the
+issue on the agent if its hook is empty. Every assignment and reclaim is
+recorded as a comment event on the issue.
+
+With --agent-cmd, a shell command is started for each assignment with
+BD_AGENT, BD_ISSUE and BD_EPIC set. 'bd swarm stub-agent' is a local stand-in
+agent that completes its hooked work, for testing a swarm end to end.
+
+The dispatcher runs until every issue in the swarm is closed. Use --once for
+a single pass.
+
+Examples:
+  bd swarm run gt-epic-123 --once --dry-run          # Preview assignments
+  bd swarm run gt-swarm-456 --role polecat --timeout 15m
+  bd swarm run gt-epic-123 --agent-cmd 'bd swarm stub-agent "$BD_AGENT"'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := rootCtx
+		once, _ := cmd.Flags().GetBool("once")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		agentCmd, _ := cmd.Flags().GetString("agent-cmd")
+		policy := DispatchPolicy{}
+		policy.MaxPerAgent, _ = cmd.Flags().GetInt("max-per-agent")
+		policy.Timeout, _ = cmd.Flags().GetDuration("timeout")
+		policy.Role, _ = cmd.Flags().GetString("role")
+		policy.Labels, _ = cmd.Flags().GetStringSlice("agent-label")
+
+		if !dryRun {
+			CheckReadonly("swarm run")
+		}
+		if policy.MaxPerAgent < 1 {
+			FatalErrorRespectJSON("--max-per-agent must be at least 1")
+		}
+		if interval <= 0 {
+			FatalErrorRespectJSON("--interval must be positive")
+		}
+
+		// Swarm commands require direct store access
+		if store == nil {
+			if daemonClient != nil {
+				var err error
+				store, err = sqlite.New(ctx, dbPath)
+				if err != nil {
+					FatalErrorRespectJSON("failed to open database: %v", err)
+				}
+				defer func() { _ = store.Close() }()
+			} else {
+				FatalErrorRespectJSON("no database connection")
+			}
+		}
+
+		epic, err := resolveSwarmEpic(ctx, store, args[0])
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+
+		var agents sync.WaitGroup
+		defer agents.Wait()
+
+		lastWaiting := ""
+		for {
+			plan, err := runDispatchPass(ctx, store, epic, policy, dryRun)
+			if err != nil {
+				FatalErrorRespectJSON("dispatch failed: %v", err)
+			}
+			if jsonOutput {
+				outputJSON(plan)
+			} else {
+				// Only repeat the waiting list when it changes
+				waiting := strings.Join(plan.Waiting, ", ")
+				renderDispatchPlan(plan, dryRun, waiting != lastWaiting)
+				lastWaiting = waiting
+			}
+
+			if agentCmd != "" && !dryRun {
+				for _, a := range plan.Actions {
+					if a.Action == "assign" {
+						startDispatchedAgent(ctx, &agents, agentCmd, epic.ID, a)
+					}
+				}
+			}
+
+			if once || dryRun || plan.Complete {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	},
+}
+
+var swarmStubAgentCmd = &cobra.Command{
+	Use:    "stub-agent <agent>",
+	Hidden: true,
+	Short:  "Stand-in agent that completes its hooked work (for testing)",
+	Long: `Act as an agent once: report a heartbeat, close every in_progress issue
+assigned to the agent, clear its hook and set its state to idle.
+
+Intended as 'bd swarm run --agent-cmd' target for exercising a swarm end to
+end without real workers.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("swarm stub-agent")
+		ctx := rootCtx
+		work, _ := cmd.Flags().GetDuration("work")
+
+		if store == nil {
+			if daemonClient != nil {
+				var err error
+				store, err = sqlite.New(ctx, dbPath)
+				if err != nil {
+					FatalErrorRespectJSON("failed to open database: %v", err)
+				}
+				defer func() { _ = store.Close() }()
+			} else {
+				FatalErrorRespectJSON("no database connection")
+			}
+		}
+
+		agentID, err := utils.ResolvePartialID(ctx, store, args[0])
+		if err != nil {
+			FatalErrorRespectJSON("agent '%s' not found: %v", args[0], err)
+		}
+		labels, _ := store.GetLabels(ctx, agentID)
+		if !isAgentBead(labels) {
+			FatalErrorRespectJSON("%s is not an agent bead (missing gt:agent label)", agentID)
+		}
+
+		if err := store.UpdateIssue(ctx, agentID, map[string]interface{}{
+			"agent_state":   string(types.StateWorking),
+			"last_activity": time.Now(),
+		}, agentID); err != nil {
+			FatalErrorRespectJSON("failed to update agent: %v", err)
+		}
+
+		assignee := agentID
+		inProgress := types.StatusInProgress
+		issues, err := store.SearchIssues(ctx, "", types.IssueFilter{Assignee: &assignee, Status: &inProgress})
+		if err != nil {
+			FatalErrorRespectJSON("failed to list agent work: %v", err)
+		}
+		if work > 0 {
+			time.Sleep(work)
+		}
+
+		var closed []string
+		for _, issue := range issues {
+			if err := store.CloseIssue(ctx, issue.ID, "Completed by stub agent", agentID, ""); err != nil {
+				FatalErrorRespectJSON("failed to close %s: %v", issue.ID, err)
+			}
+			closed = append(closed, issue.ID)
+		}
+
+		if err := store.UpdateIssue(ctx, agentID, map[string]interface{}{
+			"hook_bead":     "",
+			"agent_state":   string(types.StateIdle),
+			"last_activity": time.Now(),
+		}, agentID); err != nil {
+			FatalErrorRespectJSON("failed to update agent: %v", err)
+		}
+		markDirtyAndScheduleFlush()
+
+		if jsonOutput {
+			if closed == nil {
+				closed = []string{}
+			}
+			outputJSON(map[string]interface{}{"agent": agentID, "closed": closed})
+			return
+		}
+		fmt.Printf("%s %s completed %d issue(s)\n", ui.RenderPass("✓"), agentID, len(closed))
+	},
+}
+
+// resolveSwarmEpic resolves an epic ID, or a swarm molecule ID via its
+// relates-to link, to the epic whose children make up the swarm.
+func resolveSwarmEpic(ctx context.Context, s storage.Storage, id string) (*types.Issue, error) {
+	issueID, err := utils.ResolvePartialID(ctx, s, id)
+	if err != nil {
+		return nil, fmt.Errorf("issue '%s' not found: %w", id, err)
+	}
+	issue, err := s.GetIssue(ctx, issueID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+	if issue == nil {
+		return nil, fmt.Errorf("issue '%s' not found", issueID)
+	}
+
+	switch {
+	case issue.IssueType == types.TypeMolecule && issue.MolType == types.MolTypeSwarm:
+		deps, err := s.GetDependencyRecords(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get swarm dependencies: %w", err)
+		}
+		for _, dep := range deps {
+			if dep.Type == types.DepRelatesTo {
+				epic, err := s.GetIssue(ctx, dep.DependsOnID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get linked epic: %w", err)
+				}
+				if epic != nil {
+					return epic, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("swarm molecule '%s' has no linked epic", issueID)
+	case issue.IssueType == types.TypeEpic || issue.IssueType == types.TypeMolecule:
+		return issue, nil
+	default:
+		return nil, fmt.Errorf("'%s' is not an epic or swarm molecule (type: %s)", issueID, issue.IssueType)
+	}
+}
+
+// runDispatchPass plans one dispatcher pass and, unless dryRun, applies it.
+func runDispatchPass(ctx context.Context, s storage.Storage, epic *types.Issue, policy DispatchPolicy, dryRun bool) (*DispatchPlan, error) {
+	status, err := getSwarmStatus(ctx, s, epic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swarm status: %w", err)
+	}
+	agents, err := loadDispatchAgents(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	issueLabels := make(map[string][]string)
+	for _, group := range [][]StatusIssue{status.Active, status.Ready} {
+		for _, si := range group {
+			labels, err := s.GetLabels(ctx, si.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get labels for %s: %w", si.ID, err)
+			}
+			issueLabels[si.ID] = labels
+		}
+	}
+
+	plan := planDispatch(status, issueLabels, agents, policy, time.Now())
+
+	// Assignments are claims, so budget.enforcement applies. Refused issues
+	// wait for a later pass like any other issue no agent could take.
+	actions := make([]DispatchAction, 0, len(plan.Actions))
+	for _, a := range plan.Actions {
+		if a.Action == "assign" {
+			if err := checkStoreClaimBudget(ctx, s, a.IssueID); err != nil {
+				fmt.Fprintf(os.Stderr, "%s Not dispatching %s: %v\n", ui.RenderWarn("!"), a.IssueID, err)
+				plan.Waiting = append(plan.Waiting, a.IssueID)
+				continue
+			}
+		}
+		actions = append(actions, a)
+	}
+	plan.Actions = actions
+
+	if dryRun {
+		return plan, nil
+	}
+
+	hooks := make(map[string]string, len(agents))
+	for _, a := range agents {
+		hooks[a.ID] = a.HookBead
+	}
+	for _, a := range plan.Actions {
+		if err := applyDispatchAction(ctx, s, a, hooks); err != nil {
+			return nil, err
+		}
+	}
+	if len(plan.Actions) > 0 {
+		markDirtyAndScheduleFlush()
+	}
+	return plan, nil
+}
+
+// loadDispatchAgents returns every live agent bead with its current load.
+func loadDispatchAgents(ctx context.Context, s storage.Storage) ([]*DispatchAgent, error) {
+	issues, err := listAgentBeads(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	var agents []*DispatchAgent
+	for _, issue := range issues {
+		if issue.Status == types.StatusClosed {
+			continue
+		}
+		labels, err := s.GetLabels(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get labels for %s: %w", issue.ID, err)
+		}
+		assignee := issue.ID
+		inProgress := types.StatusInProgress
+		held, err := s.SearchIssues(ctx, "", types.IssueFilter{Assignee: &assignee, Status: &inProgress})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count work for %s: %w", issue.ID, err)
+		}
+		agents = append(agents, &DispatchAgent{
+			ID:           issue.ID,
+			RoleType:     issue.RoleType,
+			State:        issue.AgentState,
+			Labels:       labels,
+			HookBead:     issue.HookBead,
+			LastActivity: issue.LastActivity,
+			Load:         len(held),
+		})
+	}
+	return agents, nil
+}
+
+// planDispatch decides which active issues to reclaim and which ready issues
+// go to which agents. It does not touch storage.
+func planDispatch(status *SwarmStatus, issueLabels map[string][]string, agents []*DispatchAgent, policy DispatchPolicy, now time.Time) *DispatchPlan {
+	plan := &DispatchPlan{
+		EpicID:     status.EpicID,
+		Actions:    []DispatchAction{},
+		Waiting:    []string{},
+		Remaining:  status.TotalIssues - len(status.Completed),
+		Complete:   status.TotalIssues > 0 && len(status.Completed) == status.TotalIssues,
+		AgentCount: len(agents),
+	}
+
+	byID := make(map[string]*DispatchAgent, len(agents))
+	load := make(map[string]int, len(agents))
+	for _, a := range agents {
+		byID[a.ID] = a
+		load[a.ID] = a.Load
+	}
+
+	// Reclaimed issues go first: they belong to earlier waves
+	var queue []string
+	for _, si := range status.Active {
+		a := byID[si.Assignee]
+		if a == nil {
+			continue
+		}
+		reason := ""
+		switch {
+		case a.State == types.StateDead:
+			reason = "agent is dead"
+		case agentTimedOut(a, policy.Timeout, now):
+			reason = fmt.Sprintf("no activity for %s", now.Sub(*a.LastActivity).Round(time.Second))
+		default:
+			continue
+		}
+		plan.Actions = append(plan.Actions, DispatchAction{Action: "reclaim", IssueID: si.ID, Agent: a.ID, Reason: reason})
+		load[a.ID]--
+		queue = append(queue, si.ID)
+	}
+	for _, si := range status.Ready {
+		queue = append(queue, si.ID)
+	}
+
+	for _, issueID := range queue {
+		var best *DispatchAgent
+		for _, a := range agents {
+			if !agentAvailable(a, policy, now) || load[a.ID] >= agentLimit(a, policy) {
+				continue
+			}
+			if !agentMatchesIssue(a, issueLabels[issueID]) {
+				continue
+			}
+			if best == nil || preferAgent(a, best, load) {
+				best = a
+			}
+		}
+		if best == nil {
+			plan.Waiting = append(plan.Waiting, issueID)
+			continue
+		}
+		load[best.ID]++
+		plan.Actions = append(plan.Actions, DispatchAction{Action: "assign", IssueID: issueID, Agent: best.ID})
+	}
+	return plan
+}
+
+// agentTimedOut reports whether an agent has been silent for longer than
+// timeout. Agents that never reported activity are not considered timed out.
+func agentTimedOut(a *DispatchAgent, timeout time.Duration, now time.Time) bool {
+	return timeout > 0 && a.LastActivity != nil && now.Sub(*a.LastActivity) > timeout
+}
+
+// agentAvailable reports whether an agent may receive work under policy.
+func agentAvailable(a *DispatchAgent, policy DispatchPolicy, now time.Time) bool {
+	// Busy, finished-but-unreported and unhealthy agents get nothing new
+	if a.State != "" && a.State != types.StateIdle {
+		return false
+	}
+	if agentTimedOut(a, policy.Timeout, now) {
+		return false
+	}
+	if policy.Role != "" && a.RoleType != policy.Role {
+		return false
+	}
+	for _, l := range policy.Labels {
+		if !containsLabel(a.Labels, l) {
+			return false
+		}
+	}
+	return true
+}
+
+// agentLimit returns how many issues an agent may hold at once.
+func agentLimit(a *DispatchAgent, policy DispatchPolicy) int {
+	for _, l := range a.Labels {
+		if v, ok := strings.CutPrefix(l, "concurrency:"); ok {
+			var n int
+			if _, err := fmt.Sscanf(v, "%d", &n); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return policy.MaxPerAgent
+}
+
+// agentMatchesIssue checks an issue's role_type:/rig: labels against the agent.
+func agentMatchesIssue(a *DispatchAgent, labels []string) bool {
+	for _, l := range labels {
+		if role, ok := strings.CutPrefix(l, "role_type:"); ok && role != a.RoleType && !containsLabel(a.Labels, l) {
+			return false
+		}
+		if strings.HasPrefix(l, "rig:") && !containsLabel(a.Labels, l) {
+			return false
+		}
+	}
+	return true
+}
+
+// preferAgent orders candidates, all idle or without a state (see
+// agentAvailable): least loaded first, then by ID.
+func preferAgent(a, b *DispatchAgent, load map[string]int) bool {
+	if load[a.ID] != load[b.ID] {
+		return load[a.ID] < load[b.ID]
+	}
+	return a.ID < b.ID
+}
+
+// applyDispatchAction writes one planned action to storage. hooks tracks each
+// agent's hook_bead across the pass.
+func applyDispatchAction(ctx context.Context, s storage.Storage, a DispatchAction, hooks map[string]string) error {
+	switch a.Action {
+	case "reclaim":
+		if err := s.UpdateIssue(ctx, a.IssueID, map[string]interface{}{
+			"status":   string(types.StatusOpen),
+			"assignee": "",
+		}, actor); err != nil {
+			return fmt.Errorf("failed to reclaim %s: %w", a.IssueID, err)
+		}
+		if hooks[a.Agent] == a.IssueID {
+			if err := s.UpdateIssue(ctx, a.Agent, map[string]interface{}{"hook_bead": ""}, actor); err != nil {
+				return fmt.Errorf("failed to unhook %s: %w", a.Agent, err)
+			}
+			hooks[a.Agent] = ""
+		}
+		return s.AddComment(ctx, a.IssueID, actor, fmt.Sprintf("swarm run: reclaimed from %s (%s)", a.Agent, a.Reason))
+	case "assign":
+		if err := s.UpdateIssue(ctx, a.IssueID, map[string]interface{}{
+			"status":   string(types.StatusInProgress),
+			"assignee": a.Agent,
+		}, actor); err != nil {
+			return fmt.Errorf("failed to assign %s: %w", a.IssueID, err)
+		}
+		if hooks[a.Agent] == "" {
+			if err := s.UpdateIssue(ctx, a.Agent, map[string]interface{}{"hook_bead": a.IssueID}, actor); err != nil {
+				return fmt.Errorf("failed to hook %s on %s: %w", a.IssueID, a.Agent, err)
+			}
+			hooks[a.Agent] = a.IssueID
+		}
+		return s.AddComment(ctx, a.IssueID, actor, fmt.Sprintf("swarm run: dispatched to %s", a.Agent))
+	default:
+		return fmt.Errorf("unknown dispatch action %q", a.Action)
+	}
+}
+
+// startDispatchedAgent runs --agent-cmd for an assignment in the background.
+func startDispatchedAgent(ctx context.Context, wg *sync.WaitGroup, command, epicID string, a DispatchAction) {
+	c := exec.CommandContext(ctx, "sh", "-c", command) // #nosec G204 - command is supplied by the user running bd
+	c.Env = append(os.Environ(), "BD_AGENT="+a.Agent, "BD_ISSUE="+a.IssueID, "BD_EPIC="+epicID)
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	if err := c.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to start agent command for %s: %v\n", a.Agent, err)
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := c.Wait(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: agent command for %s (%s) failed: %v\n", a.Agent, a.IssueID, err)
+		}
+	}()
+}
+
+// renderDispatchPlan outputs one dispatcher pass in human-readable form.
+func renderDispatchPlan(plan *DispatchPlan, dryRun, showWaiting bool) {
+	prefix := ""
+	if dryRun {
+		prefix = "(dry run) "
+	}
+	for _, a := range plan.Actions {
+		switch a.Action {
+		case "reclaim":
+			fmt.Printf("%s%s Reclaimed %s from %s (%s)\n", prefix, ui.RenderWarn("↺"), ui.RenderID(a.IssueID), a.Agent, a.Reason)
+		case "assign":
+			fmt.Printf("%s%s Dispatched %s to %s\n", prefix, ui.RenderPass("→"), ui.RenderID(a.IssueID), a.Agent)
+		}
+	}
+	if showWaiting && len(plan.Waiting) > 0 {
+		fmt.Printf("%sWaiting for an available agent: %s\n", prefix, strings.Join(plan.Waiting, ", "))
+	}
+	if plan.Complete {
+		fmt.Printf("%s Swarm %s complete\n", ui.RenderPass("✓"), plan.EpicID)
+	}
+}
+
+func init() {
+	swarmRunCmd.Flags().Bool("once", false, "Run a single dispatch pass and exit")
+	swarmRunCmd.Flags().Bool("dry-run", false, "Show what would be dispatched without changing anything")
+	swarmRunCmd.Flags().Duration("interval", 10*time.Second, "Time between dispatch passes")
+	swarmRunCmd.Flags().Duration("timeout", 30*time.Minute, "Reclaim work from agents with no activity for this long (0 disables)")
+	swarmRunCmd.Flags().Int("max-per-agent", 1, "Issues an agent may hold at once (a concurrency:N label overrides)")
+	swarmRunCmd.Flags().String("role", "", "Only dispatch to agents with this role_type")
+	swarmRunCmd.Flags().StringSlice("agent-label", nil, "Only dispatch to agents with this label (repeatable)")
+	swarmRunCmd.Flags().String("agent-cmd", "", "Shell command started per assignment (BD_AGENT, BD_ISSUE, BD_EPIC set)")
+	swarmStubAgentCmd.Flags().Duration("work", 0, "Simulated time spent on the work before closing it")
+
+	swarmCmd.AddCommand(swarmRunCmd)
+	swarmCmd.AddCommand(swarmStubAgentCmd)
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/audit"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func dispatchStatus(active, ready []StatusIssue) *SwarmStatus {
+	return &SwarmStatus{
+		EpicID:      "bd-epic",
+		TotalIssues: len(active) + len(ready),
+		Active:      active,
+		Ready:       ready,
+	}
+}
+
+func assignments(plan *DispatchPlan) map[string]string {
+	got := make(map[string]string)
+	for _, a := range plan.Actions {
+		if a.Action == "assign" {
+			got[a.IssueID] = a.Agent
+		}
+	}
+	return got
+}
+
+func TestPlanDispatch_AssignsReadyToIdleAgents(t *testing.T) {
+	status := dispatchStatus(nil, []StatusIssue{{ID: "bd-1"}, {ID: "bd-2"}, {ID: "bd-3"}})
+	agents := []*DispatchAgent{
+		{ID: "gt-a", State: types.StateIdle},
+		{ID: "gt-b", State: types.StateIdle},
+		{ID: "gt-c", State: types.StateStuck},
+		{ID: "gt-d", State: types.StateWorking},
+		{ID: "gt-e", State: types.StateDone},
+	}
+
+	plan := planDispatch(status, nil, agents, DispatchPolicy{MaxPerAgent: 1}, time.Now())
+
+	got := assignments(plan)
+	if got["bd-1"] != "gt-a" || got["bd-2"] != "gt-b" {
+		t.Errorf("assignments = %v, want bd-1→gt-a, bd-2→gt-b", got)
+	}
+	if len(plan.Waiting) != 1 || plan.Waiting[0] != "bd-3" {
+		t.Errorf("waiting = %v, want [bd-3]", plan.Waiting)
+	}
+}
+
+func TestPlanDispatch_ConcurrencyLimits(t *testing.T) {
+	status := dispatchStatus(nil, []StatusIssue{{ID: "bd-1"}, {ID: "bd-2"}, {ID: "bd-3"}, {ID: "bd-4"}})
+	agents := []*DispatchAgent{
+		{ID: "gt-a", State: types.StateIdle, Load: 1, Labels: []string{"concurrency:3"}},
+		{ID: "gt-b", State: types.StateIdle, Load: 1},
+	}
+
+	plan := planDispatch(status, nil, agents, DispatchPolicy{MaxPerAgent: 2}, time.Now())
+
+	perAgent := make(map[string]int)
+	for _, agent := range assignments(plan) {
+		perAgent[agent]++
+	}
+	if perAgent["gt-a"] != 2 || perAgent["gt-b"] != 1 {
+		t.Errorf("per-agent assignments = %v, want gt-a:2 gt-b:1", perAgent)
+	}
+	if len(plan.Waiting) != 1 {
+		t.Errorf("waiting = %v, want one issue", plan.Waiting)
+	}
+}
+
+func TestPlanDispatch_MatchesRoleAndRig(t *testing.T) {
+	status := dispatchStatus(nil, []StatusIssue{{ID: "bd-1"}, {ID: "bd-2"}})
+	labels := map[string][]string{
+		"bd-1": {"role_type:refinery"},
+		"bd-2": {"rig:beads"},
+	}
+	agents := []*DispatchAgent{
+		{ID: "gt-a", State: types.StateIdle, RoleType: "polecat", Labels: []string{"rig:beads"}},
+		{ID: "gt-b", State: types.StateIdle, RoleType: "refinery", Labels: []string{"rig:gastown"}},
+	}
+
+	plan := planDispatch(status, labels, agents, DispatchPolicy{MaxPerAgent: 1}, time.Now())
+
+	got := assignments(plan)
+	if got["bd-1"] != "gt-b" || got["bd-2"] != "gt-a" {
+		t.Errorf("assignments = %v, want bd-1→gt-b, bd-2→gt-a", got)
+	}
+
+	plan = planDispatch(status, labels, agents, DispatchPolicy{MaxPerAgent: 1, Role: "polecat"}, time.Now())
+	got = assignments(plan)
+	if len(got) != 1 || got["bd-2"] != "gt-a" {
+		t.Errorf("with --role polecat: assignments = %v, want only bd-2→gt-a", got)
+	}
+}
+
+func TestPlanDispatch_ReclaimsFromTimedOutAgents(t *testing.T) {
+	now := time.Now()
+	stale := now.Add(-time.Hour)
+	fresh := now.Add(-time.Minute)
+	status := dispatchStatus(
+		[]StatusIssue{{ID: "bd-1", Assignee: "gt-a"}, {ID: "bd-2", Assignee: "gt-b"}, {ID: "bd-3", Assignee: "gt-c"}},
+		nil,
+	)
+	agents := []*DispatchAgent{
+		{ID: "gt-a", State: types.StateWorking, LastActivity: &stale, Load: 1},
+		{ID: "gt-b", State: types.StateWorking, LastActivity: &fresh, Load: 1},
+		{ID: "gt-c", State: types.StateDead, Load: 1},
+		{ID: "gt-d", State: types.StateIdle, LastActivity: &fresh},
+	}
+
+	plan := planDispatch(status, nil, agents, DispatchPolicy{MaxPerAgent: 1, Timeout: 30 * time.Minute}, now)
+
+	reclaimed := make(map[string]string)
+	for _, a := range plan.Actions {
+		if a.Action == "reclaim" {
+			reclaimed[a.IssueID] = a.Agent
+		}
+	}
+	if len(reclaimed) != 2 || reclaimed["bd-1"] != "gt-a" || reclaimed["bd-3"] != "gt-c" {
+		t.Errorf("reclaimed = %v, want bd-1 from gt-a and bd-3 from gt-c", reclaimed)
+	}
+	got := assignments(plan)
+	if got["bd-1"] != "gt-d" {
+		t.Errorf("assignments = %v, want bd-1→gt-d", got)
+	}
+	if len(plan.Waiting) != 1 || plan.Waiting[0] != "bd-3" {
+		t.Errorf("waiting = %v, want [bd-3]", plan.Waiting)
+	}
+}
+
+func TestPlanDispatch_Complete(t *testing.T) {
+	status := &SwarmStatus{EpicID: "bd-epic", TotalIssues: 2, Completed: []StatusIssue{{ID: "bd-1"}, {ID: "bd-2"}}}
+	plan := planDispatch(status, nil, nil, DispatchPolicy{MaxPerAgent: 1}, time.Now())
+	if !plan.Complete || plan.Remaining != 0 || len(plan.Actions) != 0 {
+		t.Errorf("plan = %+v, want complete with no actions", plan)
+	}
+}
+
+func TestSwarmRun_DispatchWithStubAgent(t *testing.T) {
+	testStore := newTestStore(t, filepath.Join(t.TempDir(), ".beads", "beads.db"))
+	ctx := context.Background()
+	oldStore, oldActor, oldCtx := store, actor, rootCtx
+	store, actor, rootCtx = testStore, "dispatcher", ctx
+	defer func() { store, actor, rootCtx = oldStore, oldActor, oldCtx }()
+
+	create := func(issue *types.Issue, labels ...string) *types.Issue {
+		t.Helper()
+		if err := testStore.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("CreateIssue failed: %v", err)
+		}
+		for _, l := range labels {
+			if err := testStore.AddLabel(ctx, issue.ID, l, "tester"); err != nil {
+				t.Fatalf("AddLabel failed: %v", err)
+			}
+		}
+		return issue
+	}
+	depend := func(from, to string, depType types.DependencyType) {
+		t.Helper()
+		if err := testStore.AddDependency(ctx, &types.Dependency{IssueID: from, DependsOnID: to, Type: depType}, "tester"); err != nil {
+			t.Fatalf("AddDependency failed: %v", err)
+		}
+	}
+
+	epic := create(&types.Issue{Title: "Epic", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeEpic})
+	first := create(&types.Issue{Title: "First", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeTask})
+	second := create(&types.Issue{Title: "Second", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeTask})
+	depend(first.ID, epic.ID, types.DepParentChild)
+	depend(second.ID, epic.ID, types.DepParentChild)
+	depend(second.ID, first.ID, types.DepBlocks)
+
+	newAgent := func(title string, state types.AgentState) *types.Issue {
+		t.Helper()
+		a := create(&types.Issue{Title: title, Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}, "gt:agent")
+		if err := testStore.UpdateIssue(ctx, a.ID, map[string]interface{}{"agent_state": string(state)}, "tester"); err != nil {
+			t.Fatalf("UpdateIssue failed: %v", err)
+		}
+		return a
+	}
+	agent := newAgent("Worker", types.StateIdle)
+	newAgent("Busy", types.StateWorking)
+	newAgent("Finished", types.StateDone)
+
+	policy := DispatchPolicy{MaxPerAgent: 1, Timeout: 30 * time.Minute}
+	for _, want := range []*types.Issue{first, second} {
+		plan, err := runDispatchPass(ctx, testStore, epic, policy, false)
+		if err != nil {
+			t.Fatalf("runDispatchPass failed: %v", err)
+		}
+		if got := assignments(plan); len(got) != 1 || got[want.ID] != agent.ID {
+			t.Fatalf("assignments = %v, want %s→%s", got, want.ID, agent.ID)
+		}
+
+		issue, _ := testStore.GetIssue(ctx, want.ID)
+		if issue.Status != types.StatusInProgress || issue.Assignee != agent.ID {
+			t.Errorf("%s: status=%s assignee=%q, want in_progress assigned to %s", want.ID, issue.Status, issue.Assignee, agent.ID)
+		}
+		worker, _ := testStore.GetIssue(ctx, agent.ID)
+		if worker.HookBead != want.ID {
+			t.Errorf("agent hook_bead = %q, want %s", worker.HookBead, want.ID)
+		}
+
+		// The stub agent closes its hooked work and goes back to idle
+		swarmStubAgentCmd.Run(swarmStubAgentCmd, []string{agent.ID})
+
+		issue, _ = testStore.GetIssue(ctx, want.ID)
+		if issue.Status != types.StatusClosed {
+			t.Errorf("%s: status=%s after stub agent, want closed", want.ID, issue.Status)
+		}
+		worker, _ = testStore.GetIssue(ctx, agent.ID)
+		if worker.HookBead != "" || worker.AgentState != types.StateIdle {
+			t.Errorf("agent after stub: hook_bead=%q state=%q, want empty and idle", worker.HookBead, worker.AgentState)
+		}
+	}
+
+	plan, err := runDispatchPass(ctx, testStore, epic, policy, false)
+	if err != nil {
+		t.Fatalf("runDispatchPass failed: %v", err)
+	}
+	if !plan.Complete || len(plan.Actions) != 0 {
+		t.Errorf("final plan = %+v, want complete with no actions", plan)
+	}
+
+	// Each assignment leaves a comment event on the issue
+	events, err := testStore.GetEvents(ctx, first.ID, 0)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	dispatched := false
+	for _, e := range events {
+		if e.EventType == types.EventCommented && e.Comment != nil && *e.Comment == "swarm run: dispatched to "+agent.ID {
+			dispatched = true
+		}
+	}
+	if !dispatched {
+		t.Errorf("no dispatch comment event on %s", first.ID)
+	}
+}
+
+func TestSwarmRun_BudgetBlocksDispatch(t *testing.T) {
+	beadsDir := filepath.Join(t.TempDir(), ".beads")
+	t.Setenv("BEADS_DIR", beadsDir)
+	testStore := newTestStore(t, filepath.Join(beadsDir, "beads.db"))
+	ctx := context.Background()
+	oldStore, oldActor, oldCtx := store, actor, rootCtx
+	store, actor, rootCtx = testStore, "dispatcher", ctx
+	defer func() { store, actor, rootCtx = oldStore, oldActor, oldCtx }()
+	setBudgetEnforcement(t, "block")
+
+	budget := 1.0
+	epic := &types.Issue{Title: "Epic", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeEpic, BudgetUSD: &budget}
+	work := &types.Issue{Title: "Work", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeTask}
+	worker := &types.Issue{Title: "Worker", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	for _, issue := range []*types.Issue{epic, work, worker} {
+		if err := testStore.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("CreateIssue failed: %v", err)
+		}
+	}
+	if err := testStore.AddDependency(ctx, &types.Dependency{IssueID: work.ID, DependsOnID: epic.ID, Type: types.DepParentChild}, "tester"); err != nil {
+		t.Fatalf("AddDependency failed: %v", err)
+	}
+	if err := testStore.AddLabel(ctx, worker.ID, "gt:agent", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if err := testStore.UpdateIssue(ctx, worker.ID, map[string]interface{}{"agent_state": string(types.StateIdle)}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if _, err := audit.Append(&audit.Entry{Kind: audit.KindLLMCall, IssueID: epic.ID, CostUSD: 2.5}); err != nil {
+		t.Fatalf("audit.Append failed: %v", err)
+	}
+
+	policy := DispatchPolicy{MaxPerAgent: 1, Timeout: 30 * time.Minute}
+	plan, err := runDispatchPass(ctx, testStore, epic, policy, false)
+	if err != nil {
+		t.Fatalf("runDispatchPass failed: %v", err)
+	}
+	if got := assignments(plan); len(got) != 0 {
+		t.Errorf("assignments = %v, want none over budget", got)
+	}
+	if len(plan.Waiting) != 1 || plan.Waiting[0] != work.ID {
+		t.Errorf("waiting = %v, want [%s]", plan.Waiting, work.ID)
+	}
+	issue, _ := testStore.GetIssue(ctx, work.ID)
+	if issue.Status != types.StatusOpen || issue.Assignee != "" {
+		t.Errorf("%s: status=%s assignee=%q, want open and unassigned", work.ID, issue.Status, issue.Assignee)
+	}
+}
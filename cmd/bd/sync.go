@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -33,7 +32,10 @@ Use --squash to accumulate changes without committing (reduces commit noise).
 Use --flush-only to just export pending changes to JSONL (useful for pre-commit hooks).
 Use --import-only to just import from JSONL (useful after git pull).
 Use --status to show diff between sync branch and main branch.
-Use --merge to merge the sync branch back to main branch.`,
+Use --merge to merge the sync branch back to main branch.
+
+In --git-refs mode, sync exchanges refs/beads/issues/* with origin instead
+(see 'bd refs sync').`,
 	Run: func(cmd *cobra.Command, _ []string) {
 		CheckReadonly("sync")
 		ctx := rootCtx
@@ -70,6 +72,12 @@ Use --merge to merge the sync branch back to main branch.`,
 			daemonClient = nil
 		}
 
+		// In --git-refs mode issues live in refs, not in a JSONL file
+		if gitRefsMode {
+			runGitRefsSync(ctx, "origin")
+			return
+		}
+
 		// Resolve noGitHistory based on fromMain (fixes #417)
 		noGitHistory = resolveNoGitHistoryForFromMain(fromMain, noGitHistory)
 
@@ -153,7 +161,7 @@ Use --merge to merge the sync branch back to main branch.`,
 		}
 
 		// Check if we're in a git repository
-		if !isGitRepo() {
+		if !isVCSRepo() {
 			FatalErrorWithHint("not in a git repository", "run 'git init' to initialize a repository")
 		}
 
@@ -358,9 +366,10 @@ Use --merge to merge the sync branch back to main branch.`,
 
 			// Check if there are changes to commit
 			relBeadsDir, _ := filepath.Rel(externalRepoRoot, beadsDir)
-			statusCmd := exec.CommandContext(ctx, "git", "-C", externalRepoRoot, "status", "--porcelain", relBeadsDir)
-			statusOutput, _ := statusCmd.Output()
-			externalHasChanges := len(strings.TrimSpace(string(statusOutput))) > 0
+			externalHasChanges := false
+			if v, err := openSyncVCS(externalRepoRoot); err == nil {
+				externalHasChanges, _ = v.HasChanges(relBeadsDir)
+			}
 
 			if externalHasChanges {
 				if dryRun {
@@ -729,8 +738,7 @@ Use --merge to merge the sync branch back to main branch.`,
 							}
 
 							// Mark conflict as resolved
-							addCmd := exec.CommandContext(ctx, "git", "add", jsonlPath)
-							if addErr := addCmd.Run(); addErr != nil {
+							if addErr := markResolved(jsonlPath); addErr != nil {
 								FatalErrorWithHint(fmt.Sprintf("failed to mark conflict resolved: %v", addErr), "resolve conflicts manually and run 'bd import' then 'bd sync' again")
 							}
 
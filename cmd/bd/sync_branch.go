@@ -3,23 +3,29 @@ package main
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/steveyegge/beads/internal/syncbranch"
+	"github.com/steveyegge/beads/internal/vcs"
 )
 
-// getCurrentBranch returns the name of the current git branch
-// Uses symbolic-ref instead of rev-parse to work in fresh repos without commits (bd-flil)
+// getCurrentBranch returns the name of the current git branch (jj bookmark)
+// git uses symbolic-ref instead of rev-parse to work in fresh repos without commits (bd-flil)
 func getCurrentBranch(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--short", "HEAD")
-	output, err := cmd.Output()
+	v, err := openSyncVCS(".")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	branch, err := v.CurrentRef()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if branch == "" {
+		return "", fmt.Errorf("failed to get current branch: not on a branch")
+	}
+	return branch, nil
 }
 
 // getSyncBranch returns the configured sync branch name
@@ -43,7 +49,8 @@ func getSyncBranch(ctx context.Context) (string, error) {
 
 // showSyncStatus shows the diff between sync branch and main branch
 func showSyncStatus(ctx context.Context) error {
-	if !isGitRepo() {
+	v, err := openSyncVCS(".")
+	if err != nil {
 		return fmt.Errorf("not in a git repository")
 	}
 
@@ -58,8 +65,7 @@ func showSyncStatus(ctx context.Context) error {
 	}
 
 	// Check if sync branch exists
-	checkCmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+syncBranch) //nolint:gosec // syncBranch from config
-	if err := checkCmd.Run(); err != nil {
+	if !v.RefExists(syncBranch) {
 		return fmt.Errorf("sync branch '%s' does not exist", syncBranch)
 	}
 
@@ -68,54 +74,50 @@ func showSyncStatus(ctx context.Context) error {
 
 	// Show commit diff
 	fmt.Println("Commits in sync branch not in main:")
-	logCmd := exec.CommandContext(ctx, "git", "log", "--oneline", currentBranch+".."+syncBranch) //nolint:gosec // branch names from git
-	logOutput, err := logCmd.CombinedOutput()
+	commits, err := v.Log(currentBranch, syncBranch)
 	if err != nil {
-		return fmt.Errorf("failed to get commit log: %w\n%s", err, logOutput)
-	}
-
-	if len(strings.TrimSpace(string(logOutput))) == 0 {
-		fmt.Println("  (none)")
-	} else {
-		fmt.Print(string(logOutput))
+		return fmt.Errorf("failed to get commit log: %w", err)
 	}
+	printCommitLog(commits)
 
 	fmt.Println("\nCommits in main not in sync branch:")
-	logCmd = exec.CommandContext(ctx, "git", "log", "--oneline", syncBranch+".."+currentBranch) //nolint:gosec // branch names from git
-	logOutput, err = logCmd.CombinedOutput()
+	commits, err = v.Log(syncBranch, currentBranch)
 	if err != nil {
-		return fmt.Errorf("failed to get commit log: %w\n%s", err, logOutput)
-	}
-
-	if len(strings.TrimSpace(string(logOutput))) == 0 {
-		fmt.Println("  (none)")
-	} else {
-		fmt.Print(string(logOutput))
+		return fmt.Errorf("failed to get commit log: %w", err)
 	}
+	printCommitLog(commits)
 
 	// Show file diff for .beads/issues.jsonl
 	fmt.Println("\nFile differences in .beads/issues.jsonl:")
-	diffCmd := exec.CommandContext(ctx, "git", "diff", currentBranch+"..."+syncBranch, "--", ".beads/issues.jsonl") //nolint:gosec // branch names from git
-	diffOutput, err := diffCmd.CombinedOutput()
+	diff, err := v.Diff(currentBranch, syncBranch, ".beads/issues.jsonl")
 	if err != nil {
-		// diff returns non-zero when there are differences, which is fine
-		if len(diffOutput) == 0 {
-			return fmt.Errorf("failed to get diff: %w", err)
-		}
+		return fmt.Errorf("failed to get diff: %w", err)
 	}
 
-	if len(strings.TrimSpace(string(diffOutput))) == 0 {
+	if len(strings.TrimSpace(diff)) == 0 {
 		fmt.Println("  (no differences)")
 	} else {
-		fmt.Print(string(diffOutput))
+		fmt.Print(diff)
 	}
 
 	return nil
 }
 
+// printCommitLog prints one-line commit summaries, or "(none)"
+func printCommitLog(commits []string) {
+	if len(commits) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	for _, commit := range commits {
+		fmt.Println(commit)
+	}
+}
+
 // mergeSyncBranch merges the sync branch back to the main branch
 func mergeSyncBranch(ctx context.Context, dryRun bool) error {
-	if !isGitRepo() {
+	v, err := openSyncVCS(".")
+	if err != nil {
 		return fmt.Errorf("not in a git repository")
 	}
 
@@ -130,18 +132,16 @@ func mergeSyncBranch(ctx context.Context, dryRun bool) error {
 	}
 
 	// Check if sync branch exists
-	checkCmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+syncBranch) //nolint:gosec // syncBranch from config
-	if err := checkCmd.Run(); err != nil {
+	if !v.RefExists(syncBranch) {
 		return fmt.Errorf("sync branch '%s' does not exist", syncBranch)
 	}
 
 	// Check if there are uncommitted changes
-	statusCmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
-	statusOutput, err := statusCmd.Output()
+	hasChanges, err := v.HasChanges()
 	if err != nil {
 		return fmt.Errorf("failed to check git status: %w", err)
 	}
-	if len(strings.TrimSpace(string(statusOutput))) > 0 {
+	if hasChanges {
 		return fmt.Errorf("uncommitted changes detected - commit or stash them first")
 	}
 
@@ -150,11 +150,10 @@ func mergeSyncBranch(ctx context.Context, dryRun bool) error {
 	if dryRun {
 		fmt.Println("→ [DRY RUN] Would merge sync branch")
 		// Show what would be merged
-		logCmd := exec.CommandContext(ctx, "git", "log", "--oneline", currentBranch+".."+syncBranch) //nolint:gosec // branch names from git
-		logOutput, _ := logCmd.CombinedOutput()
-		if len(strings.TrimSpace(string(logOutput))) > 0 {
+		commits, _ := v.Log(currentBranch, syncBranch)
+		if len(commits) > 0 {
 			fmt.Println("\nCommits that would be merged:")
-			fmt.Print(string(logOutput))
+			printCommitLog(commits)
 		} else {
 			fmt.Println("No commits to merge")
 		}
@@ -162,13 +161,10 @@ func mergeSyncBranch(ctx context.Context, dryRun bool) error {
 	}
 
 	// Perform the merge
-	mergeCmd := exec.CommandContext(ctx, "git", "merge", syncBranch, "-m", fmt.Sprintf("Merge sync branch '%s'", syncBranch)) //nolint:gosec // syncBranch from config
-	mergeOutput, err := mergeCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("merge failed: %w\n%s", err, mergeOutput)
+	if err := v.Merge(ctx, syncBranch, fmt.Sprintf("Merge sync branch '%s'", syncBranch)); err != nil {
+		return fmt.Errorf("merge failed: %w", err)
 	}
 
-	fmt.Print(string(mergeOutput))
 	fmt.Println("\n✓ Merge complete")
 
 	// Suggest next steps
@@ -208,24 +204,18 @@ func isExternalBeadsDir(ctx context.Context, beadsDir string) bool {
 // getGitCommonDir returns the shared git directory for a path.
 // For regular repos, this is the .git directory.
 // For worktrees, this returns the shared git directory (common to all worktrees).
+// For jj repos, this is the .jj directory holding the repo store.
 // This is the correct way to determine if two paths are in the same git repo,
 // especially for bare repos and worktrees.
 // GH#810: Added to fix bare repo worktree detection.
 func getGitCommonDir(ctx context.Context, path string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "-C", path, "rev-parse", "--git-common-dir")
-	output, err := cmd.Output()
+	v, err := openSyncVCS(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to get git common dir for %s: %w", path, err)
 	}
-	result := strings.TrimSpace(string(output))
-	// Make absolute for reliable comparison
-	if !filepath.IsAbs(result) {
-		// Resolve the input path to absolute first
-		absPath, err := filepath.Abs(path)
-		if err != nil {
-			return "", fmt.Errorf("failed to get absolute path for %s: %w", path, err)
-		}
-		result = filepath.Join(absPath, result)
+	result, err := v.CommonDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git common dir for %s: %w", path, err)
 	}
 	result = filepath.Clean(result)
 	// Resolve symlinks for consistent comparison (macOS /var -> /private/var)
@@ -240,12 +230,11 @@ func getGitCommonDir(ctx context.Context, path string) (string, error) {
 // for any path.
 // Contributed by dand-oss (https://github.com/steveyegge/beads/pull/533)
 func getRepoRootFromPath(ctx context.Context, path string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "-C", path, "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
+	v, err := openSyncVCS(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to get git root for %s: %w", path, err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return v.RepoRoot()
 }
 
 // commitToExternalBeadsRepo commits changes directly to an external beads repo.
@@ -264,34 +253,36 @@ func commitToExternalBeadsRepo(ctx context.Context, beadsDir, message string, pu
 		relBeadsDir = beadsDir // Fallback to absolute path
 	}
 
-	addCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "add", relBeadsDir) //nolint:gosec // paths from trusted sources
-	if output, err := addCmd.CombinedOutput(); err != nil {
-		return false, fmt.Errorf("git add failed: %w\n%s", err, output)
+	v, err := openSyncVCS(repoRoot)
+	if err != nil {
+		return false, err
 	}
 
-	// Check if there are staged changes
-	diffCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "diff", "--cached", "--quiet") //nolint:gosec // repoRoot from git
-	if diffCmd.Run() == nil {
+	// Check if there are changes to commit
+	hasChanges, err := v.HasChanges(relBeadsDir)
+	if err != nil {
+		return false, err
+	}
+	if !hasChanges {
 		return false, nil // No changes to commit
 	}
 
 	// Commit with config-based author and signing options
-	// Use pathspec to commit ONLY beads files (bd-trgb fix)
+	// Commit ONLY beads files (bd-trgb fix)
 	// This prevents accidentally committing other staged files
 	if message == "" {
 		message = fmt.Sprintf("bd sync: %s", time.Now().Format("2006-01-02 15:04:05"))
 	}
-	commitArgs := buildGitCommitArgs(repoRoot, message, "--", relBeadsDir)
-	commitCmd := exec.CommandContext(ctx, "git", commitArgs...) //nolint:gosec // args from buildGitCommitArgs
-	if output, err := commitCmd.CombinedOutput(); err != nil {
-		return false, fmt.Errorf("git commit failed: %w\n%s", err, output)
+	if err := v.Commit(ctx, syncCommitOptions(message, relBeadsDir)); err != nil {
+		return false, fmt.Errorf("commit failed: %w", err)
 	}
 
 	// Push if requested
 	if push {
-		pushCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "push") //nolint:gosec // repoRoot from git
-		if pushOutput, err := runGitCmdWithTimeoutMsg(ctx, pushCmd, "git push", 5*time.Second); err != nil {
-			return true, fmt.Errorf("git push failed: %w\n%s", err, pushOutput)
+		if err := runWithTimeoutMsg(ctx, "push", 5*time.Second, func() error {
+			return v.Push(ctx, vcs.PushOptions{})
+		}); err != nil {
+			return true, fmt.Errorf("push failed: %w", err)
 		}
 	}
 
@@ -307,16 +298,14 @@ func pullFromExternalBeadsRepo(ctx context.Context, beadsDir string) error {
 		return fmt.Errorf("failed to get repo root: %w", err)
 	}
 
-	// Check if remote exists
-	remoteCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "remote") //nolint:gosec // repoRoot from git
-	remoteOutput, err := remoteCmd.Output()
-	if err != nil || len(strings.TrimSpace(string(remoteOutput))) == 0 {
-		return nil // No remote, skip pull
+	v, err := openSyncVCS(repoRoot)
+	if err != nil {
+		return err
 	}
 
-	pullCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "pull") //nolint:gosec // repoRoot from git
-	if output, err := pullCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git pull failed: %w\n%s", err, output)
+	// Pull is a no-op without a remote
+	if err := v.Pull(ctx, vcs.PullOptions{}); err != nil {
+		return fmt.Errorf("pull failed: %w", err)
 	}
 
 	return nil
@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -128,21 +127,23 @@ func checkForcedPush(ctx context.Context) *ForcedPushCheck {
 		return result
 	}
 
+	v, err := openSyncVCS(".")
+	if err != nil {
+		return result
+	}
+
 	// Check if sync branch exists locally
-	checkLocalCmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+syncBranch) //nolint:gosec // syncBranch from config
-	if checkLocalCmd.Run() != nil {
+	if !v.RefExists(syncBranch) {
 		result.Message = fmt.Sprintf("Sync branch '%s' does not exist locally", syncBranch)
 		return result
 	}
 
 	// Get local ref
-	localRefCmd := exec.CommandContext(ctx, "git", "rev-parse", syncBranch) //nolint:gosec // syncBranch from config
-	localRefOutput, err := localRefCmd.Output()
+	localRef, err := v.GetCommitHash(syncBranch)
 	if err != nil {
 		result.Message = "Failed to get local sync branch ref"
 		return result
 	}
-	localRef := strings.TrimSpace(string(localRefOutput))
 	result.LocalRef = localRef
 
 	// Check if remote tracking branch exists
@@ -152,13 +153,12 @@ func checkForcedPush(ctx context.Context) *ForcedPushCheck {
 	}
 
 	// Get remote ref
-	remoteRefCmd := exec.CommandContext(ctx, "git", "rev-parse", remote+"/"+syncBranch) //nolint:gosec // remote and syncBranch from config
-	remoteRefOutput, err := remoteRefCmd.Output()
+	remoteName := v.RemoteRef(remote, syncBranch)
+	remoteRef, err := v.GetCommitHash(remoteName)
 	if err != nil {
-		result.Message = fmt.Sprintf("Remote tracking branch '%s/%s' does not exist", remote, syncBranch)
+		result.Message = fmt.Sprintf("Remote tracking branch '%s' does not exist", remoteName)
 		return result
 	}
-	remoteRef := strings.TrimSpace(string(remoteRefOutput))
 	result.RemoteRef = remoteRef
 
 	// If refs match, no divergence
@@ -167,27 +167,39 @@ func checkForcedPush(ctx context.Context) *ForcedPushCheck {
 		return result
 	}
 
+	divergence, err := v.HasDivergence(localRef, remoteRef)
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to compare sync branch with remote: %v", err)
+		return result
+	}
+
 	// Check if local is ahead of remote (normal case)
-	aheadCmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", remoteRef, localRef) //nolint:gosec // refs from git rev-parse
-	if aheadCmd.Run() == nil {
+	if divergence.RemoteAhead == 0 {
 		result.Message = "Local sync branch is ahead of remote (normal)"
 		return result
 	}
 
 	// Check if remote is ahead of local (behind, needs pull)
-	behindCmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", localRef, remoteRef) //nolint:gosec // refs from git rev-parse
-	if behindCmd.Run() == nil {
+	if divergence.LocalAhead == 0 {
 		result.Message = "Local sync branch is behind remote (needs pull)"
 		return result
 	}
 
 	// If neither is ancestor, branches have diverged - likely a force push
 	result.Detected = true
-	result.Message = fmt.Sprintf("Sync branch has DIVERGED from remote! Local: %s, Remote: %s. This may indicate a force push on the remote.", localRef[:8], remoteRef[:8])
+	result.Message = fmt.Sprintf("Sync branch has DIVERGED from remote! Local: %s, Remote: %s. This may indicate a force push on the remote.", shortRef(localRef), shortRef(remoteRef))
 
 	return result
 }
 
+// shortRef abbreviates a commit hash for display
+func shortRef(ref string) string {
+	if len(ref) > 8 {
+		return ref[:8]
+	}
+	return ref
+}
+
 func printForcedPushResult(fp *ForcedPushCheck) {
 	fmt.Println("1. Force Push Detection")
 	if fp.Detected {
@@ -346,25 +358,25 @@ func checkOrphanedChildrenInJSONL(jsonlPath string) (*OrphanedChildren, error) {
 	return result, nil
 }
 
-// runGitCmdWithTimeoutMsg runs a git command and prints a helpful message if it takes too long.
+// runWithTimeoutMsg runs a VCS operation and prints a helpful message if it takes too long.
 // This helps when git operations hang waiting for credential/browser auth.
-func runGitCmdWithTimeoutMsg(ctx context.Context, cmd *exec.Cmd, cmdName string, timeoutDelay time.Duration) ([]byte, error) {
-	// Use done channel to cleanly exit goroutine when command completes
+func runWithTimeoutMsg(ctx context.Context, cmdName string, timeoutDelay time.Duration, fn func() error) error {
+	// Use done channel to cleanly exit goroutine when the operation completes
 	done := make(chan struct{})
 	go func() {
 		select {
 		case <-time.After(timeoutDelay):
 			fmt.Fprintf(os.Stderr, "⏳ %s is taking longer than expected (possibly waiting for authentication). If this hangs, check for a browser auth prompt or run 'git status' in another terminal.\n", cmdName)
 		case <-done:
-			// Command completed, exit cleanly
+			// Operation completed, exit cleanly
 		case <-ctx.Done():
 			// Context canceled, don't print message
 		}
 	}()
 
-	output, err := cmd.CombinedOutput()
+	err := fn()
 	close(done)
-	return output, err
+	return err
 }
 
 func printOrphanedChildrenResult(oc *OrphanedChildren) {
@@ -199,6 +199,15 @@ func exportToJSONLDeferred(ctx context.Context, jsonlPath string) (*ExportResult
 		issue.Comments = comments
 	}
 
+	// Populate attachment references for all issues
+	allAttachments, err := store.GetAllAttachments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	for _, issue := range issues {
+		issue.Attachments = allAttachments[issue.ID]
+	}
+
 	// Create temp file for atomic write
 	dir := filepath.Dir(jsonlPath)
 	base := filepath.Base(jsonlPath)
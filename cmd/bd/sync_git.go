@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -12,69 +11,82 @@ import (
 	"github.com/steveyegge/beads/internal/beads"
 	"github.com/steveyegge/beads/internal/config"
 	"github.com/steveyegge/beads/internal/git"
+	"github.com/steveyegge/beads/internal/syncbranch"
+	"github.com/steveyegge/beads/internal/vcs"
 )
 
 // isGitRepo checks if the current directory is in a git repository
+// (colocated jj repos included)
 func isGitRepo() bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	return cmd.Run() == nil
+	v, err := vcs.NewFactory(vcs.WithCache(false), vcs.WithPreferredType(vcs.TypeGit)).Create(".")
+	return err == nil && v.Name() == vcs.TypeGit
+}
+
+// isVCSRepo checks if the current directory is in a git or jj repository
+func isVCSRepo() bool {
+	if isGitRepo() {
+		return true
+	}
+	_, err := openSyncVCS(".")
+	return err == nil
+}
+
+// openSyncVCS returns the VCS bd sync uses for the repository containing
+// path. Colocated git+jj repos sync through git unless BD_VCS says otherwise.
+func openSyncVCS(path string) (vcs.VCS, error) {
+	return syncbranch.OpenVCS(path)
 }
 
 // gitHasUnmergedPaths checks for unmerged paths or merge in progress
 func gitHasUnmergedPaths() (bool, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
-	out, err := cmd.Output()
+	v, err := openSyncVCS(".")
 	if err != nil {
-		return false, fmt.Errorf("git status failed: %w", err)
+		return false, err
 	}
 
 	// Check for unmerged status codes (DD, AU, UD, UA, DU, AA, UU)
-	for _, line := range strings.Split(string(out), "\n") {
-		if len(line) >= 2 {
-			s := line[:2]
-			if s == "DD" || s == "AU" || s == "UD" || s == "UA" || s == "DU" || s == "AA" || s == "UU" {
-				return true, nil
-			}
-		}
+	if unmerged, err := v.HasUnmergedPaths(); err != nil || unmerged {
+		return unmerged, err
 	}
 
 	// Check if MERGE_HEAD exists (merge in progress)
-	if exec.Command("git", "rev-parse", "-q", "--verify", "MERGE_HEAD").Run() == nil {
-		return true, nil
+	if v.Name() == vcs.TypeGit {
+		if _, err := v.GetCommitHash("MERGE_HEAD"); err == nil {
+			return true, nil
+		}
 	}
 
 	return false, nil
 }
 
 // gitHasUpstream checks if the current branch has an upstream configured
-// Uses git config directly for compatibility with Git for Windows
 func gitHasUpstream() bool {
-	// Get current branch name
-	branchCmd := exec.Command("git", "symbolic-ref", "--short", "HEAD")
-	branchOutput, err := branchCmd.Output()
+	v, err := openSyncVCS(".")
 	if err != nil {
 		return false
 	}
-	branch := strings.TrimSpace(string(branchOutput))
-
-	// Check if remote and merge refs are configured
-	remoteCmd := exec.Command("git", "config", "--get", fmt.Sprintf("branch.%s.remote", branch)) //nolint:gosec // G204: branch from git symbolic-ref
-	mergeCmd := exec.Command("git", "config", "--get", fmt.Sprintf("branch.%s.merge", branch))   //nolint:gosec // G204: branch from git symbolic-ref
 
-	remoteErr := remoteCmd.Run()
-	mergeErr := mergeCmd.Run()
+	// Get current branch name
+	branch, err := v.CurrentRef()
+	if err != nil || branch == "" {
+		return false
+	}
 
-	return remoteErr == nil && mergeErr == nil
+	remote, err := v.Upstream(branch)
+	return err == nil && remote != ""
 }
 
 // gitHasChanges checks if the specified file has uncommitted changes
 func gitHasChanges(ctx context.Context, filePath string) (bool, error) {
-	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", filePath)
-	output, err := cmd.Output()
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return false, err
+	}
+	v, err := openSyncVCS(filepath.Dir(absPath))
 	if err != nil {
-		return false, fmt.Errorf("git status failed: %w", err)
+		return false, fmt.Errorf("status failed: %w", err)
 	}
-	return len(strings.TrimSpace(string(output))) > 0, nil
+	return v.HasChanges(absPath)
 }
 
 // getRepoRootForWorktree returns the main repository root for running git commands
@@ -99,57 +111,42 @@ func gitHasBeadsChanges(ctx context.Context) (bool, error) {
 		return false, fmt.Errorf("no .beads directory found")
 	}
 
-	// Check if beads directory is redirected (bd-arjb)
-	// When redirected, beadsDir points outside the current repo, so we need to
-	// run git commands from the directory containing the actual .beads/
-	redirectInfo := beads.GetRedirectInfo()
-	if redirectInfo.IsRedirected {
-		// beadsDir is the target (e.g., /path/to/mayor/rig/.beads)
-		// We need to run git from the parent of .beads (e.g., /path/to/mayor/rig)
-		targetRepoDir := filepath.Dir(beadsDir)
-		statusCmd := exec.CommandContext(ctx, "git", "-C", targetRepoDir, "status", "--porcelain", beadsDir) //nolint:gosec // G204: beadsDir from beads.FindBeadsDir()
-		statusOutput, err := statusCmd.Output()
-		if err != nil {
-			return false, fmt.Errorf("git status failed: %w", err)
-		}
-		return len(strings.TrimSpace(string(statusOutput))) > 0, nil
-	}
-
-	// Run git status with absolute path from current directory.
-	// This is more robust than using -C with a repo root, because:
-	// 1. In bare repo worktree setups, GetMainRepoRoot() returns the parent
-	//    of the bare repo, which isn't a valid working tree (GH#827)
-	// 2. Git will find the repository from cwd, which is always valid
-	statusCmd := exec.CommandContext(ctx, "git", "status", "--porcelain", beadsDir) //nolint:gosec // G204: beadsDir from beads.FindBeadsDir()
-	statusOutput, err := statusCmd.Output()
+	v, err := openBeadsVCS(beadsDir)
 	if err != nil {
-		return false, fmt.Errorf("git status failed: %w", err)
+		return false, fmt.Errorf("status failed: %w", err)
 	}
-	return len(strings.TrimSpace(string(statusOutput))) > 0, nil
+	return v.HasChanges(beadsDir)
 }
 
-// buildGitCommitArgs returns git commit args with config-based author and signing options (GH#600)
-// This allows users to configure a separate author and disable GPG signing for beads commits.
-func buildGitCommitArgs(repoRoot, message string, extraArgs ...string) []string {
-	args := []string{"-C", repoRoot, "commit"}
-
-	// Add --author if configured
-	if author := config.GetString("git.author"); author != "" {
-		args = append(args, "--author", author)
+// openBeadsVCS opens the VCS that tracks beadsDir.
+//
+// Normally this is the repository found from the current directory. This is
+// more robust than using a repo root, because in bare repo worktree setups
+// GetMainRepoRoot() returns the parent of the bare repo, which isn't a valid
+// working tree (GH#827).
+//
+// When the beads directory is redirected (bd-arjb), beadsDir points outside
+// the current repo, so we use the repo containing the actual .beads/.
+func openBeadsVCS(beadsDir string) (vcs.VCS, error) {
+	if beads.GetRedirectInfo().IsRedirected {
+		// beadsDir is the target (e.g., /path/to/mayor/rig/.beads)
+		// We need the repo at the parent of .beads (e.g., /path/to/mayor/rig)
+		return openSyncVCS(filepath.Dir(beadsDir))
 	}
+	return openSyncVCS(".")
+}
 
-	// Add --no-gpg-sign if configured
-	if config.GetBool("git.no-gpg-sign") {
-		args = append(args, "--no-gpg-sign")
+// syncCommitOptions returns commit options with config-based author and signing options (GH#600)
+// This allows users to configure a separate author and disable GPG signing for beads commits.
+// Only paths are committed, so other staged files are left alone.
+func syncCommitOptions(message string, paths ...string) vcs.CommitOptions {
+	return vcs.CommitOptions{
+		Message:   message,
+		Paths:     paths,
+		Author:    config.GetString("git.author"),
+		NoGPGSign: config.GetBool("git.no-gpg-sign"),
+		CreateNew: true,
 	}
-
-	// Add message
-	args = append(args, "-m", message)
-
-	// Add any extra args (like -- pathspec)
-	args = append(args, extraArgs...)
-
-	return args
 }
 
 // gitCommit commits the specified file (worktree-aware)
@@ -160,31 +157,26 @@ func gitCommit(ctx context.Context, filePath string, message string) error {
 		return fmt.Errorf("cannot determine repository root")
 	}
 
+	v, err := openSyncVCS(repoRoot)
+	if err != nil {
+		return err
+	}
+
 	// Make file path relative to repo root for git operations
 	relPath, err := filepath.Rel(repoRoot, filePath)
 	if err != nil {
 		relPath = filePath // Fall back to absolute path
 	}
 
-	// Stage the file from repo root context
-	addCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "add", relPath) //nolint:gosec // G204: paths from internal git helpers
-	if err := addCmd.Run(); err != nil {
-		return fmt.Errorf("git add failed: %w", err)
-	}
-
 	// Generate message if not provided
 	if message == "" {
 		message = fmt.Sprintf("bd sync: %s", time.Now().Format("2006-01-02 15:04:05"))
 	}
 
-	// Commit from repo root context with config-based author and signing options
-	// Use pathspec to commit ONLY this file
+	// Commit ONLY this file, with config-based author and signing options.
 	// This prevents accidentally committing other staged files
-	commitArgs := buildGitCommitArgs(repoRoot, message, "--", relPath)
-	commitCmd := exec.CommandContext(ctx, "git", commitArgs...) //nolint:gosec // G204: args from buildGitCommitArgs
-	output, err := commitCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git commit failed: %w\n%s", err, output)
+	if err := v.Commit(ctx, syncCommitOptions(message, relPath)); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
 	}
 
 	return nil
@@ -207,6 +199,11 @@ func gitCommitBeadsDir(ctx context.Context, message string) error {
 		return fmt.Errorf("cannot determine repository root")
 	}
 
+	v, err := openSyncVCS(repoRoot)
+	if err != nil {
+		return err
+	}
+
 	// Stage only the specific sync-related files
 	// This avoids staging gitignored snapshot files (beads.*.jsonl, *.meta.json)
 	// that may still be tracked from before they were added to .gitignore
@@ -235,10 +232,8 @@ func gitCommitBeadsDir(ctx context.Context, message string) error {
 	}
 
 	// Stage only the sync files from repo root context (worktree-aware)
-	args := append([]string{"-C", repoRoot, "add"}, filesToAdd...)
-	addCmd := exec.CommandContext(ctx, "git", args...) //nolint:gosec // G204: paths from internal git helpers
-	if err := addCmd.Run(); err != nil {
-		return fmt.Errorf("git add failed: %w", err)
+	if err := v.Add(filesToAdd); err != nil {
+		return err
 	}
 
 	// Generate message if not provided
@@ -246,7 +241,7 @@ func gitCommitBeadsDir(ctx context.Context, message string) error {
 		message = fmt.Sprintf("bd sync: %s", time.Now().Format("2006-01-02 15:04:05"))
 	}
 
-	// Commit only .beads/ files using -- pathspec
+	// Commit only .beads/ files
 	// This prevents accidentally committing other staged files that the user
 	// may have staged but wasn't ready to commit yet.
 	// Convert beadsDir to relative path for git commit (worktree-aware)
@@ -255,12 +250,9 @@ func gitCommitBeadsDir(ctx context.Context, message string) error {
 		relBeadsDir = beadsDir // Fall back to absolute path if relative fails
 	}
 
-	// Use config-based author and signing options with pathspec
-	commitArgs := buildGitCommitArgs(repoRoot, message, "--", relBeadsDir)
-	commitCmd := exec.CommandContext(ctx, "git", commitArgs...) //nolint:gosec // G204: args from buildGitCommitArgs
-	output, err := commitCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git commit failed: %w\n%s", err, output)
+	// Use config-based author and signing options
+	if err := v.Commit(ctx, syncCommitOptions(message, relBeadsDir)); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
 	}
 
 	return nil
@@ -268,18 +260,23 @@ func gitCommitBeadsDir(ctx context.Context, message string) error {
 
 // hasGitRemote checks if a git remote exists in the repository
 func hasGitRemote(ctx context.Context) bool {
-	cmd := exec.CommandContext(ctx, "git", "remote")
-	output, err := cmd.Output()
+	v, err := openSyncVCS(".")
 	if err != nil {
 		return false
 	}
-	return len(strings.TrimSpace(string(output))) > 0
+	return v.HasRemote()
 }
 
 // isInRebase checks if we're currently in a git rebase state
 func isInRebase() bool {
+	v, err := openSyncVCS(".")
+	if err != nil || v.Name() != vcs.TypeGit {
+		// jj rebases never stop halfway
+		return false
+	}
+
 	// Get actual git directory (handles worktrees)
-	gitDir, err := git.GetGitDir()
+	gitDir, err := v.VCSDir()
 	if err != nil {
 		return false
 	}
@@ -300,8 +297,11 @@ func isInRebase() bool {
 // hasJSONLConflict checks if the beads JSONL file has a merge conflict
 // Returns true only if the JSONL file (issues.jsonl or beads.jsonl) is the only file in conflict
 func hasJSONLConflict() bool {
-	cmd := exec.Command("git", "status", "--porcelain")
-	out, err := cmd.Output()
+	v, err := openSyncVCS(".")
+	if err != nil {
+		return false
+	}
+	conflicted, err := v.GetConflictedFiles()
 	if err != nil {
 		return false
 	}
@@ -309,23 +309,12 @@ func hasJSONLConflict() bool {
 	var hasJSONLConflict bool
 	var hasOtherConflict bool
 
-	for _, line := range strings.Split(string(out), "\n") {
-		if len(line) < 3 {
-			continue
-		}
-
-		// Check for unmerged status codes (UU = both modified, AA = both added, etc.)
-		status := line[:2]
-		if status == "UU" || status == "AA" || status == "DD" ||
-			status == "AU" || status == "UA" || status == "DU" || status == "UD" {
-			filepath := strings.TrimSpace(line[3:])
-
-			// Check for beads JSONL files (issues.jsonl or beads.jsonl in .beads/)
-			if strings.HasSuffix(filepath, "issues.jsonl") || strings.HasSuffix(filepath, "beads.jsonl") {
-				hasJSONLConflict = true
-			} else {
-				hasOtherConflict = true
-			}
+	for _, path := range conflicted {
+		// Check for beads JSONL files (issues.jsonl or beads.jsonl in .beads/)
+		if strings.HasSuffix(path, "issues.jsonl") || strings.HasSuffix(path, "beads.jsonl") {
+			hasJSONLConflict = true
+		} else {
+			hasOtherConflict = true
 		}
 	}
 
@@ -333,12 +322,24 @@ func hasJSONLConflict() bool {
 	return hasJSONLConflict && !hasOtherConflict
 }
 
+// markResolved marks a conflicted file as resolved (git add; a no-op in jj,
+// which picks up the resolved content with the working copy)
+func markResolved(path string) error {
+	v, err := openSyncVCS(".")
+	if err != nil {
+		return err
+	}
+	return v.Add([]string{path})
+}
+
 // runGitRebaseContinue continues a rebase after resolving conflicts
 func runGitRebaseContinue(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, "git", "rebase", "--continue")
-	output, err := cmd.CombinedOutput()
+	v, err := openSyncVCS(".")
 	if err != nil {
-		return fmt.Errorf("git rebase --continue failed: %w\n%s", err, output)
+		return err
+	}
+	if _, err := v.Exec(ctx, "rebase", "--continue"); err != nil {
+		return fmt.Errorf("git rebase --continue failed: %w", err)
 	}
 	return nil
 }
@@ -348,41 +349,39 @@ func runGitRebaseContinue(ctx context.Context) error {
 // If configuredRemote is non-empty, uses that instead of the branch's configured remote.
 // This allows respecting the sync.remote bd config.
 func gitPull(ctx context.Context, configuredRemote string) error {
+	v, err := openSyncVCS(".")
+	if err != nil {
+		return err
+	}
+
 	// Check if any remote exists (support local-only repos)
-	if !hasGitRemote(ctx) {
+	if !v.HasRemote() {
 		return nil // Gracefully skip - local-only mode
 	}
 
 	// Get current branch name
-	// Use symbolic-ref to work in fresh repos without commits
-	branchCmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--short", "HEAD")
-	branchOutput, err := branchCmd.Output()
+	branch, err := v.CurrentRef()
 	if err != nil {
 		return fmt.Errorf("failed to get current branch: %w", err)
 	}
-	branch := strings.TrimSpace(string(branchOutput))
+	if branch == "" {
+		return fmt.Errorf("failed to get current branch: %w", vcs.ErrDetached)
+	}
 
 	// Determine remote to use:
 	// 1. If configuredRemote (from sync.remote bd config) is set, use that
-	// 2. Otherwise, get from git branch tracking config
+	// 2. Otherwise, get from the branch's tracking config
 	// 3. Fall back to "origin"
 	remote := configuredRemote
 	if remote == "" {
-		remoteCmd := exec.CommandContext(ctx, "git", "config", "--get", fmt.Sprintf("branch.%s.remote", branch)) //nolint:gosec // G204: branch from git symbolic-ref
-		remoteOutput, err := remoteCmd.Output()
-		if err != nil {
-			// If no remote configured, default to "origin"
+		if remote, _ = v.Upstream(branch); remote == "" {
 			remote = "origin"
-		} else {
-			remote = strings.TrimSpace(string(remoteOutput))
 		}
 	}
 
 	// Pull with explicit remote and branch
-	cmd := exec.CommandContext(ctx, "git", "pull", remote, branch) //nolint:gosec // G204: remote/branch from git config, not user input
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git pull failed: %w\n%s", err, output)
+	if err := v.Pull(ctx, vcs.PullOptions{Remote: remote, Ref: branch}); err != nil {
+		return fmt.Errorf("pull failed: %w", err)
 	}
 	return nil
 }
@@ -392,42 +391,32 @@ func gitPull(ctx context.Context, configuredRemote string) error {
 // If configuredRemote is non-empty, pushes to that remote explicitly.
 // This allows respecting the sync.remote bd config.
 func gitPush(ctx context.Context, configuredRemote string) error {
-	// Check if any remote exists (support local-only repos)
-	if !hasGitRemote(ctx) {
-		return nil // Gracefully skip - local-only mode
+	v, err := openSyncVCS(".")
+	if err != nil {
+		return err
 	}
 
-	// If configuredRemote is set, push explicitly to that remote with current branch
-	if configuredRemote != "" {
-		// Get current branch name
-		branchCmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--short", "HEAD")
-		branchOutput, err := branchCmd.Output()
-		if err != nil {
-			return fmt.Errorf("failed to get current branch: %w", err)
-		}
-		branch := strings.TrimSpace(string(branchOutput))
-
-		cmd := exec.CommandContext(ctx, "git", "push", configuredRemote, branch) //nolint:gosec // G204: configuredRemote from bd config
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("git push failed: %w\n%s", err, output)
-		}
-		return nil
+	// Check if any remote exists (support local-only repos)
+	if !v.HasRemote() {
+		return nil // Gracefully skip - local-only mode
 	}
 
-	// Default: use git's default push behavior
-	cmd := exec.CommandContext(ctx, "git", "push")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git push failed: %w\n%s", err, output)
+	// Push the current branch to configuredRemote, or to its upstream
+	if err := v.Push(ctx, vcs.PushOptions{Remote: configuredRemote}); err != nil {
+		return fmt.Errorf("push failed: %w", err)
 	}
 	return nil
 }
 
 func checkMergeDriverConfig() {
+	// The merge driver is a git feature
+	v, err := openSyncVCS(".")
+	if err != nil || v.Name() != vcs.TypeGit {
+		return
+	}
+
 	// Get current merge driver configuration
-	cmd := exec.Command("git", "config", "merge.beads.driver")
-	output, err := cmd.Output()
+	output, err := v.Exec(context.Background(), "config", "merge.beads.driver")
 	if err != nil {
 		// No merge driver configured - this is OK, user may not need it
 		return
@@ -455,12 +444,14 @@ func restoreBeadsDirFromBranch(ctx context.Context) error {
 		return fmt.Errorf("no .beads directory found")
 	}
 
-	// Restore .beads/ from HEAD (current branch's committed state)
-	// Using -- to ensure .beads/ is treated as a path, not a branch name
-	cmd := exec.CommandContext(ctx, "git", "checkout", "HEAD", "--", beadsDir) //nolint:gosec // G204: beadsDir from FindBeadsDir(), not user input
-	output, err := cmd.CombinedOutput()
+	v, err := openSyncVCS(".")
 	if err != nil {
-		return fmt.Errorf("git checkout failed: %w\n%s", err, output)
+		return err
+	}
+
+	// Restore .beads/ from HEAD (jj: the working copy's parent)
+	if err := v.Restore(ctx, beadsDir); err != nil {
+		return fmt.Errorf("restore failed: %w", err)
 	}
 	return nil
 }
@@ -478,28 +469,22 @@ func gitHasUncommittedBeadsChanges(ctx context.Context) (bool, error) {
 
 	jsonlPath := filepath.Join(beadsDir, "issues.jsonl")
 
-	// Check if beads directory is redirected (bd-arjb)
-	// When redirected, beadsDir points outside the current repo, so we need to
-	// run git commands from the directory containing the actual .beads/
-	redirectInfo := beads.GetRedirectInfo()
-	if redirectInfo.IsRedirected {
-		targetRepoDir := filepath.Dir(beadsDir)
-		cmd := exec.CommandContext(ctx, "git", "-C", targetRepoDir, "status", "--porcelain", jsonlPath) //nolint:gosec // G204: jsonlPath from internal beads.FindBeadsDir()
-		output, err := cmd.Output()
-		if err != nil {
-			return false, fmt.Errorf("git status failed: %w", err)
-		}
-		return parseGitStatusForBeadsChanges(string(output)), nil
+	v, err := openBeadsVCS(beadsDir)
+	if err != nil {
+		return false, fmt.Errorf("status failed: %w", err)
 	}
 
-	// Check git status for the JSONL file specifically
-	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", jsonlPath) //nolint:gosec // G204: jsonlPath from internal beads.FindBeadsDir()
-	output, err := cmd.Output()
+	// Check status for the JSONL file specifically
+	statuses, err := v.Status(jsonlPath)
 	if err != nil {
-		return false, fmt.Errorf("git status failed: %w", err)
+		return false, err
 	}
-
-	return parseGitStatusForBeadsChanges(string(output)), nil
+	for _, st := range statuses {
+		if parseGitStatusForBeadsChanges(string(st.StagedCode) + string(st.Status) + " " + st.Path) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // parseGitStatusForBeadsChanges parses git status --porcelain output and returns
@@ -530,18 +515,14 @@ func parseGitStatusForBeadsChanges(statusOutput string) bool {
 // after export, we restore the JSONL to its previous state so the working
 // directory stays consistent with the last successful sync.
 func rollbackJSONLFromGit(ctx context.Context, jsonlPath string) error {
-	// Check if the file is tracked by git
-	cmd := exec.CommandContext(ctx, "git", "ls-files", "--error-unmatch", jsonlPath)
-	if err := cmd.Run(); err != nil {
-		// File not tracked - nothing to restore
-		return nil
+	v, err := openSyncVCS(".")
+	if err != nil {
+		return err
 	}
 
-	// Restore from HEAD
-	restoreCmd := exec.CommandContext(ctx, "git", "checkout", "HEAD", "--", jsonlPath) //nolint:gosec // G204: jsonlPath from internal beads.FindBeadsDir()
-	output, err := restoreCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git checkout failed: %w\n%s", err, output)
+	// Restore from HEAD; untracked files are left alone
+	if err := v.Restore(ctx, jsonlPath); err != nil {
+		return fmt.Errorf("restore failed: %w", err)
 	}
 	return nil
 }
@@ -555,26 +536,29 @@ func getDefaultBranch(ctx context.Context) string {
 // getDefaultBranchForRemote returns the default branch name for a specific remote
 // Checks remote HEAD first, then falls back to checking if main/master exist
 func getDefaultBranchForRemote(ctx context.Context, remote string) string {
-	// Try to get default branch from remote
-	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", fmt.Sprintf("refs/remotes/%s/HEAD", remote)) //nolint:gosec // G204: remote from git config
-	output, err := cmd.Output()
-	if err == nil {
-		ref := strings.TrimSpace(string(output))
-		// Extract branch name from refs/remotes/<remote>/main
-		prefix := fmt.Sprintf("refs/remotes/%s/", remote)
-		if strings.HasPrefix(ref, prefix) {
-			return strings.TrimPrefix(ref, prefix)
-		}
+	v, err := openSyncVCS(".")
+	if err != nil {
+		return "main"
 	}
 
-	// Fallback: check if <remote>/main exists
-	if exec.CommandContext(ctx, "git", "rev-parse", "--verify", fmt.Sprintf("%s/main", remote)).Run() == nil { //nolint:gosec // G204: remote from git config
-		return "main"
+	// Try to get default branch from remote
+	if v.Name() == vcs.TypeGit {
+		output, err := v.Exec(ctx, "symbolic-ref", fmt.Sprintf("refs/remotes/%s/HEAD", remote))
+		if err == nil {
+			ref := strings.TrimSpace(string(output))
+			// Extract branch name from refs/remotes/<remote>/main
+			prefix := fmt.Sprintf("refs/remotes/%s/", remote)
+			if strings.HasPrefix(ref, prefix) {
+				return strings.TrimPrefix(ref, prefix)
+			}
+		}
 	}
 
-	// Fallback: check if <remote>/master exists
-	if exec.CommandContext(ctx, "git", "rev-parse", "--verify", fmt.Sprintf("%s/master", remote)).Run() == nil { //nolint:gosec // G204: remote from git config
-		return "master"
+	// Fallback: check if <remote>/main, then <remote>/master exist
+	for _, branch := range []string{"main", "master"} {
+		if _, err := v.GetCommitHash(v.RemoteRef(remote, branch)); err == nil {
+			return branch
+		}
 	}
 
 	// Default to main
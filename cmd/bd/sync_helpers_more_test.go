@@ -11,23 +11,28 @@ import (
 	"github.com/steveyegge/beads/internal/config"
 )
 
-func TestBuildGitCommitArgs_ConfigOptions(t *testing.T) {
+func TestSyncCommitOptions_ConfigOptions(t *testing.T) {
 	if err := config.Initialize(); err != nil {
 		t.Fatalf("config.Initialize: %v", err)
 	}
 	config.Set("git.author", "Test User <test@example.com>")
 	config.Set("git.no-gpg-sign", true)
 
-	args := buildGitCommitArgs("/repo", "hello", "--", ".beads")
-	joined := strings.Join(args, " ")
-	if !strings.Contains(joined, "--author") {
-		t.Fatalf("expected --author in args: %v", args)
+	opts := syncCommitOptions("hello", ".beads")
+	if opts.Author != "Test User <test@example.com>" {
+		t.Fatalf("expected author from config, got %q", opts.Author)
 	}
-	if !strings.Contains(joined, "--no-gpg-sign") {
-		t.Fatalf("expected --no-gpg-sign in args: %v", args)
+	if !opts.NoGPGSign {
+		t.Fatalf("expected NoGPGSign from config")
 	}
-	if !strings.Contains(joined, "-m hello") {
-		t.Fatalf("expected message in args: %v", args)
+	if opts.Message != "hello" {
+		t.Fatalf("expected message hello, got %q", opts.Message)
+	}
+	if len(opts.Paths) != 1 || opts.Paths[0] != ".beads" {
+		t.Fatalf("expected paths [.beads], got %v", opts.Paths)
+	}
+	if !opts.CreateNew {
+		t.Fatalf("expected CreateNew so jj starts a fresh change")
 	}
 }
 
@@ -181,35 +181,42 @@ func doSyncFromMain(ctx context.Context, jsonlPath string, renameOnImport bool,
 	}
 
 	// Check if we're in a git repository
-	if !isGitRepo() {
+	v, err := openSyncVCS(".")
+	if err != nil {
 		return fmt.Errorf("not in a git repository")
 	}
 
 	// Check if remote exists
-	if !hasGitRemote(ctx) {
+	remotes, err := v.GetRemotes()
+	if err != nil || len(remotes) == 0 {
 		return fmt.Errorf("no git remote configured")
 	}
 
 	// Verify the configured remote exists
-	checkRemoteCmd := exec.CommandContext(ctx, "git", "remote", "get-url", remote)
-	if err := checkRemoteCmd.Run(); err != nil {
+	found := false
+	for _, r := range remotes {
+		if r.Name == remote {
+			found = true
+			break
+		}
+	}
+	if !found {
 		return fmt.Errorf("configured sync.remote '%s' does not exist (run 'git remote add %s <url>')", remote, remote)
 	}
 
 	defaultBranch := getDefaultBranchForRemote(ctx, remote)
+	remoteBranch := v.RemoteRef(remote, defaultBranch)
 
 	// Step 1: Fetch from main
-	fmt.Printf("→ Fetching from %s/%s...\n", remote, defaultBranch)
-	fetchCmd := exec.CommandContext(ctx, "git", "fetch", remote, defaultBranch) //nolint:gosec // remote and defaultBranch from config
-	if output, err := fetchCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git fetch %s %s failed: %w\n%s", remote, defaultBranch, err, output)
+	fmt.Printf("→ Fetching from %s...\n", remoteBranch)
+	if err := v.Fetch(ctx, remote, defaultBranch); err != nil {
+		return fmt.Errorf("fetch %s %s failed: %w", remote, defaultBranch, err)
 	}
 
 	// Step 2: Checkout .beads/ directory from main
-	fmt.Printf("→ Checking out beads from %s/%s...\n", remote, defaultBranch)
-	checkoutCmd := exec.CommandContext(ctx, "git", "checkout", fmt.Sprintf("%s/%s", remote, defaultBranch), "--", ".beads/") //nolint:gosec // remote and defaultBranch from config
-	if output, err := checkoutCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git checkout .beads/ from %s/%s failed: %w\n%s", remote, defaultBranch, err, output)
+	fmt.Printf("→ Checking out beads from %s...\n", remoteBranch)
+	if err := v.RestoreFrom(ctx, remoteBranch, ".beads"); err != nil {
+		return fmt.Errorf("checkout .beads/ from %s failed: %w", remoteBranch, err)
 	}
 
 	// Step 3: Import JSONL
@@ -14,6 +14,8 @@ import (
 	"github.com/steveyegge/beads/internal/storage/sqlite"
 	"github.com/steveyegge/beads/internal/syncbranch"
 	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/vcs"
+	"github.com/steveyegge/beads/internal/vcs/jj"
 )
 
 func TestIsGitRepo_InGitRepo(t *testing.T) {
@@ -32,6 +34,35 @@ func TestIsGitRepo_NotInGitRepo(t *testing.T) {
 	}
 }
 
+func TestIsGitRepo_JJ(t *testing.T) {
+	if !vcs.IsJJAvailable() {
+		t.Skip("jj not available")
+	}
+
+	plainDir := t.TempDir()
+	if _, err := jj.Init(plainDir, false); err != nil {
+		t.Fatalf("jj init: %v", err)
+	}
+	t.Chdir(plainDir)
+	if isGitRepo() {
+		t.Error("expected false in a plain jj repo")
+	}
+	if !isVCSRepo() {
+		t.Error("expected a plain jj repo to be a VCS repo")
+	}
+
+	// A colocated repo is a git repo even when bd syncs through jj
+	colocatedDir := t.TempDir()
+	if _, err := jj.Init(colocatedDir, true); err != nil {
+		t.Fatalf("jj init --colocate: %v", err)
+	}
+	t.Chdir(colocatedDir)
+	t.Setenv("BD_VCS", "jj")
+	if !isGitRepo() {
+		t.Error("expected true in a colocated jj repo")
+	}
+}
+
 func TestGitHasUpstream_NoUpstream(t *testing.T) {
 	_, cleanup := setupGitRepo(t)
 	defer cleanup()
@@ -805,6 +836,40 @@ func TestGetGitCommonDir(t *testing.T) {
 			t.Errorf("common dirs differ: main=%q, worktree=%q", mainCommonDir, worktreeCommonDir)
 		}
 	})
+
+	// Test 3: jj workspaces share the .jj directory holding the repo store
+	t.Run("jj workspace shares common dir with main repo", func(t *testing.T) {
+		if !vcs.IsJJAvailable() {
+			t.Skip("jj not available")
+		}
+
+		repoDir := t.TempDir()
+		repo, err := jj.Init(repoDir, false)
+		if err != nil {
+			t.Fatalf("jj init: %v", err)
+		}
+		workspaceDir := filepath.Join(t.TempDir(), "workspace")
+		if _, err := repo.Exec(ctx, "workspace", "add", "--name", "second", workspaceDir); err != nil {
+			t.Fatalf("jj workspace add: %v", err)
+		}
+
+		mainCommonDir, err := getGitCommonDir(ctx, repoDir)
+		if err != nil {
+			t.Fatalf("getGitCommonDir(main) failed: %v", err)
+		}
+		workspaceCommonDir, err := getGitCommonDir(ctx, workspaceDir)
+		if err != nil {
+			t.Fatalf("getGitCommonDir(workspace) failed: %v", err)
+		}
+
+		expectedJJDir := filepath.Join(repoDir, ".jj")
+		if resolved, err := filepath.EvalSymlinks(expectedJJDir); err == nil {
+			expectedJJDir = resolved
+		}
+		if mainCommonDir != expectedJJDir || workspaceCommonDir != expectedJJDir {
+			t.Errorf("common dirs: main=%q, workspace=%q, want %q", mainCommonDir, workspaceCommonDir, expectedJJDir)
+		}
+	})
 }
 
 // TestIsExternalBeadsDir tests that isExternalBeadsDir correctly identifies
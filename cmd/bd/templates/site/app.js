@@ -0,0 +1,68 @@
+// Client-side filtering and search for the static issue site. The search
+// index is loaded from search-index.js (window.BD_SEARCH_INDEX) so the site
+// also works when opened straight from disk.
+(function () {
+  "use strict";
+
+  var filters = document.getElementById("filters");
+  if (filters) {
+    var rows = Array.prototype.slice.call(document.querySelectorAll("#issues tbody tr"));
+    var count = document.getElementById("filter-count");
+    var apply = function () {
+      var f = new FormData(filters);
+      var text = (f.get("text") || "").toLowerCase();
+      var shown = 0;
+      rows.forEach(function (row) {
+        var d = row.dataset;
+        var labels = d.labels ? d.labels.split(" ") : [];
+        var ok = (!f.get("status") || d.status === f.get("status")) &&
+          (!f.get("type") || d.type === f.get("type")) &&
+          (!f.get("priority") || d.priority === f.get("priority")) &&
+          (!f.get("assignee") || d.assignee === f.get("assignee")) &&
+          (!f.get("label") || labels.indexOf(f.get("label")) >= 0) &&
+          (!text || d.text.indexOf(text) >= 0);
+        row.hidden = !ok;
+        if (ok) shown++;
+      });
+      count.textContent = shown + " issues";
+    };
+    filters.addEventListener("input", apply);
+    filters.addEventListener("submit", function (e) { e.preventDefault(); });
+
+    // Allow links such as index.html#status=open&label=ui
+    if (location.hash.length > 1) {
+      location.hash.slice(1).split("&").forEach(function (pair) {
+        var kv = pair.split("=");
+        var el = filters.elements[decodeURIComponent(kv[0])];
+        if (el) el.value = decodeURIComponent(kv[1] || "");
+      });
+      apply();
+    }
+  }
+
+  var search = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var index = window.BD_SEARCH_INDEX || [];
+  if (!search || !results) return;
+
+  search.addEventListener("input", function () {
+    var terms = search.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    if (!terms.length) { results.hidden = true; return; }
+    var matches = index.filter(function (doc) {
+      return terms.every(function (t) { return doc.text.indexOf(t) >= 0; });
+    }).slice(0, 50);
+    matches.forEach(function (doc) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = search.dataset.root + doc.url;
+      a.textContent = doc.id + " " + doc.title + " [" + doc.status + "]";
+      li.appendChild(a);
+      results.appendChild(li);
+    });
+    results.hidden = matches.length === 0;
+  });
+  document.addEventListener("click", function (e) {
+    if (e.target !== search) results.hidden = true;
+  });
+})();
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/rpc"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/sqlite"
+	"github.com/steveyegge/beads/internal/tui"
+	"github.com/steveyegge/beads/internal/types"
+	"golang.org/x/term"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:     "tui",
+	GroupID: "views",
+	Short:   "Interactive kanban board for triage",
+	Long: `Open a full-screen kanban board with one column per status.
+
+Columns default to open, in_progress, blocked and deferred, followed by any
+custom statuses (status.custom) and, with --closed, closed. Use --columns to
+pick the columns and their order.
+
+Move between issues with the arrow keys (or h/j/k/l), move the selected issue
+to the previous or next column with < and >, set its priority with 0-4, and
+edit priority, assignee and labels inline with p, a and L. Enter opens a
+detail pane with dependencies and comments. Press ? for all keys.
+
+The board refreshes when issues change: from the daemon's mutation feed when a
+daemon is running, otherwise by watching the database.
+
+Examples:
+  bd tui
+  bd tui --closed
+  bd tui --columns open,in_progress,awaiting_review`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		columns, _ := cmd.Flags().GetStringSlice("columns")
+		showClosed, _ := cmd.Flags().GetBool("closed")
+		refresh, _ := cmd.Flags().GetDuration("refresh")
+
+		if !term.IsTerminal(int(os.Stdout.Fd())) {
+			FatalError("bd tui needs an interactive terminal")
+		}
+
+		ctx := rootCtx
+		var src tui.Source
+		if daemonClient != nil {
+			src = &daemonTUISource{client: daemonClient}
+		} else {
+			if err := ensureStoreActive(); err != nil {
+				FatalError("%v", err)
+			}
+			src = &storeTUISource{store: store, dbPath: dbPath}
+		}
+
+		statuses, err := tuiColumns(ctx, columns, showClosed)
+		if err != nil {
+			FatalErrorWithHint(err.Error(), "use built-in statuses or ones listed in status.custom")
+		}
+
+		if err := tui.Run(ctx, src, tui.Options{Statuses: statuses, RefreshInterval: refresh}); err != nil {
+			FatalError("%v", err)
+		}
+	},
+}
+
+func init() {
+	tuiCmd.Flags().StringSlice("columns", nil, "Statuses to show as columns, in order (default: open,in_progress,blocked,deferred + custom)")
+	tuiCmd.Flags().Bool("closed", false, "Add a closed column")
+	tuiCmd.Flags().Duration("refresh", 2*time.Second, "How often to check for changes")
+	rootCmd.AddCommand(tuiCmd)
+}
+
+// tuiColumns resolves the board columns from --columns, or the default
+// workflow statuses plus custom statuses.
+func tuiColumns(ctx context.Context, columns []string, showClosed bool) ([]types.Status, error) {
+	var custom []string
+	if s, closeStore, err := openReadStore(ctx); err == nil {
+		custom, _ = s.GetCustomStatuses(ctx)
+		closeStore()
+	}
+
+	var statuses []types.Status
+	seen := make(map[types.Status]bool)
+	add := func(s types.Status) {
+		if !seen[s] {
+			seen[s] = true
+			statuses = append(statuses, s)
+		}
+	}
+	if len(columns) > 0 {
+		for _, c := range columns {
+			s := types.Status(strings.TrimSpace(c))
+			if !s.IsValidWithCustom(custom) || s == types.StatusTombstone {
+				return nil, fmt.Errorf("invalid column status %q", c)
+			}
+			add(s)
+		}
+		return statuses, nil
+	}
+
+	for _, s := range []types.Status{types.StatusOpen, types.StatusInProgress, types.StatusBlocked, types.StatusDeferred} {
+		add(s)
+	}
+	for _, c := range custom {
+		add(types.Status(c))
+	}
+	if showClosed {
+		add(types.StatusClosed)
+	}
+	return statuses, nil
+}
+
+// daemonTUISource drives the board through the daemon. The RPC client is not
+// safe for concurrent use, so calls are serialized.
+type daemonTUISource struct {
+	mu     sync.Mutex
+	client *rpc.Client
+}
+
+func (d *daemonTUISource) ListIssues(ctx context.Context) ([]*types.Issue, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resp, err := d.client.List(&rpc.ListArgs{})
+	if err != nil {
+		return nil, err
+	}
+	var issues []*types.Issue
+	if err := json.Unmarshal(resp.Data, &issues); err != nil {
+		return nil, fmt.Errorf("failed to parse issues: %w", err)
+	}
+	return issues, nil
+}
+
+func (d *daemonTUISource) IssueDetails(ctx context.Context, id string) (*types.IssueDetails, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resp, err := d.client.Show(&rpc.ShowArgs{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	var details types.IssueDetails
+	if err := json.Unmarshal(resp.Data, &details); err != nil {
+		return nil, fmt.Errorf("failed to parse issue: %w", err)
+	}
+	return &details, nil
+}
+
+func (d *daemonTUISource) ApplyEdit(ctx context.Context, id string, edit tui.Edit) error {
+	if readonlyMode {
+		return fmt.Errorf("read-only mode")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	args := &rpc.UpdateArgs{
+		ID:           id,
+		Priority:     edit.Priority,
+		Assignee:     edit.Assignee,
+		AddLabels:    edit.AddLabels,
+		RemoveLabels: edit.RemoveLabels,
+	}
+	if edit.Status != nil {
+		status := string(*edit.Status)
+		args.Status = &status
+	}
+	_, err := d.client.Update(args)
+	return err
+}
+
+func (d *daemonTUISource) Changed(ctx context.Context, since time.Time) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resp, err := d.client.GetMutations(&rpc.GetMutationsArgs{Since: since.UnixMilli()})
+	if err != nil {
+		return false, err
+	}
+	var mutations []rpc.MutationEvent
+	if err := json.Unmarshal(resp.Data, &mutations); err != nil {
+		return false, fmt.Errorf("failed to parse mutations: %w", err)
+	}
+	return len(mutations) > 0, nil
+}
+
+// storeTUISource drives the board through direct storage access.
+type storeTUISource struct {
+	store  storage.Storage
+	dbPath string
+}
+
+func (s *storeTUISource) ListIssues(ctx context.Context) ([]*types.Issue, error) {
+	return s.store.SearchIssues(ctx, "", types.IssueFilter{})
+}
+
+func (s *storeTUISource) IssueDetails(ctx context.Context, id string) (*types.IssueDetails, error) {
+	issue, err := s.store.GetIssue(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if issue == nil {
+		return nil, fmt.Errorf("issue %s not found", id)
+	}
+	details := &types.IssueDetails{Issue: *issue}
+	details.Labels, _ = s.store.GetLabels(ctx, id)
+	if sqliteStore, ok := s.store.(*sqlite.SQLiteStorage); ok {
+		details.Dependencies, _ = sqliteStore.GetDependenciesWithMetadata(ctx, id)
+		details.Dependents, _ = sqliteStore.GetDependentsWithMetadata(ctx, id)
+	}
+	details.Comments, _ = s.store.GetIssueComments(ctx, id)
+	return details, nil
+}
+
+func (s *storeTUISource) ApplyEdit(ctx context.Context, id string, edit tui.Edit) error {
+	if readonlyMode {
+		return fmt.Errorf("read-only mode")
+	}
+	updates := make(map[string]interface{})
+	if edit.Status != nil {
+		updates["status"] = string(*edit.Status)
+	}
+	if edit.Priority != nil {
+		updates["priority"] = *edit.Priority
+	}
+	if edit.Assignee != nil {
+		updates["assignee"] = *edit.Assignee
+	}
+	if len(updates) > 0 {
+		if err := s.store.UpdateIssue(ctx, id, updates, actor); err != nil {
+			return err
+		}
+	}
+	for _, label := range edit.AddLabels {
+		if err := s.store.AddLabel(ctx, id, label, actor); err != nil {
+			return err
+		}
+	}
+	for _, label := range edit.RemoveLabels {
+		if err := s.store.RemoveLabel(ctx, id, label, actor); err != nil {
+			return err
+		}
+	}
+	markDirtyAndScheduleFlush()
+	return nil
+}
+
+// Changed watches the database files: without a daemon there is no mutation
+// feed, but any write touches the database or its WAL.
+func (s *storeTUISource) Changed(ctx context.Context, since time.Time) (bool, error) {
+	for _, path := range []string{s.dbPath, s.dbPath + "-wal"} {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/steveyegge/beads/internal/beads"
 	"github.com/steveyegge/beads/internal/turso/daemon"
 	"github.com/steveyegge/beads/internal/turso/db"
+	"github.com/steveyegge/beads/internal/turso/migrate"
 	"github.com/steveyegge/beads/internal/turso/sync"
 	"github.com/steveyegge/beads/internal/ui"
 )
@@ -22,7 +23,11 @@ var tursoCmd = &cobra.Command{
 	Long: `Manage the Turso query cache for fast multi-agent access.
 
 The Turso cache is a local SQLite database (.beads/turso.db) that provides
-fast concurrent queries for ready work. It syncs from jj task/dep files.`,
+fast concurrent queries for ready work. It syncs from jj task/dep files.
+
+Run any command with --turso (or set 'turso: true' in .beads/config.yaml)
+to use the task/dep files as the database: reads see every field, and
+writes go to tasks/*.json, deps/*.json and the cache in one step.`,
 }
 
 var tursoSyncCmd = &cobra.Command{
@@ -237,8 +242,96 @@ The daemon will:
 	},
 }
 
+var tursoExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export task/dep files back to issues.jsonl",
+	Long: `Export jj-turso task and dependency files back to JSONL.
+
+This is the inverse of 'bd migrate from-jsonl': it reads tasks/*.json and
+deps/*.json and writes one issue per line, sorted by ID, with labels,
+comments and dependencies attached. The conversion is lossless, so teams
+running both storage modes can move back and forth without losing data.
+
+Examples:
+  bd turso export                          # writes .beads/issues.jsonl
+  bd turso export -o /tmp/issues.jsonl
+  bd turso export --dry-run --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		beadsDir := beads.FindBeadsDir()
+		if beadsDir == "" {
+			fmt.Fprintf(os.Stderr, "Error: .beads directory not found\n")
+			os.Exit(1)
+		}
+
+		fromFiles, _ := cmd.Flags().GetString("from")
+		output, _ := cmd.Flags().GetString("output")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if fromFiles == "" {
+			fromFiles = beadsDir
+		}
+		if output == "" {
+			output = filepath.Join(beadsDir, "issues.jsonl")
+		}
+
+		if !dryRun {
+			if err := validateExportPath(output); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		result, err := migrate.Export(rootCtx, migrate.ExportOptions{
+			FromFiles: fromFiles,
+			ToJSONL:   output,
+			DryRun:    dryRun,
+		})
+		if err != nil {
+			if jsonOutput {
+				outputJSON(map[string]interface{}{
+					"success": false,
+					"error":   err.Error(),
+				})
+			} else {
+				fmt.Fprintf(os.Stderr, "Error during export: %v\n", err)
+			}
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			outputJSON(map[string]interface{}{
+				"success":         true,
+				"issues_exported": result.IssuesExported,
+				"deps_exported":   result.DepsExported,
+				"orphan_deps":     result.OrphanDeps,
+				"output_file":     output,
+				"dry_run":         dryRun,
+			})
+			return
+		}
+
+		if dryRun {
+			fmt.Println("=== DRY RUN MODE ===")
+		}
+		fmt.Printf("%s Exported %d issues (%d dependencies) to %s\n",
+			ui.RenderPass("✓"), result.IssuesExported, result.DepsExported, output)
+		if len(result.OrphanDeps) > 0 {
+			fmt.Printf("%s Skipped %d dependency file(s) referencing missing tasks:\n",
+				ui.RenderWarn("⚠"), len(result.OrphanDeps))
+			for _, name := range result.OrphanDeps {
+				fmt.Printf("   - %s\n", name)
+			}
+		}
+	},
+}
+
 func init() {
+	tursoExportCmd.Flags().String("from", "", "Directory containing tasks/ and deps/ (default: .beads)")
+	tursoExportCmd.Flags().StringP("output", "o", "", "Output JSONL file (default: .beads/issues.jsonl)")
+	tursoExportCmd.Flags().Bool("dry-run", false, "Preview export without writing")
+
 	tursoCmd.AddCommand(tursoSyncCmd)
+	tursoCmd.AddCommand(tursoExportCmd)
 	tursoCmd.AddCommand(tursoStatusCmd)
 	tursoCmd.AddCommand(tursoDaemonCmd)
 	rootCmd.AddCommand(tursoCmd)
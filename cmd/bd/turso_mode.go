@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/debug"
+	tursostorage "github.com/steveyegge/beads/internal/storage/turso"
+)
+
+// initializeTursoMode sets up storage backed by tasks/*.json, deps/*.json and
+// the Turso cache. This is called when --turso is set (or turso: true in
+// config.yaml) and lets every command run unchanged against jj-turso data.
+func initializeTursoMode() error {
+	beadsDir := beads.FindBeadsDir()
+	if beadsDir == "" {
+		return fmt.Errorf("no .beads directory found (hint: run 'bd init' first or set BEADS_DIR)")
+	}
+
+	tursoStore, err := tursostorage.New(rootCtx, beadsDir)
+	if err != nil {
+		return fmt.Errorf("failed to open Turso storage: %w", err)
+	}
+
+	// Detect and persist the prefix the same way --no-db does
+	prefix, err := tursoStore.GetConfig(rootCtx, "issue_prefix")
+	if err != nil {
+		_ = tursoStore.Close()
+		return fmt.Errorf("failed to read prefix: %w", err)
+	}
+	if prefix == "" {
+		prefix, err = detectPrefix(beadsDir, tursoStore.MemoryStorage)
+		if err != nil {
+			_ = tursoStore.Close()
+			return fmt.Errorf("failed to detect prefix: %w", err)
+		}
+		if err := tursoStore.SetConfig(rootCtx, "issue_prefix", prefix); err != nil {
+			_ = tursoStore.Close()
+			return fmt.Errorf("failed to set prefix: %w", err)
+		}
+	}
+
+	debug.Logf("turso mode: loaded task files from %s, prefix '%s'", beadsDir, prefix)
+
+	lockStore()
+	setStore(tursoStore)
+	setStoreActive(true)
+	unlockStore()
+	return nil
+}
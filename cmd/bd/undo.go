@@ -0,0 +1,957 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	opdaemon "github.com/steveyegge/beads/internal/turso/daemon"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+const (
+	// undoEventScan bounds how many recent events are read to find mutations.
+	undoEventScan = 1000
+
+	// mutationGap is the largest gap between consecutive events of one bd
+	// command, for events written before commands recorded their ID.
+	// events.created_at has one-second resolution.
+	mutationGap = 2 * time.Second
+
+	// undoOpScan bounds how far back the VCS operation log is searched for a
+	// JSONL snapshot.
+	undoOpScan = 200
+)
+
+// Mutation is a group of events written by one bd command, newest first.
+type Mutation struct {
+	Actor     string         `json:"actor"`
+	CommandID string         `json:"command_id,omitempty"`
+	Start     time.Time      `json:"start"`
+	End       time.Time      `json:"end"`
+	Events    []*types.Event `json:"events"`
+}
+
+// Summary describes the mutation in one line, e.g. "closed bd-1, bd-2".
+func (m *Mutation) Summary() string {
+	var kinds []string
+	ids := make(map[string][]string)
+	for i := len(m.Events) - 1; i >= 0; i-- {
+		e := m.Events[i]
+		kind := string(e.EventType)
+		if _, ok := ids[kind]; !ok {
+			kinds = append(kinds, kind)
+		}
+		if !slices.Contains(ids[kind], e.IssueID) {
+			ids[kind] = append(ids[kind], e.IssueID)
+		}
+	}
+
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		list := ids[kind]
+		if len(list) > 3 {
+			list = append(list[:3:3], fmt.Sprintf("+%d more", len(ids[kind])-3))
+		}
+		parts = append(parts, kind+" "+strings.Join(list, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// UndoAction is one reversal step of an undo plan.
+type UndoAction struct {
+	IssueID     string `json:"issue_id"`
+	Description string `json:"description"`
+	Source      string `json:"source"`            // "events" or the VCS operation the data came from
+	Skipped     string `json:"skipped,omitempty"` // Why the event can't be reversed
+
+	apply func(ctx context.Context) error
+}
+
+// UndoPlan reverses a set of mutations, newest first.
+type UndoPlan struct {
+	Mutations []*Mutation   `json:"mutations"`
+	Actions   []*UndoAction `json:"actions"`
+	Warnings  []string      `json:"warnings,omitempty"`
+}
+
+var undoCmd = &cobra.Command{
+	Use:     "undo",
+	GroupID: "issues",
+	Short:   "Reverse the most recent bd mutations",
+	Long: `Reverse the last N bd mutations using the audit trail in the events table.
+
+A mutation is everything one bd command wrote: a cascade delete or a bulk
+update counts as one step. Updates are reverted to the recorded old values,
+created issues become tombstones, deleted issues are restored, and label and
+dependency changes are reversed. Imports from sync are not local mutations
+and are never undone.
+
+When the events don't hold enough to reverse a change (the status an issue
+had before it was deleted, dependencies removed by a batch delete), the
+JSONL is read from the jj operation log or git reflog as it was just before
+the mutation.
+
+Without --force, shows what would be reversed. An undo is itself a
+mutation, so running 'bd undo' again redoes it.
+
+Examples:
+  bd undo                  # Preview reversing the last mutation
+  bd undo --force          # Reverse it
+  bd undo --steps 3 -f     # Reverse the last three mutations
+  bd history ops           # List recent mutations and VCS operations`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("undo")
+		steps, _ := cmd.Flags().GetInt("steps")
+		force, _ := cmd.Flags().GetBool("force")
+		if steps < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --steps must be at least 1\n")
+			os.Exit(1)
+		}
+
+		if err := ensureDirectMode("undo reads the events table directly"); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		ctx := rootCtx
+
+		mutations, err := recentMutations(ctx, steps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(mutations) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: no mutations to undo\n")
+			os.Exit(1)
+		}
+
+		plan, err := buildUndoPlan(ctx, mutations, findJSONLPath())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !force {
+			if jsonOutput {
+				outputJSON(plan)
+				return
+			}
+			printUndoPlan(plan, steps)
+			return
+		}
+
+		applied, failed := applyUndoPlan(ctx, plan)
+		if applied > 0 {
+			markDirtyAndScheduleFlush()
+		}
+
+		if jsonOutput {
+			outputJSON(map[string]interface{}{
+				"plan":    plan,
+				"applied": applied,
+				"failed":  failed,
+			})
+		} else {
+			fmt.Printf("%s Undid %d mutation(s): %d change(s) reversed", ui.RenderPass("✓"), len(plan.Mutations), applied)
+			if failed > 0 {
+				fmt.Printf(", %d failed", failed)
+			}
+			fmt.Println()
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	undoCmd.Flags().IntP("steps", "n", 1, "Number of mutations to undo")
+	undoCmd.Flags().BoolP("force", "f", false, "Actually undo (without this flag, shows preview)")
+	rootCmd.AddCommand(undoCmd)
+}
+
+type recentEventLister interface {
+	GetRecentEvents(ctx context.Context, limit int) ([]*types.Event, error)
+}
+
+// recentMutations returns up to limit of the latest local mutations.
+func recentMutations(ctx context.Context, limit int) ([]*Mutation, error) {
+	lister, ok := store.(recentEventLister)
+	if !ok {
+		return nil, fmt.Errorf("undo is not supported by this storage backend")
+	}
+	events, err := lister.GetRecentEvents(ctx, undoEventScan)
+	if err != nil {
+		return nil, err
+	}
+	mutations := groupMutations(events)
+	if len(mutations) > limit {
+		mutations = mutations[:limit]
+	}
+	return mutations, nil
+}
+
+// newCommandID returns a random ID for this bd invocation.
+func newCommandID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// isImportActor reports whether events by actor came from an import rather
+// than a local bd command.
+func isImportActor(actor string) bool {
+	return actor == "import"
+}
+
+// groupMutations splits newest-first events into mutations. Consecutive
+// events with the same command ID belong to the same command. Older events
+// without one are grouped when they are by the same actor and no more than
+// mutationGap apart. Import events are dropped.
+func groupMutations(events []*types.Event) []*Mutation {
+	var mutations []*Mutation
+	var cur *Mutation
+	for _, e := range events {
+		if isImportActor(e.Actor) {
+			continue
+		}
+		if cur == nil || cur.Actor != e.Actor || cur.CommandID != e.CommandID ||
+			(e.CommandID == "" && cur.Start.Sub(e.CreatedAt) > mutationGap) {
+			cur = &Mutation{Actor: e.Actor, CommandID: e.CommandID, End: e.CreatedAt}
+			mutations = append(mutations, cur)
+		}
+		cur.Start = e.CreatedAt
+		cur.Events = append(cur.Events, e)
+	}
+	return mutations
+}
+
+// undoPlanner builds an UndoPlan, tracking the state earlier actions in the
+// plan will leave behind so later ones check against it.
+type undoPlanner struct {
+	ctx       context.Context
+	jsonlPath string
+	plan      *UndoPlan
+
+	// planned holds field values set by actions already in the plan
+	planned map[string]map[string]interface{}
+	deps    map[string]bool // Dependency keys the plan re-adds or removes
+
+	snapshot       map[string]*types.Issue
+	snapshotSource string
+	snapshotLoaded bool
+}
+
+func buildUndoPlan(ctx context.Context, mutations []*Mutation, jsonlPath string) (*UndoPlan, error) {
+	p := &undoPlanner{
+		ctx:       ctx,
+		jsonlPath: jsonlPath,
+		plan:      &UndoPlan{Mutations: mutations},
+		planned:   make(map[string]map[string]interface{}),
+		deps:      make(map[string]bool),
+	}
+
+	for _, m := range mutations {
+		// Snapshots are per mutation: each needs the JSONL from before it
+		p.snapshotLoaded = false
+		for _, e := range m.Events {
+			if err := p.planEvent(m, e); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p.plan, nil
+}
+
+func (p *undoPlanner) add(a *UndoAction) {
+	if a.Source == "" {
+		a.Source = "events"
+	}
+	p.plan.Actions = append(p.plan.Actions, a)
+}
+
+func (p *undoPlanner) skip(e *types.Event, reason string) {
+	p.add(&UndoAction{
+		IssueID:     e.IssueID,
+		Description: fmt.Sprintf("%s (event %d)", e.EventType, e.ID),
+		Skipped:     reason,
+	})
+}
+
+func (p *undoPlanner) planEvent(m *Mutation, e *types.Event) error {
+	current, err := store.GetIssue(p.ctx, e.IssueID)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", e.IssueID, err)
+	}
+	if current == nil {
+		p.skip(e, "issue no longer exists")
+		return nil
+	}
+
+	switch e.EventType {
+	case types.EventCreated:
+		p.planUncreate(e, current)
+	case types.EventUpdated, types.EventStatusChanged, types.EventClosed, types.EventReopened:
+		if e.OldValue != nil && e.NewValue != nil {
+			p.planRevertUpdate(e, current)
+		} else if e.EventType == types.EventClosed {
+			p.planReopen(m, e, current)
+		} else {
+			p.skip(e, "event has no recorded old values")
+		}
+	case "deleted":
+		p.planRestore(m, e, current)
+	case "restored":
+		p.planUncreate(e, current)
+	case types.EventLabelAdded, types.EventLabelRemoved:
+		p.planLabel(e)
+	case types.EventDependencyAdded, types.EventDependencyRemoved:
+		p.planDependency(m, e)
+	default:
+		p.skip(e, "not reversible")
+	}
+	return nil
+}
+
+// currentStatus returns the status the issue will have once earlier actions
+// in the plan have run.
+func (p *undoPlanner) currentStatus(current *types.Issue) types.Status {
+	if v, ok := p.planned[current.ID]["status"]; ok {
+		return types.Status(fmt.Sprint(v))
+	}
+	return current.Status
+}
+
+func (p *undoPlanner) setPlanned(id string, updates map[string]interface{}) {
+	if p.planned[id] == nil {
+		p.planned[id] = make(map[string]interface{})
+	}
+	for k, v := range updates {
+		p.planned[id][k] = v
+	}
+}
+
+// planUncreate turns a created (or restored) issue back into a tombstone.
+func (p *undoPlanner) planUncreate(e *types.Event, current *types.Issue) {
+	if p.currentStatus(current) == types.StatusTombstone {
+		p.skip(e, "already deleted")
+		return
+	}
+	id := e.IssueID
+	p.setPlanned(id, map[string]interface{}{"status": string(types.StatusTombstone)})
+	p.add(&UndoAction{
+		IssueID:     id,
+		Description: fmt.Sprintf("delete %s (%s by %s)", id, e.EventType, e.Actor),
+		apply: func(ctx context.Context) error {
+			return createTombstone(ctx, id, actor, "undo")
+		},
+	})
+}
+
+// planRevertUpdate sets the fields an UpdateIssue call changed back to the
+// values recorded in the event.
+func (p *undoPlanner) planRevertUpdate(e *types.Event, current *types.Issue) {
+	var old types.Issue
+	if err := json.Unmarshal([]byte(*e.OldValue), &old); err != nil {
+		p.skip(e, "old values are not readable")
+		return
+	}
+	var changed map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(*e.NewValue), &changed); err != nil {
+		p.skip(e, "new values are not readable")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	var unsupported, conflicts []string
+	for key, raw := range changed {
+		oldVal, ok := issueFieldValue(&old, key)
+		if !ok {
+			unsupported = append(unsupported, key)
+			continue
+		}
+		if !timeFields[key] {
+			var want interface{}
+			_ = json.Unmarshal(raw, &want)
+			now, ok := p.planned[current.ID][key]
+			if !ok {
+				now, _ = issueFieldValue(current, key)
+			}
+			if !sameFieldValue(now, want) {
+				conflicts = append(conflicts, key)
+				continue
+			}
+		}
+		updates[key] = oldVal
+	}
+	sort.Strings(unsupported)
+	sort.Strings(conflicts)
+
+	if len(conflicts) > 0 {
+		p.plan.Warnings = append(p.plan.Warnings, fmt.Sprintf("%s: %s changed since event %d, left as is",
+			e.IssueID, strings.Join(conflicts, ", "), e.ID))
+	}
+	if len(unsupported) > 0 {
+		p.plan.Warnings = append(p.plan.Warnings, fmt.Sprintf("%s: can't revert %s from event %d",
+			e.IssueID, strings.Join(unsupported, ", "), e.ID))
+	}
+	if len(updates) == 0 {
+		p.skip(e, "nothing left to revert")
+		return
+	}
+
+	// closed_at follows status; UpdateIssue manages it when status changes
+	if _, ok := updates["status"]; ok {
+		delete(updates, "closed_at")
+	}
+
+	keys := make([]string, 0, len(updates))
+	for k := range updates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	id := e.IssueID
+	p.setPlanned(id, updates)
+	p.add(&UndoAction{
+		IssueID:     id,
+		Description: fmt.Sprintf("revert %s on %s (%s by %s)", strings.Join(keys, ", "), id, e.EventType, e.Actor),
+		apply: func(ctx context.Context) error {
+			return store.UpdateIssue(ctx, id, updates, actor)
+		},
+	})
+}
+
+// planReopen reverses CloseIssue, which records the close reason but not the
+// status the issue had before.
+func (p *undoPlanner) planReopen(m *Mutation, e *types.Event, current *types.Issue) {
+	if p.currentStatus(current) != types.StatusClosed {
+		p.skip(e, "issue is no longer closed")
+		return
+	}
+	status, source := p.priorStatus(m, e)
+	id := e.IssueID
+	updates := map[string]interface{}{"status": string(status)}
+	p.setPlanned(id, updates)
+	p.add(&UndoAction{
+		IssueID:     id,
+		Description: fmt.Sprintf("reopen %s as %s (closed by %s)", id, status, e.Actor),
+		Source:      source,
+		apply: func(ctx context.Context) error {
+			return store.UpdateIssue(ctx, id, updates, actor)
+		},
+	})
+}
+
+type tombstoneRestorer interface {
+	RestoreTombstone(ctx context.Context, id string, status types.Status, actor string) error
+}
+
+// planRestore brings back a tombstoned issue with its previous status, and
+// the dependencies a batch delete removed without recording events.
+func (p *undoPlanner) planRestore(m *Mutation, e *types.Event, current *types.Issue) {
+	if p.currentStatus(current) != types.StatusTombstone {
+		p.skip(e, "issue is not deleted")
+		return
+	}
+	status, source := p.priorStatus(m, e)
+	id := e.IssueID
+	p.setPlanned(id, map[string]interface{}{"status": string(status)})
+	p.add(&UndoAction{
+		IssueID:     id,
+		Description: fmt.Sprintf("restore %s as %s (deleted by %s)", id, status, e.Actor),
+		Source:      source,
+		apply: func(ctx context.Context) error {
+			r, ok := store.(tombstoneRestorer)
+			if !ok {
+				return fmt.Errorf("restore not supported by this storage backend")
+			}
+			return r.RestoreTombstone(ctx, id, status, actor)
+		},
+	})
+
+	snapshot, snapshotSource := p.loadSnapshot(m)
+	prior := snapshot[id]
+	if prior == nil {
+		p.plan.Warnings = append(p.plan.Warnings, fmt.Sprintf("%s: dependencies removed by the delete can't be restored without a VCS snapshot", id))
+		return
+	}
+	// Outgoing dependencies, plus incoming ones from other issues
+	var deps []*types.Dependency
+	deps = append(deps, prior.Dependencies...)
+	for otherID, other := range snapshot {
+		if otherID == id {
+			continue
+		}
+		for _, dep := range other.Dependencies {
+			if dep.DependsOnID == id {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return depKey(deps[i].IssueID, deps[i].DependsOnID) < depKey(deps[j].IssueID, deps[j].DependsOnID)
+	})
+	for _, dep := range deps {
+		p.planAddDependency(dep, snapshotSource, "")
+	}
+}
+
+// planAddDependency re-adds dep unless the plan already handles it or it
+// still exists.
+func (p *undoPlanner) planAddDependency(dep *types.Dependency, source, by string) {
+	key := depKey(dep.IssueID, dep.DependsOnID)
+	if p.deps[key] {
+		return
+	}
+	p.deps[key] = true
+	if p.dependencyExists(dep.IssueID, dep.DependsOnID) {
+		return
+	}
+
+	d := &types.Dependency{IssueID: dep.IssueID, DependsOnID: dep.DependsOnID, Type: dep.Type, Metadata: dep.Metadata}
+	if d.Type == "" {
+		d.Type = types.DepBlocks
+	}
+	desc := fmt.Sprintf("re-add dependency %s → %s (%s)", d.IssueID, d.DependsOnID, d.Type)
+	if by != "" {
+		desc += " (removed by " + by + ")"
+	}
+	p.add(&UndoAction{
+		IssueID:     d.IssueID,
+		Description: desc,
+		Source:      source,
+		apply: func(ctx context.Context) error {
+			return store.AddDependency(ctx, d, actor)
+		},
+	})
+}
+
+func (p *undoPlanner) dependencyExists(issueID, dependsOnID string) bool {
+	records, err := store.GetDependencyRecords(p.ctx, issueID)
+	if err != nil {
+		return false
+	}
+	for _, r := range records {
+		if r.DependsOnID == dependsOnID {
+			return true
+		}
+	}
+	return false
+}
+
+func depKey(issueID, dependsOnID string) string {
+	return issueID + "\x00" + dependsOnID
+}
+
+func (p *undoPlanner) planLabel(e *types.Event) {
+	if e.Comment == nil {
+		p.skip(e, "label not recorded")
+		return
+	}
+	id := e.IssueID
+	if e.EventType == types.EventLabelAdded {
+		label, ok := strings.CutPrefix(*e.Comment, "Added label: ")
+		if !ok {
+			p.skip(e, "label not recorded")
+			return
+		}
+		p.add(&UndoAction{
+			IssueID:     id,
+			Description: fmt.Sprintf("remove label %q from %s", label, id),
+			apply: func(ctx context.Context) error {
+				return store.RemoveLabel(ctx, id, label, actor)
+			},
+		})
+		return
+	}
+	label, ok := strings.CutPrefix(*e.Comment, "Removed label: ")
+	if !ok {
+		p.skip(e, "label not recorded")
+		return
+	}
+	p.add(&UndoAction{
+		IssueID:     id,
+		Description: fmt.Sprintf("add label %q back to %s", label, id),
+		apply: func(ctx context.Context) error {
+			return store.AddLabel(ctx, id, label, actor)
+		},
+	})
+}
+
+func (p *undoPlanner) planDependency(m *Mutation, e *types.Event) {
+	if e.Comment == nil {
+		p.skip(e, "dependency not recorded")
+		return
+	}
+
+	if e.EventType == types.EventDependencyAdded {
+		// "Added dependency: <issue> <type> <depends-on>"
+		fields := strings.Fields(strings.TrimPrefix(*e.Comment, "Added dependency: "))
+		if len(fields) != 3 {
+			p.skip(e, "dependency not recorded")
+			return
+		}
+		issueID, dependsOnID := fields[0], fields[2]
+		key := depKey(issueID, dependsOnID)
+		if p.deps[key] {
+			return
+		}
+		p.deps[key] = true
+		if !p.dependencyExists(issueID, dependsOnID) {
+			p.skip(e, "dependency was already removed")
+			return
+		}
+		p.add(&UndoAction{
+			IssueID:     issueID,
+			Description: fmt.Sprintf("remove dependency %s → %s (%s)", issueID, dependsOnID, fields[1]),
+			apply: func(ctx context.Context) error {
+				return store.RemoveDependency(ctx, issueID, dependsOnID, actor)
+			},
+		})
+		return
+	}
+
+	// "Removed dependency on <depends-on>": the type isn't recorded, so take
+	// it from the snapshot or the event that added the dependency
+	dependsOnID, ok := strings.CutPrefix(*e.Comment, "Removed dependency on ")
+	if !ok {
+		p.skip(e, "dependency not recorded")
+		return
+	}
+	dep := &types.Dependency{IssueID: e.IssueID, DependsOnID: dependsOnID}
+	source := "events"
+	snapshot, snapshotSource := p.loadSnapshot(m)
+	if prior := snapshot[e.IssueID]; prior != nil {
+		for _, d := range prior.Dependencies {
+			if d.DependsOnID == dependsOnID {
+				dep.Type, dep.Metadata = d.Type, d.Metadata
+				source = snapshotSource
+			}
+		}
+	}
+	if dep.Type == "" {
+		dep.Type = p.addedDependencyType(e)
+	}
+	p.planAddDependency(dep, source, e.Actor)
+}
+
+// addedDependencyType finds the type from the event that added the
+// dependency removed by e, defaulting to blocks.
+func (p *undoPlanner) addedDependencyType(e *types.Event) types.DependencyType {
+	events, err := store.GetEvents(p.ctx, e.IssueID, 0)
+	if err != nil {
+		return types.DepBlocks
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID > events[j].ID })
+	for _, prev := range events {
+		if prev.ID >= e.ID || prev.EventType != types.EventDependencyAdded || prev.Comment == nil {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(*prev.Comment, "Added dependency: "))
+		if len(fields) == 3 && fields[2] == strings.TrimPrefix(*e.Comment, "Removed dependency on ") {
+			return types.DependencyType(fields[1])
+		}
+	}
+	return types.DepBlocks
+}
+
+// priorStatus finds the status an issue had before event e from its earlier
+// events, falling back to the VCS snapshot when they don't say.
+func (p *undoPlanner) priorStatus(m *Mutation, e *types.Event) (types.Status, string) {
+	if events, err := store.GetEvents(p.ctx, e.IssueID, 0); err == nil {
+		if status, ok := statusBefore(events, e.ID); ok {
+			return status, "events"
+		}
+	}
+
+	snapshot, source := p.loadSnapshot(m)
+	if prior := snapshot[e.IssueID]; prior != nil && prior.Status != "" && prior.Status != types.StatusTombstone {
+		return prior.Status, source
+	}
+	return types.StatusOpen, "events"
+}
+
+// statusBefore derives an issue's status just before event beforeID by
+// walking its earlier events backwards. ok is false when no event says.
+func statusBefore(events []*types.Event, beforeID int64) (status types.Status, ok bool) {
+	sort.Slice(events, func(i, j int) bool { return events[i].ID > events[j].ID })
+	for _, e := range events {
+		if e.ID >= beforeID {
+			continue
+		}
+		switch {
+		case e.EventType == types.EventCreated && e.NewValue != nil:
+			var issue types.Issue
+			if json.Unmarshal([]byte(*e.NewValue), &issue) == nil && issue.Status != "" {
+				return issue.Status, true
+			}
+		case e.EventType == "restored" && e.NewValue != nil:
+			return types.Status(*e.NewValue), true
+		case e.EventType == types.EventClosed && e.OldValue == nil:
+			return types.StatusClosed, true
+		case e.OldValue != nil && e.NewValue != nil:
+			var changed map[string]interface{}
+			if json.Unmarshal([]byte(*e.NewValue), &changed) == nil {
+				if s, ok := changed["status"].(string); ok {
+					return types.Status(s), true
+				}
+			}
+			var old types.Issue
+			if json.Unmarshal([]byte(*e.OldValue), &old) == nil && old.Status != "" {
+				return old.Status, true
+			}
+		}
+	}
+	return "", false
+}
+
+// loadSnapshot reads the JSONL from the newest VCS operation before the
+// mutation. Returns nil when there is no repository or no such operation.
+func (p *undoPlanner) loadSnapshot(m *Mutation) (map[string]*types.Issue, string) {
+	if p.snapshotLoaded {
+		return p.snapshot, p.snapshotSource
+	}
+	p.snapshotLoaded = true
+	p.snapshot, p.snapshotSource = nil, ""
+	if p.jsonlPath == "" {
+		return nil, ""
+	}
+
+	v, err := vcs.NewFactory(vcs.WithCache(false), vcs.WithPreferredType(vcs.PreferredVCS())).Create(filepath.Dir(p.jsonlPath))
+	if err != nil {
+		return nil, ""
+	}
+	root, err := v.RepoRoot()
+	if err != nil {
+		return nil, ""
+	}
+	rel, err := filepath.Rel(root, p.jsonlPath)
+	if err != nil {
+		return nil, ""
+	}
+	ops, err := v.GetOperationLog(undoOpScan)
+	if err != nil {
+		return nil, ""
+	}
+
+	op := operationBefore(ops, m.Start)
+	if op == nil {
+		return nil, ""
+	}
+	data, err := v.ExtractFileAtOperation(op.ID, filepath.ToSlash(rel))
+	if err != nil {
+		return nil, ""
+	}
+
+	p.snapshot = parseJSONLSnapshot(data)
+	p.snapshotSource = fmt.Sprintf("%s %s", v.Name(), opdaemon.ShortOpID(op.ID))
+	return p.snapshot, p.snapshotSource
+}
+
+// operationBefore returns the newest operation that finished before t.
+// Operations are newest first; ones without a timestamp are skipped.
+func operationBefore(ops []vcs.OperationInfo, t time.Time) *vcs.OperationInfo {
+	// Event times are truncated to the second; only trust earlier seconds
+	cutoff := t.Truncate(time.Second)
+	for i := range ops {
+		if !ops[i].Timestamp.IsZero() && ops[i].Timestamp.Before(cutoff) {
+			return &ops[i]
+		}
+	}
+	return nil
+}
+
+// parseJSONLSnapshot indexes the issues in JSONL data by ID, skipping lines
+// that don't parse.
+func parseJSONLSnapshot(data []byte) map[string]*types.Issue {
+	issues := make(map[string]*types.Issue)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var issue types.Issue
+		if err := json.Unmarshal(line, &issue); err != nil || issue.ID == "" {
+			continue
+		}
+		issues[issue.ID] = &issue
+	}
+	return issues
+}
+
+// timeFields are not compared when checking for later changes: their stored
+// precision differs from what events record.
+var timeFields = map[string]bool{
+	"closed_at":     true,
+	"last_activity": true,
+	"due_at":        true,
+	"defer_until":   true,
+}
+
+// issueFieldValue returns an issue field by its UpdateIssue key, in the form
+// UpdateIssue accepts.
+func issueFieldValue(issue *types.Issue, key string) (interface{}, bool) {
+	timeValue := func(t *time.Time) interface{} {
+		if t == nil {
+			return nil
+		}
+		return *t
+	}
+	switch key {
+	case "status":
+		return string(issue.Status), true
+	case "priority":
+		return issue.Priority, true
+	case "title":
+		return issue.Title, true
+	case "description":
+		return issue.Description, true
+	case "design":
+		return issue.Design, true
+	case "acceptance_criteria":
+		return issue.AcceptanceCriteria, true
+	case "notes":
+		return issue.Notes, true
+	case "issue_type":
+		return string(issue.IssueType), true
+	case "assignee":
+		return issue.Assignee, true
+	case "estimated_minutes":
+		if issue.EstimatedMinutes == nil {
+			return nil, true
+		}
+		return *issue.EstimatedMinutes, true
+	case "external_ref":
+		if issue.ExternalRef == nil {
+			return nil, true
+		}
+		return *issue.ExternalRef, true
+	case "closed_at":
+		return timeValue(issue.ClosedAt), true
+	case "close_reason":
+		return issue.CloseReason, true
+	case "closed_by_session":
+		return issue.ClosedBySession, true
+	case "sender":
+		return issue.Sender, true
+	case "wisp":
+		return issue.Ephemeral, true
+	case "pinned":
+		return issue.Pinned, true
+	case "hook_bead":
+		return issue.HookBead, true
+	case "role_bead":
+		return issue.RoleBead, true
+	case "agent_state":
+		return string(issue.AgentState), true
+	case "last_activity":
+		return timeValue(issue.LastActivity), true
+	case "role_type":
+		return issue.RoleType, true
+	case "rig":
+		return issue.Rig, true
+	case "mol_type":
+		return string(issue.MolType), true
+	case "due_at":
+		return timeValue(issue.DueAt), true
+	case "defer_until":
+		return timeValue(issue.DeferUntil), true
+	case "budget_usd":
+		if issue.BudgetUSD == nil {
+			return nil, true
+		}
+		return *issue.BudgetUSD, true
+	case "await_id":
+		return issue.AwaitID, true
+	}
+	return nil, false
+}
+
+// sameFieldValue compares a field value with one decoded from JSON. Empty
+// strings, false and nil are treated alike since events omit empty values.
+func sameFieldValue(a, b interface{}) bool {
+	normalize := func(v interface{}) interface{} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var out interface{}
+		_ = json.Unmarshal(data, &out)
+		if out == "" || out == false {
+			return nil
+		}
+		return out
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func applyUndoPlan(ctx context.Context, plan *UndoPlan) (applied, failed int) {
+	for _, a := range plan.Actions {
+		if a.apply == nil {
+			continue
+		}
+		if err := a.apply(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to %s: %v\n", a.Description, err)
+			failed++
+			continue
+		}
+		applied++
+	}
+	return applied, failed
+}
+
+func printUndoPlan(plan *UndoPlan, steps int) {
+	fmt.Printf("\n%s\n", ui.RenderWarn("UNDO PREVIEW"))
+
+	fmt.Printf("\nMutations to undo:\n")
+	for i, m := range plan.Mutations {
+		fmt.Printf("  %d. %s  %s  %s\n", i+1, m.End.Local().Format("2006-01-02 15:04:05"), m.Actor, m.Summary())
+	}
+
+	fmt.Printf("\nChanges:\n")
+	reversible := 0
+	for _, a := range plan.Actions {
+		if a.Skipped != "" {
+			fmt.Printf("  - %s %s: %s\n", ui.RenderMuted("skip"), a.Description, a.Skipped)
+			continue
+		}
+		reversible++
+		source := ""
+		if a.Source != "events" {
+			source = ui.RenderMuted(" [from " + a.Source + "]")
+		}
+		fmt.Printf("  %s %s%s\n", ui.RenderPass("•"), a.Description, source)
+	}
+
+	for _, w := range plan.Warnings {
+		fmt.Printf("%s %s\n", ui.RenderWarn("Warning:"), w)
+	}
+
+	if reversible == 0 {
+		fmt.Printf("\nNothing can be reversed.\n\n")
+		return
+	}
+	command := "bd undo --force"
+	if steps > 1 {
+		command = fmt.Sprintf("bd undo --steps %d --force", steps)
+	}
+	fmt.Printf("\nTo proceed, run: %s\n\n", ui.RenderWarn(command))
+}
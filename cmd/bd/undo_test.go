@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+func TestGroupMutations(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ev := func(id int64, actor string, secs int) *types.Event {
+		return &types.Event{ID: id, IssueID: "bd-1", EventType: types.EventUpdated, Actor: actor, CreatedAt: base.Add(time.Duration(secs) * time.Second)}
+	}
+
+	// Newest first, as GetRecentEvents returns them
+	events := []*types.Event{
+		ev(7, "alice", 30),
+		ev(6, "alice", 29),
+		ev(5, "import", 29),
+		ev(4, "alice", 28),
+		ev(3, "batch delete", 28),
+		ev(2, "alice", 10),
+		ev(1, "import", 9),
+	}
+
+	mutations := groupMutations(events)
+	if len(mutations) != 3 {
+		t.Fatalf("groupMutations() returned %d mutations, want 3", len(mutations))
+	}
+	if got := len(mutations[0].Events); got != 3 {
+		t.Errorf("first mutation has %d events, want 3 (import skipped)", got)
+	}
+	if mutations[1].Actor != "batch delete" {
+		t.Errorf("second mutation actor = %q, want batch delete", mutations[1].Actor)
+	}
+	if mutations[2].Events[0].ID != 2 {
+		t.Errorf("third mutation should start at event 2, got %d", mutations[2].Events[0].ID)
+	}
+	if !mutations[0].Start.Equal(base.Add(28*time.Second)) || !mutations[0].End.Equal(base.Add(30*time.Second)) {
+		t.Errorf("unexpected first mutation span %v - %v", mutations[0].Start, mutations[0].End)
+	}
+}
+
+func TestStatusBefore(t *testing.T) {
+	str := func(s string) *string { return &s }
+	events := []*types.Event{
+		{ID: 1, EventType: types.EventCreated, NewValue: str(`{"id":"bd-1","title":"x","status":"open"}`)},
+		{ID: 2, EventType: types.EventStatusChanged, OldValue: str(`{"status":"open"}`), NewValue: str(`{"status":"in_progress"}`)},
+		{ID: 3, EventType: types.EventUpdated, OldValue: str(`{"status":"in_progress","title":"x"}`), NewValue: str(`{"title":"y"}`)},
+		{ID: 4, EventType: types.EventClosed, Comment: str("done")},
+		{ID: 5, EventType: "deleted", Comment: str("delete")},
+	}
+
+	tests := []struct {
+		before int64
+		want   types.Status
+		ok     bool
+	}{
+		{5, types.StatusClosed, true},
+		{4, types.StatusInProgress, true},
+		{3, types.StatusInProgress, true},
+		{2, types.StatusOpen, true},
+		{1, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := statusBefore(events, tt.before)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("statusBefore(%d) = %q, %v; want %q, %v", tt.before, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestOperationBefore(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ops := []vcs.OperationInfo{
+		{ID: "op3", Timestamp: base.Add(5 * time.Second)},
+		{ID: "op2", Timestamp: base.Add(500 * time.Millisecond)},
+		{ID: "op1"},
+		{ID: "op0", Timestamp: base.Add(-time.Minute)},
+	}
+
+	// op2 is in the same second as the mutation, so it may already have it
+	if op := operationBefore(ops, base.Add(900*time.Millisecond)); op == nil || op.ID != "op0" {
+		t.Errorf("operationBefore() = %v, want op0", op)
+	}
+	if op := operationBefore(ops, base.Add(10*time.Second)); op == nil || op.ID != "op3" {
+		t.Errorf("operationBefore() = %v, want op3", op)
+	}
+	if op := operationBefore(ops, base.Add(-2*time.Minute)); op != nil {
+		t.Errorf("operationBefore() = %v, want nil", op)
+	}
+}
+
+func TestParseJSONLSnapshot(t *testing.T) {
+	data := []byte(`{"id":"bd-1","title":"One","status":"closed","dependencies":[{"issue_id":"bd-1","depends_on_id":"bd-2","type":"blocks"}]}
+not json
+
+{"id":"bd-2","title":"Two","status":"open"}
+`)
+	snapshot := parseJSONLSnapshot(data)
+	if len(snapshot) != 2 {
+		t.Fatalf("parseJSONLSnapshot() returned %d issues, want 2", len(snapshot))
+	}
+	if snapshot["bd-1"].Status != types.StatusClosed || len(snapshot["bd-1"].Dependencies) != 1 {
+		t.Errorf("unexpected bd-1: %+v", snapshot["bd-1"])
+	}
+}
+
+func TestSameFieldValue(t *testing.T) {
+	ref := "gh-1"
+	tests := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{1, float64(1), true},
+		{"", nil, true},
+		{false, nil, true},
+		{&ref, "gh-1", true},
+		{"a", "b", false},
+		{2, float64(3), false},
+	}
+	for _, tt := range tests {
+		if got := sameFieldValue(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameFieldValue(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUndoPlanRoundTrip(t *testing.T) {
+	testStore := newTestStore(t, filepath.Join(t.TempDir(), ".beads", "beads.db"))
+	oldStore, oldActor := store, actor
+	store, actor = testStore, "tester"
+	defer func() { store, actor = oldStore, oldActor }()
+
+	ctx := context.Background()
+	parent := &types.Issue{Title: "Parent", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	child := &types.Issue{Title: "Child", Status: types.StatusInProgress, Priority: 2, IssueType: types.TypeTask}
+	for _, issue := range []*types.Issue{parent, child} {
+		if err := testStore.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("CreateIssue failed: %v", err)
+		}
+	}
+	if err := testStore.AddDependency(ctx, &types.Dependency{IssueID: child.ID, DependsOnID: parent.ID, Type: types.DepBlocks}, "tester"); err != nil {
+		t.Fatalf("AddDependency failed: %v", err)
+	}
+	if err := testStore.UpdateIssue(ctx, parent.ID, map[string]interface{}{"title": "Renamed", "priority": 0}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := testStore.AddLabel(ctx, parent.ID, "urgent", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if err := testStore.RemoveDependency(ctx, child.ID, parent.ID, "tester"); err != nil {
+		t.Fatalf("RemoveDependency failed: %v", err)
+	}
+	if err := testStore.CreateTombstone(ctx, child.ID, "tester", "oops"); err != nil {
+		t.Fatalf("CreateTombstone failed: %v", err)
+	}
+
+	// All events fall in one mutation since they are seconds apart at most;
+	// drop the setup events so the plan only covers the later changes.
+	mutations, err := recentMutations(ctx, 1)
+	if err != nil {
+		t.Fatalf("recentMutations failed: %v", err)
+	}
+	if len(mutations) != 1 {
+		t.Fatalf("recentMutations() returned %d mutations, want 1", len(mutations))
+	}
+	m := mutations[0]
+	var trimmed []*types.Event
+	for _, e := range m.Events {
+		if e.EventType != types.EventCreated && e.EventType != types.EventDependencyAdded {
+			trimmed = append(trimmed, e)
+		}
+	}
+	m.Events = trimmed
+
+	plan, err := buildUndoPlan(ctx, mutations, "")
+	if err != nil {
+		t.Fatalf("buildUndoPlan failed: %v", err)
+	}
+	for _, a := range plan.Actions {
+		if a.Skipped != "" {
+			t.Errorf("unexpected skipped action: %s: %s", a.Description, a.Skipped)
+		}
+	}
+
+	applied, failed := applyUndoPlan(ctx, plan)
+	if failed != 0 {
+		t.Fatalf("applyUndoPlan had %d failures", failed)
+	}
+	if applied != 4 {
+		t.Errorf("applyUndoPlan applied %d actions, want 4", applied)
+	}
+
+	gotChild, _ := testStore.GetIssue(ctx, child.ID)
+	if gotChild.Status != types.StatusInProgress {
+		t.Errorf("child status = %s, want in_progress", gotChild.Status)
+	}
+	gotParent, _ := testStore.GetIssue(ctx, parent.ID)
+	if gotParent.Title != "Parent" || gotParent.Priority != 2 {
+		t.Errorf("parent = %q P%d, want %q P2", gotParent.Title, gotParent.Priority, "Parent")
+	}
+	labels, _ := testStore.GetLabels(ctx, parent.ID)
+	if len(labels) != 0 {
+		t.Errorf("labels = %v, want none", labels)
+	}
+	deps, _ := testStore.GetDependencyRecords(ctx, child.ID)
+	if len(deps) != 1 || deps[0].DependsOnID != parent.ID || deps[0].Type != types.DepBlocks {
+		t.Errorf("child dependencies = %+v, want blocks on %s", deps, parent.ID)
+	}
+}
+
+func TestUndoGroupsByCommand(t *testing.T) {
+	testStore := newTestStore(t, filepath.Join(t.TempDir(), ".beads", "beads.db"))
+	oldStore, oldActor := store, actor
+	store, actor = testStore, "tester"
+	defer func() { store, actor = oldStore, oldActor }()
+
+	ctx := context.Background()
+	a := &types.Issue{Title: "A", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	b := &types.Issue{Title: "B", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	if err := testStore.CreateIssues(storage.WithCommandID(ctx, "create"), []*types.Issue{a, b}, "tester"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+
+	// bd update A --priority 1; bd update B --priority 0, back to back
+	if err := testStore.UpdateIssue(storage.WithCommandID(ctx, "first"), a.ID, map[string]interface{}{"priority": 1}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := testStore.UpdateIssue(storage.WithCommandID(ctx, "second"), b.ID, map[string]interface{}{"priority": 0}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+
+	mutations, err := recentMutations(ctx, 3)
+	if err != nil {
+		t.Fatalf("recentMutations failed: %v", err)
+	}
+	if len(mutations) != 3 || mutations[0].CommandID != "second" || mutations[1].CommandID != "first" {
+		t.Fatalf("mutations = %+v, want second, first, create", mutations)
+	}
+
+	// undo -f reverses only the second command, and is itself a mutation
+	undo := func(commandID string) {
+		t.Helper()
+		mutations, err := recentMutations(ctx, 1)
+		if err != nil {
+			t.Fatalf("recentMutations failed: %v", err)
+		}
+		plan, err := buildUndoPlan(ctx, mutations, "")
+		if err != nil {
+			t.Fatalf("buildUndoPlan failed: %v", err)
+		}
+		if _, failed := applyUndoPlan(storage.WithCommandID(ctx, commandID), plan); failed != 0 {
+			t.Fatalf("applyUndoPlan had %d failures", failed)
+		}
+	}
+	priorities := func() (int, int) {
+		gotA, _ := testStore.GetIssue(ctx, a.ID)
+		gotB, _ := testStore.GetIssue(ctx, b.ID)
+		return gotA.Priority, gotB.Priority
+	}
+
+	undo("undo-1")
+	if pa, pb := priorities(); pa != 1 || pb != 2 {
+		t.Errorf("after undo: A=P%d B=P%d, want A=P1 B=P2", pa, pb)
+	}
+
+	// Running undo again right away redoes it
+	undo("undo-2")
+	if pa, pb := priorities(); pa != 1 || pb != 0 {
+		t.Errorf("after redo: A=P%d B=P%d, want A=P1 B=P0", pa, pb)
+	}
+}
@@ -105,6 +105,17 @@ create, update, show, or close operation).`,
 			}
 			updates["estimated_minutes"] = estimate
 		}
+		if cmd.Flags().Changed("budget") {
+			budget, _ := cmd.Flags().GetFloat64("budget")
+			if budget < 0 {
+				FatalErrorRespectJSON("budget must be a non-negative amount in USD")
+			}
+			if budget == 0 {
+				updates["budget_usd"] = nil
+			} else {
+				updates["budget_usd"] = budget
+			}
+		}
 		if cmd.Flags().Changed("type") {
 			issueType, _ := cmd.Flags().GetString("type")
 			// Validate issue type
@@ -249,6 +260,10 @@ create, update, show, or close operation).`,
 				if estimate, ok := updates["estimated_minutes"].(int); ok {
 					updateArgs.EstimatedMinutes = &estimate
 				}
+				if cmd.Flags().Changed("budget") {
+					budget, _ := cmd.Flags().GetFloat64("budget")
+					updateArgs.BudgetUSD = &budget
+				}
 				if issueType, ok := updates["issue_type"].(string); ok {
 					updateArgs.IssueType = &issueType
 				}
@@ -291,6 +306,12 @@ create, update, show, or close operation).`,
 
 				// Set claim flag for atomic claim operation
 				updateArgs.Claim = claimFlag
+				if claimFlag {
+					if err := checkClaimBudget(ctx, id); err != nil {
+						fmt.Fprintf(os.Stderr, "Error claiming %s: %v\n", id, err)
+						continue
+					}
+				}
 
 				resp, err := daemonClient.Update(updateArgs)
 				if err != nil {
@@ -464,6 +485,14 @@ create, update, show, or close operation).`,
 					result.Close()
 					continue
 				}
+				// Budgets are tracked in the local audit log, so only local issues are checked
+				if !result.Routed {
+					if err := checkClaimBudget(ctx, result.ResolvedID); err != nil {
+						fmt.Fprintf(os.Stderr, "Error claiming %s: %v\n", id, err)
+						result.Close()
+						continue
+					}
+				}
 				// Atomically set assignee and status
 				claimUpdates := map[string]interface{}{
 					"assignee": actor,
@@ -604,6 +633,7 @@ func init() {
 	updateCmd.Flags().String("acceptance-criteria", "", "DEPRECATED: use --acceptance")
 	_ = updateCmd.Flags().MarkHidden("acceptance-criteria") // Only fails if flag missing (caught in tests)
 	updateCmd.Flags().IntP("estimate", "e", 0, "Time estimate in minutes (e.g., 60 for 1 hour)")
+	updateCmd.Flags().Float64("budget", 0, "Spend limit in USD for this issue and its descendants (0 clears it)")
 	updateCmd.Flags().StringSlice("add-label", nil, "Add labels (repeatable)")
 	updateCmd.Flags().StringSlice("remove-label", nil, "Remove labels (repeatable)")
 	updateCmd.Flags().StringSlice("set-labels", nil, "Set labels, replacing all existing (repeatable)")
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/steveyegge/beads/internal/audit"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/sqlite"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// issueUsage rolls audit log usage up the parent-child tree.
+type issueUsage struct {
+	byIssue  map[string]*types.Usage
+	children map[string][]string // parent ID -> child IDs
+	parents  map[string][]string // child ID -> parent IDs
+}
+
+// rollup returns the usage of id and all of its descendants, or nil when
+// none was recorded.
+func (u *issueUsage) rollup(id string) *types.Usage {
+	if u == nil {
+		return nil
+	}
+	total := audit.Rollup(u.byIssue, u.children, id)
+	if total.Calls == 0 {
+		return nil
+	}
+	return total
+}
+
+// loadIssueUsage reads usage from the audit log and the parent-child graph
+// from s. It returns nil when the log has no usage, so callers can skip
+// rollups without touching the database.
+func loadIssueUsage(ctx context.Context, s storage.Storage) (*issueUsage, error) {
+	path, err := audit.Path()
+	if err != nil {
+		return nil, nil
+	}
+	entries, err := audit.ReadAll(path)
+	if err != nil {
+		return nil, err
+	}
+	byIssue := audit.UsageByIssue(entries)
+	if len(byIssue) == 0 {
+		return nil, nil
+	}
+
+	deps, err := s.GetAllDependencyRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dependencies: %w", err)
+	}
+	u := &issueUsage{byIssue: byIssue, children: make(map[string][]string), parents: make(map[string][]string)}
+	for _, records := range deps {
+		for _, dep := range records {
+			if dep.Type == types.DepParentChild {
+				u.children[dep.DependsOnID] = append(u.children[dep.DependsOnID], dep.IssueID)
+				u.parents[dep.IssueID] = append(u.parents[dep.IssueID], dep.DependsOnID)
+			}
+		}
+	}
+	return u, nil
+}
+
+// openReadStore returns a store for lookups the daemon has no RPC for: the
+// direct store if there is one, otherwise a read-only connection alongside
+// the daemon.
+func openReadStore(ctx context.Context) (storage.Storage, func(), error) {
+	if store != nil {
+		return store, func() {}, nil
+	}
+	if dbPath == "" {
+		return nil, nil, fmt.Errorf("no database connection")
+	}
+	s, err := sqlite.NewReadOnly(ctx, dbPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return s, func() { _ = s.Close() }, nil
+}
+
+// loadUsageForDisplay is loadIssueUsage for read paths such as bd show:
+// usage is informational there, so failures leave it out.
+func loadUsageForDisplay(ctx context.Context) *issueUsage {
+	s, closeStore, err := openReadStore(ctx)
+	if err != nil {
+		return nil
+	}
+	defer closeStore()
+	u, _ := loadIssueUsage(ctx, s)
+	return u
+}
+
+// budgetViolation is an issue whose rolled-up spend exceeds its budget.
+type budgetViolation struct {
+	Issue *types.Issue
+	Usage *types.Usage
+}
+
+func (v budgetViolation) String() string {
+	return fmt.Sprintf("%s has spent $%.2f of its $%.2f budget", v.Issue.ID, v.Usage.CostUSD, *v.Issue.BudgetUSD)
+}
+
+// findBudgetViolations checks id and each of its ancestors for a budget its
+// subtree has exceeded, nearest first.
+func findBudgetViolations(ctx context.Context, s storage.Storage, u *issueUsage, id string) ([]budgetViolation, error) {
+	if u == nil {
+		return nil, nil
+	}
+	var violations []budgetViolation
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		issue, err := s.GetIssue(ctx, current)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", current, err)
+		}
+		if issue != nil && issue.BudgetUSD != nil {
+			if usage := u.rollup(current); usage.OverBudget(issue) {
+				violations = append(violations, budgetViolation{Issue: issue, Usage: usage})
+			}
+		}
+		for _, parent := range u.parents[current] {
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// checkClaimBudget applies budget.enforcement before id is claimed. It warns
+// about exceeded budgets above the issue and, in "block" mode, returns an
+// error so the claim is refused.
+func checkClaimBudget(ctx context.Context, id string) error {
+	s, closeStore, err := openReadStore(ctx)
+	if err != nil {
+		return budgetCheckFailed(err)
+	}
+	defer closeStore()
+	return checkStoreClaimBudget(ctx, s, id)
+}
+
+// checkStoreClaimBudget is checkClaimBudget against an already open store,
+// for commands such as swarm run that claim work on a store they were given.
+func checkStoreClaimBudget(ctx context.Context, s storage.Storage, id string) error {
+	u, err := loadIssueUsage(ctx, s)
+	if err != nil {
+		return budgetCheckFailed(err)
+	}
+	violations, err := findBudgetViolations(ctx, s, u, id)
+	if err != nil {
+		return budgetCheckFailed(err)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	if budgetBlocks() {
+		return fmt.Errorf("budget exceeded: %s (budget.enforcement=block)", violations[0])
+	}
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "%s Budget exceeded: %s\n", ui.RenderWarn("!"), v)
+	}
+	return nil
+}
+
+// budgetBlocks reports whether exceeded budgets refuse claims.
+func budgetBlocks() bool {
+	return config.GetString("budget.enforcement") == "block"
+}
+
+// budgetCheckFailed handles a budget check that could not run. Block mode
+// fails closed and refuses the claim; otherwise the claim goes ahead with a
+// warning.
+func budgetCheckFailed(err error) error {
+	if budgetBlocks() {
+		return fmt.Errorf("could not check budgets (budget.enforcement=block): %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "%s could not check budgets: %v\n", ui.RenderWarn("!"), err)
+	return nil
+}
+
+// printIssueUsage prints the budget and rolled-up usage lines of bd show.
+func printIssueUsage(issue *types.Issue, u *types.Usage) {
+	if issue.BudgetUSD != nil {
+		fmt.Printf("Budget: %s\n", formatBudget(issue, u))
+	}
+	if u != nil {
+		fmt.Printf("Usage: %s\n", formatUsage(u))
+	}
+}
+
+// formatUsage renders usage as a single line for human output.
+func formatUsage(u *types.Usage) string {
+	s := fmt.Sprintf("$%.2f, %s in / %s out tokens, %d calls",
+		u.CostUSD, formatTokenCount(u.InputTokens), formatTokenCount(u.OutputTokens), u.Calls)
+	if u.DurationMs > 0 {
+		s += fmt.Sprintf(", %.1fs", float64(u.DurationMs)/1000)
+	}
+	return s
+}
+
+// formatBudget renders spend against a budget, flagging overruns.
+func formatBudget(issue *types.Issue, u *types.Usage) string {
+	spent := 0.0
+	if u != nil {
+		spent = u.CostUSD
+	}
+	s := fmt.Sprintf("$%.2f of $%.2f", spent, *issue.BudgetUSD)
+	if u.OverBudget(issue) {
+		return ui.RenderFail(s + " (exceeded)")
+	}
+	return s
+}
+
+// formatTokenCount abbreviates token counts (1.2k, 3.4M).
+func formatTokenCount(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
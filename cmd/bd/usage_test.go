@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestFindBudgetViolations(t *testing.T) {
+	tmpDir := t.TempDir()
+	s := newTestStore(t, filepath.Join(tmpDir, ".beads", "beads.db"))
+	ctx := context.Background()
+
+	budget := 1.0
+	epic := &types.Issue{Title: "epic", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeEpic, BudgetUSD: &budget}
+	child := &types.Issue{Title: "child", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	next := &types.Issue{Title: "next", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	for _, issue := range []*types.Issue{epic, child, next} {
+		if err := s.CreateIssue(ctx, issue, "test"); err != nil {
+			t.Fatalf("CreateIssue: %v", err)
+		}
+	}
+	for _, issue := range []*types.Issue{child, next} {
+		dep := &types.Dependency{IssueID: issue.ID, DependsOnID: epic.ID, Type: types.DepParentChild}
+		if err := s.AddDependency(ctx, dep, "test"); err != nil {
+			t.Fatalf("AddDependency: %v", err)
+		}
+	}
+
+	u := &issueUsage{
+		byIssue:  map[string]*types.Usage{child.ID: {Calls: 2, CostUSD: 0.75}},
+		children: map[string][]string{epic.ID: {child.ID, next.ID}},
+		parents:  map[string][]string{child.ID: {epic.ID}, next.ID: {epic.ID}},
+	}
+	violations, err := findBudgetViolations(ctx, s, u, next.ID)
+	if err != nil {
+		t.Fatalf("findBudgetViolations: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("violations = %v, want none under budget", violations)
+	}
+
+	u.byIssue[epic.ID] = &types.Usage{Calls: 1, CostUSD: 0.5}
+	violations, err = findBudgetViolations(ctx, s, u, next.ID)
+	if err != nil {
+		t.Fatalf("findBudgetViolations: %v", err)
+	}
+	if len(violations) != 1 || violations[0].Issue.ID != epic.ID || violations[0].Usage.CostUSD != 1.25 {
+		t.Errorf("violations = %v, want the epic at $1.25", violations)
+	}
+}
+
+// setBudgetEnforcement sets budget.enforcement for the duration of a test.
+func setBudgetEnforcement(t *testing.T, mode string) {
+	t.Helper()
+	if err := config.Initialize(); err != nil {
+		t.Fatalf("config.Initialize: %v", err)
+	}
+	old := config.GetString("budget.enforcement")
+	config.Set("budget.enforcement", mode)
+	t.Cleanup(func() { config.Set("budget.enforcement", old) })
+}
+
+func TestCheckClaimBudget_NoStore(t *testing.T) {
+	oldStore, oldDBPath := store, dbPath
+	store, dbPath = nil, ""
+	defer func() { store, dbPath = oldStore, oldDBPath }()
+	ctx := context.Background()
+
+	setBudgetEnforcement(t, "warn")
+	if err := checkClaimBudget(ctx, "bd-1"); err != nil {
+		t.Errorf("warn mode: checkClaimBudget = %v, want nil", err)
+	}
+
+	// Block mode refuses claims it cannot check
+	setBudgetEnforcement(t, "block")
+	if err := checkClaimBudget(ctx, "bd-1"); err == nil {
+		t.Error("block mode: checkClaimBudget = nil, want error without a store")
+	}
+}
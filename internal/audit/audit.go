@@ -40,6 +40,12 @@ type Entry struct {
 	ToolName string `json:"tool_name,omitempty"`
 	ExitCode *int   `json:"exit_code,omitempty"`
 
+	// Usage (llm_call/tool_call). Rolled up per issue by UsageByIssue.
+	InputTokens  int64   `json:"input_tokens,omitempty"`
+	OutputTokens int64   `json:"output_tokens,omitempty"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+	DurationMs   int64   `json:"duration_ms,omitempty"`
+
 	// Labeling (append-only)
 	ParentID string `json:"parent_id,omitempty"`
 	Label    string `json:"label,omitempty"`  // "good" | "bad" | etc
@@ -0,0 +1,312 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// Entry kinds with special meaning in reports and datasets.
+const (
+	KindLLMCall  = "llm_call"
+	KindToolCall = "tool_call"
+	KindLabel    = "label"
+)
+
+// ReadAll reads every entry of an interactions log. A missing file is an
+// empty log.
+func ReadAll(path string) ([]*Entry, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is the .beads interactions log
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open interactions log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []*Entry
+	dec := json.NewDecoder(f)
+	for {
+		var e Entry
+		if err := dec.Decode(&e); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse interactions log entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, nil
+}
+
+// LatestLabels maps each labeled entry ID to its most recent label entry.
+// Labels are append-only, so relabeling an entry supersedes earlier labels.
+func LatestLabels(entries []*Entry) map[string]*Entry {
+	labels := make(map[string]*Entry)
+	for _, e := range entries {
+		if e.Kind != KindLabel || e.ParentID == "" {
+			continue
+		}
+		if prev, ok := labels[e.ParentID]; !ok || !e.CreatedAt.Before(prev.CreatedAt) {
+			labels[e.ParentID] = e
+		}
+	}
+	return labels
+}
+
+// Report aggregates the interactions log and the issue event history.
+type Report struct {
+	Since  *time.Time     `json:"since,omitempty"`
+	Actors []*ActorStats  `json:"actors"`
+	Models []*ModelStats  `json:"models"`
+	Totals *InteractStats `json:"totals"`
+}
+
+// InteractStats counts LLM and tool calls and how they were labeled.
+type InteractStats struct {
+	LLMCalls  int            `json:"llm_calls"`
+	ToolCalls int            `json:"tool_calls"`
+	Errors    int            `json:"errors"`
+	ErrorRate float64        `json:"error_rate"`
+	Labels    map[string]int `json:"labels,omitempty"`
+	GoodRatio *float64       `json:"good_ratio,omitempty"` // good / (good + bad); nil without such labels
+}
+
+// ActorStats is the per-actor section of a report.
+type ActorStats struct {
+	Actor string `json:"actor"`
+	InteractStats
+	Claimed int `json:"claimed"`
+	Closed  int `json:"closed"`
+	// MedianClaimToClose is the median time from an actor's last claim of an
+	// issue to the actor closing it, in seconds. Nil when nothing was closed
+	// after a claim.
+	MedianClaimToClose *float64 `json:"median_claim_to_close_seconds,omitempty"`
+	Reopened           int      `json:"reopened"` // Issues the actor closed that were reopened later
+	ReopenRate         float64  `json:"reopen_rate"`
+}
+
+// ModelStats is the per-model section of a report.
+type ModelStats struct {
+	Model string `json:"model"`
+	InteractStats
+}
+
+// BuildReport aggregates audit entries and issue events at or after since
+// (the zero time means everything). events may come in any order.
+func BuildReport(entries []*Entry, events []*types.Event, since time.Time) *Report {
+	report := &Report{Actors: []*ActorStats{}, Models: []*ModelStats{}, Totals: &InteractStats{}}
+	if !since.IsZero() {
+		report.Since = &since
+	}
+	actors := make(map[string]*ActorStats)
+	actorFor := func(name string) *ActorStats {
+		if name == "" {
+			name = "(unknown)"
+		}
+		if a, ok := actors[name]; ok {
+			return a
+		}
+		a := &ActorStats{Actor: name}
+		actors[name] = a
+		report.Actors = append(report.Actors, a)
+		return a
+	}
+	models := make(map[string]*ModelStats)
+	modelFor := func(name string) *ModelStats {
+		if m, ok := models[name]; ok {
+			return m
+		}
+		m := &ModelStats{Model: name}
+		models[name] = m
+		report.Models = append(report.Models, m)
+		return m
+	}
+
+	labels := LatestLabels(entries)
+	for _, e := range entries {
+		if e.CreatedAt.Before(since) || (e.Kind != KindLLMCall && e.Kind != KindToolCall) {
+			continue
+		}
+		targets := []*InteractStats{report.Totals, &actorFor(e.Actor).InteractStats}
+		if e.Kind == KindLLMCall && e.Model != "" {
+			targets = append(targets, &modelFor(e.Model).InteractStats)
+		}
+		failed := e.Error != "" || (e.ExitCode != nil && *e.ExitCode != 0)
+		for _, s := range targets {
+			if e.Kind == KindLLMCall {
+				s.LLMCalls++
+			} else {
+				s.ToolCalls++
+			}
+			if failed {
+				s.Errors++
+			}
+			if l, ok := labels[e.ID]; ok {
+				if s.Labels == nil {
+					s.Labels = make(map[string]int)
+				}
+				s.Labels[l.Label]++
+			}
+		}
+	}
+
+	addLifecycle(events, since, actorFor)
+
+	for _, s := range append([]*InteractStats{report.Totals}, interactStats(report)...) {
+		s.finish()
+	}
+	for _, a := range report.Actors {
+		if a.Closed > 0 {
+			a.ReopenRate = float64(a.Reopened) / float64(a.Closed)
+		}
+	}
+	sort.Slice(report.Actors, func(i, j int) bool { return report.Actors[i].Actor < report.Actors[j].Actor })
+	sort.Slice(report.Models, func(i, j int) bool { return report.Models[i].Model < report.Models[j].Model })
+	return report
+}
+
+func interactStats(r *Report) []*InteractStats {
+	var all []*InteractStats
+	for _, a := range r.Actors {
+		all = append(all, &a.InteractStats)
+	}
+	for _, m := range r.Models {
+		all = append(all, &m.InteractStats)
+	}
+	return all
+}
+
+func (s *InteractStats) finish() {
+	if calls := s.LLMCalls + s.ToolCalls; calls > 0 {
+		s.ErrorRate = float64(s.Errors) / float64(calls)
+	}
+	if judged := s.Labels["good"] + s.Labels["bad"]; judged > 0 {
+		ratio := float64(s.Labels["good"]) / float64(judged)
+		s.GoodRatio = &ratio
+	}
+}
+
+// addLifecycle replays each issue's events in order, crediting claims,
+// closes and later reopens to the actors involved.
+func addLifecycle(events []*types.Event, since time.Time, actorFor func(string) *ActorStats) {
+	byIssue := make(map[string][]*types.Event)
+	for _, e := range events {
+		byIssue[e.IssueID] = append(byIssue[e.IssueID], e)
+	}
+
+	durations := make(map[*ActorStats][]time.Duration)
+	for _, history := range byIssue {
+		sort.Slice(history, func(i, j int) bool {
+			if !history[i].CreatedAt.Equal(history[j].CreatedAt) {
+				return history[i].CreatedAt.Before(history[j].CreatedAt)
+			}
+			return history[i].ID < history[j].ID
+		})
+
+		var claimedAt time.Time
+		var lastCloser *ActorStats
+		for _, e := range history {
+			inWindow := !e.CreatedAt.Before(since)
+			switch {
+			case isClaim(e):
+				claimedAt = e.CreatedAt
+				if inWindow {
+					actorFor(e.Actor).Claimed++
+				}
+			case e.EventType == types.EventClosed:
+				lastCloser = nil
+				if !inWindow {
+					claimedAt = time.Time{}
+					continue
+				}
+				closer := actorFor(e.Actor)
+				closer.Closed++
+				lastCloser = closer
+				if !claimedAt.IsZero() {
+					durations[closer] = append(durations[closer], e.CreatedAt.Sub(claimedAt))
+				}
+				claimedAt = time.Time{}
+			case e.EventType == types.EventReopened:
+				if lastCloser != nil {
+					lastCloser.Reopened++
+					lastCloser = nil
+				}
+			}
+		}
+	}
+
+	for a, ds := range durations {
+		median := medianDuration(ds).Seconds()
+		a.MedianClaimToClose = &median
+	}
+}
+
+// isClaim reports whether an event moved an issue to in_progress.
+func isClaim(e *types.Event) bool {
+	if e.EventType != types.EventStatusChanged || e.NewValue == nil {
+		return false
+	}
+	var updates map[string]any
+	if err := json.Unmarshal([]byte(*e.NewValue), &updates); err != nil {
+		return false
+	}
+	return updates["status"] == string(types.StatusInProgress)
+}
+
+func medianDuration(ds []time.Duration) time.Duration {
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	mid := len(ds) / 2
+	if len(ds)%2 == 1 {
+		return ds[mid]
+	}
+	return (ds[mid-1] + ds[mid]) / 2
+}
+
+// DatasetRecord is one labeled example of a dataset export.
+type DatasetRecord struct {
+	ID        string    `json:"id"`
+	Model     string    `json:"model,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	IssueID   string    `json:"issue_id,omitempty"`
+	Prompt    string    `json:"prompt"`
+	Response  string    `json:"response"`
+	Label     string    `json:"label"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Dataset returns the labeled LLM calls at or after since as prompt/response/
+// label records, oldest first. If onlyLabel is set, other labels are skipped.
+func Dataset(entries []*Entry, since time.Time, onlyLabel string) []*DatasetRecord {
+	labels := LatestLabels(entries)
+	records := []*DatasetRecord{}
+	for _, e := range entries {
+		if e.Kind != KindLLMCall || e.CreatedAt.Before(since) {
+			continue
+		}
+		l, ok := labels[e.ID]
+		if !ok || (onlyLabel != "" && l.Label != onlyLabel) {
+			continue
+		}
+		records = append(records, &DatasetRecord{
+			ID:        e.ID,
+			Model:     e.Model,
+			Actor:     e.Actor,
+			IssueID:   e.IssueID,
+			Prompt:    e.Prompt,
+			Response:  e.Response,
+			Label:     l.Label,
+			Reason:    l.Reason,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CreatedAt.Before(records[j].CreatedAt) })
+	return records
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func statusEvent(id int64, issueID, actor string, typ types.EventType, newValue string, at time.Time) *types.Event {
+	e := &types.Event{ID: id, IssueID: issueID, Actor: actor, EventType: typ, CreatedAt: at}
+	if newValue != "" {
+		e.NewValue = &newValue
+	}
+	return e
+}
+
+func TestBuildReport(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	exit1 := 1
+	entries := []*Entry{
+		{ID: "int-1", Kind: KindLLMCall, Actor: "ann", Model: "m-large", CreatedAt: t0},
+		{ID: "int-2", Kind: KindLLMCall, Actor: "ann", Model: "m-large", Error: "timeout", CreatedAt: t0},
+		{ID: "int-3", Kind: KindToolCall, Actor: "ann", ToolName: "go test", ExitCode: &exit1, CreatedAt: t0},
+		{ID: "int-4", Kind: KindLLMCall, Actor: "bob", Model: "m-small", CreatedAt: t0},
+		{ID: "int-5", Kind: KindLabel, ParentID: "int-1", Label: "bad", CreatedAt: t0.Add(time.Minute)},
+		{ID: "int-6", Kind: KindLabel, ParentID: "int-1", Label: "good", CreatedAt: t0.Add(2 * time.Minute)},
+		{ID: "int-7", Kind: KindLabel, ParentID: "int-4", Label: "bad", CreatedAt: t0.Add(time.Minute)},
+		{ID: "int-0", Kind: KindLLMCall, Actor: "ann", Model: "m-large", CreatedAt: t0.Add(-48 * time.Hour)},
+	}
+	claim := `{"status":"in_progress","assignee":"ann"}`
+	events := []*types.Event{
+		// bd-1: claimed and closed by ann after 1h, then reopened
+		statusEvent(1, "bd-1", "ann", types.EventStatusChanged, claim, t0),
+		statusEvent(2, "bd-1", "ann", types.EventClosed, "", t0.Add(time.Hour)),
+		statusEvent(3, "bd-1", "bob", types.EventReopened, `{"status":"open"}`, t0.Add(2*time.Hour)),
+		// bd-2: claimed and closed by ann after 3h
+		statusEvent(5, "bd-2", "ann", types.EventClosed, "", t0.Add(3*time.Hour)),
+		statusEvent(4, "bd-2", "ann", types.EventStatusChanged, claim, t0),
+		// bd-3: closed by bob without a claim
+		statusEvent(6, "bd-3", "bob", types.EventClosed, "", t0),
+	}
+
+	r := BuildReport(entries, events, t0.Add(-time.Hour))
+
+	if len(r.Actors) != 2 || r.Actors[0].Actor != "ann" || r.Actors[1].Actor != "bob" {
+		t.Fatalf("actors = %+v, want ann and bob", r.Actors)
+	}
+	ann, bob := r.Actors[0], r.Actors[1]
+	if ann.LLMCalls != 2 || ann.ToolCalls != 1 || ann.Errors != 2 {
+		t.Errorf("ann calls llm=%d tool=%d errors=%d, want 2/1/2 (int-0 is before --since)", ann.LLMCalls, ann.ToolCalls, ann.Errors)
+	}
+	if ann.GoodRatio == nil || *ann.GoodRatio != 1 || ann.Labels["good"] != 1 || ann.Labels["bad"] != 0 {
+		t.Errorf("ann labels = %v ratio = %v, want the latest label (good) only", ann.Labels, ann.GoodRatio)
+	}
+	if ann.Claimed != 2 || ann.Closed != 2 || ann.Reopened != 1 || ann.ReopenRate != 0.5 {
+		t.Errorf("ann claimed=%d closed=%d reopened=%d rate=%v, want 2/2/1/0.5", ann.Claimed, ann.Closed, ann.Reopened, ann.ReopenRate)
+	}
+	if ann.MedianClaimToClose == nil || *ann.MedianClaimToClose != (2*time.Hour).Seconds() {
+		t.Errorf("ann median claim-to-close = %v, want 2h", ann.MedianClaimToClose)
+	}
+	if bob.Closed != 1 || bob.MedianClaimToClose != nil {
+		t.Errorf("bob closed=%d median=%v, want 1 close with no claim time", bob.Closed, bob.MedianClaimToClose)
+	}
+	if bob.GoodRatio == nil || *bob.GoodRatio != 0 {
+		t.Errorf("bob good ratio = %v, want 0", bob.GoodRatio)
+	}
+
+	if len(r.Models) != 2 || r.Models[0].Model != "m-large" || r.Models[0].LLMCalls != 2 || r.Models[0].ErrorRate != 0.5 {
+		t.Errorf("models = %+v, want m-large with 2 calls and 50%% errors first", r.Models)
+	}
+	if r.Totals.LLMCalls != 3 || r.Totals.ToolCalls != 1 {
+		t.Errorf("totals = %+v, want 3 LLM calls and 1 tool call", r.Totals)
+	}
+}
+
+func TestDataset(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []*Entry{
+		{ID: "int-1", Kind: KindLLMCall, Model: "m", Prompt: "p1", Response: "r1", CreatedAt: t0},
+		{ID: "int-2", Kind: KindLLMCall, Model: "m", Prompt: "p2", Response: "r2", CreatedAt: t0},
+		{ID: "int-3", Kind: KindLLMCall, Model: "m", Prompt: "p3", Response: "r3", CreatedAt: t0},
+		{ID: "int-4", Kind: KindLabel, ParentID: "int-1", Label: "good", Reason: "correct", CreatedAt: t0},
+		{ID: "int-5", Kind: KindLabel, ParentID: "int-2", Label: "bad", CreatedAt: t0},
+	}
+
+	all := Dataset(entries, time.Time{}, "")
+	if len(all) != 2 || all[0].ID != "int-1" || all[0].Label != "good" || all[0].Reason != "correct" || all[0].Prompt != "p1" {
+		t.Fatalf("dataset = %+v, want the two labeled calls", all)
+	}
+	good := Dataset(entries, time.Time{}, "good")
+	if len(good) != 1 || good[0].ID != "int-1" {
+		t.Errorf("good dataset = %+v, want only int-1", good)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	dir := t.TempDir()
+	if entries, err := ReadAll(filepath.Join(dir, FileName)); err != nil || len(entries) != 0 {
+		t.Fatalf("missing log: entries=%v err=%v, want empty", entries, err)
+	}
+
+	p := filepath.Join(dir, FileName)
+	data := `{"id":"int-1","kind":"llm_call","created_at":"2025-03-01T12:00:00Z"}
+{"id":"int-2","kind":"label","parent_id":"int-1","label":"good","created_at":"2025-03-01T12:01:00Z"}
+`
+	if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadAll(p)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(entries) != 2 || entries[1].ParentID != "int-1" {
+		t.Errorf("entries = %+v, want 2 with the label second", entries)
+	}
+
+	if err := os.WriteFile(p, []byte(data+"{not json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadAll(p); err == nil {
+		t.Error("expected an error for a malformed line")
+	}
+}
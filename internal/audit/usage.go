@@ -0,0 +1,52 @@
+package audit
+
+import "github.com/steveyegge/beads/internal/types"
+
+// HasUsage reports whether the entry carries any token, cost or duration data.
+func (e *Entry) HasUsage() bool {
+	return e.InputTokens != 0 || e.OutputTokens != 0 || e.CostUSD != 0 || e.DurationMs != 0
+}
+
+// UsageByIssue sums the usage recorded on LLM and tool calls per IssueID.
+// Entries without an issue or without usage are ignored.
+func UsageByIssue(entries []*Entry) map[string]*types.Usage {
+	byIssue := make(map[string]*types.Usage)
+	for _, e := range entries {
+		if e.IssueID == "" || (e.Kind != KindLLMCall && e.Kind != KindToolCall) || !e.HasUsage() {
+			continue
+		}
+		u, ok := byIssue[e.IssueID]
+		if !ok {
+			u = &types.Usage{}
+			byIssue[e.IssueID] = u
+		}
+		u.Add(&types.Usage{
+			Calls:        1,
+			InputTokens:  e.InputTokens,
+			OutputTokens: e.OutputTokens,
+			CostUSD:      e.CostUSD,
+			DurationMs:   e.DurationMs,
+		})
+	}
+	return byIssue
+}
+
+// Rollup sums the usage of root and every issue reachable from it through
+// children (parent ID -> child IDs). Cycles are tolerated.
+func Rollup(byIssue map[string]*types.Usage, children map[string][]string, root string) *types.Usage {
+	total := &types.Usage{}
+	seen := map[string]bool{root: true}
+	queue := []string{root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		total.Add(byIssue[id])
+		for _, child := range children[id] {
+			if !seen[child] {
+				seen[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,36 @@
+package audit
+
+import "testing"
+
+func TestUsageRollup(t *testing.T) {
+	entries := []*Entry{
+		{Kind: KindLLMCall, IssueID: "bd-1", InputTokens: 100, OutputTokens: 10, CostUSD: 0.5, DurationMs: 1000},
+		{Kind: KindLLMCall, IssueID: "bd-1.1", InputTokens: 200, OutputTokens: 20, CostUSD: 1.25},
+		{Kind: KindToolCall, IssueID: "bd-1.1.1", DurationMs: 3000},
+		{Kind: KindLLMCall, IssueID: "bd-2", CostUSD: 9},
+		{Kind: KindLLMCall, IssueID: "bd-1"},             // no usage
+		{Kind: KindLabel, IssueID: "bd-1", CostUSD: 4},   // labels are not calls
+		{Kind: KindLLMCall, InputTokens: 50, CostUSD: 4}, // no issue
+	}
+
+	byIssue := UsageByIssue(entries)
+	if len(byIssue) != 4 {
+		t.Fatalf("byIssue has %d issues, want 4", len(byIssue))
+	}
+	if u := byIssue["bd-1"]; u == nil || u.Calls != 1 || u.InputTokens != 100 {
+		t.Errorf("bd-1 usage = %+v, want 1 call with 100 input tokens", u)
+	}
+
+	children := map[string][]string{
+		"bd-1":     {"bd-1.1"},
+		"bd-1.1":   {"bd-1.1.1", "bd-1"}, // cycle back to the root
+		"bd-1.1.1": nil,
+	}
+	u := Rollup(byIssue, children, "bd-1")
+	if u.Calls != 3 || u.InputTokens != 300 || u.OutputTokens != 30 || u.CostUSD != 1.75 || u.DurationMs != 4000 {
+		t.Errorf("rollup = %+v, want 3 calls, 300/30 tokens, $1.75, 4000ms", u)
+	}
+	if u := Rollup(byIssue, children, "bd-9"); u.Calls != 0 {
+		t.Errorf("rollup of unknown issue = %+v, want empty", u)
+	}
+}
@@ -411,6 +411,11 @@ func hasBeadsProjectFiles(beadsDir string) bool {
 		return true
 	}
 
+	// Check for jj-turso task files (tasks/*.json without a JSONL file)
+	if info, err := os.Stat(filepath.Join(beadsDir, "tasks")); err == nil && info.IsDir() {
+		return true
+	}
+
 	return false
 }
 
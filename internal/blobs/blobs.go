@@ -0,0 +1,246 @@
+// Package blobs implements the content-addressed store that holds issue
+// attachment contents.
+//
+// Blobs live under .beads/blobs/<sha[:2]>/<sha>, named by the SHA256 of their
+// content. Identical files are stored once no matter how many issues attach
+// them, and the attachment records that travel in JSONL only carry the hash.
+// Blobs that no attachment references are removed by GC (run from bd compact).
+package blobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DirName is the blob directory name under .beads/.
+const DirName = "blobs"
+
+// Store is a content-addressed blob directory.
+type Store struct {
+	dir string
+}
+
+// New returns the blob store of a .beads directory. The directory is created
+// on the first Put.
+func New(beadsDir string) *Store {
+	return &Store{dir: filepath.Join(beadsDir, DirName)}
+}
+
+// Dir returns the blob directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// ValidHash reports whether sum looks like a hex SHA256 digest.
+func ValidHash(sum string) bool {
+	if len(sum) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(sum)
+	return err == nil && strings.ToLower(sum) == sum
+}
+
+// Path returns where the blob with the given hash is (or would be) stored.
+func (s *Store) Path(sum string) string {
+	return filepath.Join(s.dir, sum[:2], sum)
+}
+
+// Put copies r into the store and returns its SHA256 and size. Content that
+// is already stored is not written again.
+func (s *Store) Put(r io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return "", 0, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".incoming-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temp blob: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	dest := s.Path(sum)
+	if _, err := os.Stat(dest); err == nil {
+		return sum, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0750); err != nil {
+		return "", 0, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	// #nosec G302 -- blobs are committed alongside issues.jsonl
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return "", 0, fmt.Errorf("failed to set blob permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return "", 0, fmt.Errorf("failed to store blob: %w", err)
+	}
+	return sum, size, nil
+}
+
+// Open opens the blob with the given hash for reading.
+func (s *Store) Open(sum string) (*os.File, error) {
+	if !ValidHash(sum) {
+		return nil, fmt.Errorf("invalid blob hash %q", sum)
+	}
+	f, err := os.Open(s.Path(sum)) // #nosec G304 -- path is derived from a validated hash
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("blob %s is not in %s (not synced, git-ignored, or an un-fetched LFS object?)", sum, s.dir)
+	}
+	return f, err
+}
+
+// Has reports whether the blob with the given hash is stored.
+func (s *Store) Has(sum string) bool {
+	if !ValidHash(sum) {
+		return false
+	}
+	_, err := os.Stat(s.Path(sum))
+	return err == nil
+}
+
+// List returns the hashes of all stored blobs, sorted.
+func (s *Store) List() ([]string, error) {
+	var sums []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.IsDir() && ValidHash(d.Name()) && filepath.Base(filepath.Dir(path)) == d.Name()[:2] {
+			sums = append(sums, d.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blobs: %w", err)
+	}
+	sort.Strings(sums)
+	return sums, nil
+}
+
+// GCResult describes the blobs removed (or, in a dry run, that would be
+// removed) by GC.
+type GCResult struct {
+	Removed []string `json:"removed"`
+	Bytes   int64    `json:"bytes"`
+}
+
+// GC removes every blob whose hash is not in referenced. With dryRun set it
+// only reports what would be removed.
+func (s *Store) GC(referenced map[string]bool, dryRun bool) (*GCResult, error) {
+	sums, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	result := &GCResult{Removed: []string{}}
+	for _, sum := range sums {
+		if referenced[sum] {
+			continue
+		}
+		path := s.Path(sum)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("failed to remove blob %s: %w", sum, err)
+			}
+			// Drop the fan-out directory once it is empty
+			_ = os.Remove(filepath.Dir(path))
+		}
+		result.Removed = append(result.Removed, sum)
+		result.Bytes += info.Size()
+	}
+	return result, nil
+}
+
+// DetectMimeType guesses a MIME type from the file name, falling back to
+// sniffing the first bytes of content.
+func DetectMimeType(name string, head []byte) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); t != "" {
+		return t
+	}
+	return http.DetectContentType(head)
+}
+
+// Tracking modes for the blob directory (attachments.mode in config.yaml).
+const (
+	ModeCommit = "commit" // Blobs are committed with the rest of .beads/ (default)
+	ModeIgnore = "ignore" // Blobs stay local; only the references are shared
+	ModeLFS    = "lfs"    // Blobs are committed through Git LFS
+)
+
+const (
+	ignoreLine = DirName + "/"
+	lfsLine    = DirName + "/** filter=lfs diff=lfs merge=lfs -text"
+)
+
+// ConfigureTracking makes .beads/.gitignore and .beads/.gitattributes match
+// mode. It only adds or removes the blob lines, leaving the rest of both
+// files alone, and is safe to run repeatedly.
+func ConfigureTracking(beadsDir, mode string) error {
+	switch mode {
+	case "", ModeCommit, ModeIgnore, ModeLFS:
+	default:
+		return fmt.Errorf("invalid attachments mode %q (want %s, %s or %s)", mode, ModeCommit, ModeIgnore, ModeLFS)
+	}
+	if err := setLine(filepath.Join(beadsDir, ".gitignore"), ignoreLine, mode == ModeIgnore); err != nil {
+		return err
+	}
+	return setLine(filepath.Join(beadsDir, ".gitattributes"), lfsLine, mode == ModeLFS)
+}
+
+// setLine adds line to the file at path if want is set, or removes it.
+func setLine(path, line string, want bool) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is under .beads/
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	content := string(data)
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	has := false
+	kept := lines[:0]
+	for _, l := range lines {
+		if strings.TrimSpace(l) == line {
+			has = true
+			continue
+		}
+		kept = append(kept, l)
+	}
+	switch {
+	case want && has, !want && !has:
+		return nil
+	case want:
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += line + "\n"
+	default:
+		content = strings.Join(kept, "\n")
+		if content != "" {
+			content += "\n"
+		}
+	}
+	// #nosec G306 -- .gitignore/.gitattributes are committed
+	return os.WriteFile(path, []byte(content), 0644)
+}
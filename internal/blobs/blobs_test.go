@@ -0,0 +1,127 @@
+package blobs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPutOpenAndDedup(t *testing.T) {
+	s := New(t.TempDir())
+
+	sum, size, err := s.Put(strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	const want = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if sum != want || size != 5 {
+		t.Fatalf("Put = %s, %d; want %s, 5", sum, size, want)
+	}
+	if _, err := os.Stat(filepath.Join(s.Dir(), "2c", want)); err != nil {
+		t.Errorf("blob not stored under its fan-out directory: %v", err)
+	}
+
+	again, _, err := s.Put(strings.NewReader("hello"))
+	if err != nil || again != sum {
+		t.Fatalf("second Put = %s, %v", again, err)
+	}
+	sums, err := s.List()
+	if err != nil || len(sums) != 1 {
+		t.Fatalf("List = %v, %v; want one blob (no temp files)", sums, err)
+	}
+
+	f, err := s.Open(sum)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	data, _ := io.ReadAll(f)
+	if string(data) != "hello" {
+		t.Errorf("Open read %q", data)
+	}
+
+	if _, err := s.Open("../../etc/passwd"); err == nil {
+		t.Error("Open must reject non-hash names")
+	}
+}
+
+func TestGCRemovesUnreferenced(t *testing.T) {
+	s := New(t.TempDir())
+	keep, _, _ := s.Put(strings.NewReader("keep"))
+	drop, _, _ := s.Put(strings.NewReader("drop"))
+
+	preview, err := s.GC(map[string]bool{keep: true}, true)
+	if err != nil {
+		t.Fatalf("GC dry run failed: %v", err)
+	}
+	if len(preview.Removed) != 1 || preview.Removed[0] != drop || preview.Bytes != 4 || !s.Has(drop) {
+		t.Fatalf("dry run = %+v, blob present %v", preview, s.Has(drop))
+	}
+
+	if _, err := s.GC(map[string]bool{keep: true}, false); err != nil {
+		t.Fatalf("GC failed: %v", err)
+	}
+	if s.Has(drop) || !s.Has(keep) {
+		t.Errorf("after GC: has drop %v, has keep %v", s.Has(drop), s.Has(keep))
+	}
+
+	// A missing store is empty, not an error
+	if res, err := New(t.TempDir()).GC(nil, false); err != nil || len(res.Removed) != 0 {
+		t.Errorf("GC of missing store = %+v, %v", res, err)
+	}
+}
+
+func TestConfigureTracking(t *testing.T) {
+	dir := t.TempDir()
+	gitignore := filepath.Join(dir, ".gitignore")
+	gitattributes := filepath.Join(dir, ".gitattributes")
+	if err := os.WriteFile(gitignore, []byte("*.db"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	read := func(path string) string {
+		data, _ := os.ReadFile(path)
+		return string(data)
+	}
+
+	if err := ConfigureTracking(dir, ModeIgnore); err != nil {
+		t.Fatalf("ConfigureTracking(ignore) failed: %v", err)
+	}
+	if err := ConfigureTracking(dir, ModeIgnore); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(gitignore); got != "*.db\nblobs/\n" {
+		t.Errorf(".gitignore = %q", got)
+	}
+
+	if err := ConfigureTracking(dir, ModeLFS); err != nil {
+		t.Fatalf("ConfigureTracking(lfs) failed: %v", err)
+	}
+	if got := read(gitignore); got != "*.db\n" {
+		t.Errorf(".gitignore after lfs = %q", got)
+	}
+	if got := read(gitattributes); got != lfsLine+"\n" {
+		t.Errorf(".gitattributes = %q", got)
+	}
+
+	if err := ConfigureTracking(dir, ModeCommit); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(gitattributes); got != "" {
+		t.Errorf(".gitattributes after commit = %q", got)
+	}
+
+	if err := ConfigureTracking(dir, "bogus"); err == nil {
+		t.Error("invalid mode must fail")
+	}
+}
+
+func TestDetectMimeType(t *testing.T) {
+	if got := DetectMimeType("shot.PNG", nil); got != "image/png" {
+		t.Errorf("png = %q", got)
+	}
+	if got := DetectMimeType("noext", []byte("plain words")); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("sniffed = %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+// Package capabilities resolves cross-project dependencies.
+//
+// A project publishes a capability with a provides:<name> label on a closed
+// issue (bd ship), optionally versioned as provides:<name>@<version>. Other
+// projects depend on it with external:<project>:<name>, optionally
+// constrained as external:<project>:<name>@<constraint> (e.g. api@>=2).
+package capabilities
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// RefPrefix starts every external dependency reference.
+	RefPrefix = "external:"
+	// ProvidesPrefix marks an issue that ships a capability.
+	ProvidesPrefix = "provides:"
+	// ExportPrefix marks the issue that will ship a capability.
+	ExportPrefix = "export:"
+)
+
+// Ref is a parsed external:<project>:<name>[@<constraint>] reference.
+type Ref struct {
+	Raw        string
+	Project    string
+	Name       string
+	Constraint *Constraint // nil = any version
+}
+
+// RefError explains why a reference could not be parsed.
+type RefError struct {
+	Ref    string
+	Reason string
+}
+
+func (e *RefError) Error() string {
+	return fmt.Sprintf("invalid external reference %q: %s", e.Ref, e.Reason)
+}
+
+// ParseRef parses an external dependency reference.
+func ParseRef(ref string) (*Ref, error) {
+	if !strings.HasPrefix(ref, RefPrefix) {
+		return nil, &RefError{Ref: ref, Reason: "not an external reference"}
+	}
+	parts := strings.SplitN(ref, ":", 3)
+	if len(parts) != 3 {
+		return nil, &RefError{Ref: ref, Reason: "invalid format (expected external:project:capability)"}
+	}
+	r := &Ref{Raw: ref, Project: parts[1], Name: parts[2]}
+	if name, constraint, ok := strings.Cut(r.Name, "@"); ok {
+		r.Name = name
+		c, err := ParseConstraint(constraint)
+		if err != nil {
+			return nil, &RefError{Ref: ref, Reason: err.Error()}
+		}
+		r.Constraint = c
+	}
+	if r.Project == "" || r.Name == "" {
+		return nil, &RefError{Ref: ref, Reason: "missing project or capability"}
+	}
+	return r, nil
+}
+
+// String returns the capability with its constraint, e.g. "api@>=2".
+func (r *Ref) String() string {
+	if r.Constraint == nil {
+		return r.Name
+	}
+	return r.Name + "@" + r.Constraint.String()
+}
+
+// ParseLabel parses a provides: or export: label into the capability name
+// and version. ok is false for any other label.
+func ParseLabel(label string) (prefix, name, version string, ok bool) {
+	for _, p := range []string{ProvidesPrefix, ExportPrefix} {
+		if rest, found := strings.CutPrefix(label, p); found && rest != "" {
+			name, version, _ = strings.Cut(rest, "@")
+			return p, name, version, name != ""
+		}
+	}
+	return "", "", "", false
+}
+
+// ProvidesLabel returns the label that ships name at version (unversioned
+// when version is empty).
+func ProvidesLabel(name, version string) string {
+	if version == "" {
+		return ProvidesPrefix + name
+	}
+	return ProvidesPrefix + name + "@" + version
+}
@@ -0,0 +1,391 @@
+package capabilities
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	// Import SQLite driver (same as the sqlite storage backend)
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"golang.org/x/mod/semver"
+
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/configfile"
+)
+
+// Capability is one provides: or export: label in a project.
+type Capability struct {
+	Project string `json:"project"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	IssueID string `json:"issue_id"`
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Shipped bool   `json:"shipped"` // provides: label on a closed issue
+}
+
+// Resolution is the outcome of resolving one external reference.
+type Resolution struct {
+	Ref        string      `json:"ref"`
+	Project    string      `json:"project,omitempty"`
+	Capability string      `json:"capability,omitempty"`
+	Constraint string      `json:"constraint,omitempty"`
+	Satisfied  bool        `json:"satisfied"`
+	Reason     string      `json:"reason"`
+	Provider   *Capability `json:"provider,omitempty"` // Closed issue that satisfied the ref
+}
+
+// Registry resolves external references against other projects' databases.
+// Each database is read once and cached until its file (or WAL) changes, so
+// repeated bd ready/blocked calls don't reopen every external project.
+type Registry struct {
+	cacheDir string // Empty = in-memory cache only
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry // Keyed by database path
+}
+
+type cacheEntry struct {
+	DBPath       string       `json:"db_path"`
+	Fingerprint  string       `json:"fingerprint"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// NewRegistry creates a registry. When cacheDir is non-empty, scanned
+// projects are also cached there so separate bd invocations share them.
+func NewRegistry(cacheDir string) *Registry {
+	return &Registry{cacheDir: cacheDir, entries: make(map[string]*cacheEntry)}
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// Default returns the process-wide registry, cached under
+// ~/.cache/beads/capabilities.
+func Default() *Registry {
+	defaultRegistryOnce.Do(func() {
+		cacheDir := ""
+		if dir, err := os.UserCacheDir(); err == nil {
+			cacheDir = filepath.Join(dir, "beads", "capabilities")
+		}
+		defaultRegistry = NewRegistry(cacheDir)
+	})
+	return defaultRegistry
+}
+
+// ProjectDatabase returns the database path of a project configured in
+// external_projects. On failure, reason explains why it can't be used.
+func ProjectDatabase(project string) (dbPath, reason string) {
+	projectPath := config.ResolveExternalProjectPath(project)
+	if projectPath == "" {
+		return "", "project not configured in external_projects"
+	}
+	beadsDir := filepath.Join(projectPath, ".beads")
+	cfg, err := configfile.Load(beadsDir)
+	if err != nil || cfg == nil {
+		return "", "project has no beads database"
+	}
+	dbPath = cfg.DatabasePath(beadsDir)
+	if _, err := os.Stat(dbPath); err != nil {
+		return "", "database file not found: " + dbPath
+	}
+	return dbPath, ""
+}
+
+// Project returns the capabilities of a configured external project.
+func (r *Registry) Project(ctx context.Context, project string) ([]Capability, error) {
+	dbPath, reason := ProjectDatabase(project)
+	if dbPath == "" {
+		return nil, fmt.Errorf("%s: %s", project, reason)
+	}
+	return r.Load(ctx, project, dbPath)
+}
+
+// Load returns the capabilities in the database at dbPath, rescanning it
+// only if it changed since the last scan.
+func (r *Registry) Load(ctx context.Context, project, dbPath string) ([]Capability, error) {
+	fingerprint, err := fingerprintDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := r.entries[dbPath]
+	if entry == nil {
+		entry = r.readDiskCache(dbPath)
+	}
+	if entry == nil || entry.Fingerprint != fingerprint {
+		caps, err := scanDB(ctx, dbPath)
+		if err != nil {
+			return nil, err
+		}
+		entry = &cacheEntry{DBPath: dbPath, Fingerprint: fingerprint, Capabilities: caps}
+		r.writeDiskCache(entry)
+	}
+	r.entries[dbPath] = entry
+
+	result := make([]Capability, len(entry.Capabilities))
+	for i, c := range entry.Capabilities {
+		c.Project = project
+		result[i] = c
+	}
+	return result, nil
+}
+
+// Invalidate drops every cached project, forcing the next lookup to rescan.
+func (r *Registry) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make(map[string]*cacheEntry)
+}
+
+// Resolve resolves a single external reference.
+func (r *Registry) Resolve(ctx context.Context, ref string) *Resolution {
+	return r.ResolveAll(ctx, []string{ref})[ref]
+}
+
+// ResolveAll resolves external references, reading each project once.
+// Returns a map of ref -> resolution.
+func (r *Registry) ResolveAll(ctx context.Context, refs []string) map[string]*Resolution {
+	results := make(map[string]*Resolution, len(refs))
+	byProject := make(map[string][]*Ref)
+	for _, raw := range refs {
+		if _, done := results[raw]; done {
+			continue
+		}
+		ref, err := ParseRef(raw)
+		if err != nil {
+			results[raw] = &Resolution{Ref: raw, Reason: err.(*RefError).Reason}
+			continue
+		}
+		res := &Resolution{Ref: raw, Project: ref.Project, Capability: ref.Name}
+		if ref.Constraint != nil {
+			res.Constraint = ref.Constraint.String()
+		}
+		results[raw] = res
+		byProject[ref.Project] = append(byProject[ref.Project], ref)
+	}
+
+	for project, projectRefs := range byProject {
+		dbPath, reason := ProjectDatabase(project)
+		var caps []Capability
+		if dbPath != "" {
+			var err error
+			caps, err = r.Load(ctx, project, dbPath)
+			if err != nil {
+				reason = "cannot read project database: " + err.Error()
+			}
+		}
+		for _, ref := range projectRefs {
+			res := results[ref.Raw]
+			if reason != "" {
+				res.Reason = reason
+				continue
+			}
+			match(res, ref, caps)
+		}
+	}
+	return results
+}
+
+// match fills in res from the capabilities a project provides.
+func match(res *Resolution, ref *Ref, caps []Capability) {
+	var shipped []string
+	var pending []string
+	for i := range caps {
+		c := &caps[i]
+		if c.Name != ref.Name {
+			continue
+		}
+		if !c.Shipped {
+			pending = append(pending, fmt.Sprintf("%s is %s", c.IssueID, c.Status))
+			continue
+		}
+		if ref.Constraint == nil || ref.Constraint.Matches(c.Version) {
+			res.Satisfied = true
+			res.Reason = "capability shipped"
+			res.Provider = c
+			return
+		}
+		if c.Version == "" {
+			shipped = append(shipped, "unversioned")
+		} else {
+			shipped = append(shipped, c.Version)
+		}
+	}
+
+	if len(shipped) > 0 {
+		res.Reason = fmt.Sprintf("capability shipped as %s, none satisfies %s", strings.Join(shipped, ", "), ref.Constraint)
+		return
+	}
+	res.Reason = "capability not shipped (no closed issue with " + ProvidesPrefix + ref.Name + " label)"
+	if len(pending) > 0 {
+		res.Reason += "; " + strings.Join(pending, ", ")
+	}
+}
+
+// scanDB reads every provides: and export: label from a beads database.
+func scanDB(ctx context.Context, dbPath string) ([]Capability, error) {
+	// Regular (not read-only) mode so WAL-mode databases can be read
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT i.id, i.title, i.status, l.label FROM labels l
+		JOIN issues i ON i.id = l.issue_id
+		WHERE i.status != 'tombstone'
+		  AND ((l.label >= 'provides:' AND l.label < 'provides;')
+		    OR (l.label >= 'export:' AND l.label < 'export;'))
+		ORDER BY i.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var caps []Capability
+	var id, title, status string
+	var labels []string
+	for rows.Next() {
+		var rowID, rowTitle, rowStatus, label string
+		if err := rows.Scan(&rowID, &rowTitle, &rowStatus, &label); err != nil {
+			return nil, err
+		}
+		if rowID != id {
+			caps = append(caps, FromIssue(id, title, status, labels)...)
+			id, title, status, labels = rowID, rowTitle, rowStatus, nil
+		}
+		labels = append(labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	caps = append(caps, FromIssue(id, title, status, labels)...)
+	Sort(caps)
+	return caps, nil
+}
+
+// FromIssue returns the capabilities an issue's labels declare. An export:
+// label is only listed while the issue doesn't provide that capability yet.
+func FromIssue(id, title, status string, labels []string) []Capability {
+	var caps []Capability
+	provided := make(map[string]bool)
+	for _, want := range []string{ProvidesPrefix, ExportPrefix} {
+		for _, label := range labels {
+			prefix, name, version, ok := ParseLabel(label)
+			if !ok || prefix != want || (prefix == ExportPrefix && provided[name]) {
+				continue
+			}
+			provided[name] = true
+			caps = append(caps, Capability{
+				Name:    name,
+				Version: version,
+				IssueID: id,
+				Title:   title,
+				Status:  status,
+				Shipped: prefix == ProvidesPrefix && status == "closed",
+			})
+		}
+	}
+	return caps
+}
+
+// Sort orders capabilities by project, name, issue ID, then version.
+func Sort(caps []Capability) {
+	sort.SliceStable(caps, func(i, j int) bool {
+		if caps[i].Project != caps[j].Project {
+			return caps[i].Project < caps[j].Project
+		}
+		if caps[i].Name != caps[j].Name {
+			return caps[i].Name < caps[j].Name
+		}
+		if caps[i].IssueID != caps[j].IssueID {
+			return caps[i].IssueID < caps[j].IssueID
+		}
+		vi, _ := NormalizeVersion(caps[i].Version)
+		vj, _ := NormalizeVersion(caps[j].Version)
+		return semver.Compare(vi, vj) < 0
+	})
+}
+
+// fingerprintDB identifies the current contents of a database by the size
+// and modification time of the file and its WAL.
+func fingerprintDB(dbPath string) (string, error) {
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		return "", err
+	}
+	fp := fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+	if wal, err := os.Stat(dbPath + "-wal"); err == nil {
+		fp += fmt.Sprintf("/%d:%d", wal.Size(), wal.ModTime().UnixNano())
+	}
+	return fp, nil
+}
+
+func (r *Registry) diskCachePath(dbPath string) string {
+	sum := sha256.Sum256([]byte(dbPath))
+	return filepath.Join(r.cacheDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// readDiskCache returns the on-disk entry for dbPath, or nil. A stale or
+// corrupt entry is treated as missing.
+func (r *Registry) readDiskCache(dbPath string) *cacheEntry {
+	if r.cacheDir == "" {
+		return nil
+	}
+	// #nosec G304 -- path is derived from a hash under the cache directory
+	data, err := os.ReadFile(r.diskCachePath(dbPath))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.DBPath != dbPath {
+		return nil
+	}
+	return &entry
+}
+
+// writeDiskCache stores an entry atomically. Failures only cost a rescan
+// next time, so they are ignored.
+func (r *Registry) writeDiskCache(entry *cacheEntry) {
+	if r.cacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(r.cacheDir, 0o750); err != nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	path := r.diskCachePath(entry.DBPath)
+	tmp, err := os.CreateTemp(r.cacheDir, ".capabilities-*")
+	if err != nil {
+		return
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	_ = tmp.Close()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
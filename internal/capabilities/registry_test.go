@@ -0,0 +1,120 @@
+package capabilities_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/capabilities"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/configfile"
+	"github.com/steveyegge/beads/internal/storage/sqlite"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestRegistryResolve(t *testing.T) {
+	ctx := context.Background()
+
+	// External project with an exported but unshipped capability
+	projectDir := t.TempDir()
+	beadsDir := filepath.Join(projectDir, ".beads")
+	if err := os.MkdirAll(beadsDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := configfile.DefaultConfig().Save(beadsDir); err != nil {
+		t.Fatal(err)
+	}
+	ext, err := sqlite.New(ctx, filepath.Join(beadsDir, "beads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ext.Close()
+	if err := ext.SetConfig(ctx, "issue_prefix", "ext"); err != nil {
+		t.Fatal(err)
+	}
+	issue := &types.Issue{Title: "Public API", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeTask}
+	if err := ext.CreateIssue(ctx, issue, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ext.AddLabel(ctx, issue.ID, "export:api", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	old := config.GetExternalProjects()
+	defer config.Set("external_projects", old)
+	config.Set("external_projects", map[string]string{"ext": projectDir})
+
+	cacheDir := t.TempDir()
+	reg := capabilities.NewRegistry(cacheDir)
+
+	res := reg.Resolve(ctx, "external:ext:api")
+	if res.Satisfied {
+		t.Fatal("unshipped capability resolved as satisfied")
+	}
+	want := "capability not shipped (no closed issue with provides:api label); " + issue.ID + " is open"
+	if res.Reason != want {
+		t.Errorf("reason = %q, want %q", res.Reason, want)
+	}
+
+	// Shipping changes the database, which must invalidate the cache
+	if err := ext.CloseIssue(ctx, issue.ID, "Done", "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := ext.AddLabel(ctx, issue.ID, "provides:api@2.1.0", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	res = reg.Resolve(ctx, "external:ext:api@>=2")
+	if !res.Satisfied || res.Provider == nil || res.Provider.IssueID != issue.ID {
+		t.Fatalf("api@>=2 not satisfied after shipping: %+v", res)
+	}
+	if res.Constraint != ">=2" || res.Provider.Version != "2.1.0" {
+		t.Errorf("resolution = %+v, provider = %+v", res, res.Provider)
+	}
+
+	results := reg.ResolveAll(ctx, []string{"external:ext:api@>=3", "external:ext:api", "external:other:api"})
+	if got := results["external:ext:api@>=3"].Reason; got != "capability shipped as 2.1.0, none satisfies >=3" {
+		t.Errorf("api@>=3 reason = %q", got)
+	}
+	if !results["external:ext:api"].Satisfied {
+		t.Error("unconstrained ref should be satisfied by a versioned provides label")
+	}
+	if got := results["external:other:api"].Reason; got != "project not configured in external_projects" {
+		t.Errorf("unconfigured project reason = %q", got)
+	}
+
+	// A fresh registry picks the project up from the disk cache
+	entries, _ := os.ReadDir(cacheDir)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 disk cache entry, got %d", len(entries))
+	}
+	caps, err := capabilities.NewRegistry(cacheDir).Project(ctx, "ext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(caps) != 1 || caps[0].Project != "ext" || !caps[0].Shipped || caps[0].Version != "2.1.0" {
+		t.Errorf("Project(ext) = %+v", caps)
+	}
+}
+
+func TestFromIssue(t *testing.T) {
+	caps := capabilities.FromIssue("bd-1", "API", "closed", []string{"export:api", "provides:api@1.0.0", "export:cli", "area:x"})
+	if len(caps) != 2 {
+		t.Fatalf("FromIssue = %+v", caps)
+	}
+	var names []string
+	for _, c := range caps {
+		names = append(names, c.Name+"@"+c.Version)
+	}
+	if got := strings.Join(names, ","); got != "api@1.0.0,cli@" {
+		t.Errorf("capabilities = %s", got)
+	}
+	if !caps[0].Shipped || caps[1].Shipped {
+		t.Errorf("shipped flags = %v, %v", caps[0].Shipped, caps[1].Shipped)
+	}
+}
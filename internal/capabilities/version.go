@@ -0,0 +1,154 @@
+package capabilities
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// NormalizeVersion returns version in the "vMAJOR[.MINOR[.PATCH]]" form
+// golang.org/x/mod/semver expects, or an error if it is not a version.
+func NormalizeVersion(version string) (string, error) {
+	v := strings.TrimSpace(version)
+	if v == "" {
+		return "", fmt.Errorf("empty version")
+	}
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return "", fmt.Errorf("invalid version %q", version)
+	}
+	return v, nil
+}
+
+// Constraint is a set of version comparisons that must all hold, e.g.
+// ">=2.1, <3". Supported operators: = (or none), !=, >, >=, <, <=, ^ and ~.
+// A partial version with = matches every version it prefixes, so "2"
+// matches 2.0.0 and 2.9.1.
+type Constraint struct {
+	raw   string
+	terms []term
+}
+
+type term struct {
+	op      string
+	version string // Normalized, possibly partial ("v2", "v2.1")
+	parts   int    // Number of components given
+}
+
+// ParseConstraint parses a version constraint.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: strings.TrimSpace(s)}
+	fields := strings.FieldsFunc(c.raw, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+	for _, f := range fields {
+		op := ""
+		for _, candidate := range []string{">=", "<=", "!=", ">", "<", "=", "^", "~"} {
+			if strings.HasPrefix(f, candidate) {
+				op = candidate
+				break
+			}
+		}
+		v, err := NormalizeVersion(strings.TrimPrefix(f, op))
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %v", s, err)
+		}
+		if op == "" {
+			op = "="
+		}
+		parts := strings.Count(strings.SplitN(strings.TrimPrefix(v, "v"), "-", 2)[0], ".") + 1
+		c.terms = append(c.terms, term{op: op, version: v, parts: parts})
+	}
+	return c, nil
+}
+
+// String returns the constraint as written.
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Matches reports whether version satisfies every term. Versions that do
+// not parse never match.
+func (c *Constraint) Matches(version string) bool {
+	v, err := NormalizeVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, t := range c.terms {
+		if !t.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t term) matches(v string) bool {
+	cmp := semver.Compare(v, t.version)
+	switch t.op {
+	case "=":
+		return t.prefixOf(v)
+	case "!=":
+		return !t.prefixOf(v)
+	case ">":
+		return cmp > 0 && !t.prefixOf(v)
+	case ">=":
+		return cmp >= 0 || t.prefixOf(v)
+	case "<":
+		return cmp < 0 && !t.prefixOf(v)
+	case "<=":
+		return cmp <= 0 || t.prefixOf(v)
+	case "^":
+		// Same major (same minor for 0.x), at least the given version
+		if cmp < 0 {
+			return false
+		}
+		if semver.Major(v) != semver.Major(t.version) {
+			return false
+		}
+		return semver.Major(t.version) != "v0" || t.parts < 2 || semver.MajorMinor(v) == semver.MajorMinor(t.version)
+	case "~":
+		// Same minor when given, otherwise same major
+		if cmp < 0 {
+			return false
+		}
+		if t.parts < 2 {
+			return semver.Major(v) == semver.Major(t.version)
+		}
+		return semver.MajorMinor(v) == semver.MajorMinor(t.version)
+	}
+	return false
+}
+
+// prefixOf reports whether v falls inside the partial version t, e.g.
+// "v2.1" contains v2.1.0 and v2.1.7.
+func (t term) prefixOf(v string) bool {
+	want := versionParts(t.version)
+	got := versionParts(v)
+	for i := 0; i < t.parts && i < len(want); i++ {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	if t.parts == 3 {
+		return semver.Prerelease(v) == semver.Prerelease(t.version)
+	}
+	return true
+}
+
+// versionParts returns major, minor and patch as numbers.
+func versionParts(v string) [3]int {
+	var parts [3]int
+	core := strings.TrimPrefix(semver.Canonical(v), "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	for i, p := range strings.SplitN(core, ".", 3) {
+		parts[i], _ = strconv.Atoi(p)
+	}
+	return parts
+}
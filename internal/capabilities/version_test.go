@@ -0,0 +1,102 @@
+package capabilities
+
+import "testing"
+
+func TestConstraintMatches(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"2", "2.0.0", true},
+		{"2", "2.9.1", true},
+		{"2", "3.0.0", false},
+		{"=2.1", "2.1.7", true},
+		{"=2.1", "2.2.0", false},
+		{"2.1.0", "v2.1.0", true},
+		{">=2", "2.0.0", true},
+		{">=2", "1.9.9", false},
+		{">=2.1", "2.0.5", false},
+		{">2", "2.5.0", false},
+		{">2", "3.0.0", true},
+		{"<2", "1.9.0", true},
+		{"<2", "2.0.0", false},
+		{"<=2", "2.4.0", true},
+		{"<=2", "3.0.0", false},
+		{"!=2.1", "2.1.3", false},
+		{"!=2.1", "2.2.0", true},
+		{"^1.4", "1.9.0", true},
+		{"^1.4", "1.3.0", false},
+		{"^1.4", "2.0.0", false},
+		{"^0.3", "0.3.5", true},
+		{"^0.3", "0.4.0", false},
+		{"~1.4", "1.4.9", true},
+		{"~1.4", "1.5.0", false},
+		{"~1", "1.9.0", true},
+		{">=2, <3", "2.7.0", true},
+		{">=2,<3", "3.0.0", false},
+		{">=2", "", false},
+		{">=2", "latest", false},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+		}
+		if got := c.Matches(tt.version); got != tt.want {
+			t.Errorf("%q.Matches(%q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{"", ",", ">=", "latest", ">=2,x"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	r, err := ParseRef("external:beads:api@>=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Project != "beads" || r.Name != "api" || r.Constraint.String() != ">=2" {
+		t.Errorf("ParseRef = %+v", r)
+	}
+	if r.String() != "api@>=2" {
+		t.Errorf("String() = %q", r.String())
+	}
+
+	tests := map[string]string{
+		"bd-xyz":               "not an external reference",
+		"external:beads":       "invalid format (expected external:project:capability)",
+		"external::api":        "missing project or capability",
+		"external:beads:@2":    "missing project or capability",
+		"external:beads:api@x": `invalid version constraint "x": invalid version "x"`,
+	}
+	for ref, want := range tests {
+		_, err := ParseRef(ref)
+		refErr, ok := err.(*RefError)
+		if !ok || refErr.Reason != want {
+			t.Errorf("ParseRef(%q) error = %v, want reason %q", ref, err, want)
+		}
+	}
+}
+
+func TestParseLabel(t *testing.T) {
+	prefix, name, version, ok := ParseLabel("provides:api@2.1.0")
+	if !ok || prefix != ProvidesPrefix || name != "api" || version != "2.1.0" {
+		t.Errorf("ParseLabel = %q %q %q %v", prefix, name, version, ok)
+	}
+	if _, _, _, ok := ParseLabel("provides:"); ok {
+		t.Error("ParseLabel(provides:) should fail")
+	}
+	if _, _, _, ok := ParseLabel("area:api"); ok {
+		t.Error("ParseLabel(area:api) should fail")
+	}
+	if got := ProvidesLabel("api", "2.1.0"); got != "provides:api@2.1.0" {
+		t.Errorf("ProvidesLabel = %q", got)
+	}
+}
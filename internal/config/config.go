@@ -81,6 +81,8 @@ func Initialize() error {
 	v.SetDefault("no-auto-flush", false)
 	v.SetDefault("no-auto-import", false)
 	v.SetDefault("no-db", false)
+	v.SetDefault("turso", false)
+	v.SetDefault("git-refs", false)
 	v.SetDefault("db", "")
 	v.SetDefault("actor", "")
 	v.SetDefault("issue-prefix", "")
@@ -134,6 +136,27 @@ func Initialize() error {
 	// Maps project names to paths for resolving external: blocked_by references
 	v.SetDefault("external_projects", map[string]string{})
 
+	// Attachment blob tracking: "commit" | "ignore" | "lfs"
+	// Controls whether .beads/blobs/ is committed, git-ignored or LFS-tracked
+	v.SetDefault("attachments.mode", "commit")
+
+	// Agent liveness policy: agents without a heartbeat for agents.dead-after
+	// are marked dead and their hooked work is returned to open ("0" disables).
+	// The daemon checks every agents.reap-interval.
+	v.SetDefault("agents.dead-after", "0")
+	v.SetDefault("agents.reap-interval", "1m")
+
+	// Budget enforcement when a claim (update --claim, swarm run dispatch,
+	// agent spawn --hook, agent handoff) falls under an issue whose audited
+	// spend exceeds its budget_usd: "warn" | "block". Block also refuses
+	// claims when the budgets cannot be checked.
+	v.SetDefault("budget.enforcement", "warn")
+
+	// Formulas whose conditions may run shell commands (cmd.exit), as
+	// names or glob patterns (e.g. ["mol-release", "mol-ci-*"]). Read with
+	// GetUserStringSlice: a repository must not be able to trust itself.
+	v.SetDefault("formula.trusted", []string{})
+
 	// Read config file if it was found
 	if configFileSet {
 		if err := v.ReadInConfig(); err != nil {
@@ -361,6 +384,42 @@ func GetStringSlice(key string) []string {
 	return v.GetStringSlice(key)
 }
 
+// userConfigPaths returns the user-level config files in precedence order:
+// ~/.config/bd/config.yaml, then ~/.beads/config.yaml.
+func userConfigPaths() []string {
+	var paths []string
+	if configDir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(configDir, "bd", "config.yaml"))
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(homeDir, ".beads", "config.yaml"))
+	}
+	return paths
+}
+
+// GetUserStringSlice retrieves a string slice from the user-level config
+// files only, taking the first that sets key. The project's
+// .beads/config.yaml and environment variables are ignored, so settings that
+// grant trust cannot come from a cloned repository (or an .envrc in it).
+func GetUserStringSlice(key string) []string {
+	for _, path := range userConfigPaths() {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		uv := viper.New()
+		uv.SetConfigType("yaml")
+		uv.SetConfigFile(path)
+		if err := uv.ReadInConfig(); err != nil {
+			debug.Logf("Debug: failed to read user config %s: %v\n", path, err)
+			continue
+		}
+		if uv.IsSet(key) {
+			return uv.GetStringSlice(key)
+		}
+	}
+	return []string{}
+}
+
 // GetStringMapString retrieves a map[string]string configuration value
 func GetStringMapString(key string) map[string]string {
 	if v == nil {
@@ -844,3 +844,43 @@ validation:
 		t.Errorf("GetString(validation.on-sync) = %q, want \"warn\"", got)
 	}
 }
+
+func TestGetUserStringSlice(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("BD_FORMULA_TRUSTED", "from-env")
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+	}
+
+	// The project config is what Initialize loads, but it must not count
+	project := t.TempDir()
+	write(filepath.Join(project, ".beads", "config.yaml"), "formula.trusted:\n  - mol-*\n")
+	t.Chdir(project)
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() returned error: %v", err)
+	}
+	if got := GetUserStringSlice("formula.trusted"); len(got) != 0 {
+		t.Errorf("GetUserStringSlice() with only project config = %v, want empty", got)
+	}
+
+	write(filepath.Join(home, ".beads", "config.yaml"), "formula.trusted:\n  - mol-home\n")
+	if got := GetUserStringSlice("formula.trusted"); len(got) != 1 || got[0] != "mol-home" {
+		t.Errorf("GetUserStringSlice() = %v, want [mol-home]", got)
+	}
+
+	// ~/.config/bd/config.yaml takes precedence over ~/.beads/config.yaml
+	write(filepath.Join(home, ".config", "bd", "config.yaml"), "formula:\n  trusted: [mol-release, mol-ci-*]\n")
+	got := GetUserStringSlice("formula.trusted")
+	if len(got) != 2 || got[0] != "mol-release" || got[1] != "mol-ci-*" {
+		t.Errorf("GetUserStringSlice() = %v, want [mol-release mol-ci-*]", got)
+	}
+}
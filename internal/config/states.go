@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StateSchema declares the allowed values of a state dimension (the
+// <dimension>:<value> labels managed by bd set-state).
+//
+// Example config.yaml:
+//
+//	states:
+//	  health:
+//	    values: [healthy, degraded, failing]
+//	    default: healthy
+//	    transitions:
+//	      healthy: [degraded, failing]
+//	      degraded: [healthy, failing]
+//	      failing: [degraded]
+//	    ttl: 1h
+type StateSchema struct {
+	Dimension   string              `json:"dimension"`
+	Values      []string            `json:"values,omitempty"`      // Allowed values (empty = any)
+	Default     string              `json:"default,omitempty"`     // Value of an issue with no label for the dimension
+	Transitions map[string][]string `json:"transitions,omitempty"` // Allowed next values per value; unlisted values may move anywhere
+	TTL         time.Duration       `json:"ttl,omitempty"`         // Non-default values revert to Default after this long
+}
+
+// stateSchemaConfig is the config.yaml shape of a StateSchema.
+type stateSchemaConfig struct {
+	Values      []string            `mapstructure:"values"`
+	Default     string              `mapstructure:"default"`
+	Transitions map[string][]string `mapstructure:"transitions"`
+	TTL         string              `mapstructure:"ttl"`
+}
+
+// GetStateSchemas returns the state dimensions declared under states: in
+// config.yaml, keyed by dimension. Dimensions without a schema accept any
+// value.
+func GetStateSchemas() (map[string]*StateSchema, error) {
+	schemas := make(map[string]*StateSchema)
+	if v == nil || !v.IsSet("states") {
+		return schemas, nil
+	}
+	var raw map[string]stateSchemaConfig
+	if err := v.UnmarshalKey("states", &raw); err != nil {
+		return nil, fmt.Errorf("invalid states config: %w", err)
+	}
+	for dimension, cfg := range raw {
+		schema, err := newStateSchema(dimension, cfg)
+		if err != nil {
+			return nil, err
+		}
+		schemas[dimension] = schema
+	}
+	return schemas, nil
+}
+
+// GetStateSchema returns the schema for one dimension, or nil if it has none.
+func GetStateSchema(dimension string) (*StateSchema, error) {
+	schemas, err := GetStateSchemas()
+	if err != nil {
+		return nil, err
+	}
+	return schemas[strings.ToLower(dimension)], nil
+}
+
+func newStateSchema(dimension string, cfg stateSchemaConfig) (*StateSchema, error) {
+	s := &StateSchema{
+		Dimension:   dimension,
+		Values:      cfg.Values,
+		Default:     cfg.Default,
+		Transitions: make(map[string][]string),
+	}
+	if cfg.TTL != "" {
+		ttl, err := time.ParseDuration(cfg.TTL)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("states.%s.ttl: invalid duration %q", dimension, cfg.TTL)
+		}
+		s.TTL = ttl
+	}
+	// Config keys are case-insensitive, so transitions are keyed by the
+	// declared value with the same spelling
+	for from, to := range cfg.Transitions {
+		key := from
+		for _, value := range s.Values {
+			if strings.EqualFold(value, from) {
+				key = value
+			}
+		}
+		s.Transitions[key] = to
+	}
+
+	if s.Default != "" && !s.Allows(s.Default) {
+		return nil, fmt.Errorf("states.%s.default: %q is not one of its values", dimension, s.Default)
+	}
+	if s.TTL > 0 && s.Default == "" {
+		return nil, fmt.Errorf("states.%s.ttl: a default is required to revert to", dimension)
+	}
+	for from, targets := range s.Transitions {
+		if !s.Allows(from) {
+			return nil, fmt.Errorf("states.%s.transitions: %q is not one of its values", dimension, from)
+		}
+		for _, to := range targets {
+			if !s.Allows(to) {
+				return nil, fmt.Errorf("states.%s.transitions.%s: %q is not one of its values", dimension, from, to)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Allows reports whether value is one of the schema's values.
+func (s *StateSchema) Allows(value string) bool {
+	return len(s.Values) == 0 || slices.Contains(s.Values, value)
+}
+
+// Validate checks a change from one value to another. An empty from is the
+// dimension's default (or no value at all).
+func (s *StateSchema) Validate(from, to string) error {
+	if !s.Allows(to) {
+		return fmt.Errorf("%q is not a valid %s value (allowed: %s)", to, s.Dimension, strings.Join(s.Values, ", "))
+	}
+	if from == "" {
+		from = s.Default
+	}
+	if from == "" || from == to {
+		return nil
+	}
+	targets, ok := s.Transitions[from]
+	if !ok || slices.Contains(targets, to) {
+		return nil
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("%s cannot change from %s", s.Dimension, from)
+	}
+	return fmt.Errorf("%s cannot change from %s to %s (allowed: %s)", s.Dimension, from, to, strings.Join(targets, ", "))
+}
+
+// Expired reports whether a value set at setAt has outlived the TTL and
+// should revert to the default.
+func (s *StateSchema) Expired(value string, setAt, now time.Time) bool {
+	return s.TTL > 0 && value != s.Default && now.Sub(setAt) >= s.TTL
+}
+
+// SortedStateDimensions returns the schema dimensions in name order.
+func SortedStateDimensions(schemas map[string]*StateSchema) []string {
+	dims := make([]string, 0, len(schemas))
+	for dim := range schemas {
+		dims = append(dims, dim)
+	}
+	sort.Strings(dims)
+	return dims
+}
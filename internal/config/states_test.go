@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeStatesConfig(t *testing.T, content string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	beadsDir := filepath.Join(tmpDir, ".beads")
+	if err := os.MkdirAll(beadsDir, 0750); err != nil {
+		t.Fatalf("failed to create .beads directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(beadsDir, "config.yaml"), []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Chdir(tmpDir)
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() returned error: %v", err)
+	}
+}
+
+func TestGetStateSchemasFromFile(t *testing.T) {
+	restore := envSnapshot(t)
+	defer restore()
+
+	writeStatesConfig(t, `
+states:
+  health:
+    values: [healthy, degraded, failing]
+    default: healthy
+    transitions:
+      healthy: [degraded, failing]
+      degraded: [healthy, failing]
+      failing: [degraded]
+    ttl: 1h
+  mode:
+    values: [normal, maintenance]
+`)
+
+	schemas, err := GetStateSchemas()
+	if err != nil {
+		t.Fatalf("GetStateSchemas() returned error: %v", err)
+	}
+	if got := SortedStateDimensions(schemas); strings.Join(got, ",") != "health,mode" {
+		t.Fatalf("dimensions = %v, want [health mode]", got)
+	}
+	health := schemas["health"]
+	if health.Default != "healthy" || health.TTL != time.Hour || len(health.Transitions) != 3 {
+		t.Errorf("unexpected health schema: %+v", health)
+	}
+
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{"", "degraded", true},         // unset starts at the default
+		{"healthy", "failing", true},   // declared transition
+		{"failing", "healthy", false},  // must recover through degraded
+		{"failing", "failing", true},   // no-op
+		{"degraded", "unknown", false}, // not a value
+	}
+	for _, tt := range tests {
+		err := health.Validate(tt.from, tt.to)
+		if (err == nil) != tt.ok {
+			t.Errorf("Validate(%q, %q) = %v, want ok=%v", tt.from, tt.to, err, tt.ok)
+		}
+	}
+
+	// No transitions declared: any listed value is reachable
+	if err := schemas["mode"].Validate("maintenance", "normal"); err != nil {
+		t.Errorf("mode Validate() = %v", err)
+	}
+
+	setAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if health.Expired("degraded", setAt, setAt.Add(59*time.Minute)) {
+		t.Error("degraded should not expire before the TTL")
+	}
+	if !health.Expired("degraded", setAt, setAt.Add(time.Hour)) {
+		t.Error("degraded should expire after the TTL")
+	}
+	if health.Expired("healthy", setAt, setAt.Add(24*time.Hour)) {
+		t.Error("the default value never expires")
+	}
+
+	if s, _ := GetStateSchema("HEALTH"); s == nil || s.Dimension != "health" {
+		t.Error("GetStateSchema should be case-insensitive")
+	}
+	if s, _ := GetStateSchema("patrol"); s != nil {
+		t.Errorf("GetStateSchema(patrol) = %+v, want nil", s)
+	}
+}
+
+func TestGetStateSchemasInvalid(t *testing.T) {
+	restore := envSnapshot(t)
+	defer restore()
+
+	tests := map[string]string{
+		"default not a value": "states:\n  health:\n    values: [ok]\n    default: bad\n",
+		"ttl without default": "states:\n  health:\n    values: [ok]\n    ttl: 1h\n",
+		"bad ttl":             "states:\n  health:\n    default: ok\n    ttl: soon\n",
+		"bad transition":      "states:\n  health:\n    values: [ok, bad]\n    transitions:\n      ok: [gone]\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			writeStatesConfig(t, content)
+			if _, err := GetStateSchemas(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
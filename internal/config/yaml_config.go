@@ -19,6 +19,8 @@ import (
 var YamlOnlyKeys = map[string]bool{
 	// Bootstrap flags (affect how bd starts)
 	"no-db":          true,
+	"turso":          true,
+	"git-refs":       true,
 	"no-daemon":      true,
 	"no-auto-flush":  true,
 	"no-auto-import": true,
@@ -64,6 +66,18 @@ var YamlOnlyKeys = map[string]bool{
 	// Values: "warn" | "error" | "none"
 	"validation.on-create": true,
 	"validation.on-sync":   true,
+
+	// Attachment settings
+	// Values: "commit" | "ignore" | "lfs"
+	"attachments.mode": true,
+
+	// Agent liveness settings (read by the daemon at startup)
+	"agents.dead-after":    true,
+	"agents.reap-interval": true,
+
+	// Budget settings
+	// Values: "warn" | "block"
+	"budget.enforcement": true,
 }
 
 // IsYamlOnlyKey returns true if the given key should be stored in config.yaml
@@ -0,0 +1,188 @@
+package formats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// ExternalPrefix is the executable name prefix of external formats.
+const ExternalPrefix = "bd-format-"
+
+// capabilitiesTimeout bounds the capabilities probe, which runs whenever an
+// external format is looked up or listed.
+const capabilitiesTimeout = 5 * time.Second
+
+// External is a format provided by an executable, bd-format-<name>, so
+// teams can add formats without patching bd. It speaks JSONL:
+//
+//	bd-format-<name> capabilities   print a JSON object with "description",
+//	                                "export", "import", "round_trip", "lossy"
+//	bd-format-<name> export [k=v]   read JSONL issues on stdin, write the
+//	                                format on stdout
+//	bd-format-<name> import [k=v]   read the format on stdin, write JSONL
+//	                                issues on stdout
+//
+// Format params are passed as key=value arguments, and the database's issue
+// prefix as BD_ISSUE_PREFIX. Imported issues without an id get a hash ID.
+// External formats are always streaming. Anything written to stderr is
+// reported when the executable fails.
+type External struct {
+	name        string
+	path        string
+	description string
+	caps        Capabilities
+}
+
+type externalCapabilities struct {
+	Description string `json:"description"`
+	Capabilities
+}
+
+func (e *External) Name() string { return e.name }
+
+// Path returns the executable's path.
+func (e *External) Path() string { return e.path }
+
+func (e *External) Description() string {
+	if e.description != "" {
+		return e.description
+	}
+	return "external format (" + e.path + ")"
+}
+
+func (e *External) Capabilities() Capabilities { return e.caps }
+
+// NewExternal probes the executable at path for its capabilities.
+func NewExternal(name, path string) (*External, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), capabilitiesTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	// #nosec G204 -- bd-format-* executables are trusted like git subcommands
+	cmd := exec.CommandContext(ctx, path, "capabilities")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, externalError(path, "capabilities", err, &stderr)
+	}
+	var caps externalCapabilities
+	if err := json.Unmarshal(out, &caps); err != nil {
+		return nil, fmt.Errorf("%s capabilities: invalid JSON: %w", path, err)
+	}
+	caps.Streaming = true
+	return &External{name: name, path: path, description: caps.Description, caps: caps.Capabilities}, nil
+}
+
+// Encode pipes the issues as JSONL through "<exe> export".
+func (e *External) Encode(ctx context.Context, w io.Writer, issues []*types.Issue, opts Options) error {
+	var in bytes.Buffer
+	if err := (JSONL{}).Encode(ctx, &in, issues, opts); err != nil {
+		return err
+	}
+	return e.run(ctx, "export", &in, w, opts)
+}
+
+// Decode pipes r through "<exe> import" and reads back JSONL issues.
+func (e *External) Decode(ctx context.Context, r io.Reader, opts Options) ([]*types.Issue, error) {
+	var out bytes.Buffer
+	if err := e.run(ctx, "import", r, &out, opts); err != nil {
+		return nil, err
+	}
+	issues, err := (JSONL{}).Decode(ctx, &out, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s import wrote invalid JSONL: %w", e.path, err)
+	}
+	if err := AssignIDs(ctx, issues, opts, e.name+"-import"); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+func (e *External) run(ctx context.Context, op string, stdin io.Reader, stdout io.Writer, opts Options) error {
+	args := []string{op}
+	keys := make([]string, 0, len(opts.Params))
+	for k := range opts.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, k+"="+opts.Params[k])
+	}
+	var stderr bytes.Buffer
+	// #nosec G204 -- bd-format-* executables are trusted like git subcommands
+	cmd := exec.CommandContext(ctx, e.path, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "BD_ISSUE_PREFIX="+opts.Prefix)
+	if err := cmd.Run(); err != nil {
+		return externalError(e.path, op, err, &stderr)
+	}
+	return nil
+}
+
+func externalError(path, op string, err error, stderr *bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("%s %s: %w: %s", filepath.Base(path), op, err, msg)
+	}
+	return fmt.Errorf("%s %s: %w", filepath.Base(path), op, err)
+}
+
+// findExternal looks up bd-format-<name> on PATH.
+func findExternal(name string) (*External, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid format name %q", name)
+	}
+	path, err := exec.LookPath(ExternalPrefix + name)
+	if err != nil {
+		return nil, err
+	}
+	return NewExternal(name, path)
+}
+
+// discoverExternal returns the external formats on PATH, sorted by name.
+// Executables that fail the capabilities probe are skipped.
+func discoverExternal() []*External {
+	seen := make(map[string]bool)
+	var found []*External
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := externalName(entry.Name())
+			if !ok || seen[name] || entry.IsDir() {
+				continue
+			}
+			seen[name] = true // first on PATH wins, as with exec.LookPath
+			if ext, err := NewExternal(name, filepath.Join(dir, entry.Name())); err == nil {
+				found = append(found, ext)
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].name < found[j].name })
+	return found
+}
+
+func externalName(file string) (string, bool) {
+	if !strings.HasPrefix(file, ExternalPrefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(file, ExternalPrefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name, name != ""
+}
@@ -0,0 +1,155 @@
+// Package formats is the registry of issue import/export formats.
+//
+// A format is registered by name and declares its capabilities. Stream
+// formats (jsonl, csv, ...) encode to an io.Writer and decode from an
+// io.Reader; directory formats (html, markdown-dir) work on a directory.
+// bd export/import and the daemon's export/import operations look formats up
+// here, so a format registered once is available everywhere.
+//
+// Formats that are not built in can be provided as executables named
+// bd-format-<name> on PATH; see External.
+package formats
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/steveyegge/beads/internal/linear"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// Capabilities describe what a format supports.
+type Capabilities struct {
+	Export bool `json:"export"` // issues can be written in this format
+	Import bool `json:"import"` // issues can be read from this format
+	// Streaming formats read and write a single stream (a file or
+	// stdin/stdout); the others work on a directory.
+	Streaming bool `json:"streaming"`
+	// RoundTrip formats give back the exported issues on import.
+	RoundTrip bool `json:"round_trip"`
+	// Lossy formats drop issue data on export (comments, events, fields).
+	Lossy bool `json:"lossy"`
+}
+
+// Options are passed to encoders and decoders.
+type Options struct {
+	// Store is the database being exported from or imported into, for
+	// formats that need more than the issues (comments, history, existing
+	// IDs). It may be nil.
+	Store storage.Storage
+	// Prefix is the issue prefix of the database.
+	Prefix string
+	// Params holds format-specific settings, such as "columns" for csv.
+	Params map[string]string
+	// ResolvePrefix, if set, supplies the prefix when Prefix is empty and
+	// decoded issues need IDs. It gets the decoded issues, so the prefix
+	// can be detected from the IDs the input does have.
+	ResolvePrefix func(ctx context.Context, issues []*types.Issue) (string, error)
+}
+
+// IDPrefix returns the prefix for new IDs among the decoded issues: Prefix,
+// or else what ResolvePrefix gives.
+func (o Options) IDPrefix(ctx context.Context, issues []*types.Issue) (string, error) {
+	if o.Prefix != "" || o.ResolvePrefix == nil {
+		return o.Prefix, nil
+	}
+	return o.ResolvePrefix(ctx, issues)
+}
+
+// Format is an import/export format.
+type Format interface {
+	Name() string
+	Description() string
+	Capabilities() Capabilities
+}
+
+// Encoder is implemented by stream formats that can export.
+type Encoder interface {
+	Encode(ctx context.Context, w io.Writer, issues []*types.Issue, opts Options) error
+}
+
+// Decoder is implemented by stream formats that can import.
+type Decoder interface {
+	Decode(ctx context.Context, r io.Reader, opts Options) ([]*types.Issue, error)
+}
+
+// DirEncoder is implemented by directory formats that can export.
+type DirEncoder interface {
+	EncodeDir(ctx context.Context, dir string, issues []*types.Issue, opts Options) error
+}
+
+// DirDecoder is implemented by directory formats that can import.
+type DirDecoder interface {
+	DecodeDir(ctx context.Context, dir string, opts Options) ([]*types.Issue, error)
+}
+
+// Encode writes issues to w in a stream format.
+func Encode(ctx context.Context, f Format, w io.Writer, issues []*types.Issue, opts Options) error {
+	enc, ok := f.(Encoder)
+	if !ok || !f.Capabilities().Export || !f.Capabilities().Streaming {
+		return fmt.Errorf("format %q cannot export to a stream", f.Name())
+	}
+	return enc.Encode(ctx, w, issues, opts)
+}
+
+// Decode reads issues from r in a stream format.
+func Decode(ctx context.Context, f Format, r io.Reader, opts Options) ([]*types.Issue, error) {
+	dec, ok := f.(Decoder)
+	if !ok || !f.Capabilities().Import || !f.Capabilities().Streaming {
+		return nil, fmt.Errorf("format %q cannot import from a stream", f.Name())
+	}
+	return dec.Decode(ctx, r, opts)
+}
+
+// EncodeDir writes issues to dir in a directory format.
+func EncodeDir(ctx context.Context, f Format, dir string, issues []*types.Issue, opts Options) error {
+	enc, ok := f.(DirEncoder)
+	if !ok || !f.Capabilities().Export {
+		return fmt.Errorf("format %q cannot export to a directory", f.Name())
+	}
+	return enc.EncodeDir(ctx, dir, issues, opts)
+}
+
+// DecodeDir reads issues from dir in a directory format.
+func DecodeDir(ctx context.Context, f Format, dir string, opts Options) ([]*types.Issue, error) {
+	dec, ok := f.(DirDecoder)
+	if !ok || !f.Capabilities().Import {
+		return nil, fmt.Errorf("format %q cannot import from a directory", f.Name())
+	}
+	return dec.DecodeDir(ctx, dir, opts)
+}
+
+// AssignIDs gives hash IDs to decoded issues that have none, avoiding the
+// IDs already in the store. The importer matches issues by ID, so decoders
+// whose input may lack IDs call this before returning.
+func AssignIDs(ctx context.Context, issues []*types.Issue, opts Options, creator string) error {
+	missing := 0
+	for _, issue := range issues {
+		if issue.ID == "" {
+			missing++
+		}
+	}
+	if missing == 0 {
+		return nil
+	}
+	prefix, err := opts.IDPrefix(ctx, issues)
+	if err != nil {
+		return err
+	}
+	if prefix == "" {
+		return fmt.Errorf("%d issue(s) have no id and the database has no issue prefix", missing)
+	}
+	usedIDs := make(map[string]bool)
+	if opts.Store != nil {
+		existing, err := opts.Store.SearchIssues(ctx, "", types.IssueFilter{IncludeTombstones: true})
+		if err != nil {
+			return fmt.Errorf("failed to load existing issues: %w", err)
+		}
+		for _, issue := range existing {
+			usedIDs[issue.ID] = true
+		}
+	}
+	return linear.GenerateIssueIDs(issues, prefix, creator, linear.IDGenerationOptions{UsedIDs: usedIDs})
+}
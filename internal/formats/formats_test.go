@@ -0,0 +1,201 @@
+package formats
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// titlesFormat is an export-only test format: one title per line.
+type titlesFormat struct{}
+
+func (titlesFormat) Name() string        { return "test-titles" }
+func (titlesFormat) Description() string { return "titles" }
+func (titlesFormat) Capabilities() Capabilities {
+	return Capabilities{Export: true, Streaming: true, Lossy: true}
+}
+func (titlesFormat) Encode(_ context.Context, w io.Writer, issues []*types.Issue, _ Options) error {
+	for _, issue := range issues {
+		if _, err := io.WriteString(w, issue.Title+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	Register(titlesFormat{})
+
+	if !IsRegistered("jsonl") || !IsRegistered("test-titles") {
+		t.Fatalf("registered = %v", Names())
+	}
+	if _, err := LookupExporter("test-titles"); err != nil {
+		t.Errorf("LookupExporter: %v", err)
+	}
+	if _, err := LookupImporter("test-titles"); err == nil || !strings.Contains(err.Error(), "does not support import") {
+		t.Errorf("LookupImporter on an export-only format: %v", err)
+	}
+	if _, err := Lookup("no-such-format"); err == nil || !strings.Contains(err.Error(), "jsonl") {
+		t.Errorf("Lookup of an unknown format should list the available ones: %v", err)
+	}
+	f, _ := Lookup("test-titles")
+	if _, err := Decode(context.Background(), f, strings.NewReader(""), Options{}); err == nil {
+		t.Error("Decode on an export-only format should fail")
+	}
+	if err := EncodeDir(context.Background(), f, t.TempDir(), nil, Options{}); err == nil {
+		t.Error("EncodeDir on a stream format should fail")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a format twice should panic")
+		}
+	}()
+	Register(titlesFormat{})
+}
+
+func TestJSONLRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	issues := []*types.Issue{
+		{ID: "bd-1", Title: "One", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeBug, Labels: []string{"x"}},
+		{ID: "bd-2", Title: "Two\nlines", Status: types.StatusClosed, Priority: 3, IssueType: types.TypeTask},
+	}
+	var buf bytes.Buffer
+	if err := Encode(ctx, JSONL{}, &buf, issues, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Fatalf("wrote %d lines, want 2", got)
+	}
+	buf.WriteString("\n") // blank lines are skipped
+	back, err := Decode(ctx, JSONL{}, &buf, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != 2 || back[1].Title != "Two\nlines" || back[0].Labels[0] != "x" {
+		t.Errorf("round trip = %+v", back)
+	}
+
+	if _, err := Decode(ctx, JSONL{}, strings.NewReader("{\"id\":\"bd-1\"}\nnot json\n"), Options{}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected a line 2 error, got %v", err)
+	}
+}
+
+func TestAssignIDs(t *testing.T) {
+	ctx := context.Background()
+	issues := []*types.Issue{{ID: "bd-keep", Title: "Has id"}, {Title: "New one"}, {Title: "New two"}}
+	if err := AssignIDs(ctx, issues, Options{}, "test"); err == nil {
+		t.Error("expected an error without a prefix")
+	}
+	if err := AssignIDs(ctx, issues, Options{Prefix: "bd"}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if issues[0].ID != "bd-keep" {
+		t.Errorf("existing id changed to %q", issues[0].ID)
+	}
+	if !strings.HasPrefix(issues[1].ID, "bd-") || issues[1].ID == issues[2].ID {
+		t.Errorf("generated ids %q, %q", issues[1].ID, issues[2].ID)
+	}
+}
+
+func TestAssignIDsResolvesPrefix(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	opts := Options{ResolvePrefix: func(_ context.Context, issues []*types.Issue) (string, error) {
+		calls++
+		// Detect from the IDs the input has, as bd import does
+		prefix, _, _ := strings.Cut(issues[0].ID, "-")
+		return prefix, nil
+	}}
+
+	complete := []*types.Issue{{ID: "gh-1", Title: "Has id"}}
+	if err := AssignIDs(ctx, complete, opts, "test"); err != nil || calls != 0 {
+		t.Fatalf("AssignIDs() = %v with %d prefix lookups, want none for rows with ids", err, calls)
+	}
+
+	issues := []*types.Issue{{ID: "gh-1", Title: "Has id"}, {Title: "New"}}
+	if err := AssignIDs(ctx, issues, opts, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || !strings.HasPrefix(issues[1].ID, "gh-") {
+		t.Errorf("generated id %q after %d prefix lookups, want gh- after 1", issues[1].ID, calls)
+	}
+}
+
+func TestExternalFormat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir := t.TempDir()
+	// Export: one "id title" line per issue. Import: the reverse, with the
+	// param and prefix echoed into the description.
+	script := `#!/bin/sh
+case "$1" in
+capabilities) echo '{"description":"Plain lines","export":true,"import":true,"lossy":true}' ;;
+export) sed -n 's/.*"id":"\([^"]*\)","title":"\([^"]*\)".*/\1 \2/p' ;;
+import) while read -r title; do echo "{\"title\":\"$title\",\"description\":\"$2 $BD_ISSUE_PREFIX\"}"; done ;;
+*) echo "bad op $1" >&2; exit 3 ;;
+esac
+`
+	path := filepath.Join(dir, ExternalPrefix+"lines")
+	// #nosec G306 -- test executable
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	f, err := Lookup("lines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps := f.Capabilities(); !caps.Export || !caps.Import || !caps.Streaming || !caps.Lossy {
+		t.Errorf("capabilities = %+v", caps)
+	}
+	if f.Description() != "Plain lines" {
+		t.Errorf("description = %q", f.Description())
+	}
+	found := false
+	for _, name := range Names() {
+		found = found || name == "lines"
+	}
+	if !found {
+		t.Errorf("external format not listed: %v", Names())
+	}
+
+	ctx := context.Background()
+	var out bytes.Buffer
+	issues := []*types.Issue{{ID: "bd-1", Title: "First"}, {ID: "bd-2", Title: "Second"}}
+	if err := Encode(ctx, f, &out, issues, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "bd-1 First\nbd-2 Second\n" {
+		t.Errorf("export = %q", out.String())
+	}
+
+	back, err := Decode(ctx, f, strings.NewReader("Alpha\nBeta\n"), Options{Prefix: "bd", Params: map[string]string{"mode": "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != 2 || back[0].Title != "Alpha" || back[0].Description != "mode=x bd" {
+		t.Fatalf("import = %+v", back)
+	}
+	if !strings.HasPrefix(back[0].ID, "bd-") {
+		t.Errorf("imported issue got no id: %q", back[0].ID)
+	}
+
+	broken := filepath.Join(dir, ExternalPrefix+"broken")
+	// #nosec G306 -- test executable
+	if err := os.WriteFile(broken, []byte("#!/bin/sh\necho nope >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewExternal("broken", broken); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("expected the stderr of a failing probe in the error, got %v", err)
+	}
+}
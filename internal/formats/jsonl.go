@@ -0,0 +1,69 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func init() {
+	Register(JSONL{})
+}
+
+// JSONL is the native format: one JSON issue per line, as in
+// .beads/issues.jsonl. It is also what external formats speak.
+type JSONL struct{}
+
+func (JSONL) Name() string        { return "jsonl" }
+func (JSONL) Description() string { return "JSON Lines, one issue per line (the sync format)" }
+
+func (JSONL) Capabilities() Capabilities {
+	return Capabilities{Export: true, Import: true, Streaming: true, RoundTrip: true}
+}
+
+// Encode writes one JSON object per issue.
+func (JSONL) Encode(ctx context.Context, w io.Writer, issues []*types.Issue, _ Options) error {
+	encoder := json.NewEncoder(w)
+	for _, issue := range issues {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := encoder.Encode(issue); err != nil {
+			return fmt.Errorf("failed to encode issue %s: %w", issue.ID, err)
+		}
+	}
+	return nil
+}
+
+// Decode reads one JSON object per line. Blank lines are skipped and
+// omitted fields get their defaults.
+func (JSONL) Decode(ctx context.Context, r io.Reader, _ Options) ([]*types.Issue, error) {
+	scanner := bufio.NewScanner(r)
+	// Issues with long descriptions exceed the default 64KB token size
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	var issues []*types.Issue
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var issue types.Issue
+		if err := json.Unmarshal(line, &issue); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		issue.SetDefaults()
+		issues = append(issues, &issue)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
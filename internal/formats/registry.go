@@ -0,0 +1,129 @@
+package formats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// registry maps format names to formats
+var (
+	registry      = make(map[string]Format)
+	registryMutex sync.RWMutex
+)
+
+// Register registers a format under its name.
+// This is called from init() functions of the packages that implement
+// formats.
+//
+// Example:
+//
+//	func init() {
+//	    formats.Register(csvFormat{})
+//	}
+func Register(f Format) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if f == nil {
+		panic("formats: Register format is nil")
+	}
+	name := f.Name()
+	if name == "" || strings.ContainsAny(name, `/\ `) {
+		panic(fmt.Sprintf("formats: invalid format name %q", name))
+	}
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("formats: Register called twice for format %s", name))
+	}
+
+	registry[name] = f
+}
+
+// Lookup returns the format with the given name: a registered format, or
+// else an external bd-format-<name> executable on PATH.
+func Lookup(name string) (Format, error) {
+	registryMutex.RLock()
+	f, ok := registry[name]
+	registryMutex.RUnlock()
+	if ok {
+		return f, nil
+	}
+	if ext, err := findExternal(name); err == nil {
+		return ext, nil
+	}
+	return nil, fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(Names(), ", "))
+}
+
+// LookupExporter returns the named format if it can export.
+func LookupExporter(name string) (Format, error) {
+	f, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if !f.Capabilities().Export {
+		return nil, fmt.Errorf("format %q does not support export", name)
+	}
+	return f, nil
+}
+
+// LookupImporter returns the named format if it can import.
+func LookupImporter(name string) (Format, error) {
+	f, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if !f.Capabilities().Import {
+		return nil, fmt.Errorf("format %q does not support import", name)
+	}
+	return f, nil
+}
+
+// Registered returns the registered formats sorted by name. External
+// formats are not included; see Available.
+func Registered() []Format {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	list := make([]Format, 0, len(registry))
+	for _, f := range registry {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+// Available returns the registered formats followed by the external
+// formats found on PATH. A registered format shadows an external one of the
+// same name.
+func Available() []Format {
+	list := Registered()
+	seen := make(map[string]bool, len(list))
+	for _, f := range list {
+		seen[f.Name()] = true
+	}
+	for _, ext := range discoverExternal() {
+		if !seen[ext.Name()] {
+			seen[ext.Name()] = true
+			list = append(list, ext)
+		}
+	}
+	return list
+}
+
+// Names returns the names of the available formats.
+func Names() []string {
+	var names []string
+	for _, f := range Available() {
+		names = append(names, f.Name())
+	}
+	return names
+}
+
+// IsRegistered returns true if a format is registered under name.
+func IsRegistered(name string) bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	_, exists := registry[name]
+	return exists
+}
@@ -4,14 +4,17 @@
 //   - Step status checks: step.status == 'complete'
 //   - Step output access: step.output.approved == true
 //   - Aggregates: children(step).all(status == 'complete')
-//   - External checks: file.exists('go.mod'), env.CI == 'true'
+//   - External checks via condition providers (see providers.go):
+//     file.exists('go.mod'), env.CI == 'true', vcs.clean, vcs.branch == 'main',
+//     bead.status('bd-42') == 'closed', label.has('bd-42', 'approved'),
+//     json.path(test, '$.results[0].ok') == true
 //
-// No arbitrary code execution is allowed.
+// The only way to run code is cmd.exit('make test'), which is disabled unless
+// the formula is trusted (ConditionContext.Trusted).
 package formula
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -52,6 +55,19 @@ type ConditionContext struct {
 
 	// Vars are the formula variables (for variable substitution).
 	Vars map[string]string
+
+	// WorkDir is where file.exists paths and cmd.exit commands are resolved
+	// (empty = process working directory).
+	WorkDir string
+
+	// Trusted enables shell providers such as cmd.exit.
+	Trusted bool
+
+	// Beads backs bead.* and label.* conditions (nil = unavailable).
+	Beads BeadReader
+
+	// VCS backs vcs.* conditions (nil = unavailable).
+	VCS VCSReader
 }
 
 // Operator represents a comparison operator.
@@ -85,8 +101,9 @@ type Condition struct {
 	AggregateOver string // What to aggregate: children, descendants, steps
 
 	// For external conditions:
-	ExternalType string // file.exists, env
-	ExternalArg  string // Argument (path or env var name)
+	ExternalType string   // Provider name: file.exists, env, cmd.exit, vcs.branch, ...
+	ExternalArg  string   // First argument (path or env var name)
+	ExternalArgs []string // All arguments
 }
 
 // ConditionType categorizes conditions.
@@ -106,8 +123,11 @@ var (
 	// children(step).all(status == 'complete')
 	aggregatePattern = regexp.MustCompile(`^(children|descendants|steps)\((\w+)\)\.(all|any|count)\((.+)\)(.*)$`)
 
-	// file.exists('go.mod')
-	fileExistsPattern = regexp.MustCompile(`^file\.exists\(['"](.+)['"]\)$`)
+	// file.exists('go.mod'), vcs.branch == 'main' (provider name prefix)
+	providerNamePattern = regexp.MustCompile(`^[a-z]+(?:\.[a-z_]+)?`)
+
+	// == 0 (comparison after a provider call)
+	comparePattern = regexp.MustCompile(`^(==|!=|>=|<=|>|<)\s*(.+)$`)
 
 	// env.CI == 'true'
 	envPattern = regexp.MustCompile(`^env\.(\w+)\s*([=!<>]+)\s*(.+)$`)
@@ -126,16 +146,6 @@ func ParseCondition(expr string) (*Condition, error) {
 		return nil, fmt.Errorf("empty condition")
 	}
 
-	// Try file.exists pattern
-	if m := fileExistsPattern.FindStringSubmatch(expr); m != nil {
-		return &Condition{
-			Raw:          expr,
-			Type:         ConditionTypeExternal,
-			ExternalType: "file.exists",
-			ExternalArg:  m[1],
-		}, nil
-	}
-
 	// Try env pattern
 	if m := envPattern.FindStringSubmatch(expr); m != nil {
 		return &Condition{
@@ -143,11 +153,17 @@ func ParseCondition(expr string) (*Condition, error) {
 			Type:         ConditionTypeExternal,
 			ExternalType: "env",
 			ExternalArg:  m[1],
+			ExternalArgs: []string{m[1]},
 			Operator:     Operator(m[2]),
 			Value:        unquote(m[3]),
 		}, nil
 	}
 
+	// Try provider pattern: file.exists('go.mod'), vcs.branch == 'main'
+	if cond, err := parseProviderCondition(expr); cond != nil || err != nil {
+		return cond, err
+	}
+
 	// Try aggregate pattern: children(step).all(status == 'complete')
 	if m := aggregatePattern.FindStringSubmatch(expr); m != nil {
 		innerCond, err := ParseCondition(m[4])
@@ -385,31 +401,184 @@ func (c *Condition) evaluateAggregate(ctx *ConditionContext) (*ConditionResult,
 	return nil, fmt.Errorf("unknown aggregate function: %s", c.AggregateFunc)
 }
 
+// parseProviderCondition parses name(args) [op value] or name [op value]
+// for a registered provider. It returns nil, nil when expr is not a provider
+// call, so other patterns can be tried.
+func parseProviderCondition(expr string) (*Condition, error) {
+	name := providerNamePattern.FindString(expr)
+	if name == "" {
+		return nil, nil
+	}
+	p := LookupConditionProvider(name)
+	ns, _, _ := strings.Cut(name, ".")
+	rest := strings.TrimSpace(expr[len(name):])
+	hasArgs := strings.HasPrefix(rest, "(")
+
+	if p == nil {
+		// A call in a provider namespace (vcs.dirty()) is a typo, not a
+		// step field; vcs.dirty == true is left to the field pattern.
+		if isProviderNamespace(ns) && hasArgs {
+			return nil, fmt.Errorf("unknown condition provider %q (available: %s)", name, providerNames())
+		}
+		return nil, nil
+	}
+	if !hasArgs && p.MinArgs > 0 {
+		// e.g. a step named "bead" with field "status"
+		return nil, nil
+	}
+
+	cond := &Condition{Raw: expr, Type: ConditionTypeExternal, ExternalType: name}
+	if hasArgs {
+		end, err := matchingParen(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		args, err := splitArgs(rest[1:end])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		cond.ExternalArgs = args
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	if n := len(cond.ExternalArgs); n < p.MinArgs || n > p.MaxArgs {
+		return nil, fmt.Errorf("%s takes %s, got %d (usage: %s)", name, argCount(p), n, p.Usage)
+	}
+	if len(cond.ExternalArgs) > 0 {
+		cond.ExternalArg = cond.ExternalArgs[0]
+	}
+
+	if rest == "" {
+		rest = p.Bare
+		if rest == "" {
+			return nil, fmt.Errorf("%s returns a %s and needs a comparison (e.g. %s == 'value')", name, p.Returns, p.Usage)
+		}
+	}
+	m := comparePattern.FindStringSubmatch(rest)
+	if m == nil {
+		return nil, fmt.Errorf("unexpected %q after %s (expected a comparison such as == 'value')", rest, name)
+	}
+	cond.Operator = Operator(m[1])
+	cond.Value = unquote(m[2])
+	return cond, nil
+}
+
 func (c *Condition) evaluateExternal(ctx *ConditionContext) (*ConditionResult, error) {
-	switch c.ExternalType {
-	case "file.exists":
-		path := c.ExternalArg
-		// Substitute variables
+	p := LookupConditionProvider(c.ExternalType)
+	if p == nil {
+		return nil, fmt.Errorf("unknown external type: %s", c.ExternalType)
+	}
+	if p.Shell && !ctx.Trusted {
+		return nil, fmt.Errorf("%s: %w", c.ExternalType, ErrUntrusted)
+	}
+
+	// Substitute variables
+	args := make([]string, len(c.ExternalArgs))
+	for i, arg := range c.ExternalArgs {
 		for k, v := range ctx.Vars {
-			path = strings.ReplaceAll(path, "{{"+k+"}}", v)
+			arg = strings.ReplaceAll(arg, "{{"+k+"}}", v)
 		}
-		_, err := os.Stat(path)
-		exists := err == nil
-		return &ConditionResult{
-			Satisfied: exists,
-			Reason:    fmt.Sprintf("file %q exists: %v", path, exists),
-		}, nil
+		args[i] = arg
+	}
 
-	case "env":
-		actual := os.Getenv(c.ExternalArg)
-		satisfied, reason := compare(actual, c.Operator, c.Value)
-		return &ConditionResult{
-			Satisfied: satisfied,
-			Reason:    reason,
-		}, nil
+	actual, err := p.Eval(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.ExternalType, err)
+	}
+	satisfied, reason := compare(actual, c.Operator, c.Value)
+	return &ConditionResult{
+		Satisfied: satisfied,
+		Reason:    fmt.Sprintf("%s: %s", describeCall(c.ExternalType, args), reason),
+	}, nil
+}
+
+// describeCall renders a provider call for reasons, e.g. file.exists("go.mod").
+func describeCall(name string, args []string) string {
+	if len(args) == 0 {
+		return name
+	}
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = strconv.Quote(a)
+	}
+	return name + "(" + strings.Join(quoted, ", ") + ")"
+}
+
+func argCount(p *ConditionProvider) string {
+	switch {
+	case p.MinArgs == p.MaxArgs && p.MaxArgs == 1:
+		return "1 argument"
+	case p.MinArgs == p.MaxArgs:
+		return fmt.Sprintf("%d arguments", p.MaxArgs)
+	default:
+		return fmt.Sprintf("%d-%d arguments", p.MinArgs, p.MaxArgs)
+	}
+}
+
+// matchingParen returns the index of the ')' closing the '(' at s[0],
+// skipping quoted strings.
+func matchingParen(s string) (int, error) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	if quote != 0 {
+		return 0, fmt.Errorf("unterminated string")
 	}
+	return 0, fmt.Errorf("missing closing parenthesis")
+}
 
-	return nil, fmt.Errorf("unknown external type: %s", c.ExternalType)
+// splitArgs splits comma-separated arguments, unquoting quoted ones.
+func splitArgs(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var args []string
+	var current strings.Builder
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			current.WriteByte(ch)
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+			current.WriteByte(ch)
+		case ch == ',':
+			args = append(args, unquote(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	args = append(args, unquote(current.String()))
+	for _, a := range args {
+		if a == "" {
+			return nil, fmt.Errorf("empty argument")
+		}
+	}
+	return args, nil
 }
 
 // Helper functions
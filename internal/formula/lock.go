@@ -0,0 +1,335 @@
+package formula
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Formula package layout inside a project's .beads directory.
+const (
+	// LockFileName is the lockfile pinning installed formula packages.
+	LockFileName = "formulas.lock"
+
+	// PackagesDir is the directory (under .beads/formulas) holding installed packages.
+	PackagesDir = "packages"
+
+	// lockVersion is the current lockfile format version.
+	lockVersion = 1
+)
+
+// Lock pins installed formula packages to exact content hashes.
+// It is stored as JSON in .beads/formulas.lock and meant to be committed.
+type Lock struct {
+	Version  int                       `json:"version"`
+	Packages map[string]*LockedPackage `json:"packages"`
+
+	// path is the lockfile location, set by LoadLock.
+	path string
+}
+
+// LockedPackage records one installed package.
+type LockedPackage struct {
+	Name     string `json:"-"`
+	Version  string `json:"version"`
+	Source   string `json:"source"`             // Directory or git URL[#ref] as requested
+	Resolved string `json:"resolved,omitempty"` // Commit for git sources
+
+	// Hash covers every file in Files; Files maps file name to its sha256.
+	Hash  string            `json:"hash"`
+	Files map[string]string `json:"files"`
+
+	// Dependencies maps package name to the version the manifest requires.
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// LockPath returns the lockfile path for a .beads directory.
+func LockPath(beadsDir string) string {
+	return filepath.Join(beadsDir, LockFileName)
+}
+
+// PackageDir returns the install directory of a package.
+func PackageDir(beadsDir, name string) string {
+	return filepath.Join(beadsDir, "formulas", PackagesDir, name)
+}
+
+// PackageSearchPaths returns the directories of installed packages under
+// beadsDir, sorted by package name. Missing directories yield nil.
+func PackageSearchPaths(beadsDir string) []string {
+	root := filepath.Join(beadsDir, "formulas", PackagesDir)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			paths = append(paths, filepath.Join(root, entry.Name()))
+		}
+	}
+	return paths
+}
+
+// LoadLock reads the lockfile in beadsDir.
+// A missing lockfile yields an empty lock, so callers can always install into it.
+func LoadLock(beadsDir string) (*Lock, error) {
+	path := LockPath(beadsDir)
+	lock := &Lock{Version: lockVersion, Packages: make(map[string]*LockedPackage), path: path}
+
+	// #nosec G304 -- path is derived from the project .beads directory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", LockFileName, err)
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", LockFileName, err)
+	}
+	if lock.Version > lockVersion {
+		return nil, fmt.Errorf("%s version %d is newer than supported (%d); upgrade bd", LockFileName, lock.Version, lockVersion)
+	}
+	if lock.Packages == nil {
+		lock.Packages = make(map[string]*LockedPackage)
+	}
+	for name, pkg := range lock.Packages {
+		pkg.Name = name
+	}
+	return lock, nil
+}
+
+// Exists reports whether the lock was read from disk or has packages.
+func (l *Lock) Exists() bool {
+	if len(l.Packages) > 0 {
+		return true
+	}
+	_, err := os.Stat(l.path)
+	return err == nil
+}
+
+// Save writes the lockfile atomically.
+func (l *Lock) Save() error {
+	l.Version = lockVersion
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", LockFileName, err)
+	}
+	data = append(data, '\n')
+
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write %s: %w", LockFileName, err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write %s: %w", LockFileName, err)
+	}
+	return nil
+}
+
+// Names returns locked package names in sorted order.
+func (l *Lock) Names() []string {
+	names := make([]string, 0, len(l.Packages))
+	for name := range l.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Provider returns the locked package that ships the formula with the given
+// name (file stem), or nil if no package provides it.
+func (l *Lock) Provider(formulaName string) *LockedPackage {
+	for _, name := range l.Names() {
+		pkg := l.Packages[name]
+		for file := range pkg.Files {
+			if formulaStem(file) == formulaName {
+				return pkg
+			}
+		}
+	}
+	return nil
+}
+
+// LockError reports a mismatch between installed files and the lockfile.
+type LockError struct {
+	Package string `json:"package"`
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *LockError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("package %s: %s: %s", e.Package, e.File, e.Message)
+	}
+	return fmt.Sprintf("package %s: %s", e.Package, e.Message)
+}
+
+// checkFile verifies a file read from an installed package against the lock.
+// Files outside the packages directory are not checked.
+func (l *Lock) checkFile(beadsDir, absPath string, data []byte) error {
+	rel, err := filepath.Rel(filepath.Join(beadsDir, "formulas", PackagesDir), absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	parts := strings.SplitN(filepath.ToSlash(rel), "/", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	pkgName, file := parts[0], parts[1]
+
+	pkg, ok := l.Packages[pkgName]
+	if !ok {
+		return &LockError{Package: pkgName, Message: "installed but not in " + LockFileName + "; run 'bd formula install' to restore it"}
+	}
+	want, ok := pkg.Files[file]
+	if !ok {
+		return &LockError{Package: pkgName, File: file, Message: "not in " + LockFileName}
+	}
+	if got := hashBytes(data); got != want {
+		return &LockError{Package: pkgName, File: file,
+			Message: fmt.Sprintf("content hash %s does not match lock %s; run 'bd formula install' to restore or 'bd formula update %s' to accept", shortHash(got), shortHash(want), pkgName)}
+	}
+	return nil
+}
+
+// Verify checks every locked package against the files installed under beadsDir
+// and returns all mismatches. It never stops at the first problem so
+// 'bd formula verify' can report everything at once.
+func (l *Lock) Verify(beadsDir string) []*LockError {
+	var problems []*LockError
+
+	for _, name := range l.Names() {
+		pkg := l.Packages[name]
+		dir := PackageDir(beadsDir, name)
+		if _, err := os.Stat(dir); err != nil {
+			problems = append(problems, &LockError{Package: name, Message: "not installed; run 'bd formula install'"})
+			continue
+		}
+		if packageHash(pkg.Files) != pkg.Hash {
+			problems = append(problems, &LockError{Package: name, Message: "package hash does not match its file hashes; lockfile was edited by hand"})
+		}
+
+		var files []string
+		for file := range pkg.Files {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			// #nosec G304 -- file names come from the lockfile and are joined to the package dir
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+			if err != nil {
+				problems = append(problems, &LockError{Package: name, File: file, Message: "missing"})
+				continue
+			}
+			if err := l.checkFile(beadsDir, filepath.Join(dir, file), data); err != nil {
+				var lockErr *LockError
+				if errors.As(err, &lockErr) {
+					problems = append(problems, lockErr)
+				}
+			}
+		}
+
+		// Files added by hand would be picked up by the search paths
+		extra, _ := packageFiles(dir)
+		for _, file := range extra {
+			if _, ok := pkg.Files[file]; !ok {
+				problems = append(problems, &LockError{Package: name, File: file, Message: "not in " + LockFileName})
+			}
+		}
+
+		var deps []string
+		for dep := range pkg.Dependencies {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+		for _, dep := range deps {
+			want := pkg.Dependencies[dep]
+			got, ok := l.Packages[dep]
+			switch {
+			case !ok:
+				problems = append(problems, &LockError{Package: name, Message: fmt.Sprintf("dependency %s is not installed", dep)})
+			case !VersionMatches(got.Version, want):
+				problems = append(problems, &LockError{Package: name, Message: fmt.Sprintf("requires %s %s, but %s is locked", dep, want, got.Version)})
+			}
+		}
+	}
+
+	// Package directories without a lock entry
+	for _, dir := range PackageSearchPaths(beadsDir) {
+		name := filepath.Base(dir)
+		if _, ok := l.Packages[name]; !ok {
+			problems = append(problems, &LockError{Package: name, Message: "installed but not in " + LockFileName})
+		}
+	}
+
+	return problems
+}
+
+// packageFiles lists the manifest and formula files at the top of dir.
+func packageFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		if name == ManifestFileName || strings.HasSuffix(name, FormulaExtTOML) || strings.HasSuffix(name, FormulaExtJSON) {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// formulaStem strips the formula extension from a file name.
+// Non-formula files (the manifest) return "".
+func formulaStem(file string) string {
+	for _, ext := range []string{FormulaExtTOML, FormulaExtJSON} {
+		if strings.HasSuffix(file, ext) {
+			return strings.TrimSuffix(file, ext)
+		}
+	}
+	return ""
+}
+
+// hashBytes returns the sha256 content hash used in the lockfile.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// packageHash combines per-file hashes into a single package hash.
+func packageHash(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%s\n", name, files[name])
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// shortHash abbreviates a content hash for messages.
+func shortHash(hash string) string {
+	digest := strings.TrimPrefix(hash, "sha256:")
+	if len(digest) > 12 {
+		digest = digest[:12]
+	}
+	return digest
+}
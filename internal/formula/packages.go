@@ -0,0 +1,468 @@
+package formula
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/mod/semver"
+)
+
+// ManifestFileName is the manifest at the root of a formula package.
+const ManifestFileName = "formula-package.toml"
+
+// PackageManifest describes a formula package: a directory of formula files
+// that is installed and locked as a unit.
+//
+//	name = "review-kit"
+//	version = "1.2.0"
+//
+//	[dependencies]
+//	base-kit = { source = "../base-kit", version = "1.2" }
+type PackageManifest struct {
+	Name         string                       `toml:"name" json:"name"`
+	Version      string                       `toml:"version" json:"version"`
+	Description  string                       `toml:"description,omitempty" json:"description,omitempty"`
+	Dependencies map[string]PackageDependency `toml:"dependencies,omitempty" json:"dependencies,omitempty"`
+}
+
+// PackageDependency is a package required by another package.
+// Source uses the same syntax as 'bd formula install'; relative directory
+// sources are resolved against the depending package's directory.
+// Version, if set, is a version prefix ("1", "1.2", "1.2.3") the installed
+// dependency must match.
+type PackageDependency struct {
+	Source  string `toml:"source" json:"source"`
+	Version string `toml:"version,omitempty" json:"version,omitempty"`
+}
+
+// packageNamePattern restricts package names to safe directory names.
+var packageNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// LoadManifest reads and validates the manifest in dir.
+func LoadManifest(dir string) (*PackageManifest, error) {
+	path := filepath.Join(dir, ManifestFileName)
+	// #nosec G304 -- path is the manifest inside a package directory chosen by the user
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: no %s (not a formula package)", dir, ManifestFileName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var m PackageManifest
+	if err := toml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Validate checks the manifest fields.
+func (m *PackageManifest) Validate() error {
+	if !packageNamePattern.MatchString(m.Name) {
+		return fmt.Errorf("invalid package name %q (use lowercase letters, digits, '.', '_' and '-')", m.Name)
+	}
+	if !semver.IsValid("v" + m.Version) {
+		return fmt.Errorf("package %s: invalid version %q (want semver like 1.2.0)", m.Name, m.Version)
+	}
+	for name, dep := range m.Dependencies {
+		if !packageNamePattern.MatchString(name) {
+			return fmt.Errorf("package %s: invalid dependency name %q", m.Name, name)
+		}
+		if dep.Source == "" {
+			return fmt.Errorf("package %s: dependency %s has no source", m.Name, name)
+		}
+		if dep.Version != "" && !semver.IsValid("v"+dep.Version) {
+			return fmt.Errorf("package %s: dependency %s has invalid version %q", m.Name, name, dep.Version)
+		}
+	}
+	return nil
+}
+
+// VersionMatches reports whether version satisfies a dependency version
+// prefix: "1" matches 1.x.y, "1.2" matches 1.2.y, "1.2.3" matches exactly.
+// An empty want matches any version.
+func VersionMatches(version, want string) bool {
+	if want == "" {
+		return true
+	}
+	return strings.HasPrefix(version+".", want+".")
+}
+
+// PackageSource is a parsed install source.
+type PackageSource struct {
+	Git      bool   // Clone Location with git instead of copying a directory
+	Location string // Directory path or git URL
+	Ref      string // Branch, tag or commit for git sources (from "#ref")
+}
+
+// ParsePackageSource parses an install source: a local directory, or a git
+// URL with an optional "#ref". URLs with a scheme, scp-style "git@host:path",
+// a "git+" prefix or a ".git" suffix are treated as git.
+func ParsePackageSource(s string) PackageSource {
+	loc, ref, _ := strings.Cut(s, "#")
+	isGit := strings.HasPrefix(loc, "git+") ||
+		strings.HasPrefix(loc, "git@") ||
+		strings.Contains(loc, "://") ||
+		strings.HasSuffix(loc, ".git")
+	if !isGit {
+		return PackageSource{Location: s}
+	}
+	return PackageSource{Git: true, Location: strings.TrimPrefix(loc, "git+"), Ref: ref}
+}
+
+// GitFetcher clones url into dst, checks out ref (the default branch when
+// empty) and returns the resolved commit. The formula package does not run
+// git itself; callers supply a fetcher backed by internal/vcs.
+type GitFetcher func(ctx context.Context, url, ref, dst string) (commit string, err error)
+
+// Installer installs formula packages into a project's .beads directory and
+// records them in the lockfile.
+type Installer struct {
+	beadsDir string
+	fetch    GitFetcher
+	lock     *Lock
+
+	// installing tracks packages on the current dependency chain (cycle detection).
+	installing map[string]bool
+
+	// Installed lists packages written by the last operation, dependencies first.
+	Installed []*LockedPackage
+}
+
+// NewInstaller loads the lockfile in beadsDir and returns an installer.
+// fetch may be nil if only directory sources are used.
+func NewInstaller(beadsDir string, fetch GitFetcher) (*Installer, error) {
+	abs, err := filepath.Abs(beadsDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve path: %w", err)
+	}
+	lock, err := LoadLock(abs)
+	if err != nil {
+		return nil, err
+	}
+	return &Installer{beadsDir: abs, fetch: fetch, lock: lock, installing: make(map[string]bool)}, nil
+}
+
+// Lock returns the installer's lockfile.
+func (in *Installer) Lock() *Lock {
+	return in.lock
+}
+
+// Install installs the package at source and its dependencies, replacing any
+// installed version, then saves the lockfile.
+func (in *Installer) Install(ctx context.Context, source string) (*LockedPackage, error) {
+	in.Installed = nil
+	pkg, err := in.install(ctx, in.normalizeSource(source, ""), "")
+	if err != nil {
+		return nil, err
+	}
+	return pkg, in.lock.Save()
+}
+
+// Update reinstalls the named packages (all locked packages when none are
+// given) from their recorded sources, picking up new content and commits.
+func (in *Installer) Update(ctx context.Context, names ...string) ([]*LockedPackage, error) {
+	in.Installed = nil
+	if len(names) == 0 {
+		names = in.lock.Names()
+	}
+	var updated []*LockedPackage
+	for _, name := range names {
+		locked, ok := in.lock.Packages[name]
+		if !ok {
+			return nil, fmt.Errorf("package %s is not installed", name)
+		}
+		pkg, err := in.install(ctx, locked.Source, name)
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, pkg)
+	}
+	return updated, in.lock.Save()
+}
+
+// Restore reinstalls locked packages whose files are missing or modified,
+// fetching the exact locked content (the resolved commit for git sources).
+// It fails if a source no longer produces the locked hash. Restore never
+// changes the lockfile.
+func (in *Installer) Restore(ctx context.Context) ([]string, error) {
+	in.Installed = nil
+	broken := make(map[string]bool)
+	for _, problem := range in.lock.Verify(in.beadsDir) {
+		if _, ok := in.lock.Packages[problem.Package]; ok {
+			broken[problem.Package] = true
+		}
+	}
+
+	var restored []string
+	for _, name := range in.lock.Names() {
+		if !broken[name] {
+			continue
+		}
+		locked := in.lock.Packages[name]
+		src := ParsePackageSource(locked.Source)
+		if src.Git && locked.Resolved != "" {
+			src.Ref = locked.Resolved
+		}
+		staged, err := in.stage(ctx, src, in.resolveLocal(locked.Source))
+		if err != nil {
+			return restored, fmt.Errorf("restore %s: %w", name, err)
+		}
+		err = func() error {
+			defer staged.cleanup()
+			if staged.manifest.Name != name {
+				return fmt.Errorf("restore %s: source now provides package %s", name, staged.manifest.Name)
+			}
+			if packageHash(staged.files) != locked.Hash {
+				return fmt.Errorf("restore %s: source %s no longer matches %s; run 'bd formula update %s'", name, locked.Source, LockFileName, name)
+			}
+			return in.write(staged)
+		}()
+		if err != nil {
+			return restored, err
+		}
+		restored = append(restored, name)
+	}
+	return restored, nil
+}
+
+// stagedPackage is a fetched package that has not been written yet.
+type stagedPackage struct {
+	root     string
+	manifest *PackageManifest
+	files    map[string]string // file name -> hash
+	resolved string
+	tmpDir   string
+}
+
+func (s *stagedPackage) cleanup() {
+	if s.tmpDir != "" {
+		_ = os.RemoveAll(s.tmpDir)
+	}
+}
+
+// install installs one package and its dependencies. expect, when set, is the
+// package name the source must provide (used by Update).
+func (in *Installer) install(ctx context.Context, source, expect string) (*LockedPackage, error) {
+	src := ParsePackageSource(source)
+	staged, err := in.stage(ctx, src, in.resolveLocal(source))
+	if err != nil {
+		return nil, err
+	}
+	defer staged.cleanup()
+
+	m := staged.manifest
+	if expect != "" && m.Name != expect {
+		return nil, fmt.Errorf("%s now provides package %s, not %s", source, m.Name, expect)
+	}
+	if in.installing[m.Name] {
+		return nil, fmt.Errorf("dependency cycle through package %s", m.Name)
+	}
+	in.installing[m.Name] = true
+	defer delete(in.installing, m.Name)
+
+	// Dependencies first, so a failure leaves this package untouched
+	deps := make(map[string]string)
+	depNames := make([]string, 0, len(m.Dependencies))
+	for name := range m.Dependencies {
+		depNames = append(depNames, name)
+	}
+	sort.Strings(depNames)
+	for _, name := range depNames {
+		dep := m.Dependencies[name]
+		deps[name] = dep.Version
+		if locked, ok := in.lock.Packages[name]; ok && VersionMatches(locked.Version, dep.Version) {
+			continue
+		}
+		depSource, err := in.dependencySource(src, staged.root, dep.Source)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: dependency %s: %w", m.Name, name, err)
+		}
+		installed, err := in.install(ctx, depSource, "")
+		if err != nil {
+			return nil, fmt.Errorf("package %s: dependency %s: %w", m.Name, name, err)
+		}
+		if installed.Name != name {
+			return nil, fmt.Errorf("package %s: dependency %s: %s provides package %s", m.Name, name, dep.Source, installed.Name)
+		}
+		if !VersionMatches(installed.Version, dep.Version) {
+			return nil, fmt.Errorf("package %s requires %s %s, but %s provides %s", m.Name, name, dep.Version, dep.Source, installed.Version)
+		}
+	}
+
+	// Two packages must not provide the same formula name, or search order
+	// would silently decide which one extends resolves to
+	for file := range staged.files {
+		stem := formulaStem(file)
+		if stem == "" {
+			continue
+		}
+		if owner := in.lock.Provider(stem); owner != nil && owner.Name != m.Name {
+			return nil, fmt.Errorf("package %s: formula %s is already provided by package %s", m.Name, stem, owner.Name)
+		}
+	}
+
+	if err := in.write(staged); err != nil {
+		return nil, err
+	}
+
+	pkg := &LockedPackage{
+		Name:     m.Name,
+		Version:  m.Version,
+		Source:   source,
+		Resolved: staged.resolved,
+		Hash:     packageHash(staged.files),
+		Files:    staged.files,
+	}
+	if len(deps) > 0 {
+		pkg.Dependencies = deps
+	}
+	in.lock.Packages[m.Name] = pkg
+	in.Installed = append(in.Installed, pkg)
+	return pkg, nil
+}
+
+// stage fetches a source and validates its manifest and formulas without
+// touching the project. dir is the absolute directory for local sources.
+func (in *Installer) stage(ctx context.Context, src PackageSource, dir string) (*stagedPackage, error) {
+	staged := &stagedPackage{root: dir}
+	if src.Git {
+		if in.fetch == nil {
+			return nil, fmt.Errorf("cannot install %s: git sources are not supported here", src.Location)
+		}
+		tmp, err := os.MkdirTemp("", "bd-formula-pkg-*")
+		if err != nil {
+			return nil, fmt.Errorf("create staging dir: %w", err)
+		}
+		staged.tmpDir = tmp
+		staged.root = filepath.Join(tmp, "src")
+		commit, err := in.fetch(ctx, src.Location, src.Ref, staged.root)
+		if err != nil {
+			staged.cleanup()
+			return nil, fmt.Errorf("fetch %s: %w", src.Location, err)
+		}
+		staged.resolved = commit
+	}
+
+	manifest, err := LoadManifest(staged.root)
+	if err != nil {
+		staged.cleanup()
+		return nil, err
+	}
+	staged.manifest = manifest
+
+	files, err := packageFiles(staged.root)
+	if err != nil {
+		staged.cleanup()
+		return nil, fmt.Errorf("read package %s: %w", manifest.Name, err)
+	}
+	staged.files = make(map[string]string)
+	parser := NewParser(staged.root)
+	formulas := 0
+	for _, file := range files {
+		path := filepath.Join(staged.root, file)
+		// #nosec G304 -- path is a formula file inside the package being installed
+		data, err := os.ReadFile(path)
+		if err != nil {
+			staged.cleanup()
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		if stem := formulaStem(file); stem != "" {
+			f, err := parser.ParseFile(path)
+			if err != nil {
+				staged.cleanup()
+				return nil, fmt.Errorf("package %s: %w", manifest.Name, err)
+			}
+			if f.Formula != stem {
+				staged.cleanup()
+				return nil, fmt.Errorf("package %s: %s declares formula %q; file name must match", manifest.Name, file, f.Formula)
+			}
+			formulas++
+		}
+		staged.files[file] = hashBytes(data)
+	}
+	if formulas == 0 {
+		staged.cleanup()
+		return nil, fmt.Errorf("package %s contains no formula files", manifest.Name)
+	}
+	return staged, nil
+}
+
+// write replaces the installed copy of a staged package.
+func (in *Installer) write(staged *stagedPackage) error {
+	dst := PackageDir(in.beadsDir, staged.manifest.Name)
+	if err := os.RemoveAll(dst); err != nil {
+		return fmt.Errorf("remove old %s: %w", staged.manifest.Name, err)
+	}
+	if err := os.MkdirAll(dst, 0750); err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	for file := range staged.files {
+		// #nosec G304 -- file was listed from the staged package directory
+		data, err := os.ReadFile(filepath.Join(staged.root, file))
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file, err)
+		}
+		if hashBytes(data) != staged.files[file] {
+			return fmt.Errorf("package %s: %s changed during install", staged.manifest.Name, file)
+		}
+		if err := os.WriteFile(filepath.Join(dst, file), data, 0600); err != nil {
+			return fmt.Errorf("write %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// dependencySource resolves a dependency source declared by a package.
+func (in *Installer) dependencySource(parent PackageSource, parentRoot, dep string) (string, error) {
+	if ParsePackageSource(dep).Git || filepath.IsAbs(dep) {
+		return dep, nil
+	}
+	if parent.Git {
+		return "", fmt.Errorf("relative source %q is not allowed in a git package", dep)
+	}
+	return in.normalizeSource(dep, parentRoot), nil
+}
+
+// normalizeSource records local directories relative to the project root
+// (the parent of .beads) so the lockfile stays portable. Relative paths are
+// resolved against base, or the working directory when base is empty, and
+// stay relative; absolute paths stay absolute unless inside the project.
+func (in *Installer) normalizeSource(source, base string) string {
+	if ParsePackageSource(source).Git {
+		return source
+	}
+	path := source
+	relative := !filepath.IsAbs(path)
+	if relative {
+		if base == "" {
+			if cwd, err := os.Getwd(); err == nil {
+				base = cwd
+			}
+		}
+		path = filepath.Join(base, path)
+	}
+	if rel, err := filepath.Rel(filepath.Dir(in.beadsDir), path); err == nil && (relative || !strings.HasPrefix(rel, "..")) {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Clean(path)
+}
+
+// resolveLocal turns a recorded directory source back into an absolute path.
+func (in *Installer) resolveLocal(source string) string {
+	if ParsePackageSource(source).Git || filepath.IsAbs(source) {
+		return source
+	}
+	return filepath.Join(filepath.Dir(in.beadsDir), filepath.FromSlash(source))
+}
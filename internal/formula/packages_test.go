@@ -0,0 +1,357 @@
+package formula
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePackage creates a formula package directory with a manifest and formulas.
+func writePackage(t *testing.T, dir, manifest string, formulas map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range formulas {
+		if err := os.WriteFile(filepath.Join(dir, name+FormulaExtTOML), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const baseFormula = `
+formula = "base-review"
+[[steps]]
+id = "review"
+title = "Review"
+`
+
+const childFormula = `
+formula = "deep-review"
+extends = ["base-review"]
+[[steps]]
+id = "audit"
+title = "Audit"
+needs = ["review"]
+`
+
+func setupPackages(t *testing.T) (project, beadsDir, kitDir string) {
+	t.Helper()
+	project = t.TempDir()
+	beadsDir = filepath.Join(project, ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	libs := filepath.Join(project, "libs")
+	writePackage(t, filepath.Join(libs, "base-kit"), `
+name = "base-kit"
+version = "1.2.0"
+`, map[string]string{"base-review": baseFormula})
+
+	kitDir = filepath.Join(libs, "review-kit")
+	writePackage(t, kitDir, `
+name = "review-kit"
+version = "0.3.1"
+
+[dependencies]
+base-kit = { source = "../base-kit", version = "1.2" }
+`, map[string]string{"deep-review": childFormula})
+	return project, beadsDir, kitDir
+}
+
+func TestInstallPackageWithDependencies(t *testing.T) {
+	_, beadsDir, kitDir := setupPackages(t)
+
+	in, err := NewInstaller(beadsDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := in.Install(context.Background(), kitDir)
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if pkg.Name != "review-kit" || pkg.Version != "0.3.1" || pkg.Dependencies["base-kit"] != "1.2" {
+		t.Errorf("installed package = %+v", pkg)
+	}
+	if len(in.Installed) != 2 || in.Installed[0].Name != "base-kit" {
+		t.Errorf("Installed = %v, want base-kit first", in.Installed)
+	}
+
+	lock, err := LoadLock(beadsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lock.Packages["review-kit"].Source; got != "libs/review-kit" {
+		t.Errorf("source recorded as %q, want path relative to project", got)
+	}
+	if got := lock.Packages["base-kit"].Source; got != "libs/base-kit" {
+		t.Errorf("dependency source recorded as %q", got)
+	}
+	if problems := lock.Verify(beadsDir); len(problems) != 0 {
+		t.Errorf("Verify after install: %v", problems)
+	}
+
+	// Installed packages are on the search path and extends resolves across them
+	parser := NewParser(append([]string{filepath.Join(beadsDir, "formulas")}, PackageSearchPaths(beadsDir)...)...)
+	if err := parser.SetLock(lock, beadsDir); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parser.LoadByName("deep-review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := parser.Resolve(f)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(resolved.Steps) != 2 || resolved.Steps[0].ID != "review" {
+		t.Errorf("resolved steps = %v", resolved.Steps)
+	}
+}
+
+func TestLockDetectsDrift(t *testing.T) {
+	_, beadsDir, kitDir := setupPackages(t)
+	in, err := NewInstaller(beadsDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Install(context.Background(), kitDir); err != nil {
+		t.Fatal(err)
+	}
+	lock := in.Lock()
+	search := append([]string{filepath.Join(beadsDir, "formulas")}, PackageSearchPaths(beadsDir)...)
+
+	// A project formula shadowing a locked parent is rejected
+	shadow := filepath.Join(beadsDir, "formulas", "base-review"+FormulaExtTOML)
+	if err := os.WriteFile(shadow, []byte(baseFormula), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parser := NewParser(search...)
+	_ = parser.SetLock(lock, beadsDir)
+	f, err := parser.LoadByName("deep-review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.Resolve(f); err == nil || !strings.Contains(err.Error(), "locked to package base-kit@1.2.0") {
+		t.Errorf("shadowed parent error = %v", err)
+	}
+	if err := os.Remove(shadow); err != nil {
+		t.Fatal(err)
+	}
+
+	// Editing an installed file breaks verification and parsing
+	installed := filepath.Join(PackageDir(beadsDir, "base-kit"), "base-review"+FormulaExtTOML)
+	if err := os.WriteFile(installed, []byte(strings.Replace(baseFormula, "Review", "Skim", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	problems := lock.Verify(beadsDir)
+	if len(problems) != 1 || problems[0].Package != "base-kit" || !strings.Contains(problems[0].Message, "does not match lock") {
+		t.Errorf("Verify problems = %v", problems)
+	}
+	parser = NewParser(search...)
+	_ = parser.SetLock(lock, beadsDir)
+	if _, err := parser.LoadByName("base-review"); err == nil || !strings.Contains(err.Error(), "does not match lock") {
+		t.Errorf("tampered parse error = %v", err)
+	}
+
+	// Restore brings back the locked content without touching the lock
+	restored, err := in.Restore(context.Background())
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if len(restored) != 1 || restored[0] != "base-kit" {
+		t.Errorf("restored = %v", restored)
+	}
+	if problems := lock.Verify(beadsDir); len(problems) != 0 {
+		t.Errorf("Verify after restore: %v", problems)
+	}
+
+	// Unlocked files and packages are reported
+	if err := os.WriteFile(filepath.Join(PackageDir(beadsDir, "base-kit"), "extra"+FormulaExtTOML), []byte(`formula = "extra"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(PackageDir(beadsDir, "stray"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	problems = lock.Verify(beadsDir)
+	if len(problems) != 2 {
+		t.Errorf("Verify problems = %v, want extra file and stray package", problems)
+	}
+}
+
+func TestUpdateAndRestoreFromChangedSource(t *testing.T) {
+	project, beadsDir, kitDir := setupPackages(t)
+	in, err := NewInstaller(beadsDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Install(context.Background(), kitDir); err != nil {
+		t.Fatal(err)
+	}
+	oldHash := in.Lock().Packages["base-kit"].Hash
+
+	// Upstream edits the source; restore refuses since it no longer matches the lock
+	baseSrc := filepath.Join(project, "libs", "base-kit", "base-review"+FormulaExtTOML)
+	if err := os.WriteFile(baseSrc, []byte(strings.Replace(baseFormula, "Review", "Skim", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(PackageDir(beadsDir, "base-kit")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Restore(context.Background()); err == nil || !strings.Contains(err.Error(), "no longer matches") {
+		t.Errorf("Restore from changed source error = %v", err)
+	}
+
+	// Update accepts the new content
+	updated, err := in.Update(context.Background(), "base-kit")
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(updated) != 1 || updated[0].Hash == oldHash {
+		t.Errorf("updated = %+v, want new hash", updated)
+	}
+	if problems := in.Lock().Verify(beadsDir); len(problems) != 0 {
+		t.Errorf("Verify after update: %v", problems)
+	}
+
+	if _, err := in.Update(context.Background(), "missing"); err == nil {
+		t.Error("Update of unknown package should fail")
+	}
+}
+
+func TestInstallErrors(t *testing.T) {
+	project, beadsDir, _ := setupPackages(t)
+	libs := filepath.Join(project, "libs")
+
+	writePackage(t, filepath.Join(libs, "old-dep"), `
+name = "needs-new"
+version = "1.0.0"
+
+[dependencies]
+base-kit = { source = "../base-kit", version = "2" }
+`, map[string]string{"needs-new": `formula = "needs-new"
+[[steps]]
+id = "a"
+title = "A"`})
+
+	writePackage(t, filepath.Join(libs, "dupe"), `
+name = "dupe"
+version = "1.0.0"
+`, map[string]string{"base-review": baseFormula})
+
+	writePackage(t, filepath.Join(libs, "bad-version"), `
+name = "bad"
+version = "latest"
+`, nil)
+
+	in, err := NewInstaller(beadsDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := map[string]string{
+		filepath.Join(libs, "old-dep"):     "requires base-kit 2, but ../base-kit provides 1.2.0",
+		filepath.Join(libs, "bad-version"): `invalid version "latest"`,
+		filepath.Join(libs, "missing"):     "not a formula package",
+		"https://example.com/kit.git":      "git sources are not supported",
+	}
+	for source, want := range tests {
+		if _, err := in.Install(ctx, source); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Install(%s) error = %v, want %q", source, err, want)
+		}
+	}
+
+	// Two packages may not provide the same formula
+	if _, err := in.Install(ctx, filepath.Join(libs, "base-kit")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Install(ctx, filepath.Join(libs, "dupe")); err == nil || !strings.Contains(err.Error(), "already provided by package base-kit") {
+		t.Errorf("duplicate formula error = %v", err)
+	}
+}
+
+func TestInstallFromGit(t *testing.T) {
+	_, beadsDir, _ := setupPackages(t)
+	var gotURL, gotRef string
+	fetch := func(ctx context.Context, url, ref, dst string) (string, error) {
+		gotURL, gotRef = url, ref
+		writePackage(t, dst, `
+name = "git-kit"
+version = "2.0.0"
+`, map[string]string{"git-flow": `formula = "git-flow"
+[[steps]]
+id = "a"
+title = "A"`})
+		return "0123456789abcdef", nil
+	}
+
+	in, err := NewInstaller(beadsDir, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := in.Install(context.Background(), "git+file:///srv/kit#v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotURL != "file:///srv/kit" || gotRef != "v2" {
+		t.Errorf("fetch(%q, %q)", gotURL, gotRef)
+	}
+	if pkg.Resolved != "0123456789abcdef" || pkg.Source != "git+file:///srv/kit#v2" {
+		t.Errorf("locked git package = %+v", pkg)
+	}
+
+	// Restore fetches the pinned commit, not the branch
+	if err := os.RemoveAll(PackageDir(beadsDir, "git-kit")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Restore(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if gotRef != "0123456789abcdef" {
+		t.Errorf("restore fetched ref %q, want locked commit", gotRef)
+	}
+}
+
+func TestParsePackageSource(t *testing.T) {
+	tests := []struct {
+		in   string
+		want PackageSource
+	}{
+		{"../kit", PackageSource{Location: "../kit"}},
+		{"/abs/kit#notaref", PackageSource{Location: "/abs/kit#notaref"}},
+		{"https://github.com/org/kit.git", PackageSource{Git: true, Location: "https://github.com/org/kit.git"}},
+		{"git@github.com:org/kit.git#v1.2.0", PackageSource{Git: true, Location: "git@github.com:org/kit.git", Ref: "v1.2.0"}},
+		{"git+file:///srv/kit#main", PackageSource{Git: true, Location: "file:///srv/kit", Ref: "main"}},
+	}
+	for _, tt := range tests {
+		if got := ParsePackageSource(tt.in); got != tt.want {
+			t.Errorf("ParsePackageSource(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVersionMatches(t *testing.T) {
+	tests := []struct {
+		version, want string
+		match         bool
+	}{
+		{"1.2.3", "", true},
+		{"1.2.3", "1", true},
+		{"1.2.3", "1.2", true},
+		{"1.2.3", "1.2.3", true},
+		{"1.20.0", "1.2", false},
+		{"2.0.0", "1", false},
+	}
+	for _, tt := range tests {
+		if got := VersionMatches(tt.version, tt.want); got != tt.match {
+			t.Errorf("VersionMatches(%q, %q) = %v", tt.version, tt.want, got)
+		}
+	}
+}
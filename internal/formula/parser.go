@@ -35,6 +35,10 @@ type Parser struct {
 
 	// resolvingChain tracks the order of formulas being resolved (for error messages).
 	resolvingChain []string
+
+	// lock, when set, pins formulas from installed packages (see SetLock).
+	lock     *Lock
+	lockRoot string
 }
 
 // NewParser creates a new formula parser.
@@ -60,6 +64,9 @@ func defaultSearchPaths() []string {
 	// Project-level formulas
 	if cwd, err := os.Getwd(); err == nil {
 		paths = append(paths, filepath.Join(cwd, ".beads", "formulas"))
+
+		// Installed formula packages
+		paths = append(paths, PackageSearchPaths(filepath.Join(cwd, ".beads"))...)
 	}
 
 	// User-level formulas
@@ -75,6 +82,20 @@ func defaultSearchPaths() []string {
 	return paths
 }
 
+// SetLock makes the parser enforce a formula package lockfile: files read
+// from installed packages under beadsDir must match their locked hashes, and
+// a formula name provided by a locked package must resolve to that package
+// rather than a same-named file earlier in the search paths.
+func (p *Parser) SetLock(lock *Lock, beadsDir string) error {
+	abs, err := filepath.Abs(beadsDir)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+	p.lock = lock
+	p.lockRoot = abs
+	return nil
+}
+
 // ParseFile parses a formula from a file path.
 // Detects format from extension: .formula.toml or .formula.json
 func (p *Parser) ParseFile(path string) (*Formula, error) {
@@ -95,6 +116,12 @@ func (p *Parser) ParseFile(path string) (*Formula, error) {
 		return nil, fmt.Errorf("read %s: %w", path, err)
 	}
 
+	if p.lock != nil {
+		if err := p.lock.checkFile(p.lockRoot, absPath, data); err != nil {
+			return nil, err
+		}
+	}
+
 	// Detect format from extension
 	var formula *Formula
 	if strings.HasSuffix(path, FormulaExtTOML) {
@@ -251,6 +278,9 @@ func (p *Parser) loadFormula(name string) (*Formula, error) {
 		for _, ext := range extensions {
 			path := filepath.Join(dir, name+ext)
 			if _, err := os.Stat(path); err == nil {
+				if err := p.checkProvider(name, path); err != nil {
+					return nil, err
+				}
 				return p.ParseFile(path)
 			}
 		}
@@ -259,6 +289,27 @@ func (p *Parser) loadFormula(name string) (*Formula, error) {
 	return nil, fmt.Errorf("formula %q not found in search paths", name)
 }
 
+// checkProvider rejects a formula file that shadows the locked package
+// providing the same name, so extends cannot silently switch parents.
+func (p *Parser) checkProvider(name, path string) error {
+	if p.lock == nil {
+		return nil
+	}
+	pkg := p.lock.Provider(name)
+	if pkg == nil {
+		return nil
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+	if filepath.Dir(absPath) != PackageDir(p.lockRoot, pkg.Name) {
+		return fmt.Errorf("formula %q is locked to package %s@%s but resolves to %s; rename one of them",
+			name, pkg.Name, pkg.Version, absPath)
+	}
+	return nil
+}
+
 // LoadByName loads a formula by name from search paths.
 // This is the public API for loading formulas used by expansion operators.
 func (p *Parser) LoadByName(name string) (*Formula, error) {
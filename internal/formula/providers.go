@@ -0,0 +1,456 @@
+package formula
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConditionProvider evaluates one external condition function, such as
+// file.exists('go.mod') or vcs.branch. Providers are typed: Returns names the
+// kind of value Eval produces, and conditions compare against it with the
+// usual operators (vcs.branch == 'main', cmd.exit('make test') == 0).
+type ConditionProvider struct {
+	// Name is the function name used in conditions (e.g. "bead.status").
+	Name string
+
+	// Usage shows the call syntax (e.g. "bead.status(id)").
+	Usage string
+
+	// Description is a one-line explanation for listings.
+	Description string
+
+	// Returns is the value type: "bool", "string" or "int".
+	Returns string
+
+	// MinArgs and MaxArgs bound the number of arguments.
+	MinArgs, MaxArgs int
+
+	// Bare is the comparison implied when a condition has none, e.g.
+	// "== true" so file.exists('x') needs no operator. Empty means a
+	// comparison is required.
+	Bare string
+
+	// Shell marks providers that run commands. They are disabled unless
+	// ConditionContext.Trusted is set.
+	Shell bool
+
+	// Runtime marks providers that read step state, which only exists once
+	// the molecule runs (so bd cook --dry-run cannot preview them).
+	Runtime bool
+
+	// Eval computes the value for the given (variable-substituted) args.
+	Eval func(ctx *ConditionContext, args []string) (interface{}, error)
+}
+
+// BeadReader gives bead.* and label.* conditions read access to issues.
+type BeadReader interface {
+	BeadStatus(id string) (string, error)
+	BeadLabels(id string) ([]string, error)
+}
+
+// VCSReader gives vcs.* conditions read access to the repository.
+// vcs.VCS satisfies it.
+type VCSReader interface {
+	CurrentRef() (string, error)
+	HasChanges(paths ...string) (bool, error)
+}
+
+// ErrUntrusted is returned when a shell condition is evaluated for a
+// formula that is not trusted.
+var ErrUntrusted = errors.New("shell conditions are disabled for untrusted formulas")
+
+// DefaultCommandTimeout bounds cmd.exit when the condition gives no timeout.
+const DefaultCommandTimeout = 10 * time.Second
+
+// maxCommandTimeout caps any timeout a condition asks for.
+const maxCommandTimeout = 5 * time.Minute
+
+// providerNamespaces are the prefixes reserved for providers, so a typo like
+// vcs.dirty is reported instead of being read as step "vcs", field "dirty".
+var providerNamespaces = map[string]bool{}
+
+var (
+	providers      = make(map[string]*ConditionProvider)
+	providersMutex sync.RWMutex
+)
+
+// RegisterConditionProvider adds a provider. It panics if the name is taken,
+// like vcs.Register.
+func RegisterConditionProvider(p *ConditionProvider) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+
+	if p == nil || p.Eval == nil {
+		panic("formula: RegisterConditionProvider called with nil provider or Eval")
+	}
+	if _, exists := providers[p.Name]; exists {
+		panic(fmt.Sprintf("formula: RegisterConditionProvider called twice for %s", p.Name))
+	}
+	providers[p.Name] = p
+	if ns, _, ok := strings.Cut(p.Name, "."); ok {
+		providerNamespaces[ns] = true
+	} else {
+		providerNamespaces[p.Name] = true
+	}
+}
+
+// LookupConditionProvider returns the provider with the given name, or nil.
+func LookupConditionProvider(name string) *ConditionProvider {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+	return providers[name]
+}
+
+// ConditionProviders returns all registered providers sorted by name.
+func ConditionProviders() []*ConditionProvider {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+
+	result := make([]*ConditionProvider, 0, len(providers))
+	for _, p := range providers {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func isProviderNamespace(ns string) bool {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+	return providerNamespaces[ns]
+}
+
+func providerNames() string {
+	var names []string
+	for _, p := range ConditionProviders() {
+		names = append(names, p.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func init() {
+	RegisterConditionProvider(&ConditionProvider{
+		Name:        "file.exists",
+		Usage:       "file.exists(path)",
+		Description: "path exists (relative to the working directory)",
+		Returns:     "bool",
+		MinArgs:     1, MaxArgs: 1,
+		Bare: "== true",
+		Eval: func(ctx *ConditionContext, args []string) (interface{}, error) {
+			_, err := os.Stat(ctx.resolvePath(args[0]))
+			return err == nil, nil
+		},
+	})
+	RegisterConditionProvider(&ConditionProvider{
+		Name:        "env",
+		Usage:       "env.NAME or env(name)",
+		Description: "value of an environment variable",
+		Returns:     "string",
+		MinArgs:     1, MaxArgs: 1,
+		Eval: func(_ *ConditionContext, args []string) (interface{}, error) {
+			return os.Getenv(args[0]), nil
+		},
+	})
+	RegisterConditionProvider(&ConditionProvider{
+		Name:        "cmd.exit",
+		Usage:       "cmd.exit(command[, timeout])",
+		Description: "exit code of a shell command (bare: exits 0); needs a trusted formula",
+		Returns:     "int",
+		MinArgs:     1, MaxArgs: 2,
+		Bare:  "== 0",
+		Shell: true,
+		Eval:  evalCmdExit,
+	})
+	RegisterConditionProvider(&ConditionProvider{
+		Name:        "vcs.clean",
+		Usage:       "vcs.clean",
+		Description: "working copy has no uncommitted changes",
+		Returns:     "bool",
+		Bare:        "== true",
+		Eval: func(ctx *ConditionContext, _ []string) (interface{}, error) {
+			if ctx.VCS == nil {
+				return nil, fmt.Errorf("no repository available")
+			}
+			dirty, err := ctx.VCS.HasChanges()
+			if err != nil {
+				return nil, err
+			}
+			return !dirty, nil
+		},
+	})
+	RegisterConditionProvider(&ConditionProvider{
+		Name:        "vcs.branch",
+		Usage:       "vcs.branch",
+		Description: "current branch (git) or bookmark (jj)",
+		Returns:     "string",
+		Eval: func(ctx *ConditionContext, _ []string) (interface{}, error) {
+			if ctx.VCS == nil {
+				return nil, fmt.Errorf("no repository available")
+			}
+			return ctx.VCS.CurrentRef()
+		},
+	})
+	RegisterConditionProvider(&ConditionProvider{
+		Name:        "bead.status",
+		Usage:       "bead.status(id)",
+		Description: "status of an issue",
+		Returns:     "string",
+		MinArgs:     1, MaxArgs: 1,
+		Eval: func(ctx *ConditionContext, args []string) (interface{}, error) {
+			if ctx.Beads == nil {
+				return nil, fmt.Errorf("no database available")
+			}
+			return ctx.Beads.BeadStatus(args[0])
+		},
+	})
+	RegisterConditionProvider(&ConditionProvider{
+		Name:        "label.has",
+		Usage:       "label.has(id, label)",
+		Description: "issue has the label",
+		Returns:     "bool",
+		MinArgs:     2, MaxArgs: 2,
+		Bare: "== true",
+		Eval: func(ctx *ConditionContext, args []string) (interface{}, error) {
+			if ctx.Beads == nil {
+				return nil, fmt.Errorf("no database available")
+			}
+			labels, err := ctx.Beads.BeadLabels(args[0])
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range labels {
+				if l == args[1] {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	})
+	RegisterConditionProvider(&ConditionProvider{
+		Name:        "json.path",
+		Usage:       "json.path(step, '$.path[0].key')",
+		Description: "value at a JSON path in a step's output",
+		Returns:     "string",
+		MinArgs:     2, MaxArgs: 2,
+		Runtime: true,
+		Eval: func(ctx *ConditionContext, args []string) (interface{}, error) {
+			stepID := args[0]
+			if stepID == "step" {
+				stepID = ctx.CurrentStep
+			}
+			step, ok := ctx.Steps[stepID]
+			if !ok {
+				return nil, fmt.Errorf("step %q not found", stepID)
+			}
+			return jsonPath(step.Output, args[1])
+		},
+	})
+}
+
+// evalCmdExit runs a command through the shell with a timeout, in the working
+// directory, with a minimal environment (no inherited secrets).
+func evalCmdExit(ctx *ConditionContext, args []string) (interface{}, error) {
+	timeout := DefaultCommandTimeout
+	if len(args) > 1 {
+		d, err := time.ParseDuration(args[1])
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", args[1])
+		}
+		timeout = min(d, maxCommandTimeout)
+	}
+
+	runCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(runCtx, "cmd", "/C", args[0]) // #nosec G204 -- only for trusted formulas
+	} else {
+		cmd = exec.CommandContext(runCtx, "sh", "-c", args[0]) // #nosec G204 -- only for trusted formulas
+	}
+	cmd.Dir = ctx.WorkDir
+	cmd.Env = conditionEnv()
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if runCtx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%q timed out after %s", args[0], timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return 0, nil
+}
+
+// conditionEnv is the environment shell conditions run with.
+func conditionEnv() []string {
+	env := []string{"BEADS_CONDITION=1"}
+	for _, key := range []string{"PATH", "HOME", "TMPDIR", "TEMP", "LANG", "SYSTEMROOT"} {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	return env
+}
+
+// jsonPath looks up a JSONPath-style path ($.a.b[0]['c d']) in decoded JSON.
+// A missing key or index yields nil rather than an error.
+func jsonPath(root interface{}, path string) (interface{}, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+	var current interface{}
+	if m, ok := root.(map[string]interface{}); ok {
+		current = m
+	} else {
+		current = root
+	}
+
+	for p != "" {
+		var key string
+		index := -1
+		switch {
+		case p[0] == '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			key, p = p[:end], p[end:]
+			if key == "" {
+				return nil, fmt.Errorf("invalid JSON path %q: empty key", path)
+			}
+		case p[0] == '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: missing ]", path)
+			}
+			inner := strings.TrimSpace(p[1:end])
+			p = p[end+1:]
+			if n, err := strconv.Atoi(inner); err == nil {
+				index = n
+			} else if unq := unquote(inner); unq != inner {
+				key = unq
+			} else {
+				return nil, fmt.Errorf("invalid JSON path %q: bad subscript [%s]", path, inner)
+			}
+		default:
+			// Allow a leading key without "$." (e.g. "results[0]")
+			p = "." + p
+			continue
+		}
+
+		if index >= 0 {
+			arr, ok := current.([]interface{})
+			if !ok || index >= len(arr) {
+				return nil, nil
+			}
+			current = arr[index]
+			continue
+		}
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		current = m[key]
+	}
+	return current, nil
+}
+
+// resolvePath makes relative paths relative to the working directory.
+func (ctx *ConditionContext) resolvePath(path string) string {
+	if ctx.WorkDir == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(ctx.WorkDir, path)
+}
+
+// ConditionCheck reports one runtime condition found in a cooked formula.
+type ConditionCheck struct {
+	StepID   string           // Step carrying the gate or loop
+	Kind     string           // "gate" or "loop"
+	Expr     string           // Condition as written
+	Provider string           // Provider name for external conditions
+	Err      error            // Parse error, unknown provider, or untrusted shell use
+	Preview  *ConditionResult // Current result, when it can be evaluated before running
+	EvalErr  error            // Why the preview failed (e.g. no repository)
+}
+
+// CheckConditions finds the gate and loop conditions in cooked steps and
+// validates them. Conditions that neither read step state nor run commands
+// are evaluated against ctx, so bd cook --dry-run can show their current
+// value.
+func CheckConditions(steps []*Step, ctx *ConditionContext) []*ConditionCheck {
+	var checks []*ConditionCheck
+	for _, step := range steps {
+		for _, label := range step.Labels {
+			check := conditionFromLabel(step.ID, label)
+			if check == nil {
+				continue
+			}
+			check.run(ctx)
+			checks = append(checks, check)
+		}
+		checks = append(checks, CheckConditions(step.Children, ctx)...)
+	}
+	return checks
+}
+
+// conditionFromLabel extracts the condition from a gate:{...} or loop:{...}
+// label added by ApplyControlFlow.
+func conditionFromLabel(stepID, label string) *ConditionCheck {
+	kind, payload, ok := strings.Cut(label, ":")
+	if !ok || (kind != "gate" && kind != "loop") || !strings.HasPrefix(payload, "{") {
+		return nil
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(payload), &meta); err != nil {
+		return nil
+	}
+	key := "condition"
+	if kind == "loop" {
+		key = "until"
+	}
+	expr, _ := meta[key].(string)
+	if expr == "" {
+		return nil
+	}
+	return &ConditionCheck{StepID: stepID, Kind: kind, Expr: expr}
+}
+
+func (check *ConditionCheck) run(ctx *ConditionContext) {
+	cond, err := ParseCondition(check.Expr)
+	if err != nil {
+		check.Err = err
+		return
+	}
+	if cond.Type != ConditionTypeExternal {
+		return
+	}
+	check.Provider = cond.ExternalType
+	p := LookupConditionProvider(cond.ExternalType)
+	switch {
+	case p == nil:
+		check.Err = fmt.Errorf("unknown condition provider %q", cond.ExternalType)
+	case p.Shell && !ctx.Trusted:
+		check.Err = fmt.Errorf("%s: %w (trust the formula to enable it)", cond.ExternalType, ErrUntrusted)
+	case p.Shell || p.Runtime:
+		// Commands are never run and step state doesn't exist yet
+	default:
+		check.Preview, check.EvalErr = cond.Evaluate(ctx)
+	}
+}
@@ -0,0 +1,214 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type fakeVCS struct {
+	ref   string
+	dirty bool
+}
+
+func (f fakeVCS) CurrentRef() (string, error)        { return f.ref, nil }
+func (f fakeVCS) HasChanges(...string) (bool, error) { return f.dirty, nil }
+
+type fakeBeads struct {
+	status map[string]string
+	labels map[string][]string
+}
+
+func (f fakeBeads) BeadStatus(id string) (string, error) {
+	if s, ok := f.status[id]; ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("issue %s not found", id)
+}
+
+func (f fakeBeads) BeadLabels(id string) ([]string, error) {
+	return f.labels[id], nil
+}
+
+func TestParseProviderCondition(t *testing.T) {
+	tests := []struct {
+		expr     string
+		provider string
+		args     []string
+		op       Operator
+		value    string
+	}{
+		{"file.exists('go.mod')", "file.exists", []string{"go.mod"}, OpEqual, "true"},
+		{"vcs.clean", "vcs.clean", nil, OpEqual, "true"},
+		{"vcs.branch == main", "vcs.branch", nil, OpEqual, "main"},
+		{"bead.status('bd-42') == 'closed'", "bead.status", []string{"bd-42"}, OpEqual, "closed"},
+		{"label.has(bd-42, 'needs review')", "label.has", []string{"bd-42", "needs review"}, OpEqual, "true"},
+		{"cmd.exit('test -f x, y (z)', '30s') != 0", "cmd.exit", []string{"test -f x, y (z)", "30s"}, OpNotEqual, "0"},
+		{"cmd.exit('make test')", "cmd.exit", []string{"make test"}, OpEqual, "0"},
+		{"json.path(test, '$.results[0].ok') == true", "json.path", []string{"test", "$.results[0].ok"}, OpEqual, "true"},
+		{"env('CI') == 'true'", "env", []string{"CI"}, OpEqual, "true"},
+	}
+	for _, tt := range tests {
+		cond, err := ParseCondition(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCondition(%q): %v", tt.expr, err)
+			continue
+		}
+		if cond.Type != ConditionTypeExternal || cond.ExternalType != tt.provider ||
+			strings.Join(cond.ExternalArgs, "|") != strings.Join(tt.args, "|") ||
+			cond.Operator != tt.op || cond.Value != tt.value {
+			t.Errorf("ParseCondition(%q) = %s(%q) %s %q", tt.expr, cond.ExternalType, cond.ExternalArgs, cond.Operator, cond.Value)
+		}
+	}
+
+	// A step named like a provider namespace still works as a field condition
+	cond, err := ParseCondition("vcs.status == 'complete'")
+	if err != nil || cond.Type != ConditionTypeField || cond.StepRef != "vcs" {
+		t.Errorf("vcs.status field condition = %+v, %v", cond, err)
+	}
+}
+
+func TestParseProviderConditionErrors(t *testing.T) {
+	tests := map[string]string{
+		"vcs.dirty()":                  `unknown condition provider "vcs.dirty"`,
+		"bead.status('a', 'b') == x":   "bead.status takes 1 argument, got 2",
+		"vcs.branch":                   "vcs.branch returns a string and needs a comparison",
+		"file.exists('go.mod'":         "missing closing parenthesis",
+		"file.exists('go.mod)":         "unterminated string",
+		"file.exists('go.mod') banana": `unexpected "banana" after file.exists`,
+		"label.has('bd-1', )":          "empty argument",
+	}
+	for expr, want := range tests {
+		_, err := ParseCondition(expr)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseCondition(%q) error = %v, want %q", expr, err, want)
+		}
+	}
+}
+
+func TestEvaluateProviders(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &ConditionContext{
+		WorkDir: dir,
+		Vars:    map[string]string{"issue": "bd-1"},
+		VCS:     fakeVCS{ref: "main"},
+		Beads: fakeBeads{
+			status: map[string]string{"bd-1": "closed"},
+			labels: map[string][]string{"bd-1": {"approved"}},
+		},
+		Steps: map[string]*StepState{
+			"test": {ID: "test", Output: map[string]interface{}{
+				"results": []interface{}{map[string]interface{}{"ok": true, "name": "unit"}},
+			}},
+		},
+		CurrentStep: "test",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"file.exists('go.mod')", true},
+		{"file.exists('missing.txt')", false},
+		{"vcs.clean", true},
+		{"vcs.branch == 'main'", true},
+		{"vcs.branch != main", false},
+		{"bead.status('{{issue}}') == closed", true},
+		{"label.has('bd-1', 'approved')", true},
+		{"label.has('bd-1', 'rejected')", false},
+		{"json.path(test, '$.results[0].ok') == true", true},
+		{"json.path(step, \"results[0]['name']\") == 'unit'", true},
+		{"json.path(test, '$.results[3].ok') == true", false},
+	}
+	for _, tt := range tests {
+		result, err := EvaluateCondition(tt.expr, ctx)
+		if err != nil {
+			t.Errorf("EvaluateCondition(%q): %v", tt.expr, err)
+			continue
+		}
+		if result.Satisfied != tt.want {
+			t.Errorf("EvaluateCondition(%q) = %v, want %v (reason: %s)", tt.expr, result.Satisfied, tt.want, result.Reason)
+		}
+	}
+
+	if _, err := EvaluateCondition("bead.status('bd-404') == closed", ctx); err == nil || !strings.Contains(err.Error(), "bd-404 not found") {
+		t.Errorf("missing bead error = %v", err)
+	}
+	if _, err := EvaluateCondition("vcs.clean", &ConditionContext{}); err == nil || !strings.Contains(err.Error(), "no repository") {
+		t.Errorf("missing VCS error = %v", err)
+	}
+}
+
+func TestCmdExitRequiresTrust(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	ctx := &ConditionContext{WorkDir: t.TempDir()}
+
+	_, err := EvaluateCondition("cmd.exit('true')", ctx)
+	if !errors.Is(err, ErrUntrusted) {
+		t.Fatalf("untrusted cmd.exit error = %v, want ErrUntrusted", err)
+	}
+
+	ctx.Trusted = true
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"cmd.exit('true')", true},
+		{"cmd.exit('exit 3') == 3", true},
+		{"cmd.exit('false')", false},
+		// Secrets in the parent environment are not passed through
+		{"cmd.exit('test -z \"$BEADS_TEST_SECRET\"')", true},
+	}
+	t.Setenv("BEADS_TEST_SECRET", "hunter2")
+	for _, tt := range tests {
+		result, err := EvaluateCondition(tt.expr, ctx)
+		if err != nil {
+			t.Errorf("EvaluateCondition(%q): %v", tt.expr, err)
+			continue
+		}
+		if result.Satisfied != tt.want {
+			t.Errorf("EvaluateCondition(%q) = %v, want %v (reason: %s)", tt.expr, result.Satisfied, tt.want, result.Reason)
+		}
+	}
+
+	_, err = EvaluateCondition("cmd.exit('sleep 5', '100ms')", ctx)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("timeout error = %v", err)
+	}
+}
+
+func TestCheckConditions(t *testing.T) {
+	steps := []*Step{
+		{ID: "deploy", Labels: []string{`gate:{"condition":"vcs.branch == 'main'"}`}},
+		{ID: "review", Labels: []string{`gate:{"condition":"review.status == 'complete'"}`}, Children: []*Step{
+			{ID: "retry", Labels: []string{`loop:{"max":3,"until":"cmd.exit('make test')"}`}},
+		}},
+		{ID: "ship", Labels: []string{"gate:not-json", `gate:{"condition":"vcs.clean"}`}},
+	}
+	checks := CheckConditions(steps, &ConditionContext{VCS: fakeVCS{ref: "main", dirty: true}})
+	if len(checks) != 4 {
+		t.Fatalf("CheckConditions returned %d checks, want 4", len(checks))
+	}
+
+	if c := checks[0]; c.Provider != "vcs.branch" || c.Preview == nil || !c.Preview.Satisfied {
+		t.Errorf("deploy check = %+v", c)
+	}
+	if c := checks[1]; c.Provider != "" || c.Preview != nil || c.Err != nil {
+		t.Errorf("step condition should be left for runtime: %+v", c)
+	}
+	if c := checks[2]; c.Kind != "loop" || c.StepID != "retry" || !errors.Is(c.Err, ErrUntrusted) {
+		t.Errorf("untrusted loop check = %+v", c)
+	}
+	if c := checks[3]; c.Preview == nil || c.Preview.Satisfied {
+		t.Errorf("vcs.clean on a dirty tree = %+v", c)
+	}
+}
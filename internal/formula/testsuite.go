@@ -0,0 +1,296 @@
+package formula
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Formula test files sit next to the formula they test.
+const (
+	// TestFileExt is the extension of formula test files (<name>.formula.test.toml).
+	TestFileExt = ".formula.test.toml"
+
+	// GoldenFileExt is the extension of golden graphs (<name>.<case>.golden.json).
+	GoldenFileExt = ".golden.json"
+)
+
+// TestSuite is a set of test cases for one formula, loaded from
+// <name>.formula.test.toml:
+//
+//	[[case]]
+//	name = "with-review"
+//	vars = { component = "api", review = "true" }
+//	steps = ["design", "implement", "review"]
+//
+//	[case.deps]
+//	review = ["implement"]
+//
+//	[case.labels]
+//	review = ["needs-review"]
+type TestSuite struct {
+	Formula string      `toml:"-"`
+	Path    string      `toml:"-"`
+	Cases   []*TestCase `toml:"case"`
+}
+
+// TestCase cooks a formula with Vars and checks the resulting graph.
+// Steps, Deps and Labels are optional inline expectations; each listed entry
+// must match exactly. Step IDs are relative to the proto root ("parent.child"
+// for nested steps). Deps lists blocking dependencies; other dependency
+// types are written "type:step". The whole graph is also compared with the
+// case's golden file when one exists.
+type TestCase struct {
+	Name   string              `toml:"name"`
+	Vars   map[string]string   `toml:"vars"`
+	Steps  []string            `toml:"steps"`
+	Deps   map[string][]string `toml:"deps"`
+	Labels map[string][]string `toml:"labels"`
+}
+
+// TestFilePath returns the test file path for a formula file or name in dir.
+func TestFilePath(dir, formulaName string) string {
+	return filepath.Join(dir, formulaName+TestFileExt)
+}
+
+// LoadTestSuite reads a formula test file.
+func LoadTestSuite(path string) (*TestSuite, error) {
+	if !strings.HasSuffix(path, TestFileExt) {
+		return nil, fmt.Errorf("%s: formula test files must end in %s", path, TestFileExt)
+	}
+	// #nosec G304 -- path is a test file chosen by the user or found in formula search paths
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var suite TestSuite
+	if err := toml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	suite.Path = path
+	suite.Formula = strings.TrimSuffix(filepath.Base(path), TestFileExt)
+
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("%s: no [[case]] entries", path)
+	}
+	seen := make(map[string]bool)
+	for i, c := range suite.Cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("%s: case %d has no name", path, i+1)
+		}
+		if strings.ContainsAny(c.Name, `/\`) {
+			return nil, fmt.Errorf("%s: case name %q must not contain path separators", path, c.Name)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("%s: duplicate case %q", path, c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return &suite, nil
+}
+
+// GoldenPath returns the golden file for a case, next to the test file.
+func (s *TestSuite) GoldenPath(c *TestCase) string {
+	return filepath.Join(filepath.Dir(s.Path), s.Formula+"."+c.Name+GoldenFileExt)
+}
+
+// TestGraph is the issue graph a formula cooks to, normalized for comparison:
+// issue IDs are relative to the proto root and everything is sorted.
+type TestGraph struct {
+	Formula string            `json:"formula"`
+	Vars    map[string]string `json:"vars,omitempty"`
+	Steps   []*TestGraphStep  `json:"steps"`
+}
+
+// TestGraphStep is one cooked issue.
+type TestGraphStep struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Type     string   `json:"type"`
+	Priority int      `json:"priority"`
+	Parent   string   `json:"parent,omitempty"`
+	Deps     []string `json:"deps,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+}
+
+// Sort orders steps by ID and their deps and labels alphabetically.
+func (g *TestGraph) Sort() {
+	sort.Slice(g.Steps, func(i, j int) bool { return g.Steps[i].ID < g.Steps[j].ID })
+	for _, s := range g.Steps {
+		sort.Strings(s.Deps)
+		sort.Strings(s.Labels)
+	}
+}
+
+// Step returns the step with the given ID, or nil.
+func (g *TestGraph) Step(id string) *TestGraphStep {
+	for _, s := range g.Steps {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// LoadGolden reads a golden graph. A missing file returns (nil, nil).
+func LoadGolden(path string) (*TestGraph, error) {
+	// #nosec G304 -- golden path is derived from the test file path
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var g TestGraph
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	g.Sort()
+	return &g, nil
+}
+
+// WriteGolden writes a graph as indented JSON.
+func WriteGolden(path string, g *TestGraph) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode golden: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Check compares a cooked graph against the case's inline expectations and
+// returns one message per mismatch.
+func (c *TestCase) Check(got *TestGraph) []string {
+	var failures []string
+
+	if c.Steps != nil {
+		want := make(map[string]bool)
+		for _, id := range c.Steps {
+			want[id] = true
+		}
+		var missing, unexpected []string
+		for _, id := range c.Steps {
+			if got.Step(id) == nil {
+				missing = append(missing, id)
+			}
+		}
+		for _, s := range got.Steps {
+			if !want[s.ID] {
+				unexpected = append(unexpected, s.ID)
+			}
+		}
+		sort.Strings(missing)
+		if len(missing) > 0 {
+			failures = append(failures, "missing steps: "+strings.Join(missing, ", "))
+		}
+		if len(unexpected) > 0 {
+			failures = append(failures, "unexpected steps: "+strings.Join(unexpected, ", "))
+		}
+	}
+
+	failures = append(failures, checkStepLists("deps", c.Deps, got, func(s *TestGraphStep) []string { return s.Deps })...)
+	failures = append(failures, checkStepLists("labels", c.Labels, got, func(s *TestGraphStep) []string { return s.Labels })...)
+	return failures
+}
+
+// checkStepLists compares expected per-step string lists, ignoring order.
+func checkStepLists(what string, want map[string][]string, got *TestGraph, field func(*TestGraphStep) []string) []string {
+	ids := make([]string, 0, len(want))
+	for id := range want {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var failures []string
+	for _, id := range ids {
+		step := got.Step(id)
+		if step == nil {
+			failures = append(failures, fmt.Sprintf("%s: step %s not found", what, id))
+			continue
+		}
+		expected := append([]string(nil), want[id]...)
+		sort.Strings(expected)
+		if actual := field(step); !equalStrings(expected, actual) {
+			failures = append(failures, fmt.Sprintf("%s of %s: want %s, got %s", what, id, formatList(expected), formatList(actual)))
+		}
+	}
+	return failures
+}
+
+// DiffGraphs describes the differences between a golden graph and a cooked
+// one, step by step. It returns nil when they match.
+func DiffGraphs(want, got *TestGraph) []string {
+	var diffs []string
+	if want.Formula != got.Formula {
+		diffs = append(diffs, fmt.Sprintf("formula: %q → %q", want.Formula, got.Formula))
+	}
+
+	ids := make(map[string]bool)
+	for _, s := range want.Steps {
+		ids[s.ID] = true
+	}
+	for _, s := range got.Steps {
+		ids[s.ID] = true
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	for _, id := range sorted {
+		w, g := want.Step(id), got.Step(id)
+		switch {
+		case g == nil:
+			diffs = append(diffs, fmt.Sprintf("- step %s (%q) is no longer created", id, w.Title))
+		case w == nil:
+			diffs = append(diffs, fmt.Sprintf("+ step %s (%q) is new", id, g.Title))
+		default:
+			if w.Title != g.Title {
+				diffs = append(diffs, fmt.Sprintf("~ %s title: %q → %q", id, w.Title, g.Title))
+			}
+			if w.Type != g.Type {
+				diffs = append(diffs, fmt.Sprintf("~ %s type: %s → %s", id, w.Type, g.Type))
+			}
+			if w.Priority != g.Priority {
+				diffs = append(diffs, fmt.Sprintf("~ %s priority: %d → %d", id, w.Priority, g.Priority))
+			}
+			if w.Parent != g.Parent {
+				diffs = append(diffs, fmt.Sprintf("~ %s parent: %q → %q", id, w.Parent, g.Parent))
+			}
+			if !equalStrings(w.Deps, g.Deps) {
+				diffs = append(diffs, fmt.Sprintf("~ %s deps: %s → %s", id, formatList(w.Deps), formatList(g.Deps)))
+			}
+			if !equalStrings(w.Labels, g.Labels) {
+				diffs = append(diffs, fmt.Sprintf("~ %s labels: %s → %s", id, formatList(w.Labels), formatList(g.Labels)))
+			}
+		}
+	}
+	return diffs
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatList(items []string) string {
+	return "[" + strings.Join(items, ", ") + "]"
+}
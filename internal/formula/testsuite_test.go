@@ -0,0 +1,137 @@
+package formula
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTestSuite(t *testing.T) {
+	dir := t.TempDir()
+	path := TestFilePath(dir, "release")
+	content := `
+[[case]]
+name = "default"
+steps = ["design"]
+
+[[case]]
+name = "with-review"
+vars = { review = "true" }
+[case.deps]
+review = ["implement"]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	suite, err := LoadTestSuite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.Formula != "release" || len(suite.Cases) != 2 {
+		t.Fatalf("suite = %+v", suite)
+	}
+	c := suite.Cases[1]
+	if c.Vars["review"] != "true" || c.Deps["review"][0] != "implement" {
+		t.Errorf("case = %+v", c)
+	}
+	if got := suite.GoldenPath(c); got != filepath.Join(dir, "release.with-review.golden.json") {
+		t.Errorf("GoldenPath = %s", got)
+	}
+
+	bad := map[string]string{
+		"":                           "no [[case]] entries",
+		"[[case]]\nsteps = []\n":     "case 1 has no name",
+		"[[case]]\nname = \"a/b\"\n": "must not contain path separators",
+		"[[case]]\nname = \"a\"\n[[case]]\nname = \"a\"\n": `duplicate case "a"`,
+	}
+	for content, want := range bad {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTestSuite(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadTestSuite(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
+
+func testGraph() *TestGraph {
+	g := &TestGraph{Formula: "release", Steps: []*TestGraphStep{
+		{ID: "review", Title: "Review", Type: "task", Priority: 2, Deps: []string{"implement"}, Labels: []string{"needs-review"}},
+		{ID: "design", Title: "Design", Type: "task", Priority: 2},
+		{ID: "implement", Title: "Implement", Type: "task", Priority: 2, Deps: []string{"design"}},
+	}}
+	g.Sort()
+	return g
+}
+
+func TestTestCaseCheck(t *testing.T) {
+	got := testGraph()
+
+	pass := &TestCase{
+		Steps:  []string{"design", "implement", "review"},
+		Deps:   map[string][]string{"review": {"implement"}, "design": nil},
+		Labels: map[string][]string{"review": {"needs-review"}},
+	}
+	if failures := pass.Check(got); len(failures) != 0 {
+		t.Errorf("Check = %v, want no failures", failures)
+	}
+
+	fail := &TestCase{
+		Steps:  []string{"design", "ship"},
+		Deps:   map[string][]string{"review": {"design"}},
+		Labels: map[string][]string{"missing": {"x"}},
+	}
+	want := []string{
+		"missing steps: ship",
+		"unexpected steps: implement, review",
+		"deps of review: want [design], got [implement]",
+		"labels: step missing not found",
+	}
+	failures := fail.Check(got)
+	if strings.Join(failures, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Check =\n%s\nwant\n%s", strings.Join(failures, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDiffGraphs(t *testing.T) {
+	want := testGraph()
+	if diffs := DiffGraphs(want, testGraph()); len(diffs) != 0 {
+		t.Errorf("identical graphs differ: %v", diffs)
+	}
+
+	got := testGraph()
+	got.Step("implement").Title = "Build"
+	got.Step("review").Deps = []string{"design"}
+	got.Steps = append(got.Steps, &TestGraphStep{ID: "ship", Title: "Ship", Type: "task"})
+	got.Steps = got.Steps[1:] // drop design
+	got.Sort()
+
+	expected := []string{
+		`- step design ("Design") is no longer created`,
+		`~ implement title: "Implement" → "Build"`,
+		`~ review deps: [implement] → [design]`,
+		`+ step ship ("Ship") is new`,
+	}
+	diffs := DiffGraphs(want, got)
+	if strings.Join(diffs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("DiffGraphs =\n%s\nwant\n%s", strings.Join(diffs, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestGoldenRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "release.default"+GoldenFileExt)
+	if g, err := LoadGolden(path); g != nil || err != nil {
+		t.Fatalf("missing golden = %v, %v", g, err)
+	}
+	if err := WriteGolden(path, testGraph()); err != nil {
+		t.Fatal(err)
+	}
+	g, err := LoadGolden(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := DiffGraphs(testGraph(), g); len(diffs) != 0 {
+		t.Errorf("round trip differs: %v", diffs)
+	}
+}
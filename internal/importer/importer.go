@@ -167,6 +167,11 @@ func ImportIssues(ctx context.Context, dbPath string, store storage.Storage, iss
 		return nil, err
 	}
 
+	// Import attachment references
+	if err := importAttachments(ctx, sqliteStore, issues, opts); err != nil {
+		return nil, err
+	}
+
 	// Checkpoint WAL to ensure data persistence and reduce WAL file size
 	if err := sqliteStore.CheckpointWAL(ctx); err != nil {
 		// Non-fatal - just log warning
@@ -608,6 +613,12 @@ func upsertIssues(ctx context.Context, sqliteStore *sqlite.SQLiteStorage, issues
 						updates["external_ref"] = nil
 					}
 
+					if incoming.BudgetUSD != nil {
+						updates["budget_usd"] = *incoming.BudgetUSD
+					} else {
+						updates["budget_usd"] = nil
+					}
+
 					// Only update if data actually changed
 					if IssueDataChanged(existing, updates) {
 						if err := sqliteStore.UpdateIssue(ctx, existing.ID, updates, "import"); err != nil {
@@ -713,6 +724,12 @@ func upsertIssues(ctx context.Context, sqliteStore *sqlite.SQLiteStorage, issues
 					updates["external_ref"] = nil
 				}
 
+				if incoming.BudgetUSD != nil {
+					updates["budget_usd"] = *incoming.BudgetUSD
+				} else {
+					updates["budget_usd"] = nil
+				}
+
 				// Only update if data actually changed
 				if IssueDataChanged(existingWithID, updates) {
 					if err := sqliteStore.UpdateIssue(ctx, incoming.ID, updates, "import"); err != nil {
@@ -933,6 +950,42 @@ func importComments(ctx context.Context, sqliteStore *sqlite.SQLiteStorage, issu
 	return nil
 }
 
+// importAttachments imports attachment references for issues. Attachments
+// missing locally are added and ones whose content changed are replaced;
+// the blobs themselves arrive through git with .beads/blobs/.
+func importAttachments(ctx context.Context, sqliteStore *sqlite.SQLiteStorage, issues []*types.Issue, opts Options) error {
+	for _, issue := range issues {
+		if len(issue.Attachments) == 0 {
+			continue
+		}
+
+		currentAttachments, err := sqliteStore.GetAttachments(ctx, issue.ID)
+		if err != nil {
+			return fmt.Errorf("error getting attachments for %s: %w", issue.ID, err)
+		}
+		current := make(map[string]string, len(currentAttachments))
+		for _, a := range currentAttachments {
+			current[a.Name] = a.SHA256
+		}
+
+		for _, a := range issue.Attachments {
+			if sum, ok := current[a.Name]; ok && sum == a.SHA256 {
+				continue
+			}
+			attachment := *a
+			attachment.IssueID = issue.ID
+			if err := sqliteStore.AddAttachment(ctx, &attachment, "import"); err != nil {
+				if opts.Strict {
+					return fmt.Errorf("error adding attachment %s to %s: %w", a.Name, issue.ID, err)
+				}
+				continue
+			}
+		}
+	}
+
+	return nil
+}
+
 // shouldProtectFromUpdate checks if an update should be skipped due to timestamp-aware protection (GH#865).
 // Returns true if the update should be skipped (local is newer), false if the update should proceed.
 // If the issue is not in the protection map, returns false (allow update).
@@ -4,7 +4,9 @@
 package importer
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -676,6 +678,92 @@ func TestImportIssues_Update(t *testing.T) {
 	}
 }
 
+func TestImportIssues_BudgetRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	newStore := func() (*sqlite.SQLiteStorage, string) {
+		path := t.TempDir() + "/test.db"
+		s, err := sqlite.New(ctx, path)
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		if err := s.SetConfig(ctx, "issue_prefix", "test"); err != nil {
+			t.Fatalf("Failed to set prefix: %v", err)
+		}
+		return s, path
+	}
+	src, _ := newStore()
+	dst, dstPath := newStore()
+
+	// exportImport writes src as JSONL the way bd export does and imports it into dst
+	exportImport := func() *types.Issue {
+		t.Helper()
+		issues, err := src.SearchIssues(ctx, "", types.IssueFilter{})
+		if err != nil {
+			t.Fatalf("SearchIssues failed: %v", err)
+		}
+		var buf bytes.Buffer
+		for _, issue := range issues {
+			if err := json.NewEncoder(&buf).Encode(issue); err != nil {
+				t.Fatalf("encode failed: %v", err)
+			}
+		}
+		var decoded []*types.Issue
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var issue types.Issue
+			if err := dec.Decode(&issue); err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			decoded = append(decoded, &issue)
+		}
+		if _, err := ImportIssues(ctx, dstPath, dst, decoded, Options{}); err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+		got, err := dst.GetIssue(ctx, "test-budget")
+		if err != nil || got == nil {
+			t.Fatalf("GetIssue = %v, %v", got, err)
+		}
+		return got
+	}
+
+	budget := 5.0
+	issue := &types.Issue{
+		ID:        "test-budget",
+		Title:     "Budgeted",
+		Status:    types.StatusOpen,
+		Priority:  2,
+		IssueType: types.TypeTask,
+		BudgetUSD: &budget,
+	}
+	if err := src.CreateIssue(ctx, issue, "test"); err != nil {
+		t.Fatalf("CreateIssue failed: %v", err)
+	}
+	if got := exportImport(); got.BudgetUSD == nil || *got.BudgetUSD != 5 {
+		t.Errorf("new issue budget = %v, want 5", got.BudgetUSD)
+	}
+
+	// Changing or clearing the budget updates the existing issue
+	for _, want := range []interface{}{12.5, nil} {
+		time.Sleep(10 * time.Millisecond) // imports only apply newer updates
+		if err := src.UpdateIssue(ctx, "test-budget", map[string]interface{}{"budget_usd": want}, "test"); err != nil {
+			t.Fatalf("UpdateIssue failed: %v", err)
+		}
+		got := exportImport()
+		switch want {
+		case nil:
+			if got.BudgetUSD != nil {
+				t.Errorf("cleared budget = %v, want nil", *got.BudgetUSD)
+			}
+		default:
+			if got.BudgetUSD == nil || *got.BudgetUSD != want {
+				t.Errorf("updated budget = %v, want %v", got.BudgetUSD, want)
+			}
+		}
+	}
+}
+
 func TestImportIssues_DryRun(t *testing.T) {
 	ctx := context.Background()
 	
@@ -121,6 +121,17 @@ func (fc *fieldComparator) equalBool(existingVal bool, newVal interface{}) bool
 	}
 }
 
+func (fc *fieldComparator) equalPtrFloat(existing *float64, newVal interface{}) bool {
+	switch t := newVal.(type) {
+	case nil:
+		return existing == nil
+	case float64:
+		return existing != nil && *existing == t
+	default:
+		return false
+	}
+}
+
 func (fc *fieldComparator) checkFieldChanged(key string, existing *types.Issue, newVal interface{}) bool {
 	switch key {
 	case "title":
@@ -145,6 +156,8 @@ func (fc *fieldComparator) checkFieldChanged(key string, existing *types.Issue,
 		return !fc.equalPtrStr(existing.ExternalRef, newVal)
 	case "pinned":
 		return !fc.equalBool(existing.Pinned, newVal)
+	case "budget_usd":
+		return !fc.equalPtrFloat(existing.BudgetUSD, newVal)
 	default:
 		return false
 	}
@@ -1,11 +1,16 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/beads/internal/formats"
 	"github.com/steveyegge/beads/internal/types"
 )
 
@@ -865,6 +870,75 @@ func TestExport(t *testing.T) {
 	}
 }
 
+// rpcTitlesFormat is an export-only format registered for TestFormatExportImport
+type rpcTitlesFormat struct{}
+
+func (rpcTitlesFormat) Name() string        { return "rpc-test-titles" }
+func (rpcTitlesFormat) Description() string { return "titles" }
+func (rpcTitlesFormat) Capabilities() formats.Capabilities {
+	return formats.Capabilities{Export: true, Streaming: true, Lossy: true}
+}
+func (rpcTitlesFormat) Encode(_ context.Context, w io.Writer, issues []*types.Issue, _ formats.Options) error {
+	for _, issue := range issues {
+		if _, err := io.WriteString(w, issue.Title+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestFormatExportImport tests Export and Import with registered formats via RPC
+func TestFormatExportImport(t *testing.T) {
+	formats.Register(rpcTitlesFormat{})
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	if _, err := client.Create(&CreateArgs{Title: "Exported", IssueType: "task", Priority: 2}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	dir := t.TempDir()
+
+	titlesPath := filepath.Join(dir, "titles.txt")
+	resp, err := client.Export(&ExportArgs{JSONLPath: titlesPath, Format: "rpc-test-titles"})
+	if err != nil || !resp.Success {
+		t.Fatalf("Export failed: %v %s", err, resp.Error)
+	}
+	if data, _ := os.ReadFile(titlesPath); string(data) != "Exported\n" {
+		t.Errorf("titles export = %q", data)
+	}
+
+	_, err = client.Import(&ImportArgs{JSONLPath: titlesPath, Format: "rpc-test-titles"})
+	if err == nil || !strings.Contains(err.Error(), "does not support import") {
+		t.Errorf("import of an export-only format: %v", err)
+	}
+
+	// A JSONL file with one new issue imports through the importer
+	jsonlPath := filepath.Join(dir, "in.jsonl")
+	line := `{"id":"bd-imported1","title":"From file","status":"open","priority":1,"issue_type":"bug","created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z"}` + "\n"
+	if err := os.WriteFile(jsonlPath, []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, dryRun := range []bool{true, false} {
+		resp, err = client.Import(&ImportArgs{JSONLPath: jsonlPath, DryRun: dryRun})
+		if err != nil || !resp.Success {
+			t.Fatalf("Import failed: %v %s", err, resp.Error)
+		}
+		var result struct {
+			Created int `json:"created"`
+		}
+		if err := json.Unmarshal(resp.Data, &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Created != 1 {
+			t.Errorf("dry run %v: created = %d, want 1", dryRun, result.Created)
+		}
+	}
+	resp, err = client.Show(&ShowArgs{ID: "bd-imported1"})
+	if err != nil || !resp.Success {
+		t.Fatalf("imported issue not found: %v %s", err, resp.Error)
+	}
+}
+
 // TestMutationChan tests access to the mutation channel
 func TestMutationChan(t *testing.T) {
 	server, _, cleanup := setupTestServer(t)
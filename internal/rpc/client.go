@@ -31,6 +31,11 @@ func rpcDebugLog(format string, args ...interface{}) {
 // It's set dynamically by main.go from cmd/bd/version.go before making RPC calls
 var ClientVersion = "0.0.0" // Placeholder; overridden at startup
 
+// ClientCommandID identifies the bd invocation making requests, so the
+// daemon records it on the events it writes on the client's behalf.
+// It's set by main.go at startup.
+var ClientCommandID string
+
 // Client represents an RPC client that connects to the daemon
 type Client struct {
 	conn       net.Conn
@@ -187,6 +192,7 @@ func (c *Client) ExecuteWithCwd(operation string, args interface{}, cwd string)
 		ClientVersion: ClientVersion,
 		Cwd:           cwd,
 		ExpectedDB:    c.dbPath, // Send expected database path for validation
+		CommandID:     ClientCommandID,
 	}
 
 	reqJSON, err := json.Marshal(req)
@@ -397,11 +403,16 @@ func (c *Client) Batch(args *BatchArgs) (*Response, error) {
 
 
 
-// Export exports the database to JSONL format
+// Export exports the database to JSONL or another registered format
 func (c *Client) Export(args *ExportArgs) (*Response, error) {
 	return c.Execute(OpExport, args)
 }
 
+// Import imports issues from a file in a registered format via the daemon
+func (c *Client) Import(args *ImportArgs) (*Response, error) {
+	return c.Execute(OpImport, args)
+}
+
 // EpicStatus gets epic completion status via the daemon
 func (c *Client) EpicStatus(args *EpicStatusArgs) (*Response, error) {
 	return c.Execute(OpEpicStatus, args)
@@ -63,6 +63,7 @@ type Request struct {
 	Cwd           string          `json:"cwd,omitempty"`            // Working directory for database discovery
 	ClientVersion string          `json:"client_version,omitempty"` // Client version for compatibility checks
 	ExpectedDB    string          `json:"expected_db,omitempty"`    // Expected database path for validation (absolute)
+	CommandID     string          `json:"command_id,omitempty"`     // bd invocation making the request, recorded on events
 }
 
 // Response represents an RPC response from daemon to client
@@ -86,6 +87,7 @@ type CreateArgs struct {
 	Assignee           string   `json:"assignee,omitempty"`
 	ExternalRef        string   `json:"external_ref,omitempty"`  // Link to external issue trackers
 	EstimatedMinutes   *int     `json:"estimated_minutes,omitempty"` // Time estimate in minutes
+	BudgetUSD          *float64 `json:"budget_usd,omitempty"`        // Spend limit for the issue and its descendants
 	Labels             []string `json:"labels,omitempty"`
 	Dependencies       []string `json:"dependencies,omitempty"`
 	// Waits-for dependencies
@@ -126,6 +128,7 @@ type UpdateArgs struct {
 	Assignee           *string  `json:"assignee,omitempty"`
 	ExternalRef        *string  `json:"external_ref,omitempty"` // Link to external issue trackers
 	EstimatedMinutes   *int     `json:"estimated_minutes,omitempty"` // Time estimate in minutes
+	BudgetUSD          *float64 `json:"budget_usd,omitempty"`        // Spend limit; 0 clears it
 	IssueType          *string  `json:"issue_type,omitempty"`        // Issue type (bug|feature|task|epic|chore)
 	AddLabels          []string `json:"add_labels,omitempty"`
 	RemoveLabels       []string `json:"remove_labels,omitempty"`
@@ -203,6 +206,7 @@ type ListArgs struct {
 	Labels    []string `json:"labels,omitempty"`     // AND semantics
 	LabelsAny []string `json:"labels_any,omitempty"` // OR semantics
 	IDs       []string `json:"ids,omitempty"`        // Filter by specific issue IDs
+	States    []types.StateFilter `json:"states,omitempty"` // State dimension filters (AND semantics)
 	Limit     int      `json:"limit,omitempty"`
 	
 	// Pattern matching
@@ -496,12 +500,17 @@ type CompactStatsData struct {
 
 // ExportArgs represents arguments for the export operation
 type ExportArgs struct {
-	JSONLPath string `json:"jsonl_path"` // Path to export JSONL file
+	JSONLPath string            `json:"jsonl_path"`       // Path to export file (or directory for directory formats)
+	Format    string            `json:"format,omitempty"` // Registered format name (default: jsonl)
+	Params    map[string]string `json:"params,omitempty"` // Format-specific settings
 }
 
 // ImportArgs represents arguments for the import operation
 type ImportArgs struct {
-	JSONLPath string `json:"jsonl_path"` // Path to import JSONL file
+	JSONLPath string            `json:"jsonl_path"`       // Path to import file (or directory for directory formats)
+	Format    string            `json:"format,omitempty"` // Registered format name (default: jsonl)
+	Params    map[string]string `json:"params,omitempty"` // Format-specific settings
+	DryRun    bool              `json:"dry_run,omitempty"`
 }
 
 // GetMutationsArgs represents arguments for retrieving recent mutations
@@ -76,6 +76,7 @@ const (
 	MutationSquashed = "squashed" // Wisp squashed to digest
 	MutationBurned   = "burned"   // Wisp discarded without digest
 	MutationStatus   = "status"   // Status change (in_progress, completed, failed)
+	MutationReaped   = "reaped"   // Agent marked dead; its hooked work returned to open
 )
 
 // MutationEvent represents a database mutation for event-driven sync
@@ -181,6 +182,12 @@ func (s *Server) emitRichMutation(event MutationEvent) {
 	s.recentMutationsMu.Unlock()
 }
 
+// EmitMutation publishes a mutation made by the daemon itself rather than
+// through an RPC request, so it reaches the event loop and activity feeds.
+func (s *Server) EmitMutation(event MutationEvent) {
+	s.emitRichMutation(event)
+}
+
 // MutationChan returns the mutation event channel for the daemon to consume
 func (s *Server) MutationChan() <-chan MutationEvent {
 	return s.mutationChan
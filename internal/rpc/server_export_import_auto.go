@@ -14,6 +14,7 @@ import (
 	"github.com/steveyegge/beads/internal/autoimport"
 	"github.com/steveyegge/beads/internal/debug"
 	"github.com/steveyegge/beads/internal/export"
+	"github.com/steveyegge/beads/internal/formats"
 	"github.com/steveyegge/beads/internal/importer"
 	"github.com/steveyegge/beads/internal/storage"
 	"github.com/steveyegge/beads/internal/storage/sqlite"
@@ -31,6 +32,10 @@ func (s *Server) handleExport(req *Request) Response {
 		}
 	}
 
+	if exportArgs.Format != "" && exportArgs.Format != "jsonl" {
+		return s.handleFormatExport(req, exportArgs)
+	}
+
 	store := s.storage
 	ctx := s.reqCtx(req)
 
@@ -137,6 +142,32 @@ func (s *Server) handleExport(req *Request) Response {
 		issue.Comments = allComments[issue.ID]
 	}
 
+	// Populate attachment references for all issues (enrichment data)
+	var allAttachments map[string][]*types.Attachment
+	result = export.FetchWithPolicy(ctx, cfg, export.DataTypeAttachments, "get attachments", func() error {
+		var err error
+		allAttachments, err = store.GetAllAttachments(ctx)
+		return err
+	})
+	if result.Err != nil {
+		return Response{
+			Success: false,
+			Error:   fmt.Sprintf("failed to get attachments: %v", result.Err),
+		}
+	}
+	if !result.Success {
+		// Attachments fetch failed but policy allows continuing
+		allAttachments = make(map[string][]*types.Attachment) // Empty map
+		if manifest != nil {
+			manifest.PartialData = append(manifest.PartialData, "attachments")
+			manifest.Warnings = append(manifest.Warnings, result.Warnings...)
+			manifest.Complete = false
+		}
+	}
+	for _, issue := range issues {
+		issue.Attachments = allAttachments[issue.ID]
+	}
+
 	// Create temp file for atomic write
 	dir := filepath.Dir(exportArgs.JSONLPath)
 	base := filepath.Base(exportArgs.JSONLPath)
@@ -240,12 +271,132 @@ func (s *Server) handleImport(req *Request) Response {
 		}
 	}
 
-	// Note: The actual import logic is complex and lives in cmd/bd/import.go
-	// For now, we'll return an error suggesting to use direct mode
-	// In the future, we can refactor the import logic into a shared package
+	format := importArgs.Format
+	if format == "" {
+		format = "jsonl"
+	}
+	f, err := formats.LookupImporter(format)
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+
+	store := s.storage
+	ctx := s.reqCtx(req)
+	opts := formats.Options{Store: store, Params: importArgs.Params}
+	opts.Prefix, _ = store.GetConfig(ctx, "issue_prefix")
+
+	var issues []*types.Issue
+	if f.Capabilities().Streaming {
+		// #nosec G304 -- path supplied by the local client
+		in, err := os.Open(importArgs.JSONLPath)
+		if err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("failed to open input: %v", err)}
+		}
+		issues, err = formats.Decode(ctx, f, in, opts)
+		_ = in.Close()
+		if err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("failed to read %s input: %v", format, err)}
+		}
+	} else {
+		issues, err = formats.DecodeDir(ctx, f, importArgs.JSONLPath, opts)
+		if err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("failed to read %s input: %v", format, err)}
+		}
+	}
+
+	result, err := importer.ImportIssues(ctx, "", store, issues, importer.Options{DryRun: importArgs.DryRun})
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("import failed: %v", err)}
+	}
+	if !importArgs.DryRun && result.Created+result.Updated > 0 {
+		// Let the daemon's event loop export the imported changes
+		s.emitMutation(MutationUpdate, "", "", "")
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"format":    format,
+		"created":   result.Created,
+		"updated":   result.Updated,
+		"unchanged": result.Unchanged,
+		"skipped":   result.Skipped,
+		"dry_run":   importArgs.DryRun,
+	})
 	return Response{
-		Success: false,
-		Error:   "import via daemon not yet implemented, use --no-daemon flag",
+		Success: true,
+		Data:    data,
+	}
+}
+
+// handleFormatExport exports to a registered format other than JSONL.
+// Unlike the JSONL export it is a one-off file for people or other tools,
+// so dirty flags and the manifest are left alone.
+func (s *Server) handleFormatExport(req *Request, exportArgs ExportArgs) Response {
+	f, err := formats.LookupExporter(exportArgs.Format)
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+
+	store := s.storage
+	ctx := s.reqCtx(req)
+
+	issues, err := store.SearchIssues(ctx, "", types.IssueFilter{})
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("failed to get issues: %v", err)}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].ID < issues[j].ID
+	})
+	allDeps, err := store.GetAllDependencyRecords(ctx)
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("failed to get dependencies: %v", err)}
+	}
+	issueIDs := make([]string, len(issues))
+	for i, issue := range issues {
+		issueIDs[i] = issue.ID
+	}
+	allLabels, err := store.GetLabelsForIssues(ctx, issueIDs)
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("failed to get labels: %v", err)}
+	}
+	for _, issue := range issues {
+		issue.Dependencies = allDeps[issue.ID]
+		issue.Labels = allLabels[issue.ID]
+	}
+
+	opts := formats.Options{Store: store, Params: exportArgs.Params}
+	opts.Prefix, _ = store.GetConfig(ctx, "issue_prefix")
+
+	if !f.Capabilities().Streaming {
+		if err := formats.EncodeDir(ctx, f, exportArgs.JSONLPath, issues, opts); err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+	} else {
+		// Create temp file for atomic write
+		dir := filepath.Dir(exportArgs.JSONLPath)
+		tempFile, err := os.CreateTemp(dir, filepath.Base(exportArgs.JSONLPath)+".tmp.*")
+		if err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("failed to create temp file: %v", err)}
+		}
+		tempPath := tempFile.Name()
+		defer func() { _ = os.Remove(tempPath) }()
+		err = formats.Encode(ctx, f, tempFile, issues, opts)
+		_ = tempFile.Close()
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		if err := os.Rename(tempPath, exportArgs.JSONLPath); err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("failed to replace output file: %v", err)}
+		}
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"exported_count": len(issues),
+		"path":           exportArgs.JSONLPath,
+		"format":         f.Name(),
+	})
+	return Response{
+		Success: true,
+		Data:    data,
 	}
 }
 
@@ -533,6 +684,24 @@ func (s *Server) triggerExport(ctx context.Context, store storage.Storage, dbPat
 		issue.Comments = allComments[issue.ID]
 	}
 
+	// Populate attachment references for all issues (enrichment data)
+	var allAttachments map[string][]*types.Attachment
+	result = export.FetchWithPolicy(ctx, cfg, export.DataTypeAttachments, "get attachments", func() error {
+		var err error
+		allAttachments, err = store.GetAllAttachments(ctx)
+		return err
+	})
+	if result.Err != nil {
+		return fmt.Errorf("failed to get attachments: %w", result.Err)
+	}
+	if !result.Success {
+		// Attachments fetch failed but policy allows continuing
+		allAttachments = make(map[string][]*types.Attachment) // Empty map
+	}
+	for _, issue := range allIssues {
+		issue.Attachments = allAttachments[issue.ID]
+	}
+
 	// Write to JSONL file with atomic replace (temp file + rename)
 	dir := filepath.Dir(jsonlPath)
 	base := filepath.Base(jsonlPath)
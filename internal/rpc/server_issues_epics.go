@@ -84,6 +84,13 @@ func updatesFromArgs(a UpdateArgs) map[string]interface{} {
 	if a.EstimatedMinutes != nil {
 		u["estimated_minutes"] = *a.EstimatedMinutes
 	}
+	if a.BudgetUSD != nil {
+		if *a.BudgetUSD == 0 {
+			u["budget_usd"] = nil
+		} else {
+			u["budget_usd"] = *a.BudgetUSD
+		}
+	}
 	if a.IssueType != nil {
 		u["issue_type"] = *a.IssueType
 	}
@@ -250,6 +257,7 @@ func (s *Server) handleCreate(req *Request) Response {
 		Assignee:           strValue(assignee),
 		ExternalRef:        externalRef,
 		EstimatedMinutes:   createArgs.EstimatedMinutes,
+		BudgetUSD:          createArgs.BudgetUSD,
 		Status:             types.StatusOpen,
 		// Messaging fields
 		Sender:    createArgs.Sender,
@@ -1045,6 +1053,9 @@ func (s *Server) handleList(req *Request) Response {
 	if len(labelsAny) > 0 {
 		filter.LabelsAny = labelsAny
 	}
+	if len(listArgs.States) > 0 {
+		filter.States = listArgs.States
+	}
 	if len(listArgs.IDs) > 0 {
 		ids := util.NormalizeLabels(listArgs.IDs)
 		if len(ids) > 0 {
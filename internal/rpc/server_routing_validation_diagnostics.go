@@ -11,6 +11,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/steveyegge/beads/internal/storage"
 	"github.com/steveyegge/beads/internal/types"
 	"golang.org/x/mod/semver"
 )
@@ -259,8 +260,12 @@ func (s *Server) handleRequest(req *Request) Response {
 }
 
 // Adapter helpers
-func (s *Server) reqCtx(_ *Request) context.Context {
-	return context.Background()
+func (s *Server) reqCtx(req *Request) context.Context {
+	ctx := context.Background()
+	if req != nil && req.CommandID != "" {
+		ctx = storage.WithCommandID(ctx, req.CommandID)
+	}
+	return ctx
 }
 
 func (s *Server) reqActor(req *Request) string {
@@ -0,0 +1,33 @@
+package storage
+
+import "context"
+
+type commandIDKey struct{}
+
+// WithCommandID returns a context whose writes are attributed to the bd
+// invocation id. Backends that keep an audit trail record it on every event,
+// so all the changes one command made can be found (and undone) together.
+func WithCommandID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, commandIDKey{}, id)
+}
+
+// CommandID returns the invocation ID set by WithCommandID, or "".
+func CommandID(ctx context.Context) string {
+	id, _ := ctx.Value(commandIDKey{}).(string)
+	return id
+}
+
+type sourceOpKey struct{}
+
+// WithSourceOp returns a context whose writes apply the VCS operation opID,
+// such as the jj operation that brought in the JSONL an auto-import reads.
+// Backends that keep an audit trail record it on every event.
+func WithSourceOp(ctx context.Context, opID string) context.Context {
+	return context.WithValue(ctx, sourceOpKey{}, opID)
+}
+
+// SourceOp returns the operation ID set by WithSourceOp, or "".
+func SourceOp(ctx context.Context) string {
+	id, _ := ctx.Value(sourceOpKey{}).(string)
+	return id
+}
@@ -0,0 +1,244 @@
+// Package gitrefs implements the storage interface on top of git refs instead
+// of a tracked JSONL file.
+//
+// Each issue is stored as a JSON blob (the same record `bd export` writes,
+// with labels, dependencies and comments inline) and pointed to by
+// refs/beads/issues/<id>. Nothing lives in the working tree or on the code
+// branch, so issue edits never show up in diffs or conflict with code merges,
+// and one issue edit never conflicts with another: Sync fetches and pushes
+// the issue refs individually and three-way merges only the issues that both
+// sides changed.
+//
+// At startup the refs are loaded into an in-memory index that answers queries
+// with the same semantics as the other backends. Like --no-db mode, changes
+// are buffered in the index and committed to refs by Flush (or Close). Every
+// ref update is a compare-and-swap against the value that was loaded, so a
+// concurrent writer or sync is never silently overwritten.
+//
+// All git access goes through vcs.VCS.Exec using plumbing commands.
+package gitrefs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/memory"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+// GitRefsStorage implements storage.Storage backed by per-issue git refs.
+type GitRefsStorage struct {
+	*memory.MemoryStorage // Query index, rebuilt from the refs on open
+
+	refs   refs
+	gitDir string
+
+	mu     sync.Mutex          // Protects loaded and config
+	loaded map[string]refEntry // Issue ID -> ref value as of the last load/flush
+	config refEntry            // ConfigRef value as of the last load/flush
+}
+
+// Compile-time interface check
+var _ storage.Storage = (*GitRefsStorage)(nil)
+
+// New opens the issue refs of the repository behind v, which must be a git
+// (or colocated) repository.
+func New(ctx context.Context, v vcs.VCS) (*GitRefsStorage, error) {
+	if v.Name() == vcs.TypeJJ {
+		return nil, fmt.Errorf("git refs storage requires a git repository, got %s", v.Name())
+	}
+	gitDir, err := v.VCSDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate git directory: %w", err)
+	}
+
+	s := &GitRefsStorage{
+		MemoryStorage: memory.New(""),
+		refs:          refs{v: v},
+		gitDir:        gitDir,
+	}
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load rebuilds the in-memory index from the issue refs and the config ref.
+func (s *GitRefsStorage) load(ctx context.Context) error {
+	entries, err := s.refs.list(ctx, IssueRefPrefix)
+	if err != nil {
+		return err
+	}
+
+	issues := make([]*types.Issue, 0, len(entries))
+	for id, entry := range entries {
+		issue, err := decodeIssue(entry.data)
+		if err != nil {
+			return fmt.Errorf("%s%s: %w", IssueRefPrefix, id, err)
+		}
+		issues = append(issues, issue)
+	}
+
+	s.MemoryStorage.Reset()
+	if err := s.MemoryStorage.LoadFromIssues(issues); err != nil {
+		return fmt.Errorf("failed to index issue refs: %w", err)
+	}
+
+	cfgEntries, err := s.refs.list(ctx, ConfigRef)
+	if err != nil {
+		return err
+	}
+	cfgEntry := cfgEntries[""]
+	if len(cfgEntry.data) > 0 {
+		var cfg map[string]string
+		if err := json.Unmarshal(cfgEntry.data, &cfg); err != nil {
+			return fmt.Errorf("%s: %w", ConfigRef, err)
+		}
+		for k, v := range cfg {
+			_ = s.MemoryStorage.SetConfig(ctx, k, v)
+		}
+	}
+
+	// Loading is not a change
+	_ = s.MemoryStorage.ClearDirtyIssuesByID(ctx, s.dirtyIDs(ctx))
+
+	s.mu.Lock()
+	s.loaded = entries
+	s.config = cfgEntry
+	s.mu.Unlock()
+	return nil
+}
+
+// Reload discards the index and reloads it from the refs, picking up changes
+// made by Sync or another process.
+func (s *GitRefsStorage) Reload(ctx context.Context) error {
+	return s.load(ctx)
+}
+
+// Flush commits every issue and config change since the last load or flush
+// to refs. Issues whose record is unchanged are not rewritten, and refs of
+// issues that no longer exist (deleted or renamed) are removed.
+func (s *GitRefsStorage) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type pending struct {
+		id   string
+		data []byte
+	}
+	var writes []pending
+	live := make(map[string]bool)
+	for _, issue := range s.MemoryStorage.GetAllIssues() {
+		// Wisps are local-only, like in JSONL exports
+		if issue.Ephemeral {
+			continue
+		}
+		live[issue.ID] = true
+		data, err := encodeIssue(issue)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", issue.ID, err)
+		}
+		if prev, ok := s.loaded[issue.ID]; ok && string(prev.data) == string(data) {
+			continue
+		}
+		writes = append(writes, pending{id: issue.ID, data: data})
+	}
+
+	cfg, err := s.MemoryStorage.GetAllConfig(ctx)
+	if err != nil {
+		return err
+	}
+	cfgData, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	cfgChanged := string(cfgData) != string(s.config.data) && !(len(cfg) == 0 && len(s.config.data) == 0)
+
+	blobs := make([][]byte, 0, len(writes)+1)
+	for _, w := range writes {
+		blobs = append(blobs, w.data)
+	}
+	if cfgChanged {
+		blobs = append(blobs, cfgData)
+	}
+	oids, err := s.refs.writeBlobs(ctx, blobs)
+	if err != nil {
+		return err
+	}
+
+	for i, w := range writes {
+		if err := s.refs.update(ctx, IssueRefPrefix+w.id, oids[i], s.loaded[w.id].oid); err != nil {
+			return err
+		}
+		s.loaded[w.id] = refEntry{oid: oids[i], data: w.data}
+	}
+	if cfgChanged {
+		oid := oids[len(writes)]
+		if err := s.refs.update(ctx, ConfigRef, oid, s.config.oid); err != nil {
+			return err
+		}
+		s.config = refEntry{oid: oid, data: cfgData}
+	}
+
+	stale := make([]string, 0)
+	for id := range s.loaded {
+		if !live[id] {
+			stale = append(stale, id)
+		}
+	}
+	sort.Strings(stale)
+	for _, id := range stale {
+		if err := s.refs.remove(ctx, IssueRefPrefix+id, s.loaded[id].oid); err != nil {
+			return err
+		}
+		delete(s.loaded, id)
+	}
+
+	return s.MemoryStorage.ClearDirtyIssuesByID(ctx, s.dirtyIDs(ctx))
+}
+
+// dirtyIDs returns the IDs the index currently marks dirty.
+func (s *GitRefsStorage) dirtyIDs(ctx context.Context) []string {
+	ids, _ := s.MemoryStorage.GetDirtyIssues(ctx)
+	return ids
+}
+
+// encodeIssue returns the blob stored for an issue: one compact JSON line.
+func encodeIssue(issue *types.Issue) ([]byte, error) {
+	data, err := json.Marshal(issue)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// decodeIssue parses an issue blob.
+func decodeIssue(data []byte) (*types.Issue, error) {
+	var issue types.Issue
+	if err := json.Unmarshal(data, &issue); err != nil {
+		return nil, fmt.Errorf("invalid issue record: %w", err)
+	}
+	if issue.ID == "" {
+		return nil, fmt.Errorf("invalid issue record: missing id")
+	}
+	return &issue, nil
+}
+
+// Lifecycle
+
+// Close flushes pending changes to refs and closes the index
+func (s *GitRefsStorage) Close() error {
+	err := s.Flush(context.Background())
+	_ = s.MemoryStorage.Close()
+	return err
+}
+
+// Path returns the git directory holding the issue refs
+func (s *GitRefsStorage) Path() string {
+	return s.gitDir
+}
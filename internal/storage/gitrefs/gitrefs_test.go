@@ -0,0 +1,502 @@
+package gitrefs
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/vcs"
+	"github.com/steveyegge/beads/internal/vcs/git"
+)
+
+// setupRepo creates a git repository with origin pointing at remote (if set).
+func setupRepo(t *testing.T, remote string) vcs.VCS {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	if remote != "" {
+		runGit(t, dir, "remote", "add", "origin", remote)
+	}
+	v, err := git.New(dir)
+	if err != nil {
+		t.Fatalf("git.New failed: %v", err)
+	}
+	return v
+}
+
+// setupRemote creates a bare repository to sync through.
+func setupRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, "", "init", "-q", "--bare", dir)
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func openStore(t *testing.T, v vcs.VCS) *GitRefsStorage {
+	t.Helper()
+	s, err := New(context.Background(), v)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return s
+}
+
+func newIssue(title string) *types.Issue {
+	return &types.Issue{
+		Title:     title,
+		Status:    types.StatusOpen,
+		Priority:  2,
+		IssueType: types.TypeTask,
+	}
+}
+
+func issueRefs(t *testing.T, v vcs.VCS) []string {
+	t.Helper()
+	entries, err := refs{v: v}.list(context.Background(), IssueRefPrefix)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	var ids []string
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestFlush_WritesRefsAndReloads(t *testing.T) {
+	ctx := context.Background()
+	v := setupRepo(t, "")
+
+	s := openStore(t, v)
+	if err := s.SetConfig(ctx, "issue_prefix", "bd"); err != nil {
+		t.Fatalf("SetConfig failed: %v", err)
+	}
+	a, b := newIssue("A"), newIssue("B")
+	if err := s.CreateIssues(ctx, []*types.Issue{a, b}, "tester"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+	if err := s.AddLabel(ctx, a.ID, "backend", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	dep := &types.Dependency{IssueID: b.ID, DependsOnID: a.ID, Type: types.DepBlocks}
+	if err := s.AddDependency(ctx, dep, "tester"); err != nil {
+		t.Fatalf("AddDependency failed: %v", err)
+	}
+	if _, err := s.AddIssueComment(ctx, a.ID, "tester", "hello"); err != nil {
+		t.Fatalf("AddIssueComment failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if got := issueRefs(t, v); !slices.Equal(got, []string{a.ID, b.ID}) {
+		t.Fatalf("issue refs = %v, want [%s %s]", got, a.ID, b.ID)
+	}
+	// Nothing is written to the working tree
+	root, _ := v.RepoRoot()
+	if status := runGit(t, root, "status", "--porcelain"); status != "" {
+		t.Errorf("working tree changed: %q", status)
+	}
+
+	s = openStore(t, v)
+	defer s.Close()
+	if prefix, _ := s.GetConfig(ctx, "issue_prefix"); prefix != "bd" {
+		t.Errorf("issue_prefix = %q after reload, want bd", prefix)
+	}
+	if labels, _ := s.GetLabels(ctx, a.ID); !slices.Equal(labels, []string{"backend"}) {
+		t.Errorf("labels = %v after reload", labels)
+	}
+	if comments, _ := s.GetIssueComments(ctx, a.ID); len(comments) != 1 || comments[0].Text != "hello" {
+		t.Errorf("comments = %v after reload", comments)
+	}
+	ready, err := s.GetReadyWork(ctx, types.WorkFilter{})
+	if err != nil {
+		t.Fatalf("GetReadyWork failed: %v", err)
+	}
+	if len(ready) != 1 || ready[0].ID != a.ID {
+		t.Errorf("ready = %d issues, want only %s", len(ready), a.ID)
+	}
+}
+
+func TestFlush_DeletesAndSkipsUnchanged(t *testing.T) {
+	ctx := context.Background()
+	v := setupRepo(t, "")
+
+	s := openStore(t, v)
+	_ = s.SetConfig(ctx, "issue_prefix", "bd")
+	a, b := newIssue("A"), newIssue("B")
+	if err := s.CreateIssues(ctx, []*types.Issue{a, b}, "tester"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	before := runGit(t, "", "--git-dir", s.Path(), "rev-parse", IssueRefPrefix+b.ID)
+
+	if err := s.DeleteIssue(ctx, a.ID); err != nil {
+		t.Fatalf("DeleteIssue failed: %v", err)
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if got := issueRefs(t, v); !slices.Equal(got, []string{b.ID}) {
+		t.Errorf("issue refs = %v after delete, want [%s]", got, b.ID)
+	}
+	if after := runGit(t, "", "--git-dir", s.Path(), "rev-parse", IssueRefPrefix+b.ID); after != before {
+		t.Errorf("unchanged issue was rewritten: %s -> %s", before, after)
+	}
+}
+
+func TestFlush_RefusesToClobberConcurrentWrite(t *testing.T) {
+	ctx := context.Background()
+	v := setupRepo(t, "")
+
+	s := openStore(t, v)
+	_ = s.SetConfig(ctx, "issue_prefix", "bd")
+	a := newIssue("A")
+	if err := s.CreateIssue(ctx, a, "tester"); err != nil {
+		t.Fatalf("CreateIssue failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	first, second := openStore(t, v), openStore(t, v)
+	if err := first.UpdateIssue(ctx, a.ID, map[string]interface{}{"title": "first"}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := second.UpdateIssue(ctx, a.ID, map[string]interface{}{"title": "second"}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := second.Close(); err == nil {
+		t.Fatal("expected the stale writer's flush to fail")
+	}
+
+	s = openStore(t, v)
+	defer s.Close()
+	if got, _ := s.GetIssue(ctx, a.ID); got == nil || got.Title != "first" {
+		t.Errorf("title = %v, want first", got)
+	}
+}
+
+func TestSync_ExchangesAndMergesPerIssue(t *testing.T) {
+	ctx := context.Background()
+	remote := setupRemote(t)
+	alice, bob := setupRepo(t, remote), setupRepo(t, remote)
+
+	// Alice creates two issues and publishes them
+	s := openStore(t, alice)
+	_ = s.SetConfig(ctx, "issue_prefix", "bd")
+	a, b := newIssue("A"), newIssue("B")
+	if err := s.CreateIssues(ctx, []*types.Issue{a, b}, "alice"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+	if err := s.AddLabel(ctx, a.ID, "shared", "alice"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	res, err := Sync(ctx, alice, "origin")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !slices.Equal(res.Pushed, []string{a.ID, b.ID}) {
+		t.Errorf("pushed = %v", res.Pushed)
+	}
+
+	// Bob pulls them
+	res, err = Sync(ctx, bob, "origin")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !slices.Equal(res.Pulled, []string{a.ID, b.ID}) {
+		t.Errorf("pulled = %v", res.Pulled)
+	}
+
+	// Both edit A (different fields) and Bob also edits B
+	s = openStore(t, alice)
+	if err := s.UpdateIssue(ctx, a.ID, map[string]interface{}{"title": "A by alice"}, "alice"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := s.AddLabel(ctx, a.ID, "alice", "alice"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	s = openStore(t, bob)
+	if err := s.UpdateIssue(ctx, a.ID, map[string]interface{}{"priority": 0}, "bob"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := s.RemoveLabel(ctx, a.ID, "shared", "bob"); err != nil {
+		t.Fatalf("RemoveLabel failed: %v", err)
+	}
+	if err := s.CloseIssue(ctx, b.ID, "done", "bob", ""); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := Sync(ctx, alice, "origin"); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	res, err = Sync(ctx, bob, "origin")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !slices.Equal(res.Merged, []string{a.ID}) || !slices.Contains(res.Pushed, b.ID) {
+		t.Errorf("bob's sync: merged %v, pushed %v", res.Merged, res.Pushed)
+	}
+	if _, err := Sync(ctx, alice, "origin"); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// Both clones converge on the merged record
+	for name, v := range map[string]vcs.VCS{"alice": alice, "bob": bob} {
+		s := openStore(t, v)
+		got, _ := s.GetIssue(ctx, a.ID)
+		if got == nil || got.Title != "A by alice" || got.Priority != 0 {
+			t.Errorf("%s: merged issue = %+v, want alice's title and bob's priority", name, got)
+		}
+		if labels, _ := s.GetLabels(ctx, a.ID); !slices.Equal(labels, []string{"alice"}) {
+			t.Errorf("%s: labels = %v, want [alice]", name, labels)
+		}
+		if got, _ := s.GetIssue(ctx, b.ID); got == nil || got.Status != types.StatusClosed {
+			t.Errorf("%s: B = %+v, want closed", name, got)
+		}
+		_ = s.Close()
+	}
+}
+
+func TestSync_PropagatesDeletes(t *testing.T) {
+	ctx := context.Background()
+	remote := setupRemote(t)
+	alice, bob := setupRepo(t, remote), setupRepo(t, remote)
+
+	s := openStore(t, alice)
+	_ = s.SetConfig(ctx, "issue_prefix", "bd")
+	a, b := newIssue("A"), newIssue("B")
+	if err := s.CreateIssues(ctx, []*types.Issue{a, b}, "alice"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	for _, v := range []vcs.VCS{alice, bob} {
+		if _, err := Sync(ctx, v, "origin"); err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+	}
+
+	// Alice deletes A; Bob edits B concurrently
+	s = openStore(t, alice)
+	if err := s.DeleteIssue(ctx, a.ID); err != nil {
+		t.Fatalf("DeleteIssue failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	s = openStore(t, bob)
+	if err := s.UpdateIssue(ctx, b.ID, map[string]interface{}{"title": "B by bob"}, "bob"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	res, err := Sync(ctx, alice, "origin")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !slices.Equal(res.Removed, []string{a.ID}) {
+		t.Errorf("removed = %v, want [%s]", res.Removed, a.ID)
+	}
+	res, err = Sync(ctx, bob, "origin")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !slices.Equal(res.Deleted, []string{a.ID}) || !slices.Equal(res.Pushed, []string{b.ID}) {
+		t.Errorf("bob's sync: deleted %v, pushed %v", res.Deleted, res.Pushed)
+	}
+	if got := issueRefs(t, bob); !slices.Equal(got, []string{b.ID}) {
+		t.Errorf("bob's refs = %v, want [%s]", got, b.ID)
+	}
+
+	// A second sync has nothing to do
+	res, err = Sync(ctx, bob, "origin")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(res.Pulled)+len(res.Pushed)+len(res.Merged)+len(res.Deleted)+len(res.Removed) != 0 {
+		t.Errorf("idempotent sync did work: %+v", res)
+	}
+}
+
+func TestSync_RejectedPushKeepsBase(t *testing.T) {
+	ctx := context.Background()
+	remote := setupRemote(t)
+	alice, bob := setupRepo(t, remote), setupRepo(t, remote)
+
+	s := openStore(t, alice)
+	_ = s.SetConfig(ctx, "issue_prefix", "bd")
+	a, b := newIssue("A"), newIssue("B")
+	if err := s.CreateIssues(ctx, []*types.Issue{a, b}, "alice"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	for _, v := range []vcs.VCS{alice, bob} {
+		if _, err := Sync(ctx, v, "origin"); err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+	}
+
+	// Bob publishes an edit to A; Alice edits B
+	s = openStore(t, bob)
+	if err := s.UpdateIssue(ctx, a.ID, map[string]interface{}{"priority": 0}, "bob"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := Sync(ctx, bob, "origin"); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	s = openStore(t, alice)
+	if err := s.UpdateIssue(ctx, b.ID, map[string]interface{}{"title": "B by alice"}, "alice"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// The remote rejects Alice's next push
+	hook := "#!/bin/sh\nif [ -f reject-once ]; then rm reject-once; exit 1; fi\n"
+	if err := os.WriteFile(filepath.Join(remote, "hooks", "pre-receive"), []byte(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(remote, "reject-once"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	base, err := refs{v: alice}.list(ctx, trackingPrefix("origin"))
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if _, err := Sync(ctx, alice, "origin"); err == nil {
+		t.Fatal("expected the rejected push to fail the sync")
+	}
+	after, err := refs{v: alice}.list(ctx, trackingPrefix("origin"))
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	for _, id := range []string{a.ID, b.ID} {
+		if after[id].oid != base[id].oid {
+			t.Errorf("tracking ref for %s moved after a rejected push", id)
+		}
+	}
+
+	// The retry pushes Alice's edit without reverting Bob's
+	res, err := Sync(ctx, alice, "origin")
+	if err != nil {
+		t.Fatalf("retry Sync failed: %v", err)
+	}
+	if !slices.Equal(res.Pushed, []string{b.ID}) {
+		t.Errorf("retry pushed %v, want [%s]", res.Pushed, b.ID)
+	}
+	if _, err := Sync(ctx, bob, "origin"); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	for name, v := range map[string]vcs.VCS{"alice": alice, "bob": bob} {
+		s := openStore(t, v)
+		if got, _ := s.GetIssue(ctx, a.ID); got == nil || got.Priority != 0 {
+			t.Errorf("%s: A = %+v, want bob's priority", name, got)
+		}
+		if got, _ := s.GetIssue(ctx, b.ID); got == nil || got.Title != "B by alice" {
+			t.Errorf("%s: B = %+v, want alice's title", name, got)
+		}
+		_ = s.Close()
+	}
+}
+
+func TestMergeRecords_KeepsUnknownFieldsAndComments(t *testing.T) {
+	base := []byte(`{"id":"bd-1","title":"T","status":"open","priority":2,"issue_type":"task","created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z","design":"d0"}`)
+	local := []byte(`{"id":"bd-1","title":"T local","status":"open","priority":2,"issue_type":"task","created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-02T00:00:00Z","design":"d0","comments":[{"id":1,"issue_id":"bd-1","author":"a","text":"x","created_at":"2025-01-02T00:00:00Z"}]}`)
+	remote := []byte(`{"id":"bd-1","title":"T","status":"closed","priority":2,"issue_type":"task","created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-03T00:00:00Z","closed_at":"2025-01-03T00:00:00Z","design":"d1","comments":[{"id":1,"issue_id":"bd-1","author":"b","text":"y","created_at":"2025-01-03T00:00:00Z"}]}`)
+
+	out, err := mergeRecords(base, local, remote)
+	if err != nil {
+		t.Fatalf("mergeRecords failed: %v", err)
+	}
+	issue, err := decodeIssue(out)
+	if err != nil {
+		t.Fatalf("decodeIssue failed: %v", err)
+	}
+	if issue.Title != "T local" || issue.Status != types.StatusClosed || issue.ClosedAt == nil {
+		t.Errorf("merged fields: title %q status %s closed_at %v", issue.Title, issue.Status, issue.ClosedAt)
+	}
+	if issue.Design != "d1" {
+		t.Errorf("design = %q, want the later side's d1", issue.Design)
+	}
+	if len(issue.Comments) != 2 {
+		t.Errorf("comments = %d, want both sides' comments", len(issue.Comments))
+	}
+}
+
+func TestMergeAttachments_ThreeWayByName(t *testing.T) {
+	base := []byte(`[{"name":"keep","sha256":"k"},{"name":"gone","sha256":"g"},{"name":"edited","sha256":"e0"}]`)
+	local := []byte(`[{"name":"keep","sha256":"k"},{"name":"edited","sha256":"e1"},{"name":"new-local","sha256":"l"}]`)
+	remote := []byte(`[{"name":"keep","sha256":"k"},{"name":"gone","sha256":"g"},{"name":"edited","sha256":"e0"},{"name":"new-remote","sha256":"r"}]`)
+
+	out, err := mergeAttachments(base, local, remote)
+	if err != nil {
+		t.Fatalf("mergeAttachments failed: %v", err)
+	}
+	var got []types.Attachment
+	for _, raw := range out {
+		var a types.Attachment
+		if err := json.Unmarshal(raw, &a); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, a)
+	}
+	want := []string{"edited:e1", "keep:k", "new-local:l", "new-remote:r"}
+	if len(got) != len(want) {
+		t.Fatalf("merged %d attachments %+v, want %v", len(got), got, want)
+	}
+	for i, a := range got {
+		if a.Name+":"+a.SHA256 != want[i] {
+			t.Errorf("attachment %d = %s:%s, want %s", i, a.Name, a.SHA256, want[i])
+		}
+	}
+}
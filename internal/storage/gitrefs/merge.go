@@ -0,0 +1,340 @@
+package gitrefs
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/steveyegge/beads/internal/merge"
+)
+
+// mergedFields are the record keys resolved by merge.Merge3WayWithTTL. All
+// other keys are taken from whichever side was updated last, except labels,
+// comments and attachments, which are merged as sets.
+var mergedFields = []string{
+	"title", "description", "notes", "status", "priority", "issue_type",
+	"updated_at", "closed_at", "close_reason", "closed_by_session",
+	"deleted_at", "deleted_by", "delete_reason", "original_type",
+}
+
+// mergeRecords three-way merges one issue's blobs. base is the record both
+// sides last agreed on and may be nil if the issue was created on both sides
+// independently. The field rules are the JSONL merge driver's, applied to a
+// single issue so unrelated issues can never conflict.
+func mergeRecords(base, local, remote []byte) ([]byte, error) {
+	var baseIssues []merge.Issue
+	if len(base) > 0 {
+		b, err := decodeMergeIssue(base)
+		if err != nil {
+			return nil, fmt.Errorf("base: %w", err)
+		}
+		baseIssues = append(baseIssues, b)
+	}
+	l, err := decodeMergeIssue(local)
+	if err != nil {
+		return nil, fmt.Errorf("local: %w", err)
+	}
+	r, err := decodeMergeIssue(remote)
+	if err != nil {
+		return nil, fmt.Errorf("remote: %w", err)
+	}
+
+	merged, conflicts := merge.Merge3WayWithTTL(baseIssues, []merge.Issue{l}, []merge.Issue{r}, merge.DefaultTombstoneTTL, false)
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("unresolved conflict in %s:\n%s", l.ID, strings.Join(conflicts, "\n"))
+	}
+	if len(merged) != 1 {
+		return nil, fmt.Errorf("merge of %s produced %d records", l.ID, len(merged))
+	}
+
+	var baseFull, localFull, remoteFull map[string]json.RawMessage
+	if len(base) > 0 {
+		if err := json.Unmarshal(base, &baseFull); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(local, &localFull); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(remote, &remoteFull); err != nil {
+		return nil, err
+	}
+
+	// Start from the most recently updated side so fields the merge driver
+	// does not know about are kept
+	result := localFull
+	if merged[0].UpdatedAt == r.UpdatedAt && r.UpdatedAt != l.UpdatedAt {
+		result = remoteFull
+	}
+	result = cloneRecord(result)
+
+	resolved, err := json.Marshal(merged[0])
+	if err != nil {
+		return nil, err
+	}
+	var resolvedFields map[string]json.RawMessage
+	if err := json.Unmarshal(resolved, &resolvedFields); err != nil {
+		return nil, err
+	}
+	for _, key := range mergedFields {
+		if v, ok := resolvedFields[key]; ok {
+			result[key] = v
+		} else {
+			delete(result, key)
+		}
+	}
+
+	deps, err := mergeDependencies(merged[0].Dependencies, localFull["dependencies"], remoteFull["dependencies"])
+	if err != nil {
+		return nil, err
+	}
+	setOrDelete(result, "dependencies", deps)
+
+	labels, err := mergeLabels(baseFull["labels"], localFull["labels"], remoteFull["labels"])
+	if err != nil {
+		return nil, err
+	}
+	setOrDelete(result, "labels", labels)
+
+	comments, err := mergeComments(localFull["comments"], remoteFull["comments"])
+	if err != nil {
+		return nil, err
+	}
+	setOrDelete(result, "comments", comments)
+
+	attachments, err := mergeAttachments(baseFull["attachments"], localFull["attachments"], remoteFull["attachments"])
+	if err != nil {
+		return nil, err
+	}
+	setOrDelete(result, "attachments", attachments)
+
+	// The content hash describes a single side; let the index recompute it
+	delete(result, "content_hash")
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Round-trip through the typed record so the blob is canonical
+	issue, err := decodeIssue(data)
+	if err != nil {
+		return nil, err
+	}
+	return encodeIssue(issue)
+}
+
+func decodeMergeIssue(data []byte) (merge.Issue, error) {
+	var issue merge.Issue
+	if err := json.Unmarshal(data, &issue); err != nil {
+		return issue, fmt.Errorf("invalid issue record: %w", err)
+	}
+	return issue, nil
+}
+
+func cloneRecord(rec map[string]json.RawMessage) map[string]json.RawMessage {
+	out := make(map[string]json.RawMessage, len(rec))
+	for k, v := range rec {
+		out[k] = v
+	}
+	return out
+}
+
+// setOrDelete stores v under key, or removes key if v is empty.
+func setOrDelete[T any](rec map[string]json.RawMessage, key string, v []T) {
+	if len(v) == 0 {
+		delete(rec, key)
+		return
+	}
+	data, _ := json.Marshal(v)
+	rec[key] = data
+}
+
+// mergeDependencies keeps the edges the merge driver resolved, taking each
+// edge's full record (metadata, thread ID) from the remote or local side.
+func mergeDependencies(resolved []merge.Dependency, local, remote json.RawMessage) ([]json.RawMessage, error) {
+	type depKey struct{ dependsOn, typ string }
+	full := make(map[depKey]json.RawMessage)
+	for _, raw := range []json.RawMessage{local, remote} {
+		if len(raw) == 0 {
+			continue
+		}
+		var deps []json.RawMessage
+		if err := json.Unmarshal(raw, &deps); err != nil {
+			return nil, fmt.Errorf("invalid dependencies: %w", err)
+		}
+		for _, dep := range deps {
+			var d merge.Dependency
+			if err := json.Unmarshal(dep, &d); err != nil {
+				return nil, fmt.Errorf("invalid dependency: %w", err)
+			}
+			full[depKey{d.DependsOnID, d.Type}] = dep
+		}
+	}
+
+	out := make([]json.RawMessage, 0, len(resolved))
+	for _, d := range resolved {
+		if dep, ok := full[depKey{d.DependsOnID, d.Type}]; ok {
+			out = append(out, dep)
+			continue
+		}
+		dep, err := json.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, dep)
+	}
+	return out, nil
+}
+
+// mergeLabels three-way merges label sets: a label added on either side is
+// kept, and a label removed on either side is dropped.
+func mergeLabels(base, local, remote json.RawMessage) ([]string, error) {
+	var b, l, r []string
+	for _, pair := range []struct {
+		raw json.RawMessage
+		dst *[]string
+	}{{base, &b}, {local, &l}, {remote, &r}} {
+		if len(pair.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(pair.raw, pair.dst); err != nil {
+			return nil, fmt.Errorf("invalid labels: %w", err)
+		}
+	}
+
+	var out []string
+	for _, label := range slices.Concat(l, r) {
+		if slices.Contains(out, label) {
+			continue
+		}
+		inBase := slices.Contains(b, label)
+		if inBase && (!slices.Contains(l, label) || !slices.Contains(r, label)) {
+			continue // Removed on one side
+		}
+		out = append(out, label)
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// mergeComments unions both sides' comments. Comments are append-only, so a
+// comment present on either side is kept; duplicates are matched on author,
+// text and creation time because comment IDs are assigned per clone.
+func mergeComments(local, remote json.RawMessage) ([]json.RawMessage, error) {
+	type commentKey struct{ author, text, createdAt string }
+	seen := make(map[commentKey]bool)
+	var out []json.RawMessage
+	for _, raw := range []json.RawMessage{local, remote} {
+		if len(raw) == 0 {
+			continue
+		}
+		var comments []json.RawMessage
+		if err := json.Unmarshal(raw, &comments); err != nil {
+			return nil, fmt.Errorf("invalid comments: %w", err)
+		}
+		for _, c := range comments {
+			var fields struct {
+				Author    string `json:"author"`
+				Text      string `json:"text"`
+				CreatedAt string `json:"created_at"`
+			}
+			if err := json.Unmarshal(c, &fields); err != nil {
+				return nil, fmt.Errorf("invalid comment: %w", err)
+			}
+			key := commentKey{fields.Author, fields.Text, fields.CreatedAt}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// mergeAttachments three-way merges attachments by name: an attachment added
+// or replaced on one side wins over an unchanged one, a removal wins over an
+// unchanged attachment, and if both sides replaced it the newer one is kept.
+func mergeAttachments(base, local, remote json.RawMessage) ([]json.RawMessage, error) {
+	type entry struct {
+		raw       json.RawMessage
+		createdAt string
+	}
+	decode := func(raw json.RawMessage) (map[string]entry, error) {
+		out := make(map[string]entry)
+		if len(raw) == 0 {
+			return out, nil
+		}
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, fmt.Errorf("invalid attachments: %w", err)
+		}
+		for _, a := range list {
+			var fields struct {
+				Name      string `json:"name"`
+				CreatedAt string `json:"created_at"`
+			}
+			if err := json.Unmarshal(a, &fields); err != nil {
+				return nil, fmt.Errorf("invalid attachment: %w", err)
+			}
+			out[fields.Name] = entry{raw: a, createdAt: fields.CreatedAt}
+		}
+		return out, nil
+	}
+	b, err := decode(base)
+	if err != nil {
+		return nil, err
+	}
+	l, err := decode(local)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decode(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(l)+len(r))
+	for name := range l {
+		names = append(names, name)
+	}
+	for name := range r {
+		if _, ok := l[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	same := func(x, y entry) bool { return string(x.raw) == string(y.raw) }
+	var out []json.RawMessage
+	for _, name := range names {
+		prev, inBase := b[name]
+		lv, inLocal := l[name]
+		rv, inRemote := r[name]
+		switch {
+		case inLocal && inRemote:
+			switch {
+			case same(lv, rv), inBase && same(rv, prev):
+				out = append(out, lv.raw)
+			case inBase && same(lv, prev):
+				out = append(out, rv.raw)
+			case rv.createdAt > lv.createdAt:
+				out = append(out, rv.raw)
+			default:
+				out = append(out, lv.raw)
+			}
+		case inLocal:
+			// Removed on the remote unless it was added or replaced here
+			if !inBase || !same(lv, prev) {
+				out = append(out, lv.raw)
+			}
+		case inRemote:
+			if !inBase || !same(rv, prev) {
+				out = append(out, rv.raw)
+			}
+		}
+	}
+	return out, nil
+}
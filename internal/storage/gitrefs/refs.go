@@ -0,0 +1,135 @@
+package gitrefs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+const (
+	// IssueRefPrefix is the ref namespace holding one blob per issue.
+	IssueRefPrefix = "refs/beads/issues/"
+
+	// ConfigRef holds this clone's config as a JSON blob. It is never pushed.
+	ConfigRef = "refs/beads/config"
+
+	// trackingRefRoot holds the issue refs as of the last completed sync,
+	// one namespace per remote.
+	trackingRefRoot = "refs/beads-remote/"
+
+	// fetchRefRoot is where a sync fetches the remote's issue refs. They are
+	// kept apart from the tracking refs until the sync's push succeeds.
+	fetchRefRoot = "refs/beads-fetch/"
+)
+
+// trackingPrefix returns the namespace recording remote's last-synced issue refs.
+func trackingPrefix(remote string) string {
+	return trackingRefRoot + remote + "/issues/"
+}
+
+// fetchPrefix returns the namespace remote's issue refs are fetched into.
+func fetchPrefix(remote string) string {
+	return fetchRefRoot + remote + "/issues/"
+}
+
+// refEntry is one ref's object ID and blob contents.
+type refEntry struct {
+	oid  string
+	data []byte
+}
+
+// refs wraps the git plumbing used by the storage and sync code.
+type refs struct {
+	v vcs.VCS
+}
+
+// list returns every ref under prefix, keyed by the name after the prefix,
+// with blob contents read in a single for-each-ref call.
+func (r refs) list(ctx context.Context, prefix string) (map[string]refEntry, error) {
+	out, err := r.v.Exec(ctx, "for-each-ref", "--format=%(objectname) %(refname)%00%(raw)%00", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+
+	entries := make(map[string]refEntry)
+	for _, record := range bytes.Split(out, []byte("\x00\n")) {
+		header, data, ok := bytes.Cut(record, []byte{0})
+		if !ok {
+			continue
+		}
+		oid, name, ok := strings.Cut(string(header), " ")
+		if !ok || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		entries[strings.TrimPrefix(name, prefix)] = refEntry{oid: oid, data: data}
+	}
+	return entries, nil
+}
+
+// read returns the contents of the blob oid.
+func (r refs) read(ctx context.Context, oid string) ([]byte, error) {
+	out, err := r.v.Exec(ctx, "cat-file", "blob", oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", oid, err)
+	}
+	return out, nil
+}
+
+// writeBlobs stores each blob in the object database and returns their
+// object IDs in order, using one hash-object call for the whole batch.
+func (r refs) writeBlobs(ctx context.Context, blobs [][]byte) ([]string, error) {
+	if len(blobs) == 0 {
+		return nil, nil
+	}
+
+	dir, err := os.MkdirTemp("", "beads-refs-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	args := []string{"hash-object", "-w", "--"}
+	for i, blob := range blobs {
+		path := filepath.Join(dir, fmt.Sprintf("%d.json", i))
+		if err := os.WriteFile(path, blob, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write temp blob: %w", err)
+		}
+		args = append(args, path)
+	}
+
+	out, err := r.v.Exec(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write blobs: %w", err)
+	}
+	oids := vcs.ParseLines(out)
+	if len(oids) != len(blobs) {
+		return nil, fmt.Errorf("hash-object returned %d object IDs for %d blobs", len(oids), len(blobs))
+	}
+	return oids, nil
+}
+
+// update points ref at oid. oldOID guards against concurrent writers: the
+// update fails unless the ref still has that value ("" = must not exist).
+func (r refs) update(ctx context.Context, ref, oid, oldOID string) error {
+	if _, err := r.v.Exec(ctx, "update-ref", "-m", "beads", ref, oid, oldOID); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ref, err)
+	}
+	return nil
+}
+
+// remove deletes ref if it still has the value oldOID ("" = any value).
+func (r refs) remove(ctx context.Context, ref, oldOID string) error {
+	args := []string{"update-ref", "-d", ref}
+	if oldOID != "" {
+		args = append(args, oldOID)
+	}
+	if _, err := r.v.Exec(ctx, args...); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", ref, err)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package gitrefs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+// SyncResult summarizes what a Sync did.
+type SyncResult struct {
+	Pulled  []string `json:"pulled,omitempty"`  // Issues updated or created from the remote
+	Pushed  []string `json:"pushed,omitempty"`  // Issues updated or created on the remote
+	Merged  []string `json:"merged,omitempty"`  // Issues changed on both sides and three-way merged
+	Deleted []string `json:"deleted,omitempty"` // Issues deleted locally because the remote deleted them
+	Removed []string `json:"removed,omitempty"` // Issues deleted on the remote because they were deleted locally
+}
+
+// Sync exchanges issue refs with remote. Each issue is reconciled on its own:
+// a side that did not change since the last sync takes the other side's
+// value, and an issue both sides changed is three-way merged against the
+// value recorded at the last sync. Only issues that actually differ are
+// pushed, so concurrent edits to different issues never conflict.
+//
+// The last-synced values live under refs/beads-remote/<remote>/issues/ and
+// only move once the push succeeds; the remote's current refs are fetched
+// into refs/beads-fetch/<remote>/issues/ instead. A Sync that fails part way
+// therefore leaves the merge base where it was. The local issue refs are
+// updated with compare-and-swap, so a Sync racing a local writer fails
+// instead of losing the write; rerunning it is safe.
+func Sync(ctx context.Context, v vcs.VCS, remote string) (*SyncResult, error) {
+	if remote == "" {
+		remote = "origin"
+	}
+	r := refs{v: v}
+	tracking, fetched := trackingPrefix(remote), fetchPrefix(remote)
+
+	base, err := r.list(ctx, tracking)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := v.Exec(ctx, "fetch", "--prune", "--no-tags", remote,
+		"+"+IssueRefPrefix+"*:"+fetched+"*"); err != nil {
+		return nil, fmt.Errorf("failed to fetch issue refs from %s: %w", remote, err)
+	}
+	theirs, err := r.list(ctx, fetched)
+	if err != nil {
+		return nil, err
+	}
+	ours, err := r.list(ctx, IssueRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	for id := range ours {
+		ids[id] = true
+	}
+	for id := range theirs {
+		ids[id] = true
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	// Refs pointing at blobs cannot fast-forward, so every update is a
+	// forced push leased on the value we fetched: if the remote moved since,
+	// the push is rejected and the next sync merges the new remote value.
+	var leases, specs []string
+	push := func(src, dst, expected string) {
+		leases = append(leases, "--force-with-lease="+dst+":"+expected)
+		specs = append(specs, src+":"+dst)
+	}
+
+	// agreed is what both sides hold once the sync completes ("" = deleted)
+	agreed := make(map[string]string, len(sorted))
+
+	result := &SyncResult{}
+	var merges []string
+	for _, id := range sorted {
+		local, hasLocal := ours[id]
+		upstream, hasRemote := theirs[id]
+		prev, hasBase := base[id]
+		ref := IssueRefPrefix + id
+
+		switch {
+		case hasLocal && hasRemote && local.oid == upstream.oid:
+			// In sync
+			agreed[id] = local.oid
+		case !hasRemote:
+			if hasBase && local.oid == prev.oid {
+				// Deleted on the remote and untouched here
+				if err := r.remove(ctx, ref, local.oid); err != nil {
+					return nil, err
+				}
+				agreed[id] = ""
+				result.Deleted = append(result.Deleted, id)
+				continue
+			}
+			// New here, or edited here after a remote delete: edits win
+			push(ref, ref, upstream.oid)
+			agreed[id] = local.oid
+			result.Pushed = append(result.Pushed, id)
+		case !hasLocal:
+			if hasBase && upstream.oid == prev.oid {
+				// Deleted here and untouched on the remote
+				push("", ref, upstream.oid)
+				agreed[id] = ""
+				result.Removed = append(result.Removed, id)
+				continue
+			}
+			if err := r.update(ctx, ref, upstream.oid, ""); err != nil {
+				return nil, err
+			}
+			agreed[id] = upstream.oid
+			result.Pulled = append(result.Pulled, id)
+		case hasBase && local.oid == prev.oid:
+			// Only the remote changed
+			if err := r.update(ctx, ref, upstream.oid, local.oid); err != nil {
+				return nil, err
+			}
+			agreed[id] = upstream.oid
+			result.Pulled = append(result.Pulled, id)
+		case hasBase && upstream.oid == prev.oid:
+			// Only we changed
+			push(ref, ref, upstream.oid)
+			agreed[id] = local.oid
+			result.Pushed = append(result.Pushed, id)
+		default:
+			merges = append(merges, id)
+		}
+	}
+
+	if len(merges) > 0 {
+		blobs := make([][]byte, len(merges))
+		for i, id := range merges {
+			var baseData []byte
+			if prev, ok := base[id]; ok {
+				baseData = prev.data
+			}
+			merged, err := mergeRecords(baseData, ours[id].data, theirs[id].data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge %s: %w", id, err)
+			}
+			blobs[i] = merged
+		}
+		oids, err := r.writeBlobs(ctx, blobs)
+		if err != nil {
+			return nil, err
+		}
+		for i, id := range merges {
+			ref := IssueRefPrefix + id
+			if err := r.update(ctx, ref, oids[i], ours[id].oid); err != nil {
+				return nil, err
+			}
+			if oids[i] != theirs[id].oid {
+				push(ref, ref, theirs[id].oid)
+			}
+			agreed[id] = oids[i]
+			result.Merged = append(result.Merged, id)
+		}
+	}
+
+	if len(specs) > 0 {
+		args := slices.Concat([]string{"push", "--atomic", "--no-verify"}, leases, []string{remote}, specs)
+		if _, err := v.Exec(ctx, args...); err != nil {
+			return nil, fmt.Errorf("failed to push issue refs to %s: %w", remote, err)
+		}
+	}
+
+	// Record what both sides now agree on as the base for the next sync.
+	// Issues deleted on both sides before this sync are only in base.
+	for id := range base {
+		if _, ok := agreed[id]; !ok {
+			agreed[id] = ""
+		}
+	}
+	for id, oid := range agreed {
+		prev := base[id].oid
+		switch {
+		case oid == prev:
+		case oid == "":
+			err = r.remove(ctx, tracking+id, prev)
+		default:
+			err = r.update(ctx, tracking+id, oid, prev)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for id, entry := range theirs {
+		if err := r.remove(ctx, fetched+id, entry.oid); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
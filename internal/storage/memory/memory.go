@@ -6,7 +6,10 @@ package memory
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -21,20 +24,25 @@ type MemoryStorage struct {
 	mu sync.RWMutex // Protects all maps
 
 	// Core data
-	issues       map[string]*types.Issue       // ID -> Issue
+	issues       map[string]*types.Issue        // ID -> Issue
 	dependencies map[string][]*types.Dependency // IssueID -> Dependencies
-	labels       map[string][]string           // IssueID -> Labels
-	events       map[string][]*types.Event     // IssueID -> Events
-	comments     map[string][]*types.Comment   // IssueID -> Comments
-	config       map[string]string             // Config key-value pairs
-	metadata     map[string]string             // Metadata key-value pairs
-	counters     map[string]int                // Prefix -> Last ID
+	labels       map[string][]string            // IssueID -> Labels
+	events       map[string][]*types.Event      // IssueID -> Events
+	comments     map[string][]*types.Comment    // IssueID -> Comments
+	attachments  map[string][]*types.Attachment // IssueID -> Attachments (sorted by name)
+	config       map[string]string              // Config key-value pairs
+	metadata     map[string]string              // Metadata key-value pairs
+	counters     map[string]int                 // Prefix -> Last ID
 
 	// Indexes for O(1) lookups
 	externalRefToID map[string]string // ExternalRef -> IssueID
 
 	// For tracking
-	dirty map[string]bool // IssueIDs that have been modified
+	dirty        map[string]bool   // IssueIDs that have been modified
+	exportHashes map[string]string // IssueID -> content hash at last export
+	commentSeq   int64             // Last comment ID handed out
+
+	txMu sync.Mutex // Serializes RunInTransaction
 
 	jsonlPath string // Path to source JSONL file (for reference)
 	closed    bool
@@ -48,11 +56,13 @@ func New(jsonlPath string) *MemoryStorage {
 		labels:          make(map[string][]string),
 		events:          make(map[string][]*types.Event),
 		comments:        make(map[string][]*types.Comment),
+		attachments:     make(map[string][]*types.Attachment),
 		config:          make(map[string]string),
 		metadata:        make(map[string]string),
 		counters:        make(map[string]int),
 		externalRefToID: make(map[string]string),
 		dirty:           make(map[string]bool),
+		exportHashes:    make(map[string]string),
 		jsonlPath:       jsonlPath,
 	}
 }
@@ -89,6 +99,17 @@ func (m *MemoryStorage) LoadFromIssues(issues []*types.Issue) error {
 		// Store comments
 		if len(issue.Comments) > 0 {
 			m.comments[issue.ID] = issue.Comments
+			for _, c := range issue.Comments {
+				if c.ID > m.commentSeq {
+					m.commentSeq = c.ID
+				}
+			}
+		}
+
+		// Store attachments
+		for _, a := range issue.Attachments {
+			a.IssueID = issue.ID
+			m.putAttachment(a)
 		}
 
 		// Update counter based on issue ID
@@ -111,6 +132,41 @@ func (m *MemoryStorage) LoadFromIssues(issues []*types.Issue) error {
 	return nil
 }
 
+// LoadEvents appends events to the audit trails of loaded issues, in order.
+// Events for issues that aren't loaded are skipped.
+func (m *MemoryStorage) LoadEvents(events []*types.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, event := range events {
+		if _, ok := m.issues[event.IssueID]; ok {
+			m.events[event.IssueID] = append(m.events[event.IssueID], event)
+		}
+	}
+}
+
+// Reset discards all issues, dependencies, labels, comments, events, config and
+// metadata. Backends that keep their source of truth elsewhere (such as the
+// Turso task files) use it to reload after a rolled-back transaction.
+func (m *MemoryStorage) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.issues = make(map[string]*types.Issue)
+	m.dependencies = make(map[string][]*types.Dependency)
+	m.labels = make(map[string][]string)
+	m.events = make(map[string][]*types.Event)
+	m.comments = make(map[string][]*types.Comment)
+	m.attachments = make(map[string][]*types.Attachment)
+	m.config = make(map[string]string)
+	m.metadata = make(map[string]string)
+	m.counters = make(map[string]int)
+	m.externalRefToID = make(map[string]string)
+	m.dirty = make(map[string]bool)
+	m.exportHashes = make(map[string]string)
+	m.commentSeq = 0
+}
+
 // GetAllIssues returns all issues in memory (for export to JSONL)
 func (m *MemoryStorage) GetAllIssues() []*types.Issue {
 	m.mu.RLock()
@@ -136,6 +192,11 @@ func (m *MemoryStorage) GetAllIssues() []*types.Issue {
 			issueCopy.Comments = comments
 		}
 
+		// Attach attachment references
+		if attachments, ok := m.attachments[issue.ID]; ok {
+			issueCopy.Attachments = attachments
+		}
+
 		issues = append(issues, &issueCopy)
 	}
 
@@ -189,26 +250,14 @@ func (m *MemoryStorage) CreateIssue(ctx context.Context, issue *types.Issue, act
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Validate
-	if err := issue.Validate(); err != nil {
+	now := time.Now()
+	if err := m.prepareIssue(issue, now); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Set timestamps
-	now := time.Now()
-	issue.CreatedAt = now
-	issue.UpdatedAt = now
-
 	// Generate ID if not set
 	if issue.ID == "" {
-		prefix := m.config["issue_prefix"]
-		if prefix == "" {
-			prefix = "bd" // Default fallback
-		}
-
-		// Get next ID
-		m.counters[prefix]++
-		issue.ID = fmt.Sprintf("%s-%d", prefix, m.counters[prefix])
+		issue.ID = m.nextIssueID(issue)
 	}
 
 	// Check for duplicate
@@ -216,7 +265,65 @@ func (m *MemoryStorage) CreateIssue(ctx context.Context, issue *types.Issue, act
 		return fmt.Errorf("issue %s already exists", issue.ID)
 	}
 
-	// Store issue
+	m.insertIssue(ctx, issue, actor, now)
+	return nil
+}
+
+// prepareIssue defaults the timestamps, enforces the closed_at and deleted_at
+// invariants the same way SQLite does (GH#523), validates the issue against
+// the configured custom statuses and types, and fills in the content hash.
+// The caller must hold the write lock.
+func (m *MemoryStorage) prepareIssue(issue *types.Issue, now time.Time) error {
+	if issue.CreatedAt.IsZero() {
+		issue.CreatedAt = now
+	}
+	if issue.UpdatedAt.IsZero() {
+		issue.UpdatedAt = now
+	}
+
+	latest := issue.CreatedAt
+	if issue.UpdatedAt.After(latest) {
+		latest = issue.UpdatedAt
+	}
+	if issue.Status == types.StatusClosed && issue.ClosedAt == nil {
+		closedAt := latest.Add(time.Second)
+		issue.ClosedAt = &closedAt
+	}
+	if issue.Status == types.StatusTombstone && issue.DeletedAt == nil {
+		deletedAt := latest.Add(time.Second)
+		issue.DeletedAt = &deletedAt
+	}
+
+	customStatuses := parseCommaSeparated(m.config["status.custom"])
+	customTypes := parseCommaSeparated(m.config["types.custom"])
+	if err := issue.ValidateWithCustom(customStatuses, customTypes); err != nil {
+		return err
+	}
+
+	if issue.ContentHash == "" {
+		issue.ContentHash = issue.ComputeContentHash()
+	}
+	return nil
+}
+
+// nextIssueID returns the next sequential ID for the configured prefix.
+// The caller must hold the write lock.
+func (m *MemoryStorage) nextIssueID(issue *types.Issue) string {
+	prefix := m.config["issue_prefix"]
+	if prefix == "" {
+		prefix = "bd" // Default fallback
+	}
+	if issue.IDPrefix != "" {
+		prefix = prefix + "-" + issue.IDPrefix
+	}
+
+	m.counters[prefix]++
+	return fmt.Sprintf("%s-%d", prefix, m.counters[prefix])
+}
+
+// insertIssue stores a prepared issue and records its creation event.
+// The caller must hold the write lock.
+func (m *MemoryStorage) insertIssue(ctx context.Context, issue *types.Issue, actor string, now time.Time) {
 	m.issues[issue.ID] = issue
 	m.dirty[issue.ID] = true
 
@@ -225,16 +332,22 @@ func (m *MemoryStorage) CreateIssue(ctx context.Context, issue *types.Issue, act
 		m.externalRefToID[*issue.ExternalRef] = issue.ID
 	}
 
-	// Record event
-	event := &types.Event{
+	// Explicit child IDs advance the parent's counter so GetNextChildID
+	// never hands out a collision (GH#728)
+	if parentID, childNum, ok := extractParentAndChildNumber(issue.ID); ok {
+		if m.counters[parentID] < childNum {
+			m.counters[parentID] = childNum
+		}
+	}
+
+	m.events[issue.ID] = append(m.events[issue.ID], &types.Event{
 		IssueID:   issue.ID,
 		EventType: types.EventCreated,
 		Actor:     actor,
 		CreatedAt: now,
-	}
-	m.events[issue.ID] = append(m.events[issue.ID], event)
-
-	return nil
+		CommandID: storage.CommandID(ctx),
+		SourceOp:  storage.SourceOp(ctx),
+	})
 }
 
 // CreateIssues creates multiple issues atomically
@@ -242,30 +355,22 @@ func (m *MemoryStorage) CreateIssues(ctx context.Context, issues []*types.Issue,
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	now := time.Now()
+
 	// Validate all first
 	for i, issue := range issues {
-		if err := issue.Validate(); err != nil {
+		if err := m.prepareIssue(issue, now); err != nil {
 			return fmt.Errorf("validation failed for issue %d: %w", i, err)
 		}
 	}
 
-	now := time.Now()
-	prefix := m.config["issue_prefix"]
-	if prefix == "" {
-		prefix = "bd"
-	}
-
 	// Track IDs in this batch to detect duplicates within batch
 	batchIDs := make(map[string]bool)
 
 	// Generate IDs for issues that need them
 	for _, issue := range issues {
-		issue.CreatedAt = now
-		issue.UpdatedAt = now
-
 		if issue.ID == "" {
-			m.counters[prefix]++
-			issue.ID = fmt.Sprintf("%s-%d", prefix, m.counters[prefix])
+			issue.ID = m.nextIssueID(issue)
 		}
 
 		// Check for duplicates in existing issues
@@ -282,22 +387,7 @@ func (m *MemoryStorage) CreateIssues(ctx context.Context, issues []*types.Issue,
 
 	// Store all issues
 	for _, issue := range issues {
-		m.issues[issue.ID] = issue
-		m.dirty[issue.ID] = true
-
-		// Index external ref for O(1) lookup
-		if issue.ExternalRef != nil && *issue.ExternalRef != "" {
-			m.externalRefToID[*issue.ExternalRef] = issue.ID
-		}
-
-		// Record event
-		event := &types.Event{
-			IssueID:   issue.ID,
-			EventType: types.EventCreated,
-			Actor:     actor,
-			CreatedAt: now,
-		}
-		m.events[issue.ID] = append(m.events[issue.ID], event)
+		m.insertIssue(ctx, issue, actor, now)
 	}
 
 	return nil
@@ -361,6 +451,43 @@ func (m *MemoryStorage) GetIssueByExternalRef(ctx context.Context, externalRef s
 	return &issueCopy, nil
 }
 
+// allowedUpdateFields lists the fields UpdateIssue accepts, matching the
+// SQLite backend so callers get the same errors in every mode.
+var allowedUpdateFields = map[string]bool{
+	"status":              true,
+	"priority":            true,
+	"title":               true,
+	"assignee":            true,
+	"description":         true,
+	"design":              true,
+	"acceptance_criteria": true,
+	"notes":               true,
+	"issue_type":          true,
+	"estimated_minutes":   true,
+	"external_ref":        true,
+	"closed_at":           true,
+	"close_reason":        true,
+	"closed_by_session":   true,
+	"sender":              true,
+	"wisp":                true,
+	"pinned":              true,
+	"hook_bead":           true,
+	"role_bead":           true,
+	"agent_state":         true,
+	"last_activity":       true,
+	"role_type":           true,
+	"rig":                 true,
+	"mol_type":            true,
+	"event_category":      true,
+	"event_actor":         true,
+	"event_target":        true,
+	"event_payload":       true,
+	"due_at":              true,
+	"defer_until":         true,
+	"budget_usd":          true,
+	"await_id":            true,
+}
+
 // UpdateIssue updates fields on an issue
 func (m *MemoryStorage) UpdateIssue(ctx context.Context, id string, updates map[string]interface{}, actor string) error {
 	m.mu.Lock()
@@ -371,110 +498,268 @@ func (m *MemoryStorage) UpdateIssue(ctx context.Context, id string, updates map[
 		return fmt.Errorf("issue %s not found", id)
 	}
 
-	now := time.Now()
-	issue.UpdatedAt = now
+	customStatuses := parseCommaSeparated(m.config["status.custom"])
+	for key, value := range updates {
+		if !allowedUpdateFields[key] {
+			return fmt.Errorf("invalid field for update: %s", key)
+		}
+		if err := validateFieldUpdate(key, value, customStatuses); err != nil {
+			return fmt.Errorf("validate field update: %w", err)
+		}
+	}
 
-	// Apply updates
+	// Apply to a copy so a bad value leaves the issue untouched
+	updated := *issue
 	for key, value := range updates {
-		switch key {
-		case "title":
-			if v, ok := value.(string); ok {
-				issue.Title = v
-			}
-		case "description":
-			if v, ok := value.(string); ok {
-				issue.Description = v
-			}
-		case "design":
-			if v, ok := value.(string); ok {
-				issue.Design = v
-			}
-		case "acceptance_criteria":
-			if v, ok := value.(string); ok {
-				issue.AcceptanceCriteria = v
-			}
-		case "notes":
-			if v, ok := value.(string); ok {
-				issue.Notes = v
-			}
-		case "status":
-			if v, ok := value.(string); ok {
-				oldStatus := issue.Status
-				issue.Status = types.Status(v)
-
-				// Manage closed_at
-				if issue.Status == types.StatusClosed && oldStatus != types.StatusClosed {
-					issue.ClosedAt = &now
-				} else if issue.Status != types.StatusClosed && oldStatus == types.StatusClosed {
-					issue.ClosedAt = nil
-				}
-			}
-		case "priority":
-			if v, ok := value.(int); ok {
-				issue.Priority = v
-			}
-		case "issue_type":
-			if v, ok := value.(string); ok {
-				issue.IssueType = types.IssueType(v)
-			}
-		case "assignee":
-			if v, ok := value.(string); ok {
-				issue.Assignee = v
-			} else if value == nil {
-				issue.Assignee = ""
-			}
-		case "external_ref":
-			// Update external ref index
-			oldRef := issue.ExternalRef
-			if v, ok := value.(string); ok {
-				// Remove old index entry if exists
-				if oldRef != nil && *oldRef != "" {
-					delete(m.externalRefToID, *oldRef)
-				}
-				// Add new index entry
-				if v != "" {
-					m.externalRefToID[v] = id
-				}
-				issue.ExternalRef = &v
-			} else if value == nil {
-				// Remove old index entry if exists
-				if oldRef != nil && *oldRef != "" {
-					delete(m.externalRefToID, *oldRef)
-				}
-				issue.ExternalRef = nil
-			}
-		case "close_reason":
-			if v, ok := value.(string); ok {
-				issue.CloseReason = v
-			}
-		case "closed_by_session":
-			if v, ok := value.(string); ok {
-				issue.ClosedBySession = v
-			}
+		if err := applyFieldUpdate(&updated, key, value); err != nil {
+			return err
 		}
 	}
 
-	m.dirty[id] = true
+	now := time.Now()
+	updated.UpdatedAt = now
+
+	// Auto-manage closed_at when status changes, unless it was set explicitly
+	// (imports preserve the original timestamp)
+	_, hasStatus := updates["status"]
+	if _, explicit := updates["closed_at"]; !explicit && hasStatus {
+		if updated.Status == types.StatusClosed {
+			closedAt := now
+			updated.ClosedAt = &closedAt
+		} else if issue.Status == types.StatusClosed {
+			updated.ClosedAt = nil
+			updated.CloseReason = ""
+		}
+	}
+
+	updated.ContentHash = updated.ComputeContentHash()
+
+	// Keep the external ref index in sync
+	if oldRef := issue.ExternalRef; oldRef != nil && *oldRef != "" {
+		delete(m.externalRefToID, *oldRef)
+	}
+	if updated.ExternalRef != nil && *updated.ExternalRef != "" {
+		m.externalRefToID[*updated.ExternalRef] = id
+	}
 
-	// Record event
 	eventType := types.EventUpdated
-	if status, hasStatus := updates["status"]; hasStatus {
-		if status == string(types.StatusClosed) {
+	if hasStatus {
+		switch {
+		case updated.Status == types.StatusClosed:
 			eventType = types.EventClosed
+		case issue.Status == types.StatusClosed:
+			eventType = types.EventReopened
+		default:
+			eventType = types.EventStatusChanged
 		}
 	}
 
+	*issue = updated
+	m.dirty[id] = true
+
 	event := &types.Event{
 		IssueID:   id,
 		EventType: eventType,
 		Actor:     actor,
 		CreatedAt: now,
+		CommandID: storage.CommandID(ctx),
+		SourceOp:  storage.SourceOp(ctx),
 	}
 	m.events[id] = append(m.events[id], event)
 
 	return nil
 }
 
+// validateFieldUpdate applies the same value checks as the SQLite backend.
+func validateFieldUpdate(key string, value interface{}, customStatuses []string) error {
+	switch key {
+	case "priority":
+		if priority, ok := value.(int); ok && (priority < 0 || priority > 4) {
+			return fmt.Errorf("priority must be between 0 and 4 (got %d)", priority)
+		}
+	case "status":
+		if status, ok := stringValue(value); ok {
+			// Tombstones are only created via bd delete (bd-y68)
+			if types.Status(status) == types.StatusTombstone {
+				return fmt.Errorf("cannot set status to tombstone directly; use 'bd delete' instead")
+			}
+			if !types.Status(status).IsValidWithCustom(customStatuses) {
+				return fmt.Errorf("invalid status: %s", status)
+			}
+		}
+	case "issue_type":
+		if issueType, ok := stringValue(value); ok && !types.IssueType(issueType).IsValid() {
+			return fmt.Errorf("invalid issue type: %s", issueType)
+		}
+	case "title":
+		if title, ok := value.(string); ok && (len(title) == 0 || len(title) > 500) {
+			return fmt.Errorf("title must be 1-500 characters")
+		}
+	case "estimated_minutes":
+		if mins, ok := value.(int); ok && mins < 0 {
+			return fmt.Errorf("estimated_minutes cannot be negative")
+		}
+	case "budget_usd":
+		if budget, ok := value.(float64); ok && budget < 0 {
+			return fmt.Errorf("budget_usd cannot be negative")
+		}
+	}
+	return nil
+}
+
+// applyFieldUpdate sets a single whitelisted field on issue.
+func applyFieldUpdate(issue *types.Issue, key string, value interface{}) error {
+	if key == "estimated_minutes" {
+		switch v := value.(type) {
+		case nil:
+			issue.EstimatedMinutes = nil
+		case int:
+			issue.EstimatedMinutes = &v
+		case *int:
+			issue.EstimatedMinutes = v
+		default:
+			return fmt.Errorf("estimated_minutes must be int, got %T", value)
+		}
+		return nil
+	}
+	if key == "budget_usd" {
+		switch v := value.(type) {
+		case nil:
+			issue.BudgetUSD = nil
+		case float64:
+			issue.BudgetUSD = &v
+		case *float64:
+			issue.BudgetUSD = v
+		default:
+			return fmt.Errorf("budget_usd must be float64, got %T", value)
+		}
+		return nil
+	}
+	if key == "priority" {
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("priority must be int, got %T", value)
+		}
+		issue.Priority = v
+		return nil
+	}
+	if key == "wisp" || key == "pinned" {
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("%s must be bool, got %T", key, value)
+		}
+		if key == "wisp" {
+			issue.Ephemeral = v
+		} else {
+			issue.Pinned = v
+		}
+		return nil
+	}
+
+	if times := map[string]**time.Time{
+		"closed_at":     &issue.ClosedAt,
+		"last_activity": &issue.LastActivity,
+		"due_at":        &issue.DueAt,
+		"defer_until":   &issue.DeferUntil,
+	}; times[key] != nil {
+		switch v := value.(type) {
+		case nil:
+			*times[key] = nil
+		case time.Time:
+			*times[key] = &v
+		case *time.Time:
+			*times[key] = v
+		default:
+			return fmt.Errorf("%s must be a time, got %T", key, value)
+		}
+		return nil
+	}
+
+	if key == "external_ref" {
+		switch v := value.(type) {
+		case nil:
+			issue.ExternalRef = nil
+		case string:
+			issue.ExternalRef = &v
+		case *string:
+			issue.ExternalRef = v
+		default:
+			return fmt.Errorf("external_ref must be string or *string, got %T", value)
+		}
+		return nil
+	}
+
+	// Everything else is a string column; nil clears it
+	v, ok := stringValue(value)
+	if !ok && value != nil {
+		return fmt.Errorf("%s must be a string, got %T", key, value)
+	}
+	switch key {
+	case "status":
+		issue.Status = types.Status(v)
+	case "issue_type":
+		issue.IssueType = types.IssueType(v)
+	case "agent_state":
+		issue.AgentState = types.AgentState(v)
+	case "mol_type":
+		issue.MolType = types.MolType(v)
+	case "title":
+		issue.Title = v
+	case "assignee":
+		issue.Assignee = v
+	case "description":
+		issue.Description = v
+	case "design":
+		issue.Design = v
+	case "acceptance_criteria":
+		issue.AcceptanceCriteria = v
+	case "notes":
+		issue.Notes = v
+	case "close_reason":
+		issue.CloseReason = v
+	case "closed_by_session":
+		issue.ClosedBySession = v
+	case "sender":
+		issue.Sender = v
+	case "hook_bead":
+		issue.HookBead = v
+	case "role_bead":
+		issue.RoleBead = v
+	case "role_type":
+		issue.RoleType = v
+	case "rig":
+		issue.Rig = v
+	case "event_category":
+		issue.EventKind = v
+	case "event_actor":
+		issue.Actor = v
+	case "event_target":
+		issue.Target = v
+	case "event_payload":
+		issue.Payload = v
+	case "await_id":
+		issue.AwaitID = v
+	}
+	return nil
+}
+
+// stringValue unwraps the string-like values callers pass in update maps.
+func stringValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case types.Status:
+		return string(v), true
+	case types.IssueType:
+		return string(v), true
+	case types.AgentState:
+		return string(v), true
+	case types.MolType:
+		return string(v), true
+	}
+	return "", false
+}
+
 // CloseIssue closes an issue with a reason.
 // The session parameter tracks which Claude Code session closed the issue (can be empty).
 func (m *MemoryStorage) CloseIssue(ctx context.Context, id string, reason string, actor string, session string) error {
@@ -517,12 +802,61 @@ func (m *MemoryStorage) CreateTombstone(ctx context.Context, id string, actor st
 		Actor:     actor,
 		Comment:   &reason,
 		CreatedAt: now,
+		CommandID: storage.CommandID(ctx),
+		SourceOp:  storage.SourceOp(ctx),
 	}
 	m.events[id] = append(m.events[id], event)
 
 	return nil
 }
 
+// RestoreTombstone turns a tombstone back into a live issue with the given
+// status, restoring the issue type saved when it was deleted.
+func (m *MemoryStorage) RestoreTombstone(ctx context.Context, id string, status types.Status, actor string) error {
+	if status == types.StatusTombstone || status == "" {
+		return fmt.Errorf("invalid status for restored issue: %q", status)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	issue, ok := m.issues[id]
+	if !ok || issue.Status != types.StatusTombstone {
+		return fmt.Errorf("issue %s is not a tombstone", id)
+	}
+
+	now := time.Now()
+	issue.Status = status
+	issue.ClosedAt = nil
+	if status == types.StatusClosed {
+		issue.ClosedAt = &now
+	}
+	if issue.OriginalType != "" {
+		issue.IssueType = types.IssueType(issue.OriginalType)
+	}
+	issue.OriginalType = ""
+	issue.DeletedAt = nil
+	issue.DeletedBy = ""
+	issue.DeleteReason = ""
+	issue.UpdatedAt = now
+
+	m.dirty[id] = true
+
+	oldValue, newValue := string(types.StatusTombstone), string(status)
+	m.events[id] = append(m.events[id], &types.Event{
+		IssueID:   id,
+		EventType: "restored",
+		Actor:     actor,
+		OldValue:  &oldValue,
+		NewValue:  &newValue,
+		CreatedAt: now,
+		CommandID: storage.CommandID(ctx),
+		SourceOp:  storage.SourceOp(ctx),
+	})
+
+	return nil
+}
+
 // DeleteIssue permanently deletes an issue and all associated data
 func (m *MemoryStorage) DeleteIssue(ctx context.Context, id string) error {
 	m.mu.Lock()
@@ -542,12 +876,25 @@ func (m *MemoryStorage) DeleteIssue(ctx context.Context, id string) error {
 	// Delete the issue
 	delete(m.issues, id)
 
-	// Delete associated data
+	// Delete associated data, including dependencies pointing at the issue
 	delete(m.dependencies, id)
+	for issueID, deps := range m.dependencies {
+		kept := deps[:0:0]
+		for _, dep := range deps {
+			if dep.DependsOnID != id {
+				kept = append(kept, dep)
+			}
+		}
+		if len(kept) != len(deps) {
+			m.dependencies[issueID] = kept
+		}
+	}
 	delete(m.labels, id)
 	delete(m.events, id)
 	delete(m.comments, id)
+	delete(m.attachments, id)
 	delete(m.dirty, id)
+	delete(m.exportHashes, id)
 
 	return nil
 }
@@ -557,88 +904,14 @@ func (m *MemoryStorage) SearchIssues(ctx context.Context, query string, filter t
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	now := time.Now()
 	var results []*types.Issue
 
 	for _, issue := range m.issues {
-		// Apply filters
-		if filter.Status != nil && issue.Status != *filter.Status {
-			continue
-		}
-		if filter.Priority != nil && issue.Priority != *filter.Priority {
-			continue
-		}
-		if filter.IssueType != nil && issue.IssueType != *filter.IssueType {
-			continue
-		}
-		if filter.Assignee != nil && issue.Assignee != *filter.Assignee {
+		if !m.matchesIssueFilter(issue, query, filter, now) {
 			continue
 		}
 
-		// Query search (title, description, or ID)
-		if query != "" {
-			query = strings.ToLower(query)
-			if !strings.Contains(strings.ToLower(issue.Title), query) &&
-				!strings.Contains(strings.ToLower(issue.Description), query) &&
-				!strings.Contains(strings.ToLower(issue.ID), query) {
-				continue
-			}
-		}
-
-		// Label filtering: must have ALL specified labels
-		if len(filter.Labels) > 0 {
-			issueLabels := m.labels[issue.ID]
-			hasAllLabels := true
-			for _, reqLabel := range filter.Labels {
-				found := false
-				for _, label := range issueLabels {
-					if label == reqLabel {
-						found = true
-						break
-					}
-				}
-				if !found {
-					hasAllLabels = false
-					break
-				}
-			}
-			if !hasAllLabels {
-				continue
-			}
-		}
-
-		// ID filtering
-		if len(filter.IDs) > 0 {
-			found := false
-			for _, filterID := range filter.IDs {
-				if issue.ID == filterID {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
-		}
-
-		// ID prefix filtering (for shell completion)
-		if filter.IDPrefix != "" && !strings.HasPrefix(issue.ID, filter.IDPrefix) {
-			continue
-		}
-
-		// Parent filtering (bd-yqhh): filter children by parent issue
-		if filter.ParentID != nil {
-			isChild := false
-			for _, dep := range m.dependencies[issue.ID] {
-				if dep.Type == types.DepParentChild && dep.DependsOnID == *filter.ParentID {
-					isChild = true
-					break
-				}
-			}
-			if !isChild {
-				continue
-			}
-		}
-
 		// Copy issue and attach metadata
 		issueCopy := *issue
 		if deps, ok := m.dependencies[issue.ID]; ok {
@@ -651,12 +924,15 @@ func (m *MemoryStorage) SearchIssues(ctx context.Context, query string, filter t
 		results = append(results, &issueCopy)
 	}
 
-	// Sort by priority, then by created_at
+	// Sort by priority, then newest first (matches SQLite)
 	sort.Slice(results, func(i, j int) bool {
 		if results[i].Priority != results[j].Priority {
 			return results[i].Priority < results[j].Priority
 		}
-		return results[i].CreatedAt.After(results[j].CreatedAt)
+		if !results[i].CreatedAt.Equal(results[j].CreatedAt) {
+			return results[i].CreatedAt.After(results[j].CreatedAt)
+		}
+		return results[i].ID < results[j].ID
 	})
 
 	// Apply limit
@@ -667,32 +943,311 @@ func (m *MemoryStorage) SearchIssues(ctx context.Context, query string, filter t
 	return results, nil
 }
 
+// matchesIssueFilter reports whether issue satisfies query and every set
+// field of filter. The caller must hold at least a read lock.
+func (m *MemoryStorage) matchesIssueFilter(issue *types.Issue, query string, filter types.IssueFilter, now time.Time) bool {
+	// Query search (title, description, or ID)
+	if query != "" && !containsFold(issue.Title, query) &&
+		!containsFold(issue.Description, query) && !containsFold(issue.ID, query) {
+		return false
+	}
+	if filter.TitleSearch != "" && !containsFold(issue.Title, filter.TitleSearch) {
+		return false
+	}
+	if filter.TitleContains != "" && !containsFold(issue.Title, filter.TitleContains) {
+		return false
+	}
+	if filter.DescriptionContains != "" && !containsFold(issue.Description, filter.DescriptionContains) {
+		return false
+	}
+	if filter.NotesContains != "" && !containsFold(issue.Notes, filter.NotesContains) {
+		return false
+	}
+
+	// Tombstones are hidden unless explicitly requested
+	if filter.Status != nil {
+		if issue.Status != *filter.Status {
+			return false
+		}
+	} else if !filter.IncludeTombstones && issue.Status == types.StatusTombstone {
+		return false
+	}
+	for _, status := range filter.ExcludeStatus {
+		if issue.Status == status {
+			return false
+		}
+	}
+	for _, issueType := range filter.ExcludeTypes {
+		if issue.IssueType == issueType {
+			return false
+		}
+	}
+
+	if filter.Priority != nil && issue.Priority != *filter.Priority {
+		return false
+	}
+	if filter.PriorityMin != nil && issue.Priority < *filter.PriorityMin {
+		return false
+	}
+	if filter.PriorityMax != nil && issue.Priority > *filter.PriorityMax {
+		return false
+	}
+	if filter.IssueType != nil && issue.IssueType != *filter.IssueType {
+		return false
+	}
+	if filter.Assignee != nil && issue.Assignee != *filter.Assignee {
+		return false
+	}
+
+	// Date ranges
+	if filter.CreatedAfter != nil && !issue.CreatedAt.After(*filter.CreatedAfter) {
+		return false
+	}
+	if filter.CreatedBefore != nil && !issue.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+	if filter.UpdatedAfter != nil && !issue.UpdatedAt.After(*filter.UpdatedAfter) {
+		return false
+	}
+	if filter.UpdatedBefore != nil && !issue.UpdatedAt.Before(*filter.UpdatedBefore) {
+		return false
+	}
+	if filter.ClosedAfter != nil && (issue.ClosedAt == nil || !issue.ClosedAt.After(*filter.ClosedAfter)) {
+		return false
+	}
+	if filter.ClosedBefore != nil && (issue.ClosedAt == nil || !issue.ClosedAt.Before(*filter.ClosedBefore)) {
+		return false
+	}
+
+	// Empty/null checks
+	if filter.EmptyDescription && issue.Description != "" {
+		return false
+	}
+	if filter.NoAssignee && issue.Assignee != "" {
+		return false
+	}
+	issueLabels := m.labels[issue.ID]
+	if filter.NoLabels && len(issueLabels) > 0 {
+		return false
+	}
+
+	// Label filtering: must have ALL of Labels and at least one of LabelsAny
+	for _, reqLabel := range filter.Labels {
+		if !hasLabel(issueLabels, reqLabel) {
+			return false
+		}
+	}
+	if len(filter.LabelsAny) > 0 {
+		hasAny := false
+		for _, label := range filter.LabelsAny {
+			if hasLabel(issueLabels, label) {
+				hasAny = true
+				break
+			}
+		}
+		if !hasAny {
+			return false
+		}
+	}
+	for _, state := range filter.States {
+		if !matchesStateFilter(issueLabels, state) {
+			return false
+		}
+	}
+
+	// ID filtering
+	if len(filter.IDs) > 0 {
+		found := false
+		for _, filterID := range filter.IDs {
+			if issue.ID == filterID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	// ID prefix filtering (for shell completion)
+	if filter.IDPrefix != "" && !strings.HasPrefix(issue.ID, filter.IDPrefix) {
+		return false
+	}
+
+	if filter.Ephemeral != nil && issue.Ephemeral != *filter.Ephemeral {
+		return false
+	}
+	if filter.Pinned != nil && issue.Pinned != *filter.Pinned {
+		return false
+	}
+	if filter.IsTemplate != nil && issue.IsTemplate != *filter.IsTemplate {
+		return false
+	}
+
+	// Parent filtering (bd-yqhh): direct children of the parent issue
+	if filter.ParentID != nil {
+		isChild := false
+		for _, dep := range m.dependencies[issue.ID] {
+			if dep.Type == types.DepParentChild && dep.DependsOnID == *filter.ParentID {
+				isChild = true
+				break
+			}
+		}
+		if !isChild {
+			return false
+		}
+	}
+
+	if filter.MolType != nil && issue.MolType != *filter.MolType {
+		return false
+	}
+
+	// Time-based scheduling filters (GH#820)
+	if filter.Deferred && issue.DeferUntil == nil {
+		return false
+	}
+	if filter.DeferAfter != nil && (issue.DeferUntil == nil || !issue.DeferUntil.After(*filter.DeferAfter)) {
+		return false
+	}
+	if filter.DeferBefore != nil && (issue.DeferUntil == nil || !issue.DeferUntil.Before(*filter.DeferBefore)) {
+		return false
+	}
+	if filter.DueAfter != nil && (issue.DueAt == nil || !issue.DueAt.After(*filter.DueAfter)) {
+		return false
+	}
+	if filter.DueBefore != nil && (issue.DueAt == nil || !issue.DueAt.Before(*filter.DueBefore)) {
+		return false
+	}
+	if filter.Overdue && (issue.DueAt == nil || !issue.DueAt.Before(now) || issue.Status == types.StatusClosed) {
+		return false
+	}
+
+	return true
+}
+
+// containsFold reports whether substr is within s, ignoring case like SQL LIKE.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// hasLabel reports whether labels contains label.
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesStateFilter reports whether labels satisfy a state dimension filter.
+func matchesStateFilter(labels []string, f types.StateFilter) bool {
+	prefix := f.Dimension + ":"
+	set := false
+	for _, l := range labels {
+		if !strings.HasPrefix(l, prefix) {
+			continue
+		}
+		set = true
+		if f.Value == "" || l == f.Label() {
+			return true
+		}
+	}
+	return !set && f.IncludeUnset
+}
+
 // AddDependency adds a dependency between issues
 func (m *MemoryStorage) AddDependency(ctx context.Context, dep *types.Dependency, actor string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Check that both issues exist
-	if _, exists := m.issues[dep.IssueID]; !exists {
+	if !dep.Type.IsValid() {
+		return fmt.Errorf("invalid dependency type: %q (must be non-empty string, max 50 chars)", dep.Type)
+	}
+
+	issue, exists := m.issues[dep.IssueID]
+	if !exists {
 		return fmt.Errorf("issue %s not found", dep.IssueID)
 	}
-	if _, exists := m.issues[dep.DependsOnID]; !exists {
-		return fmt.Errorf("issue %s not found", dep.DependsOnID)
+
+	// External refs (external:<project>:<capability>) are resolved lazily
+	isExternalRef := strings.HasPrefix(dep.DependsOnID, "external:")
+	if !isExternalRef {
+		target, exists := m.issues[dep.DependsOnID]
+		if !exists {
+			return fmt.Errorf("dependency target %s not found", dep.DependsOnID)
+		}
+		if dep.IssueID == dep.DependsOnID {
+			return fmt.Errorf("issue cannot depend on itself")
+		}
+		// Children depend on parents, never the other way around
+		if dep.Type == types.DepParentChild && issue.IssueType == types.TypeEpic && target.IssueType != types.TypeEpic {
+			return fmt.Errorf("invalid parent-child dependency: parent (%s) cannot depend on child (%s). Use: bd dep add %s %s --type parent-child",
+				dep.IssueID, dep.DependsOnID, dep.DependsOnID, dep.IssueID)
+		}
 	}
 
-	// Check for duplicates
+	// One edge per issue pair, whatever its type
 	for _, existing := range m.dependencies[dep.IssueID] {
-		if existing.DependsOnID == dep.DependsOnID && existing.Type == dep.Type {
-			return fmt.Errorf("dependency already exists")
+		if existing.DependsOnID == dep.DependsOnID {
+			return fmt.Errorf("dependency from %s to %s already exists", dep.IssueID, dep.DependsOnID)
 		}
 	}
 
+	// relates-to links are bidirectional by design, so skip cycle detection
+	if dep.Type != types.DepRelatesTo && m.reachable(dep.DependsOnID, dep.IssueID) {
+		return fmt.Errorf("cannot add dependency: would create a cycle (%s → %s → ... → %s)",
+			dep.IssueID, dep.DependsOnID, dep.IssueID)
+	}
+
+	if dep.CreatedAt.IsZero() {
+		dep.CreatedAt = time.Now()
+	}
+	if dep.CreatedBy == "" {
+		dep.CreatedBy = actor
+	}
+
 	m.dependencies[dep.IssueID] = append(m.dependencies[dep.IssueID], dep)
 	m.dirty[dep.IssueID] = true
+	if !isExternalRef {
+		m.dirty[dep.DependsOnID] = true
+	}
+
+	comment := fmt.Sprintf("Added dependency: %s %s %s", dep.IssueID, dep.Type, dep.DependsOnID)
+	m.events[dep.IssueID] = append(m.events[dep.IssueID], &types.Event{
+		IssueID:   dep.IssueID,
+		EventType: types.EventDependencyAdded,
+		Actor:     actor,
+		Comment:   &comment,
+		CreatedAt: dep.CreatedAt,
+		CommandID: storage.CommandID(ctx),
+		SourceOp:  storage.SourceOp(ctx),
+	})
 
 	return nil
 }
 
+// reachable reports whether to can be reached from from by following
+// dependency edges of any type. The caller must hold at least a read lock.
+func (m *MemoryStorage) reachable(from, to string) bool {
+	visited := map[string]bool{from: true}
+	stack := []string{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, dep := range m.dependencies[id] {
+			if dep.DependsOnID == to {
+				return true
+			}
+			if !visited[dep.DependsOnID] {
+				visited[dep.DependsOnID] = true
+				stack = append(stack, dep.DependsOnID)
+			}
+		}
+	}
+	return false
+}
+
 // RemoveDependency removes a dependency
 func (m *MemoryStorage) RemoveDependency(ctx context.Context, issueID, dependsOnID string, actor string) error {
 	m.mu.Lock()
@@ -709,6 +1264,22 @@ func (m *MemoryStorage) RemoveDependency(ctx context.Context, issueID, dependsOn
 
 	m.dependencies[issueID] = newDeps
 	m.dirty[issueID] = true
+	if _, exists := m.issues[dependsOnID]; exists {
+		m.dirty[dependsOnID] = true
+	}
+
+	if len(newDeps) != len(deps) {
+		comment := fmt.Sprintf("Removed dependency on %s", dependsOnID)
+		m.events[issueID] = append(m.events[issueID], &types.Event{
+			IssueID:   issueID,
+			EventType: types.EventDependencyRemoved,
+			Actor:     actor,
+			Comment:   &comment,
+			CreatedAt: time.Now(),
+			CommandID: storage.CommandID(ctx),
+			SourceOp:  storage.SourceOp(ctx),
+		})
+	}
 
 	return nil
 }
@@ -739,7 +1310,8 @@ func (m *MemoryStorage) GetDependents(ctx context.Context, issueID string) ([]*t
 		for _, dep := range deps {
 			if dep.DependsOnID == issueID {
 				if issue, exists := m.issues[id]; exists {
-					results = append(results, issue)
+					issueCopy := *issue
+					results = append(results, &issueCopy)
 				}
 				break
 			}
@@ -854,97 +1426,219 @@ func (m *MemoryStorage) GetAllDependencyRecords(ctx context.Context) (map[string
 	return result, nil
 }
 
-// GetDirtyIssueHash returns the hash for dirty issue tracking
+// GetDirtyIssueHash returns the content hash of a dirty issue
 func (m *MemoryStorage) GetDirtyIssueHash(ctx context.Context, issueID string) (string, error) {
-	// Memory storage doesn't track dirty hashes, return empty string
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if issue, ok := m.issues[issueID]; ok && m.dirty[issueID] {
+		return issue.ContentHash, nil
+	}
 	return "", nil
 }
 
 // GetExportHash returns the hash for export tracking
 func (m *MemoryStorage) GetExportHash(ctx context.Context, issueID string) (string, error) {
-	// Memory storage doesn't track export hashes, return empty string
-	return "", nil
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.exportHashes[issueID], nil
 }
 
 // SetExportHash sets the hash for export tracking
 func (m *MemoryStorage) SetExportHash(ctx context.Context, issueID, hash string) error {
-	// Memory storage doesn't track export hashes, no-op
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.exportHashes[issueID] = hash
 	return nil
 }
 
 // ClearAllExportHashes clears all export hashes
 func (m *MemoryStorage) ClearAllExportHashes(ctx context.Context) error {
-	// Memory storage doesn't track export hashes, no-op
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.exportHashes = make(map[string]string)
 	return nil
 }
 
-// GetJSONLFileHash gets the JSONL file hash
+// GetJSONLFileHash gets the JSONL file hash (stored as metadata, like SQLite)
 func (m *MemoryStorage) GetJSONLFileHash(ctx context.Context) (string, error) {
-	// Memory storage doesn't track JSONL file hashes, return empty string
-	return "", nil
+	return m.GetMetadata(ctx, "jsonl_file_hash")
 }
 
 // SetJSONLFileHash sets the JSONL file hash
 func (m *MemoryStorage) SetJSONLFileHash(ctx context.Context, fileHash string) error {
-	// Memory storage doesn't track JSONL file hashes, no-op
-	return nil
+	return m.SetMetadata(ctx, "jsonl_file_hash", fileHash)
 }
 
-// GetDependencyTree gets the dependency tree for an issue
+// GetDependencyTree gets the dependency tree for an issue.
+// Normal mode walks what the issue depends on; reverse mode walks its dependents.
 func (m *MemoryStorage) GetDependencyTree(ctx context.Context, issueID string, maxDepth int, showAllPaths bool, reverse bool) ([]*types.TreeNode, error) {
-	// Get the root issue first
-	root, err := m.GetIssue(ctx, issueID)
-	if err != nil {
-		return nil, err
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if maxDepth <= 0 {
+		maxDepth = 50
 	}
-	if root == nil {
+
+	root, ok := m.issues[issueID]
+	if !ok {
 		return nil, nil
 	}
 
+	// Dependents, indexed once for reverse mode
+	var dependents map[string][]string
+	if reverse {
+		dependents = make(map[string][]string)
+		for id, deps := range m.dependencies {
+			for _, dep := range deps {
+				dependents[dep.DependsOnID] = append(dependents[dep.DependsOnID], id)
+			}
+		}
+	}
+
+	// Enumerate every acyclic path, like the SQLite recursive CTE
 	var nodes []*types.TreeNode
+	onPath := make(map[string]bool)
+	var walk func(issue *types.Issue, depth int, parentID string)
+	walk = func(issue *types.Issue, depth int, parentID string) {
+		nodes = append(nodes, &types.TreeNode{
+			Issue:     *issue,
+			Depth:     depth,
+			ParentID:  parentID, // Root's parent is itself
+			Truncated: depth == maxDepth,
+		})
+		if depth >= maxDepth {
+			return
+		}
 
-	// Add root node at depth 0
-	rootNode := &types.TreeNode{
-		Depth:    0,
-		ParentID: issueID, // Root's parent is itself
-	}
-	rootNode.ID = root.ID
-	rootNode.Title = root.Title
-	rootNode.Description = root.Description
-	rootNode.Status = root.Status
-	rootNode.Priority = root.Priority
-	rootNode.IssueType = root.IssueType
-	nodes = append(nodes, rootNode)
-
-	// Get dependencies (or dependents if reverse)
-	// Note: reverse mode not fully implemented - uses same logic for now
-	deps, err := m.GetDependencies(ctx, issueID)
-	if err != nil {
-		return nil, err
+		var next []string
+		if reverse {
+			next = dependents[issue.ID]
+		} else {
+			for _, dep := range m.dependencies[issue.ID] {
+				next = append(next, dep.DependsOnID)
+			}
+		}
+
+		onPath[issue.ID] = true
+		for _, id := range next {
+			if child, ok := m.issues[id]; ok && !onPath[id] {
+				walk(child, depth+1, issue.ID)
+			}
+		}
+		delete(onPath, issue.ID)
 	}
+	walk(root, 0, root.ID)
 
-	// Add dependencies at depth 1
-	for _, dep := range deps {
-		node := &types.TreeNode{
-			Depth:    1,
-			ParentID: issueID, // Parent is the root
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Depth != nodes[j].Depth {
+			return nodes[i].Depth < nodes[j].Depth
 		}
-		node.ID = dep.ID
-		node.Title = dep.Title
-		node.Description = dep.Description
-		node.Status = dep.Status
-		node.Priority = dep.Priority
-		node.IssueType = dep.IssueType
-		nodes = append(nodes, node)
+		if nodes[i].Priority != nodes[j].Priority {
+			return nodes[i].Priority < nodes[j].Priority
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	if showAllPaths {
+		return nodes, nil
 	}
 
-	return nodes, nil
+	// Keep only the shallowest occurrence of each issue
+	seen := make(map[string]bool)
+	deduped := nodes[:0]
+	for _, node := range nodes {
+		if !seen[node.ID] {
+			seen[node.ID] = true
+			deduped = append(deduped, node)
+		}
+	}
+	return deduped, nil
 }
 
-// DetectCycles detects dependency cycles
+// DetectCycles detects dependency cycles, ignoring bidirectional relates-to links
 func (m *MemoryStorage) DetectCycles(ctx context.Context) ([][]*types.Issue, error) {
-	// Simplified - return empty (no cycles detected)
-	return nil, nil
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.dependencies))
+	for id := range m.dependencies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	visited := make(map[string]bool)
+	onStack := make(map[string]bool)
+	seen := make(map[string]bool)
+	var cycles [][]*types.Issue
+
+	var dfs func(id string, path []string)
+	dfs = func(id string, path []string) {
+		visited[id] = true
+		onStack[id] = true
+		path = append(path, id)
+
+		for _, dep := range m.dependencies[id] {
+			if dep.Type == types.DepRelatesTo {
+				continue
+			}
+			next := dep.DependsOnID
+			if !visited[next] {
+				dfs(next, path)
+				continue
+			}
+			if !onStack[next] {
+				continue
+			}
+
+			// Found a cycle; rotate it to start at the smallest ID so the
+			// same cycle reached from different entry points dedupes
+			start := 0
+			for i, n := range path {
+				if n == next {
+					start = i
+					break
+				}
+			}
+			cycle := path[start:]
+			minIdx := 0
+			for i, n := range cycle {
+				if n < cycle[minIdx] {
+					minIdx = i
+				}
+			}
+			normalized := append(append([]string{}, cycle[minIdx:]...), cycle[:minIdx]...)
+			key := strings.Join(normalized, "→")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			var issues []*types.Issue
+			for _, cycleID := range normalized {
+				if issue, ok := m.issues[cycleID]; ok {
+					issueCopy := *issue
+					issues = append(issues, &issueCopy)
+				}
+			}
+			if len(issues) > 0 {
+				cycles = append(cycles, issues)
+			}
+		}
+
+		onStack[id] = false
+	}
+
+	for _, id := range ids {
+		if !visited[id] {
+			dfs(id, nil)
+		}
+	}
+
+	return cycles, nil
 }
 
 // Add label methods
@@ -993,7 +1687,17 @@ func (m *MemoryStorage) GetLabels(ctx context.Context, issueID string) ([]string
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.labels[issueID], nil
+	return sortedLabels(m.labels[issueID]), nil
+}
+
+// sortedLabels returns a sorted copy of labels (nil if there are none).
+func sortedLabels(labels []string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+	sorted := append([]string(nil), labels...)
+	sort.Strings(sorted)
+	return sorted
 }
 
 func (m *MemoryStorage) GetLabelsForIssues(ctx context.Context, issueIDs []string) (map[string][]string, error) {
@@ -1002,8 +1706,8 @@ func (m *MemoryStorage) GetLabelsForIssues(ctx context.Context, issueIDs []strin
 
 	result := make(map[string][]string)
 	for _, issueID := range issueIDs {
-		if labels, exists := m.labels[issueID]; exists {
-			result[issueID] = labels
+		if labels, exists := m.labels[issueID]; exists && len(labels) > 0 {
+			result[issueID] = sortedLabels(labels)
 		}
 	}
 	return result, nil
@@ -1026,6 +1730,13 @@ func (m *MemoryStorage) GetIssuesByLabel(ctx context.Context, label string) ([]*
 		}
 	}
 
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Priority != results[j].Priority {
+			return results[i].Priority < results[j].Priority
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+
 	return results, nil
 }
 
@@ -1034,6 +1745,15 @@ func (m *MemoryStorage) GetReadyWork(ctx context.Context, filter types.WorkFilte
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	now := time.Now()
+	blocked := m.blockedIssueIDs()
+
+	// Build set of descendant IDs if parent filter is specified
+	var descendantIDs map[string]bool
+	if filter.ParentID != nil {
+		descendantIDs = m.getAllDescendants(*filter.ParentID)
+	}
+
 	var results []*types.Issue
 
 	for _, issue := range m.issues {
@@ -1042,6 +1762,11 @@ func (m *MemoryStorage) GetReadyWork(ctx context.Context, filter types.WorkFilte
 			continue
 		}
 
+		// Skip wisps - they never show up as ready work
+		if issue.Ephemeral {
+			continue
+		}
+
 		// Status filtering: default to open OR in_progress if not specified
 		if filter.Status == "" {
 			if issue.Status != types.StatusOpen && issue.Status != types.StatusInProgress {
@@ -1123,8 +1848,21 @@ func (m *MemoryStorage) GetReadyWork(ctx context.Context, filter types.WorkFilte
 			}
 		}
 
-		// Skip issues with any open 'blocks' dependencies
-		if len(m.getOpenBlockers(issue.ID)) > 0 {
+		// Parent filtering: only include descendants of specified parent
+		if descendantIDs != nil && !descendantIDs[issue.ID] {
+			continue
+		}
+
+		if filter.MolType != nil && issue.MolType != *filter.MolType {
+			continue
+		}
+
+		// Hide issues deferred into the future (GH#820)
+		if !filter.IncludeDeferred && issue.DeferUntil != nil && issue.DeferUntil.After(now) {
+			continue
+		}
+
+		if blocked[issue.ID] {
 			continue
 		}
 
@@ -1216,6 +1954,105 @@ func (m *MemoryStorage) getOpenBlockers(issueID string) []string {
 	return blockers
 }
 
+// blockedIssueIDs returns the set the SQLite blocked_issues_cache would hold:
+// issues with an open 'blocks' blocker, an unmet 'conditional-blocks' blocker
+// or a closed 'waits-for' gate, plus everything below them via parent-child.
+// The caller must hold at least a read lock.
+func (m *MemoryStorage) blockedIssueIDs() map[string]bool {
+	children := make(map[string][]string)
+	for issueID, deps := range m.dependencies {
+		for _, dep := range deps {
+			if dep.Type == types.DepParentChild {
+				children[dep.DependsOnID] = append(children[dep.DependsOnID], issueID)
+			}
+		}
+	}
+
+	blocked := make(map[string]bool)
+	var frontier []string
+	for issueID, deps := range m.dependencies {
+		for _, dep := range deps {
+			if m.dependencyBlocks(dep, children) {
+				blocked[issueID] = true
+				frontier = append(frontier, issueID)
+				break
+			}
+		}
+	}
+
+	// Children of blocked issues inherit the blockage
+	for depth := 0; depth < 50 && len(frontier) > 0; depth++ {
+		var next []string
+		for _, id := range frontier {
+			for _, child := range children[id] {
+				if !blocked[child] {
+					blocked[child] = true
+					next = append(next, child)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return blocked
+}
+
+// dependencyBlocks reports whether a single dependency currently blocks its issue.
+func (m *MemoryStorage) dependencyBlocks(dep *types.Dependency, children map[string][]string) bool {
+	switch dep.Type {
+	case types.DepBlocks:
+		blocker, ok := m.issues[dep.DependsOnID]
+		if !ok {
+			return true // Missing blocker: data is incomplete, keep blocking
+		}
+		switch blocker.Status {
+		case types.StatusOpen, types.StatusInProgress, types.StatusBlocked, types.StatusDeferred, types.StatusHooked:
+			return true
+		}
+	case types.DepConditionalBlocks:
+		// B runs only if A fails (bd-kzda)
+		blocker, ok := m.issues[dep.DependsOnID]
+		if !ok {
+			return false
+		}
+		switch blocker.Status {
+		case types.StatusOpen, types.StatusInProgress, types.StatusBlocked, types.StatusDeferred:
+			return true
+		case types.StatusClosed:
+			return !types.IsFailureClose(blocker.CloseReason)
+		}
+	case types.DepWaitsFor:
+		// Fanout gate over the spawner's children (bd-xo1o.2)
+		meta := types.WaitsForMeta{Gate: types.WaitsForAllChildren}
+		if dep.Metadata != "" {
+			_ = json.Unmarshal([]byte(dep.Metadata), &meta)
+		}
+		spawnerID := meta.SpawnerID
+		if spawnerID == "" {
+			spawnerID = dep.DependsOnID
+		}
+		anyOpen, anyClosed := false, false
+		for _, childID := range children[spawnerID] {
+			child, ok := m.issues[childID]
+			if !ok {
+				continue
+			}
+			if child.Status == types.StatusClosed || child.Status == types.StatusTombstone {
+				anyClosed = true
+			} else {
+				anyOpen = true
+			}
+		}
+		switch meta.Gate {
+		case "", types.WaitsForAllChildren:
+			return anyOpen
+		case types.WaitsForAnyChildren:
+			return !anyClosed
+		}
+	}
+	return false
+}
+
 // GetBlockedIssues returns issues that are blocked by other issues
 // Note: Pinned issues are excluded from the output (beads-ei4)
 func (m *MemoryStorage) GetBlockedIssues(ctx context.Context, filter types.WorkFilter) ([]*types.BlockedIssue, error) {
@@ -1302,8 +2139,55 @@ func (m *MemoryStorage) collectDescendants(parentID string, descendants map[stri
 	}
 }
 
+// GetEpicsEligibleForClosure returns every open epic with its child counts;
+// an epic is eligible once it has children and all of them are closed.
 func (m *MemoryStorage) GetEpicsEligibleForClosure(ctx context.Context) ([]*types.EpicStatus, error) {
-	return nil, nil
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var results []*types.EpicStatus
+	for _, issue := range m.issues {
+		if issue.IssueType != types.TypeEpic || issue.Status == types.StatusClosed {
+			continue
+		}
+		total, closed := m.countChildren(issue.ID)
+		epic := *issue
+		results = append(results, &types.EpicStatus{
+			Epic:             &epic,
+			TotalChildren:    total,
+			ClosedChildren:   closed,
+			EligibleForClose: total > 0 && closed == total,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i].Epic, results[j].Epic
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	return results, nil
+}
+
+// countChildren returns the number of parent-child children of parentID and
+// how many of them are closed. The caller must hold at least a read lock.
+func (m *MemoryStorage) countChildren(parentID string) (total, closed int) {
+	for issueID, deps := range m.dependencies {
+		for _, dep := range deps {
+			if dep.Type != types.DepParentChild || dep.DependsOnID != parentID {
+				continue
+			}
+			if child, ok := m.issues[issueID]; ok {
+				total++
+				if child.Status == types.StatusClosed {
+					closed++
+				}
+			}
+		}
+	}
+	return total, closed
 }
 
 func (m *MemoryStorage) GetStaleIssues(ctx context.Context, filter types.StaleFilter) ([]*types.Issue, error) {
@@ -1321,7 +2205,8 @@ func (m *MemoryStorage) GetStaleIssues(ctx context.Context, filter types.StaleFi
 			continue
 		}
 		if issue.UpdatedAt.Before(cutoff) {
-			stale = append(stale, issue)
+			issueCopy := *issue
+			stale = append(stale, &issueCopy)
 		}
 	}
 
@@ -1343,6 +2228,7 @@ func (m *MemoryStorage) GetNewlyUnblockedByClose(ctx context.Context, closedIssu
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	blocked := m.blockedIssueIDs()
 	var unblocked []*types.Issue
 
 	// Find issues that depend on the closed issue
@@ -1373,9 +2259,8 @@ func (m *MemoryStorage) GetNewlyUnblockedByClose(ctx context.Context, closedIssu
 			continue
 		}
 
-		// Check if now unblocked (no remaining open blockers)
-		blockers := m.getOpenBlockers(issueID)
-		if len(blockers) == 0 {
+		// Check if now unblocked (no remaining blockers of any kind)
+		if !blocked[issueID] {
 			issueCopy := *issue
 			unblocked = append(unblocked, &issueCopy)
 		}
@@ -1389,17 +2274,63 @@ func (m *MemoryStorage) GetNewlyUnblockedByClose(ctx context.Context, closedIssu
 	return unblocked, nil
 }
 
+// AddComment records a comment event on an issue
 func (m *MemoryStorage) AddComment(ctx context.Context, issueID, actor, comment string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	issue, ok := m.issues[issueID]
+	if !ok {
+		return fmt.Errorf("issue %s not found", issueID)
+	}
+
+	now := time.Now()
+	issue.UpdatedAt = now
+	m.dirty[issueID] = true
+	m.events[issueID] = append(m.events[issueID], &types.Event{
+		IssueID:   issueID,
+		EventType: types.EventCommented,
+		Actor:     actor,
+		Comment:   &comment,
+		CreatedAt: now,
+		CommandID: storage.CommandID(ctx),
+		SourceOp:  storage.SourceOp(ctx),
+	})
+
 	return nil
 }
 
+// GetEvents returns an issue's events, newest first
 func (m *MemoryStorage) GetEvents(ctx context.Context, issueID string, limit int) ([]*types.Event, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	events := m.events[issueID]
+	stored := m.events[issueID]
+	events := make([]*types.Event, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		events = append(events, stored[i])
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+// GetRecentEvents returns the most recent events across all issues, newest first
+func (m *MemoryStorage) GetRecentEvents(ctx context.Context, limit int) ([]*types.Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var events []*types.Event
+	for _, stored := range m.events {
+		events = append(events, stored...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
 	if limit > 0 && len(events) > limit {
-		events = events[len(events)-limit:]
+		events = events[:limit]
 	}
 
 	return events, nil
@@ -1409,8 +2340,13 @@ func (m *MemoryStorage) AddIssueComment(ctx context.Context, issueID, author, te
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if _, ok := m.issues[issueID]; !ok {
+		return nil, fmt.Errorf("issue %s not found", issueID)
+	}
+
+	m.commentSeq++
 	comment := &types.Comment{
-		ID:        int64(len(m.comments[issueID]) + 1),
+		ID:        m.commentSeq,
 		IssueID:   issueID,
 		Author:    author,
 		Text:      text,
@@ -1443,6 +2379,77 @@ func (m *MemoryStorage) GetCommentsForIssues(ctx context.Context, issueIDs []str
 	return result, nil
 }
 
+func (m *MemoryStorage) AddAttachment(ctx context.Context, attachment *types.Attachment, actor string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.issues[attachment.IssueID]; !ok {
+		return fmt.Errorf("issue %s not found", attachment.IssueID)
+	}
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = time.Now().UTC()
+	}
+	if attachment.CreatedBy == "" {
+		attachment.CreatedBy = actor
+	}
+
+	a := *attachment
+	m.putAttachment(&a)
+	m.dirty[attachment.IssueID] = true
+	return nil
+}
+
+// putAttachment inserts or replaces a by name, keeping the issue's list
+// sorted. Caller must hold the write lock.
+func (m *MemoryStorage) putAttachment(a *types.Attachment) {
+	list := m.attachments[a.IssueID]
+	i, found := slices.BinarySearchFunc(list, a.Name, func(e *types.Attachment, name string) int {
+		return strings.Compare(e.Name, name)
+	})
+	if found {
+		list[i] = a
+		return
+	}
+	m.attachments[a.IssueID] = slices.Insert(list, i, a)
+}
+
+func (m *MemoryStorage) RemoveAttachment(ctx context.Context, issueID, name, actor string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := m.attachments[issueID]
+	i := slices.IndexFunc(list, func(a *types.Attachment) bool { return a.Name == name })
+	if i < 0 {
+		return fmt.Errorf("attachment %q not found on %s", name, issueID)
+	}
+	list = slices.Delete(list, i, i+1)
+	if len(list) == 0 {
+		delete(m.attachments, issueID)
+	} else {
+		m.attachments[issueID] = list
+	}
+	m.dirty[issueID] = true
+	return nil
+}
+
+func (m *MemoryStorage) GetAttachments(ctx context.Context, issueID string) ([]*types.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Clone(m.attachments[issueID]), nil
+}
+
+func (m *MemoryStorage) GetAllAttachments(ctx context.Context) (map[string][]*types.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string][]*types.Attachment, len(m.attachments))
+	for id, attachments := range m.attachments {
+		result[id] = slices.Clone(attachments)
+	}
+	return result, nil
+}
+
 func (m *MemoryStorage) GetStatistics(ctx context.Context) (*types.Statistics, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -1451,6 +2458,13 @@ func (m *MemoryStorage) GetStatistics(ctx context.Context) (*types.Statistics, e
 
 	// First pass: count by status
 	for _, issue := range m.issues {
+		// TotalIssues excludes tombstones (matches SQLite behavior)
+		if issue.Status != types.StatusTombstone {
+			stats.TotalIssues++
+		}
+		if issue.Pinned {
+			stats.PinnedIssues++
+		}
 		switch issue.Status {
 		case types.StatusOpen:
 			stats.OpenIssues++
@@ -1462,14 +2476,9 @@ func (m *MemoryStorage) GetStatistics(ctx context.Context) (*types.Statistics, e
 			stats.DeferredIssues++
 		case types.StatusTombstone:
 			stats.TombstoneIssues++
-		case types.StatusPinned:
-			stats.PinnedIssues++
 		}
 	}
 
-	// TotalIssues excludes tombstones (matches SQLite behavior)
-	stats.TotalIssues = stats.OpenIssues + stats.InProgressIssues + stats.ClosedIssues + stats.DeferredIssues + stats.PinnedIssues
-
 	// Second pass: calculate blocked and ready issues based on dependencies
 	// An issue is blocked if it has open blockers (uses same logic as GetBlockedIssues)
 	for id, issue := range m.issues {
@@ -1697,12 +2706,127 @@ func (m *MemoryStorage) GetMetadata(ctx context.Context, key string) (string, er
 	return m.metadata[key], nil
 }
 
-// Prefix rename operations (no-ops for memory storage)
+// Prefix rename operations
+
+// UpdateIssueID renames an issue, moving its dependencies, labels, comments,
+// events and dirty marker to the new ID, and takes the text fields from issue.
 func (m *MemoryStorage) UpdateIssueID(ctx context.Context, oldID, newID string, issue *types.Issue, actor string) error {
-	return fmt.Errorf("UpdateIssueID not supported in --no-db mode")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.issues[oldID]
+	if !ok {
+		return fmt.Errorf("issue not found: %s", oldID)
+	}
+	if _, taken := m.issues[newID]; taken && newID != oldID {
+		return fmt.Errorf("issue %s already exists", newID)
+	}
+
+	now := time.Now()
+	existing.ID = newID
+	existing.Title = issue.Title
+	existing.Description = issue.Description
+	existing.Design = issue.Design
+	existing.AcceptanceCriteria = issue.AcceptanceCriteria
+	existing.Notes = issue.Notes
+	existing.UpdatedAt = now
+	delete(m.issues, oldID)
+	m.issues[newID] = existing
+
+	if existing.ExternalRef != nil && *existing.ExternalRef != "" {
+		m.externalRefToID[*existing.ExternalRef] = newID
+	}
+
+	if deps, ok := m.dependencies[oldID]; ok {
+		delete(m.dependencies, oldID)
+		m.dependencies[newID] = deps
+	}
+	for _, deps := range m.dependencies {
+		for _, dep := range deps {
+			if dep.IssueID == oldID {
+				dep.IssueID = newID
+			}
+			if dep.DependsOnID == oldID {
+				dep.DependsOnID = newID
+			}
+		}
+	}
+
+	if labels, ok := m.labels[oldID]; ok {
+		delete(m.labels, oldID)
+		m.labels[newID] = labels
+	}
+	if comments, ok := m.comments[oldID]; ok {
+		delete(m.comments, oldID)
+		for _, c := range comments {
+			c.IssueID = newID
+		}
+		m.comments[newID] = comments
+	}
+	if attachments, ok := m.attachments[oldID]; ok {
+		delete(m.attachments, oldID)
+		for _, a := range attachments {
+			a.IssueID = newID
+		}
+		m.attachments[newID] = attachments
+	}
+	if events, ok := m.events[oldID]; ok {
+		delete(m.events, oldID)
+		for _, e := range events {
+			e.IssueID = newID
+		}
+		m.events[newID] = events
+	}
+	if hash, ok := m.exportHashes[oldID]; ok {
+		delete(m.exportHashes, oldID)
+		m.exportHashes[newID] = hash
+	}
+	if counter, ok := m.counters[oldID]; ok {
+		delete(m.counters, oldID)
+		m.counters[newID] = counter
+	}
+
+	delete(m.dirty, oldID)
+	m.dirty[newID] = true
+
+	oldValue, newValue := oldID, newID
+	m.events[newID] = append(m.events[newID], &types.Event{
+		IssueID:   newID,
+		EventType: "renamed",
+		Actor:     actor,
+		OldValue:  &oldValue,
+		NewValue:  &newValue,
+		CreatedAt: now,
+		CommandID: storage.CommandID(ctx),
+		SourceOp:  storage.SourceOp(ctx),
+	})
+
+	return nil
 }
 
+// RenameDependencyPrefix rewrites oldPrefix to newPrefix on both ends of
+// every dependency record.
 func (m *MemoryStorage) RenameDependencyPrefix(ctx context.Context, oldPrefix, newPrefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	renamed := make(map[string][]*types.Dependency, len(m.dependencies))
+	for issueID, deps := range m.dependencies {
+		for _, dep := range deps {
+			if strings.HasPrefix(dep.IssueID, oldPrefix) {
+				dep.IssueID = newPrefix + dep.IssueID[len(oldPrefix):]
+			}
+			if strings.HasPrefix(dep.DependsOnID, oldPrefix) {
+				dep.DependsOnID = newPrefix + dep.DependsOnID[len(oldPrefix):]
+			}
+		}
+		if strings.HasPrefix(issueID, oldPrefix) {
+			issueID = newPrefix + issueID[len(oldPrefix):]
+		}
+		renamed[issueID] = append(renamed[issueID], deps...)
+	}
+	m.dependencies = renamed
+
 	return nil
 }
 
@@ -1783,13 +2907,143 @@ func (m *MemoryStorage) UnderlyingConn(ctx context.Context) (*sql.Conn, error) {
 }
 
 // RunInTransaction executes a function within a transaction context.
-// For MemoryStorage, this provides basic atomicity via mutex locking.
-// If the function returns an error, changes are NOT automatically rolled back
-// since MemoryStorage doesn't support true transaction rollback.
-//
-// Note: For full rollback support, callers should use SQLite storage.
+// Writes are applied to the store as they happen, so reads inside fn see
+// them. If fn returns an error or panics, the state captured before fn ran
+// is restored and the panic is re-raised. Transactions are serialized with
+// each other but not isolated from writers outside a transaction.
 func (m *MemoryStorage) RunInTransaction(ctx context.Context, fn func(tx storage.Transaction) error) error {
-	return fmt.Errorf("RunInTransaction not supported in --no-db mode: use SQLite storage for transaction support")
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+
+	snap := m.snapshot()
+	defer func() {
+		if r := recover(); r != nil {
+			m.restore(snap)
+			panic(r)
+		}
+	}()
+
+	if err := fn(m); err != nil {
+		m.restore(snap)
+		return err
+	}
+	return nil
+}
+
+// snapshot is a deep copy of all mutable state, used for transaction rollback.
+type snapshot struct {
+	issues          map[string]types.Issue
+	dependencies    map[string][]types.Dependency
+	labels          map[string][]string
+	events          map[string][]types.Event
+	comments        map[string][]types.Comment
+	attachments     map[string][]types.Attachment
+	config          map[string]string
+	metadata        map[string]string
+	counters        map[string]int
+	externalRefToID map[string]string
+	dirty           map[string]bool
+	exportHashes    map[string]string
+	commentSeq      int64
+}
+
+func (m *MemoryStorage) snapshot() *snapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snap := &snapshot{
+		issues:          make(map[string]types.Issue, len(m.issues)),
+		dependencies:    make(map[string][]types.Dependency, len(m.dependencies)),
+		labels:          make(map[string][]string, len(m.labels)),
+		events:          make(map[string][]types.Event, len(m.events)),
+		comments:        make(map[string][]types.Comment, len(m.comments)),
+		attachments:     make(map[string][]types.Attachment, len(m.attachments)),
+		config:          maps.Clone(m.config),
+		metadata:        maps.Clone(m.metadata),
+		counters:        maps.Clone(m.counters),
+		externalRefToID: maps.Clone(m.externalRefToID),
+		dirty:           maps.Clone(m.dirty),
+		exportHashes:    maps.Clone(m.exportHashes),
+		commentSeq:      m.commentSeq,
+	}
+	for id, issue := range m.issues {
+		snap.issues[id] = *issue
+	}
+	for id, deps := range m.dependencies {
+		for _, dep := range deps {
+			snap.dependencies[id] = append(snap.dependencies[id], *dep)
+		}
+	}
+	for id, labels := range m.labels {
+		snap.labels[id] = append([]string(nil), labels...)
+	}
+	for id, events := range m.events {
+		for _, e := range events {
+			snap.events[id] = append(snap.events[id], *e)
+		}
+	}
+	for id, comments := range m.comments {
+		for _, c := range comments {
+			snap.comments[id] = append(snap.comments[id], *c)
+		}
+	}
+	for id, attachments := range m.attachments {
+		for _, a := range attachments {
+			snap.attachments[id] = append(snap.attachments[id], *a)
+		}
+	}
+	return snap
+}
+
+// restore replaces all state with snap. Issues that existed before the
+// snapshot keep their pointer identity so callers' references stay valid.
+func (m *MemoryStorage) restore(snap *snapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	issues := make(map[string]*types.Issue, len(snap.issues))
+	for id, saved := range snap.issues {
+		issue := m.issues[id]
+		if issue == nil {
+			issue = new(types.Issue)
+		}
+		*issue = saved
+		issues[id] = issue
+	}
+	m.issues = issues
+
+	m.dependencies = make(map[string][]*types.Dependency, len(snap.dependencies))
+	for id, deps := range snap.dependencies {
+		for i := range deps {
+			m.dependencies[id] = append(m.dependencies[id], &deps[i])
+		}
+	}
+	m.labels = snap.labels
+	m.events = make(map[string][]*types.Event, len(snap.events))
+	for id, events := range snap.events {
+		for i := range events {
+			m.events[id] = append(m.events[id], &events[i])
+		}
+	}
+	m.comments = make(map[string][]*types.Comment, len(snap.comments))
+	for id, comments := range snap.comments {
+		for i := range comments {
+			m.comments[id] = append(m.comments[id], &comments[i])
+		}
+	}
+	m.attachments = make(map[string][]*types.Attachment, len(snap.attachments))
+	for id, attachments := range snap.attachments {
+		for i := range attachments {
+			m.attachments[id] = append(m.attachments[id], &attachments[i])
+		}
+	}
+	m.config = snap.config
+	m.metadata = snap.metadata
+	m.counters = snap.counters
+	m.externalRefToID = snap.externalRefToID
+	m.dirty = snap.dirty
+	m.exportHashes = snap.exportHashes
+	m.commentSeq = snap.commentSeq
 }
 
 // REMOVED (bd-c7af): SyncAllCounters - no longer needed with hash IDs
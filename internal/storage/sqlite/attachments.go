@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// AddAttachment records an attachment on an issue, replacing any existing
+// attachment with the same name.
+func (s *SQLiteStorage) AddAttachment(ctx context.Context, attachment *types.Attachment, actor string) error {
+	// Verify issue exists
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM issues WHERE id = ?)`, attachment.IssueID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check issue existence: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("issue %s not found", attachment.IssueID)
+	}
+
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = time.Now().UTC()
+	}
+	if attachment.CreatedBy == "" {
+		attachment.CreatedBy = actor
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO attachments (issue_id, name, size, sha256, mime_type, created_at, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (issue_id, name) DO UPDATE SET
+			size = excluded.size,
+			sha256 = excluded.sha256,
+			mime_type = excluded.mime_type,
+			created_at = excluded.created_at,
+			created_by = excluded.created_by
+	`, attachment.IssueID, attachment.Name, attachment.Size, attachment.SHA256,
+		attachment.MimeType, attachment.CreatedAt, attachment.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to insert attachment: %w", err)
+	}
+
+	// Mark issue as dirty for JSONL export
+	if err := s.MarkIssueDirty(ctx, attachment.IssueID); err != nil {
+		return fmt.Errorf("failed to mark issue dirty: %w", err)
+	}
+	return nil
+}
+
+// RemoveAttachment removes a named attachment from an issue. The blob itself
+// is left for garbage collection since other attachments may share it.
+func (s *SQLiteStorage) RemoveAttachment(ctx context.Context, issueID, name, actor string) error {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM attachments WHERE issue_id = ? AND name = ?
+	`, issueID, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove attachment: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("attachment %q not found on %s", name, issueID)
+	}
+
+	// Mark issue as dirty for JSONL export
+	if err := s.MarkIssueDirty(ctx, issueID); err != nil {
+		return fmt.Errorf("failed to mark issue dirty: %w", err)
+	}
+	return nil
+}
+
+// GetAttachments returns an issue's attachments ordered by name
+func (s *SQLiteStorage) GetAttachments(ctx context.Context, issueID string) ([]*types.Attachment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT issue_id, name, size, sha256, mime_type, created_at, created_by
+		FROM attachments
+		WHERE issue_id = ?
+		ORDER BY name
+	`, issueID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var attachments []*types.Attachment
+	for rows.Next() {
+		a := &types.Attachment{}
+		if err := rows.Scan(&a.IssueID, &a.Name, &a.Size, &a.SHA256, &a.MimeType, &a.CreatedAt, &a.CreatedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// GetAllAttachments returns every attachment keyed by issue ID, for export
+// and blob garbage collection
+func (s *SQLiteStorage) GetAllAttachments(ctx context.Context) (map[string][]*types.Attachment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT issue_id, name, size, sha256, mime_type, created_at, created_by
+		FROM attachments
+		ORDER BY issue_id, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	result := make(map[string][]*types.Attachment)
+	for rows.Next() {
+		a := &types.Attachment{}
+		if err := rows.Scan(&a.IssueID, &a.Name, &a.Size, &a.SHA256, &a.MimeType, &a.CreatedAt, &a.CreatedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		result[a.IssueID] = append(result[a.IssueID], a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+	return result, nil
+}
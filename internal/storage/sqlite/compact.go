@@ -312,9 +312,9 @@ func (s *SQLiteStorage) ApplyCompaction(ctx context.Context, issueID string, lev
 			level, originalSize, compressedSize, reductionPct)
 		
 		_, err = tx.ExecContext(ctx, `
-			INSERT INTO events (issue_id, event_type, actor, comment)
-			VALUES (?, ?, 'compactor', ?)
-		`, issueID, types.EventCompacted, eventData)
+			INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+			VALUES (?, ?, 'compactor', ?, ?, ?)
+		`, issueID, types.EventCompacted, eventData, eventCommandID(ctx), eventSourceOp(ctx))
 		
 		if err != nil {
 			return fmt.Errorf("failed to record compaction event: %w", err)
@@ -151,10 +151,10 @@ func (s *SQLiteStorage) AddDependency(ctx context.Context, dep *types.Dependency
 
 	// Record event
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, comment)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`, dep.IssueID, types.EventDependencyAdded, actor,
-		fmt.Sprintf("Added dependency: %s %s %s", dep.IssueID, dep.Type, dep.DependsOnID))
+		fmt.Sprintf("Added dependency: %s %s %s", dep.IssueID, dep.Type, dep.DependsOnID), eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -213,10 +213,10 @@ func (s *SQLiteStorage) RemoveDependency(ctx context.Context, issueID, dependsOn
 		}
 
 		_, err = tx.ExecContext(ctx, `
-			INSERT INTO events (issue_id, event_type, actor, comment)
-			VALUES (?, ?, ?, ?)
+			INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+			VALUES (?, ?, ?, ?, ?, ?)
 		`, issueID, types.EventDependencyRemoved, actor,
-			fmt.Sprintf("Removed dependency on %s", dependsOnID))
+			fmt.Sprintf("Removed dependency on %s", dependsOnID), eventCommandID(ctx), eventSourceOp(ctx))
 		if err != nil {
 			return fmt.Errorf("failed to record event: %w", err)
 		}
@@ -883,6 +883,12 @@ func (s *SQLiteStorage) scanIssues(ctx context.Context, rows *sql.Rows) ([]*type
 		var awaitID sql.NullString
 		var timeoutNs sql.NullInt64
 		var waiters sql.NullString
+		var budgetUSD sql.NullFloat64
+		// Event fields
+		var eventKind sql.NullString
+		var actor sql.NullString
+		var target sql.NullString
+		var payload sql.NullString
 
 		err := rows.Scan(
 			&issue.ID, &contentHash, &issue.Title, &issue.Description, &issue.Design,
@@ -891,7 +897,8 @@ func (s *SQLiteStorage) scanIssues(ctx context.Context, rows *sql.Rows) ([]*type
 			&issue.CreatedAt, &issue.CreatedBy, &issue.UpdatedAt, &closedAt, &externalRef, &sourceRepo, &closeReason,
 			&deletedAt, &deletedBy, &deleteReason, &originalType,
 			&sender, &wisp, &pinned, &isTemplate,
-			&awaitType, &awaitID, &timeoutNs, &waiters,
+			&awaitType, &awaitID, &timeoutNs, &waiters, &budgetUSD,
+			&eventKind, &actor, &target, &payload,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan issue: %w", err)
@@ -957,6 +964,22 @@ func (s *SQLiteStorage) scanIssues(ctx context.Context, rows *sql.Rows) ([]*type
 		if waiters.Valid && waiters.String != "" {
 			issue.Waiters = parseJSONStringArray(waiters.String)
 		}
+		if budgetUSD.Valid {
+			issue.BudgetUSD = &budgetUSD.Float64
+		}
+		// Event fields
+		if eventKind.Valid {
+			issue.EventKind = eventKind.String
+		}
+		if actor.Valid {
+			issue.Actor = actor.String
+		}
+		if target.Valid {
+			issue.Target = target.String
+		}
+		if payload.Valid {
+			issue.Payload = payload.String
+		}
 
 		issues = append(issues, &issue)
 		issueIDs = append(issueIDs, issue.ID)
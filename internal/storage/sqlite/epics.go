@@ -30,7 +30,7 @@ func (s *SQLiteStorage) GetEpicsEligibleForClosure(ctx context.Context) ([]*type
 		SELECT 
 			i.id, i.title, i.description, i.design, i.acceptance_criteria, i.notes,
 			i.status, i.priority, i.issue_type, i.assignee, i.estimated_minutes,
-			i.created_at, i.updated_at, i.closed_at, i.external_ref,
+			i.created_at, i.updated_at, i.closed_at, i.external_ref, i.budget_usd,
 			COALESCE(es.total_children, 0) AS total_children,
 			COALESCE(es.closed_children, 0) AS closed_children
 		FROM issues i
@@ -57,7 +57,7 @@ func (s *SQLiteStorage) GetEpicsEligibleForClosure(ctx context.Context) ([]*type
 			&epic.AcceptanceCriteria, &epic.Notes, &epic.Status,
 			&epic.Priority, &epic.IssueType, &assignee,
 			&epic.EstimatedMinutes, &epic.CreatedAt, &epic.UpdatedAt,
-			&epic.ClosedAt, &epic.ExternalRef,
+			&epic.ClosedAt, &epic.ExternalRef, &epic.BudgetUSD,
 			&totalChildren, &closedChildren,
 		)
 		if err != nil {
@@ -32,9 +32,9 @@ func (s *SQLiteStorage) AddComment(ctx context.Context, issueID, actor, comment
 		}
 
 		_, err = tx.ExecContext(ctx, `
-			INSERT INTO events (issue_id, event_type, actor, comment)
-			VALUES (?, ?, ?, ?)
-		`, issueID, types.EventCommented, actor, comment)
+			INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, issueID, types.EventCommented, actor, comment, eventCommandID(ctx), eventSourceOp(ctx))
 		if err != nil {
 			return fmt.Errorf("failed to add comment: %w", err)
 		}
@@ -64,7 +64,7 @@ func (s *SQLiteStorage) GetEvents(ctx context.Context, issueID string, limit int
 
 	// #nosec G201 - safe SQL with controlled formatting
 	query := fmt.Sprintf(`
-		SELECT id, issue_id, event_type, actor, old_value, new_value, comment, created_at
+		SELECT id, issue_id, event_type, actor, old_value, new_value, comment, created_at, command_id, source_op
 		FROM events
 		WHERE issue_id = ?
 		ORDER BY created_at DESC
@@ -80,11 +80,11 @@ func (s *SQLiteStorage) GetEvents(ctx context.Context, issueID string, limit int
 	var events []*types.Event
 	for rows.Next() {
 		var event types.Event
-		var oldValue, newValue, comment sql.NullString
+		var oldValue, newValue, comment, commandID, sourceOp sql.NullString
 
 		err := rows.Scan(
 			&event.ID, &event.IssueID, &event.EventType, &event.Actor,
-			&oldValue, &newValue, &comment, &event.CreatedAt,
+			&oldValue, &newValue, &comment, &event.CreatedAt, &commandID, &sourceOp,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan event: %w", err)
@@ -99,6 +99,8 @@ func (s *SQLiteStorage) GetEvents(ctx context.Context, issueID string, limit int
 		if comment.Valid {
 			event.Comment = &comment.String
 		}
+		event.CommandID = commandID.String
+		event.SourceOp = sourceOp.String
 
 		events = append(events, &event)
 	}
@@ -106,6 +108,62 @@ func (s *SQLiteStorage) GetEvents(ctx context.Context, issueID string, limit int
 	return events, nil
 }
 
+// GetRecentEvents returns the most recent events across all issues, newest
+// first. Events are ordered by insertion since created_at has one-second
+// resolution.
+func (s *SQLiteStorage) GetRecentEvents(ctx context.Context, limit int) ([]*types.Event, error) {
+	var args []interface{}
+	limitSQL := ""
+	if limit > 0 {
+		limitSQL = limitClause
+		args = append(args, limit)
+	}
+
+	// #nosec G201 - safe SQL with controlled formatting
+	query := fmt.Sprintf(`
+		SELECT id, issue_id, event_type, actor, old_value, new_value, comment, created_at, command_id, source_op
+		FROM events
+		ORDER BY id DESC
+		%s
+	`, limitSQL)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var events []*types.Event
+	for rows.Next() {
+		var event types.Event
+		var oldValue, newValue, comment, commandID, sourceOp sql.NullString
+
+		err := rows.Scan(
+			&event.ID, &event.IssueID, &event.EventType, &event.Actor,
+			&oldValue, &newValue, &comment, &event.CreatedAt, &commandID, &sourceOp,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+
+		if oldValue.Valid {
+			event.OldValue = &oldValue.String
+		}
+		if newValue.Valid {
+			event.NewValue = &newValue.String
+		}
+		if comment.Valid {
+			event.Comment = &comment.String
+		}
+		event.CommandID = commandID.String
+		event.SourceOp = sourceOp.String
+
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
 // GetStatistics returns aggregate statistics
 func (s *SQLiteStorage) GetStatistics(ctx context.Context) (*types.Statistics, error) {
 	var stats types.Statistics
@@ -6,9 +6,28 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/steveyegge/beads/internal/storage"
 	"github.com/steveyegge/beads/internal/types"
 )
 
+// eventCommandID returns the invocation ID to record on events written with
+// ctx, or nil (NULL) when the caller did not set one.
+func eventCommandID(ctx context.Context) interface{} {
+	if id := storage.CommandID(ctx); id != "" {
+		return id
+	}
+	return nil
+}
+
+// eventSourceOp returns the VCS operation to record on events written with
+// ctx, or nil (NULL) when the write did not come from one.
+func eventSourceOp(ctx context.Context) interface{} {
+	if id := storage.SourceOp(ctx); id != "" {
+		return id
+	}
+	return nil
+}
+
 // recordCreatedEvent records a single creation event for an issue
 func recordCreatedEvent(ctx context.Context, conn *sql.Conn, issue *types.Issue, actor string) error {
 	eventData, err := json.Marshal(issue)
@@ -19,9 +38,9 @@ func recordCreatedEvent(ctx context.Context, conn *sql.Conn, issue *types.Issue,
 	eventDataStr := string(eventData)
 	
 	_, err = conn.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, new_value)
-		VALUES (?, ?, ?, ?)
-	`, issue.ID, types.EventCreated, actor, eventDataStr)
+		INSERT INTO events (issue_id, event_type, actor, new_value, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, issue.ID, types.EventCreated, actor, eventDataStr, eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -31,14 +50,15 @@ func recordCreatedEvent(ctx context.Context, conn *sql.Conn, issue *types.Issue,
 // recordCreatedEvents bulk records creation events for multiple issues
 func recordCreatedEvents(ctx context.Context, conn *sql.Conn, issues []*types.Issue, actor string) error {
 	stmt, err := conn.PrepareContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, new_value)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO events (issue_id, event_type, actor, new_value, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare event statement: %w", err)
 	}
 	defer func() { _ = stmt.Close() }()
 
+	commandID, sourceOp := eventCommandID(ctx), eventSourceOp(ctx)
 	for _, issue := range issues {
 		eventData, err := json.Marshal(issue)
 		if err != nil {
@@ -46,7 +66,7 @@ func recordCreatedEvents(ctx context.Context, conn *sql.Conn, issues []*types.Is
 			eventData = []byte(fmt.Sprintf(`{"id":"%s","title":"%s"}`, issue.ID, issue.Title))
 		}
 
-		_, err = stmt.ExecContext(ctx, issue.ID, types.EventCreated, actor, string(eventData))
+		_, err = stmt.ExecContext(ctx, issue.ID, types.EventCreated, actor, string(eventData), commandID, sourceOp)
 		if err != nil {
 			return fmt.Errorf("failed to record event for %s: %w", issue.ID, err)
 		}
@@ -193,6 +193,56 @@ func TestGetEventsEmpty(t *testing.T) {
 	}
 }
 
+func TestGetRecentEvents(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	var ids []string
+	for _, title := range []string{"First", "Second"} {
+		issue := &types.Issue{Title: title, Status: types.StatusOpen, Priority: 1, IssueType: types.TypeTask}
+		if err := store.CreateIssue(ctx, issue, "test-user"); err != nil {
+			t.Fatalf("CreateIssue failed: %v", err)
+		}
+		ids = append(ids, issue.ID)
+	}
+	if err := store.AddComment(ctx, ids[0], testUserAlice, "Comment"); err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+
+	events, err := store.GetRecentEvents(ctx, 0)
+	if err != nil {
+		t.Fatalf("GetRecentEvents failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+
+	// Newest first across issues, even within the same second
+	want := []struct {
+		issueID   string
+		eventType types.EventType
+	}{
+		{ids[0], types.EventCommented},
+		{ids[1], types.EventCreated},
+		{ids[0], types.EventCreated},
+	}
+	for i, w := range want {
+		if events[i].IssueID != w.issueID || events[i].EventType != w.eventType {
+			t.Errorf("event %d = %s/%s, want %s/%s", i, events[i].IssueID, events[i].EventType, w.issueID, w.eventType)
+		}
+	}
+
+	limited, err := store.GetRecentEvents(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetRecentEvents failed: %v", err)
+	}
+	if len(limited) != 2 {
+		t.Errorf("Expected 2 events with limit, got %d", len(limited))
+	}
+}
+
 func TestAddCommentMarksDirty(t *testing.T) {
 	store, cleanup := setupTestDB(t)
 	defer cleanup()
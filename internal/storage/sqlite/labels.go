@@ -35,9 +35,9 @@ func (s *SQLiteStorage) executeLabelOperation(
 		}
 
 		_, err = tx.ExecContext(ctx, `
-			INSERT INTO events (issue_id, event_type, actor, comment)
-			VALUES (?, ?, ?, ?)
-		`, issueID, eventType, actor, eventComment)
+			INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, issueID, eventType, actor, eventComment, eventCommandID(ctx), eventSourceOp(ctx))
 		if err != nil {
 			return fmt.Errorf("failed to record event: %w", err)
 		}
@@ -160,7 +160,8 @@ func (s *SQLiteStorage) GetIssuesByLabel(ctx context.Context, label string) ([]*
 		       i.created_at, i.created_by, i.updated_at, i.closed_at, i.external_ref, i.source_repo, i.close_reason,
 		       i.deleted_at, i.deleted_by, i.delete_reason, i.original_type,
 		       i.sender, i.ephemeral, i.pinned, i.is_template,
-		       i.await_type, i.await_id, i.timeout_ns, i.waiters
+		       i.await_type, i.await_id, i.timeout_ns, i.waiters, i.budget_usd,
+		       i.event_kind, i.actor, i.target, i.payload
 		FROM issues i
 		JOIN labels l ON i.id = l.issue_id
 		WHERE l.label = ?
@@ -173,3 +174,30 @@ func (s *SQLiteStorage) GetIssuesByLabel(ctx context.Context, label string) ([]*
 
 	return s.scanIssues(ctx, rows)
 }
+
+// stateFilterClauses builds the WHERE clauses for state dimension filters.
+// "Any value" matches use a range over the dimension's label prefix so the
+// label index applies: every "<dim>:..." label sorts between "<dim>:" and
+// "<dim>;".
+func stateFilterClauses(filters []types.StateFilter) ([]string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	for _, f := range filters {
+		prefix := f.Dimension + ":"
+		upper := f.Dimension + ";"
+		var clause string
+		if f.Value != "" {
+			clause = "id IN (SELECT issue_id FROM labels WHERE label = ?)"
+			args = append(args, f.Label())
+		} else {
+			clause = "id IN (SELECT issue_id FROM labels WHERE label >= ? AND label < ?)"
+			args = append(args, prefix, upper)
+		}
+		if f.IncludeUnset {
+			clause = "(" + clause + " OR id NOT IN (SELECT issue_id FROM labels WHERE label >= ? AND label < ?))"
+			args = append(args, prefix, upper)
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, args
+}
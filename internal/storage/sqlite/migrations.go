@@ -52,6 +52,10 @@ var migrationsList = []Migration{
 	{"event_fields", migrations.MigrateEventFields},
 	{"closed_by_session_column", migrations.MigrateClosedBySessionColumn},
 	{"due_defer_columns", migrations.MigrateDueDeferColumns},
+	{"attachments_table", migrations.MigrateAttachmentsTable},
+	{"budget_column", migrations.MigrateBudgetColumn},
+	{"event_command_id", migrations.MigrateEventCommandID},
+	{"event_source_op", migrations.MigrateEventSourceOp},
 }
 
 // MigrationInfo contains metadata about a migration for inspection
@@ -111,6 +115,10 @@ func getMigrationDescription(name string) string {
 		"event_fields":                 "Adds event fields (event_kind, actor, target, payload) for operational state change beads",
 		"closed_by_session_column":     "Adds closed_by_session column for tracking which Claude Code session closed an issue",
 		"due_defer_columns":            "Adds due_at and defer_until columns for time-based task scheduling (GH#820)",
+		"attachments_table":            "Adds attachments table for files attached to issues (content in .beads/blobs/)",
+		"budget_column":                "Adds budget_usd column for per-issue spend limits",
+		"event_command_id":             "Adds command_id column to events to group the changes made by one bd command",
+		"event_source_op":              "Adds source_op column to events for the VCS operation an import applied",
 	}
 
 	if desc, ok := descriptions[name]; ok {
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MigrateAttachmentsTable creates the attachments table. Rows hold only the
+// metadata of a file attached to an issue; the content is stored by SHA256 in
+// the .beads/blobs/ directory.
+func MigrateAttachmentsTable(db *sql.DB) error {
+	var tableName string
+	err := db.QueryRow(`
+		SELECT name FROM sqlite_master
+		WHERE type='table' AND name='attachments'
+	`).Scan(&tableName)
+
+	if err == sql.ErrNoRows {
+		_, err := db.Exec(`
+			CREATE TABLE attachments (
+				issue_id TEXT NOT NULL,
+				name TEXT NOT NULL,
+				size INTEGER NOT NULL DEFAULT 0,
+				sha256 TEXT NOT NULL,
+				mime_type TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				created_by TEXT NOT NULL DEFAULT '',
+				PRIMARY KEY (issue_id, name),
+				FOREIGN KEY (issue_id) REFERENCES issues(id) ON DELETE CASCADE
+			)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to create attachments table: %w", err)
+		}
+		if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_attachments_sha256 ON attachments(sha256)`); err != nil {
+			return fmt.Errorf("failed to create attachments sha256 index: %w", err)
+		}
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to check for attachments table: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MigrateBudgetColumn adds the budget_usd column to the issues table.
+// It holds the spend limit for an issue (usually an epic) and its
+// descendants; usage itself lives in the audit log.
+func MigrateBudgetColumn(db *sql.DB) error {
+	var exists bool
+	err := db.QueryRow(`
+		SELECT COUNT(*) > 0
+		FROM pragma_table_info('issues')
+		WHERE name = 'budget_usd'
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check budget_usd column: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := db.Exec(`ALTER TABLE issues ADD COLUMN budget_usd REAL`); err != nil {
+		return fmt.Errorf("failed to add budget_usd column: %w", err)
+	}
+	return nil
+}
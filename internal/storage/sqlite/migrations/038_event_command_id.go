@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MigrateEventCommandID adds the command_id column to the events table.
+// Every event written by one bd invocation carries the same ID, so undo can
+// tell where one command's changes end and the next one's begin.
+func MigrateEventCommandID(db *sql.DB) error {
+	var exists bool
+	err := db.QueryRow(`
+		SELECT COUNT(*) > 0
+		FROM pragma_table_info('events')
+		WHERE name = 'command_id'
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check command_id column: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := db.Exec(`ALTER TABLE events ADD COLUMN command_id TEXT`); err != nil {
+		return fmt.Errorf("failed to add command_id column: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MigrateEventSourceOp adds the source_op column to the events table. It
+// records the VCS operation (e.g. a jj fetch) whose changes an import
+// applied, keeping the actor column for who made the change.
+func MigrateEventSourceOp(db *sql.DB) error {
+	var exists bool
+	err := db.QueryRow(`
+		SELECT COUNT(*) > 0
+		FROM pragma_table_info('events')
+		WHERE name = 'source_op'
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check source_op column: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := db.Exec(`ALTER TABLE events ADD COLUMN source_op TEXT`); err != nil {
+		return fmt.Errorf("failed to add source_op column: %w", err)
+	}
+	return nil
+}
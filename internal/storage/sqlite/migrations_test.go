@@ -509,9 +509,10 @@ func TestMigrateContentHashColumn(t *testing.T) {
 				payload TEXT DEFAULT '',
 				due_at DATETIME,
 				defer_until DATETIME,
+				budget_usd REAL,
 				CHECK ((status = 'closed') = (closed_at IS NOT NULL))
 			);
-			INSERT INTO issues SELECT id, title, description, design, acceptance_criteria, notes, status, priority, issue_type, assignee, estimated_minutes, created_at, '', updated_at, closed_at, external_ref, compaction_level, compacted_at, original_size, compacted_at_commit, source_repo, '', NULL, '', '', '', '', 0, 0, 0, '', '', '', '', '', '', 0, '', '', '', '', NULL, '', '', '', '', '', '', '', NULL, NULL FROM issues_backup;
+			INSERT INTO issues SELECT id, title, description, design, acceptance_criteria, notes, status, priority, issue_type, assignee, estimated_minutes, created_at, '', updated_at, closed_at, external_ref, compaction_level, compacted_at, original_size, compacted_at_commit, source_repo, '', NULL, '', '', '', '', 0, 0, 0, '', '', '', '', '', '', 0, '', '', '', '', NULL, '', '', '', '', '', '', '', NULL, NULL, NULL FROM issues_backup;
 			DROP TABLE issues_backup;
 		`)
 		if err != nil {
@@ -726,3 +727,61 @@ func TestMigrateOrphanDetection(t *testing.T) {
 		}
 	})
 }
+
+func TestMigrateAttachmentsTable(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+	db := store.db
+
+	// Simulate a database created before attachments existed
+	if _, err := db.Exec("DROP TABLE IF EXISTS attachments"); err != nil {
+		t.Fatalf("failed to drop attachments: %v", err)
+	}
+
+	// Running twice must be a no-op the second time
+	for i := 0; i < 2; i++ {
+		if err := migrations.MigrateAttachmentsTable(db); err != nil {
+			t.Fatalf("failed to migrate attachments table (run %d): %v", i+1, err)
+		}
+	}
+
+	var columns int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('attachments')`).Scan(&columns); err != nil {
+		t.Fatalf("failed to inspect attachments: %v", err)
+	}
+	if columns != 7 {
+		t.Errorf("attachments has %d columns, want 7", columns)
+	}
+}
+
+func TestMigrateBudgetColumn(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Running again must be a no-op
+	if err := migrations.MigrateBudgetColumn(store.db); err != nil {
+		t.Fatalf("failed to migrate budget column: %v", err)
+	}
+
+	budget := 12.5
+	issue := &types.Issue{Title: "epic", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeEpic, BudgetUSD: &budget}
+	if err := store.CreateIssue(ctx, issue, "test"); err != nil {
+		t.Fatalf("CreateIssue: %v", err)
+	}
+	got, err := store.GetIssue(ctx, issue.ID)
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if got.BudgetUSD == nil || *got.BudgetUSD != budget {
+		t.Fatalf("budget = %v, want %v", got.BudgetUSD, budget)
+	}
+
+	if err := store.UpdateIssue(ctx, issue.ID, map[string]interface{}{"budget_usd": nil}, "test"); err != nil {
+		t.Fatalf("UpdateIssue: %v", err)
+	}
+	got, _ = store.GetIssue(ctx, issue.ID)
+	if got.BudgetUSD != nil {
+		t.Errorf("budget = %v after clearing, want nil", *got.BudgetUSD)
+	}
+}
@@ -413,6 +413,17 @@ func (s *SQLiteStorage) upsertIssueInTx(ctx context.Context, tx *sql.Tx, issue *
 		}
 	}
 
+	// Import attachment references if present
+	for _, a := range issue.Attachments {
+		_, err = tx.ExecContext(ctx, `
+			INSERT OR REPLACE INTO attachments (issue_id, name, size, sha256, mime_type, created_at, created_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, issue.ID, a.Name, a.Size, a.SHA256, a.MimeType, a.CreatedAt, a.CreatedBy)
+		if err != nil {
+			return fmt.Errorf("failed to import attachment: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -477,6 +488,14 @@ func (s *SQLiteStorage) DeleteIssuesBySourceRepo(ctx context.Context, sourceRepo
 		}
 	}
 
+	// Delete attachment references for all affected issues
+	for _, id := range issueIDs {
+		_, err = tx.ExecContext(ctx, `DELETE FROM attachments WHERE issue_id = ?`, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete attachments for %s: %w", id, err)
+		}
+	}
+
 	// Delete labels for all affected issues
 	for _, id := range issueIDs {
 		_, err = tx.ExecContext(ctx, `DELETE FROM labels WHERE issue_id = ?`, id)
@@ -50,6 +50,15 @@ func (s *SQLiteStorage) ExportToMultiRepo(ctx context.Context) (map[string]int,
 		issue.Labels = labels
 	}
 
+	// Populate attachment references for all issues
+	allAttachments, err := s.GetAllAttachments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	for _, issue := range allIssues {
+		issue.Attachments = allAttachments[issue.ID]
+	}
+
 	// Filter out wisps - they should never be exported to JSONL (bd-687g)
 	// Wisps exist only in SQLite and are shared via .beads/redirect, not JSONL.
 	filtered := make([]*types.Issue, 0, len(allIssues))
@@ -293,6 +293,8 @@ func (s *SQLiteStorage) GetIssue(ctx context.Context, id string) (*types.Issue,
 	// Time-based scheduling fields (GH#820)
 	var dueAt sql.NullTime
 	var deferUntil sql.NullTime
+	// Spend limit
+	var budgetUSD sql.NullFloat64
 
 	var contentHash sql.NullString
 	var compactedAtCommit sql.NullString
@@ -306,7 +308,7 @@ func (s *SQLiteStorage) GetIssue(ctx context.Context, id string) (*types.Issue,
 		       await_type, await_id, timeout_ns, waiters,
 		       hook_bead, role_bead, agent_state, last_activity, role_type, rig, mol_type,
 		       event_kind, actor, target, payload,
-		       due_at, defer_until
+		       due_at, defer_until, budget_usd
 		FROM issues
 		WHERE id = ?
 	`, id).Scan(
@@ -320,7 +322,7 @@ func (s *SQLiteStorage) GetIssue(ctx context.Context, id string) (*types.Issue,
 		&awaitType, &awaitID, &timeoutNs, &waiters,
 		&hookBead, &roleBead, &agentState, &lastActivity, &roleType, &rig, &molType,
 		&eventKind, &actor, &target, &payload,
-		&dueAt, &deferUntil,
+		&dueAt, &deferUntil, &budgetUSD,
 	)
 
 	if err == sql.ErrNoRows {
@@ -442,6 +444,9 @@ func (s *SQLiteStorage) GetIssue(ctx context.Context, id string) (*types.Issue,
 	if deferUntil.Valid {
 		issue.DeferUntil = &deferUntil.Time
 	}
+	if budgetUSD.Valid {
+		issue.BudgetUSD = &budgetUSD.Float64
+	}
 
 	// Fetch labels for this issue
 	labels, err := s.GetLabels(ctx, issue.ID)
@@ -706,6 +711,8 @@ var allowedUpdateFields = map[string]bool{
 	// Time-based scheduling fields (GH#820)
 	"due_at":      true,
 	"defer_until": true,
+	// Spend limit, checked against audit log usage
+	"budget_usd": true,
 	// Gate fields (bd-z6kw: support await_id updates for gate discovery)
 	"await_id": true,
 }
@@ -923,9 +930,9 @@ func (s *SQLiteStorage) UpdateIssue(ctx context.Context, id string, updates map[
 	eventType := determineEventType(oldIssue, updates)
 
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, old_value, new_value)
-		VALUES (?, ?, ?, ?, ?)
-	`, id, eventType, actor, oldDataStr, newDataStr)
+		INSERT INTO events (issue_id, event_type, actor, old_value, new_value, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, eventType, actor, oldDataStr, newDataStr, eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -1016,6 +1023,11 @@ func (s *SQLiteStorage) UpdateIssueID(ctx context.Context, oldID, newID string,
 		return fmt.Errorf("failed to update comments: %w", err)
 	}
 
+	_, err = tx.ExecContext(ctx, `UPDATE attachments SET issue_id = ? WHERE issue_id = ?`, newID, oldID)
+	if err != nil {
+		return fmt.Errorf("failed to update attachments: %w", err)
+	}
+
 	_, err = tx.ExecContext(ctx, `
 		UPDATE dirty_issues SET issue_id = ? WHERE issue_id = ?
 	`, newID, oldID)
@@ -1043,13 +1055,18 @@ func (s *SQLiteStorage) UpdateIssueID(ctx context.Context, oldID, newID string,
 	}
 
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, old_value, new_value)
-		VALUES (?, 'renamed', ?, ?, ?)
-	`, newID, actor, oldID, newID)
+		INSERT INTO events (issue_id, event_type, actor, old_value, new_value, command_id, source_op)
+		VALUES (?, 'renamed', ?, ?, ?, ?, ?)
+	`, newID, actor, oldID, newID, eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record rename event: %w", err)
 	}
 
+	// Invalidate blocked issues cache since it is keyed by issue ID
+	if err := s.invalidateBlockedCache(ctx, tx); err != nil {
+		return fmt.Errorf("failed to invalidate blocked cache: %w", err)
+	}
+
 	return tx.Commit()
 }
 
@@ -1126,9 +1143,9 @@ func (s *SQLiteStorage) CloseIssue(ctx context.Context, id string, reason string
 	}
 
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, comment)
-		VALUES (?, ?, ?, ?)
-	`, id, types.EventClosed, actor, reason)
+		INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, id, types.EventClosed, actor, reason, eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -1208,9 +1225,9 @@ func (s *SQLiteStorage) CloseIssue(ctx context.Context, id string, reason string
 
 			// Record the close event
 			_, err = tx.ExecContext(ctx, `
-				INSERT INTO events (issue_id, event_type, actor, comment)
-				VALUES (?, ?, ?, ?)
-			`, convoyID, types.EventClosed, "system:convoy-completion", closeReason)
+				INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, convoyID, types.EventClosed, "system:convoy-completion", closeReason, eventCommandID(ctx), eventSourceOp(ctx))
 			if err != nil {
 				return fmt.Errorf("failed to record convoy close event: %w", err)
 			}
@@ -1273,9 +1290,9 @@ func (s *SQLiteStorage) CreateTombstone(ctx context.Context, id string, actor st
 
 	// Record tombstone creation event
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, comment)
-		VALUES (?, ?, ?, ?)
-	`, id, "deleted", actor, reason)
+		INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, id, "deleted", actor, reason, eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record tombstone event: %w", err)
 	}
@@ -1303,6 +1320,78 @@ func (s *SQLiteStorage) CreateTombstone(ctx context.Context, id string, actor st
 	return nil
 }
 
+// RestoreTombstone turns a tombstone back into a live issue with the given
+// status, restoring the issue type saved when it was deleted. Dependencies
+// removed by the delete are not restored.
+func (s *SQLiteStorage) RestoreTombstone(ctx context.Context, id string, status types.Status, actor string) error {
+	if status == types.StatusTombstone || status == "" {
+		return fmt.Errorf("invalid status for restored issue: %q", status)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	var closedAt interface{}
+	if status == types.StatusClosed {
+		closedAt = now
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE issues
+		SET status = ?,
+		    closed_at = ?,
+		    issue_type = CASE WHEN COALESCE(original_type, '') = '' THEN issue_type ELSE original_type END,
+		    deleted_at = NULL,
+		    deleted_by = '',
+		    delete_reason = '',
+		    original_type = '',
+		    updated_at = ?
+		WHERE id = ? AND status = ?
+	`, status, closedAt, now, id, types.StatusTombstone)
+	if err != nil {
+		return fmt.Errorf("failed to restore tombstone: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("issue %s is not a tombstone", id)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO events (issue_id, event_type, actor, old_value, new_value, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, "restored", actor, string(types.StatusTombstone), string(status), eventCommandID(ctx), eventSourceOp(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to record restore event: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO dirty_issues (issue_id, marked_at)
+		VALUES (?, ?)
+		ON CONFLICT (issue_id) DO UPDATE SET marked_at = excluded.marked_at
+	`, id, now)
+	if err != nil {
+		return fmt.Errorf("failed to mark issue dirty: %w", err)
+	}
+
+	// The restored issue can block others again
+	if err := s.invalidateBlockedCache(ctx, tx); err != nil {
+		return fmt.Errorf("failed to invalidate blocked cache: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return wrapDBError("commit restore transaction", err)
+	}
+
+	return nil
+}
+
 // DeleteIssue permanently removes an issue from the database
 func (s *SQLiteStorage) DeleteIssue(ctx context.Context, id string) error {
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -1329,6 +1418,12 @@ func (s *SQLiteStorage) DeleteIssue(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to delete comments: %w", err)
 	}
 
+	// Delete attachment references (blobs are collected by bd compact)
+	_, err = tx.ExecContext(ctx, `DELETE FROM attachments WHERE issue_id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachments: %w", err)
+	}
+
 	// Delete from dirty_issues
 	_, err = tx.ExecContext(ctx, `DELETE FROM dirty_issues WHERE issue_id = ?`, id)
 	if err != nil {
@@ -1349,6 +1444,11 @@ func (s *SQLiteStorage) DeleteIssue(ctx context.Context, id string) error {
 		return fmt.Errorf("issue not found: %s", id)
 	}
 
+	// Invalidate blocked issues cache since the deleted issue may have been a blocker
+	if err := s.invalidateBlockedCache(ctx, tx); err != nil {
+		return fmt.Errorf("failed to invalidate blocked cache: %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return wrapDBError("commit delete transaction", err)
 	}
@@ -1617,9 +1717,9 @@ func (s *SQLiteStorage) executeDelete(ctx context.Context, tx *sql.Tx, inClause
 
 		// Record tombstone creation event
 		_, err = tx.ExecContext(ctx, `
-			INSERT INTO events (issue_id, event_type, actor, comment)
-			VALUES (?, ?, ?, ?)
-		`, id, "deleted", "batch delete", "batch delete")
+			INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, id, "deleted", "batch delete", "batch delete", eventCommandID(ctx), eventSourceOp(ctx))
 		if err != nil {
 			return fmt.Errorf("failed to record tombstone event for %s: %w", id, err)
 		}
@@ -1831,6 +1931,13 @@ func (s *SQLiteStorage) SearchIssues(ctx context.Context, query string, filter t
 		whereClauses = append(whereClauses, fmt.Sprintf("id IN (SELECT issue_id FROM labels WHERE label IN (%s))", strings.Join(placeholders, ", ")))
 	}
 
+	// State dimension filtering
+	if len(filter.States) > 0 {
+		stateClauses, stateArgs := stateFilterClauses(filter.States)
+		whereClauses = append(whereClauses, stateClauses...)
+		args = append(args, stateArgs...)
+	}
+
 	// ID filtering: match specific issue IDs
 	if len(filter.IDs) > 0 {
 		placeholders := make([]string, len(filter.IDs))
@@ -1929,7 +2036,8 @@ func (s *SQLiteStorage) SearchIssues(ctx context.Context, query string, filter t
 		       created_at, created_by, updated_at, closed_at, external_ref, source_repo, close_reason,
 		       deleted_at, deleted_by, delete_reason, original_type,
 		       sender, ephemeral, pinned, is_template,
-		       await_type, await_id, timeout_ns, waiters
+		       await_type, await_id, timeout_ns, waiters, budget_usd,
+		       event_kind, actor, target, payload
 		FROM issues
 		%s
 		ORDER BY priority ASC, created_at DESC
@@ -155,7 +155,8 @@ func (s *SQLiteStorage) GetReadyWork(ctx context.Context, filter types.WorkFilte
 		i.created_at, i.created_by, i.updated_at, i.closed_at, i.external_ref, i.source_repo, i.close_reason,
 		i.deleted_at, i.deleted_by, i.delete_reason, i.original_type,
 		i.sender, i.ephemeral, i.pinned, i.is_template,
-		i.await_type, i.await_id, i.timeout_ns, i.waiters
+		i.await_type, i.await_id, i.timeout_ns, i.waiters, i.budget_usd,
+		i.event_kind, i.actor, i.target, i.payload
 		FROM issues i
 		WHERE %s
 		AND NOT EXISTS (
@@ -604,16 +605,17 @@ func (s *SQLiteStorage) GetBlockedIssues(ctx context.Context, filter types.WorkF
 		blocked = append(blocked, &issue)
 	}
 
-	// Filter out satisfied external dependencies from BlockedBy lists
-	// Only check if external_projects are configured
-	if len(config.GetExternalProjects()) > 0 && len(blocked) > 0 {
+	// Filter out satisfied external dependencies from BlockedBy lists and
+	// explain the rest (unconfigured projects resolve without any DB access)
+	if len(blocked) > 0 {
 		blocked = filterBlockedByExternalDeps(ctx, blocked)
 	}
 
 	return blocked, nil
 }
 
-// filterBlockedByExternalDeps removes satisfied external deps from BlockedBy lists.
+// filterBlockedByExternalDeps removes satisfied external deps from BlockedBy lists
+// and records why each remaining one is unsatisfied in ExternalBlockers.
 // Issues with no remaining blockers are removed unless they have status=blocked/deferred.
 func filterBlockedByExternalDeps(ctx context.Context, blocked []*types.BlockedIssue) []*types.BlockedIssue {
 	if len(blocked) == 0 {
@@ -642,27 +644,19 @@ func filterBlockedByExternalDeps(ctx context.Context, blocked []*types.BlockedIs
 	}
 	statuses := CheckExternalDeps(ctx, refList)
 
-	// Build set of satisfied refs
-	satisfiedRefs := make(map[string]bool)
-	for ref, status := range statuses {
-		if status.Satisfied {
-			satisfiedRefs[ref] = true
-		}
-	}
-
-	// If nothing is satisfied, return as-is
-	if len(satisfiedRefs) == 0 {
-		return blocked
-	}
-
 	// Filter each issue's BlockedBy list
 	result := make([]*types.BlockedIssue, 0, len(blocked))
 	for _, issue := range blocked {
-		// Filter out satisfied external deps
+		// Filter out satisfied external deps, explain unsatisfied ones
 		var filteredBlockers []string
 		for _, ref := range issue.BlockedBy {
-			if !satisfiedRefs[ref] {
-				filteredBlockers = append(filteredBlockers, ref)
+			status, ok := statuses[ref]
+			if ok && status.Satisfied {
+				continue
+			}
+			filteredBlockers = append(filteredBlockers, ref)
+			if ok {
+				issue.ExternalBlockers = append(issue.ExternalBlockers, types.ExternalBlocker{Ref: ref, Reason: status.Reason})
 			}
 		}
 
@@ -701,7 +695,8 @@ func (s *SQLiteStorage) GetNewlyUnblockedByClose(ctx context.Context, closedIssu
 		       i.created_at, i.created_by, i.updated_at, i.closed_at, i.external_ref, i.source_repo, i.close_reason,
 		       i.deleted_at, i.deleted_by, i.delete_reason, i.original_type,
 		       i.sender, i.ephemeral, i.pinned, i.is_template,
-		       i.await_type, i.await_id, i.timeout_ns, i.waiters
+		       i.await_type, i.await_id, i.timeout_ns, i.waiters, i.budget_usd,
+		       i.event_kind, i.actor, i.target, i.payload
 		FROM issues i
 		JOIN dependencies d ON i.id = d.issue_id
 		WHERE d.depends_on_id = ?
@@ -1227,6 +1227,13 @@ func TestGetBlockedIssuesFiltersExternalDeps(t *testing.T) {
 		if len(blocked[0].BlockedBy) != 1 || blocked[0].BlockedBy[0] != "external:external-test:test-capability" {
 			t.Errorf("Expected BlockedBy to contain external ref, got %v", blocked[0].BlockedBy)
 		}
+		want := types.ExternalBlocker{
+			Ref:    "external:external-test:test-capability",
+			Reason: "capability not shipped (no closed issue with provides:test-capability label)",
+		}
+		if len(blocked[0].ExternalBlockers) != 1 || blocked[0].ExternalBlockers[0] != want {
+			t.Errorf("Expected ExternalBlockers %v, got %v", want, blocked[0].ExternalBlockers)
+		}
 	}
 
 	// Test 2: Ship the capability in external project
@@ -25,6 +25,7 @@ var expectedSchema = map[string][]string{
 	"dependencies":         {"issue_id", "depends_on_id", "type", "created_at", "created_by", "metadata", "thread_id"},
 	"labels":               {"issue_id", "label"},
 	"comments":             {"id", "issue_id", "author", "text", "created_at"},
+	"attachments":          {"issue_id", "name", "size", "sha256", "mime_type", "created_at", "created_by"},
 	"events":               {"id", "issue_id", "event_type", "actor", "old_value", "new_value", "comment", "created_at"},
 	"config":               {"key", "value"},
 	"metadata":             {"key", "value"},
@@ -444,3 +444,69 @@ func TestDeleteIssuesCreatesTombstones(t *testing.T) {
 		}
 	})
 }
+
+func TestRestoreTombstone(t *testing.T) {
+	store := newTestStore(t, "file::memory:?mode=memory&cache=private")
+	ctx := context.Background()
+
+	issue := &types.Issue{
+		ID:        "bd-1",
+		Title:     "Test Issue",
+		Status:    types.StatusOpen,
+		Priority:  1,
+		IssueType: types.TypeBug,
+	}
+	if err := store.CreateIssue(ctx, issue, "test"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	t.Run("rejects live issue", func(t *testing.T) {
+		if err := store.RestoreTombstone(ctx, "bd-1", types.StatusOpen, "tester"); err == nil {
+			t.Error("Expected error restoring an issue that is not a tombstone")
+		}
+	})
+
+	if err := store.CreateTombstone(ctx, "bd-1", "tester", "oops"); err != nil {
+		t.Fatalf("CreateTombstone failed: %v", err)
+	}
+
+	t.Run("rejects tombstone status", func(t *testing.T) {
+		if err := store.RestoreTombstone(ctx, "bd-1", types.StatusTombstone, "tester"); err == nil {
+			t.Error("Expected error restoring to tombstone status")
+		}
+	})
+
+	t.Run("restores status and type", func(t *testing.T) {
+		if err := store.RestoreTombstone(ctx, "bd-1", types.StatusInProgress, "tester"); err != nil {
+			t.Fatalf("RestoreTombstone failed: %v", err)
+		}
+
+		restored, err := store.GetIssue(ctx, "bd-1")
+		if err != nil {
+			t.Fatalf("Failed to get issue: %v", err)
+		}
+		if restored.Status != types.StatusInProgress {
+			t.Errorf("Expected status=in_progress, got %s", restored.Status)
+		}
+		if restored.IssueType != types.TypeBug {
+			t.Errorf("Expected type=bug, got %s", restored.IssueType)
+		}
+		if restored.DeletedAt != nil || restored.DeletedBy != "" || restored.DeleteReason != "" || restored.OriginalType != "" {
+			t.Errorf("Tombstone fields should be cleared, got %+v", restored)
+		}
+
+		events, err := store.GetEvents(ctx, "bd-1", 0)
+		if err != nil {
+			t.Fatalf("GetEvents failed: %v", err)
+		}
+		found := false
+		for _, e := range events {
+			if e.EventType == "restored" && e.NewValue != nil && *e.NewValue == string(types.StatusInProgress) {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected a restored event")
+		}
+	})
+}
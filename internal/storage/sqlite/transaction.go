@@ -322,7 +322,8 @@ func (t *sqliteTxStorage) GetIssue(ctx context.Context, id string) (*types.Issue
 		       compaction_level, compacted_at, compacted_at_commit, original_size, source_repo, close_reason,
 		       deleted_at, deleted_by, delete_reason, original_type,
 		       sender, ephemeral, pinned, is_template,
-		       await_type, await_id, timeout_ns, waiters
+		       await_type, await_id, timeout_ns, waiters, budget_usd,
+		       event_kind, actor, target, payload
 		FROM issues
 		WHERE id = ?
 	`, id)
@@ -445,9 +446,9 @@ func (t *sqliteTxStorage) UpdateIssue(ctx context.Context, id string, updates ma
 	eventType := determineEventType(oldIssue, updates)
 
 	_, err = t.conn.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, old_value, new_value)
-		VALUES (?, ?, ?, ?, ?)
-	`, id, eventType, actor, string(oldData), string(newData))
+		INSERT INTO events (issue_id, event_type, actor, old_value, new_value, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, eventType, actor, string(oldData), string(newData), eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -552,9 +553,9 @@ func (t *sqliteTxStorage) CloseIssue(ctx context.Context, id string, reason stri
 	}
 
 	_, err = t.conn.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, comment)
-		VALUES (?, ?, ?, ?)
-	`, id, types.EventClosed, actor, reason)
+		INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, id, types.EventClosed, actor, reason, eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -629,9 +630,9 @@ func (t *sqliteTxStorage) CloseIssue(ctx context.Context, id string, reason stri
 
 			// Record the close event
 			_, err = t.conn.ExecContext(ctx, `
-				INSERT INTO events (issue_id, event_type, actor, comment)
-				VALUES (?, ?, ?, ?)
-			`, convoyID, types.EventClosed, "system:convoy-completion", closeReason)
+				INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, convoyID, types.EventClosed, "system:convoy-completion", closeReason, eventCommandID(ctx), eventSourceOp(ctx))
 			if err != nil {
 				return fmt.Errorf("failed to record convoy close event: %w", err)
 			}
@@ -680,6 +681,11 @@ func (t *sqliteTxStorage) DeleteIssue(ctx context.Context, id string) error {
 		return fmt.Errorf("issue not found: %s", id)
 	}
 
+	// Invalidate blocked issues cache since the deleted issue may have been a blocker
+	if err := t.parent.invalidateBlockedCache(ctx, t.conn); err != nil {
+		return fmt.Errorf("failed to invalidate blocked cache: %w", err)
+	}
+
 	return nil
 }
 
@@ -784,10 +790,10 @@ func (t *sqliteTxStorage) AddDependency(ctx context.Context, dep *types.Dependen
 
 	// Record event
 	_, err = t.conn.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, comment)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`, dep.IssueID, types.EventDependencyAdded, actor,
-		fmt.Sprintf("Added dependency: %s %s %s", dep.IssueID, dep.Type, dep.DependsOnID))
+		fmt.Sprintf("Added dependency: %s %s %s", dep.IssueID, dep.Type, dep.DependsOnID), eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -843,10 +849,10 @@ func (t *sqliteTxStorage) RemoveDependency(ctx context.Context, issueID, depends
 	}
 
 	_, err = t.conn.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, comment)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`, issueID, types.EventDependencyRemoved, actor,
-		fmt.Sprintf("Removed dependency on %s", dependsOnID))
+		fmt.Sprintf("Removed dependency on %s", dependsOnID), eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -889,9 +895,9 @@ func (t *sqliteTxStorage) AddLabel(ctx context.Context, issueID, label, actor st
 
 	// Record event
 	_, err = t.conn.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, comment)
-		VALUES (?, ?, ?, ?)
-	`, issueID, types.EventLabelAdded, actor, fmt.Sprintf("Added label: %s", label))
+		INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, issueID, types.EventLabelAdded, actor, fmt.Sprintf("Added label: %s", label), eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -924,9 +930,9 @@ func (t *sqliteTxStorage) RemoveLabel(ctx context.Context, issueID, label, actor
 
 	// Record event
 	_, err = t.conn.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, comment)
-		VALUES (?, ?, ?, ?)
-	`, issueID, types.EventLabelRemoved, actor, fmt.Sprintf("Removed label: %s", label))
+		INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, issueID, types.EventLabelRemoved, actor, fmt.Sprintf("Removed label: %s", label), eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to record event: %w", err)
 	}
@@ -1036,9 +1042,9 @@ func (t *sqliteTxStorage) AddComment(ctx context.Context, issueID, actor, commen
 
 	// Insert comment event
 	_, err = t.conn.ExecContext(ctx, `
-		INSERT INTO events (issue_id, event_type, actor, comment)
-		VALUES (?, ?, ?, ?)
-	`, issueID, types.EventCommented, actor, comment)
+		INSERT INTO events (issue_id, event_type, actor, comment, command_id, source_op)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, issueID, types.EventCommented, actor, comment, eventCommandID(ctx), eventSourceOp(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to add comment: %w", err)
 	}
@@ -1192,6 +1198,13 @@ func (t *sqliteTxStorage) SearchIssues(ctx context.Context, query string, filter
 		whereClauses = append(whereClauses, fmt.Sprintf("id IN (SELECT issue_id FROM labels WHERE label IN (%s))", strings.Join(placeholders, ", ")))
 	}
 
+	// State dimension filtering
+	if len(filter.States) > 0 {
+		stateClauses, stateArgs := stateFilterClauses(filter.States)
+		whereClauses = append(whereClauses, stateClauses...)
+		args = append(args, stateArgs...)
+	}
+
 	// ID filtering: match specific issue IDs
 	if len(filter.IDs) > 0 {
 		placeholders := make([]string, len(filter.IDs))
@@ -1251,7 +1264,8 @@ func (t *sqliteTxStorage) SearchIssues(ctx context.Context, query string, filter
 		       compaction_level, compacted_at, compacted_at_commit, original_size, source_repo, close_reason,
 		       deleted_at, deleted_by, delete_reason, original_type,
 		       sender, ephemeral, pinned, is_template,
-		       await_type, await_id, timeout_ns, waiters
+		       await_type, await_id, timeout_ns, waiters, budget_usd,
+		       event_kind, actor, target, payload
 		FROM issues
 		%s
 		ORDER BY priority ASC, created_at DESC
@@ -1303,6 +1317,12 @@ func scanIssueRow(row scanner) (*types.Issue, error) {
 	var awaitID sql.NullString
 	var timeoutNs sql.NullInt64
 	var waiters sql.NullString
+	var budgetUSD sql.NullFloat64
+	// Event fields
+	var eventKind sql.NullString
+	var actor sql.NullString
+	var target sql.NullString
+	var payload sql.NullString
 
 	err := row.Scan(
 		&issue.ID, &contentHash, &issue.Title, &issue.Description, &issue.Design,
@@ -1312,7 +1332,8 @@ func scanIssueRow(row scanner) (*types.Issue, error) {
 		&issue.CompactionLevel, &compactedAt, &compactedAtCommit, &originalSize, &sourceRepo, &closeReason,
 		&deletedAt, &deletedBy, &deleteReason, &originalType,
 		&sender, &wisp, &pinned, &isTemplate,
-		&awaitType, &awaitID, &timeoutNs, &waiters,
+		&awaitType, &awaitID, &timeoutNs, &waiters, &budgetUSD,
+		&eventKind, &actor, &target, &payload,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan issue: %w", err)
@@ -1387,6 +1408,22 @@ func scanIssueRow(row scanner) (*types.Issue, error) {
 	if waiters.Valid && waiters.String != "" {
 		issue.Waiters = parseJSONStringArray(waiters.String)
 	}
+	if budgetUSD.Valid {
+		issue.BudgetUSD = &budgetUSD.Float64
+	}
+	// Event fields
+	if eventKind.Valid {
+		issue.EventKind = eventKind.String
+	}
+	if actor.Valid {
+		issue.Actor = actor.String
+	}
+	if target.Valid {
+		issue.Target = target.String
+	}
+	if payload.Valid {
+		issue.Payload = payload.String
+	}
 
 	return &issue, nil
 }
@@ -123,6 +123,12 @@ type Storage interface {
 	GetIssueComments(ctx context.Context, issueID string) ([]*types.Comment, error)
 	GetCommentsForIssues(ctx context.Context, issueIDs []string) (map[string][]*types.Comment, error)
 
+	// Attachments (metadata only; content lives in the blob store)
+	AddAttachment(ctx context.Context, attachment *types.Attachment, actor string) error
+	RemoveAttachment(ctx context.Context, issueID, name, actor string) error
+	GetAttachments(ctx context.Context, issueID string) ([]*types.Attachment, error)
+	GetAllAttachments(ctx context.Context) (map[string][]*types.Attachment, error)
+
 	// Statistics
 	GetStatistics(ctx context.Context) (*types.Statistics, error)
 
@@ -122,6 +122,18 @@ func (m *mockStorage) GetIssueComments(ctx context.Context, issueID string) ([]*
 func (m *mockStorage) GetCommentsForIssues(ctx context.Context, issueIDs []string) (map[string][]*types.Comment, error) {
 	return nil, nil
 }
+func (m *mockStorage) AddAttachment(ctx context.Context, attachment *types.Attachment, actor string) error {
+	return nil
+}
+func (m *mockStorage) RemoveAttachment(ctx context.Context, issueID, name, actor string) error {
+	return nil
+}
+func (m *mockStorage) GetAttachments(ctx context.Context, issueID string) ([]*types.Attachment, error) {
+	return nil, nil
+}
+func (m *mockStorage) GetAllAttachments(ctx context.Context) (map[string][]*types.Attachment, error) {
+	return nil, nil
+}
 func (m *mockStorage) GetStatistics(ctx context.Context) (*types.Statistics, error) {
 	return nil, nil
 }
@@ -337,6 +349,12 @@ func TestInterfaceDocumentation(t *testing.T) {
 		_ = s.GetIssueComments
 		_ = s.GetCommentsForIssues
 
+		// Verify attachment methods
+		_ = s.AddAttachment
+		_ = s.RemoveAttachment
+		_ = s.GetAttachments
+		_ = s.GetAllAttachments
+
 		// Verify statistics
 		_ = s.GetStatistics
 
@@ -0,0 +1,302 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func testDependencies(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	blocker, blocked, related := newIssue("Blocker"), newIssue("Blocked"), newIssue("Related")
+	create(t, s, blocker, blocked, related)
+	depend(t, s, blocked.ID, blocker.ID, types.DepBlocks)
+	depend(t, s, blocked.ID, related.ID, types.DepRelated)
+
+	deps, err := s.GetDependencies(ctx, blocked.ID)
+	if err != nil {
+		t.Fatalf("GetDependencies failed: %v", err)
+	}
+	expectIDSet(t, "GetDependencies", deps, blocker.ID, related.ID)
+
+	dependents, err := s.GetDependents(ctx, blocker.ID)
+	if err != nil {
+		t.Fatalf("GetDependents failed: %v", err)
+	}
+	expectIDSet(t, "GetDependents", dependents, blocked.ID)
+
+	withMeta, err := s.GetDependenciesWithMetadata(ctx, blocked.ID)
+	if err != nil {
+		t.Fatalf("GetDependenciesWithMetadata failed: %v", err)
+	}
+	depTypes := map[string]types.DependencyType{}
+	for _, d := range withMeta {
+		depTypes[d.ID] = d.DependencyType
+	}
+	if depTypes[blocker.ID] != types.DepBlocks || depTypes[related.ID] != types.DepRelated || len(depTypes) != 2 {
+		t.Errorf("GetDependenciesWithMetadata types = %v", depTypes)
+	}
+
+	dependentsMeta, err := s.GetDependentsWithMetadata(ctx, blocker.ID)
+	if err != nil {
+		t.Fatalf("GetDependentsWithMetadata failed: %v", err)
+	}
+	if len(dependentsMeta) != 1 || dependentsMeta[0].ID != blocked.ID || dependentsMeta[0].DependencyType != types.DepBlocks {
+		t.Errorf("GetDependentsWithMetadata = %+v", dependentsMeta)
+	}
+
+	records, err := s.GetDependencyRecords(ctx, blocked.ID)
+	if err != nil {
+		t.Fatalf("GetDependencyRecords failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("GetDependencyRecords returned %d records, want 2", len(records))
+	}
+	for _, rec := range records {
+		if rec.IssueID != blocked.ID {
+			t.Errorf("record IssueID = %s, want %s", rec.IssueID, blocked.ID)
+		}
+		if rec.CreatedAt.IsZero() {
+			t.Error("AddDependency must default CreatedAt")
+		}
+		if rec.CreatedBy != "tester" {
+			t.Errorf("record CreatedBy = %q, want the actor", rec.CreatedBy)
+		}
+	}
+
+	all, err := s.GetAllDependencyRecords(ctx)
+	if err != nil {
+		t.Fatalf("GetAllDependencyRecords failed: %v", err)
+	}
+	if len(all[blocked.ID]) != 2 || len(all[blocker.ID]) != 0 {
+		t.Errorf("GetAllDependencyRecords = %v", all)
+	}
+
+	counts, err := s.GetDependencyCounts(ctx, []string{blocker.ID, blocked.ID, related.ID})
+	if err != nil {
+		t.Fatalf("GetDependencyCounts failed: %v", err)
+	}
+	if c := counts[blocked.ID]; c == nil || c.DependencyCount != 2 || c.DependentCount != 0 {
+		t.Errorf("counts[blocked] = %+v, want 2 deps / 0 dependents", c)
+	}
+	if c := counts[blocker.ID]; c == nil || c.DependencyCount != 0 || c.DependentCount != 1 {
+		t.Errorf("counts[blocker] = %+v, want 0 deps / 1 dependent", c)
+	}
+
+	// Metadata and thread IDs survive the round trip.
+	reply := newIssue("Reply")
+	create(t, s, reply)
+	replyDep := &types.Dependency{
+		IssueID:     reply.ID,
+		DependsOnID: blocker.ID,
+		Type:        types.DepRepliesTo,
+		Metadata:    `{"score":1}`,
+		ThreadID:    blocker.ID,
+	}
+	if err := s.AddDependency(ctx, replyDep, "tester"); err != nil {
+		t.Fatalf("AddDependency(replies-to) failed: %v", err)
+	}
+	records, _ = s.GetDependencyRecords(ctx, reply.ID)
+	if len(records) != 1 || records[0].Metadata != `{"score":1}` || records[0].ThreadID != blocker.ID {
+		t.Errorf("replies-to record = %+v, want metadata and thread preserved", records)
+	}
+
+	if err := s.RemoveDependency(ctx, blocked.ID, blocker.ID, "tester"); err != nil {
+		t.Fatalf("RemoveDependency failed: %v", err)
+	}
+	deps, _ = s.GetDependencies(ctx, blocked.ID)
+	expectIDSet(t, "GetDependencies after remove", deps, related.ID)
+	if dependents, _ := s.GetDependents(ctx, blocker.ID); idSet(dependents)[blocked.ID] {
+		t.Error("GetDependents still lists the removed dependency")
+	}
+}
+
+func testDependencyValidation(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	a, b, c := newIssue("A"), newIssue("B"), newIssue("C")
+	epic := newIssue("Epic")
+	epic.IssueType = types.TypeEpic
+	create(t, s, a, b, c, epic)
+
+	reject := func(what string, dep *types.Dependency) {
+		t.Helper()
+		if err := s.AddDependency(ctx, dep, "tester"); err == nil {
+			t.Errorf("%s: AddDependency should fail", what)
+		}
+	}
+
+	reject("missing issue", &types.Dependency{IssueID: Prefix + "-missing", DependsOnID: a.ID, Type: types.DepBlocks})
+	reject("missing target", &types.Dependency{IssueID: a.ID, DependsOnID: Prefix + "-missing", Type: types.DepBlocks})
+	reject("self dependency", &types.Dependency{IssueID: a.ID, DependsOnID: a.ID, Type: types.DepBlocks})
+	reject("empty type", &types.Dependency{IssueID: a.ID, DependsOnID: b.ID, Type: ""})
+	reject("epic depending on its child", &types.Dependency{IssueID: epic.ID, DependsOnID: a.ID, Type: types.DepParentChild})
+
+	depend(t, s, a.ID, b.ID, types.DepBlocks)
+	reject("duplicate", &types.Dependency{IssueID: a.ID, DependsOnID: b.ID, Type: types.DepBlocks})
+
+	// Cycles are rejected across dependency types.
+	depend(t, s, b.ID, c.ID, types.DepParentChild)
+	reject("direct cycle", &types.Dependency{IssueID: b.ID, DependsOnID: a.ID, Type: types.DepBlocks})
+	reject("indirect cycle", &types.Dependency{IssueID: c.ID, DependsOnID: a.ID, Type: types.DepDiscoveredFrom})
+
+	// relates-to is bidirectional by design and exempt from cycle checks.
+	depend(t, s, a.ID, c.ID, types.DepRelatesTo)
+	depend(t, s, c.ID, a.ID, types.DepRelatesTo)
+
+	// External references are resolved lazily and need no local target.
+	depend(t, s, c.ID, "external:other:capability", types.DepBlocks)
+}
+
+func testDependencyTree(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	// leaf <- middle <- root: root depends on middle, which depends on leaf.
+	root, middle, leaf := newIssue("Root"), newIssue("Middle"), newIssue("Leaf")
+	create(t, s, root, middle, leaf)
+	depend(t, s, root.ID, middle.ID, types.DepBlocks)
+	depend(t, s, middle.ID, leaf.ID, types.DepBlocks)
+
+	tree, err := s.GetDependencyTree(ctx, root.ID, 10, false, false)
+	if err != nil {
+		t.Fatalf("GetDependencyTree failed: %v", err)
+	}
+	expectDepths(t, "tree", tree, map[string]int{root.ID: 0, middle.ID: 1, leaf.ID: 2})
+	for _, node := range tree {
+		if node.ID == leaf.ID && node.ParentID != middle.ID {
+			t.Errorf("leaf ParentID = %s, want %s", node.ParentID, middle.ID)
+		}
+	}
+
+	reverse, err := s.GetDependencyTree(ctx, leaf.ID, 10, false, true)
+	if err != nil {
+		t.Fatalf("GetDependencyTree(reverse) failed: %v", err)
+	}
+	expectDepths(t, "reverse tree", reverse, map[string]int{leaf.ID: 0, middle.ID: 1, root.ID: 2})
+
+	limited, err := s.GetDependencyTree(ctx, root.ID, 1, false, false)
+	if err != nil {
+		t.Fatalf("GetDependencyTree(maxDepth=1) failed: %v", err)
+	}
+	expectDepths(t, "depth-limited tree", limited, map[string]int{root.ID: 0, middle.ID: 1})
+	for _, node := range limited {
+		if node.ID == middle.ID && !node.Truncated {
+			t.Error("nodes at maxDepth should be marked Truncated")
+		}
+	}
+
+	// A diamond is reported once per node unless showAllPaths is set.
+	side := newIssue("Side")
+	create(t, s, side)
+	depend(t, s, root.ID, side.ID, types.DepBlocks)
+	depend(t, s, side.ID, leaf.ID, types.DepBlocks)
+	tree, _ = s.GetDependencyTree(ctx, root.ID, 10, false, false)
+	if len(tree) != 4 {
+		t.Errorf("deduplicated diamond tree has %d nodes, want 4", len(tree))
+	}
+	allPaths, _ := s.GetDependencyTree(ctx, root.ID, 10, true, false)
+	if len(allPaths) != 5 {
+		t.Errorf("showAllPaths diamond tree has %d nodes, want 5", len(allPaths))
+	}
+}
+
+func expectDepths(t *testing.T, what string, nodes []*types.TreeNode, want map[string]int) {
+	t.Helper()
+	got := make(map[string]int, len(nodes))
+	for _, node := range nodes {
+		got[node.ID] = node.Depth
+	}
+	if len(got) != len(want) || len(nodes) != len(want) {
+		t.Errorf("%s depths = %v, want %v", what, got, want)
+		return
+	}
+	for id, depth := range want {
+		if d, ok := got[id]; !ok || d != depth {
+			t.Errorf("%s depths = %v, want %v", what, got, want)
+			return
+		}
+	}
+	if nodes[0].Depth != 0 {
+		t.Errorf("%s must start with the root node, got %s at depth %d", what, nodes[0].ID, nodes[0].Depth)
+	}
+}
+
+func testDetectCycles(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	a, b, c := newIssue("A"), newIssue("B"), newIssue("C")
+	create(t, s, a, b, c)
+	depend(t, s, a.ID, b.ID, types.DepBlocks)
+	depend(t, s, b.ID, c.ID, types.DepBlocks)
+	depend(t, s, c.ID, a.ID, types.DepRelatesTo)
+	depend(t, s, a.ID, c.ID, types.DepRelatesTo)
+
+	cycles, err := s.DetectCycles(ctx)
+	if err != nil {
+		t.Fatalf("DetectCycles failed: %v", err)
+	}
+	if len(cycles) != 0 {
+		t.Errorf("DetectCycles on a DAG (plus relates-to links) = %d cycles, want 0", len(cycles))
+	}
+}
+
+func testLabels(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	a, b := newIssue("A"), newIssue("B")
+	a.Priority = 1
+	create(t, s, a, b)
+
+	for _, label := range []string{"zeta", "alpha", "alpha"} {
+		if err := s.AddLabel(ctx, a.ID, label, "tester"); err != nil {
+			t.Fatalf("AddLabel(%s) failed: %v", label, err)
+		}
+	}
+	if err := s.AddLabel(ctx, b.ID, "alpha", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if err := s.AddLabel(ctx, Prefix+"-missing", "alpha", "tester"); err == nil {
+		t.Error("AddLabel on a missing issue should fail")
+	}
+
+	labels, err := s.GetLabels(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("GetLabels failed: %v", err)
+	}
+	if len(labels) != 2 || labels[0] != "alpha" || labels[1] != "zeta" {
+		t.Errorf("GetLabels = %v, want sorted and deduplicated [alpha zeta]", labels)
+	}
+	if got := mustGet(t, s, a.ID).Labels; len(got) != 2 {
+		t.Errorf("GetIssue labels = %v, want 2 labels attached", got)
+	}
+
+	byIssue, err := s.GetLabelsForIssues(ctx, []string{a.ID, b.ID, Prefix + "-missing"})
+	if err != nil {
+		t.Fatalf("GetLabelsForIssues failed: %v", err)
+	}
+	if len(byIssue[a.ID]) != 2 || len(byIssue[b.ID]) != 1 || len(byIssue[Prefix+"-missing"]) != 0 {
+		t.Errorf("GetLabelsForIssues = %v", byIssue)
+	}
+
+	tagged, err := s.GetIssuesByLabel(ctx, "alpha")
+	if err != nil {
+		t.Fatalf("GetIssuesByLabel failed: %v", err)
+	}
+	expectIDs(t, "GetIssuesByLabel (priority order)", tagged, a.ID, b.ID)
+
+	if err := s.RemoveLabel(ctx, a.ID, "alpha", "tester"); err != nil {
+		t.Fatalf("RemoveLabel failed: %v", err)
+	}
+	if err := s.RemoveLabel(ctx, a.ID, "never-added", "tester"); err != nil {
+		t.Errorf("RemoveLabel of an absent label should be a no-op, got %v", err)
+	}
+	labels, _ = s.GetLabels(ctx, a.ID)
+	if len(labels) != 1 || labels[0] != "zeta" {
+		t.Errorf("GetLabels after remove = %v, want [zeta]", labels)
+	}
+	tagged, _ = s.GetIssuesByLabel(ctx, "alpha")
+	expectIDs(t, "GetIssuesByLabel after remove", tagged, b.ID)
+}
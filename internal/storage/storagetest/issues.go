@@ -0,0 +1,474 @@
+package storagetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func testCreateAndGetIssue(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	issue := newIssue("Write the suite")
+	issue.Description = "Cover every method"
+	issue.Design = "Table of subtests"
+	issue.AcceptanceCriteria = "All backends pass"
+	issue.Notes = "SQLite is the reference"
+	issue.Assignee = "alice"
+	issue.Priority = 1
+	issue.IssueType = types.TypeFeature
+	create(t, s, issue)
+
+	if !strings.HasPrefix(issue.ID, Prefix+"-") {
+		t.Errorf("generated ID %q does not use prefix %q", issue.ID, Prefix)
+	}
+	if issue.CreatedAt.IsZero() || issue.UpdatedAt.IsZero() {
+		t.Error("CreateIssue must set CreatedAt and UpdatedAt")
+	}
+
+	got := mustGet(t, s, issue.ID)
+	if got.Title != issue.Title || got.Description != issue.Description || got.Design != issue.Design ||
+		got.AcceptanceCriteria != issue.AcceptanceCriteria || got.Notes != issue.Notes {
+		t.Errorf("text fields not preserved: got %+v", got)
+	}
+	if got.Status != types.StatusOpen || got.Priority != 1 || got.IssueType != types.TypeFeature || got.Assignee != "alice" {
+		t.Errorf("workflow fields not preserved: status=%s priority=%d type=%s assignee=%q",
+			got.Status, got.Priority, got.IssueType, got.Assignee)
+	}
+
+	missing, err := s.GetIssue(ctx, Prefix+"-missing")
+	if err != nil {
+		t.Errorf("GetIssue on a missing ID should not error, got %v", err)
+	}
+	if missing != nil {
+		t.Errorf("GetIssue on a missing ID = %+v, want nil", missing)
+	}
+
+	// Caller-supplied IDs and timestamps are preserved (used by import).
+	created := ago(72 * time.Hour)
+	explicit := newIssue("Explicit")
+	explicit.ID = Prefix + "-explicit"
+	explicit.CreatedAt = created
+	explicit.UpdatedAt = created
+	create(t, s, explicit)
+	got = mustGet(t, s, explicit.ID)
+	if !got.CreatedAt.Equal(created) {
+		t.Errorf("CreatedAt = %v, want caller-supplied %v", got.CreatedAt, created)
+	}
+	if !got.UpdatedAt.Equal(created) {
+		t.Errorf("UpdatedAt = %v, want caller-supplied %v", got.UpdatedAt, created)
+	}
+
+	// Re-creating an existing ID may fail or be ignored, but never clobbers.
+	dup := newIssue("Duplicate")
+	dup.ID = explicit.ID
+	_ = s.CreateIssue(ctx, dup, "tester")
+	if got := mustGet(t, s, explicit.ID); got.Title != explicit.Title {
+		t.Errorf("CreateIssue with an existing ID overwrote it: title %q", got.Title)
+	}
+}
+
+func testCreateIssueValidation(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	cases := map[string]*types.Issue{
+		"empty title":         {Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask},
+		"bad priority":        {Title: "x", Status: types.StatusOpen, Priority: 9, IssueType: types.TypeTask},
+		"bad status":          {Title: "x", Status: "bogus", Priority: 2, IssueType: types.TypeTask},
+		"bad type":            {Title: "x", Status: types.StatusOpen, Priority: 2, IssueType: "bogus"},
+		"open with closed_at": {Title: "x", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask, ClosedAt: timePtr(time.Now())},
+	}
+	for name, issue := range cases {
+		if err := s.CreateIssue(ctx, issue, "tester"); err == nil {
+			t.Errorf("%s: CreateIssue should fail validation", name)
+		}
+	}
+
+	// Closed issues without closed_at are repaired rather than rejected (GH#523).
+	closed := newIssue("Closed on import")
+	closed.Status = types.StatusClosed
+	create(t, s, closed)
+	if got := mustGet(t, s, closed.ID); got.ClosedAt == nil {
+		t.Error("closed issue created without closed_at should get one")
+	}
+}
+
+func testCreateIssues(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	batch := []*types.Issue{newIssue("One"), newIssue("Two"), newIssue("Three")}
+	if err := s.CreateIssues(ctx, batch, "tester"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, issue := range batch {
+		if issue.ID == "" {
+			t.Fatal("CreateIssues must assign IDs")
+		}
+		if seen[issue.ID] {
+			t.Errorf("duplicate generated ID %s", issue.ID)
+		}
+		seen[issue.ID] = true
+		mustGet(t, s, issue.ID)
+	}
+
+	// A batch with an invalid issue is rejected as a whole.
+	bad := []*types.Issue{newIssue("Fine"), {Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}}
+	if err := s.CreateIssues(ctx, bad, "tester"); err == nil {
+		t.Fatal("CreateIssues with an invalid issue should fail")
+	}
+	if got := search(t, s, "Fine", types.IssueFilter{}); len(got) != 0 {
+		t.Errorf("failed batch must not leave partial results, found %v", ids(got))
+	}
+
+	// Duplicate IDs within a batch are rejected.
+	a, b := newIssue("A"), newIssue("B")
+	a.ID, b.ID = Prefix+"-same", Prefix+"-same"
+	if err := s.CreateIssues(ctx, []*types.Issue{a, b}, "tester"); err == nil {
+		t.Error("CreateIssues with duplicate IDs should fail")
+	}
+}
+
+func testExternalRef(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	issue := newIssue("Linked")
+	issue.ExternalRef = strPtr("gh-1")
+	create(t, s, issue)
+
+	got, err := s.GetIssueByExternalRef(ctx, "gh-1")
+	if err != nil || got == nil || got.ID != issue.ID {
+		t.Fatalf("GetIssueByExternalRef(gh-1) = %v, %v; want %s", got, err, issue.ID)
+	}
+	if got, err := s.GetIssueByExternalRef(ctx, "gh-missing"); err != nil || got != nil {
+		t.Errorf("GetIssueByExternalRef(gh-missing) = %v, %v; want nil, nil", got, err)
+	}
+
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"external_ref": "gh-2"}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue(external_ref) failed: %v", err)
+	}
+	if got, _ := s.GetIssueByExternalRef(ctx, "gh-1"); got != nil {
+		t.Errorf("old external ref still resolves to %s", got.ID)
+	}
+	if got, _ := s.GetIssueByExternalRef(ctx, "gh-2"); got == nil || got.ID != issue.ID {
+		t.Errorf("new external ref does not resolve to %s", issue.ID)
+	}
+	if ref := mustGet(t, s, issue.ID).ExternalRef; ref == nil || *ref != "gh-2" {
+		t.Errorf("ExternalRef = %v, want gh-2", ref)
+	}
+}
+
+func testUpdateIssue(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	issue := newIssue("Before")
+	issue.CreatedAt = ago(time.Hour)
+	issue.UpdatedAt = issue.CreatedAt
+	create(t, s, issue)
+
+	updates := map[string]interface{}{
+		"title":               "After",
+		"description":         "new description",
+		"design":              "new design",
+		"acceptance_criteria": "new criteria",
+		"notes":               "new notes",
+		"priority":            0,
+		"issue_type":          string(types.TypeBug),
+		"assignee":            "bob",
+		"status":              string(types.StatusInProgress),
+		"estimated_minutes":   30,
+		"pinned":              true,
+	}
+	if err := s.UpdateIssue(ctx, issue.ID, updates, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+
+	got := mustGet(t, s, issue.ID)
+	if got.Title != "After" || got.Description != "new description" || got.Design != "new design" ||
+		got.AcceptanceCriteria != "new criteria" || got.Notes != "new notes" {
+		t.Errorf("text updates not applied: %+v", got)
+	}
+	if got.Priority != 0 || got.IssueType != types.TypeBug || got.Assignee != "bob" || got.Status != types.StatusInProgress {
+		t.Errorf("workflow updates not applied: priority=%d type=%s assignee=%q status=%s",
+			got.Priority, got.IssueType, got.Assignee, got.Status)
+	}
+	if got.EstimatedMinutes == nil || *got.EstimatedMinutes != 30 {
+		t.Errorf("EstimatedMinutes = %v, want 30", got.EstimatedMinutes)
+	}
+	if !got.Pinned {
+		t.Error("Pinned update not applied")
+	}
+	if !got.UpdatedAt.After(issue.CreatedAt) {
+		t.Errorf("UpdatedAt = %v, want after %v", got.UpdatedAt, issue.CreatedAt)
+	}
+
+	if err := s.UpdateIssue(ctx, Prefix+"-missing", map[string]interface{}{"title": "x"}, "tester"); err == nil {
+		t.Error("UpdateIssue on a missing issue should fail")
+	}
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"no_such_field": "x"}, "tester"); err == nil {
+		t.Error("UpdateIssue with an unknown field should fail")
+	}
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"status": "bogus"}, "tester"); err == nil {
+		t.Error("UpdateIssue with an invalid status should fail")
+	}
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"priority": 7}, "tester"); err == nil {
+		t.Error("UpdateIssue with an invalid priority should fail")
+	}
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"title": ""}, "tester"); err == nil {
+		t.Error("UpdateIssue with an empty title should fail")
+	}
+
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"assignee": nil}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue(assignee=nil) failed: %v", err)
+	}
+	if got := mustGet(t, s, issue.ID); got.Assignee != "" {
+		t.Errorf("Assignee = %q after clearing, want empty", got.Assignee)
+	}
+}
+
+func testCloseAndReopen(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	issue := newIssue("Close me")
+	create(t, s, issue)
+
+	if err := s.CloseIssue(ctx, issue.ID, "done", "tester", "session-1"); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	got := mustGet(t, s, issue.ID)
+	if got.Status != types.StatusClosed {
+		t.Errorf("Status = %s, want closed", got.Status)
+	}
+	if got.ClosedAt == nil {
+		t.Error("CloseIssue must set ClosedAt")
+	}
+	if got.CloseReason != "done" {
+		t.Errorf("CloseReason = %q, want done", got.CloseReason)
+	}
+
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"status": string(types.StatusOpen)}, "tester"); err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if got := mustGet(t, s, issue.ID); got.Status != types.StatusOpen || got.ClosedAt != nil {
+		t.Errorf("reopened issue: status=%s closed_at=%v, want open and nil", got.Status, got.ClosedAt)
+	}
+
+	// Closing via UpdateIssue maintains the same closed_at invariant.
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"status": string(types.StatusClosed)}, "tester"); err != nil {
+		t.Fatalf("close via update failed: %v", err)
+	}
+	if got := mustGet(t, s, issue.ID); got.ClosedAt == nil {
+		t.Error("closing via UpdateIssue must set ClosedAt")
+	}
+
+	if err := s.CloseIssue(ctx, Prefix+"-missing", "done", "tester", ""); err == nil {
+		t.Error("CloseIssue on a missing issue should fail")
+	}
+}
+
+func testDeleteIssue(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	target, dependent, other := newIssue("Target"), newIssue("Dependent"), newIssue("Other")
+	create(t, s, target, dependent, other)
+	depend(t, s, dependent.ID, target.ID, types.DepBlocks)
+	depend(t, s, target.ID, other.ID, types.DepRelated)
+	if err := s.AddLabel(ctx, target.ID, "doomed", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if _, err := s.AddIssueComment(ctx, target.ID, "tester", "bye"); err != nil {
+		t.Fatalf("AddIssueComment failed: %v", err)
+	}
+
+	if err := s.DeleteIssue(ctx, target.ID); err != nil {
+		t.Fatalf("DeleteIssue failed: %v", err)
+	}
+	if got, err := s.GetIssue(ctx, target.ID); err != nil || got != nil {
+		t.Errorf("GetIssue after delete = %v, %v; want nil, nil", got, err)
+	}
+
+	// Dependencies in both directions are removed.
+	if deps, _ := s.GetDependencyRecords(ctx, dependent.ID); len(deps) != 0 {
+		t.Errorf("dependent still has %d dependency records after delete", len(deps))
+	}
+	if dependents, _ := s.GetDependents(ctx, other.ID); len(dependents) != 0 {
+		t.Errorf("other still has dependents %v after delete", ids(dependents))
+	}
+	if comments, _ := s.GetIssueComments(ctx, target.ID); len(comments) != 0 {
+		t.Errorf("comments survived delete: %v", comments)
+	}
+	if issues, _ := s.GetIssuesByLabel(ctx, "doomed"); len(issues) != 0 {
+		t.Errorf("label lookup still finds %v", ids(issues))
+	}
+
+	// Removing the blocker makes the dependent ready.
+	if !idSet(ready(t, s, types.WorkFilter{}))[dependent.ID] {
+		t.Error("dependent should be ready once its blocker is deleted")
+	}
+
+	if err := s.DeleteIssue(ctx, target.ID); err == nil {
+		t.Error("deleting a missing issue should fail")
+	}
+}
+
+func testTombstoneFiltering(t *testing.T, s storage.Storage) {
+	live := newIssue("Live widget")
+	deleted := time.Now()
+	tomb := newIssue("Dead widget")
+	tomb.Status = types.StatusTombstone
+	tomb.DeletedAt = &deleted
+	tomb.DeletedBy = "tester"
+	tomb.DeleteReason = "duplicate"
+	tomb.OriginalType = string(types.TypeTask)
+	create(t, s, live, tomb)
+
+	expectIDSet(t, "default search", search(t, s, "widget", types.IssueFilter{}), live.ID)
+	expectIDSet(t, "search with IncludeTombstones", search(t, s, "widget", types.IssueFilter{IncludeTombstones: true}), live.ID, tomb.ID)
+
+	status := types.StatusTombstone
+	expectIDSet(t, "search by tombstone status", search(t, s, "", types.IssueFilter{Status: &status}), tomb.ID)
+
+	if idSet(ready(t, s, types.WorkFilter{}))[tomb.ID] {
+		t.Error("tombstones must never be ready work")
+	}
+
+	// Tombstones are still addressable by ID (for sync/import).
+	got := mustGet(t, s, tomb.ID)
+	if got.Status != types.StatusTombstone || got.DeletedAt == nil {
+		t.Errorf("tombstone fields not preserved: status=%s deleted_at=%v", got.Status, got.DeletedAt)
+	}
+}
+
+func testSearchFilters(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	parent := newIssue("Parent epic")
+	parent.IssueType = types.TypeEpic
+	parent.Priority = 1
+
+	bug := newIssue("Login crash")
+	bug.IssueType = types.TypeBug
+	bug.Priority = 0
+	bug.Assignee = "alice"
+	bug.Description = "Crashes on submit"
+	bug.Notes = "repro attached"
+
+	feature := newIssue("Dark mode")
+	feature.IssueType = types.TypeFeature
+	feature.Priority = 3
+	feature.Assignee = "bob"
+	feature.Description = "Theme support"
+
+	chore := newIssue("Bump deps")
+	chore.Priority = 4
+	chore.Status = types.StatusInProgress
+
+	pinned := newIssue("Pinned context")
+	pinned.Pinned = true
+	pinned.Description = "Always relevant"
+
+	wisp := newIssue("Ephemeral patrol")
+	wisp.Ephemeral = true
+	wisp.Description = "Short lived"
+
+	create(t, s, parent, bug, feature, chore, pinned, wisp)
+	depend(t, s, bug.ID, parent.ID, types.DepParentChild)
+	depend(t, s, feature.ID, parent.ID, types.DepParentChild)
+	for id, labels := range map[string][]string{
+		bug.ID:     {"frontend", "urgent", "health:degraded"},
+		feature.ID: {"frontend", "health:healthy"},
+		chore.ID:   {"backend", "healthcheck", "health:failing"},
+	} {
+		for _, label := range labels {
+			if err := s.AddLabel(ctx, id, label, "tester"); err != nil {
+				t.Fatalf("AddLabel failed: %v", err)
+			}
+		}
+	}
+
+	all := []string{parent.ID, bug.ID, feature.ID, chore.ID, pinned.ID, wisp.ID}
+	expectIDSet(t, "no filter", search(t, s, "", types.IssueFilter{}), all...)
+
+	expectIDSet(t, "query title (case-insensitive)", search(t, s, "login", types.IssueFilter{}), bug.ID)
+	expectIDSet(t, "query description", search(t, s, "theme", types.IssueFilter{}), feature.ID)
+	expectIDSet(t, "query id", search(t, s, bug.ID, types.IssueFilter{}), bug.ID)
+
+	inProgress := types.StatusInProgress
+	expectIDSet(t, "status", search(t, s, "", types.IssueFilter{Status: &inProgress}), chore.ID)
+	expectIDSet(t, "priority", search(t, s, "", types.IssueFilter{Priority: intPtr(0)}), bug.ID)
+	expectIDSet(t, "priority range", search(t, s, "", types.IssueFilter{PriorityMin: intPtr(1), PriorityMax: intPtr(3)}), parent.ID, feature.ID, pinned.ID, wisp.ID)
+
+	bugType := types.TypeBug
+	expectIDSet(t, "issue type", search(t, s, "", types.IssueFilter{IssueType: &bugType}), bug.ID)
+	expectIDSet(t, "assignee", search(t, s, "", types.IssueFilter{Assignee: strPtr("bob")}), feature.ID)
+	expectIDSet(t, "no assignee", search(t, s, "", types.IssueFilter{NoAssignee: true}), parent.ID, chore.ID, pinned.ID, wisp.ID)
+
+	expectIDSet(t, "labels (AND)", search(t, s, "", types.IssueFilter{Labels: []string{"frontend", "urgent"}}), bug.ID)
+	expectIDSet(t, "labels (OR)", search(t, s, "", types.IssueFilter{LabelsAny: []string{"urgent", "backend"}}), bug.ID, chore.ID)
+	expectIDSet(t, "no labels", search(t, s, "", types.IssueFilter{NoLabels: true}), parent.ID, pinned.ID, wisp.ID)
+
+	degraded := types.StateFilter{Dimension: "health", Value: "degraded"}
+	expectIDSet(t, "state", search(t, s, "", types.IssueFilter{States: []types.StateFilter{degraded}}), bug.ID)
+	expectIDSet(t, "state any value", search(t, s, "", types.IssueFilter{States: []types.StateFilter{{Dimension: "health"}}}), bug.ID, feature.ID, chore.ID)
+	healthyOrUnset := types.StateFilter{Dimension: "health", Value: "healthy", IncludeUnset: true}
+	expectIDSet(t, "state with default", search(t, s, "", types.IssueFilter{States: []types.StateFilter{healthyOrUnset}}), parent.ID, feature.ID, pinned.ID, wisp.ID)
+	expectIDSet(t, "state and labels", search(t, s, "", types.IssueFilter{Labels: []string{"frontend"}, States: []types.StateFilter{healthyOrUnset}}), feature.ID)
+
+	expectIDSet(t, "ids", search(t, s, "", types.IssueFilter{IDs: []string{bug.ID, chore.ID}}), bug.ID, chore.ID)
+	expectIDSet(t, "id prefix", search(t, s, "", types.IssueFilter{IDPrefix: bug.ID}), bug.ID)
+
+	expectIDSet(t, "title contains", search(t, s, "", types.IssueFilter{TitleContains: "mode"}), feature.ID)
+	expectIDSet(t, "title search", search(t, s, "", types.IssueFilter{TitleSearch: "crash"}), bug.ID)
+	expectIDSet(t, "description contains", search(t, s, "", types.IssueFilter{DescriptionContains: "submit"}), bug.ID)
+	expectIDSet(t, "notes contains", search(t, s, "", types.IssueFilter{NotesContains: "repro"}), bug.ID)
+	expectIDSet(t, "empty description", search(t, s, "", types.IssueFilter{EmptyDescription: true}), parent.ID, chore.ID)
+
+	expectIDSet(t, "exclude status", search(t, s, "", types.IssueFilter{ExcludeStatus: []types.Status{types.StatusOpen}}), chore.ID)
+	expectIDSet(t, "exclude types", search(t, s, "", types.IssueFilter{ExcludeTypes: []types.IssueType{types.TypeTask, types.TypeEpic}}), bug.ID, feature.ID)
+
+	expectIDSet(t, "pinned", search(t, s, "", types.IssueFilter{Pinned: boolPtr(true)}), pinned.ID)
+	expectIDSet(t, "ephemeral", search(t, s, "", types.IssueFilter{Ephemeral: boolPtr(true)}), wisp.ID)
+	if got := search(t, s, "", types.IssueFilter{Ephemeral: boolPtr(false)}); idSet(got)[wisp.ID] || len(got) != len(all)-1 {
+		t.Errorf("non-ephemeral search = %v, want everything but %s", ids(got), wisp.ID)
+	}
+
+	expectIDSet(t, "parent", search(t, s, "", types.IssueFilter{ParentID: &parent.ID}), bug.ID, feature.ID)
+
+	if got := search(t, s, "", types.IssueFilter{Limit: 2}); len(got) != 2 {
+		t.Errorf("limit 2 returned %d issues", len(got))
+	}
+
+	after := time.Now().Add(-time.Minute)
+	if got := search(t, s, "", types.IssueFilter{CreatedAfter: &after}); len(got) != len(all) {
+		t.Errorf("CreatedAfter(1m ago) returned %d issues, want %d", len(got), len(all))
+	}
+	if got := search(t, s, "", types.IssueFilter{CreatedBefore: &after}); len(got) != 0 {
+		t.Errorf("CreatedBefore(1m ago) returned %v, want none", ids(got))
+	}
+}
+
+func testSearchOrdering(t *testing.T, s storage.Storage) {
+	older := newIssue("Older P1")
+	older.Priority = 1
+	older.CreatedAt = ago(3 * time.Hour)
+	newer := newIssue("Newer P1")
+	newer.Priority = 1
+	newer.CreatedAt = ago(time.Hour)
+	urgent := newIssue("Urgent P0")
+	urgent.Priority = 0
+	urgent.CreatedAt = ago(5 * time.Hour)
+	low := newIssue("Low P3")
+	low.Priority = 3
+	low.CreatedAt = ago(10 * time.Minute)
+	create(t, s, older, newer, urgent, low)
+
+	// Priority ascending, then newest first.
+	expectIDs(t, "search order", search(t, s, "", types.IssueFilter{}), urgent.ID, newer.ID, older.ID, low.ID)
+	expectIDs(t, "search order with limit", search(t, s, "", types.IssueFilter{Limit: 2}), urgent.ID, newer.ID)
+}
+
+func timePtr(v time.Time) *time.Time { return &v }
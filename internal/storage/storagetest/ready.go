@@ -0,0 +1,383 @@
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func testReadyWorkFiltering(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	open := newIssue("Open task")
+	open.Assignee = "alice"
+	inProgress := newIssue("In progress")
+	inProgress.Status = types.StatusInProgress
+	closed := newIssue("Closed")
+	closed.Status = types.StatusClosed
+	blockedStatus := newIssue("Blocked status")
+	blockedStatus.Status = types.StatusBlocked
+	pinned := newIssue("Pinned")
+	pinned.Pinned = true
+	wisp := newIssue("Wisp")
+	wisp.Ephemeral = true
+	future := time.Now().Add(24 * time.Hour)
+	deferred := newIssue("Deferred")
+	deferred.DeferUntil = &future
+	past := time.Now().Add(-time.Hour)
+	undeferred := newIssue("Deferral elapsed")
+	undeferred.DeferUntil = &past
+	bug := newIssue("Bug")
+	bug.IssueType = types.TypeBug
+	bug.Priority = 0
+
+	workflow := []*types.Issue{}
+	for _, issueType := range []types.IssueType{types.TypeGate, types.TypeMolecule, types.TypeMessage, types.TypeMergeRequest} {
+		issue := newIssue("Workflow " + string(issueType))
+		issue.IssueType = issueType
+		workflow = append(workflow, issue)
+	}
+
+	create(t, s, open, inProgress, closed, blockedStatus, pinned, wisp, deferred, undeferred, bug)
+	create(t, s, workflow...)
+	if err := s.AddLabel(ctx, bug.ID, "urgent", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if err := s.AddLabel(ctx, open.ID, "later", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+
+	expectIDSet(t, "default ready work", ready(t, s, types.WorkFilter{}), open.ID, inProgress.ID, undeferred.ID, bug.ID)
+	expectIDSet(t, "include deferred", ready(t, s, types.WorkFilter{IncludeDeferred: true}), open.ID, inProgress.ID, deferred.ID, undeferred.ID, bug.ID)
+	expectIDSet(t, "status filter", ready(t, s, types.WorkFilter{Status: types.StatusInProgress}), inProgress.ID)
+	expectIDSet(t, "type filter", ready(t, s, types.WorkFilter{Type: string(types.TypeBug)}), bug.ID)
+	expectIDSet(t, "explicit workflow type", ready(t, s, types.WorkFilter{Type: string(types.TypeGate)}), workflow[0].ID)
+	expectIDSet(t, "priority filter", ready(t, s, types.WorkFilter{Priority: intPtr(0)}), bug.ID)
+	expectIDSet(t, "assignee filter", ready(t, s, types.WorkFilter{Assignee: strPtr("alice")}), open.ID)
+	expectIDSet(t, "unassigned", ready(t, s, types.WorkFilter{Unassigned: true}), inProgress.ID, undeferred.ID, bug.ID)
+	expectIDSet(t, "labels (AND)", ready(t, s, types.WorkFilter{Labels: []string{"urgent"}}), bug.ID)
+	expectIDSet(t, "labels (OR)", ready(t, s, types.WorkFilter{LabelsAny: []string{"urgent", "later"}}), open.ID, bug.ID)
+
+	if got := ready(t, s, types.WorkFilter{Limit: 2}); len(got) != 2 {
+		t.Errorf("limit 2 returned %d issues", len(got))
+	}
+
+	// ParentID selects all descendants, not just direct children.
+	epic := newIssue("Epic")
+	epic.IssueType = types.TypeEpic
+	child, grandchild := newIssue("Child"), newIssue("Grandchild")
+	create(t, s, epic, child, grandchild)
+	depend(t, s, child.ID, epic.ID, types.DepParentChild)
+	depend(t, s, grandchild.ID, child.ID, types.DepParentChild)
+	expectIDSet(t, "parent filter", ready(t, s, types.WorkFilter{ParentID: &epic.ID}), child.ID, grandchild.ID)
+
+	swarm := types.MolTypeSwarm
+	mol := newIssue("Swarm step")
+	mol.MolType = swarm
+	create(t, s, mol)
+	expectIDSet(t, "mol type filter", ready(t, s, types.WorkFilter{MolType: &swarm}), mol.ID)
+}
+
+func testReadyWorkOrdering(t *testing.T, s storage.Storage) {
+	// Two recent issues (priority decides) and two old ones (age decides).
+	recentLow := newIssue("Recent P3")
+	recentLow.Priority = 3
+	recentLow.CreatedAt = ago(time.Hour)
+	recentHigh := newIssue("Recent P1")
+	recentHigh.Priority = 1
+	recentHigh.CreatedAt = ago(2 * time.Hour)
+	oldLow := newIssue("Old P4")
+	oldLow.Priority = 4
+	oldLow.CreatedAt = ago(10 * 24 * time.Hour)
+	oldHigh := newIssue("Old P0")
+	oldHigh.Priority = 0
+	oldHigh.CreatedAt = ago(5 * 24 * time.Hour)
+	for _, issue := range []*types.Issue{recentLow, recentHigh, oldLow, oldHigh} {
+		issue.UpdatedAt = issue.CreatedAt
+	}
+	create(t, s, recentLow, recentHigh, oldLow, oldHigh)
+
+	expectIDs(t, "hybrid (default)", ready(t, s, types.WorkFilter{}),
+		recentHigh.ID, recentLow.ID, oldLow.ID, oldHigh.ID)
+	expectIDs(t, "hybrid (explicit)", ready(t, s, types.WorkFilter{SortPolicy: types.SortPolicyHybrid}),
+		recentHigh.ID, recentLow.ID, oldLow.ID, oldHigh.ID)
+	expectIDs(t, "priority", ready(t, s, types.WorkFilter{SortPolicy: types.SortPolicyPriority}),
+		oldHigh.ID, recentHigh.ID, recentLow.ID, oldLow.ID)
+	expectIDs(t, "oldest", ready(t, s, types.WorkFilter{SortPolicy: types.SortPolicyOldest}),
+		oldLow.ID, oldHigh.ID, recentHigh.ID, recentLow.ID)
+	expectIDs(t, "limit applies after ordering", ready(t, s, types.WorkFilter{SortPolicy: types.SortPolicyPriority, Limit: 2}),
+		oldHigh.ID, recentHigh.ID)
+}
+
+func testBlockedSemantics(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	blocker := newIssue("Blocker")
+	blocked := newIssue("Blocked")
+	epic := newIssue("Blocked epic")
+	epic.IssueType = types.TypeEpic
+	child, grandchild := newIssue("Child of blocked epic"), newIssue("Grandchild of blocked epic")
+	related := newIssue("Only related")
+	create(t, s, blocker, blocked, epic, child, grandchild, related)
+
+	depend(t, s, blocked.ID, blocker.ID, types.DepBlocks)
+	depend(t, s, epic.ID, blocker.ID, types.DepBlocks)
+	depend(t, s, child.ID, epic.ID, types.DepParentChild)
+	depend(t, s, grandchild.ID, child.ID, types.DepParentChild)
+	depend(t, s, related.ID, blocker.ID, types.DepRelated)
+
+	// Blockage propagates through parent-child; non-blocking types never block.
+	expectIDSet(t, "ready while blocker open", ready(t, s, types.WorkFilter{}), blocker.ID, related.ID)
+
+	// Every non-closed status of the blocker keeps its dependents blocked.
+	for _, status := range []types.Status{types.StatusInProgress, types.StatusBlocked, types.StatusDeferred, types.StatusHooked} {
+		if err := s.UpdateIssue(ctx, blocker.ID, map[string]interface{}{"status": string(status)}, "tester"); err != nil {
+			t.Fatalf("UpdateIssue(status=%s) failed: %v", status, err)
+		}
+		if idSet(ready(t, s, types.WorkFilter{}))[blocked.ID] {
+			t.Errorf("blocker with status %s should still block", status)
+		}
+	}
+
+	if err := s.CloseIssue(ctx, blocker.ID, "done", "tester", ""); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	expectIDSet(t, "ready after blocker closed", ready(t, s, types.WorkFilter{}),
+		blocked.ID, epic.ID, child.ID, grandchild.ID, related.ID)
+
+	// Reopening the blocker restores the blockage.
+	if err := s.UpdateIssue(ctx, blocker.ID, map[string]interface{}{"status": string(types.StatusOpen)}, "tester"); err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	expectIDSet(t, "ready after blocker reopened", ready(t, s, types.WorkFilter{}), blocker.ID, related.ID)
+
+	// Removing the dependency unblocks as well.
+	if err := s.RemoveDependency(ctx, epic.ID, blocker.ID, "tester"); err != nil {
+		t.Fatalf("RemoveDependency failed: %v", err)
+	}
+	expectIDSet(t, "ready after dependency removed", ready(t, s, types.WorkFilter{}),
+		blocker.ID, epic.ID, child.ID, grandchild.ID, related.ID)
+}
+
+func testConditionalBlocks(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	// fallback runs only if attempt fails.
+	attempt, fallback := newIssue("Attempt"), newIssue("Fallback")
+	create(t, s, attempt, fallback)
+	depend(t, s, fallback.ID, attempt.ID, types.DepConditionalBlocks)
+
+	if idSet(ready(t, s, types.WorkFilter{}))[fallback.ID] {
+		t.Error("conditional-blocks dependent must wait while the blocker is open")
+	}
+	if err := s.CloseIssue(ctx, attempt.ID, "shipped", "tester", ""); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	if idSet(ready(t, s, types.WorkFilter{}))[fallback.ID] {
+		t.Error("conditional-blocks dependent must stay blocked when the blocker succeeds")
+	}
+
+	retry, recovery := newIssue("Retry"), newIssue("Recovery")
+	create(t, s, retry, recovery)
+	depend(t, s, recovery.ID, retry.ID, types.DepConditionalBlocks)
+	if err := s.CloseIssue(ctx, retry.ID, "Failed: timeout talking to API", "tester", ""); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	if !idSet(ready(t, s, types.WorkFilter{}))[recovery.ID] {
+		t.Error("conditional-blocks dependent must be ready once the blocker fails")
+	}
+
+	// waits-for gates on all children of the spawner.
+	spawner, step, waiter := newIssue("Spawner"), newIssue("Spawned step"), newIssue("Waiter")
+	create(t, s, spawner, step, waiter)
+	depend(t, s, step.ID, spawner.ID, types.DepParentChild)
+	meta, err := json.Marshal(types.WaitsForMeta{Gate: types.WaitsForAllChildren})
+	if err != nil {
+		t.Fatalf("failed to marshal waits-for metadata: %v", err)
+	}
+	waitsFor := &types.Dependency{IssueID: waiter.ID, DependsOnID: spawner.ID, Type: types.DepWaitsFor, Metadata: string(meta)}
+	if err := s.AddDependency(ctx, waitsFor, "tester"); err != nil {
+		t.Fatalf("AddDependency(waits-for) failed: %v", err)
+	}
+	if idSet(ready(t, s, types.WorkFilter{}))[waiter.ID] {
+		t.Error("waits-for dependent must wait for open children of the spawner")
+	}
+	if err := s.CloseIssue(ctx, step.ID, "done", "tester", ""); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	if !idSet(ready(t, s, types.WorkFilter{}))[waiter.ID] {
+		t.Error("waits-for dependent must be ready once all spawned children close")
+	}
+}
+
+func testBlockedIssues(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	b1, b2 := newIssue("Blocker one"), newIssue("Blocker two")
+	blocked := newIssue("Blocked twice")
+	blocked.Priority = 1
+	statusOnly := newIssue("Marked blocked")
+	statusOnly.Status = types.StatusBlocked
+	statusOnly.Priority = 3
+	pinned := newIssue("Pinned and blocked")
+	pinned.Pinned = true
+	epic := newIssue("Epic")
+	epic.IssueType = types.TypeEpic
+	create(t, s, b1, b2, blocked, statusOnly, pinned, epic)
+	depend(t, s, blocked.ID, b1.ID, types.DepBlocks)
+	depend(t, s, blocked.ID, b2.ID, types.DepBlocks)
+	depend(t, s, pinned.ID, b1.ID, types.DepBlocks)
+	depend(t, s, statusOnly.ID, epic.ID, types.DepParentChild)
+
+	got, err := s.GetBlockedIssues(ctx, types.WorkFilter{})
+	if err != nil {
+		t.Fatalf("GetBlockedIssues failed: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != blocked.ID || got[1].ID != statusOnly.ID {
+		var gotIDs []string
+		for _, b := range got {
+			gotIDs = append(gotIDs, b.ID)
+		}
+		t.Fatalf("GetBlockedIssues = %v, want [%s %s] (priority order, pinned excluded)", gotIDs, blocked.ID, statusOnly.ID)
+	}
+	if got[0].BlockedByCount != 2 || len(got[0].BlockedBy) != 2 {
+		t.Errorf("BlockedBy = %v (count %d), want both blockers", got[0].BlockedBy, got[0].BlockedByCount)
+	}
+	if got[1].BlockedByCount != 0 {
+		t.Errorf("status-only blocked issue BlockedByCount = %d, want 0", got[1].BlockedByCount)
+	}
+
+	byParent, err := s.GetBlockedIssues(ctx, types.WorkFilter{ParentID: &epic.ID})
+	if err != nil {
+		t.Fatalf("GetBlockedIssues(parent) failed: %v", err)
+	}
+	if len(byParent) != 1 || byParent[0].ID != statusOnly.ID {
+		t.Errorf("GetBlockedIssues(parent) returned %d issues, want only %s", len(byParent), statusOnly.ID)
+	}
+
+	if err := s.CloseIssue(ctx, b1.ID, "done", "tester", ""); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	got, _ = s.GetBlockedIssues(ctx, types.WorkFilter{})
+	if len(got) == 0 || got[0].ID != blocked.ID || got[0].BlockedByCount != 1 || got[0].BlockedBy[0] != b2.ID {
+		t.Errorf("after closing one blocker, GetBlockedIssues[0] = %+v, want blocked only by %s", got, b2.ID)
+	}
+}
+
+func testNewlyUnblockedByClose(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	target := newIssue("Target")
+	other := newIssue("Other blocker")
+	soloHigh := newIssue("Only blocked by target (P0)")
+	soloHigh.Priority = 0
+	soloLow := newIssue("Only blocked by target (P3)")
+	soloLow.Priority = 3
+	shared := newIssue("Also blocked by other")
+	pinned := newIssue("Pinned")
+	pinned.Pinned = true
+	create(t, s, target, other, soloHigh, soloLow, shared, pinned)
+	depend(t, s, soloHigh.ID, target.ID, types.DepBlocks)
+	depend(t, s, soloLow.ID, target.ID, types.DepBlocks)
+	depend(t, s, shared.ID, target.ID, types.DepBlocks)
+	depend(t, s, shared.ID, other.ID, types.DepBlocks)
+	depend(t, s, pinned.ID, target.ID, types.DepBlocks)
+
+	if err := s.CloseIssue(ctx, target.ID, "done", "tester", ""); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	got, err := s.GetNewlyUnblockedByClose(ctx, target.ID)
+	if err != nil {
+		t.Fatalf("GetNewlyUnblockedByClose failed: %v", err)
+	}
+	expectIDs(t, "GetNewlyUnblockedByClose", got, soloHigh.ID, soloLow.ID)
+}
+
+func testEpicsEligibleForClosure(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	done := newIssue("Finished epic")
+	done.IssueType = types.TypeEpic
+	done.Priority = 1
+	partial := newIssue("Partial epic")
+	partial.IssueType = types.TypeEpic
+	partial.Priority = 2
+	empty := newIssue("Empty epic")
+	empty.IssueType = types.TypeEpic
+	empty.Priority = 3
+	closedEpic := newIssue("Closed epic")
+	closedEpic.IssueType = types.TypeEpic
+	c1, c2, c3 := newIssue("Done child"), newIssue("Done child 2"), newIssue("Open child")
+	create(t, s, done, partial, empty, closedEpic, c1, c2, c3)
+	depend(t, s, c1.ID, done.ID, types.DepParentChild)
+	depend(t, s, c2.ID, partial.ID, types.DepParentChild)
+	depend(t, s, c3.ID, partial.ID, types.DepParentChild)
+	for _, id := range []string{c1.ID, c2.ID, closedEpic.ID} {
+		if err := s.CloseIssue(ctx, id, "done", "tester", ""); err != nil {
+			t.Fatalf("CloseIssue failed: %v", err)
+		}
+	}
+
+	epics, err := s.GetEpicsEligibleForClosure(ctx)
+	if err != nil {
+		t.Fatalf("GetEpicsEligibleForClosure failed: %v", err)
+	}
+	if len(epics) != 3 {
+		t.Fatalf("GetEpicsEligibleForClosure returned %d epics, want the 3 open ones", len(epics))
+	}
+	want := []struct {
+		id            string
+		total, closed int
+		eligible      bool
+	}{
+		{done.ID, 1, 1, true},
+		{partial.ID, 2, 1, false},
+		{empty.ID, 0, 0, false},
+	}
+	for i, w := range want {
+		e := epics[i]
+		if e.Epic.ID != w.id || e.TotalChildren != w.total || e.ClosedChildren != w.closed || e.EligibleForClose != w.eligible {
+			t.Errorf("epic %d = {%s total=%d closed=%d eligible=%v}, want {%s total=%d closed=%d eligible=%v}",
+				i, e.Epic.ID, e.TotalChildren, e.ClosedChildren, e.EligibleForClose, w.id, w.total, w.closed, w.eligible)
+		}
+	}
+}
+
+func testStaleIssues(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	fresh := newIssue("Fresh")
+	oldest := newIssue("Untouched for a month")
+	oldest.CreatedAt = ago(30 * 24 * time.Hour)
+	oldest.UpdatedAt = oldest.CreatedAt
+	older := newIssue("Untouched for two weeks")
+	older.Status = types.StatusInProgress
+	older.CreatedAt = ago(14 * 24 * time.Hour)
+	older.UpdatedAt = older.CreatedAt
+	oldClosed := newIssue("Closed long ago")
+	oldClosed.Status = types.StatusClosed
+	oldClosed.CreatedAt = ago(40 * 24 * time.Hour)
+	oldClosed.UpdatedAt = oldClosed.CreatedAt
+	closedAt := oldClosed.CreatedAt.Add(time.Hour)
+	oldClosed.ClosedAt = &closedAt
+	create(t, s, fresh, oldest, older, oldClosed)
+
+	stale, err := s.GetStaleIssues(ctx, types.StaleFilter{Days: 7})
+	if err != nil {
+		t.Fatalf("GetStaleIssues failed: %v", err)
+	}
+	expectIDs(t, "stale (oldest first, closed excluded)", stale, oldest.ID, older.ID)
+
+	stale, _ = s.GetStaleIssues(ctx, types.StaleFilter{Days: 7, Status: string(types.StatusInProgress)})
+	expectIDs(t, "stale by status", stale, older.ID)
+
+	stale, _ = s.GetStaleIssues(ctx, types.StaleFilter{Days: 7, Limit: 1})
+	expectIDs(t, "stale with limit", stale, oldest.ID)
+
+	stale, _ = s.GetStaleIssues(ctx, types.StaleFilter{Days: 60})
+	expectIDs(t, "nothing older than 60 days", stale)
+}
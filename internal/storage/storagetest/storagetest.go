@@ -0,0 +1,223 @@
+// Package storagetest provides a conformance test suite for storage.Storage
+// implementations.
+//
+// The SQLite backend is the reference implementation. Every other backend
+// (memory, turso, or a third-party one) proves compatibility by running the
+// same suite from its own tests:
+//
+//	func TestConformance(t *testing.T) {
+//	    storagetest.Run(t, func(t *testing.T) storage.Storage {
+//	        store, err := mybackend.New(t.TempDir())
+//	        if err != nil {
+//	            t.Fatalf("failed to create store: %v", err)
+//	        }
+//	        t.Cleanup(func() { _ = store.Close() })
+//	        return store
+//	    })
+//	}
+//
+// The suite covers issue CRUD, search filters, dependencies and cycle
+// prevention, labels, ready-work ordering and blocked-cache semantics, epics,
+// comments and events, statistics, dirty/export tracking, hierarchical child
+// IDs, config (including custom statuses and types), metadata, prefix renames
+// and transactions.
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// Prefix is the issue_prefix configured on every store before a subtest runs.
+const Prefix = "bd"
+
+// Factory returns a new, empty store. It is called once per subtest, so each
+// subtest starts from a clean slate. The factory is responsible for closing
+// the store (typically via t.Cleanup).
+type Factory func(t *testing.T) storage.Storage
+
+// Run executes the full conformance suite against stores produced by newStore.
+func Run(t *testing.T, newStore Factory) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s storage.Storage)
+	}{
+		{"CreateAndGetIssue", testCreateAndGetIssue},
+		{"CreateIssueValidation", testCreateIssueValidation},
+		{"CreateIssues", testCreateIssues},
+		{"ExternalRef", testExternalRef},
+		{"UpdateIssue", testUpdateIssue},
+		{"CloseAndReopen", testCloseAndReopen},
+		{"DeleteIssue", testDeleteIssue},
+		{"TombstoneFiltering", testTombstoneFiltering},
+		{"SearchFilters", testSearchFilters},
+		{"SearchOrdering", testSearchOrdering},
+		{"Dependencies", testDependencies},
+		{"DependencyValidation", testDependencyValidation},
+		{"DependencyTree", testDependencyTree},
+		{"DetectCycles", testDetectCycles},
+		{"Labels", testLabels},
+		{"ReadyWorkFiltering", testReadyWorkFiltering},
+		{"ReadyWorkOrdering", testReadyWorkOrdering},
+		{"BlockedSemantics", testBlockedSemantics},
+		{"ConditionalBlocks", testConditionalBlocks},
+		{"BlockedIssues", testBlockedIssues},
+		{"NewlyUnblockedByClose", testNewlyUnblockedByClose},
+		{"EpicsEligibleForClosure", testEpicsEligibleForClosure},
+		{"StaleIssues", testStaleIssues},
+		{"CommentsAndEvents", testCommentsAndEvents},
+		{"Attachments", testAttachments},
+		{"Statistics", testStatistics},
+		{"MoleculeProgress", testMoleculeProgress},
+		{"DirtyTracking", testDirtyTracking},
+		{"ExportHashes", testExportHashes},
+		{"NextChildID", testNextChildID},
+		{"Config", testConfig},
+		{"CustomStatusesAndTypes", testCustomStatusesAndTypes},
+		{"Metadata", testMetadata},
+		{"UpdateIssueID", testUpdateIssueID},
+		{"RenameDependencyPrefix", testRenameDependencyPrefix},
+		{"TransactionCommit", testTransactionCommit},
+		{"TransactionRollback", testTransactionRollback},
+		{"TransactionPanic", testTransactionPanic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			if err := s.SetConfig(context.Background(), "issue_prefix", Prefix); err != nil {
+				t.Fatalf("failed to set issue_prefix: %v", err)
+			}
+			tt.fn(t, s)
+		})
+	}
+}
+
+// newIssue returns a valid open task with the given title.
+func newIssue(title string) *types.Issue {
+	return &types.Issue{
+		Title:     title,
+		Status:    types.StatusOpen,
+		Priority:  2,
+		IssueType: types.TypeTask,
+	}
+}
+
+// create stores each issue and fails the test on error. IDs are assigned by
+// the backend unless already set.
+func create(t *testing.T, s storage.Storage, issues ...*types.Issue) {
+	t.Helper()
+	for _, issue := range issues {
+		if err := s.CreateIssue(context.Background(), issue, "tester"); err != nil {
+			t.Fatalf("CreateIssue(%q) failed: %v", issue.Title, err)
+		}
+	}
+}
+
+// depend records that issueID depends on dependsOnID with the given type.
+func depend(t *testing.T, s storage.Storage, issueID, dependsOnID string, depType types.DependencyType) {
+	t.Helper()
+	dep := &types.Dependency{IssueID: issueID, DependsOnID: dependsOnID, Type: depType}
+	if err := s.AddDependency(context.Background(), dep, "tester"); err != nil {
+		t.Fatalf("AddDependency(%s -> %s, %s) failed: %v", issueID, dependsOnID, depType, err)
+	}
+}
+
+// mustGet fetches an issue that is expected to exist.
+func mustGet(t *testing.T, s storage.Storage, id string) *types.Issue {
+	t.Helper()
+	issue, err := s.GetIssue(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetIssue(%s) failed: %v", id, err)
+	}
+	if issue == nil {
+		t.Fatalf("GetIssue(%s) returned nil", id)
+	}
+	return issue
+}
+
+// search runs SearchIssues and fails the test on error.
+func search(t *testing.T, s storage.Storage, query string, filter types.IssueFilter) []*types.Issue {
+	t.Helper()
+	issues, err := s.SearchIssues(context.Background(), query, filter)
+	if err != nil {
+		t.Fatalf("SearchIssues(%q) failed: %v", query, err)
+	}
+	return issues
+}
+
+// ready runs GetReadyWork and fails the test on error.
+func ready(t *testing.T, s storage.Storage, filter types.WorkFilter) []*types.Issue {
+	t.Helper()
+	issues, err := s.GetReadyWork(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetReadyWork failed: %v", err)
+	}
+	return issues
+}
+
+// ids returns the IDs of issues in order.
+func ids(issues []*types.Issue) []string {
+	out := make([]string, len(issues))
+	for i, issue := range issues {
+		out[i] = issue.ID
+	}
+	return out
+}
+
+// idSet returns the IDs of issues as a set.
+func idSet(issues []*types.Issue) map[string]bool {
+	out := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		out[issue.ID] = true
+	}
+	return out
+}
+
+// expectIDs fails the test unless got holds exactly want, in order.
+func expectIDs(t *testing.T, what string, got []*types.Issue, want ...string) {
+	t.Helper()
+	gotIDs := ids(got)
+	if len(gotIDs) != len(want) {
+		t.Errorf("%s = %v, want %v", what, gotIDs, want)
+		return
+	}
+	for i := range want {
+		if gotIDs[i] != want[i] {
+			t.Errorf("%s = %v, want %v", what, gotIDs, want)
+			return
+		}
+	}
+}
+
+// expectIDSet fails the test unless got holds exactly want, in any order.
+func expectIDSet(t *testing.T, what string, got []*types.Issue, want ...string) {
+	t.Helper()
+	set := idSet(got)
+	if len(set) != len(want) || len(got) != len(want) {
+		t.Errorf("%s = %v, want %v (any order)", what, ids(got), want)
+		return
+	}
+	for _, id := range want {
+		if !set[id] {
+			t.Errorf("%s = %v, want %v (any order)", what, ids(got), want)
+			return
+		}
+	}
+}
+
+// ago returns a UTC timestamp d in the past, truncated to the second so it
+// survives backends that store second precision.
+func ago(d time.Duration) time.Time {
+	return time.Now().Add(-d).UTC().Truncate(time.Second)
+}
+
+func intPtr(v int) *int       { return &v }
+func strPtr(v string) *string { return &v }
+func boolPtr(v bool) *bool    { return &v }
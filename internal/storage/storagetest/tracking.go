@@ -0,0 +1,612 @@
+package storagetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func testCommentsAndEvents(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	a, b := newIssue("A"), newIssue("B")
+	create(t, s, a, b)
+
+	first, err := s.AddIssueComment(ctx, a.ID, "alice", "first")
+	if err != nil {
+		t.Fatalf("AddIssueComment failed: %v", err)
+	}
+	if first.ID == 0 || first.IssueID != a.ID || first.Author != "alice" || first.Text != "first" || first.CreatedAt.IsZero() {
+		t.Errorf("AddIssueComment returned %+v", first)
+	}
+	second, err := s.AddIssueComment(ctx, a.ID, "bob", "second")
+	if err != nil {
+		t.Fatalf("AddIssueComment failed: %v", err)
+	}
+	if second.ID == first.ID {
+		t.Error("comment IDs must be unique")
+	}
+	if _, err := s.AddIssueComment(ctx, b.ID, "carol", "other"); err != nil {
+		t.Fatalf("AddIssueComment failed: %v", err)
+	}
+	if _, err := s.AddIssueComment(ctx, Prefix+"-missing", "alice", "nope"); err == nil {
+		t.Error("AddIssueComment on a missing issue should fail")
+	}
+
+	comments, err := s.GetIssueComments(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("GetIssueComments failed: %v", err)
+	}
+	if len(comments) != 2 || comments[0].Text != "first" || comments[1].Text != "second" {
+		t.Errorf("GetIssueComments = %+v, want [first second]", comments)
+	}
+
+	byIssue, err := s.GetCommentsForIssues(ctx, []string{a.ID, b.ID})
+	if err != nil {
+		t.Fatalf("GetCommentsForIssues failed: %v", err)
+	}
+	if len(byIssue[a.ID]) != 2 || len(byIssue[b.ID]) != 1 {
+		t.Errorf("GetCommentsForIssues counts = %d/%d, want 2/1", len(byIssue[a.ID]), len(byIssue[b.ID]))
+	}
+
+	// Events: creation, updates and comment events are all recorded.
+	if err := s.UpdateIssue(ctx, a.ID, map[string]interface{}{"title": "A2"}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := s.AddComment(ctx, a.ID, "tester", "audit note"); err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+	if err := s.AddComment(ctx, Prefix+"-missing", "tester", "nope"); err == nil {
+		t.Error("AddComment on a missing issue should fail")
+	}
+
+	events, err := s.GetEvents(ctx, a.ID, 0)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	seen := map[types.EventType]bool{}
+	for _, e := range events {
+		if e.IssueID != a.ID {
+			t.Errorf("event for %s returned for %s", e.IssueID, a.ID)
+		}
+		seen[e.EventType] = true
+		if e.EventType == types.EventCommented && (e.Comment == nil || *e.Comment != "audit note") {
+			t.Errorf("commented event text = %v, want audit note", e.Comment)
+		}
+	}
+	for _, want := range []types.EventType{types.EventCreated, types.EventUpdated, types.EventCommented} {
+		if !seen[want] {
+			t.Errorf("GetEvents missing %s event, got %v", want, seen)
+		}
+	}
+
+	// Events share second-resolution timestamps, so only the limit is portable.
+	if latest, err := s.GetEvents(ctx, a.ID, 1); err != nil || len(latest) != 1 {
+		t.Errorf("GetEvents(limit=1) = %d events, %v; want 1", len(latest), err)
+	}
+}
+
+func testAttachments(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	a, b := newIssue("A"), newIssue("B")
+	create(t, s, a, b)
+	if err := s.ClearDirtyIssuesByID(ctx, []string{a.ID, b.ID}); err != nil {
+		t.Fatalf("ClearDirtyIssuesByID failed: %v", err)
+	}
+
+	log := &types.Attachment{IssueID: a.ID, Name: "run.log", Size: 12, SHA256: strings.Repeat("1", 64), MimeType: "text/plain"}
+	if err := s.AddAttachment(ctx, log, "alice"); err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+	shot := &types.Attachment{IssueID: a.ID, Name: "after.png", Size: 99, SHA256: strings.Repeat("2", 64), MimeType: "image/png"}
+	if err := s.AddAttachment(ctx, shot, "alice"); err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+	if err := s.AddAttachment(ctx, &types.Attachment{IssueID: b.ID, Name: "run.log", SHA256: strings.Repeat("1", 64)}, "bob"); err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+	if err := s.AddAttachment(ctx, &types.Attachment{IssueID: Prefix + "-missing", Name: "x", SHA256: "x"}, "alice"); err == nil {
+		t.Error("AddAttachment on a missing issue should fail")
+	}
+	if dirty, _ := s.GetDirtyIssues(ctx); !contains(dirty, a.ID) {
+		t.Errorf("AddAttachment must mark the issue dirty, got %v", dirty)
+	}
+
+	got, err := s.GetAttachments(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("GetAttachments failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "after.png" || got[1].Name != "run.log" {
+		t.Fatalf("GetAttachments = %+v, want [after.png run.log]", got)
+	}
+	if got[1].Size != 12 || got[1].SHA256 != log.SHA256 || got[1].MimeType != "text/plain" ||
+		got[1].CreatedBy != "alice" || got[1].CreatedAt.IsZero() {
+		t.Errorf("GetAttachments returned %+v", got[1])
+	}
+
+	// Re-adding a name replaces the attachment
+	replaced := &types.Attachment{IssueID: a.ID, Name: "run.log", Size: 30, SHA256: strings.Repeat("3", 64), MimeType: "text/plain"}
+	if err := s.AddAttachment(ctx, replaced, "bob"); err != nil {
+		t.Fatalf("AddAttachment (replace) failed: %v", err)
+	}
+	got, _ = s.GetAttachments(ctx, a.ID)
+	if len(got) != 2 || got[1].SHA256 != replaced.SHA256 || got[1].Size != 30 || got[1].CreatedBy != "bob" {
+		t.Errorf("after replace GetAttachments = %+v", got)
+	}
+
+	all, err := s.GetAllAttachments(ctx)
+	if err != nil {
+		t.Fatalf("GetAllAttachments failed: %v", err)
+	}
+	if len(all[a.ID]) != 2 || len(all[b.ID]) != 1 {
+		t.Errorf("GetAllAttachments counts = %d/%d, want 2/1", len(all[a.ID]), len(all[b.ID]))
+	}
+
+	if err := s.RemoveAttachment(ctx, a.ID, "after.png", "alice"); err != nil {
+		t.Fatalf("RemoveAttachment failed: %v", err)
+	}
+	if err := s.RemoveAttachment(ctx, a.ID, "after.png", "alice"); err == nil {
+		t.Error("RemoveAttachment of a missing attachment should fail")
+	}
+	if got, _ := s.GetAttachments(ctx, a.ID); len(got) != 1 || got[0].Name != "run.log" {
+		t.Errorf("after remove GetAttachments = %+v, want [run.log]", got)
+	}
+
+	// Attachments follow a rename and go away with the issue
+	oldID := a.ID
+	newID := Prefix + "-attached"
+	if err := s.UpdateIssueID(ctx, oldID, newID, a, "tester"); err != nil {
+		t.Fatalf("UpdateIssueID failed: %v", err)
+	}
+	if got, _ := s.GetAttachments(ctx, newID); len(got) != 1 || got[0].IssueID != newID {
+		t.Errorf("attachments after rename = %+v, want one on %s", got, newID)
+	}
+	if err := s.DeleteIssue(ctx, b.ID); err != nil {
+		t.Fatalf("DeleteIssue failed: %v", err)
+	}
+	if got, _ := s.GetAttachments(ctx, b.ID); len(got) != 0 {
+		t.Errorf("attachments of a deleted issue = %+v, want none", got)
+	}
+}
+
+func testStatistics(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	blocker := newIssue("Blocker")
+	blocked := newIssue("Blocked")
+	working := newIssue("Working")
+	working.Status = types.StatusInProgress
+	done := newIssue("Done")
+	deferredIssue := newIssue("On ice")
+	deferredIssue.Status = types.StatusDeferred
+	pinned := newIssue("Pinned")
+	pinned.Pinned = true
+	epic := newIssue("Epic")
+	epic.IssueType = types.TypeEpic
+	create(t, s, blocker, blocked, working, done, deferredIssue, pinned, epic)
+	depend(t, s, blocked.ID, blocker.ID, types.DepBlocks)
+	depend(t, s, done.ID, epic.ID, types.DepParentChild)
+	if err := s.CloseIssue(ctx, done.ID, "done", "tester", ""); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+
+	stats, err := s.GetStatistics(ctx)
+	if err != nil {
+		t.Fatalf("GetStatistics failed: %v", err)
+	}
+	want := types.Statistics{
+		TotalIssues:             7,
+		OpenIssues:              4, // blocker, blocked, pinned, epic
+		InProgressIssues:        1,
+		ClosedIssues:            1,
+		DeferredIssues:          1,
+		BlockedIssues:           1,
+		ReadyIssues:             3, // blocker, pinned, epic
+		PinnedIssues:            1,
+		EpicsEligibleForClosure: 1,
+	}
+	got := *stats
+	got.AverageLeadTime = 0
+	if got != want {
+		t.Errorf("GetStatistics = %+v\nwant %+v", got, want)
+	}
+	if stats.AverageLeadTime < 0 {
+		t.Errorf("AverageLeadTime = %v, want >= 0", stats.AverageLeadTime)
+	}
+}
+
+func testMoleculeProgress(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	mol := newIssue("Molecule")
+	mol.IssueType = types.TypeMolecule
+	done, working, todo := newIssue("Step done"), newIssue("Step working"), newIssue("Step todo")
+	create(t, s, mol, done, working, todo)
+	for _, step := range []*types.Issue{done, working, todo} {
+		depend(t, s, step.ID, mol.ID, types.DepParentChild)
+	}
+	if err := s.CloseIssue(ctx, done.ID, "done", "tester", ""); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	if err := s.UpdateIssue(ctx, working.ID, map[string]interface{}{"status": string(types.StatusInProgress)}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+
+	progress, err := s.GetMoleculeProgress(ctx, mol.ID)
+	if err != nil {
+		t.Fatalf("GetMoleculeProgress failed: %v", err)
+	}
+	if progress.MoleculeID != mol.ID || progress.MoleculeTitle != "Molecule" {
+		t.Errorf("progress identity = %s/%q", progress.MoleculeID, progress.MoleculeTitle)
+	}
+	if progress.Total != 3 || progress.Completed != 1 || progress.InProgress != 1 || progress.CurrentStepID != working.ID {
+		t.Errorf("progress = %+v, want total=3 completed=1 in_progress=1 current=%s", progress, working.ID)
+	}
+	if progress.FirstClosed == nil || progress.LastClosed == nil {
+		t.Error("FirstClosed/LastClosed must be set once a step has closed")
+	}
+
+	if _, err := s.GetMoleculeProgress(ctx, Prefix+"-missing"); err == nil {
+		t.Error("GetMoleculeProgress on a missing molecule should fail")
+	}
+}
+
+func testDirtyTracking(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	a, b := newIssue("A"), newIssue("B")
+	create(t, s, a, b)
+
+	dirty, err := s.GetDirtyIssues(ctx)
+	if err != nil {
+		t.Fatalf("GetDirtyIssues failed: %v", err)
+	}
+	if !contains(dirty, a.ID) || !contains(dirty, b.ID) {
+		t.Errorf("GetDirtyIssues = %v, want both new issues", dirty)
+	}
+	if _, err := s.GetDirtyIssueHash(ctx, a.ID); err != nil {
+		t.Errorf("GetDirtyIssueHash failed: %v", err)
+	}
+
+	if err := s.ClearDirtyIssuesByID(ctx, []string{a.ID, b.ID}); err != nil {
+		t.Fatalf("ClearDirtyIssuesByID failed: %v", err)
+	}
+	if dirty, _ := s.GetDirtyIssues(ctx); len(dirty) != 0 {
+		t.Errorf("GetDirtyIssues after clear = %v, want none", dirty)
+	}
+
+	mutations := map[string]func() error{
+		"UpdateIssue": func() error {
+			return s.UpdateIssue(ctx, a.ID, map[string]interface{}{"title": "A2"}, "tester")
+		},
+		"AddLabel": func() error { return s.AddLabel(ctx, a.ID, "l", "tester") },
+		"AddDependency": func() error {
+			return s.AddDependency(ctx, &types.Dependency{IssueID: a.ID, DependsOnID: b.ID, Type: types.DepRelated}, "tester")
+		},
+		"AddIssueComment": func() error { _, err := s.AddIssueComment(ctx, a.ID, "tester", "c"); return err },
+	}
+	for name, mutate := range mutations {
+		if err := s.ClearDirtyIssuesByID(ctx, []string{a.ID, b.ID}); err != nil {
+			t.Fatalf("ClearDirtyIssuesByID failed: %v", err)
+		}
+		if err := mutate(); err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if dirty, _ := s.GetDirtyIssues(ctx); !contains(dirty, a.ID) {
+			t.Errorf("%s must mark the issue dirty, got %v", name, dirty)
+		}
+	}
+}
+
+func testExportHashes(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	issue := newIssue("Exported")
+	create(t, s, issue)
+
+	if hash, err := s.GetExportHash(ctx, issue.ID); err != nil || hash != "" {
+		t.Errorf("GetExportHash before set = %q, %v; want empty", hash, err)
+	}
+	if err := s.SetExportHash(ctx, issue.ID, "abc123"); err != nil {
+		t.Fatalf("SetExportHash failed: %v", err)
+	}
+	if hash, err := s.GetExportHash(ctx, issue.ID); err != nil || hash != "abc123" {
+		t.Errorf("GetExportHash = %q, %v; want abc123", hash, err)
+	}
+	if err := s.SetExportHash(ctx, issue.ID, "def456"); err != nil {
+		t.Fatalf("SetExportHash (overwrite) failed: %v", err)
+	}
+	if hash, _ := s.GetExportHash(ctx, issue.ID); hash != "def456" {
+		t.Errorf("GetExportHash after overwrite = %q, want def456", hash)
+	}
+	if err := s.ClearAllExportHashes(ctx); err != nil {
+		t.Fatalf("ClearAllExportHashes failed: %v", err)
+	}
+	if hash, _ := s.GetExportHash(ctx, issue.ID); hash != "" {
+		t.Errorf("GetExportHash after clear = %q, want empty", hash)
+	}
+
+	if hash, err := s.GetJSONLFileHash(ctx); err != nil || hash != "" {
+		t.Errorf("GetJSONLFileHash before set = %q, %v; want empty", hash, err)
+	}
+	if err := s.SetJSONLFileHash(ctx, "filehash"); err != nil {
+		t.Fatalf("SetJSONLFileHash failed: %v", err)
+	}
+	if hash, err := s.GetJSONLFileHash(ctx); err != nil || hash != "filehash" {
+		t.Errorf("GetJSONLFileHash = %q, %v; want filehash", hash, err)
+	}
+}
+
+func testNextChildID(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	parent := newIssue("Parent")
+	parent.ID = Prefix + "-parent"
+	create(t, s, parent)
+
+	for _, want := range []string{parent.ID + ".1", parent.ID + ".2"} {
+		got, err := s.GetNextChildID(ctx, parent.ID)
+		if err != nil {
+			t.Fatalf("GetNextChildID failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("GetNextChildID = %s, want %s", got, want)
+		}
+	}
+
+	// Children created with explicit IDs advance the counter (GH#728).
+	explicit := newIssue("Explicit child")
+	explicit.ID = parent.ID + ".7"
+	create(t, s, explicit)
+	if got, _ := s.GetNextChildID(ctx, parent.ID); got != parent.ID+".8" {
+		t.Errorf("GetNextChildID after explicit .7 = %s, want %s.8", got, parent.ID)
+	}
+
+	// Counters are per parent.
+	if got, _ := s.GetNextChildID(ctx, explicit.ID); got != explicit.ID+".1" {
+		t.Errorf("GetNextChildID(%s) = %s, want %s.1", explicit.ID, got, explicit.ID)
+	}
+
+	if _, err := s.GetNextChildID(ctx, Prefix+"-missing"); err == nil {
+		t.Error("GetNextChildID on a missing parent should fail")
+	}
+
+	deep := newIssue("Depth three")
+	deep.ID = Prefix + "-parent.7.1.1"
+	mid := newIssue("Depth two")
+	mid.ID = Prefix + "-parent.7.1"
+	create(t, s, mid, deep)
+	if _, err := s.GetNextChildID(ctx, deep.ID); err == nil {
+		t.Error("GetNextChildID beyond the maximum hierarchy depth should fail")
+	}
+}
+
+func testConfig(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	if v, err := s.GetConfig(ctx, "issue_prefix"); err != nil || v != Prefix {
+		t.Fatalf("GetConfig(issue_prefix) = %q, %v; want %q", v, err, Prefix)
+	}
+	if v, err := s.GetConfig(ctx, "missing.key"); err != nil || v != "" {
+		t.Errorf("GetConfig(missing) = %q, %v; want empty, nil", v, err)
+	}
+
+	if err := s.SetConfig(ctx, "sync.branch", "beads-sync"); err != nil {
+		t.Fatalf("SetConfig failed: %v", err)
+	}
+	if err := s.SetConfig(ctx, "sync.branch", "main"); err != nil {
+		t.Fatalf("SetConfig (overwrite) failed: %v", err)
+	}
+	if v, _ := s.GetConfig(ctx, "sync.branch"); v != "main" {
+		t.Errorf("GetConfig after overwrite = %q, want main", v)
+	}
+
+	all, err := s.GetAllConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetAllConfig failed: %v", err)
+	}
+	if all["issue_prefix"] != Prefix || all["sync.branch"] != "main" {
+		t.Errorf("GetAllConfig = %v", all)
+	}
+	all["sync.branch"] = "mutated"
+	if v, _ := s.GetConfig(ctx, "sync.branch"); v != "main" {
+		t.Error("mutating the GetAllConfig result must not change the store")
+	}
+
+	if err := s.DeleteConfig(ctx, "sync.branch"); err != nil {
+		t.Fatalf("DeleteConfig failed: %v", err)
+	}
+	if v, _ := s.GetConfig(ctx, "sync.branch"); v != "" {
+		t.Errorf("GetConfig after delete = %q, want empty", v)
+	}
+	if err := s.DeleteConfig(ctx, "never.set"); err != nil {
+		t.Errorf("DeleteConfig of a missing key should be a no-op, got %v", err)
+	}
+}
+
+func testCustomStatusesAndTypes(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	if statuses, err := s.GetCustomStatuses(ctx); err != nil || len(statuses) != 0 {
+		t.Errorf("GetCustomStatuses before config = %v, %v; want none", statuses, err)
+	}
+	if issueTypes, err := s.GetCustomTypes(ctx); err != nil || len(issueTypes) != 0 {
+		t.Errorf("GetCustomTypes before config = %v, %v; want none", issueTypes, err)
+	}
+
+	issue := newIssue("Needs review")
+	create(t, s, issue)
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"status": "review"}, "tester"); err == nil {
+		t.Error("UpdateIssue to an unconfigured custom status should fail")
+	}
+
+	if err := s.SetConfig(ctx, "status.custom", "review, qa ,,"); err != nil {
+		t.Fatalf("SetConfig(status.custom) failed: %v", err)
+	}
+	if err := s.SetConfig(ctx, "types.custom", "spike,incident"); err != nil {
+		t.Fatalf("SetConfig(types.custom) failed: %v", err)
+	}
+
+	statuses, err := s.GetCustomStatuses(ctx)
+	if err != nil || len(statuses) != 2 || statuses[0] != "review" || statuses[1] != "qa" {
+		t.Errorf("GetCustomStatuses = %v, %v; want [review qa]", statuses, err)
+	}
+	issueTypes, err := s.GetCustomTypes(ctx)
+	if err != nil || len(issueTypes) != 2 || issueTypes[0] != "spike" || issueTypes[1] != "incident" {
+		t.Errorf("GetCustomTypes = %v, %v; want [spike incident]", issueTypes, err)
+	}
+
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"status": "review"}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue to a configured custom status failed: %v", err)
+	}
+	if got := mustGet(t, s, issue.ID); got.Status != "review" {
+		t.Errorf("Status = %s, want review", got.Status)
+	}
+	reviewStatus := types.Status("review")
+	expectIDSet(t, "search by custom status", search(t, s, "", types.IssueFilter{Status: &reviewStatus}), issue.ID)
+	if idSet(ready(t, s, types.WorkFilter{}))[issue.ID] {
+		t.Error("issues in a custom status are not ready work by default")
+	}
+
+	custom := newIssue("Custom from the start")
+	custom.Status = "qa"
+	custom.IssueType = "spike"
+	create(t, s, custom)
+	if got := mustGet(t, s, custom.ID); got.Status != "qa" || got.IssueType != "spike" {
+		t.Errorf("custom issue = status %s type %s, want qa/spike", got.Status, got.IssueType)
+	}
+
+	unknown := newIssue("Unknown type")
+	unknown.IssueType = "saga"
+	if err := s.CreateIssue(ctx, unknown, "tester"); err == nil {
+		t.Error("CreateIssue with an unconfigured custom type should fail")
+	}
+
+	// Tombstone is never a valid target for a plain status update.
+	if err := s.UpdateIssue(ctx, issue.ID, map[string]interface{}{"status": string(types.StatusTombstone)}, "tester"); err == nil {
+		t.Error("UpdateIssue to tombstone should fail")
+	}
+}
+
+func testMetadata(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	if v, err := s.GetMetadata(ctx, "last_import_hash"); err != nil || v != "" {
+		t.Errorf("GetMetadata(missing) = %q, %v; want empty, nil", v, err)
+	}
+	if err := s.SetMetadata(ctx, "last_import_hash", "h1"); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	if err := s.SetMetadata(ctx, "last_import_hash", "h2"); err != nil {
+		t.Fatalf("SetMetadata (overwrite) failed: %v", err)
+	}
+	if v, err := s.GetMetadata(ctx, "last_import_hash"); err != nil || v != "h2" {
+		t.Errorf("GetMetadata = %q, %v; want h2", v, err)
+	}
+	// Metadata and config are separate namespaces.
+	if v, _ := s.GetConfig(ctx, "last_import_hash"); v != "" {
+		t.Errorf("metadata leaked into config: %q", v)
+	}
+}
+
+func testUpdateIssueID(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	issue, dependent, other := newIssue("Renamed"), newIssue("Dependent"), newIssue("Other")
+	create(t, s, issue, dependent, other)
+	depend(t, s, dependent.ID, issue.ID, types.DepBlocks)
+	depend(t, s, issue.ID, other.ID, types.DepRelated)
+	if err := s.AddLabel(ctx, issue.ID, "keep", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if _, err := s.AddIssueComment(ctx, issue.ID, "tester", "still here"); err != nil {
+		t.Fatalf("AddIssueComment failed: %v", err)
+	}
+
+	oldID := issue.ID
+	newID := Prefix + "-renamed"
+	issue.Title = "Renamed (new title)"
+	if err := s.UpdateIssueID(ctx, oldID, newID, issue, "tester"); err != nil {
+		t.Fatalf("UpdateIssueID failed: %v", err)
+	}
+
+	if got, _ := s.GetIssue(ctx, oldID); got != nil {
+		t.Errorf("old ID %s still resolves", oldID)
+	}
+	got := mustGet(t, s, newID)
+	if got.Title != "Renamed (new title)" {
+		t.Errorf("Title = %q, want the title from the passed issue", got.Title)
+	}
+	if labels, _ := s.GetLabels(ctx, newID); len(labels) != 1 || labels[0] != "keep" {
+		t.Errorf("labels after rename = %v, want [keep]", labels)
+	}
+	if comments, _ := s.GetIssueComments(ctx, newID); len(comments) != 1 {
+		t.Errorf("comments after rename = %d, want 1", len(comments))
+	}
+	if deps, _ := s.GetDependencies(ctx, dependent.ID); len(deps) != 1 || deps[0].ID != newID {
+		t.Errorf("dependent now depends on %v, want [%s]", ids(deps), newID)
+	}
+	if deps, _ := s.GetDependencies(ctx, newID); len(deps) != 1 || deps[0].ID != other.ID {
+		t.Errorf("renamed issue depends on %v, want [%s]", ids(deps), other.ID)
+	}
+	if dirty, _ := s.GetDirtyIssues(ctx); !contains(dirty, newID) {
+		t.Errorf("renamed issue must be dirty, got %v", dirty)
+	}
+
+	if err := s.UpdateIssueID(ctx, Prefix+"-missing", Prefix+"-other", issue, "tester"); err == nil {
+		t.Error("UpdateIssueID on a missing issue should fail")
+	}
+}
+
+func testRenameDependencyPrefix(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	a, b := newIssue("A"), newIssue("B")
+	create(t, s, a, b)
+	depend(t, s, a.ID, b.ID, types.DepBlocks)
+	oldA := a.ID
+
+	// Mirror bd rename-prefix: rename every issue, then the dependency and
+	// counter prefixes.
+	newA := "kb-" + a.ID[len(Prefix)+1:]
+	newB := "kb-" + b.ID[len(Prefix)+1:]
+	for oldID, newID := range map[string]string{a.ID: newA, b.ID: newB} {
+		issue := mustGet(t, s, oldID)
+		issue.ID = newID
+		if err := s.UpdateIssueID(ctx, oldID, newID, issue, "tester"); err != nil {
+			t.Fatalf("UpdateIssueID failed: %v", err)
+		}
+	}
+	if err := s.RenameDependencyPrefix(ctx, Prefix, "kb"); err != nil {
+		t.Fatalf("RenameDependencyPrefix failed: %v", err)
+	}
+	if err := s.RenameCounterPrefix(ctx, Prefix, "kb"); err != nil {
+		t.Fatalf("RenameCounterPrefix failed: %v", err)
+	}
+
+	all, err := s.GetAllDependencyRecords(ctx)
+	if err != nil {
+		t.Fatalf("GetAllDependencyRecords failed: %v", err)
+	}
+	deps := all[newA]
+	if len(deps) != 1 || deps[0].IssueID != newA || deps[0].DependsOnID != newB {
+		t.Errorf("dependency records after prefix rename = %v, want %s -> %s", all, newA, newB)
+	}
+	if len(all[oldA]) != 0 {
+		t.Errorf("records still keyed by old ID %s", oldA)
+	}
+	expectIDSet(t, "ready after prefix rename", ready(t, s, types.WorkFilter{}), newB)
+}
+
+func contains(list []string, want string) bool {
+	for _, v := range list {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
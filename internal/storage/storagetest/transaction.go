@@ -0,0 +1,248 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func testTransactionCommit(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	existing := newIssue("Existing")
+	create(t, s, existing)
+
+	parent, child := newIssue("Tx parent"), newIssue("Tx child")
+	batch := []*types.Issue{newIssue("Tx batch 1"), newIssue("Tx batch 2")}
+	err := s.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		if err := tx.CreateIssue(ctx, parent, "tester"); err != nil {
+			return err
+		}
+		if err := tx.CreateIssue(ctx, child, "tester"); err != nil {
+			return err
+		}
+		if err := tx.CreateIssues(ctx, batch, "tester"); err != nil {
+			return err
+		}
+
+		// Read-your-writes inside the transaction.
+		got, err := tx.GetIssue(ctx, child.ID)
+		if err != nil || got == nil {
+			t.Errorf("tx.GetIssue = %v, %v; want the new child", got, err)
+		}
+		if found, err := tx.SearchIssues(ctx, "Tx batch", types.IssueFilter{}); err != nil || len(found) != 2 {
+			t.Errorf("tx.SearchIssues = %d results, %v; want 2", len(found), err)
+		}
+
+		if err := tx.AddDependency(ctx, &types.Dependency{IssueID: child.ID, DependsOnID: parent.ID, Type: types.DepBlocks}, "tester"); err != nil {
+			return err
+		}
+		if err := tx.AddDependency(ctx, &types.Dependency{IssueID: batch[0].ID, DependsOnID: parent.ID, Type: types.DepRelated}, "tester"); err != nil {
+			return err
+		}
+		if err := tx.RemoveDependency(ctx, batch[0].ID, parent.ID, "tester"); err != nil {
+			return err
+		}
+		if err := tx.AddLabel(ctx, parent.ID, "tx", "tester"); err != nil {
+			return err
+		}
+		if err := tx.AddLabel(ctx, parent.ID, "temp", "tester"); err != nil {
+			return err
+		}
+		if err := tx.RemoveLabel(ctx, parent.ID, "temp", "tester"); err != nil {
+			return err
+		}
+		if err := tx.UpdateIssue(ctx, existing.ID, map[string]interface{}{"title": "Existing (updated)"}, "tester"); err != nil {
+			return err
+		}
+		if err := tx.CloseIssue(ctx, batch[1].ID, "done", "tester", ""); err != nil {
+			return err
+		}
+		if err := tx.DeleteIssue(ctx, batch[0].ID); err != nil {
+			return err
+		}
+		if err := tx.SetConfig(ctx, "tx.key", "tx-value"); err != nil {
+			return err
+		}
+		if v, err := tx.GetConfig(ctx, "tx.key"); err != nil || v != "tx-value" {
+			t.Errorf("tx.GetConfig = %q, %v; want tx-value", v, err)
+		}
+		if err := tx.SetMetadata(ctx, "tx.meta", "meta-value"); err != nil {
+			return err
+		}
+		if v, err := tx.GetMetadata(ctx, "tx.meta"); err != nil || v != "meta-value" {
+			t.Errorf("tx.GetMetadata = %q, %v; want meta-value", v, err)
+		}
+		return tx.AddComment(ctx, parent.ID, "tester", "created in a transaction")
+	})
+	if err != nil {
+		t.Fatalf("RunInTransaction failed: %v", err)
+	}
+
+	mustGet(t, s, parent.ID)
+	if deps, _ := s.GetDependencies(ctx, child.ID); len(deps) != 1 || deps[0].ID != parent.ID {
+		t.Errorf("committed dependency missing: %v", ids(deps))
+	}
+	if labels, _ := s.GetLabels(ctx, parent.ID); len(labels) != 1 || labels[0] != "tx" {
+		t.Errorf("committed labels = %v, want [tx]", labels)
+	}
+	if got := mustGet(t, s, existing.ID); got.Title != "Existing (updated)" {
+		t.Errorf("committed update missing: title %q", got.Title)
+	}
+	if got := mustGet(t, s, batch[1].ID); got.Status != types.StatusClosed {
+		t.Errorf("committed close missing: status %s", got.Status)
+	}
+	if got, _ := s.GetIssue(ctx, batch[0].ID); got != nil {
+		t.Error("committed delete missing")
+	}
+	if v, _ := s.GetConfig(ctx, "tx.key"); v != "tx-value" {
+		t.Errorf("committed config = %q", v)
+	}
+	if v, _ := s.GetMetadata(ctx, "tx.meta"); v != "meta-value" {
+		t.Errorf("committed metadata = %q", v)
+	}
+	expectIDSet(t, "ready after commit", ready(t, s, types.WorkFilter{}), existing.ID, parent.ID)
+}
+
+func testTransactionRollback(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	existing, blocker := newIssue("Existing"), newIssue("Blocker")
+	create(t, s, existing, blocker)
+	if err := s.AddLabel(ctx, existing.ID, "keep", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+
+	rolledBack := newIssue("Rolled back")
+	wantErr := errors.New("boom")
+	err := s.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		if err := tx.CreateIssue(ctx, rolledBack, "tester"); err != nil {
+			return err
+		}
+		if err := tx.UpdateIssue(ctx, existing.ID, map[string]interface{}{"title": "changed", "priority": 0}, "tester"); err != nil {
+			return err
+		}
+		if err := tx.AddDependency(ctx, &types.Dependency{IssueID: existing.ID, DependsOnID: blocker.ID, Type: types.DepBlocks}, "tester"); err != nil {
+			return err
+		}
+		if err := tx.RemoveLabel(ctx, existing.ID, "keep", "tester"); err != nil {
+			return err
+		}
+		if err := tx.CloseIssue(ctx, blocker.ID, "done", "tester", ""); err != nil {
+			return err
+		}
+		if err := tx.SetConfig(ctx, "issue_prefix", "zz"); err != nil {
+			return err
+		}
+		if err := tx.SetMetadata(ctx, "tx.meta", "meta-value"); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("RunInTransaction returned %v, want %v", err, wantErr)
+	}
+
+	expectRolledBack(t, s, existing, blocker, rolledBack)
+	if v, _ := s.GetMetadata(ctx, "tx.meta"); v != "" {
+		t.Errorf("metadata survived rollback: %q", v)
+	}
+
+	// A failing operation inside the transaction also rolls back earlier work.
+	partial := newIssue("Partial")
+	err = s.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		if err := tx.CreateIssue(ctx, partial, "tester"); err != nil {
+			return err
+		}
+		return tx.UpdateIssue(ctx, Prefix+"-missing", map[string]interface{}{"title": "x"}, "tester")
+	})
+	if err == nil {
+		t.Fatal("RunInTransaction should surface the failing operation's error")
+	}
+	if got, _ := s.GetIssue(ctx, partial.ID); got != nil {
+		t.Error("issue created before a failing operation survived rollback")
+	}
+}
+
+func testTransactionPanic(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	existing, blocker := newIssue("Existing"), newIssue("Blocker")
+	create(t, s, existing, blocker)
+	if err := s.AddLabel(ctx, existing.ID, "keep", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+
+	rolledBack := newIssue("Rolled back")
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want the original panic value", r)
+			}
+		}()
+		_ = s.RunInTransaction(ctx, func(tx storage.Transaction) error {
+			if err := tx.CreateIssue(ctx, rolledBack, "tester"); err != nil {
+				return err
+			}
+			if err := tx.UpdateIssue(ctx, existing.ID, map[string]interface{}{"title": "changed", "priority": 0}, "tester"); err != nil {
+				return err
+			}
+			if err := tx.AddDependency(ctx, &types.Dependency{IssueID: existing.ID, DependsOnID: blocker.ID, Type: types.DepBlocks}, "tester"); err != nil {
+				return err
+			}
+			if err := tx.RemoveLabel(ctx, existing.ID, "keep", "tester"); err != nil {
+				return err
+			}
+			if err := tx.CloseIssue(ctx, blocker.ID, "done", "tester", ""); err != nil {
+				return err
+			}
+			if err := tx.SetConfig(ctx, "issue_prefix", "zz"); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+
+	expectRolledBack(t, s, existing, blocker, rolledBack)
+
+	// The store remains usable after a rolled-back panic.
+	after := newIssue("After panic")
+	create(t, s, after)
+	mustGet(t, s, after.ID)
+}
+
+// expectRolledBack checks that none of the writes made by the rollback and
+// panic transactions above are visible.
+func expectRolledBack(t *testing.T, s storage.Storage, existing, blocker, rolledBack *types.Issue) {
+	t.Helper()
+	ctx := context.Background()
+
+	if rolledBack.ID != "" {
+		if got, _ := s.GetIssue(ctx, rolledBack.ID); got != nil {
+			t.Error("issue created in a rolled-back transaction is visible")
+		}
+	}
+	if found := search(t, s, "Rolled back", types.IssueFilter{}); len(found) != 0 {
+		t.Errorf("search finds rolled-back issues %v", ids(found))
+	}
+	got := mustGet(t, s, existing.ID)
+	if got.Title != "Existing" || got.Priority != 2 {
+		t.Errorf("update survived rollback: title %q priority %d", got.Title, got.Priority)
+	}
+	if deps, _ := s.GetDependencyRecords(ctx, existing.ID); len(deps) != 0 {
+		t.Errorf("dependency survived rollback: %v", deps)
+	}
+	if labels, _ := s.GetLabels(ctx, existing.ID); len(labels) != 1 || labels[0] != "keep" {
+		t.Errorf("label removal survived rollback: %v", labels)
+	}
+	if got := mustGet(t, s, blocker.ID); got.Status != types.StatusOpen || got.ClosedAt != nil {
+		t.Errorf("close survived rollback: status %s", got.Status)
+	}
+	if v, _ := s.GetConfig(ctx, "issue_prefix"); v != Prefix {
+		t.Errorf("config change survived rollback: issue_prefix = %q", v)
+	}
+	expectIDSet(t, "ready after rollback", ready(t, s, types.WorkFilter{}), existing.ID, blocker.ID)
+}
@@ -0,0 +1,20 @@
+package turso
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/storagetest"
+)
+
+func TestStorageConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		store, err := New(context.Background(), t.TempDir())
+		if err != nil {
+			t.Fatalf("failed to create storage: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
@@ -0,0 +1,82 @@
+package turso
+
+import (
+	"context"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// tursoTx implements storage.Transaction by applying changes to the index
+// immediately (read-your-writes) and deferring file and cache writes to commit.
+type tursoTx struct {
+	s *TursoStorage
+	w *writeSet
+}
+
+// Compile-time interface check
+var _ storage.Transaction = (*tursoTx)(nil)
+
+func (t *tursoTx) CreateIssue(ctx context.Context, issue *types.Issue, actor string) error {
+	return t.s.createIssue(ctx, t.w, issue, actor)
+}
+
+func (t *tursoTx) CreateIssues(ctx context.Context, issues []*types.Issue, actor string) error {
+	return t.s.createIssues(ctx, t.w, issues, actor)
+}
+
+func (t *tursoTx) UpdateIssue(ctx context.Context, id string, updates map[string]interface{}, actor string) error {
+	return t.s.updateIssue(ctx, t.w, id, updates, actor)
+}
+
+func (t *tursoTx) CloseIssue(ctx context.Context, id string, reason string, actor string, session string) error {
+	return t.s.closeIssue(ctx, t.w, id, reason, actor, session)
+}
+
+func (t *tursoTx) DeleteIssue(ctx context.Context, id string) error {
+	return t.s.deleteIssue(ctx, t.w, id)
+}
+
+func (t *tursoTx) GetIssue(ctx context.Context, id string) (*types.Issue, error) {
+	return t.s.MemoryStorage.GetIssue(ctx, id)
+}
+
+func (t *tursoTx) SearchIssues(ctx context.Context, query string, filter types.IssueFilter) ([]*types.Issue, error) {
+	return t.s.MemoryStorage.SearchIssues(ctx, query, filter)
+}
+
+func (t *tursoTx) AddDependency(ctx context.Context, dep *types.Dependency, actor string) error {
+	return t.s.addDependency(ctx, t.w, dep, actor)
+}
+
+func (t *tursoTx) RemoveDependency(ctx context.Context, issueID, dependsOnID string, actor string) error {
+	return t.s.removeDependency(ctx, t.w, issueID, dependsOnID, actor)
+}
+
+func (t *tursoTx) AddLabel(ctx context.Context, issueID, label, actor string) error {
+	return t.s.addLabel(ctx, t.w, issueID, label, actor)
+}
+
+func (t *tursoTx) RemoveLabel(ctx context.Context, issueID, label, actor string) error {
+	return t.s.removeLabel(ctx, t.w, issueID, label, actor)
+}
+
+func (t *tursoTx) SetConfig(ctx context.Context, key, value string) error {
+	return t.s.setConfig(ctx, t.w, key, value)
+}
+
+func (t *tursoTx) GetConfig(ctx context.Context, key string) (string, error) {
+	return t.s.MemoryStorage.GetConfig(ctx, key)
+}
+
+func (t *tursoTx) SetMetadata(ctx context.Context, key, value string) error {
+	return t.s.setMetadata(ctx, t.w, key, value)
+}
+
+func (t *tursoTx) GetMetadata(ctx context.Context, key string) (string, error) {
+	return t.s.MemoryStorage.GetMetadata(ctx, key)
+}
+
+func (t *tursoTx) AddComment(ctx context.Context, issueID, actor, comment string) error {
+	return t.s.addComment(ctx, t.w, issueID, actor, comment)
+}
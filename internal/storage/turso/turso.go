@@ -0,0 +1,831 @@
+// Package turso implements the storage interface on top of the jj-turso layout:
+// one JSON file per task in tasks/, one per dependency in deps/, and the Turso
+// query cache (.beads/turso.db) that other agents read from.
+//
+// The task and dep files are the source of truth. At startup they are loaded
+// into an in-memory index that answers queries with exactly the same semantics
+// as the other backends. Every mutation is written through to the affected
+// task/dep files and to the Turso cache, so `bd ready --turso`, the dashboard
+// and the sync daemon see changes immediately. The audit trail, export hashes
+// and dirty flags have no place in the task files and live in the cache alone.
+package turso
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/memory"
+	"github.com/steveyegge/beads/internal/turso/db"
+	"github.com/steveyegge/beads/internal/turso/migrate"
+	"github.com/steveyegge/beads/internal/turso/schema"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// TursoStorage implements storage.Storage backed by task/dep files and the Turso cache.
+type TursoStorage struct {
+	*memory.MemoryStorage // Query index, rebuilt from the files on open
+
+	db       *db.DB
+	dbPath   string
+	filesDir string // Directory containing tasks/ and deps/
+	tasksDir string
+	depsDir  string
+
+	// writeMu serializes every write, including whole transactions. A
+	// rollback reloads the index from the files, and a write flushed while
+	// the index is half rebuilt would see its issues as deleted.
+	writeMu sync.Mutex
+
+	// storedEvents counts each issue's events already in the cache. The
+	// index only ever appends events, so the rest are new.
+	storedEvents map[string]int
+}
+
+// Compile-time interface check
+var _ storage.Storage = (*TursoStorage)(nil)
+
+// New opens the Turso storage rooted at beadsDir.
+// Task files are read from beadsDir/tasks and beadsDir/deps, and the cache
+// lives at beadsDir/turso.db (created if missing).
+func New(ctx context.Context, beadsDir string) (*TursoStorage, error) {
+	dbPath := filepath.Join(beadsDir, "turso.db")
+	database, err := db.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.InitSchemaContext(ctx); err != nil {
+		_ = database.Close()
+		return nil, err
+	}
+
+	s := &TursoStorage{
+		MemoryStorage: memory.New(dbPath),
+		db:            database,
+		dbPath:        dbPath,
+		filesDir:      beadsDir,
+		tasksDir:      filepath.Join(beadsDir, "tasks"),
+		depsDir:       filepath.Join(beadsDir, "deps"),
+	}
+
+	if err := s.load(ctx); err != nil {
+		_ = database.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load rebuilds the in-memory index from the task/dep files and the cache's
+// config, metadata, event and export tracking tables.
+func (s *TursoStorage) load(ctx context.Context) error {
+	s.MemoryStorage.Reset()
+
+	// Dep files are deleted by name, so legacy files must be renamed into the
+	// current from/to order before they are indexed
+	if _, err := schema.UpgradeDepFiles(s.depsDir); err != nil {
+		return err
+	}
+
+	issues, _, err := migrate.FromTaskFiles(s.filesDir)
+	if err != nil {
+		return err
+	}
+	if err := s.MemoryStorage.LoadFromIssues(issues); err != nil {
+		return fmt.Errorf("failed to index task files: %w", err)
+	}
+
+	cfg, err := s.db.GetAllConfig(ctx)
+	if err != nil {
+		return err
+	}
+	for k, v := range cfg {
+		_ = s.MemoryStorage.SetConfig(ctx, k, v)
+	}
+
+	meta, err := s.db.GetAllMetadata(ctx)
+	if err != nil {
+		return err
+	}
+	for k, v := range meta {
+		_ = s.MemoryStorage.SetMetadata(ctx, k, v)
+	}
+
+	events, err := s.db.GetAllEvents(ctx)
+	if err != nil {
+		return err
+	}
+	s.MemoryStorage.LoadEvents(events)
+	s.storedEvents = make(map[string]int)
+	for _, event := range events {
+		s.storedEvents[event.IssueID]++
+	}
+
+	hashes, err := s.db.GetAllExportHashes(ctx)
+	if err != nil {
+		return err
+	}
+	for id, hash := range hashes {
+		_ = s.MemoryStorage.SetExportHash(ctx, id, hash)
+	}
+
+	dirty, err := s.db.GetDirtyIssues(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range dirty {
+		_ = s.MemoryStorage.MarkIssueDirty(ctx, id)
+	}
+
+	return nil
+}
+
+// writeSet records what a mutation touched so it can be written through.
+type writeSet struct {
+	issues   map[string]bool
+	config   map[string]*string // nil value = delete
+	metadata map[string]string
+
+	// Export tracking changes that don't touch the task files
+	tracked     map[string]bool // Issues whose export hash or dirty flag changed
+	clearHashes bool
+}
+
+func newWriteSet() *writeSet {
+	return &writeSet{
+		issues:   make(map[string]bool),
+		config:   make(map[string]*string),
+		metadata: make(map[string]string),
+		tracked:  make(map[string]bool),
+	}
+}
+
+func (w *writeSet) touch(ids ...string) {
+	for _, id := range ids {
+		w.issues[id] = true
+	}
+}
+
+// flush writes every touched issue, its dependencies, and any config or
+// metadata changes to the files and the Turso cache.
+func (s *TursoStorage) flush(ctx context.Context, w *writeSet) error {
+	ids := make([]string, 0, len(w.issues))
+	for id := range w.issues {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	// Tasks first so dependency rows can satisfy the cache's foreign keys
+	var live []string
+	for _, id := range ids {
+		issue, err := s.MemoryStorage.GetIssue(ctx, id)
+		if err != nil {
+			return err
+		}
+		if issue == nil {
+			if err := s.removeTask(ctx, id); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.writeTask(ctx, issue); err != nil {
+			return err
+		}
+		live = append(live, id)
+	}
+
+	for _, id := range live {
+		if err := s.syncDeps(ctx, id); err != nil {
+			return err
+		}
+		if err := s.syncEvents(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	if err := s.syncTracking(ctx, w, live); err != nil {
+		return err
+	}
+
+	for k, v := range w.config {
+		if v == nil {
+			if err := s.db.DeleteConfig(ctx, k); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.db.SetConfig(ctx, k, *v); err != nil {
+			return err
+		}
+	}
+	for k, v := range w.metadata {
+		if err := s.db.SetMetadata(ctx, k, v); err != nil {
+			return err
+		}
+	}
+
+	if len(ids) > 0 {
+		if err := s.db.RefreshBlockedCacheContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTask writes one issue (with labels, comments and attachments) to its task file and the cache.
+func (s *TursoStorage) writeTask(ctx context.Context, issue *types.Issue) error {
+	comments, err := s.MemoryStorage.GetIssueComments(ctx, issue.ID)
+	if err != nil {
+		return err
+	}
+	issue.Comments = comments
+	attachments, err := s.MemoryStorage.GetAttachments(ctx, issue.ID)
+	if err != nil {
+		return err
+	}
+	issue.Attachments = attachments
+
+	task := schema.FromIssue(issue)
+	if err := schema.WriteTaskFile(s.tasksDir, task); err != nil {
+		return err
+	}
+	return s.db.UpsertTaskContext(ctx, task)
+}
+
+// removeTask deletes a task file, every dep file that mentions it, and its
+// cache rows, events and export tracking.
+func (s *TursoStorage) removeTask(ctx context.Context, id string) error {
+	path := filepath.Join(s.tasksDir, id+".json")
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete task file %s: %w", path, err)
+	}
+
+	deps, err := schema.ListDepsForIssue(s.depsDir, id)
+	if err != nil {
+		return err
+	}
+	for _, dep := range deps {
+		if err := schema.DeleteDepFile(s.depsDir, dep.From, dep.Type, dep.To); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.DeleteEvents(ctx, id); err != nil {
+		return err
+	}
+	delete(s.storedEvents, id)
+	if err := s.db.DeleteExportHash(ctx, id); err != nil {
+		return err
+	}
+	if err := s.db.SetDirty(ctx, id, false); err != nil {
+		return err
+	}
+
+	return s.db.DeleteTaskContext(ctx, id)
+}
+
+// syncDeps makes the dep files and cache rows for issueID's outgoing
+// dependencies match the in-memory records exactly.
+func (s *TursoStorage) syncDeps(ctx context.Context, issueID string) error {
+	records, err := s.MemoryStorage.GetDependencyRecords(ctx, issueID)
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]*schema.DepFile, len(records))
+	for _, rec := range records {
+		dep := schema.FromTypeDependency(rec)
+		want[dep.ToFileName()] = dep
+	}
+
+	existing, err := schema.ListDepsForIssue(s.depsDir, issueID)
+	if err != nil {
+		return err
+	}
+	for _, dep := range existing {
+		if dep.To != issueID {
+			continue // An incoming edge, owned by the other issue
+		}
+		if _, keep := want[dep.ToFileName()]; keep {
+			continue
+		}
+		if err := schema.DeleteDepFile(s.depsDir, dep.From, dep.Type, dep.To); err != nil {
+			return err
+		}
+		if err := s.db.DeleteDepContext(ctx, dep.From, dep.To, dep.Type); err != nil {
+			return err
+		}
+	}
+
+	if len(want) > 0 {
+		if err := os.MkdirAll(s.depsDir, 0755); err != nil {
+			return fmt.Errorf("failed to create deps directory: %w", err)
+		}
+	}
+	for _, dep := range want {
+		if err := schema.WriteDepFile(s.depsDir, dep); err != nil {
+			return err
+		}
+		// The cache only holds edges between local tasks; external:
+		// references live in the dep files alone.
+		if target, _ := s.MemoryStorage.GetIssue(ctx, dep.From); target == nil {
+			continue
+		}
+		if err := s.db.UpsertDepContext(ctx, dep); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncEvents appends the events recorded in the index since the last flush
+// to the cache.
+func (s *TursoStorage) syncEvents(ctx context.Context, issueID string) error {
+	events, err := s.MemoryStorage.GetEvents(ctx, issueID, 0)
+	if err != nil {
+		return err
+	}
+	// GetEvents is newest first
+	for i := len(events) - 1 - s.storedEvents[issueID]; i >= 0; i-- {
+		if err := s.db.AddEvent(ctx, events[i]); err != nil {
+			return err
+		}
+		s.storedEvents[issueID]++
+	}
+	return nil
+}
+
+// syncTracking writes the export hashes and dirty flags of the live touched
+// issues, and of every issue whose tracking alone changed, to the cache.
+func (s *TursoStorage) syncTracking(ctx context.Context, w *writeSet, live []string) error {
+	if w.clearHashes {
+		if err := s.db.ClearExportHashes(ctx); err != nil {
+			return err
+		}
+	}
+
+	ids := slices.Clone(live)
+	for id := range w.tracked {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	dirtyIDs, err := s.MemoryStorage.GetDirtyIssues(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.db.SetDirty(ctx, id, slices.Contains(dirtyIDs, id)); err != nil {
+			return err
+		}
+		hash, err := s.MemoryStorage.GetExportHash(ctx, id)
+		if err != nil {
+			return err
+		}
+		if hash == "" {
+			err = s.db.DeleteExportHash(ctx, id)
+		} else {
+			err = s.db.SetExportHash(ctx, id, hash)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write runs a mutation against the index and writes the result through.
+func (s *TursoStorage) write(ctx context.Context, fn func(w *writeSet) error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	w := newWriteSet()
+	if err := fn(w); err != nil {
+		return err
+	}
+	return s.flush(ctx, w)
+}
+
+// Issues
+
+// CreateIssue creates a new issue and writes its task file
+func (s *TursoStorage) CreateIssue(ctx context.Context, issue *types.Issue, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.createIssue(ctx, w, issue, actor)
+	})
+}
+
+func (s *TursoStorage) createIssue(ctx context.Context, w *writeSet, issue *types.Issue, actor string) error {
+	if err := s.MemoryStorage.CreateIssue(ctx, issue, actor); err != nil {
+		return err
+	}
+	w.touch(issue.ID)
+	return nil
+}
+
+// CreateIssues creates multiple issues and writes their task files
+func (s *TursoStorage) CreateIssues(ctx context.Context, issues []*types.Issue, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.createIssues(ctx, w, issues, actor)
+	})
+}
+
+func (s *TursoStorage) createIssues(ctx context.Context, w *writeSet, issues []*types.Issue, actor string) error {
+	if err := s.MemoryStorage.CreateIssues(ctx, issues, actor); err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		w.touch(issue.ID)
+	}
+	return nil
+}
+
+// UpdateIssue updates fields on an issue and rewrites its task file
+func (s *TursoStorage) UpdateIssue(ctx context.Context, id string, updates map[string]interface{}, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.updateIssue(ctx, w, id, updates, actor)
+	})
+}
+
+func (s *TursoStorage) updateIssue(ctx context.Context, w *writeSet, id string, updates map[string]interface{}, actor string) error {
+	if err := s.MemoryStorage.UpdateIssue(ctx, id, updates, actor); err != nil {
+		return err
+	}
+	w.touch(id)
+	return nil
+}
+
+// CloseIssue closes an issue and rewrites its task file
+func (s *TursoStorage) CloseIssue(ctx context.Context, id string, reason string, actor string, session string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.closeIssue(ctx, w, id, reason, actor, session)
+	})
+}
+
+func (s *TursoStorage) closeIssue(ctx context.Context, w *writeSet, id string, reason string, actor string, session string) error {
+	if err := s.MemoryStorage.CloseIssue(ctx, id, reason, actor, session); err != nil {
+		return err
+	}
+	w.touch(id)
+	return nil
+}
+
+// CreateTombstone soft-deletes an issue, keeping its task file with status=tombstone
+func (s *TursoStorage) CreateTombstone(ctx context.Context, id string, actor string, reason string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		if err := s.MemoryStorage.CreateTombstone(ctx, id, actor, reason); err != nil {
+			return err
+		}
+		w.touch(id)
+		return nil
+	})
+}
+
+// DeleteIssue permanently deletes an issue, its task file and its dep files
+func (s *TursoStorage) DeleteIssue(ctx context.Context, id string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.deleteIssue(ctx, w, id)
+	})
+}
+
+func (s *TursoStorage) deleteIssue(ctx context.Context, w *writeSet, id string) error {
+	if err := s.MemoryStorage.DeleteIssue(ctx, id); err != nil {
+		return err
+	}
+	w.touch(id)
+	return nil
+}
+
+// Dependencies
+
+// AddDependency adds a dependency and writes its dep file
+func (s *TursoStorage) AddDependency(ctx context.Context, dep *types.Dependency, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.addDependency(ctx, w, dep, actor)
+	})
+}
+
+func (s *TursoStorage) addDependency(ctx context.Context, w *writeSet, dep *types.Dependency, actor string) error {
+	// Dep files require a timestamp; the in-memory index does not set one
+	if dep.CreatedAt.IsZero() {
+		dep.CreatedAt = time.Now()
+	}
+	if dep.CreatedBy == "" {
+		dep.CreatedBy = actor
+	}
+	if err := s.MemoryStorage.AddDependency(ctx, dep, actor); err != nil {
+		return err
+	}
+	w.touch(dep.IssueID)
+	return nil
+}
+
+// RemoveDependency removes a dependency and deletes its dep file
+func (s *TursoStorage) RemoveDependency(ctx context.Context, issueID, dependsOnID string, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.removeDependency(ctx, w, issueID, dependsOnID, actor)
+	})
+}
+
+func (s *TursoStorage) removeDependency(ctx context.Context, w *writeSet, issueID, dependsOnID string, actor string) error {
+	if err := s.MemoryStorage.RemoveDependency(ctx, issueID, dependsOnID, actor); err != nil {
+		return err
+	}
+	w.touch(issueID)
+	return nil
+}
+
+// Prefix rename
+
+// UpdateIssueID renames an issue, moving its task file and rewriting every
+// dep file that mentions it
+func (s *TursoStorage) UpdateIssueID(ctx context.Context, oldID, newID string, issue *types.Issue, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		if err := s.MemoryStorage.UpdateIssueID(ctx, oldID, newID, issue, actor); err != nil {
+			return err
+		}
+		w.touch(oldID, newID)
+		return s.touchDependents(ctx, w, newID)
+	})
+}
+
+// RenameDependencyPrefix rewrites dependency prefixes and every dep file
+func (s *TursoStorage) RenameDependencyPrefix(ctx context.Context, oldPrefix, newPrefix string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		if err := s.MemoryStorage.RenameDependencyPrefix(ctx, oldPrefix, newPrefix); err != nil {
+			return err
+		}
+		all, err := s.MemoryStorage.GetAllDependencyRecords(ctx)
+		if err != nil {
+			return err
+		}
+		for issueID := range all {
+			w.touch(issueID)
+		}
+		return nil
+	})
+}
+
+// touchDependents marks every issue with a dependency on id, so its dep
+// files are rewritten on flush.
+func (s *TursoStorage) touchDependents(ctx context.Context, w *writeSet, id string) error {
+	all, err := s.MemoryStorage.GetAllDependencyRecords(ctx)
+	if err != nil {
+		return err
+	}
+	for issueID, deps := range all {
+		for _, dep := range deps {
+			if dep.DependsOnID == id {
+				w.touch(issueID)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Labels
+
+// AddLabel adds a label and rewrites the task file
+func (s *TursoStorage) AddLabel(ctx context.Context, issueID, label, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.addLabel(ctx, w, issueID, label, actor)
+	})
+}
+
+func (s *TursoStorage) addLabel(ctx context.Context, w *writeSet, issueID, label, actor string) error {
+	if err := s.MemoryStorage.AddLabel(ctx, issueID, label, actor); err != nil {
+		return err
+	}
+	w.touch(issueID)
+	return nil
+}
+
+// RemoveLabel removes a label and rewrites the task file
+func (s *TursoStorage) RemoveLabel(ctx context.Context, issueID, label, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.removeLabel(ctx, w, issueID, label, actor)
+	})
+}
+
+func (s *TursoStorage) removeLabel(ctx context.Context, w *writeSet, issueID, label, actor string) error {
+	if err := s.MemoryStorage.RemoveLabel(ctx, issueID, label, actor); err != nil {
+		return err
+	}
+	w.touch(issueID)
+	return nil
+}
+
+// Comments
+
+// AddIssueComment adds a comment and rewrites the task file
+func (s *TursoStorage) AddIssueComment(ctx context.Context, issueID, author, text string) (*types.Comment, error) {
+	var comment *types.Comment
+	err := s.write(ctx, func(w *writeSet) error {
+		var err error
+		comment, err = s.MemoryStorage.AddIssueComment(ctx, issueID, author, text)
+		if err != nil {
+			return err
+		}
+		w.touch(issueID)
+		return nil
+	})
+	return comment, err
+}
+
+// AddComment records a comment event and rewrites the task file
+func (s *TursoStorage) AddComment(ctx context.Context, issueID, actor, comment string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.addComment(ctx, w, issueID, actor, comment)
+	})
+}
+
+func (s *TursoStorage) addComment(ctx context.Context, w *writeSet, issueID, actor, comment string) error {
+	if err := s.MemoryStorage.AddComment(ctx, issueID, actor, comment); err != nil {
+		return err
+	}
+	w.touch(issueID)
+	return nil
+}
+
+// Attachments
+
+// AddAttachment records an attachment and rewrites the task file
+func (s *TursoStorage) AddAttachment(ctx context.Context, attachment *types.Attachment, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		if err := s.MemoryStorage.AddAttachment(ctx, attachment, actor); err != nil {
+			return err
+		}
+		w.touch(attachment.IssueID)
+		return nil
+	})
+}
+
+// RemoveAttachment removes an attachment and rewrites the task file
+func (s *TursoStorage) RemoveAttachment(ctx context.Context, issueID, name, actor string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		if err := s.MemoryStorage.RemoveAttachment(ctx, issueID, name, actor); err != nil {
+			return err
+		}
+		w.touch(issueID)
+		return nil
+	})
+}
+
+// Config and metadata
+
+// SetConfig sets a configuration value in the index and the cache
+func (s *TursoStorage) SetConfig(ctx context.Context, key, value string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.setConfig(ctx, w, key, value)
+	})
+}
+
+func (s *TursoStorage) setConfig(ctx context.Context, w *writeSet, key, value string) error {
+	if err := s.MemoryStorage.SetConfig(ctx, key, value); err != nil {
+		return err
+	}
+	w.config[key] = &value
+	return nil
+}
+
+// DeleteConfig removes a configuration value from the index and the cache
+func (s *TursoStorage) DeleteConfig(ctx context.Context, key string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		if err := s.MemoryStorage.DeleteConfig(ctx, key); err != nil {
+			return err
+		}
+		w.config[key] = nil
+		return nil
+	})
+}
+
+// SetMetadata sets a metadata value in the index and the cache
+func (s *TursoStorage) SetMetadata(ctx context.Context, key, value string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		return s.setMetadata(ctx, w, key, value)
+	})
+}
+
+func (s *TursoStorage) setMetadata(ctx context.Context, w *writeSet, key, value string) error {
+	if err := s.MemoryStorage.SetMetadata(ctx, key, value); err != nil {
+		return err
+	}
+	w.metadata[key] = value
+	return nil
+}
+
+// Export tracking
+
+// SetExportHash records an issue's content hash at export in the index and the cache
+func (s *TursoStorage) SetExportHash(ctx context.Context, issueID, hash string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		if err := s.MemoryStorage.SetExportHash(ctx, issueID, hash); err != nil {
+			return err
+		}
+		w.tracked[issueID] = true
+		return nil
+	})
+}
+
+// ClearAllExportHashes forgets every export hash in the index and the cache
+func (s *TursoStorage) ClearAllExportHashes(ctx context.Context) error {
+	return s.write(ctx, func(w *writeSet) error {
+		if err := s.MemoryStorage.ClearAllExportHashes(ctx); err != nil {
+			return err
+		}
+		w.clearHashes = true
+		return nil
+	})
+}
+
+// SetJSONLFileHash stores the JSONL file hash as metadata in the index and the cache
+func (s *TursoStorage) SetJSONLFileHash(ctx context.Context, fileHash string) error {
+	return s.SetMetadata(ctx, "jsonl_file_hash", fileHash)
+}
+
+// ClearDirtyIssuesByID clears dirty flags in the index and the cache
+func (s *TursoStorage) ClearDirtyIssuesByID(ctx context.Context, issueIDs []string) error {
+	return s.write(ctx, func(w *writeSet) error {
+		if err := s.MemoryStorage.ClearDirtyIssuesByID(ctx, issueIDs); err != nil {
+			return err
+		}
+		for _, id := range issueIDs {
+			w.tracked[id] = true
+		}
+		return nil
+	})
+}
+
+// RenameCounterPrefix has nothing to persist: ID counters are rebuilt from
+// the task file names on every load.
+func (s *TursoStorage) RenameCounterPrefix(ctx context.Context, oldPrefix, newPrefix string) error {
+	return nil
+}
+
+// Transactions
+
+// RunInTransaction executes fn with all writes buffered until it returns.
+// On success the touched task/dep files and cache rows are written in one
+// pass. On error or panic nothing is written and the index is reloaded from
+// the files, which were never modified, so the rollback is exact.
+func (s *TursoStorage) RunInTransaction(ctx context.Context, fn func(tx storage.Transaction) error) (err error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	tx := &tursoTx{s: s, w: newWriteSet()}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if loadErr := s.load(ctx); loadErr != nil {
+				r = fmt.Errorf("%v (reload after rollback also failed: %w)", r, loadErr)
+			}
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if loadErr := s.load(ctx); loadErr != nil {
+			return errors.Join(err, fmt.Errorf("reload after rollback failed: %w", loadErr))
+		}
+		return err
+	}
+
+	return s.flush(ctx, tx.w)
+}
+
+// Lifecycle
+
+// Close closes the Turso cache
+func (s *TursoStorage) Close() error {
+	_ = s.MemoryStorage.Close()
+	return s.db.Close()
+}
+
+// Path returns the path to the Turso cache database
+func (s *TursoStorage) Path() string {
+	return s.dbPath
+}
+
+// UnderlyingDB returns the Turso cache connection.
+// Only the tasks and deps tables are synced to the task files; rows written
+// directly to the other tables are not reloaded into the index until reopen.
+func (s *TursoStorage) UnderlyingDB() *sql.DB {
+	return s.db.RawDB()
+}
+
+// UnderlyingConn returns a single connection to the Turso cache
+func (s *TursoStorage) UnderlyingConn(ctx context.Context) (*sql.Conn, error) {
+	return s.db.RawDB().Conn(ctx)
+}
@@ -0,0 +1,400 @@
+package turso
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/storage"
+	tursodb "github.com/steveyegge/beads/internal/turso/db"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func setupTestStore(t *testing.T) (*TursoStorage, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	store, err := New(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	if err := store.SetConfig(context.Background(), "issue_prefix", "bd"); err != nil {
+		t.Fatalf("failed to set issue_prefix: %v", err)
+	}
+
+	return store, dir
+}
+
+func newIssue(title string) *types.Issue {
+	return &types.Issue{
+		Title:     title,
+		Status:    types.StatusOpen,
+		Priority:  2,
+		IssueType: types.TypeTask,
+	}
+}
+
+func TestCreateIssue_WritesTaskFileAndCache(t *testing.T) {
+	store, dir := setupTestStore(t)
+	ctx := context.Background()
+
+	issue := newIssue("Write through")
+	issue.Design = "design notes"
+	if err := store.CreateIssue(ctx, issue, "tester"); err != nil {
+		t.Fatalf("CreateIssue failed: %v", err)
+	}
+	if err := store.AddLabel(ctx, issue.ID, "backend", "tester"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if _, err := store.AddIssueComment(ctx, issue.ID, "tester", "hello"); err != nil {
+		t.Fatalf("AddIssueComment failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "tasks", issue.ID+".json")); err != nil {
+		t.Fatalf("expected task file: %v", err)
+	}
+
+	task, err := store.db.GetTaskByID(issue.ID)
+	if err != nil {
+		t.Fatalf("expected cache row: %v", err)
+	}
+	if len(task.Tags) != 1 || task.Tags[0] != "backend" {
+		t.Errorf("expected cached tags [backend], got %v", task.Tags)
+	}
+
+	// Reopen from disk: everything must come back from the task files
+	_ = store.Close()
+	reopened, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	got, err := reopened.GetIssue(ctx, issue.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetIssue after reopen = %v, %v", got, err)
+	}
+	if got.Design != "design notes" {
+		t.Errorf("expected design to persist, got %q", got.Design)
+	}
+	labels, _ := reopened.GetLabels(ctx, issue.ID)
+	if len(labels) != 1 || labels[0] != "backend" {
+		t.Errorf("expected labels [backend], got %v", labels)
+	}
+	comments, _ := reopened.GetIssueComments(ctx, issue.ID)
+	if len(comments) != 1 || comments[0].Text != "hello" {
+		t.Errorf("expected one comment, got %v", comments)
+	}
+	prefix, _ := reopened.GetConfig(ctx, "issue_prefix")
+	if prefix != "bd" {
+		t.Errorf("expected issue_prefix to persist, got %q", prefix)
+	}
+}
+
+func TestDependencies_WriteThroughAndBlockedCache(t *testing.T) {
+	store, dir := setupTestStore(t)
+	ctx := context.Background()
+
+	blocker := newIssue("Blocker")
+	blocked := newIssue("Blocked")
+	if err := store.CreateIssues(ctx, []*types.Issue{blocker, blocked}, "tester"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+
+	dep := &types.Dependency{IssueID: blocked.ID, DependsOnID: blocker.ID, Type: types.DepBlocks}
+	if err := store.AddDependency(ctx, dep, "tester"); err != nil {
+		t.Fatalf("AddDependency failed: %v", err)
+	}
+
+	depPath := filepath.Join(dir, "deps", blocker.ID+"--blocks--"+blocked.ID+".json")
+	if _, err := os.Stat(depPath); err != nil {
+		t.Fatalf("expected dep file %s: %v", depPath, err)
+	}
+
+	ready, err := store.db.GetReadyTasks(ctx, tursodb.ReadyTasksOptions{})
+	if err != nil {
+		t.Fatalf("GetReadyTasks failed: %v", err)
+	}
+	if len(ready) != 1 || ready[0].ID != blocker.ID {
+		t.Errorf("expected only the blocker in the cache's ready set, got %v", ready)
+	}
+
+	work, err := store.GetReadyWork(ctx, types.WorkFilter{})
+	if err != nil {
+		t.Fatalf("GetReadyWork failed: %v", err)
+	}
+	if len(work) != 1 || work[0].ID != blocker.ID {
+		t.Errorf("expected only the blocker from GetReadyWork, got %v", work)
+	}
+
+	if err := store.RemoveDependency(ctx, blocked.ID, blocker.ID, "tester"); err != nil {
+		t.Fatalf("RemoveDependency failed: %v", err)
+	}
+	if _, err := os.Stat(depPath); !os.IsNotExist(err) {
+		t.Error("expected dep file to be removed")
+	}
+	if n, _ := store.db.GetDepCount(); n != 0 {
+		t.Errorf("expected 0 cached deps, got %d", n)
+	}
+}
+
+func TestDeleteIssue_RemovesFiles(t *testing.T) {
+	store, dir := setupTestStore(t)
+	ctx := context.Background()
+
+	parent := newIssue("Parent")
+	child := newIssue("Child")
+	if err := store.CreateIssues(ctx, []*types.Issue{parent, child}, "tester"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+	dep := &types.Dependency{IssueID: child.ID, DependsOnID: parent.ID, Type: types.DepParentChild}
+	if err := store.AddDependency(ctx, dep, "tester"); err != nil {
+		t.Fatalf("AddDependency failed: %v", err)
+	}
+
+	if err := store.DeleteIssue(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteIssue failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "tasks", parent.ID+".json")); !os.IsNotExist(err) {
+		t.Error("expected task file to be removed")
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "deps"))
+	if len(entries) != 0 {
+		t.Errorf("expected dep files mentioning the deleted issue to be removed, got %d", len(entries))
+	}
+}
+
+func TestRunInTransaction_CommitAndRollback(t *testing.T) {
+	store, dir := setupTestStore(t)
+	ctx := context.Background()
+
+	committed := newIssue("Committed")
+	err := store.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		if err := tx.CreateIssue(ctx, committed, "tester"); err != nil {
+			return err
+		}
+		// Read-your-writes inside the transaction
+		got, err := tx.GetIssue(ctx, committed.ID)
+		if err != nil || got == nil {
+			t.Errorf("expected read-your-writes, got %v, %v", got, err)
+		}
+		return tx.AddLabel(ctx, committed.ID, "tx", "tester")
+	})
+	if err != nil {
+		t.Fatalf("RunInTransaction failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tasks", committed.ID+".json")); err != nil {
+		t.Fatalf("expected committed task file: %v", err)
+	}
+
+	rolledBack := newIssue("Rolled back")
+	wantErr := errors.New("boom")
+	err = store.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		if err := tx.CreateIssue(ctx, rolledBack, "tester"); err != nil {
+			return err
+		}
+		if err := tx.UpdateIssue(ctx, committed.ID, map[string]interface{}{"title": "changed"}, "tester"); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+
+	if got, _ := store.GetIssue(ctx, rolledBack.ID); got != nil {
+		t.Error("expected rolled back issue to be gone from the index")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tasks", rolledBack.ID+".json")); !os.IsNotExist(err) {
+		t.Error("expected no task file for rolled back issue")
+	}
+	got, _ := store.GetIssue(ctx, committed.ID)
+	if got == nil || got.Title != "Committed" {
+		t.Errorf("expected committed issue to keep its title, got %+v", got)
+	}
+	if prefix, _ := store.GetConfig(ctx, "issue_prefix"); prefix != "bd" {
+		t.Errorf("expected config to survive rollback, got %q", prefix)
+	}
+}
+
+func TestEventsAndExportTracking_Persist(t *testing.T) {
+	store, dir := setupTestStore(t)
+	ctx := context.Background()
+
+	issue := newIssue("Tracked")
+	if err := store.CreateIssue(ctx, issue, "tester"); err != nil {
+		t.Fatalf("CreateIssue failed: %v", err)
+	}
+	if err := store.UpdateIssue(ctx, issue.ID, map[string]interface{}{"priority": 1}, "tester"); err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+	if err := store.AddComment(ctx, issue.ID, "tester", "looked into it"); err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+	other := newIssue("Untouched")
+	if err := store.CreateIssue(ctx, other, "tester"); err != nil {
+		t.Fatalf("CreateIssue failed: %v", err)
+	}
+	if err := store.SetExportHash(ctx, issue.ID, "hash-1"); err != nil {
+		t.Fatalf("SetExportHash failed: %v", err)
+	}
+	if err := store.ClearDirtyIssuesByID(ctx, []string{issue.ID}); err != nil {
+		t.Fatalf("ClearDirtyIssuesByID failed: %v", err)
+	}
+	if err := store.SetJSONLFileHash(ctx, "file-hash"); err != nil {
+		t.Fatalf("SetJSONLFileHash failed: %v", err)
+	}
+	want, err := store.GetEvents(ctx, issue.ID, 0)
+	if err != nil || len(want) != 3 {
+		t.Fatalf("GetEvents = %d events, %v; want created, updated, commented", len(want), err)
+	}
+
+	// A rollback reloads the index from the files and the cache
+	wantErr := errors.New("boom")
+	err = store.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		if err := tx.AddComment(ctx, issue.ID, "tester", "rolled back"); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+
+	check := func(s *TursoStorage, when string) {
+		t.Helper()
+		events, err := s.GetEvents(ctx, issue.ID, 0)
+		if err != nil || len(events) != len(want) {
+			t.Fatalf("%s: GetEvents = %d events, %v; want %d", when, len(events), err, len(want))
+		}
+		for i := range want {
+			if events[i].EventType != want[i].EventType || events[i].Actor != want[i].Actor {
+				t.Errorf("%s: event %d = %s by %s, want %s by %s", when, i,
+					events[i].EventType, events[i].Actor, want[i].EventType, want[i].Actor)
+			}
+		}
+		if hash, _ := s.GetExportHash(ctx, issue.ID); hash != "hash-1" {
+			t.Errorf("%s: export hash = %q, want hash-1", when, hash)
+		}
+		if hash, _ := s.GetJSONLFileHash(ctx); hash != "file-hash" {
+			t.Errorf("%s: JSONL file hash = %q, want file-hash", when, hash)
+		}
+		dirty, _ := s.GetDirtyIssues(ctx)
+		if len(dirty) != 1 || dirty[0] != other.ID {
+			t.Errorf("%s: dirty issues = %v, want [%s]", when, dirty, other.ID)
+		}
+	}
+	check(store, "after rollback")
+
+	_ = store.Close()
+	reopened, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	check(reopened, "after reopen")
+
+	if err := reopened.DeleteIssue(ctx, issue.ID); err != nil {
+		t.Fatalf("DeleteIssue failed: %v", err)
+	}
+	if err := reopened.ClearAllExportHashes(ctx); err != nil {
+		t.Fatalf("ClearAllExportHashes failed: %v", err)
+	}
+	if events, _ := reopened.db.GetAllEvents(ctx); len(events) != 1 || events[0].IssueID != other.ID {
+		t.Errorf("cached events after delete = %+v, want only %s's", events, other.ID)
+	}
+	if hashes, _ := reopened.db.GetAllExportHashes(ctx); len(hashes) != 0 {
+		t.Errorf("cached export hashes after clear = %v, want none", hashes)
+	}
+}
+
+func TestRunInTransaction_RollbackDoesNotRaceWrites(t *testing.T) {
+	store, dir := setupTestStore(t)
+	ctx := context.Background()
+
+	live := newIssue("Live")
+	if err := store.CreateIssue(ctx, live, "tester"); err != nil {
+		t.Fatalf("CreateIssue failed: %v", err)
+	}
+
+	// Plain writes keep flushing the live issue while transactions roll
+	// back and reload the index underneath them
+	done := make(chan error)
+	go func() {
+		for i := 0; i < 200; i++ {
+			if err := store.UpdateIssue(ctx, live.ID, map[string]interface{}{"priority": i % 4}, "tester"); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	for i := 0; i < 200; i++ {
+		_ = store.RunInTransaction(ctx, func(tx storage.Transaction) error {
+			if err := tx.CreateIssue(ctx, newIssue("Rolled back"), "tester"); err != nil {
+				return err
+			}
+			return errors.New("roll back")
+		})
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("UpdateIssue failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "tasks", live.ID+".json")); err != nil {
+		t.Fatalf("live task file was removed: %v", err)
+	}
+	if got, _ := store.GetIssue(ctx, live.ID); got == nil {
+		t.Fatal("live issue missing from the index")
+	}
+}
+
+func TestUpdateIssueID_RewritesFiles(t *testing.T) {
+	store, dir := setupTestStore(t)
+	ctx := context.Background()
+
+	blocker := newIssue("Blocker")
+	blocked := newIssue("Blocked")
+	if err := store.CreateIssues(ctx, []*types.Issue{blocker, blocked}, "tester"); err != nil {
+		t.Fatalf("CreateIssues failed: %v", err)
+	}
+	dep := &types.Dependency{IssueID: blocked.ID, DependsOnID: blocker.ID, Type: types.DepBlocks}
+	if err := store.AddDependency(ctx, dep, "tester"); err != nil {
+		t.Fatalf("AddDependency failed: %v", err)
+	}
+
+	oldID := blocker.ID
+	renamed, err := store.GetIssue(ctx, oldID)
+	if err != nil {
+		t.Fatalf("GetIssue failed: %v", err)
+	}
+	if err := store.UpdateIssueID(ctx, oldID, "kb-1", renamed, "tester"); err != nil {
+		t.Fatalf("UpdateIssueID failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "tasks", oldID+".json")); !os.IsNotExist(err) {
+		t.Error("expected old task file to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tasks", "kb-1.json")); err != nil {
+		t.Errorf("expected renamed task file: %v", err)
+	}
+
+	// A fresh store sees the rename and the rewritten dependency.
+	reopened, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	deps, err := reopened.GetDependencyRecords(ctx, blocked.ID)
+	if err != nil {
+		t.Fatalf("GetDependencyRecords failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].DependsOnID != "kb-1" {
+		t.Errorf("expected dependency on kb-1 after reopen, got %+v", deps)
+	}
+}
@@ -4,10 +4,10 @@ package syncbranch
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/vcs"
 )
 
 // Config keys for sync branch integrity tracking
@@ -49,7 +49,7 @@ type ForcePushStatus struct {
 // Parameters:
 //   - ctx: Context for cancellation
 //   - store: Storage interface for reading config
-//   - repoRoot: Path to the git repository root
+//   - repoRoot: Path to the repository root
 //   - syncBranch: Name of the sync branch (e.g., "beads-sync")
 //
 // Returns ForcePushStatus with details about the check.
@@ -72,6 +72,11 @@ func CheckForcePush(ctx context.Context, store storage.Storage, repoRoot, syncBr
 		return status, nil
 	}
 
+	v, err := OpenVCS(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
 	// Get worktree path for git operations
 	worktreePath := getBeadsWorktreePath(ctx, repoRoot, syncBranch)
 
@@ -84,12 +89,14 @@ func CheckForcePush(ctx context.Context, store storage.Storage, repoRoot, syncBr
 	// refs/remotes/origin/beads-sync if it already exists. On fresh clones or
 	// after ref cleanup, this can leave the tracking ref stale, causing
 	// false-positive force-push detection when comparing against wrong commits.
-	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", syncBranch, status.Remote, syncBranch)
-	fetchCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "fetch", status.Remote, refspec) // #nosec G204 - repoRoot/syncBranch are validated git inputs
-	fetchOutput, err := fetchCmd.CombinedOutput()
-	if err != nil {
+	// jj fetches every bookmark and always updates the remote bookmarks.
+	fetchRef := syncBranch
+	if !isJJ(v) {
+		fetchRef = fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", syncBranch, status.Remote, syncBranch)
+	}
+	if err := v.Fetch(ctx, status.Remote, fetchRef); err != nil {
 		// Check if remote branch doesn't exist
-		if strings.Contains(string(fetchOutput), "couldn't find remote ref") {
+		if strings.Contains(err.Error(), "couldn't find remote ref") {
 			status.Message = "Remote sync branch does not exist"
 			return status, nil
 		}
@@ -97,13 +104,17 @@ func CheckForcePush(ctx context.Context, store storage.Storage, repoRoot, syncBr
 	}
 
 	// Get current remote SHA
-	remoteRef := fmt.Sprintf("%s/%s", status.Remote, syncBranch)
-	revParseCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "rev-parse", remoteRef) // #nosec G204 - remoteRef constructed from trusted config
-	revParseOutput, err := revParseCmd.Output()
+	remoteRef := v.RemoteRef(status.Remote, syncBranch)
+	currentSHA, err := v.GetCommitHash(remoteRef)
 	if err != nil {
+		// jj fetches without error when the remote has no such bookmark
+		if isJJ(v) {
+			status.Message = "Remote sync branch does not exist"
+			return status, nil
+		}
 		return nil, fmt.Errorf("failed to get remote SHA: %w", err)
 	}
-	status.CurrentRemoteSHA = strings.TrimSpace(string(revParseOutput))
+	status.CurrentRemoteSHA = currentSHA
 
 	// If SHA matches, no change at all
 	if storedSHA == status.CurrentRemoteSHA {
@@ -113,8 +124,7 @@ func CheckForcePush(ctx context.Context, store storage.Storage, repoRoot, syncBr
 
 	// Check if stored SHA is an ancestor of current remote SHA
 	// This means remote was updated normally (fast-forward)
-	isAncestorCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "merge-base", "--is-ancestor", storedSHA, status.CurrentRemoteSHA) // #nosec G204 - args derive from git SHAs we validated earlier
-	if isAncestorCmd.Run() == nil {
+	if isAncestor(v, storedSHA, status.CurrentRemoteSHA) {
 		// Stored SHA is ancestor - normal update, no force-push
 		status.Message = "Remote sync branch updated normally (fast-forward)"
 		return status, nil
@@ -139,11 +149,16 @@ func CheckForcePush(ctx context.Context, store storage.Storage, repoRoot, syncBr
 // Parameters:
 //   - ctx: Context for cancellation
 //   - store: Storage interface for writing config
-//   - repoRoot: Path to the git repository root
+//   - repoRoot: Path to the repository root
 //   - syncBranch: Name of the sync branch (e.g., "beads-sync")
 //
 // Returns error if the update fails.
 func UpdateStoredRemoteSHA(ctx context.Context, store storage.Storage, repoRoot, syncBranch string) error {
+	v, err := OpenVCS(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
 	// Get worktree path for git operations
 	worktreePath := getBeadsWorktreePath(ctx, repoRoot, syncBranch)
 
@@ -151,19 +166,15 @@ func UpdateStoredRemoteSHA(ctx context.Context, store storage.Storage, repoRoot,
 	remote := getRemoteForBranch(ctx, worktreePath, syncBranch)
 
 	// Get current remote SHA
-	remoteRef := fmt.Sprintf("%s/%s", remote, syncBranch)
-	revParseCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "rev-parse", remoteRef) // #nosec G204 - remoteRef is internal config
-	revParseOutput, err := revParseCmd.Output()
+	currentSHA, err := v.GetCommitHash(v.RemoteRef(remote, syncBranch))
 	if err != nil {
 		// Remote branch might not exist yet (first push)
 		// Try local branch instead
-		revParseCmd = exec.CommandContext(ctx, "git", "-C", repoRoot, "rev-parse", syncBranch) // #nosec G204 - branch name from config
-		revParseOutput, err = revParseCmd.Output()
+		currentSHA, err = v.GetCommitHash(syncBranch)
 		if err != nil {
 			return fmt.Errorf("failed to get sync branch SHA: %w", err)
 		}
 	}
-	currentSHA := strings.TrimSpace(string(revParseOutput))
 
 	// Store the SHA
 	if err := store.SetConfig(ctx, RemoteSHAConfigKey, currentSHA); err != nil {
@@ -173,6 +184,13 @@ func UpdateStoredRemoteSHA(ctx context.Context, store storage.Storage, repoRoot,
 	return nil
 }
 
+// isAncestor returns true if ancestor is reachable from descendant.
+// A stored SHA the repository no longer knows counts as not an ancestor.
+func isAncestor(v vcs.VCS, ancestor, descendant string) bool {
+	base, err := v.MergeBase(ancestor, descendant)
+	return err == nil && base != "" && base == ancestor
+}
+
 // ClearStoredRemoteSHA removes the stored remote SHA.
 // Use this when resetting the sync state (e.g., after accepting a rebase).
 func ClearStoredRemoteSHA(ctx context.Context, store storage.Storage) error {
@@ -0,0 +1,64 @@
+package syncbranch
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/steveyegge/beads/internal/git"
+	"github.com/steveyegge/beads/internal/vcs"
+
+	// Register the VCS backends
+	_ "github.com/steveyegge/beads/internal/vcs/git"
+	_ "github.com/steveyegge/beads/internal/vcs/jj"
+)
+
+// OpenVCS returns the VCS for a repository, sync worktree or jj workspace.
+// Colocated git+jj repos sync through git unless BD_VCS says otherwise, so
+// their sync branch stays a plain git branch.
+func OpenVCS(path string) (vcs.VCS, error) {
+	opts := []vcs.FactoryOption{vcs.WithCache(false)}
+	if os.Getenv("BD_VCS") == "" {
+		opts = append(opts, vcs.WithPreferredType(vcs.TypeGit))
+	}
+	return vcs.NewFactory(opts...).Create(path)
+}
+
+// isJJ returns true if v is the jj backend (colocated or not).
+func isJJ(v vcs.VCS) bool {
+	return v.Name() != vcs.TypeGit
+}
+
+// EnsureSyncWorkspace makes sure the sync branch has a healthy working copy
+// separate from the user's and returns its path. For git this is a worktree
+// under .git/beads-worktrees; for jj it is a jj workspace on the sync
+// bookmark under .jj/beads-workspaces.
+func EnsureSyncWorkspace(ctx context.Context, repoRoot, syncBranch string) (string, error) {
+	v, err := OpenVCS(repoRoot)
+	if err != nil {
+		return "", err
+	}
+
+	// GH#639: Use git-common-dir for worktree path to support bare repos
+	worktreePath := getBeadsWorktreePath(ctx, repoRoot, syncBranch)
+
+	if isJJ(v) {
+		ws, err := v.CreateWorkspace(vcs.WorkspaceOptions{
+			Name: syncBranch,
+			Path: worktreePath,
+			Ref:  syncBranch,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create workspace: %w", err)
+		}
+		return ws.Path(), nil
+	}
+
+	// CreateBeadsWorktree performs a full health check internally and
+	// automatically repairs unhealthy worktrees by removing and recreating them
+	wtMgr := git.NewWorktreeManager(repoRoot)
+	if err := wtMgr.CreateBeadsWorktree(syncBranch, worktreePath); err != nil {
+		return "", fmt.Errorf("failed to create worktree: %w", err)
+	}
+	return worktreePath, nil
+}
@@ -0,0 +1,236 @@
+package syncbranch
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/vcs"
+)
+
+// TestSyncRoundTrip_Git pushes issues from one clone through the sync branch
+// and pulls them into another.
+func TestSyncRoundTrip_Git(t *testing.T) {
+	alice, bob := newGitClones(t)
+	testSyncRoundTrip(t, context.Background(), alice, bob)
+}
+
+// TestSyncRoundTrip_JJ is the same round trip in plain jj repos, where the
+// sync branch is a bookmark checked out in a jj workspace.
+func TestSyncRoundTrip_JJ(t *testing.T) {
+	alice, bob := newJJClones(t)
+	testSyncRoundTrip(t, context.Background(), alice, bob)
+}
+
+// TestCheckForcePush_Git tracks the remote sync branch across a normal
+// update and a force push.
+func TestCheckForcePush_Git(t *testing.T) {
+	alice, bob := newGitClones(t)
+	testCheckForcePush(t, context.Background(), alice, bob, "~1")
+}
+
+// TestCheckForcePush_JJ is the same check against remote bookmarks.
+func TestCheckForcePush_JJ(t *testing.T) {
+	alice, bob := newJJClones(t)
+	testCheckForcePush(t, context.Background(), alice, bob, "-")
+}
+
+// newGitClones returns two git clones of a bare remote with a main branch.
+func newGitClones(t *testing.T) (string, string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	t.Setenv("BD_VCS", "")
+
+	tmpDir := t.TempDir()
+	remoteDir := filepath.Join(tmpDir, "remote.git")
+	runGit(t, tmpDir, "init", "--bare", "--initial-branch=main", remoteDir)
+
+	seed := filepath.Join(tmpDir, "seed")
+	runGit(t, tmpDir, "clone", remoteDir, seed)
+	runGit(t, seed, "config", "user.email", "test@test.com")
+	runGit(t, seed, "config", "user.name", "Test User")
+	writeFile(t, filepath.Join(seed, "README.md"), "# Test Repo")
+	runGit(t, seed, "add", ".")
+	runGit(t, seed, "commit", "-m", "initial commit")
+	runGit(t, seed, "push", "origin", "HEAD:main")
+
+	clone := func(name string) string {
+		dir := filepath.Join(tmpDir, name)
+		runGit(t, tmpDir, "clone", remoteDir, dir)
+		runGit(t, dir, "config", "user.email", "test@test.com")
+		runGit(t, dir, "config", "user.name", "Test User")
+		return dir
+	}
+	return clone("alice"), clone("bob")
+}
+
+// newJJClones returns two plain jj repos sharing a bare git remote.
+func newJJClones(t *testing.T) (string, string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	if !vcs.IsJJAvailable() {
+		t.Skip("jj not installed")
+	}
+	t.Setenv("BD_VCS", "jj")
+	t.Setenv("JJ_USER", "Test User")
+	t.Setenv("JJ_EMAIL", "test@test.com")
+
+	tmpDir := t.TempDir()
+	remoteDir := filepath.Join(tmpDir, "remote.git")
+	runGit(t, tmpDir, "init", "--bare", "--initial-branch=main", remoteDir)
+
+	clone := func(name string) string {
+		dir := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		runJJ(t, dir, "git", "init")
+		runJJ(t, dir, "git", "remote", "add", "origin", remoteDir)
+		writeFile(t, filepath.Join(dir, "README.md"), "# "+name)
+		runJJ(t, dir, "commit", "-m", "initial commit")
+		return dir
+	}
+	return clone("alice"), clone("bob")
+}
+
+func testSyncRoundTrip(t *testing.T, ctx context.Context, alice, bob string) {
+	t.Helper()
+
+	syncBranch := "beads-sync"
+	aliceJSONL := filepath.Join(alice, ".beads", "issues.jsonl")
+	bobJSONL := filepath.Join(bob, ".beads", "issues.jsonl")
+	if err := os.MkdirAll(filepath.Dir(bobJSONL), 0750); err != nil {
+		t.Fatalf("Failed to create .beads dir: %v", err)
+	}
+
+	writeFile(t, aliceJSONL, `{"id":"test-1"}`)
+	result, err := CommitToSyncBranch(ctx, alice, syncBranch, aliceJSONL, true)
+	if err != nil {
+		t.Fatalf("CommitToSyncBranch() error = %v", err)
+	}
+	if !result.Committed || !result.Pushed {
+		t.Fatalf("CommitToSyncBranch() = %+v, want committed and pushed", result)
+	}
+
+	pull, err := PullFromSyncBranch(ctx, bob, syncBranch, bobJSONL, false)
+	if err != nil {
+		t.Fatalf("PullFromSyncBranch() error = %v", err)
+	}
+	if !pull.Pulled {
+		t.Fatalf("PullFromSyncBranch() Pulled = false, want true")
+	}
+	data, err := os.ReadFile(bobJSONL)
+	if err != nil {
+		t.Fatalf("failed to read pulled JSONL: %v", err)
+	}
+	if !strings.Contains(string(data), "test-1") {
+		t.Errorf("pulled JSONL = %q, want test-1", data)
+	}
+
+	// Bob adds an issue and pushes it back
+	writeFile(t, bobJSONL, `{"id":"test-1"}`+"\n"+`{"id":"test-2"}`)
+	if _, err := CommitToSyncBranch(ctx, bob, syncBranch, bobJSONL, true); err != nil {
+		t.Fatalf("CommitToSyncBranch() from second clone error = %v", err)
+	}
+
+	if _, err := PullFromSyncBranch(ctx, alice, syncBranch, aliceJSONL, false); err != nil {
+		t.Fatalf("PullFromSyncBranch() into first clone error = %v", err)
+	}
+	data, err = os.ReadFile(aliceJSONL)
+	if err != nil {
+		t.Fatalf("failed to read pulled JSONL: %v", err)
+	}
+	if !strings.Contains(string(data), "test-2") {
+		t.Errorf("pulled JSONL = %q, want test-2", data)
+	}
+}
+
+// testCheckForcePush records alice's view of the remote sync branch, then
+// has bob update it normally and finally rewind it with a force push.
+// parent is the revision suffix that names a commit's parent.
+func testCheckForcePush(t *testing.T, ctx context.Context, alice, bob, parent string) {
+	t.Helper()
+
+	syncBranch := "beads-sync"
+	store := newTestStore(t)
+	defer store.Close()
+
+	aliceJSONL := filepath.Join(alice, ".beads", "issues.jsonl")
+	bobJSONL := filepath.Join(bob, ".beads", "issues.jsonl")
+	if err := os.MkdirAll(filepath.Dir(bobJSONL), 0750); err != nil {
+		t.Fatalf("Failed to create .beads dir: %v", err)
+	}
+
+	writeFile(t, aliceJSONL, `{"id":"test-1"}`)
+	if _, err := CommitToSyncBranch(ctx, alice, syncBranch, aliceJSONL, true); err != nil {
+		t.Fatalf("CommitToSyncBranch() error = %v", err)
+	}
+	if err := UpdateStoredRemoteSHA(ctx, store, alice, syncBranch); err != nil {
+		t.Fatalf("UpdateStoredRemoteSHA() error = %v", err)
+	}
+	first, _ := GetStoredRemoteSHA(ctx, store)
+
+	status, err := CheckForcePush(ctx, store, alice, syncBranch)
+	if err != nil {
+		t.Fatalf("CheckForcePush() error = %v", err)
+	}
+	if status.Detected || status.CurrentRemoteSHA != first {
+		t.Errorf("CheckForcePush() after own push = %+v, want unchanged at %s", status, first)
+	}
+
+	// Bob builds on alice's commit: a fast-forward
+	if _, err := PullFromSyncBranch(ctx, bob, syncBranch, bobJSONL, false); err != nil {
+		t.Fatalf("PullFromSyncBranch() error = %v", err)
+	}
+	writeFile(t, bobJSONL, `{"id":"test-1"}`+"\n"+`{"id":"test-2"}`)
+	if _, err := CommitToSyncBranch(ctx, bob, syncBranch, bobJSONL, true); err != nil {
+		t.Fatalf("CommitToSyncBranch() from second clone error = %v", err)
+	}
+
+	status, err = CheckForcePush(ctx, store, alice, syncBranch)
+	if err != nil {
+		t.Fatalf("CheckForcePush() error = %v", err)
+	}
+	if status.Detected || status.CurrentRemoteSHA == first {
+		t.Errorf("CheckForcePush() after fast-forward = %+v, want a normal update", status)
+	}
+	if err := UpdateStoredRemoteSHA(ctx, store, alice, syncBranch); err != nil {
+		t.Fatalf("UpdateStoredRemoteSHA() error = %v", err)
+	}
+
+	// Bob rewinds the remote sync branch to alice's commit
+	worktree, err := OpenVCS(getBeadsWorktreePath(ctx, bob, syncBranch))
+	if err != nil {
+		t.Fatalf("OpenVCS(sync worktree) error = %v", err)
+	}
+	if err := worktree.ResetRef(ctx, syncBranch, syncBranch+parent); err != nil {
+		t.Fatalf("ResetRef() error = %v", err)
+	}
+	if err := worktree.Push(ctx, vcs.PushOptions{Remote: "origin", Ref: syncBranch, Force: true}); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	status, err = CheckForcePush(ctx, store, alice, syncBranch)
+	if err != nil {
+		t.Fatalf("CheckForcePush() error = %v", err)
+	}
+	if !status.Detected || status.CurrentRemoteSHA != first {
+		t.Errorf("CheckForcePush() after force push = %+v, want detected at %s", status, first)
+	}
+}
+
+func runJJ(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("jj", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("jj %v failed: %v\n%s", args, err, output)
+	}
+}
@@ -3,18 +3,19 @@ package syncbranch
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/steveyegge/beads/internal/git"
 	"github.com/steveyegge/beads/internal/merge"
 	"github.com/steveyegge/beads/internal/utils"
+	"github.com/steveyegge/beads/internal/vcs"
 )
 
 // CommitResult contains information about a worktree commit operation
@@ -78,19 +79,15 @@ func CommitToSyncBranch(ctx context.Context, repoRoot, syncBranch, jsonlPath str
 		Branch: syncBranch,
 	}
 
-	// GH#639: Use git-common-dir for worktree path to support bare repos
-	worktreePath := getBeadsWorktreePath(ctx, repoRoot, syncBranch)
+	// Ensure the worktree (jj: workspace) exists and is healthy
+	worktreePath, err := EnsureSyncWorkspace(ctx, repoRoot, syncBranch)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize worktree manager
 	wtMgr := git.NewWorktreeManager(repoRoot)
 
-	// Ensure worktree exists and is healthy
-	// CreateBeadsWorktree performs a full health check internally and
-	// automatically repairs unhealthy worktrees by removing and recreating them
-	if err := wtMgr.CreateBeadsWorktree(syncBranch, worktreePath); err != nil {
-		return nil, fmt.Errorf("failed to create worktree: %w", err)
-	}
-
 	// Get remote name
 	remote := getRemoteForBranch(ctx, worktreePath, syncBranch)
 
@@ -158,15 +155,19 @@ func CommitToSyncBranch(ctx context.Context, repoRoot, syncBranch, jsonlPath str
 // This reduces divergence by keeping the local sync branch up-to-date before committing.
 // Returns nil on success, or error if fetch/ff fails (caller should treat as non-fatal).
 func preemptiveFetchAndFastForward(ctx context.Context, worktreePath, branch, remote string) error {
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return err
+	}
+
 	// Fetch from remote
-	fetchCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "fetch", remote, branch)
-	if output, err := fetchCmd.CombinedOutput(); err != nil {
-		// Check if remote branch doesn't exist yet (first sync)
-		if strings.Contains(string(output), "couldn't find remote ref") {
-			return nil // Not an error - remote branch doesn't exist yet
-		}
+	found, err := fetchSyncBranch(ctx, v, remote, branch)
+	if err != nil {
 		return fmt.Errorf("fetch failed: %w", err)
 	}
+	if !found {
+		return nil // Not an error - remote branch doesn't exist yet
+	}
 
 	// Check if we can fast-forward
 	localAhead, remoteAhead, err := getDivergence(ctx, worktreePath, branch, remote)
@@ -176,16 +177,34 @@ func preemptiveFetchAndFastForward(ctx context.Context, worktreePath, branch, re
 
 	// If remote has new commits and we have no local commits, fast-forward
 	if remoteAhead > 0 && localAhead == 0 {
-		mergeCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "merge", "--ff-only",
-			fmt.Sprintf("%s/%s", remote, branch))
-		if output, err := mergeCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("fast-forward failed: %w\n%s", err, output)
+		if err := fastForward(ctx, v, branch, remote); err != nil {
+			return fmt.Errorf("fast-forward failed: %w", err)
 		}
 	}
 
 	return nil
 }
 
+// fetchSyncBranch fetches the sync branch from remote. found is false when
+// the remote doesn't have the branch yet (first sync).
+func fetchSyncBranch(ctx context.Context, v vcs.VCS, remote, branch string) (bool, error) {
+	if err := v.Fetch(ctx, remote, branch); err != nil {
+		if strings.Contains(err.Error(), "couldn't find remote ref") {
+			return false, nil
+		}
+		return false, err
+	}
+	if _, err := v.GetCommitHash(v.RemoteRef(remote, branch)); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// fastForward moves the sync branch to the fetched remote branch.
+func fastForward(ctx context.Context, v vcs.VCS, branch, remote string) error {
+	return v.Pull(ctx, vcs.PullOptions{Remote: remote, Ref: branch, FFOnly: true})
+}
+
 // PullFromSyncBranch pulls changes from the sync branch and copies JSONL to the main repo.
 // This fetches remote changes without affecting the user's working directory.
 //
@@ -231,15 +250,10 @@ func PullFromSyncBranch(ctx context.Context, repoRoot, syncBranch, jsonlPath str
 		JSONLPath: jsonlPath,
 	}
 
-	// GH#639: Use git-common-dir for worktree path to support bare repos
-	worktreePath := getBeadsWorktreePath(ctx, repoRoot, syncBranch)
-
-	// Initialize worktree manager
-	wtMgr := git.NewWorktreeManager(repoRoot)
-
-	// Ensure worktree exists
-	if err := wtMgr.CreateBeadsWorktree(syncBranch, worktreePath); err != nil {
-		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	// Ensure the worktree (jj: workspace) exists
+	worktreePath, err := EnsureSyncWorkspace(ctx, repoRoot, syncBranch)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get remote name
@@ -251,16 +265,20 @@ func PullFromSyncBranch(ctx context.Context, repoRoot, syncBranch, jsonlPath str
 		return nil, fmt.Errorf("failed to get relative JSONL path: %w", err)
 	}
 
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+
 	// Step 1: Fetch from remote (don't pull - we handle merge ourselves)
-	fetchCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "fetch", remote, syncBranch)
-	if output, err := fetchCmd.CombinedOutput(); err != nil {
-		// Check if remote branch doesn't exist yet (first sync)
-		if strings.Contains(string(output), "couldn't find remote ref") {
-			// Remote branch doesn't exist - nothing to pull
-			result.Pulled = false
-			return result, nil
-		}
-		return nil, fmt.Errorf("git fetch failed in worktree: %w\n%s", err, output)
+	found, err := fetchSyncBranch(ctx, v, remote, syncBranch)
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed in worktree: %w", err)
+	}
+	if !found {
+		// Remote branch doesn't exist - nothing to pull
+		result.Pulled = false
+		return result, nil
 	}
 
 	// Step 2: Check for divergence
@@ -282,10 +300,8 @@ func PullFromSyncBranch(ctx context.Context, repoRoot, syncBranch, jsonlPath str
 	// Case 2: Can fast-forward (we have no local commits ahead of remote)
 	if localAhead == 0 {
 		// Simple fast-forward merge
-		mergeCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "merge", "--ff-only",
-			fmt.Sprintf("%s/%s", remote, syncBranch))
-		if output, err := mergeCmd.CombinedOutput(); err != nil {
-			return nil, fmt.Errorf("git merge --ff-only failed: %w\n%s", err, output)
+		if err := fastForward(ctx, v, syncBranch, remote); err != nil {
+			return nil, fmt.Errorf("fast-forward failed: %w", err)
 		}
 		result.Pulled = true
 		result.FastForwarded = true
@@ -305,7 +321,7 @@ func PullFromSyncBranch(ctx context.Context, repoRoot, syncBranch, jsonlPath str
 	// 4. Commit merged content on top
 
 	// Extract local content before merge for safety check
-	localContent, extractErr := extractJSONLFromCommit(ctx, worktreePath, "HEAD", jsonlRelPath)
+	localContent, extractErr := extractJSONLFromCommit(ctx, worktreePath, syncBranch, jsonlRelPath)
 	if extractErr != nil {
 		// Add warning to result so callers can display appropriately
 		result.SafetyWarnings = append(result.SafetyWarnings,
@@ -318,10 +334,8 @@ func PullFromSyncBranch(ctx context.Context, repoRoot, syncBranch, jsonlPath str
 	}
 
 	// Reset worktree to remote's history (adopt their commit graph)
-	resetCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "reset", "--hard",
-		fmt.Sprintf("%s/%s", remote, syncBranch))
-	if output, err := resetCmd.CombinedOutput(); err != nil {
-		return nil, fmt.Errorf("git reset failed: %w\n%s", err, output)
+	if err := v.ResetRef(ctx, syncBranch, v.RemoteRef(remote, syncBranch)); err != nil {
+		return nil, fmt.Errorf("reset failed: %w", err)
 	}
 
 	// Write merged content
@@ -414,35 +428,18 @@ func PullFromSyncBranch(ctx context.Context, repoRoot, syncBranch, jsonlPath str
 // getDivergence returns how many commits local is ahead and behind remote.
 // Returns (localAhead, remoteAhead, error)
 func getDivergence(ctx context.Context, worktreePath, branch, remote string) (int, int, error) {
-	// Use rev-list to count commits in each direction
-	// --left-right --count gives us "local\tremote"
-	cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "rev-list",
-		"--left-right", "--count",
-		fmt.Sprintf("HEAD...%s/%s", remote, branch))
-	output, err := cmd.Output()
-	if err != nil {
-		// If this fails, remote branch might not exist locally yet
-		// Check if it's a tracking issue
-		return 0, 0, fmt.Errorf("failed to get divergence: %w", err)
-	}
-
-	// Parse "N\tM" format
-	parts := strings.Fields(strings.TrimSpace(string(output)))
-	if len(parts) != 2 {
-		return 0, 0, fmt.Errorf("unexpected rev-list output: %s", output)
-	}
-
-	localAhead, err := strconv.Atoi(parts[0])
+	v, err := OpenVCS(worktreePath)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to parse local ahead count: %w", err)
+		return 0, 0, err
 	}
 
-	remoteAhead, err := strconv.Atoi(parts[1])
+	info, err := v.HasDivergence(branch, v.RemoteRef(remote, branch))
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to parse remote ahead count: %w", err)
+		// If this fails, remote branch might not exist locally yet
+		return 0, 0, fmt.Errorf("failed to get divergence: %w", err)
 	}
 
-	return localAhead, remoteAhead, nil
+	return info.LocalAhead, info.RemoteAhead, nil
 }
 
 // CheckDivergence checks the divergence between local sync branch and remote.
@@ -460,30 +457,29 @@ func CheckDivergence(ctx context.Context, repoRoot, syncBranch string) (*Diverge
 		Branch: syncBranch,
 	}
 
-	// GH#639: Use git-common-dir for worktree path to support bare repos
-	worktreePath := getBeadsWorktreePath(ctx, repoRoot, syncBranch)
-
-	// Initialize worktree manager
-	wtMgr := git.NewWorktreeManager(repoRoot)
-
-	// Ensure worktree exists
-	if err := wtMgr.CreateBeadsWorktree(syncBranch, worktreePath); err != nil {
-		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	// Ensure the worktree (jj: workspace) exists
+	worktreePath, err := EnsureSyncWorkspace(ctx, repoRoot, syncBranch)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get remote name
 	remote := getRemoteForBranch(ctx, worktreePath, syncBranch)
 	info.Remote = remote
 
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+
 	// Fetch from remote to get latest state
-	fetchCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "fetch", remote, syncBranch)
-	if output, err := fetchCmd.CombinedOutput(); err != nil {
-		// Check if remote branch doesn't exist yet (first sync)
-		if strings.Contains(string(output), "couldn't find remote ref") {
-			// Remote branch doesn't exist - no divergence possible
-			return info, nil
-		}
-		return nil, fmt.Errorf("git fetch failed: %w\n%s", err, output)
+	found, err := fetchSyncBranch(ctx, v, remote, syncBranch)
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+	if !found {
+		// Remote branch doesn't exist - no divergence possible
+		return info, nil
 	}
 
 	// Check for divergence
@@ -517,31 +513,32 @@ func CheckDivergence(ctx context.Context, repoRoot, syncBranch string) (*Diverge
 //
 // Returns error if reset fails.
 func ResetToRemote(ctx context.Context, repoRoot, syncBranch, jsonlPath string) error {
-	// GH#639: Use git-common-dir for worktree path to support bare repos
-	worktreePath := getBeadsWorktreePath(ctx, repoRoot, syncBranch)
-
-	// Initialize worktree manager
-	wtMgr := git.NewWorktreeManager(repoRoot)
-
-	// Ensure worktree exists
-	if err := wtMgr.CreateBeadsWorktree(syncBranch, worktreePath); err != nil {
-		return fmt.Errorf("failed to create worktree: %w", err)
+	// Ensure the worktree (jj: workspace) exists
+	worktreePath, err := EnsureSyncWorkspace(ctx, repoRoot, syncBranch)
+	if err != nil {
+		return err
 	}
 
 	// Get remote name
 	remote := getRemoteForBranch(ctx, worktreePath, syncBranch)
 
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return err
+	}
+
 	// Fetch from remote to get latest state
-	fetchCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "fetch", remote, syncBranch)
-	if output, err := fetchCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git fetch failed: %w\n%s", err, output)
+	found, err := fetchSyncBranch(ctx, v, remote, syncBranch)
+	if err != nil {
+		return fmt.Errorf("fetch failed: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("remote branch %s not found", v.RemoteRef(remote, syncBranch))
 	}
 
 	// Reset worktree to remote's state
-	resetCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "reset", "--hard",
-		fmt.Sprintf("%s/%s", remote, syncBranch))
-	if output, err := resetCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git reset failed: %w\n%s", err, output)
+	if err := v.ResetRef(ctx, syncBranch, v.RemoteRef(remote, syncBranch)); err != nil {
+		return fmt.Errorf("reset failed: %w", err)
 	}
 
 	// Convert absolute path to relative path from repo root
@@ -561,15 +558,18 @@ func ResetToRemote(ctx context.Context, repoRoot, syncBranch, jsonlPath string)
 // performContentMerge extracts JSONL from base, local, and remote, then merges content.
 // Returns the merged JSONL content.
 func performContentMerge(ctx context.Context, worktreePath, branch, remote, jsonlRelPath string) ([]byte, error) {
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	remoteRef := v.RemoteRef(remote, branch)
+
 	// Find merge base
-	mergeBaseCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "merge-base",
-		"HEAD", fmt.Sprintf("%s/%s", remote, branch))
-	mergeBaseOutput, err := mergeBaseCmd.Output()
+	mergeBase, err := v.MergeBase(branch, remoteRef)
 	if err != nil {
 		// No common ancestor - treat as empty base
-		mergeBaseOutput = nil
+		mergeBase = ""
 	}
-	mergeBase := strings.TrimSpace(string(mergeBaseOutput))
 
 	// Create temp files for 3-way merge
 	tmpDir, err := os.MkdirTemp("", "bd-merge-*")
@@ -600,8 +600,8 @@ func performContentMerge(ctx context.Context, worktreePath, branch, remote, json
 		}
 	}
 
-	// Extract local JSONL (current HEAD in worktree)
-	localContent, err := extractJSONLFromCommit(ctx, worktreePath, "HEAD", jsonlRelPath)
+	// Extract local JSONL (the sync branch in the worktree)
+	localContent, err := extractJSONLFromCommit(ctx, worktreePath, branch, jsonlRelPath)
 	if err != nil {
 		// Local file might not exist - use empty
 		localContent = []byte{}
@@ -611,7 +611,6 @@ func performContentMerge(ctx context.Context, worktreePath, branch, remote, json
 	}
 
 	// Extract remote JSONL
-	remoteRef := fmt.Sprintf("%s/%s", remote, branch)
 	remoteContent, err := extractJSONLFromCommit(ctx, worktreePath, remoteRef, jsonlRelPath)
 	if err != nil {
 		// Remote file might not exist - use empty
@@ -644,11 +643,13 @@ func performContentMerge(ctx context.Context, worktreePath, branch, remote, json
 	return mergedContent, nil
 }
 
-// extractJSONLFromCommit extracts a file's content from a specific git commit.
+// extractJSONLFromCommit extracts a file's content from a specific commit.
 func extractJSONLFromCommit(ctx context.Context, worktreePath, commit, filePath string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "show",
-		fmt.Sprintf("%s:%s", commit, filePath))
-	output, err := cmd.Output()
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	output, err := v.ExtractFileFromRef(commit, filepath.ToSlash(filePath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract %s from %s: %w", filePath, commit, err)
 	}
@@ -696,47 +697,48 @@ func copyJSONLToMainRepo(worktreePath, jsonlRelPath, jsonlPath string) error {
 
 // hasChangesInWorktree checks if there are uncommitted changes in the worktree
 func hasChangesInWorktree(ctx context.Context, worktreePath, filePath string) (bool, error) {
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return false, err
+	}
+
 	// Check the entire .beads directory for changes
 	beadsDir := filepath.Dir(filePath)
-	relBeadsDir, err := filepath.Rel(worktreePath, beadsDir)
+	relPath, err := filepath.Rel(worktreePath, beadsDir)
 	if err != nil {
 		// Fallback to checking just the file
-		relPath, err := filepath.Rel(worktreePath, filePath)
+		relPath, err = filepath.Rel(worktreePath, filePath)
 		if err != nil {
 			return false, fmt.Errorf("failed to make path relative: %w", err)
 		}
-		cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "status", "--porcelain", relPath)
-		output, err := cmd.Output()
-		if err != nil {
-			return false, fmt.Errorf("git status failed in worktree: %w", err)
-		}
-		return len(strings.TrimSpace(string(output))) > 0, nil
 	}
 
-	cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "status", "--porcelain", relBeadsDir)
-	output, err := cmd.Output()
+	hasChanges, err := v.HasChanges(relPath)
 	if err != nil {
-		return false, fmt.Errorf("git status failed in worktree: %w", err)
+		return false, fmt.Errorf("status failed in worktree: %w", err)
 	}
-	return len(strings.TrimSpace(string(output))) > 0, nil
+	return hasChanges, nil
 }
 
 // commitInWorktree stages and commits changes in the worktree
 func commitInWorktree(ctx context.Context, worktreePath, jsonlRelPath, message string) error {
-	// Stage the entire .beads directory
-	beadsRelDir := filepath.Dir(jsonlRelPath)
-
-	addCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "add", beadsRelDir)
-	if err := addCmd.Run(); err != nil {
-		return fmt.Errorf("git add failed in worktree: %w", err)
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return err
 	}
 
-	// Commit with --no-verify to skip hooks (pre-commit hook would fail in worktree context)
-	// The worktree is internal to bd sync, so we don't need to run bd's pre-commit hook
-	commitCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "commit", "--no-verify", "-m", message)
-	output, err := commitCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git commit failed in worktree: %w\n%s", err, output)
+	// Commit the entire .beads directory, with NoVerify to skip hooks (pre-commit
+	// hook would fail in worktree context). The worktree is internal to bd sync,
+	// so we don't need to run bd's pre-commit hook. CreateNew advances the jj
+	// bookmark to the new commit.
+	beadsRelDir := filepath.Dir(jsonlRelPath)
+	if err := v.Commit(ctx, vcs.CommitOptions{
+		Message:   message,
+		Paths:     []string{beadsRelDir},
+		NoVerify:  true,
+		CreateNew: true,
+	}); err != nil {
+		return fmt.Errorf("commit failed in worktree: %w", err)
 	}
 
 	return nil
@@ -768,10 +770,14 @@ func contentMergeRecovery(ctx context.Context, worktreePath, branch, remote stri
 	// The JSONL is always at .beads/issues.jsonl relative to worktree
 	jsonlRelPath := filepath.Join(".beads", "issues.jsonl")
 
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return err
+	}
+
 	// Step 1: Fetch latest from remote
-	fetchCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "fetch", remote, branch)
-	if output, err := fetchCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("fetch failed: %w\n%s", err, output)
+	if err := v.Fetch(ctx, remote, branch); err != nil {
+		return fmt.Errorf("fetch failed: %w", err)
 	}
 
 	// Step 2: Perform content-level merge (same algorithm as PullFromSyncBranch)
@@ -781,10 +787,8 @@ func contentMergeRecovery(ctx context.Context, worktreePath, branch, remote stri
 	}
 
 	// Step 3: Reset worktree to remote's history (adopt their commit graph)
-	resetCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "reset", "--hard",
-		fmt.Sprintf("%s/%s", remote, branch))
-	if output, err := resetCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git reset failed: %w\n%s", err, output)
+	if err := v.ResetRef(ctx, branch, v.RemoteRef(remote, branch)); err != nil {
+		return fmt.Errorf("reset failed: %w", err)
 	}
 
 	// Step 4: Write merged content
@@ -825,55 +829,75 @@ func contentMergeRecovery(ctx context.Context, worktreePath, branch, remote stri
 //
 // Returns: combined output and error from the command
 func runCmdWithTimeoutMessage(ctx context.Context, timeoutMsg string, timeoutDelay time.Duration, cmd *exec.Cmd) ([]byte, error) {
-	// Use done channel to cleanly exit goroutine when command completes
+	var output []byte
+	err := runWithTimeoutMessage(ctx, timeoutMsg, timeoutDelay, func() error {
+		var err error
+		output, err = cmd.CombinedOutput()
+		return err
+	})
+	return output, err
+}
+
+// runWithTimeoutMessage runs fn and prints timeoutMsg if it takes longer
+// than timeoutDelay.
+func runWithTimeoutMessage(ctx context.Context, timeoutMsg string, timeoutDelay time.Duration, fn func() error) error {
+	// Use done channel to cleanly exit goroutine when fn completes
 	done := make(chan struct{})
 	go func() {
 		select {
 		case <-time.After(timeoutDelay):
 			fmt.Fprintf(os.Stderr, "⏳ %s\n", timeoutMsg)
 		case <-done:
-			// Command completed, exit cleanly
+			// Completed, exit cleanly
 		case <-ctx.Done():
 			// Context canceled, don't print message
 		}
 	}()
 
-	output, err := cmd.CombinedOutput()
+	err := fn()
 	close(done)
-	return output, err
+	return err
 }
 
 // pushFromWorktree pushes the sync branch from the worktree with retry logic
 // for handling concurrent push conflicts (non-fast-forward errors).
 func pushFromWorktree(ctx context.Context, worktreePath, branch string) error {
+	v, err := OpenVCS(worktreePath)
+	if err != nil {
+		return err
+	}
+
 	remote := getRemoteForBranch(ctx, worktreePath, branch)
 	maxRetries := 5
 
 	var lastErr error
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		// Push with explicit remote and branch, set upstream if not set
-		cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "push", "--set-upstream", remote, branch)
+		// Push with explicit remote and branch, set upstream if not set.
 		// Set BD_SYNC_IN_PROGRESS so pre-push hook knows to skip checks (GH#532)
 		// This prevents circular error where hook suggests running bd sync
-		cmd.Env = append(os.Environ(), "BD_SYNC_IN_PROGRESS=1")
+		opts := vcs.PushOptions{
+			Remote:      remote,
+			Ref:         branch,
+			SetUpstream: true,
+			Env:         []string{"BD_SYNC_IN_PROGRESS=1"},
+		}
 
 		// Run with timeout message in case of hanging auth
-		output, err := runCmdWithTimeoutMessage(
+		err := runWithTimeoutMessage(
 			ctx,
-			fmt.Sprintf("Git push is waiting (possibly for authentication). If this hangs, check for a browser auth prompt."),
+			"Push is waiting (possibly for authentication). If this hangs, check for a browser auth prompt.",
 			5*time.Second,
-			cmd,
+			func() error { return v.Push(ctx, opts) },
 		)
 
 		if err == nil {
 			return nil // Success
 		}
 
-		outputStr := string(output)
-		lastErr = fmt.Errorf("git push failed from worktree: %w\n%s", err, outputStr)
+		lastErr = fmt.Errorf("push failed from worktree: %w", err)
 
 		// Check if this is a non-fast-forward error (concurrent push conflict)
-		if isNonFastForwardError(outputStr) {
+		if errors.Is(err, vcs.ErrPushRejected) || isNonFastForwardError(err.Error()) {
 			// Use content-level merge instead of git rebase.
 			// Git rebase is text-level and can resurrect tombstones.
 			if mergeErr := contentMergeRecovery(ctx, worktreePath, branch, remote); mergeErr != nil {
@@ -890,13 +914,13 @@ Recovery options:
      bd sync --force-push
 
   3. Manual recovery in the sync branch worktree:
-     cd .git/beads-worktrees/%s
+     cd %s
      git status
      # Resolve conflicts manually, then:
      bd sync
 
 Original error: %v
-Merge error: %v`, branch, remote, branch, branch, lastErr, mergeErr)
+Merge error: %v`, branch, remote, branch, worktreePath, lastErr, mergeErr)
 			}
 			// Content merge succeeded - retry push immediately (no backoff needed)
 			continue
@@ -923,12 +947,9 @@ Merge error: %v`, branch, remote, branch, branch, lastErr, mergeErr)
 //
 // Returns error if push fails.
 func PushSyncBranch(ctx context.Context, repoRoot, syncBranch string) error {
-	// Worktree path is under .git/beads-worktrees/<branch>
-	worktreePath := filepath.Join(repoRoot, ".git", "beads-worktrees", syncBranch)
-
 	// Recreate worktree if it was cleaned up, using the same pattern as CommitToSyncBranch
-	wtMgr := git.NewWorktreeManager(repoRoot)
-	if err := wtMgr.CreateBeadsWorktree(syncBranch, worktreePath); err != nil {
+	worktreePath, err := EnsureSyncWorkspace(ctx, repoRoot, syncBranch)
+	if err != nil {
 		return fmt.Errorf("failed to ensure worktree exists: %w", err)
 	}
 
@@ -936,21 +957,20 @@ func PushSyncBranch(ctx context.Context, repoRoot, syncBranch string) error {
 }
 
 // getBeadsWorktreePath returns the path where beads worktrees should be stored.
-// GH#639: Uses git rev-parse --git-common-dir to correctly handle bare repos and worktrees.
+// GH#639: Uses the git common dir to correctly handle bare repos and worktrees.
 // For regular repos, this is typically .git/beads-worktrees/<branch>.
 // For bare repos or worktrees of bare repos, this uses the common git directory.
+// For jj repos, this is the jj workspace directory .jj/beads-workspaces/<branch>.
 func getBeadsWorktreePath(ctx context.Context, repoRoot, syncBranch string) string {
-	// Try to get the git common directory using git's native API
-	// This handles all cases: regular repos, worktrees, bare repos
-	cmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "rev-parse", "--git-common-dir")
-	output, err := cmd.Output()
-	if err == nil {
-		gitCommonDir := strings.TrimSpace(string(output))
-		// Make path absolute if it's relative
-		if !filepath.IsAbs(gitCommonDir) {
-			gitCommonDir = filepath.Join(repoRoot, gitCommonDir)
+	if v, err := OpenVCS(repoRoot); err == nil {
+		if isJJ(v) {
+			if jjDir, err := v.VCSDir(); err == nil {
+				return filepath.Join(jjDir, "beads-workspaces", syncBranch)
+			}
+		} else if gitCommonDir, err := v.CommonDir(); err == nil {
+			// This handles all cases: regular repos, worktrees, bare repos
+			return filepath.Join(gitCommonDir, "beads-worktrees", syncBranch)
 		}
-		return filepath.Join(gitCommonDir, "beads-worktrees", syncBranch)
 	}
 
 	// Fallback to legacy behavior for compatibility
@@ -959,12 +979,15 @@ func getBeadsWorktreePath(ctx context.Context, repoRoot, syncBranch string) stri
 
 // getRemoteForBranch gets the remote name for a branch, defaulting to "origin"
 func getRemoteForBranch(ctx context.Context, worktreePath, branch string) string {
-	remoteCmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "config", "--get", fmt.Sprintf("branch.%s.remote", branch))
-	remoteOutput, err := remoteCmd.Output()
+	v, err := OpenVCS(worktreePath)
 	if err != nil {
 		return "origin" // Default
 	}
-	return strings.TrimSpace(string(remoteOutput))
+	remote, err := v.Upstream(branch)
+	if err != nil || remote == "" {
+		return "origin" // Default
+	}
+	return remote
 }
 
 // GetRepoRoot returns the git repository root directory
@@ -1001,14 +1024,15 @@ func GetRepoRoot(ctx context.Context) (string, error) {
 		}
 	}
 
-	// Fallback to git command if not determined above
+	// Fall back to VCS detection if not determined above (subdirectories, jj repos)
 	if repoRoot == "" {
-		cmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel")
-		output, err := cmd.Output()
+		v, err := OpenVCS(".")
 		if err != nil {
 			return "", fmt.Errorf("not a git repository: %w", err)
 		}
-		repoRoot = strings.TrimSpace(string(output))
+		if repoRoot, err = v.RepoRoot(); err != nil {
+			return "", fmt.Errorf("not a git repository: %w", err)
+		}
 	}
 
 	// Canonicalize path to fix case on macOS/Windows (GH#880)
@@ -1109,22 +1133,28 @@ func normalizeBeadsRelPath(relPath string) string {
 
 // HasGitRemote checks if any git remote exists
 func HasGitRemote(ctx context.Context) bool {
-	cmd := exec.CommandContext(ctx, "git", "remote")
-	output, err := cmd.Output()
+	v, err := OpenVCS(".")
 	if err != nil {
 		return false
 	}
-	return len(strings.TrimSpace(string(output))) > 0
+	return v.HasRemote()
 }
 
-// GetCurrentBranch returns the name of the current git branch
+// GetCurrentBranch returns the name of the current git branch (jj: the
+// bookmark the working copy is on)
 func GetCurrentBranch(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--short", "HEAD")
-	output, err := cmd.Output()
+	v, err := OpenVCS(".")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	branch, err := v.CurrentRef()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if branch == "" {
+		return "", fmt.Errorf("failed to get current branch: not on a branch")
+	}
+	return branch, nil
 }
 
 // IsSyncBranchSameAsCurrent returns true if the sync branch is the same as the current branch.
@@ -0,0 +1,641 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// column is one status lane of the board.
+type column struct {
+	status types.Status
+	issues []*types.Issue
+	offset int // First visible row
+}
+
+// editField is the field being edited inline, if any.
+type editField int
+
+const (
+	editNone editField = iota
+	editPriority
+	editAssignee
+	editLabels
+)
+
+type (
+	issuesLoadedMsg struct {
+		issues []*types.Issue
+		at     time.Time
+		err    error
+	}
+	detailsLoadedMsg struct {
+		details *types.IssueDetails
+		err     error
+	}
+	editAppliedMsg struct {
+		id   string
+		what string
+		err  error
+	}
+	changedMsg struct {
+		changed bool
+		err     error
+	}
+	tickMsg time.Time
+)
+
+// Board is the bubbletea model of the kanban board.
+type Board struct {
+	ctx  context.Context
+	src  Source
+	opts Options
+
+	columns  []column
+	col, row int
+	width    int
+	height   int
+
+	showDetail   bool
+	details      *types.IssueDetails
+	detailScroll int
+
+	editing editField
+	input   textinput.Model
+
+	showHelp    bool
+	message     string
+	loaded      bool
+	lastRefresh time.Time
+}
+
+// NewBoard creates a board over src.
+func NewBoard(ctx context.Context, src Source, opts Options) *Board {
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = 2 * time.Second
+	}
+	b := &Board{ctx: ctx, src: src, opts: opts, width: 120, height: 30}
+	for _, s := range opts.Statuses {
+		b.columns = append(b.columns, column{status: s})
+	}
+	b.input = textinput.New()
+	b.input.CharLimit = 200
+	return b
+}
+
+// Run shows the board full-screen until the user quits.
+func Run(ctx context.Context, src Source, opts Options) error {
+	_, err := tea.NewProgram(NewBoard(ctx, src, opts), tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	return err
+}
+
+// Init loads the issues and starts the refresh timer.
+func (b *Board) Init() tea.Cmd {
+	return tea.Batch(b.load(), b.tick())
+}
+
+func (b *Board) load() tea.Cmd {
+	return func() tea.Msg {
+		at := time.Now()
+		issues, err := b.src.ListIssues(b.ctx)
+		return issuesLoadedMsg{issues: issues, at: at, err: err}
+	}
+}
+
+func (b *Board) tick() tea.Cmd {
+	return tea.Tick(b.opts.RefreshInterval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (b *Board) loadDetails(id string) tea.Cmd {
+	return func() tea.Msg {
+		details, err := b.src.IssueDetails(b.ctx, id)
+		return detailsLoadedMsg{details: details, err: err}
+	}
+}
+
+func (b *Board) apply(id, what string, edit Edit) tea.Cmd {
+	return func() tea.Msg {
+		return editAppliedMsg{id: id, what: what, err: b.src.ApplyEdit(b.ctx, id, edit)}
+	}
+}
+
+// Update handles messages and keys.
+func (b *Board) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.width, b.height = msg.Width, msg.Height
+		b.clampScroll()
+		return b, nil
+
+	case issuesLoadedMsg:
+		if msg.err != nil {
+			b.message = "Error loading issues: " + msg.err.Error()
+			return b, nil
+		}
+		b.setIssues(msg.issues)
+		b.loaded = true
+		b.lastRefresh = msg.at
+		if b.showDetail {
+			if issue := b.selected(); issue != nil {
+				return b, b.loadDetails(issue.ID)
+			}
+		}
+		return b, nil
+
+	case detailsLoadedMsg:
+		if msg.err != nil {
+			b.message = "Error loading details: " + msg.err.Error()
+			return b, nil
+		}
+		if issue := b.selected(); issue != nil && msg.details != nil && msg.details.ID == issue.ID {
+			b.details = msg.details
+		}
+		return b, nil
+
+	case editAppliedMsg:
+		if msg.err != nil {
+			b.message = fmt.Sprintf("Error updating %s: %v", msg.id, msg.err)
+		} else {
+			b.message = fmt.Sprintf("%s: %s", msg.id, msg.what)
+		}
+		return b, b.load()
+
+	case tickMsg:
+		since := b.lastRefresh
+		return b, tea.Batch(b.tick(), func() tea.Msg {
+			changed, err := b.src.Changed(b.ctx, since)
+			return changedMsg{changed: changed, err: err}
+		})
+
+	case changedMsg:
+		if msg.err == nil && msg.changed {
+			return b, b.load()
+		}
+		return b, nil
+
+	case tea.KeyMsg:
+		if b.editing != editNone {
+			return b.updateEditing(msg)
+		}
+		return b.updateKeys(msg)
+	}
+	return b, nil
+}
+
+func (b *Board) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if b.showHelp {
+		b.showHelp = false
+		return b, nil
+	}
+	b.message = ""
+	issue := b.selected()
+
+	switch key := msg.String(); key {
+	case "q", "ctrl+c":
+		return b, tea.Quit
+	case "?":
+		b.showHelp = true
+	case "left", "h":
+		b.moveCursor(-1, 0)
+		return b, b.followSelection()
+	case "right", "l":
+		b.moveCursor(1, 0)
+		return b, b.followSelection()
+	case "up", "k":
+		b.moveCursor(0, -1)
+		return b, b.followSelection()
+	case "down", "j":
+		b.moveCursor(0, 1)
+		return b, b.followSelection()
+	case "g", "home":
+		b.row = 0
+		b.clampScroll()
+		return b, b.followSelection()
+	case "G", "end":
+		b.row = len(b.columns[b.col].issues) - 1
+		b.clampScroll()
+		return b, b.followSelection()
+	case "<", ">", "shift+left", "shift+right":
+		delta := 1
+		if key == "<" || key == "shift+left" {
+			delta = -1
+		}
+		return b, b.moveIssue(delta)
+	case "0", "1", "2", "3", "4":
+		if issue != nil {
+			p, _ := strconv.Atoi(key)
+			if p != issue.Priority {
+				issue.Priority = p
+				return b, b.apply(issue.ID, fmt.Sprintf("priority → P%d", p), Edit{Priority: &p})
+			}
+		}
+	case "p":
+		if issue != nil {
+			b.startEdit(editPriority, "Priority (0-4): ", strconv.Itoa(issue.Priority))
+			return b, textinput.Blink
+		}
+	case "a":
+		if issue != nil {
+			b.startEdit(editAssignee, "Assignee: ", issue.Assignee)
+			return b, textinput.Blink
+		}
+	case "L":
+		if issue != nil {
+			b.startEdit(editLabels, "Labels (comma-separated): ", strings.Join(issue.Labels, ", "))
+			return b, textinput.Blink
+		}
+	case "enter", " ":
+		b.showDetail = !b.showDetail
+		b.details = nil
+		b.detailScroll = 0
+		return b, b.followSelection()
+	case "esc":
+		b.showDetail = false
+	case "pgdown", "ctrl+d":
+		b.detailScroll += 5
+	case "pgup", "ctrl+u":
+		b.detailScroll = max(0, b.detailScroll-5)
+	case "r":
+		b.message = "Refreshing…"
+		return b, b.load()
+	}
+	return b, nil
+}
+
+func (b *Board) startEdit(field editField, prompt, value string) {
+	b.editing = field
+	b.input.Prompt = prompt
+	b.input.SetValue(value)
+	b.input.CursorEnd()
+	b.input.Focus()
+}
+
+func (b *Board) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		b.editing = editNone
+		b.input.Blur()
+		return b, nil
+	case "enter":
+		field := b.editing
+		b.editing = editNone
+		b.input.Blur()
+		issue := b.selected()
+		if issue == nil {
+			return b, nil
+		}
+		edit, what, err := buildEdit(field, issue, b.input.Value())
+		if err != nil {
+			b.message = err.Error()
+			return b, nil
+		}
+		if edit == nil {
+			return b, nil
+		}
+		return b, b.apply(issue.ID, what, *edit)
+	}
+	var cmd tea.Cmd
+	b.input, cmd = b.input.Update(msg)
+	return b, cmd
+}
+
+// buildEdit turns the text typed for an inline edit into an Edit. It returns
+// a nil edit when nothing changed.
+func buildEdit(field editField, issue *types.Issue, value string) (*Edit, string, error) {
+	value = strings.TrimSpace(value)
+	switch field {
+	case editPriority:
+		p, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(value), "P"))
+		if err != nil || p < 0 || p > 4 {
+			return nil, "", fmt.Errorf("priority must be 0-4, got %q", value)
+		}
+		if p == issue.Priority {
+			return nil, "", nil
+		}
+		return &Edit{Priority: &p}, fmt.Sprintf("priority → P%d", p), nil
+	case editAssignee:
+		if value == issue.Assignee {
+			return nil, "", nil
+		}
+		what := "assignee → " + value
+		if value == "" {
+			what = "unassigned"
+		}
+		return &Edit{Assignee: &value}, what, nil
+	case editLabels:
+		want := make(map[string]bool)
+		var ordered []string
+		for _, l := range strings.Split(value, ",") {
+			if l = strings.TrimSpace(l); l != "" && !want[l] {
+				want[l] = true
+				ordered = append(ordered, l)
+			}
+		}
+		have := make(map[string]bool)
+		edit := &Edit{}
+		for _, l := range issue.Labels {
+			have[l] = true
+			if !want[l] {
+				edit.RemoveLabels = append(edit.RemoveLabels, l)
+			}
+		}
+		for _, l := range ordered {
+			if !have[l] {
+				edit.AddLabels = append(edit.AddLabels, l)
+			}
+		}
+		if len(edit.AddLabels) == 0 && len(edit.RemoveLabels) == 0 {
+			return nil, "", nil
+		}
+		return edit, "labels → " + strings.Join(ordered, ", "), nil
+	}
+	return nil, "", nil
+}
+
+// moveIssue moves the selected issue to the adjacent column, changing its
+// status. The cursor follows the issue.
+func (b *Board) moveIssue(delta int) tea.Cmd {
+	issue := b.selected()
+	target := b.col + delta
+	if issue == nil || target < 0 || target >= len(b.columns) {
+		return nil
+	}
+	status := b.columns[target].status
+	from := &b.columns[b.col]
+	from.issues = append(from.issues[:b.row:b.row], from.issues[b.row+1:]...)
+	issue.Status = status
+	to := &b.columns[target]
+	to.issues = append(to.issues, issue)
+	sortIssues(to.issues)
+	b.selectID(issue.ID)
+	return b.apply(issue.ID, "status → "+string(status), Edit{Status: &status})
+}
+
+// setIssues regroups issues into the columns, keeping the selection on the
+// same issue when it is still on the board.
+func (b *Board) setIssues(issues []*types.Issue) {
+	var selectedID string
+	if issue := b.selected(); issue != nil {
+		selectedID = issue.ID
+	}
+	byStatus := groupByStatus(issues, b.opts.Statuses)
+	for i := range b.columns {
+		b.columns[i].issues = byStatus[b.columns[i].status]
+	}
+	if selectedID == "" || !b.selectID(selectedID) {
+		b.moveCursor(0, 0)
+	}
+}
+
+// groupByStatus buckets issues into the given statuses, sorted by priority
+// and then most recently updated. Issues in other statuses are dropped.
+func groupByStatus(issues []*types.Issue, statuses []types.Status) map[types.Status][]*types.Issue {
+	byStatus := make(map[types.Status][]*types.Issue, len(statuses))
+	for _, s := range statuses {
+		byStatus[s] = nil
+	}
+	for _, issue := range issues {
+		if _, ok := byStatus[issue.Status]; ok {
+			byStatus[issue.Status] = append(byStatus[issue.Status], issue)
+		}
+	}
+	for _, list := range byStatus {
+		sortIssues(list)
+	}
+	return byStatus
+}
+
+func sortIssues(issues []*types.Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Priority != issues[j].Priority {
+			return issues[i].Priority < issues[j].Priority
+		}
+		if !issues[i].UpdatedAt.Equal(issues[j].UpdatedAt) {
+			return issues[i].UpdatedAt.After(issues[j].UpdatedAt)
+		}
+		return issues[i].ID < issues[j].ID
+	})
+}
+
+func (b *Board) selected() *types.Issue {
+	if b.col < 0 || b.col >= len(b.columns) {
+		return nil
+	}
+	issues := b.columns[b.col].issues
+	if b.row < 0 || b.row >= len(issues) {
+		return nil
+	}
+	return issues[b.row]
+}
+
+func (b *Board) selectID(id string) bool {
+	for c := range b.columns {
+		for r, issue := range b.columns[c].issues {
+			if issue.ID == id {
+				b.col, b.row = c, r
+				b.clampScroll()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (b *Board) moveCursor(dCol, dRow int) {
+	if len(b.columns) == 0 {
+		return
+	}
+	b.col = min(max(b.col+dCol, 0), len(b.columns)-1)
+	b.row = min(max(b.row+dRow, 0), max(len(b.columns[b.col].issues)-1, 0))
+	b.clampScroll()
+}
+
+// followSelection reloads the detail pane for the newly selected issue.
+func (b *Board) followSelection() tea.Cmd {
+	if !b.showDetail {
+		return nil
+	}
+	b.detailScroll = 0
+	issue := b.selected()
+	if issue == nil {
+		b.details = nil
+		return nil
+	}
+	if b.details != nil && b.details.ID == issue.ID {
+		return nil
+	}
+	return b.loadDetails(issue.ID)
+}
+
+// cardRows is how many cards fit in a column.
+func (b *Board) cardRows() int {
+	return max(b.height-4, 1) // title, column header, status line, input line
+}
+
+func (b *Board) clampScroll() {
+	if b.col >= len(b.columns) {
+		return
+	}
+	c := &b.columns[b.col]
+	rows := b.cardRows()
+	if b.row < c.offset {
+		c.offset = b.row
+	}
+	if b.row >= c.offset+rows {
+		c.offset = b.row - rows + 1
+	}
+	c.offset = max(c.offset, 0)
+}
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(ui.ColorAccent)
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	mutedStyle    = lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	paneStyle     = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderLeft(true).PaddingLeft(1)
+)
+
+// View renders the board.
+func (b *Board) View() string {
+	if b.showHelp {
+		return helpText
+	}
+	if !b.loaded {
+		if b.message != "" {
+			return b.message + "\n\nPress q to quit."
+		}
+		return "Loading issues…"
+	}
+
+	total := 0
+	for _, c := range b.columns {
+		total += len(c.issues)
+	}
+	title := titleStyle.Render("beads board") + mutedStyle.Render(fmt.Sprintf("  %d issues  ·  ? for help", total))
+
+	boardWidth := b.width
+	detailWidth := 0
+	if b.showDetail {
+		detailWidth = b.width * 2 / 5
+		boardWidth = b.width - detailWidth
+	}
+	colWidth := max(boardWidth/max(len(b.columns), 1), 12)
+
+	lanes := make([]string, len(b.columns))
+	for i, c := range b.columns {
+		lanes[i] = b.renderColumn(i, c, colWidth)
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top, lanes...)
+	if b.showDetail {
+		pane := paneStyle.Width(detailWidth - 2).Height(b.cardRows() + 1).Render(b.renderDetails(detailWidth - 3))
+		body = lipgloss.JoinHorizontal(lipgloss.Top, body, pane)
+	}
+
+	footer := mutedStyle.Render("←→ column  ↑↓ issue  </> move  0-4/p priority  a assignee  L labels  enter details  q quit")
+	if b.editing != editNone {
+		footer = b.input.View()
+	} else if b.message != "" {
+		footer = b.message
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, title, body, footer)
+}
+
+func (b *Board) renderColumn(index int, c column, width int) string {
+	header := headerStyle.Render(ansi.Truncate(fmt.Sprintf("%s (%d)", c.status, len(c.issues)), width-1, "…"))
+	lines := []string{header}
+	end := min(c.offset+b.cardRows(), len(c.issues))
+	for r := c.offset; r < end; r++ {
+		issue := c.issues[r]
+		card := fmt.Sprintf("P%d %s %s", issue.Priority, issue.ID, issue.Title)
+		card = ansi.Truncate(card, width-1, "…")
+		if index == b.col && r == b.row {
+			card = selectedStyle.Render(card)
+		}
+		lines = append(lines, card)
+	}
+	if len(c.issues) == 0 {
+		lines = append(lines, mutedStyle.Render("—"))
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n"))
+}
+
+func (b *Board) renderDetails(width int) string {
+	d := b.details
+	if d == nil {
+		return mutedStyle.Render("Loading…")
+	}
+	wrap := lipgloss.NewStyle().Width(width)
+	var lines []string
+	add := func(s string) { lines = append(lines, strings.Split(wrap.Render(s), "\n")...) }
+
+	add(titleStyle.Render(d.ID) + " " + headerStyle.Render(d.Title))
+	add(fmt.Sprintf("%s · P%d · %s", d.Status, d.Priority, d.IssueType))
+	if d.Assignee != "" {
+		add("Assignee: " + d.Assignee)
+	}
+	if len(d.Labels) > 0 {
+		add("Labels: " + strings.Join(d.Labels, ", "))
+	}
+	if d.Description != "" {
+		add("")
+		add(d.Description)
+	}
+	if len(d.Dependencies) > 0 {
+		add("")
+		add(headerStyle.Render("Depends on"))
+		for _, dep := range d.Dependencies {
+			add(fmt.Sprintf("  %s %s [%s] %s", dep.DependencyType, dep.ID, dep.Status, dep.Title))
+		}
+	}
+	if len(d.Dependents) > 0 {
+		add("")
+		add(headerStyle.Render("Dependents"))
+		for _, dep := range d.Dependents {
+			add(fmt.Sprintf("  %s %s [%s] %s", dep.DependencyType, dep.ID, dep.Status, dep.Title))
+		}
+	}
+	if len(d.Comments) > 0 {
+		add("")
+		add(headerStyle.Render(fmt.Sprintf("Comments (%d)", len(d.Comments))))
+		for _, c := range d.Comments {
+			add(mutedStyle.Render(fmt.Sprintf("%s, %s", c.Author, c.CreatedAt.Format("2006-01-02 15:04"))))
+			add("  " + c.Text)
+		}
+	}
+
+	b.detailScroll = min(b.detailScroll, max(len(lines)-1, 0))
+	lines = lines[b.detailScroll:]
+	if rows := b.cardRows() + 1; len(lines) > rows {
+		lines = lines[:rows]
+	}
+	return strings.Join(lines, "\n")
+}
+
+const helpText = `beads board
+
+  ← → / h l        previous / next column
+  ↑ ↓ / j k        previous / next issue
+  g / G            first / last issue in column
+  < > / shift+← →  move issue to the previous / next column (changes status)
+  0-4              set priority
+  p                edit priority
+  a                edit assignee (empty to unassign)
+  L                edit labels (comma-separated)
+  enter / space    toggle the detail pane
+  pgup / pgdown    scroll the detail pane
+  r                refresh now
+  q                quit
+
+The board refreshes on its own when issues change.
+
+Press any key to return.`
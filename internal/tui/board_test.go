@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+type fakeSource struct {
+	issues []*types.Issue
+	edits  map[string][]Edit
+}
+
+func (f *fakeSource) ListIssues(ctx context.Context) ([]*types.Issue, error) {
+	return f.issues, nil
+}
+
+func (f *fakeSource) IssueDetails(ctx context.Context, id string) (*types.IssueDetails, error) {
+	for _, issue := range f.issues {
+		if issue.ID == id {
+			return &types.IssueDetails{Issue: *issue}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeSource) ApplyEdit(ctx context.Context, id string, edit Edit) error {
+	if f.edits == nil {
+		f.edits = make(map[string][]Edit)
+	}
+	f.edits[id] = append(f.edits[id], edit)
+	return nil
+}
+
+func (f *fakeSource) Changed(ctx context.Context, since time.Time) (bool, error) {
+	return false, nil
+}
+
+func newTestBoard(t *testing.T) (*Board, *fakeSource) {
+	t.Helper()
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	src := &fakeSource{issues: []*types.Issue{
+		{ID: "bd-1", Title: "low", Status: types.StatusOpen, Priority: 3, UpdatedAt: t0},
+		{ID: "bd-2", Title: "urgent", Status: types.StatusOpen, Priority: 0, UpdatedAt: t0},
+		{ID: "bd-3", Title: "doing", Status: types.StatusInProgress, Priority: 2, UpdatedAt: t0, Labels: []string{"ui", "p1"}},
+		{ID: "bd-4", Title: "gone", Status: types.StatusClosed, Priority: 2, UpdatedAt: t0},
+	}}
+	b := NewBoard(context.Background(), src, Options{Statuses: []types.Status{types.StatusOpen, types.StatusInProgress, types.StatusBlocked}})
+	b.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	issues, _ := src.ListIssues(context.Background())
+	b.Update(issuesLoadedMsg{issues: issues, at: t0})
+	return b, src
+}
+
+// press sends a key and runs any resulting command once, feeding its message
+// back into the board (commands that reload are skipped).
+func press(b *Board, key string) {
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "right":
+		msg = tea.KeyMsg{Type: tea.KeyRight}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	_, cmd := b.Update(msg)
+	if cmd == nil {
+		return
+	}
+	if result := cmd(); result != nil {
+		if _, ok := result.(editAppliedMsg); ok {
+			b.Update(result)
+			return
+		}
+		if _, ok := result.(detailsLoadedMsg); ok {
+			b.Update(result)
+		}
+	}
+}
+
+func TestBoardGroupsAndSorts(t *testing.T) {
+	b, _ := newTestBoard(t)
+	if len(b.columns) != 3 {
+		t.Fatalf("columns = %d, want 3", len(b.columns))
+	}
+	open := b.columns[0].issues
+	if len(open) != 2 || open[0].ID != "bd-2" || open[1].ID != "bd-1" {
+		t.Errorf("open column = %v, want bd-2 (P0) before bd-1 (P3)", open)
+	}
+	if len(b.columns[1].issues) != 1 || len(b.columns[2].issues) != 0 {
+		t.Errorf("in_progress/blocked = %d/%d issues, want 1/0", len(b.columns[1].issues), len(b.columns[2].issues))
+	}
+	view := b.View()
+	for _, want := range []string{"open (2)", "in_progress (1)", "blocked (0)", "bd-2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view is missing %q", want)
+		}
+	}
+	if strings.Contains(view, "bd-4") {
+		t.Error("closed issue is shown without a closed column")
+	}
+}
+
+func TestBoardMoveIssue(t *testing.T) {
+	b, src := newTestBoard(t)
+	press(b, ">")
+
+	edits := src.edits["bd-2"]
+	if len(edits) != 1 || edits[0].Status == nil || *edits[0].Status != types.StatusInProgress {
+		t.Fatalf("edits = %+v, want bd-2 moved to in_progress", edits)
+	}
+	if sel := b.selected(); sel == nil || sel.ID != "bd-2" || b.col != 1 {
+		t.Errorf("selection = %v in column %d, want bd-2 in column 1", sel, b.col)
+	}
+
+	// Moving past the last column is a no-op
+	press(b, ">")
+	press(b, ">")
+	if got := len(src.edits["bd-2"]); got != 2 {
+		t.Errorf("edits = %d, want 2 (no move past the last column)", got)
+	}
+}
+
+func TestBoardInlineEdits(t *testing.T) {
+	b, src := newTestBoard(t)
+
+	press(b, "1")
+	if edits := src.edits["bd-2"]; len(edits) != 1 || *edits[0].Priority != 1 {
+		t.Fatalf("edits = %+v, want priority 1", edits)
+	}
+
+	press(b, "right")
+	press(b, "a")
+	for _, r := range "alice" {
+		b.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	press(b, "enter")
+	if edits := src.edits["bd-3"]; len(edits) != 1 || edits[0].Assignee == nil || *edits[0].Assignee != "alice" {
+		t.Fatalf("edits = %+v, want assignee alice", edits)
+	}
+}
+
+func TestBuildEditLabels(t *testing.T) {
+	issue := &types.Issue{ID: "bd-3", Labels: []string{"ui", "p1"}}
+	edit, _, err := buildEdit(editLabels, issue, "ui, backend,backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edit.AddLabels) != 1 || edit.AddLabels[0] != "backend" || len(edit.RemoveLabels) != 1 || edit.RemoveLabels[0] != "p1" {
+		t.Errorf("edit = %+v, want +backend -p1", edit)
+	}
+
+	if edit, _, _ := buildEdit(editLabels, issue, "p1,ui"); edit != nil {
+		t.Errorf("edit = %+v, want none for the same labels", edit)
+	}
+	if _, _, err := buildEdit(editPriority, issue, "7"); err == nil {
+		t.Error("expected an error for priority 7")
+	}
+	if edit, _, _ := buildEdit(editPriority, issue, "P2"); edit == nil || *edit.Priority != 2 {
+		t.Errorf("edit = %+v, want priority 2 from P2", edit)
+	}
+}
+
+func TestBoardDetailPane(t *testing.T) {
+	b, _ := newTestBoard(t)
+	press(b, "enter")
+	if !b.showDetail || b.details == nil || b.details.ID != "bd-2" {
+		t.Fatalf("details = %+v, want bd-2 loaded", b.details)
+	}
+	press(b, "down")
+	if b.details == nil || b.details.ID != "bd-1" {
+		t.Errorf("details = %+v, want bd-1 after moving down", b.details)
+	}
+	if !strings.Contains(b.View(), "low") {
+		t.Error("detail pane does not show the selected issue")
+	}
+}
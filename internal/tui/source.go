@@ -0,0 +1,41 @@
+// Package tui implements the interactive kanban board behind bd tui.
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// Source is where the board reads issues from and writes edits to. The CLI
+// provides one backed by the daemon and one backed by direct storage.
+type Source interface {
+	// ListIssues returns every issue that may appear on the board.
+	ListIssues(ctx context.Context) ([]*types.Issue, error)
+	// IssueDetails returns an issue with its labels, dependencies and comments.
+	IssueDetails(ctx context.Context, id string) (*types.IssueDetails, error)
+	// ApplyEdit writes an edit made on the board.
+	ApplyEdit(ctx context.Context, id string, edit Edit) error
+	// Changed reports whether anything changed since the given time. Sources
+	// without a change feed may always report true.
+	Changed(ctx context.Context, since time.Time) (bool, error)
+}
+
+// Edit is a change made on the board. Nil fields are left alone.
+type Edit struct {
+	Status       *types.Status
+	Priority     *int
+	Assignee     *string
+	AddLabels    []string
+	RemoveLabels []string
+}
+
+// Options configures the board.
+type Options struct {
+	// Statuses are the board columns, left to right. Issues in other
+	// statuses are not shown.
+	Statuses []types.Status
+	// RefreshInterval is how often the source is polled for changes.
+	RefreshInterval time.Duration
+}
@@ -47,6 +47,9 @@ type Agent struct {
 	// CreatedAt is when the agent was spawned
 	CreatedAt time.Time
 
+	// RecoveredFrom is the operation the bookmark was restored from (Recover only)
+	RecoveredFrom string
+
 	// VCS is the version control system interface
 	vcs vcs.VCS
 }
@@ -419,40 +422,61 @@ func Recover(ctx context.Context, v vcs.VCS, opts RecoverOptions) (*Agent, error
 		return nil, fmt.Errorf("recovery target bookmark %s already exists", recoverToID)
 	}
 
-	// Get operation log
+	// Get operation log (newest first)
 	ops, err := v.GetOperationLog(100) // Check last 100 operations
 	if err != nil {
 		return nil, fmt.Errorf("failed to get operation log: %w", err)
 	}
 
-	// Find last operation involving this agent
-	var targetOpID string
-	for _, op := range ops {
-		// Check if operation mentions this agent
+	// Start from the requested operation, or the last operation involving
+	// this agent
+	start := -1
+	for i, op := range ops {
+		if opts.OperationID != "" {
+			if strings.HasPrefix(op.ID, opts.OperationID) {
+				start = i
+				break
+			}
+			continue
+		}
 		if strings.Contains(op.Description, agentID) ||
 			containsString(op.Args, agentID) {
-			targetOpID = op.ID
+			start = i
 			break
 		}
 	}
 
-	if targetOpID == "" && opts.OperationID == "" {
+	if start < 0 {
+		if opts.OperationID != "" {
+			return nil, fmt.Errorf("operation %s not found in the last %d operations", opts.OperationID, len(ops))
+		}
 		return nil, fmt.Errorf("no operations found for agent %s", agentID)
 	}
 
-	if opts.OperationID != "" {
-		targetOpID = opts.OperationID
-	}
+	// The matching operation may be the one that deleted or abandoned the
+	// bookmark, so walk back until the bookmark still points at a change
+	for _, op := range ops[start:] {
+		commitID, err := bookmarkAtOperation(ctx, v, agentID, op.ID)
+		if err != nil || commitID == "" {
+			continue
+		}
 
-	// Restore from that operation
-	// This is simplified - real implementation would need to:
-	// 1. Get the change ID at that operation
-	// 2. Create bookmark pointing to that change
-	//
-	// For now, we'll just try to create a bookmark from the last known state
-	// This requires manual implementation based on jj's operation log format
+		// jj makes a hidden commit visible again when a bookmark points at it
+		if err := v.CreateRef(recoverToID, commitID); err != nil {
+			return nil, fmt.Errorf("failed to create recovery bookmark: %w", err)
+		}
 
-	return nil, fmt.Errorf("recovery not yet fully implemented - use jj op log manually")
+		return &Agent{
+			ID:            recoverToID,
+			Bookmark:      recoverToID,
+			BasedOn:       agentID,
+			CreatedAt:     time.Now(),
+			RecoveredFrom: op.ID,
+			vcs:           v,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("bookmark %s not found at or before operation %s", agentID, ops[start].ID)
 }
 
 // DeleteAgent removes an agent bookmark.
@@ -519,6 +543,28 @@ func normalizeAgentID(id string) string {
 	return AgentBookmarkPrefix + id
 }
 
+// BookmarkName returns the bookmark name for an agent ID, adding the
+// "agent-" prefix if needed.
+func BookmarkName(id string) string {
+	return normalizeAgentID(id)
+}
+
+// bookmarkAtOperation returns the commit the bookmark pointed at as of the
+// given jj operation, or "" if it did not exist then.
+func bookmarkAtOperation(ctx context.Context, v vcs.VCS, bookmark, opID string) (string, error) {
+	revset := fmt.Sprintf("bookmarks(exact:%q)", bookmark)
+	output, err := v.Exec(ctx, "log", "--at-op", opID, "--ignore-working-copy",
+		"--no-graph", "-r", revset, "-T", `commit_id ++ "\n"`)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Fields(string(output))
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return lines[0], nil
+}
+
 // containsString checks if a string slice contains a value
 func containsString(slice []string, value string) bool {
 	for _, item := range slice {
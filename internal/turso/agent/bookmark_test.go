@@ -0,0 +1,273 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/vcs"
+	"github.com/steveyegge/beads/internal/vcs/jj"
+)
+
+// newJJRepo creates a jj repository with a main bookmark on an initial
+// commit and the working copy on top of it.
+func newJJRepo(t *testing.T) (vcs.VCS, string) {
+	t.Helper()
+	if !vcs.IsJJAvailable() {
+		t.Skip("jj not available")
+	}
+	t.Setenv("JJ_USER", "Test Agent")
+	t.Setenv("JJ_EMAIL", "agent@example.com")
+
+	dir := t.TempDir()
+	j, err := jj.Init(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to initialize jj repo: %v", err)
+	}
+	writeFile(t, dir, "README.md", "hello\n")
+	if _, err := j.Exec(context.Background(), "commit", "-m", "initial"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if err := j.CreateRef(MainBookmark, "@-"); err != nil {
+		t.Fatalf("Failed to create main: %v", err)
+	}
+	return j, dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+// commitOf returns the commit ID of a single revision.
+func commitOf(t *testing.T, v vcs.VCS, revset string) string {
+	t.Helper()
+	output, err := v.Exec(context.Background(), "log", "--no-graph", "-r", revset, "-T", "commit_id")
+	if err != nil {
+		t.Fatalf("Failed to resolve %s: %v", revset, err)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// bookmarkCommit returns the commit ID a bookmark points at.
+func bookmarkCommit(t *testing.T, v vcs.VCS, bookmark string) string {
+	t.Helper()
+	return commitOf(t, v, fmt.Sprintf("bookmarks(exact:%q)", bookmark))
+}
+
+// parentOf returns the commit ID of a bookmark's parent.
+func parentOf(t *testing.T, v vcs.VCS, bookmark string) string {
+	t.Helper()
+	return commitOf(t, v, fmt.Sprintf("bookmarks(exact:%q)-", bookmark))
+}
+
+// filesAt lists the files in a revision.
+func filesAt(t *testing.T, v vcs.VCS, revset string) []string {
+	t.Helper()
+	output, err := v.Exec(context.Background(), "file", "list", "-r", revset)
+	if err != nil {
+		t.Fatalf("Failed to list files at %s: %v", revset, err)
+	}
+	return strings.Fields(string(output))
+}
+
+func latestOperation(t *testing.T, v vcs.VCS) string {
+	t.Helper()
+	ops, err := v.GetOperationLog(1)
+	if err != nil || len(ops) == 0 {
+		t.Fatalf("Failed to get operation log: %v", err)
+	}
+	return ops[0].ID
+}
+
+func TestNormalizeAgentID(t *testing.T) {
+	tests := map[string]string{
+		"47":               "agent-47",
+		"gt-emma":          "agent-gt-emma",
+		"agent-47":         "agent-47",
+		"archive/agent-47": "archive/agent-47",
+	}
+	for id, want := range tests {
+		if got := BookmarkName(id); got != want {
+			t.Errorf("BookmarkName(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+// TestSpawnHandoffComplete walks an agent bookmark through its lifecycle.
+func TestSpawnHandoffComplete(t *testing.T) {
+	v, dir := newJJRepo(t)
+	ctx := context.Background()
+	initial := bookmarkCommit(t, v, MainBookmark)
+
+	spawned, err := Spawn(ctx, v, SpawnOptions{AgentID: "47", Description: "Work on bd-123"})
+	if err != nil {
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	if spawned.Bookmark != "agent-47" || spawned.BasedOn != MainBookmark {
+		t.Errorf("Spawn() = %+v, want agent-47 based on main", spawned)
+	}
+	if got := parentOf(t, v, "agent-47"); got != initial {
+		t.Errorf("agent-47 parent = %s, want main (%s)", got, initial)
+	}
+	if _, err := Spawn(ctx, v, SpawnOptions{AgentID: "47"}); err == nil {
+		t.Error("Spawn of an existing agent should fail")
+	}
+	if _, err := Spawn(ctx, v, SpawnOptions{AgentID: "48", BaseBranch: "missing"}); err == nil {
+		t.Error("Spawn from a missing base should fail")
+	}
+
+	// The working copy is the agent's change, so new files land on it
+	writeFile(t, dir, "work.txt", "first agent\n")
+
+	handed, err := Handoff(ctx, v, HandoffOptions{FromAgentID: "agent-47", ToAgentID: "48", Reason: "context limit", ArchiveOld: true})
+	if err != nil {
+		t.Fatalf("Handoff failed: %v", err)
+	}
+	if handed.Bookmark != "agent-48" || handed.BasedOn != "agent-47" {
+		t.Errorf("Handoff() = %+v, want agent-48 based on agent-47", handed)
+	}
+	if v.RefExists("agent-47") || !v.RefExists("archive/agent-47") {
+		t.Error("Handoff with ArchiveOld should replace agent-47 with archive/agent-47")
+	}
+	archived := bookmarkCommit(t, v, "archive/agent-47")
+	if got := parentOf(t, v, "agent-48"); got != archived {
+		t.Errorf("agent-48 parent = %s, want the archived agent-47 change %s", got, archived)
+	}
+	if !slices.Contains(filesAt(t, v, "agent-48"), "work.txt") {
+		t.Error("agent-48 should carry agent-47's work")
+	}
+
+	writeFile(t, dir, "handoff.txt", "second agent\n")
+
+	if err := Complete(ctx, v, CompleteOptions{AgentID: "48", DeleteBookmark: true}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if v.RefExists("agent-48") {
+		t.Error("Complete with DeleteBookmark should remove agent-48")
+	}
+	files := filesAt(t, v, MainBookmark)
+	if !slices.Contains(files, "work.txt") || !slices.Contains(files, "handoff.txt") {
+		t.Errorf("main files = %v, want both agents' work", files)
+	}
+
+	agents, err := List(ctx, v)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(agents) != 1 || agents[0].Bookmark != "archive/agent-47" || !agents[0].IsArchived {
+		t.Errorf("List() = %+v, want only archive/agent-47", agents)
+	}
+}
+
+// TestRecover restores an agent whose bookmark was deleted and whose change
+// was abandoned.
+func TestRecover(t *testing.T) {
+	v, dir := newJJRepo(t)
+	ctx := context.Background()
+
+	if _, err := Spawn(ctx, v, SpawnOptions{AgentID: "47", Description: "Work on bd-123"}); err != nil {
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	writeFile(t, dir, "work.txt", "unsaved agent work\n")
+	lost := bookmarkCommit(t, v, "agent-47")
+
+	// Simulate a crash that lost the agent's bookmark and change
+	if _, err := v.Exec(ctx, "new", MainBookmark); err != nil {
+		t.Fatalf("Failed to leave the agent's change: %v", err)
+	}
+	if err := DeleteAgent(ctx, v, "47"); err != nil {
+		t.Fatalf("DeleteAgent failed: %v", err)
+	}
+	if _, err := v.Exec(ctx, "abandon", lost); err != nil {
+		t.Fatalf("Failed to abandon %s: %v", lost, err)
+	}
+
+	recovered, err := Recover(ctx, v, RecoverOptions{AgentID: "47"})
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovered.Bookmark != "agent-47-recovered" || recovered.BasedOn != "agent-47" || recovered.RecoveredFrom == "" {
+		t.Errorf("Recover() = %+v, want agent-47-recovered with the source operation", recovered)
+	}
+	if got := bookmarkCommit(t, v, "agent-47-recovered"); got != lost {
+		t.Errorf("recovered bookmark at %s, want the lost change %s", got, lost)
+	}
+	if !slices.Contains(filesAt(t, v, "agent-47-recovered"), "work.txt") {
+		t.Error("recovered change should have the agent's work")
+	}
+
+	if _, err := Recover(ctx, v, RecoverOptions{AgentID: "47"}); err == nil {
+		t.Error("Recover onto an existing bookmark should fail")
+	}
+	if _, err := Recover(ctx, v, RecoverOptions{AgentID: "99", RecoverToID: "99-copy"}); err == nil {
+		t.Error("Recover of an agent with no operations should fail")
+	}
+	if _, err := Recover(ctx, v, RecoverOptions{AgentID: "47", RecoverToID: "47-again", OperationID: "ffffffffffff"}); err == nil {
+		t.Error("Recover from an unknown operation should fail")
+	}
+}
+
+// TestRecoverFromOperation recovers the bookmark as of an older operation.
+func TestRecoverFromOperation(t *testing.T) {
+	v, dir := newJJRepo(t)
+	ctx := context.Background()
+
+	if _, err := Spawn(ctx, v, SpawnOptions{AgentID: "47"}); err != nil {
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	writeFile(t, dir, "work.txt", "v1\n")
+	first := bookmarkCommit(t, v, "agent-47")
+	op := latestOperation(t, v)
+
+	writeFile(t, dir, "work.txt", "v2\n")
+	if second := bookmarkCommit(t, v, "agent-47"); second == first {
+		t.Fatal("agent-47 should move when the working copy changes")
+	}
+
+	recovered, err := Recover(ctx, v, RecoverOptions{AgentID: "47", RecoverToID: "47-v1", OperationID: op[:12]})
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if !strings.HasPrefix(recovered.RecoveredFrom, op[:12]) {
+		t.Errorf("RecoveredFrom = %s, want %s", recovered.RecoveredFrom, op)
+	}
+	if got := bookmarkCommit(t, v, "agent-47-v1"); got != first {
+		t.Errorf("agent-47-v1 at %s, want %s", got, first)
+	}
+}
+
+func TestBookmarkAtOperation(t *testing.T) {
+	v, _ := newJJRepo(t)
+	ctx := context.Background()
+	base := bookmarkCommit(t, v, MainBookmark)
+
+	if err := v.CreateRef("agent-1", MainBookmark); err != nil {
+		t.Fatalf("CreateRef failed: %v", err)
+	}
+	created := latestOperation(t, v)
+	if err := v.DeleteRef("agent-1"); err != nil {
+		t.Fatalf("DeleteRef failed: %v", err)
+	}
+	deleted := latestOperation(t, v)
+
+	if got, err := bookmarkAtOperation(ctx, v, "agent-1", created); err != nil || got != base {
+		t.Errorf("bookmarkAtOperation(created) = %q, %v; want %s", got, err, base)
+	}
+	if got, err := bookmarkAtOperation(ctx, v, "agent-1", deleted); err != nil || got != "" {
+		t.Errorf("bookmarkAtOperation(deleted) = %q, %v; want none", got, err)
+	}
+	// A prefix of another bookmark's name must not match
+	if got, err := bookmarkAtOperation(ctx, v, "agent", created); err != nil || got != "" {
+		t.Errorf("bookmarkAtOperation(agent) = %q, %v; want none", got, err)
+	}
+	if _, err := bookmarkAtOperation(ctx, v, "agent-1", "ffffffffffff"); err == nil {
+		t.Error("bookmarkAtOperation with an unknown operation should fail")
+	}
+}
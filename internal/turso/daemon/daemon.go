@@ -187,6 +187,14 @@ func (d *Daemon) PerformFullSync() error {
 		}
 	}
 
+	// Rewrite dep files left in an older format so later file events and
+	// deletions see the current from/to order
+	if n, err := schema.UpgradeDepFiles(d.depsDir); err != nil {
+		return fmt.Errorf("failed to upgrade deps: %w", err)
+	} else if n > 0 {
+		d.config.Logger.Printf("Upgraded %d dependency files to format %d", n, schema.DepFormat)
+	}
+
 	// Sync all dependencies
 	deps, err := schema.ListAllDeps(d.depsDir)
 	if err != nil {
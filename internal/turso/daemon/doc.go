@@ -41,6 +41,8 @@
 //   - Parses operation metadata (ID, description, timestamp)
 //   - Runs `jj op show` to determine affected files
 //   - Delivers operations in chronological order (oldest first)
+//   - Pages further back when more than 50 operations happened between
+//     polls, and calls Resync when the last seen operation is gone
 //   - Continues watching even after transient errors
 //
 // Performance characteristics:
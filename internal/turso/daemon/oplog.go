@@ -14,6 +14,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -66,6 +67,20 @@ type OpLogWatcherConfig struct {
 	// LastOpID is the operation ID to start watching from
 	// If empty, starts from the most recent operation
 	LastOpID string
+
+	// MatchFile selects which changed files end up in AffectedFiles.
+	// If nil, .json files under TasksDir and DepsDir are matched.
+	MatchFile func(path string) bool
+
+	// Logf receives warnings (default: printed to stdout)
+	Logf func(format string, args ...interface{})
+
+	// Resync is called instead of the callback when the last seen operation
+	// is no longer in the op log (garbage collected, or more than
+	// opLogMaxScan operations back), so the operations in between cannot be
+	// replayed. latest is the newest operation; the caller should do a full
+	// import. If nil, only the newest operation is delivered to the callback.
+	Resync func(latest OpLogEntry) error
 }
 
 // OpLogCallback is called when new operations are detected.
@@ -150,9 +165,22 @@ func ParseOpLog(output []byte) ([]OpLogEntry, error) {
 //
 // Returns a list of file paths relative to the repository root.
 func GetAffectedFiles(ctx context.Context, repoPath string, entry OpLogEntry, tasksDir, depsDir string) ([]string, error) {
+	files, err := GetChangedFiles(ctx, repoPath, entry)
+	if err != nil {
+		return nil, err
+	}
+	return filterFiles(files, taskOrDepFile(tasksDir, depsDir)), nil
+}
+
+// GetChangedFiles returns every file modified by an operation, relative to
+// the repository root.
+//
+// The working copy is not snapshotted, so watching the op log does not
+// itself create new operations.
+func GetChangedFiles(ctx context.Context, repoPath string, entry OpLogEntry) ([]string, error) {
 	// Run: jj op show {opID} --op-diff --patch
 	// This shows what files changed in this operation
-	cmd := exec.CommandContext(ctx, "jj", "op", "show", entry.ID, "--op-diff", "--patch")
+	cmd := exec.CommandContext(ctx, "jj", "op", "show", entry.ID, "--op-diff", "--patch", "--ignore-working-copy")
 	cmd.Dir = repoPath
 
 	output, err := cmd.CombinedOutput()
@@ -160,7 +188,7 @@ func GetAffectedFiles(ctx context.Context, repoPath string, entry OpLogEntry, ta
 		return nil, fmt.Errorf("failed to get op diff: %w (output: %s)", err, output)
 	}
 
-	return parseAffectedFiles(output, tasksDir, depsDir), nil
+	return parseChangedFiles(output), nil
 }
 
 // parseAffectedFiles extracts task/dep file paths from jj op show output.
@@ -170,6 +198,37 @@ func GetAffectedFiles(ctx context.Context, repoPath string, entry OpLogEntry, ta
 //   Modified regular file deps/bd-abc--blocks--bd-xyz.json:
 //   Removed regular file tasks/bd-456.json:
 func parseAffectedFiles(diffOutput []byte, tasksDir, depsDir string) []string {
+	return filterFiles(parseChangedFiles(diffOutput), taskOrDepFile(tasksDir, depsDir))
+}
+
+// taskOrDepFile matches .json files directly under the tasks or deps directory.
+func taskOrDepFile(tasksDir, depsDir string) func(string) bool {
+	return func(filePath string) bool {
+		// Check if this is a task or dep file
+		if !strings.HasPrefix(filePath, tasksDir+"/") &&
+			!strings.HasPrefix(filePath, depsDir+"/") {
+			return false
+		}
+
+		// Check if it's a JSON file
+		return strings.HasSuffix(filePath, ".json")
+	}
+}
+
+// filterFiles returns the files accepted by match, preserving order.
+func filterFiles(files []string, match func(string) bool) []string {
+	var matched []string
+	for _, f := range files {
+		if match(f) {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+// parseChangedFiles extracts every file path from jj op show output,
+// deduplicated and in order of appearance.
+func parseChangedFiles(diffOutput []byte) []string {
 	var files []string
 	seen := make(map[string]bool)
 
@@ -191,17 +250,6 @@ func parseAffectedFiles(diffOutput []byte, tasksDir, depsDir string) []string {
 
 		filePath := matches[1]
 
-		// Check if this is a task or dep file
-		if !strings.HasPrefix(filePath, tasksDir+"/") &&
-			!strings.HasPrefix(filePath, depsDir+"/") {
-			continue
-		}
-
-		// Check if it's a JSON file
-		if !strings.HasSuffix(filePath, ".json") {
-			continue
-		}
-
 		// Deduplicate
 		if seen[filePath] {
 			continue
@@ -253,6 +301,14 @@ func WatchOpLog(ctx context.Context, config OpLogWatcherConfig, callback OpLogCa
 	if config.DepsDir == "" {
 		config.DepsDir = "deps"
 	}
+	if config.MatchFile == nil {
+		config.MatchFile = taskOrDepFile(config.TasksDir, config.DepsDir)
+	}
+	if config.Logf == nil {
+		config.Logf = func(format string, args ...interface{}) {
+			fmt.Printf(format+"\n", args...)
+		}
+	}
 
 	// Get absolute repo path
 	absRepoPath, err := filepath.Abs(config.RepoPath)
@@ -270,27 +326,32 @@ func WatchOpLog(ctx context.Context, config OpLogWatcherConfig, callback OpLogCa
 			return ctx.Err()
 
 		case <-ticker.C:
-			// Get recent operations
-			cmd := exec.CommandContext(ctx, "jj", "op", "log", "--no-graph",
-				"-T", `id ++ "\n" ++ description ++ "\n---\n"`,
-				"-n", "50") // Check last 50 operations
-			cmd.Dir = absRepoPath
-
-			output, err := cmd.CombinedOutput()
+			entries, newOps, found, err := pollOpLog(lastSeenID, func(limit int) ([]OpLogEntry, error) {
+				return readOpLog(ctx, absRepoPath, limit)
+			})
 			if err != nil {
 				// Log error but continue watching
-				fmt.Printf("Warning: failed to get op log: %v\n", err)
+				config.Logf("Warning: %v", err)
 				continue
 			}
-
-			entries, err := ParseOpLog(output)
-			if err != nil {
-				fmt.Printf("Warning: failed to parse op log: %v\n", err)
+			if len(entries) == 0 {
 				continue
 			}
 
-			// Find new operations
-			newOps := findNewOperations(entries, lastSeenID)
+			latest := entries[0]
+
+			if !found {
+				config.Logf("Warning: last seen operation %s not found in the op log; resyncing from %s",
+					ShortOpID(lastSeenID), ShortOpID(latest.ID))
+				if config.Resync != nil {
+					lastSeenID = latest.ID
+					if err := config.Resync(latest); err != nil {
+						config.Logf("Warning: resync error: %v", err)
+					}
+					continue
+				}
+				newOps = entries[:1]
+			}
 			if len(newOps) == 0 {
 				continue
 			}
@@ -300,38 +361,85 @@ func WatchOpLog(ctx context.Context, config OpLogWatcherConfig, callback OpLogCa
 
 			// Get affected files for each operation
 			for i := range newOps {
-				files, err := GetAffectedFiles(ctx, absRepoPath, newOps[i], config.TasksDir, config.DepsDir)
+				files, err := GetChangedFiles(ctx, absRepoPath, newOps[i])
 				if err != nil {
 					// Log error but continue with other operations
-					fmt.Printf("Warning: failed to get affected files for %s: %v\n", newOps[i].ID[:12], err)
+					config.Logf("Warning: failed to get affected files for %s: %v", ShortOpID(newOps[i].ID), err)
 					continue
 				}
-				newOps[i].AffectedFiles = files
+				newOps[i].AffectedFiles = filterFiles(files, config.MatchFile)
 			}
 
-			// Update last seen ID (most recent in the original list)
-			lastSeenID = entries[0].ID
+			// Update last seen ID. newOps shares entries' backing array, so
+			// entries[0] is no longer the newest after the reverse above
+			lastSeenID = latest.ID
 
 			// Call callback with new operations
 			if err := callback(newOps); err != nil {
-				fmt.Printf("Warning: callback error: %v\n", err)
+				config.Logf("Warning: callback error: %v", err)
 				// Continue watching despite callback error
 			}
 		}
 	}
 }
 
+// opLogPageSize is how many operations a poll reads first. When the last
+// seen operation is not among them, the read is repeated with twice the
+// limit until it is found, the start of the log is reached, or opLogMaxScan
+// operations have been read.
+const (
+	opLogPageSize = 50
+	opLogMaxScan  = 6400
+)
+
+// pollOpLog reads operations with read(limit), newest first, paging further
+// back until lastSeenID is found. It returns the operations read and those
+// newer than lastSeenID. found is false when lastSeenID is not within reach.
+func pollOpLog(lastSeenID string, read func(limit int) ([]OpLogEntry, error)) (entries, newOps []OpLogEntry, found bool, err error) {
+	for limit := opLogPageSize; ; limit *= 2 {
+		entries, err = read(limit)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		newOps, found = findNewOperations(entries, lastSeenID)
+		if found || len(entries) < limit || limit >= opLogMaxScan {
+			return entries, newOps, found, nil
+		}
+	}
+}
+
+// readOpLog returns the newest limit operations, newest first.
+func readOpLog(ctx context.Context, repoPath string, limit int) ([]OpLogEntry, error) {
+	cmd := exec.CommandContext(ctx, "jj", "op", "log", "--no-graph", "--ignore-working-copy",
+		"-T", `id ++ "\n" ++ description ++ "\n---\n"`,
+		"-n", strconv.Itoa(limit))
+	cmd.Dir = repoPath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get op log: %w (output: %s)", err, output)
+	}
+
+	entries, err := ParseOpLog(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse op log: %w", err)
+	}
+	return entries, nil
+}
+
 // findNewOperations returns operations that are newer than lastSeenID.
 //
 // The input entries are assumed to be in reverse chronological order (newest first).
-// Returns new operations in reverse chronological order.
-func findNewOperations(entries []OpLogEntry, lastSeenID string) []OpLogEntry {
+// Returns new operations in reverse chronological order, and whether
+// lastSeenID was found. With no lastSeenID only the most recent operation
+// is returned.
+func findNewOperations(entries []OpLogEntry, lastSeenID string) ([]OpLogEntry, bool) {
 	if lastSeenID == "" {
 		// First run - return only the most recent operation
 		if len(entries) > 0 {
-			return entries[:1]
+			return entries[:1], true
 		}
-		return nil
+		return nil, true
 	}
 
 	// Find where the last seen ID appears
@@ -339,18 +447,23 @@ func findNewOperations(entries []OpLogEntry, lastSeenID string) []OpLogEntry {
 		if entry.ID == lastSeenID {
 			// Return everything before this index (newer operations)
 			if i == 0 {
-				return nil // No new operations
+				return nil, true // No new operations
 			}
-			return entries[:i]
+			return entries[:i], true
 		}
 	}
 
-	// lastSeenID not found - it might have been garbage collected
-	// Return only the most recent operation to avoid processing too much history
-	if len(entries) > 0 {
-		return entries[:1]
+	// lastSeenID not found - it might have been garbage collected or be
+	// further back than entries reach
+	return nil, false
+}
+
+// ShortOpID abbreviates a jj operation ID the way jj displays it.
+func ShortOpID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
 	}
-	return nil
+	return id
 }
 
 // reverseSlice reverses a slice of OpLogEntry in place.
@@ -365,19 +478,9 @@ func reverseSlice(entries []OpLogEntry) {
 //
 // This is useful for initializing the watcher's LastOpID.
 func GetLatestOperationID(ctx context.Context, repoPath string) (string, error) {
-	cmd := exec.CommandContext(ctx, "jj", "op", "log", "--no-graph",
-		"-T", `id ++ "\n" ++ description ++ "\n---\n"`,
-		"-n", "1")
-	cmd.Dir = repoPath
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("failed to get latest operation: %w (output: %s)", err, output)
-	}
-
-	entries, err := ParseOpLog(output)
+	entries, err := readOpLog(ctx, repoPath, 1)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse op log: %w", err)
+		return "", err
 	}
 
 	if len(entries) == 0 {
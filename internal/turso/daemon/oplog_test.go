@@ -243,6 +243,26 @@ Added regular file mydeps/bd-abc--blocks--bd-xyz.json:
 	}
 }
 
+func TestParseChangedFiles(t *testing.T) {
+	input := `Changed commits:
+Modified regular file .beads/issues.jsonl:
+       1:   1: {"id":"bd-1"}
+Added regular file src/main.go:
+        1: package main
+Modified regular file .beads/issues.jsonl:
+`
+	got := parseChangedFiles([]byte(input))
+	want := []string{".beads/issues.jsonl", "src/main.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseChangedFiles() = %v, want %v", got, want)
+	}
+
+	beadsOnly := filterFiles(got, func(path string) bool { return path == ".beads/issues.jsonl" })
+	if !reflect.DeepEqual(beadsOnly, []string{".beads/issues.jsonl"}) {
+		t.Errorf("filterFiles() = %v, want [.beads/issues.jsonl]", beadsOnly)
+	}
+}
+
 func TestFindNewOperations(t *testing.T) {
 	entries := []OpLogEntry{
 		{ID: "op5", Description: "newest"},
@@ -257,6 +277,7 @@ func TestFindNewOperations(t *testing.T) {
 		entries    []OpLogEntry
 		lastSeenID string
 		want       []OpLogEntry
+		notFound   bool
 	}{
 		{
 			name:       "first run - no last seen",
@@ -280,27 +301,95 @@ func TestFindNewOperations(t *testing.T) {
 			want:       nil,
 		},
 		{
-			name:       "all operations are new",
+			name:       "last seen not found",
 			entries:    entries,
 			lastSeenID: "op-unknown",
-			want:       []OpLogEntry{{ID: "op5", Description: "newest"}}, // Returns only most recent
+			want:       nil,
+			notFound:   true,
 		},
 		{
 			name:       "empty entries",
 			entries:    []OpLogEntry{},
 			lastSeenID: "op1",
 			want:       nil,
+			notFound:   true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := findNewOperations(tt.entries, tt.lastSeenID)
+			got, found := findNewOperations(tt.entries, tt.lastSeenID)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("findNewOperations() = %v, want %v", got, tt.want)
 			}
+			if found == tt.notFound {
+				t.Errorf("findNewOperations() found = %v, want %v", found, !tt.notFound)
+			}
+		})
+	}
+}
+
+func TestPollOpLog(t *testing.T) {
+	// history is the op log, newest first
+	history := make([]OpLogEntry, 300)
+	for i := range history {
+		history[i] = OpLogEntry{ID: fmt.Sprintf("op%d", len(history)-i)}
+	}
+	var limits []int
+	read := func(limit int) ([]OpLogEntry, error) {
+		limits = append(limits, limit)
+		if limit > len(history) {
+			limit = len(history)
+		}
+		return history[:limit], nil
+	}
+
+	tests := []struct {
+		name       string
+		lastSeenID string
+		wantNew    int
+		wantFound  bool
+		wantLimits []int
+	}{
+		{"within the first page", "op290", 10, true, []int{50}},
+		{"pages further back", "op120", 180, true, []int{50, 100, 200}},
+		{"start of the log reached", "op-gone", 0, false, []int{50, 100, 200, 400}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits = nil
+			entries, newOps, found, err := pollOpLog(tt.lastSeenID, read)
+			if err != nil {
+				t.Fatalf("pollOpLog() error = %v", err)
+			}
+			if found != tt.wantFound || len(newOps) != tt.wantNew {
+				t.Errorf("pollOpLog() = %d new, found %v; want %d new, found %v", len(newOps), found, tt.wantNew, tt.wantFound)
+			}
+			if entries[0].ID != "op300" {
+				t.Errorf("newest entry = %s, want op300", entries[0].ID)
+			}
+			if !reflect.DeepEqual(limits, tt.wantLimits) {
+				t.Errorf("read limits = %v, want %v", limits, tt.wantLimits)
+			}
 		})
 	}
+
+	// Paging stops at opLogMaxScan even when there is more history
+	long := make([]OpLogEntry, opLogMaxScan*2)
+	for i := range long {
+		long[i] = OpLogEntry{ID: fmt.Sprintf("op%d", len(long)-i)}
+	}
+	maxRead := 0
+	_, _, found, err := pollOpLog("op1", func(limit int) ([]OpLogEntry, error) {
+		maxRead = limit
+		return long[:limit], nil
+	})
+	if err != nil || found {
+		t.Errorf("pollOpLog() past max scan: found = %v, err = %v; want not found", found, err)
+	}
+	if maxRead != opLogMaxScan {
+		t.Errorf("largest read = %d, want %d", maxRead, opLogMaxScan)
+	}
 }
 
 func TestReverseSlice(t *testing.T) {
@@ -10,7 +10,8 @@
 // Architecture:
 //   - Database file: .beads/turso.db
 //   - WAL mode: Concurrent readers during writes
-//   - Schema: tasks, deps, blocked_cache tables
+//   - Schema: tasks, deps, blocked_cache, config, metadata tables, plus the
+//     events, export_hashes and dirty_issues tables the storage adapter keeps
 //   - Indexes: Optimized for ready work queries (status, priority, defer_until)
 //
 // Workflow:
@@ -31,6 +32,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/beads/internal/turso/schema"
+	"github.com/steveyegge/beads/internal/types"
 	_ "github.com/ncruces/go-sqlite3/driver"
 	_ "github.com/ncruces/go-sqlite3/embed"
 )
@@ -184,6 +186,40 @@ func (db *DB) InitSchemaContext(ctx context.Context) error {
 		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
 	);
 
+	-- Key-value tables used by the storage.Storage adapter
+	CREATE TABLE IF NOT EXISTS config (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS metadata (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	-- Audit trail and export tracking, which have no place in the task files
+	CREATE TABLE IF NOT EXISTS events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		issue_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		old_value TEXT,
+		new_value TEXT,
+		comment TEXT,
+		created_at TEXT NOT NULL,
+		command_id TEXT NOT NULL DEFAULT '',
+		source_op TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS export_hashes (
+		issue_id TEXT PRIMARY KEY,
+		content_hash TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS dirty_issues (
+		issue_id TEXT PRIMARY KEY
+	);
+
 	-- Indexes for common queries
 	CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
 	CREATE INDEX IF NOT EXISTS idx_tasks_priority ON tasks(priority);
@@ -201,6 +237,8 @@ func (db *DB) InitSchemaContext(ctx context.Context) error {
 	CREATE INDEX IF NOT EXISTS idx_deps_type ON deps(type);
 	CREATE INDEX IF NOT EXISTS idx_deps_blocks
 	    ON deps(type, from_id) WHERE type = 'blocks';
+
+	CREATE INDEX IF NOT EXISTS idx_events_issue ON events(issue_id);
 	`
 
 	if _, err := db.conn.ExecContext(ctx, schema); err != nil {
@@ -836,3 +874,239 @@ func (db *DB) GetDepsForTaskContext(ctx context.Context, taskID string) ([]*sche
 
 	return deps, nil
 }
+
+// SetConfig stores a configuration value.
+func (db *DB) SetConfig(ctx context.Context, key, value string) error {
+	return db.setKV(ctx, "config", key, value)
+}
+
+// GetConfig returns a configuration value, or "" if the key is not set.
+func (db *DB) GetConfig(ctx context.Context, key string) (string, error) {
+	return db.getKV(ctx, "config", key)
+}
+
+// DeleteConfig removes a configuration value.
+// Returns nil if the key doesn't exist (idempotent).
+func (db *DB) DeleteConfig(ctx context.Context, key string) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM config WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("failed to delete config %s: %w", key, err)
+	}
+	return nil
+}
+
+// GetAllConfig returns every configuration value.
+func (db *DB) GetAllConfig(ctx context.Context) (map[string]string, error) {
+	return db.allKV(ctx, "config")
+}
+
+// SetMetadata stores an internal metadata value (import hashes, sync state).
+func (db *DB) SetMetadata(ctx context.Context, key, value string) error {
+	return db.setKV(ctx, "metadata", key, value)
+}
+
+// GetMetadata returns a metadata value, or "" if the key is not set.
+func (db *DB) GetMetadata(ctx context.Context, key string) (string, error) {
+	return db.getKV(ctx, "metadata", key)
+}
+
+// GetAllMetadata returns every metadata value.
+func (db *DB) GetAllMetadata(ctx context.Context) (map[string]string, error) {
+	return db.allKV(ctx, "metadata")
+}
+
+// AddEvent appends an event to an issue's audit trail.
+func (db *DB) AddEvent(ctx context.Context, event *types.Event) error {
+	query := `
+	INSERT INTO events (
+		issue_id, event_type, actor, old_value, new_value, comment,
+		created_at, command_id, source_op
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn.ExecContext(ctx, query,
+		event.IssueID, string(event.EventType), event.Actor,
+		event.OldValue, event.NewValue, event.Comment,
+		event.CreatedAt.UTC().Format(time.RFC3339Nano), event.CommandID, event.SourceOp)
+	if err != nil {
+		return fmt.Errorf("failed to add event for %s: %w", event.IssueID, err)
+	}
+	return nil
+}
+
+// DeleteEvents removes an issue's audit trail.
+func (db *DB) DeleteEvents(ctx context.Context, issueID string) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM events WHERE issue_id = ?`, issueID); err != nil {
+		return fmt.Errorf("failed to delete events for %s: %w", issueID, err)
+	}
+	return nil
+}
+
+// GetAllEvents returns every event, oldest first.
+func (db *DB) GetAllEvents(ctx context.Context) ([]*types.Event, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+	SELECT id, issue_id, event_type, actor, old_value, new_value, comment,
+		created_at, command_id, source_op
+	FROM events
+	ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*types.Event
+	for rows.Next() {
+		var event types.Event
+		var eventType, createdAt string
+		var oldValue, newValue, comment sql.NullString
+		if err := rows.Scan(&event.ID, &event.IssueID, &eventType, &event.Actor,
+			&oldValue, &newValue, &comment, &createdAt, &event.CommandID, &event.SourceOp); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.EventType = types.EventType(eventType)
+		if oldValue.Valid {
+			event.OldValue = &oldValue.String
+		}
+		if newValue.Valid {
+			event.NewValue = &newValue.String
+		}
+		if comment.Valid {
+			event.Comment = &comment.String
+		}
+		if event.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse event time %q: %w", createdAt, err)
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events: %w", err)
+	}
+	return events, nil
+}
+
+// SetExportHash records the content hash of an issue at its last export.
+func (db *DB) SetExportHash(ctx context.Context, issueID, hash string) error {
+	query := `INSERT INTO export_hashes (issue_id, content_hash) VALUES (?, ?)
+	ON CONFLICT(issue_id) DO UPDATE SET content_hash = excluded.content_hash`
+	if _, err := db.conn.ExecContext(ctx, query, issueID, hash); err != nil {
+		return fmt.Errorf("failed to set export hash for %s: %w", issueID, err)
+	}
+	return nil
+}
+
+// DeleteExportHash forgets an issue's export hash.
+func (db *DB) DeleteExportHash(ctx context.Context, issueID string) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM export_hashes WHERE issue_id = ?`, issueID); err != nil {
+		return fmt.Errorf("failed to delete export hash for %s: %w", issueID, err)
+	}
+	return nil
+}
+
+// ClearExportHashes forgets every export hash.
+func (db *DB) ClearExportHashes(ctx context.Context) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM export_hashes`); err != nil {
+		return fmt.Errorf("failed to clear export hashes: %w", err)
+	}
+	return nil
+}
+
+// GetAllExportHashes returns every export hash by issue ID.
+func (db *DB) GetAllExportHashes(ctx context.Context) (map[string]string, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT issue_id, content_hash FROM export_hashes`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query export hashes: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var issueID, hash string
+		if err := rows.Scan(&issueID, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan export hash: %w", err)
+		}
+		hashes[issueID] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating export hashes: %w", err)
+	}
+	return hashes, nil
+}
+
+// SetDirty marks or clears an issue as changed since the last export.
+func (db *DB) SetDirty(ctx context.Context, issueID string, dirty bool) error {
+	query := `DELETE FROM dirty_issues WHERE issue_id = ?`
+	if dirty {
+		query = `INSERT OR IGNORE INTO dirty_issues (issue_id) VALUES (?)`
+	}
+	if _, err := db.conn.ExecContext(ctx, query, issueID); err != nil {
+		return fmt.Errorf("failed to update dirty flag for %s: %w", issueID, err)
+	}
+	return nil
+}
+
+// GetDirtyIssues returns the IDs of issues changed since the last export.
+func (db *DB) GetDirtyIssues(ctx context.Context) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT issue_id FROM dirty_issues ORDER BY issue_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dirty issues: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan dirty issue: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dirty issues: %w", err)
+	}
+	return ids, nil
+}
+
+// setKV upserts a row in one of the key-value tables.
+// table is always a constant from this package, never user input.
+func (db *DB) setKV(ctx context.Context, table, key, value string) error {
+	query := `INSERT INTO ` + table + ` (key, value) VALUES (?, ?)
+	ON CONFLICT(key) DO UPDATE SET value = excluded.value`
+	if _, err := db.conn.ExecContext(ctx, query, key, value); err != nil {
+		return fmt.Errorf("failed to set %s %s: %w", table, key, err)
+	}
+	return nil
+}
+
+// getKV reads a row from one of the key-value tables.
+func (db *DB) getKV(ctx context.Context, table, key string) (string, error) {
+	var value string
+	err := db.conn.QueryRowContext(ctx, `SELECT value FROM `+table+` WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s %s: %w", table, key, err)
+	}
+	return value, nil
+}
+
+// allKV reads every row from one of the key-value tables.
+func (db *DB) allKV(ctx context.Context, table string) (map[string]string, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT key, value FROM `+table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", table, err)
+		}
+		values[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s: %w", table, err)
+	}
+	return values, nil
+}
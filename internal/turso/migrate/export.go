@@ -0,0 +1,149 @@
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/steveyegge/beads/internal/turso/schema"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// ExportOptions contains configuration for exporting task files back to JSONL
+type ExportOptions struct {
+	FromFiles string // Directory containing tasks/ and deps/
+	ToJSONL   string // Output JSONL file path
+	DryRun    bool   // Preview without writing
+}
+
+// ExportResult contains statistics about the export
+type ExportResult struct {
+	IssuesExported int
+	DepsExported   int
+	OrphanDeps     []string // Dep files referencing a task that does not exist
+}
+
+// FromTaskFiles reads tasks/*.json and deps/*.json under dir and reassembles
+// them into issues with their dependencies attached. Issues are sorted by ID
+// to match the ordering of `bd export`.
+func FromTaskFiles(dir string) ([]*types.Issue, []string, error) {
+	tasks, err := schema.ReadAllTaskFiles(filepath.Join(dir, "tasks"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read task files: %w", err)
+	}
+
+	deps, err := schema.ListAllDeps(filepath.Join(dir, "deps"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read dep files: %w", err)
+	}
+
+	issues := make([]*types.Issue, 0, len(tasks))
+	byID := make(map[string]*types.Issue, len(tasks))
+	for _, task := range tasks {
+		issue := task.ToIssue()
+		if len(issue.Labels) == 0 {
+			issue.Labels = nil
+		}
+		issues = append(issues, issue)
+		byID[issue.ID] = issue
+	}
+
+	var orphans []string
+	for _, dep := range deps {
+		d := dep.ToTypeDependency()
+		issue, ok := byID[d.IssueID]
+		if !ok {
+			orphans = append(orphans, dep.ToFileName())
+			continue
+		}
+		issue.Dependencies = append(issue.Dependencies, d)
+	}
+
+	slices.SortFunc(issues, func(a, b *types.Issue) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	for _, issue := range issues {
+		slices.SortFunc(issue.Dependencies, func(a, b *types.Dependency) int {
+			if c := cmp.Compare(a.DependsOnID, b.DependsOnID); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Type, b.Type)
+		})
+	}
+
+	return issues, orphans, nil
+}
+
+// WriteJSONL writes issues to path as JSON Lines, atomically via a temp file
+func WriteJSONL(issues []*types.Issue, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp.*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	encoder := json.NewEncoder(tmp)
+	for _, issue := range issues {
+		if err := encoder.Encode(issue); err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("failed to encode issue %s: %w", issue.ID, err)
+		}
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+
+	return nil
+}
+
+// Export performs the file-based format to JSONL export.
+// It is the inverse of Migrate: Migrate followed by Export yields issues
+// with identical content hashes (tombstones excepted, which Migrate skips).
+func Export(ctx context.Context, opts ExportOptions) (*ExportResult, error) {
+	if _, err := os.Stat(filepath.Join(opts.FromFiles, "tasks")); err != nil {
+		return nil, fmt.Errorf("tasks directory does not exist: %w", err)
+	}
+
+	issues, orphans, err := FromTaskFiles(opts.FromFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExportResult{
+		IssuesExported: len(issues),
+		OrphanDeps:     orphans,
+	}
+	for _, issue := range issues {
+		result.DepsExported += len(issue.Dependencies)
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := WriteJSONL(issues, opts.ToJSONL); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
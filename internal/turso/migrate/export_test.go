@@ -0,0 +1,179 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestExport_RoundTripPreservesContentHashes(t *testing.T) {
+	tmpDir := t.TempDir()
+	jsonlPath := filepath.Join(tmpDir, "issues.jsonl")
+	filesDir := filepath.Join(tmpDir, "files")
+	outPath := filepath.Join(tmpDir, "out", "issues.jsonl")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	ref := "gh-42"
+	issues := []*types.Issue{
+		{
+			ID:                 "bd-1",
+			Title:              "Epic",
+			Description:        "The epic",
+			Design:             "Design doc",
+			AcceptanceCriteria: "It works",
+			Notes:              "Some notes",
+			Status:             types.StatusOpen,
+			Priority:           1,
+			IssueType:          types.TypeEpic,
+			Labels:             []string{"backend"},
+			ExternalRef:        &ref,
+			MolType:            types.MolTypeSwarm,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+			Comments: []*types.Comment{
+				{ID: 1, IssueID: "bd-1", Author: "alice", Text: "first", CreatedAt: now},
+			},
+		},
+		{
+			ID:        "bd-2",
+			Title:     "Gate",
+			Status:    types.StatusOpen,
+			Priority:  2,
+			IssueType: types.TypeGate,
+			AwaitType: "gh:run",
+			AwaitID:   "123",
+			Timeout:   time.Hour,
+			Waiters:   []string{"mayor"},
+			CreatedAt: now,
+			UpdatedAt: now,
+			Dependencies: []*types.Dependency{
+				{IssueID: "bd-2", DependsOnID: "bd-1", Type: types.DepParentChild, CreatedAt: now},
+				{IssueID: "bd-2", DependsOnID: "bd-3", Type: types.DepBlocks, CreatedAt: now, CreatedBy: "bob"},
+			},
+		},
+		{
+			ID:        "bd-3",
+			Title:     "Blocker",
+			Status:    types.StatusClosed,
+			Priority:  0,
+			IssueType: types.TypeBug,
+			CreatedAt: now,
+			UpdatedAt: now,
+			ClosedAt:  &now,
+		},
+	}
+
+	file, err := os.Create(jsonlPath)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	encoder := json.NewEncoder(file)
+	for _, issue := range issues {
+		if err := encoder.Encode(issue); err != nil {
+			t.Fatalf("failed to encode %s: %v", issue.ID, err)
+		}
+	}
+	file.Close()
+
+	ctx := context.Background()
+	if _, err := Migrate(ctx, MigrateOptions{FromJSONL: jsonlPath, ToFiles: filesDir}); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	result, err := Export(ctx, ExportOptions{FromFiles: filesDir, ToJSONL: outPath})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if result.IssuesExported != 3 {
+		t.Errorf("expected 3 issues exported, got %d", result.IssuesExported)
+	}
+	if result.DepsExported != 2 {
+		t.Errorf("expected 2 deps exported, got %d", result.DepsExported)
+	}
+
+	exported, err := FromJSONL(outPath)
+	if err != nil {
+		t.Fatalf("FromJSONL on exported file failed: %v", err)
+	}
+	if len(exported) != len(issues) {
+		t.Fatalf("expected %d exported issues, got %d", len(issues), len(exported))
+	}
+
+	for i, want := range issues {
+		got := exported[i]
+		if got.ID != want.ID {
+			t.Fatalf("expected issue %s at position %d, got %s", want.ID, i, got.ID)
+		}
+		if got.ComputeContentHash() != want.ComputeContentHash() {
+			t.Errorf("%s: content hash changed across JSONL -> files -> JSONL", want.ID)
+		}
+		if len(got.Dependencies) != len(want.Dependencies) {
+			t.Errorf("%s: expected %d deps, got %d", want.ID, len(want.Dependencies), len(got.Dependencies))
+			continue
+		}
+		for j, dep := range want.Dependencies {
+			if got.Dependencies[j].DependsOnID != dep.DependsOnID || got.Dependencies[j].Type != dep.Type {
+				t.Errorf("%s: dep %d = %+v, want %+v", want.ID, j, got.Dependencies[j], dep)
+			}
+		}
+	}
+
+	if len(exported[0].Comments) != 1 || exported[0].Comments[0].Text != "first" {
+		t.Errorf("expected comments to survive round trip, got %+v", exported[0].Comments)
+	}
+}
+
+func TestExport_DryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+
+	task := IssueToTaskFile(&types.Issue{
+		ID: "bd-1", Title: "Task", Status: types.StatusOpen, IssueType: types.TypeTask,
+		CreatedAt: now, UpdatedAt: now,
+	})
+	if err := WriteTaskFile(task, tmpDir); err != nil {
+		t.Fatalf("WriteTaskFile failed: %v", err)
+	}
+
+	outPath := filepath.Join(tmpDir, "issues.jsonl")
+	result, err := Export(context.Background(), ExportOptions{FromFiles: tmpDir, ToJSONL: outPath, DryRun: true})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if result.IssuesExported != 1 {
+		t.Errorf("expected 1 issue, got %d", result.IssuesExported)
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Error("dry run should not write the output file")
+	}
+}
+
+func TestExport_OrphanDeps(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+
+	task := IssueToTaskFile(&types.Issue{
+		ID: "bd-1", Title: "Task", Status: types.StatusOpen, IssueType: types.TypeTask,
+		CreatedAt: now, UpdatedAt: now,
+	})
+	if err := WriteTaskFile(task, tmpDir); err != nil {
+		t.Fatalf("WriteTaskFile failed: %v", err)
+	}
+	dep := &DepFile{From: "bd-1", To: "bd-missing", Type: "blocks", CreatedAt: now}
+	if err := WriteDepFile(dep, tmpDir); err != nil {
+		t.Fatalf("WriteDepFile failed: %v", err)
+	}
+
+	result, err := Export(context.Background(), ExportOptions{FromFiles: tmpDir, ToJSONL: filepath.Join(tmpDir, "out.jsonl")})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(result.OrphanDeps) != 1 {
+		t.Errorf("expected 1 orphan dep, got %v", result.OrphanDeps)
+	}
+}
@@ -8,32 +8,16 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/steveyegge/beads/internal/turso/schema"
 	"github.com/steveyegge/beads/internal/types"
 )
 
-// TaskFile represents the individual JSON file format for tasks/*.json
-type TaskFile struct {
-	ID           string     `json:"id"`
-	Title        string     `json:"title"`
-	Type         string     `json:"type"`
-	Status       string     `json:"status"`
-	Priority     int        `json:"priority"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	AssignedAgent string    `json:"assigned_agent,omitempty"`
-	Description  string     `json:"description,omitempty"`
-	Tags         []string   `json:"tags,omitempty"`
-	DueAt        *time.Time `json:"due_at,omitempty"`
-	DeferUntil   *time.Time `json:"defer_until,omitempty"`
-}
+// TaskFile is the individual JSON file format for tasks/*.json.
+// It aliases schema.TaskFile so migration and the sync daemon share one format.
+type TaskFile = schema.TaskFile
 
-// DepFile represents the individual JSON file format for deps/*.json
-type DepFile struct {
-	From      string    `json:"from"`
-	To        string    `json:"to"`
-	Type      string    `json:"type"`
-	CreatedAt time.Time `json:"created_at"`
-}
+// DepFile is the individual JSON file format for deps/*.json.
+type DepFile = schema.DepFile
 
 // MigrateOptions contains configuration for the migration
 type MigrateOptions struct {
@@ -86,40 +70,21 @@ func FromJSONL(jsonlPath string) ([]*types.Issue, error) {
 
 // IssueToTaskFile converts an Issue to TaskFile format
 func IssueToTaskFile(issue *types.Issue) *TaskFile {
-	task := &TaskFile{
-		ID:          issue.ID,
-		Title:       issue.Title,
-		Type:        string(issue.IssueType),
-		Status:      string(issue.Status),
-		Priority:    issue.Priority,
-		CreatedAt:   issue.CreatedAt,
-		UpdatedAt:   issue.UpdatedAt,
-		Description: issue.Description,
-		DueAt:       issue.DueAt,
-		DeferUntil:  issue.DeferUntil,
-	}
+	task := schema.FromIssue(issue)
 
-	// Convert assignee to assigned_agent
-	if issue.Assignee != "" {
-		task.AssignedAgent = issue.Assignee
-	}
-
-	// Convert labels to tags
-	if len(issue.Labels) > 0 {
-		task.Tags = issue.Labels
+	// Keep empty label sets out of the file entirely
+	if len(task.Tags) == 0 {
+		task.Tags = nil
 	}
 
 	return task
 }
 
-// DependencyToDepFile converts a Dependency to DepFile format
+// DependencyToDepFile converts a Dependency to DepFile format.
+// The dependency target (DependsOnID) becomes From, matching the
+// "{from} blocks {to}" convention used by the Turso cache.
 func DependencyToDepFile(dep *types.Dependency) *DepFile {
-	return &DepFile{
-		From:      dep.IssueID,
-		To:        dep.DependsOnID,
-		Type:      string(dep.Type),
-		CreatedAt: dep.CreatedAt,
-	}
+	return schema.FromTypeDependency(dep)
 }
 
 // DepFileName generates the filename for a dependency
@@ -159,6 +124,11 @@ func WriteTaskFile(task *TaskFile, outputDir string) error {
 
 // WriteDepFile writes a DepFile to disk
 func WriteDepFile(dep *DepFile, outputDir string) error {
+	// Always stamp the current format so readers never treat it as legacy
+	current := *dep
+	current.Format = schema.DepFormat
+	dep = &current
+
 	depPath := filepath.Join(outputDir, "deps", DepFileName(dep.From, dep.Type, dep.To))
 
 	// Ensure parent directory exists
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/steveyegge/beads/internal/turso/schema"
 	"github.com/steveyegge/beads/internal/types"
 )
 
@@ -148,17 +149,22 @@ func TestDependencyToDepFile(t *testing.T) {
 
 	depFile := DependencyToDepFile(dep)
 
-	if depFile.From != "bd-123" {
-		t.Errorf("expected from bd-123, got %s", depFile.From)
+	// The blocker (DependsOnID) is "from": bd-456 blocks bd-123
+	if depFile.From != "bd-456" {
+		t.Errorf("expected from bd-456, got %s", depFile.From)
 	}
 
-	if depFile.To != "bd-456" {
-		t.Errorf("expected to bd-456, got %s", depFile.To)
+	if depFile.To != "bd-123" {
+		t.Errorf("expected to bd-123, got %s", depFile.To)
 	}
 
 	if depFile.Type != "blocks" {
 		t.Errorf("expected type blocks, got %s", depFile.Type)
 	}
+
+	if depFile.Format != schema.DepFormat {
+		t.Errorf("expected format %d, got %d", schema.DepFormat, depFile.Format)
+	}
 }
 
 func TestDepFileName(t *testing.T) {
@@ -12,13 +12,26 @@ import (
 	"github.com/steveyegge/beads/internal/types"
 )
 
+// DepFormat is the dep file format written by this version of beads.
+//
+// Format 1 files were written by `bd turso migrate` before the format was
+// versioned: they have no "format" field and store the dependent issue as
+// from. Format 2 stores the blocker as from, matching the Turso cache's
+// "{from} blocks {to}" convention. ReadDepFile converts older files to the
+// current format, and UpgradeDepFiles rewrites them on disk.
+const DepFormat = 2
+
 // DepFile represents a single dependency stored in deps/*.json
 // Filename convention: {from}--{type}--{to}.json
 type DepFile struct {
+	Format    int       `json:"format,omitempty"` // Dep file format; missing means format 1
 	From      string    `json:"from"`
 	To        string    `json:"to"`
 	Type      string    `json:"type"`
 	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	Metadata  string    `json:"metadata,omitempty"`  // Type-specific edge data (JSON blob)
+	ThreadID  string    `json:"thread_id,omitempty"` // Conversation root for replies-to edges
 }
 
 // Validate checks if the DepFile has valid field values
@@ -43,9 +56,25 @@ func (d *DepFile) Validate() error {
 		return fmt.Errorf("created_at is required")
 	}
 
+	if d.Format > DepFormat {
+		return fmt.Errorf("unsupported dep file format %d (this version of beads reads up to %d)", d.Format, DepFormat)
+	}
+
 	return nil
 }
 
+// upgrade converts a dependency read from an older format file to the
+// current format. Returns true if anything changed.
+func (d *DepFile) upgrade() bool {
+	if d.Format >= DepFormat {
+		return false
+	}
+	// Format 1 stored the dependent issue as from
+	d.From, d.To = d.To, d.From
+	d.Format = DepFormat
+	return true
+}
+
 // ToFileName generates the filename for this dependency
 // Format: {from}--{type}--{to}.json
 func (d *DepFile) ToFileName() string {
@@ -75,35 +104,46 @@ func FromFileName(filename string) (string, string, string, error) {
 	return from, typ, to, nil
 }
 
-// ReadDepFile reads and validates a dependency file
+// ReadDepFile reads and validates a dependency file.
+// Files in an older format are returned converted to the current format.
 func ReadDepFile(path string) (*DepFile, error) {
+	dep, _, err := readDepFile(path)
+	return dep, err
+}
+
+// readDepFile reads a dependency file and reports whether it was in an
+// older format.
+func readDepFile(path string) (*DepFile, bool, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read dep file: %w", err)
+		return nil, false, fmt.Errorf("failed to read dep file: %w", err)
 	}
 
 	var dep DepFile
 	if err := json.Unmarshal(data, &dep); err != nil {
-		return nil, fmt.Errorf("failed to parse dep file: %w", err)
+		return nil, false, fmt.Errorf("failed to parse dep file: %w", err)
 	}
 
 	if err := dep.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid dep file: %w", err)
+		return nil, false, fmt.Errorf("invalid dep file: %w", err)
 	}
 
-	return &dep, nil
+	return &dep, dep.upgrade(), nil
 }
 
-// WriteDepFile writes a dependency file with validation
+// WriteDepFile writes a dependency file with validation.
+// The file is always written in the current format.
 func WriteDepFile(dir string, dep *DepFile) error {
-	if err := dep.Validate(); err != nil {
+	current := *dep
+	current.Format = DepFormat
+	if err := current.Validate(); err != nil {
 		return fmt.Errorf("invalid dependency: %w", err)
 	}
 
-	filename := dep.ToFileName()
+	filename := current.ToFileName()
 	path := filepath.Join(dir, filename)
 
-	data, err := json.MarshalIndent(dep, "", "  ")
+	data, err := json.MarshalIndent(&current, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal dep file: %w", err)
 	}
@@ -115,6 +155,45 @@ func WriteDepFile(dir string, dep *DepFile) error {
 	return nil
 }
 
+// UpgradeDepFiles rewrites every dep file in an older format in the current
+// format, renaming it to match its new from/to order. Returns the number of
+// files upgraded.
+func UpgradeDepFiles(depsDir string) (int, error) {
+	entries, err := os.ReadDir(depsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read deps directory: %w", err)
+	}
+
+	upgraded := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		path := filepath.Join(depsDir, entry.Name())
+		dep, old, err := readDepFile(path)
+		if err != nil || !old {
+			// Invalid files are skipped here as they are everywhere else
+			continue
+		}
+
+		if err := WriteDepFile(depsDir, dep); err != nil {
+			return upgraded, err
+		}
+		if dep.ToFileName() != entry.Name() {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return upgraded, fmt.Errorf("failed to remove old dep file %s: %w", entry.Name(), err)
+			}
+		}
+		upgraded++
+	}
+
+	return upgraded, nil
+}
+
 // ListDepsForIssue lists all dependency files involving a given issue ID
 // Returns both dependencies (where issue is 'from') and dependents (where issue is 'to')
 func ListDepsForIssue(depsDir string, issueID string) ([]*DepFile, error) {
@@ -189,16 +268,23 @@ func (d *DepFile) ToTypeDependency() *types.Dependency {
 		DependsOnID: d.From,
 		Type:        types.DependencyType(d.Type),
 		CreatedAt:   d.CreatedAt,
+		CreatedBy:   d.CreatedBy,
+		Metadata:    d.Metadata,
+		ThreadID:    d.ThreadID,
 	}
 }
 
 // FromTypeDependency creates a DepFile from types.Dependency
 func FromTypeDependency(dep *types.Dependency) *DepFile {
 	return &DepFile{
+		Format:    DepFormat,
 		From:      dep.DependsOnID,
 		To:        dep.IssueID,
 		Type:      string(dep.Type),
 		CreatedAt: dep.CreatedAt,
+		CreatedBy: dep.CreatedBy,
+		Metadata:  dep.Metadata,
+		ThreadID:  dep.ThreadID,
 	}
 }
 
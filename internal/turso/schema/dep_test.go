@@ -610,3 +610,112 @@ func TestListDepsForIssue_SkipsInvalidFiles(t *testing.T) {
 		t.Errorf("ListDepsForIssue() count = %v, want 1 (should skip invalid files)", len(deps))
 	}
 }
+
+func TestReadDepFile_LegacyFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Format 1 files have no format field and store the dependent as from
+	path := filepath.Join(tmpDir, "bd-child--blocks--bd-blocker.json")
+	legacy := `{"from":"bd-child","to":"bd-blocker","type":"blocks","created_at":"2026-01-10T07:36:29Z"}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy dep file: %v", err)
+	}
+
+	dep, err := ReadDepFile(path)
+	if err != nil {
+		t.Fatalf("ReadDepFile() error = %v", err)
+	}
+	if dep.From != "bd-blocker" || dep.To != "bd-child" {
+		t.Errorf("ReadDepFile() = %s -> %s, want bd-blocker -> bd-child", dep.From, dep.To)
+	}
+	if dep.Format != DepFormat {
+		t.Errorf("Format = %d, want %d", dep.Format, DepFormat)
+	}
+
+	typesDep := dep.ToTypeDependency()
+	if typesDep.IssueID != "bd-child" || typesDep.DependsOnID != "bd-blocker" {
+		t.Errorf("ToTypeDependency() = %s depends on %s, want bd-child depends on bd-blocker",
+			typesDep.IssueID, typesDep.DependsOnID)
+	}
+}
+
+func TestReadDepFile_FutureFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	path := filepath.Join(tmpDir, "bd-abc--blocks--bd-xyz.json")
+	future := `{"format":99,"from":"bd-abc","to":"bd-xyz","type":"blocks","created_at":"2026-01-10T07:36:29Z"}`
+	if err := os.WriteFile(path, []byte(future), 0644); err != nil {
+		t.Fatalf("Failed to write dep file: %v", err)
+	}
+
+	if _, err := ReadDepFile(path); err == nil {
+		t.Error("ReadDepFile() expected error for unsupported format, got nil")
+	}
+}
+
+func TestUpgradeDepFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	legacyPath := filepath.Join(tmpDir, "bd-child--blocks--bd-blocker.json")
+	legacy := `{"from":"bd-child","to":"bd-blocker","type":"blocks","created_at":"2026-01-10T07:36:29Z"}`
+	if err := os.WriteFile(legacyPath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy dep file: %v", err)
+	}
+
+	current := &DepFile{
+		From:      "bd-abc",
+		To:        "bd-xyz",
+		Type:      "blocks",
+		CreatedAt: time.Now(),
+	}
+	if err := WriteDepFile(tmpDir, current); err != nil {
+		t.Fatalf("WriteDepFile() error = %v", err)
+	}
+
+	n, err := UpgradeDepFiles(tmpDir)
+	if err != nil {
+		t.Fatalf("UpgradeDepFiles() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("UpgradeDepFiles() = %d, want 1", n)
+	}
+
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Error("Legacy dep file still exists after upgrade")
+	}
+
+	upgraded, err := ReadDepFile(filepath.Join(tmpDir, "bd-blocker--blocks--bd-child.json"))
+	if err != nil {
+		t.Fatalf("ReadDepFile() on upgraded file error = %v", err)
+	}
+	if upgraded.From != "bd-blocker" || upgraded.To != "bd-child" {
+		t.Errorf("upgraded dep = %s -> %s, want bd-blocker -> bd-child", upgraded.From, upgraded.To)
+	}
+
+	// A second pass has nothing left to do
+	n, err = UpgradeDepFiles(tmpDir)
+	if err != nil {
+		t.Fatalf("UpgradeDepFiles() second pass error = %v", err)
+	}
+	if n != 0 {
+		t.Errorf("UpgradeDepFiles() second pass = %d, want 0", n)
+	}
+
+	deps, err := ListAllDeps(tmpDir)
+	if err != nil {
+		t.Fatalf("ListAllDeps() error = %v", err)
+	}
+	if len(deps) != 2 {
+		t.Errorf("ListAllDeps() count = %d, want 2", len(deps))
+	}
+}
+
+func TestUpgradeDepFiles_MissingDir(t *testing.T) {
+	n, err := UpgradeDepFiles(filepath.Join(t.TempDir(), "deps"))
+	if err != nil {
+		t.Fatalf("UpgradeDepFiles() error = %v", err)
+	}
+	if n != 0 {
+		t.Errorf("UpgradeDepFiles() = %d, want 0", n)
+	}
+}
@@ -11,15 +11,20 @@
 // Dependencies are stored as individual JSON files in deps/*.json with the
 // filename convention: {from}--{type}--{to}.json
 //
-// Example: bd-abc--blocks--bd-xyz.json
+// Example: bd-abc--blocks--bd-xyz.json (bd-abc blocks bd-xyz)
 //
 //	{
+//	  "format": 2,
 //	  "from": "bd-abc",
 //	  "to": "bd-xyz",
 //	  "type": "blocks",
 //	  "created_at": "2026-01-10T07:36:29Z"
 //	}
 //
+// Files without a "format" field were written by older versions of
+// `bd turso migrate`, which stored the dependent issue as from. ReadDepFile
+// swaps them into the current order and UpgradeDepFiles rewrites them on disk.
+//
 // # Dependency Types
 //
 // Supported types from internal/types package:
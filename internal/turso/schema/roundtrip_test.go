@@ -0,0 +1,177 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// randomIssue builds an issue with every field that participates in the
+// content hash (and the JSONL encoding) populated from r.
+func randomIssue(r *rand.Rand, n int) *types.Issue {
+	str := func(prefix string) string {
+		if r.Intn(4) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s-%d-%d", prefix, n, r.Intn(1000))
+	}
+	ts := func() time.Time {
+		return time.Unix(1700000000+r.Int63n(10000000), r.Int63n(1e9)).UTC()
+	}
+	tsPtr := func() *time.Time {
+		if r.Intn(2) == 0 {
+			return nil
+		}
+		t := ts()
+		return &t
+	}
+	strPtr := func(prefix string) *string {
+		if r.Intn(2) == 0 {
+			return nil
+		}
+		s := str(prefix)
+		return &s
+	}
+
+	statuses := []types.Status{types.StatusOpen, types.StatusInProgress, types.StatusBlocked, types.StatusClosed, types.StatusDeferred}
+	issueTypes := []types.IssueType{types.TypeTask, types.TypeBug, types.TypeFeature, types.TypeEpic, types.TypeGate, types.TypeMolecule}
+
+	issue := &types.Issue{
+		ID:                 fmt.Sprintf("bd-rt%d", n),
+		Title:              fmt.Sprintf("Round trip %d", n),
+		Description:        str("desc"),
+		Design:             str("design"),
+		AcceptanceCriteria: str("acceptance"),
+		Notes:              str("notes"),
+		Status:             statuses[r.Intn(len(statuses))],
+		Priority:           r.Intn(5),
+		IssueType:          issueTypes[r.Intn(len(issueTypes))],
+		Assignee:           str("agent"),
+		CreatedAt:          ts(),
+		CreatedBy:          str("creator"),
+		UpdatedAt:          ts(),
+		ClosedAt:           tsPtr(),
+		CloseReason:        str("reason"),
+		ClosedBySession:    str("session"),
+		DueAt:              tsPtr(),
+		DeferUntil:         tsPtr(),
+		ExternalRef:        strPtr("gh"),
+		CompactionLevel:    r.Intn(3),
+		CompactedAt:        tsPtr(),
+		CompactedAtCommit:  strPtr("commit"),
+		OriginalSize:       r.Intn(5000),
+		Sender:             str("sender"),
+		Pinned:             r.Intn(2) == 0,
+		IsTemplate:         r.Intn(2) == 0,
+		AwaitType:          str("gh:run"),
+		AwaitID:            str("run"),
+		Timeout:            time.Duration(r.Intn(3600)) * time.Second,
+		Holder:             str("holder"),
+		SourceFormula:      str("formula"),
+		SourceLocation:     str("steps"),
+		HookBead:           str("bd-hook"),
+		RoleBead:           str("bd-role"),
+		AgentState:         types.AgentState(str("state")),
+		LastActivity:       tsPtr(),
+		RoleType:           str("polecat"),
+		Rig:                str("rig"),
+		MolType:            types.MolType(str("swarm")),
+		EventKind:          str("patrol.muted"),
+		Actor:              str("actor"),
+		Target:             str("target"),
+		Payload:            str("payload"),
+	}
+
+	if minutes := r.Intn(120); minutes > 0 {
+		issue.EstimatedMinutes = &minutes
+	}
+	for i := 0; i < r.Intn(4); i++ {
+		issue.Labels = append(issue.Labels, fmt.Sprintf("label-%d", i))
+	}
+	for i := 0; i < r.Intn(3); i++ {
+		issue.Waiters = append(issue.Waiters, fmt.Sprintf("waiter-%d", i))
+	}
+	for i := 0; i < r.Intn(3); i++ {
+		issue.Comments = append(issue.Comments, &types.Comment{
+			ID:        int64(i + 1),
+			IssueID:   issue.ID,
+			Author:    str("author"),
+			Text:      fmt.Sprintf("comment %d", i),
+			CreatedAt: ts(),
+		})
+	}
+	for i := 0; i < r.Intn(3); i++ {
+		issue.BondedFrom = append(issue.BondedFrom, types.BondRef{
+			SourceID:  fmt.Sprintf("proto-%d", i),
+			BondType:  types.BondTypeSequential,
+			BondPoint: str("bd-point"),
+		})
+	}
+	if r.Intn(2) == 0 {
+		issue.Creator = &types.EntityRef{Name: "polecat/Nux", Platform: "gastown", Org: "beads", ID: str("id")}
+	}
+	for i := 0; i < r.Intn(3); i++ {
+		score := float32(r.Intn(100)) / 100
+		issue.Validations = append(issue.Validations, types.Validation{
+			Validator: &types.EntityRef{Name: fmt.Sprintf("validator-%d", i)},
+			Outcome:   "accepted",
+			Timestamp: ts().Truncate(time.Second),
+			Score:     &score,
+		})
+	}
+
+	return issue
+}
+
+// TestIssueTaskFileRoundTrip_PreservesContentHash is a property test: for many
+// randomly populated issues, Issue -> TaskFile -> JSON file -> TaskFile -> Issue
+// must preserve both the content hash and the JSONL encoding.
+func TestIssueTaskFileRoundTrip_PreservesContentHash(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	dir := t.TempDir()
+
+	for n := 0; n < 200; n++ {
+		original := randomIssue(r, n)
+
+		if err := WriteTaskFile(dir, FromIssue(original)); err != nil {
+			t.Fatalf("issue %d: WriteTaskFile() error = %v", n, err)
+		}
+		task, err := ReadTaskFile(filepath.Join(dir, original.ID+".json"))
+		if err != nil {
+			t.Fatalf("issue %d: ReadTaskFile() error = %v", n, err)
+		}
+		got := task.ToIssue()
+
+		if want, have := original.ComputeContentHash(), got.ComputeContentHash(); want != have {
+			t.Fatalf("issue %d: content hash changed across round trip\nwant %s\ngot  %s", n, want, have)
+		}
+
+		wantJSON, _ := json.Marshal(original)
+		gotJSON, _ := json.Marshal(got)
+		if string(wantJSON) != string(gotJSON) {
+			t.Fatalf("issue %d: JSONL encoding changed across round trip\nwant %s\ngot  %s", n, wantJSON, gotJSON)
+		}
+	}
+}
+
+func TestDepFileRoundTrip_PreservesMetadata(t *testing.T) {
+	original := &types.Dependency{
+		IssueID:     "bd-reply",
+		DependsOnID: "bd-root",
+		Type:        types.DepRepliesTo,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+		CreatedBy:   "alice",
+		Metadata:    `{"score":0.9}`,
+		ThreadID:    "bd-root",
+	}
+
+	got := FromTypeDependency(original).ToTypeDependency()
+	if *got != *original {
+		t.Errorf("dependency round trip = %+v, want %+v", got, original)
+	}
+}
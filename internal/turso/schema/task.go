@@ -20,16 +20,22 @@ type TaskFile struct {
 	ID string `json:"id"`
 
 	// ===== Task Content =====
-	Title       string `json:"title"`
-	Description string `json:"description,omitempty"`
-	Type        string `json:"type"` // bug, feature, task, epic, chore
-	Status      string `json:"status"` // open, in_progress, blocked, closed, etc.
+	Title              string `json:"title"`
+	Description        string `json:"description,omitempty"`
+	Design             string `json:"design,omitempty"`
+	AcceptanceCriteria string `json:"acceptance_criteria,omitempty"`
+	Notes              string `json:"notes,omitempty"`
+	Type               string `json:"type"`   // bug, feature, task, epic, chore
+	Status             string `json:"status"` // open, in_progress, blocked, closed, etc.
 
 	// ===== Priority & Scheduling =====
-	Priority int `json:"priority"` // 0-4 (P0=critical, P4=backlog)
+	Priority         int      `json:"priority"` // 0-4 (P0=critical, P4=backlog)
+	EstimatedMinutes *int     `json:"estimated_minutes,omitempty"`
+	BudgetUSD        *float64 `json:"budget_usd,omitempty"` // Spend limit for the task and its subtasks
 
 	// ===== Assignment & Ownership =====
 	AssignedAgent string `json:"assigned_agent,omitempty"` // Agent ID for ownership
+	CreatedBy     string `json:"created_by,omitempty"`
 
 	// ===== Tags & Classification =====
 	Tags []string `json:"tags,omitempty"` // Labels/tags for categorization
@@ -38,9 +44,78 @@ type TaskFile struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// ===== Closure =====
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	CloseReason     string     `json:"close_reason,omitempty"`
+	ClosedBySession string     `json:"closed_by_session,omitempty"`
+
 	// ===== Time-Based Scheduling =====
 	DueAt      *time.Time `json:"due_at,omitempty"`      // When this should be completed
 	DeferUntil *time.Time `json:"defer_until,omitempty"` // Hide from ready work until this time
+
+	// ===== External Integration =====
+	ExternalRef *string `json:"external_ref,omitempty"`
+
+	// ===== Compaction Metadata =====
+	CompactionLevel   int        `json:"compaction_level,omitempty"`
+	CompactedAt       *time.Time `json:"compacted_at,omitempty"`
+	CompactedAtCommit *string    `json:"compacted_at_commit,omitempty"`
+	OriginalSize      int        `json:"original_size,omitempty"`
+
+	// ===== Comments =====
+	// Comments are embedded rather than stored as separate files because they
+	// are append-only and always read together with the task.
+	Comments []*types.Comment `json:"comments,omitempty"`
+
+	// ===== Attachments =====
+	// Attachment references are embedded like comments; the content lives in
+	// the blob store.
+	Attachments []*types.Attachment `json:"attachments,omitempty"`
+
+	// ===== Tombstone Fields =====
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy    string     `json:"deleted_by,omitempty"`
+	DeleteReason string     `json:"delete_reason,omitempty"`
+	OriginalType string     `json:"original_type,omitempty"`
+
+	// ===== Messaging & Context Markers =====
+	Sender     string `json:"sender,omitempty"`
+	Ephemeral  bool   `json:"ephemeral,omitempty"`
+	Pinned     bool   `json:"pinned,omitempty"`
+	IsTemplate bool   `json:"is_template,omitempty"`
+
+	// ===== HOP Entity Tracking =====
+	Creator     *types.EntityRef   `json:"creator,omitempty"`
+	Validations []types.Validation `json:"validations,omitempty"`
+
+	// ===== Gate Fields =====
+	AwaitType string        `json:"await_type,omitempty"`
+	AwaitID   string        `json:"await_id,omitempty"`
+	Timeout   time.Duration `json:"timeout,omitempty"`
+	Waiters   []string      `json:"waiters,omitempty"`
+
+	// ===== Slot Fields =====
+	Holder string `json:"holder,omitempty"`
+
+	// ===== Molecule Fields =====
+	MolType        string          `json:"mol_type,omitempty"`
+	BondedFrom     []types.BondRef `json:"bonded_from,omitempty"`
+	SourceFormula  string          `json:"source_formula,omitempty"`
+	SourceLocation string          `json:"source_location,omitempty"`
+
+	// ===== Agent Identity Fields =====
+	HookBead     string     `json:"hook_bead,omitempty"`
+	RoleBead     string     `json:"role_bead,omitempty"`
+	AgentState   string     `json:"agent_state,omitempty"`
+	LastActivity *time.Time `json:"last_activity,omitempty"`
+	RoleType     string     `json:"role_type,omitempty"`
+	Rig          string     `json:"rig,omitempty"`
+
+	// ===== Event Fields =====
+	EventKind string `json:"event_kind,omitempty"`
+	Actor     string `json:"actor,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Payload   string `json:"payload,omitempty"`
 }
 
 // Validate checks if the TaskFile has valid field values.
@@ -79,40 +154,132 @@ func (t *TaskFile) Filename() string {
 
 // ToIssue converts TaskFile to the existing types.Issue structure.
 // This allows interoperability with the current storage layer.
+// Dependencies live in deps/*.json and are not populated here.
 func (t *TaskFile) ToIssue() *types.Issue {
 	issue := &types.Issue{
-		ID:          t.ID,
-		Title:       t.Title,
-		Description: t.Description,
-		IssueType:   types.IssueType(t.Type),
-		Status:      types.Status(t.Status),
-		Priority:    t.Priority,
-		Assignee:    t.AssignedAgent,
-		CreatedAt:   t.CreatedAt,
-		UpdatedAt:   t.UpdatedAt,
-		DueAt:       t.DueAt,
-		DeferUntil:  t.DeferUntil,
-		Labels:      t.Tags,
+		ID:                 t.ID,
+		Title:              t.Title,
+		Description:        t.Description,
+		Design:             t.Design,
+		AcceptanceCriteria: t.AcceptanceCriteria,
+		Notes:              t.Notes,
+		IssueType:          types.IssueType(t.Type),
+		Status:             types.Status(t.Status),
+		Priority:           t.Priority,
+		EstimatedMinutes:   t.EstimatedMinutes,
+		BudgetUSD:          t.BudgetUSD,
+		Assignee:           t.AssignedAgent,
+		CreatedBy:          t.CreatedBy,
+		CreatedAt:          t.CreatedAt,
+		UpdatedAt:          t.UpdatedAt,
+		ClosedAt:           t.ClosedAt,
+		CloseReason:        t.CloseReason,
+		ClosedBySession:    t.ClosedBySession,
+		DueAt:              t.DueAt,
+		DeferUntil:         t.DeferUntil,
+		ExternalRef:        t.ExternalRef,
+		CompactionLevel:    t.CompactionLevel,
+		CompactedAt:        t.CompactedAt,
+		CompactedAtCommit:  t.CompactedAtCommit,
+		OriginalSize:       t.OriginalSize,
+		Labels:             t.Tags,
+		Comments:           t.Comments,
+		Attachments:        t.Attachments,
+		DeletedAt:          t.DeletedAt,
+		DeletedBy:          t.DeletedBy,
+		DeleteReason:       t.DeleteReason,
+		OriginalType:       t.OriginalType,
+		Sender:             t.Sender,
+		Ephemeral:          t.Ephemeral,
+		Pinned:             t.Pinned,
+		IsTemplate:         t.IsTemplate,
+		Creator:            t.Creator,
+		Validations:        t.Validations,
+		AwaitType:          t.AwaitType,
+		AwaitID:            t.AwaitID,
+		Timeout:            t.Timeout,
+		Waiters:            t.Waiters,
+		Holder:             t.Holder,
+		MolType:            types.MolType(t.MolType),
+		BondedFrom:         t.BondedFrom,
+		SourceFormula:      t.SourceFormula,
+		SourceLocation:     t.SourceLocation,
+		HookBead:           t.HookBead,
+		RoleBead:           t.RoleBead,
+		AgentState:         types.AgentState(t.AgentState),
+		LastActivity:       t.LastActivity,
+		RoleType:           t.RoleType,
+		Rig:                t.Rig,
+		EventKind:          t.EventKind,
+		Actor:              t.Actor,
+		Target:             t.Target,
+		Payload:            t.Payload,
 	}
 	return issue
 }
 
 // FromIssue converts a types.Issue to TaskFile format.
 // This is the inverse of ToIssue() for migration and compatibility.
+// Dependencies are not included; use FromTypeDependency for each of them.
 func FromIssue(issue *types.Issue) *TaskFile {
 	task := &TaskFile{
-		ID:            issue.ID,
-		Title:         issue.Title,
-		Description:   issue.Description,
-		Type:          string(issue.IssueType),
-		Status:        string(issue.Status),
-		Priority:      issue.Priority,
-		AssignedAgent: issue.Assignee,
-		CreatedAt:     issue.CreatedAt,
-		UpdatedAt:     issue.UpdatedAt,
-		DueAt:         issue.DueAt,
-		DeferUntil:    issue.DeferUntil,
-		Tags:          issue.Labels,
+		ID:                 issue.ID,
+		Title:              issue.Title,
+		Description:        issue.Description,
+		Design:             issue.Design,
+		AcceptanceCriteria: issue.AcceptanceCriteria,
+		Notes:              issue.Notes,
+		Type:               string(issue.IssueType),
+		Status:             string(issue.Status),
+		Priority:           issue.Priority,
+		EstimatedMinutes:   issue.EstimatedMinutes,
+		BudgetUSD:          issue.BudgetUSD,
+		AssignedAgent:      issue.Assignee,
+		CreatedBy:          issue.CreatedBy,
+		CreatedAt:          issue.CreatedAt,
+		UpdatedAt:          issue.UpdatedAt,
+		ClosedAt:           issue.ClosedAt,
+		CloseReason:        issue.CloseReason,
+		ClosedBySession:    issue.ClosedBySession,
+		DueAt:              issue.DueAt,
+		DeferUntil:         issue.DeferUntil,
+		ExternalRef:        issue.ExternalRef,
+		CompactionLevel:    issue.CompactionLevel,
+		CompactedAt:        issue.CompactedAt,
+		CompactedAtCommit:  issue.CompactedAtCommit,
+		OriginalSize:       issue.OriginalSize,
+		Tags:               issue.Labels,
+		Comments:           issue.Comments,
+		Attachments:        issue.Attachments,
+		DeletedAt:          issue.DeletedAt,
+		DeletedBy:          issue.DeletedBy,
+		DeleteReason:       issue.DeleteReason,
+		OriginalType:       issue.OriginalType,
+		Sender:             issue.Sender,
+		Ephemeral:          issue.Ephemeral,
+		Pinned:             issue.Pinned,
+		IsTemplate:         issue.IsTemplate,
+		Creator:            issue.Creator,
+		Validations:        issue.Validations,
+		AwaitType:          issue.AwaitType,
+		AwaitID:            issue.AwaitID,
+		Timeout:            issue.Timeout,
+		Waiters:            issue.Waiters,
+		Holder:             issue.Holder,
+		MolType:            string(issue.MolType),
+		BondedFrom:         issue.BondedFrom,
+		SourceFormula:      issue.SourceFormula,
+		SourceLocation:     issue.SourceLocation,
+		HookBead:           issue.HookBead,
+		RoleBead:           issue.RoleBead,
+		AgentState:         string(issue.AgentState),
+		LastActivity:       issue.LastActivity,
+		RoleType:           issue.RoleType,
+		Rig:                issue.Rig,
+		EventKind:          issue.EventKind,
+		Actor:              issue.Actor,
+		Target:             issue.Target,
+		Payload:            issue.Payload,
 	}
 	return task
 }
@@ -24,7 +24,7 @@
 // Basic usage:
 //
 //	// Open database
-//	database, err := db.Open("file:.beads/turso.db")
+//	database, err := db.Open(".beads/turso.db")
 //	if err != nil {
 //	    return err
 //	}
@@ -12,7 +12,7 @@ import (
 // Note: This is for documentation only and won't run as a test.
 func ExampleNew() {
 	// Open database
-	database, err := db.Open("file:.beads/turso.db")
+	database, err := db.Open(".beads/turso.db")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -36,7 +36,7 @@ func ExampleNew() {
 
 // This example demonstrates syncing individual files.
 func ExampleSyncer_SyncTask() {
-	database, err := db.Open("file:.beads/turso.db")
+	database, err := db.Open(".beads/turso.db")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -59,7 +59,7 @@ func ExampleSyncer_SyncTask() {
 
 // This example demonstrates deleting from cache.
 func ExampleSyncer_DeleteTask() {
-	database, err := db.Open("file:.beads/turso.db")
+	database, err := db.Open(".beads/turso.db")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -19,7 +19,7 @@ func setupTestDB(t *testing.T) (*db.DB, string) {
 	tmpDir := t.TempDir()
 	dbPath := filepath.Join(tmpDir, "test.db")
 
-	database, err := db.Open("file:" + dbPath)
+	database, err := db.Open(dbPath)
 	if err != nil {
 		t.Fatalf("failed to open test database: %v", err)
 	}
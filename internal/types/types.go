@@ -29,8 +29,9 @@ type Issue struct {
 	IssueType IssueType `json:"issue_type,omitempty"`
 
 	// ===== Assignment =====
-	Assignee         string `json:"assignee,omitempty"`
-	EstimatedMinutes *int   `json:"estimated_minutes,omitempty"`
+	Assignee         string   `json:"assignee,omitempty"`
+	EstimatedMinutes *int     `json:"estimated_minutes,omitempty"`
+	BudgetUSD        *float64 `json:"budget_usd,omitempty"` // Spend limit for this issue and its descendants
 
 	// ===== Timestamps =====
 	CreatedAt   time.Time  `json:"created_at"`
@@ -61,6 +62,7 @@ type Issue struct {
 	Labels       []string      `json:"labels,omitempty"`
 	Dependencies []*Dependency `json:"dependencies,omitempty"`
 	Comments     []*Comment    `json:"comments,omitempty"`
+	Attachments  []*Attachment `json:"attachments,omitempty"`
 
 	// ===== Tombstone Fields (soft-delete support) =====
 	DeletedAt    *time.Time `json:"deleted_at,omitempty"`    // When deleted
@@ -185,6 +187,11 @@ func (i *Issue) ComputeContentHash() string {
 	w.str(i.Target)
 	w.str(i.Payload)
 
+	// Budget is only hashed when set so existing content hashes stay stable
+	if i.BudgetUSD != nil {
+		w.str(fmt.Sprintf("budget:%g", *i.BudgetUSD))
+	}
+
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
@@ -325,6 +332,9 @@ func (i *Issue) ValidateWithCustom(customStatuses, customTypes []string) error {
 	if i.EstimatedMinutes != nil && *i.EstimatedMinutes < 0 {
 		return fmt.Errorf("estimated_minutes cannot be negative")
 	}
+	if i.BudgetUSD != nil && *i.BudgetUSD < 0 {
+		return fmt.Errorf("budget_usd cannot be negative")
+	}
 	// Enforce closed_at invariant: closed_at should be set if and only if status is closed
 	// Exception: tombstones may retain closed_at from before deletion
 	if i.Status == StatusClosed && i.ClosedAt == nil {
@@ -571,6 +581,7 @@ type IssueDetails struct {
 	Dependents   []*IssueWithDependencyMetadata `json:"dependents,omitempty"`
 	Comments     []*Comment                     `json:"comments,omitempty"`
 	Parent       *string                        `json:"parent,omitempty"`
+	Usage        *Usage                         `json:"usage,omitempty"` // Issue plus descendants, from the audit log
 }
 
 // DependencyType categorizes the relationship
@@ -697,6 +708,19 @@ type Comment struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Attachment is a file attached to an issue. The content lives in the
+// content-addressed blob store (.beads/blobs/) under its SHA256; the issue
+// only carries the reference, so exports stay small.
+type Attachment struct {
+	IssueID   string    `json:"issue_id"`
+	Name      string    `json:"name"` // Unique per issue; re-attaching a name replaces it
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	MimeType  string    `json:"mime_type,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+}
+
 // Event represents an audit trail entry
 type Event struct {
 	ID        int64      `json:"id"`
@@ -707,6 +731,8 @@ type Event struct {
 	NewValue  *string    `json:"new_value,omitempty"`
 	Comment   *string    `json:"comment,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
+	CommandID string     `json:"command_id,omitempty"` // bd invocation that wrote the event
+	SourceOp  string     `json:"source_op,omitempty"`  // VCS operation an import applied
 }
 
 // EventType categorizes audit trail events
@@ -730,8 +756,15 @@ const (
 // BlockedIssue extends Issue with blocking information
 type BlockedIssue struct {
 	Issue
-	BlockedByCount int      `json:"blocked_by_count"`
-	BlockedBy      []string `json:"blocked_by"`
+	BlockedByCount   int               `json:"blocked_by_count"`
+	BlockedBy        []string          `json:"blocked_by"`
+	ExternalBlockers []ExternalBlocker `json:"external_blockers,omitempty"` // Why each external:... blocker is unsatisfied
+}
+
+// ExternalBlocker explains an unsatisfied cross-project dependency.
+type ExternalBlocker struct {
+	Ref    string `json:"ref"`    // external:<project>:<capability>[@<constraint>]
+	Reason string `json:"reason"` // e.g. "capability not shipped (...)"
 }
 
 // TreeNode represents a node in a dependency tree
@@ -753,6 +786,7 @@ type MoleculeProgressStats struct {
 	CurrentStepID string     `json:"current_step_id"` // First in_progress step ID (if any)
 	FirstClosed   *time.Time `json:"first_closed,omitempty"`
 	LastClosed    *time.Time `json:"last_closed,omitempty"`
+	Usage         *Usage     `json:"usage,omitempty"` // Rolled up from the audit log; filled in by the CLI
 }
 
 // Statistics provides aggregate metrics
@@ -805,6 +839,9 @@ type IssueFilter struct {
 	PriorityMin *int
 	PriorityMax *int
 
+	// State dimension filtering (AND semantics across entries)
+	States []StateFilter
+
 	// Tombstone filtering
 	IncludeTombstones bool // If false (default), exclude tombstones from results
 
@@ -838,6 +875,20 @@ type IssueFilter struct {
 	Overdue     bool       // Filter issues where due_at < now AND status != closed
 }
 
+// StateFilter matches issues by the value of a state dimension, i.e. by
+// their <dimension>:<value> label.
+type StateFilter struct {
+	Dimension    string `json:"dimension"`
+	Value        string `json:"value,omitempty"`         // Empty matches any value
+	IncludeUnset bool   `json:"include_unset,omitempty"` // Also match issues with no value (Value is the schema default)
+}
+
+// Label returns the label for the filter's value, or the label prefix
+// shared by every value when Value is empty.
+func (f StateFilter) Label() string {
+	return f.Dimension + ":" + f.Value
+}
+
 // SortPolicy determines how ready work is ordered
 type SortPolicy string
 
@@ -901,6 +952,35 @@ type EpicStatus struct {
 	TotalChildren    int    `json:"total_children"`
 	ClosedChildren   int    `json:"closed_children"`
 	EligibleForClose bool   `json:"eligible_for_close"`
+	Usage            *Usage `json:"usage,omitempty"` // Rolled up from the audit log; filled in by the CLI
+}
+
+// Usage is model usage accounted to an issue, usually rolled up over an
+// issue and its descendants from the audit log.
+type Usage struct {
+	Calls        int     `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	DurationMs   int64   `json:"duration_ms"`
+}
+
+// Add accumulates other into u.
+func (u *Usage) Add(other *Usage) {
+	if other == nil {
+		return
+	}
+	u.Calls += other.Calls
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CostUSD += other.CostUSD
+	u.DurationMs += other.DurationMs
+}
+
+// OverBudget reports whether the spend exceeds an issue's budget. An issue
+// without a budget is never over it.
+func (u *Usage) OverBudget(issue *Issue) bool {
+	return u != nil && issue != nil && issue.BudgetUSD != nil && u.CostUSD > *issue.BudgetUSD
 }
 
 // BondRef tracks compound molecule lineage.
@@ -37,7 +37,8 @@ type DetectionResult struct {
 //
 // Detection precedence:
 //  1. Check for .jj directory (indicates jj or colocated mode)
-//  2. Check for .git directory or file (indicates git or worktree)
+//  2. Check for .git directory or file (indicates git or worktree),
+//     or a bare git repository at path itself
 //  3. Walk up parent directories until VCS found or root reached
 //
 // For colocated repositories (both .jj and .git present), the Type
@@ -91,6 +92,14 @@ func Detect(path string) (*DetectionResult, error) {
 			}
 		}
 
+		// A bare git repository has no .git; only check the starting
+		// directory so paths inside a .git directory aren't mistaken for one
+		if !result.HasJJ && !result.HasGit && current == absPath && isBareGitDir(current) {
+			result.HasGit = true
+			result.RepoRoot = current
+			result.VCSDir = current
+		}
+
 		// If we found VCS markers, determine the type and return
 		if result.HasJJ || result.HasGit {
 			result.Colocated = result.HasJJ && result.HasGit
@@ -122,6 +131,20 @@ func Detect(path string) (*DetectionResult, error) {
 	}
 }
 
+// isBareGitDir returns true if dir looks like a bare git repository:
+// a HEAD file next to objects and refs directories.
+func isBareGitDir(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	for _, sub := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
 // resolveGitWorktreeRoot resolves the main repository root from a worktree's .git file.
 // Returns (mainRepoRoot, worktreeGitDir).
 //
@@ -58,7 +58,7 @@ func (g *Git) Status(paths ...string) ([]vcs.FileStatus, error) {
 	}
 
 	var statuses []vcs.FileStatus
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
 
 	for _, line := range lines {
 		if line == "" {
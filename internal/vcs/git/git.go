@@ -9,7 +9,10 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/steveyegge/beads/internal/vcs"
 )
@@ -22,6 +25,9 @@ type Git struct {
 	// vcsDir is the .git directory path (may be a file for worktrees)
 	vcsDir string
 
+	// commonDir is the .git directory shared by all worktrees
+	commonDir string
+
 	// isWorktree indicates if this is a git worktree
 	isWorktree bool
 
@@ -80,6 +86,14 @@ func (g *Git) VCSDir() (string, error) {
 	return g.vcsDir, nil
 }
 
+// CommonDir returns the .git directory shared by all worktrees
+func (g *Git) CommonDir() (string, error) {
+	if g.commonDir == "" {
+		return "", vcs.ErrNotInVCS
+	}
+	return g.commonDir, nil
+}
+
 // IsInVCS returns true if inside a git repository
 func (g *Git) IsInVCS() bool {
 	return g.repoRoot != ""
@@ -133,8 +147,9 @@ func (g *Git) GetOperationLog(limit int) ([]vcs.OperationInfo, error) {
 		limit = 10
 	}
 
+	// With --date=unix, %gD renders as HEAD@{<unix time>}
 	cmd := exec.Command("git", "reflog", "-n", fmt.Sprintf("%d", limit),
-		"--format=%H %gD %gs")
+		"--date=unix", "--format=%H %gD %gs")
 	cmd.Dir = g.repoRoot
 
 	output, err := cmd.Output()
@@ -142,8 +157,13 @@ func (g *Git) GetOperationLog(limit int) ([]vcs.OperationInfo, error) {
 		return nil, fmt.Errorf("git reflog failed: %w", err)
 	}
 
+	return parseReflog(string(output)), nil
+}
+
+// parseReflog parses `git reflog --date=unix --format="%H %gD %gs"` output.
+func parseReflog(output string) []vcs.OperationInfo {
 	var ops []vcs.OperationInfo
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
 
 	for _, line := range lines {
 		if line == "" {
@@ -155,12 +175,24 @@ func (g *Git) GetOperationLog(limit int) ([]vcs.OperationInfo, error) {
 			continue
 		}
 
-		ops = append(ops, vcs.OperationInfo{
+		op := vcs.OperationInfo{
 			ID:          parts[0],
 			Description: parts[2],
-			// Git reflog doesn't have structured timestamps/user/args
-		})
+			// Git reflog doesn't have structured user/args
+		}
+		if start := strings.Index(parts[1], "@{"); start >= 0 && strings.HasSuffix(parts[1], "}") {
+			if secs, err := strconv.ParseInt(parts[1][start+2:len(parts[1])-1], 10, 64); err == nil {
+				op.Timestamp = time.Unix(secs, 0)
+			}
+		}
+		ops = append(ops, op)
 	}
 
-	return ops, nil
+	return ops
+}
+
+// ExtractFileAtOperation returns a file's content at the commit a reflog
+// entry points to.
+func (g *Git) ExtractFileAtOperation(opID, path string) ([]byte, error) {
+	return g.ExtractFileFromRef(opID, filepath.ToSlash(path))
 }
@@ -5,7 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/steveyegge/beads/internal/vcs"
 )
@@ -405,3 +407,238 @@ func TestConflicts(t *testing.T) {
 		t.Error("IsInRebaseOrMerge() = true for clean repo, want false")
 	}
 }
+
+func TestStatusModifiedFirstLine(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(repoPath)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	testFile := filepath.Join(repoPath, "test.txt")
+	if err := os.WriteFile(testFile, []byte("v1"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := g.Commit(ctx, vcs.CommitOptions{Message: "init", Paths: []string{"test.txt"}}); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+
+	// " M test.txt" starts with a space that must not be trimmed away
+	if err := os.WriteFile(testFile, []byte("v2"), 0644); err != nil {
+		t.Fatalf("failed to modify test file: %v", err)
+	}
+	statuses, err := g.Status()
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Path != "test.txt" {
+		t.Fatalf("Status() = %+v, want test.txt", statuses)
+	}
+	if statuses[0].Status != vcs.StatusModified {
+		t.Errorf("Status()[0].Status = %v, want %v", statuses[0].Status, vcs.StatusModified)
+	}
+}
+
+func TestSyncHelpers(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(repoPath)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if got := g.RemoteRef("origin", "beads-sync"); got != "origin/beads-sync" {
+		t.Errorf("RemoteRef() = %v, want origin/beads-sync", got)
+	}
+
+	testFile := filepath.Join(repoPath, "test.txt")
+	commit := func(content, msg string) string {
+		t.Helper()
+		if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		if err := g.Commit(ctx, vcs.CommitOptions{Message: msg, Paths: []string{"test.txt"}}); err != nil {
+			t.Fatalf("Commit() failed: %v", err)
+		}
+		hash, err := g.GetCommitHash("HEAD")
+		if err != nil {
+			t.Fatalf("GetCommitHash() failed: %v", err)
+		}
+		return hash
+	}
+
+	base := commit("v1", "first")
+	current, _ := g.CurrentRef()
+	if err := g.CreateRef("side", "HEAD"); err != nil {
+		t.Fatalf("CreateRef() failed: %v", err)
+	}
+	commit("v2", "second")
+
+	// Upstream is empty until the branch tracks a remote
+	if remote, err := g.Upstream(current); err != nil || remote != "" {
+		t.Errorf("Upstream() = %q, %v, want empty", remote, err)
+	}
+	exec.Command("git", "-C", repoPath, "config", "branch."+current+".remote", "upstream").Run()
+	if remote, _ := g.Upstream(current); remote != "upstream" {
+		t.Errorf("Upstream() = %q, want upstream", remote)
+	}
+
+	if mb, err := g.MergeBase(current, "side"); err != nil || mb != base {
+		t.Errorf("MergeBase() = %q, %v, want %v", mb, err, base)
+	}
+
+	// Restore discards working copy edits to tracked files and skips untracked ones
+	if err := os.WriteFile(testFile, []byte("dirty"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if err := g.Restore(ctx, "test.txt", "missing.txt"); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(testFile); string(data) != "v2" {
+		t.Errorf("after Restore() test.txt = %q, want v2", data)
+	}
+
+	// ResetRef on the checked-out branch resets the working copy too
+	if err := g.ResetRef(ctx, current, "side"); err != nil {
+		t.Fatalf("ResetRef() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(testFile); string(data) != "v1" {
+		t.Errorf("after ResetRef() test.txt = %q, want v1", data)
+	}
+
+	// ResetRef on another branch just moves it
+	if err := g.ResetRef(ctx, "side", current); err != nil {
+		t.Fatalf("ResetRef() on other branch failed: %v", err)
+	}
+	if hash, _ := g.GetCommitHash("side"); hash != base {
+		t.Errorf("side = %v, want %v", hash, base)
+	}
+}
+
+func TestLogDiffMerge(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(repoPath)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	commit := func(name, content, msg string) {
+		t.Helper()
+		write(name, content)
+		if err := g.Commit(ctx, vcs.CommitOptions{Message: msg, Paths: []string{name}}); err != nil {
+			t.Fatalf("Commit() failed: %v", err)
+		}
+	}
+
+	commit("a.txt", "a1", "base")
+	current, _ := g.CurrentRef()
+	if err := g.CreateRef("side", "HEAD"); err != nil {
+		t.Fatalf("CreateRef() failed: %v", err)
+	}
+	exec.Command("git", "-C", repoPath, "checkout", "-q", "side").Run()
+	commit("b.txt", "b1", "side change")
+	exec.Command("git", "-C", repoPath, "checkout", "-q", current).Run()
+	commit("a.txt", "a2", "main change")
+
+	commits, err := g.Log(current, "side")
+	if err != nil || len(commits) != 1 || !strings.HasSuffix(commits[0], " side change") {
+		t.Errorf("Log() = %q, %v, want the side commit", commits, err)
+	}
+
+	// Diff is relative to the fork point, so main's change to a.txt is not in it
+	diff, err := g.Diff(current, "side")
+	if err != nil {
+		t.Fatalf("Diff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "b.txt") || strings.Contains(diff, "a.txt") {
+		t.Errorf("Diff() = %q, want only b.txt", diff)
+	}
+
+	if err := g.Merge(ctx, "side", "Merge side"); err != nil {
+		t.Fatalf("Merge() failed: %v", err)
+	}
+	if commits, _ := g.Log(current, "side"); len(commits) != 0 {
+		t.Errorf("after Merge() Log() = %q, want none", commits)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoPath, "b.txt")); string(data) != "b1" {
+		t.Errorf("after Merge() b.txt = %q, want b1", data)
+	}
+
+	// RestoreFrom takes the content at the given ref
+	write("a.txt", "dirty")
+	if err := g.RestoreFrom(ctx, "side", "a.txt"); err != nil {
+		t.Fatalf("RestoreFrom() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoPath, "a.txt")); string(data) != "a1" {
+		t.Errorf("after RestoreFrom() a.txt = %q, want a1", data)
+	}
+}
+
+func TestOperationLog(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(repoPath)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+
+	testFile := filepath.Join(repoPath, "test.txt")
+	for _, content := range []string{"first", "second"} {
+		if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		if err := g.Commit(ctx, vcs.CommitOptions{Message: content, Paths: []string{"test.txt"}}); err != nil {
+			t.Fatalf("Commit() failed: %v", err)
+		}
+	}
+
+	ops, err := g.GetOperationLog(10)
+	if err != nil {
+		t.Fatalf("GetOperationLog() failed: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("GetOperationLog() returned %d ops, want 2", len(ops))
+	}
+	if ops[0].Timestamp.IsZero() || time.Since(ops[0].Timestamp) > time.Hour {
+		t.Errorf("expected a recent timestamp, got %v", ops[0].Timestamp)
+	}
+
+	// Newest first: the older entry still has the first version
+	content, err := g.ExtractFileAtOperation(ops[1].ID, "test.txt")
+	if err != nil {
+		t.Fatalf("ExtractFileAtOperation() failed: %v", err)
+	}
+	if string(content) != "first" {
+		t.Errorf("ExtractFileAtOperation() = %q, want %q", content, "first")
+	}
+}
+
+func TestParseReflog(t *testing.T) {
+	output := "abc123 HEAD@{1700000000} commit: second\ndef456 HEAD@{1699990000} commit (initial): first\nbad line\n"
+	ops := parseReflog(output)
+	if len(ops) != 2 {
+		t.Fatalf("parseReflog() returned %d ops, want 2", len(ops))
+	}
+	if ops[0].ID != "abc123" || ops[0].Description != "commit: second" {
+		t.Errorf("unexpected first op: %+v", ops[0])
+	}
+	if !ops[0].Timestamp.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Timestamp = %v, want %v", ops[0].Timestamp, time.Unix(1700000000, 0))
+	}
+}
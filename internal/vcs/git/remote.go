@@ -3,6 +3,7 @@ package git
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -100,12 +101,12 @@ func (g *Git) Pull(ctx context.Context, opts vcs.PullOptions) error {
 	if err != nil {
 		outputStr := string(output)
 
-		// Check for common error types
+		// Check for common error types, keeping git's output for the caller
 		if strings.Contains(outputStr, "non-fast-forward") {
-			return vcs.ErrMergeRequired
+			return fmt.Errorf("%w\n%s", vcs.ErrMergeRequired, outputStr)
 		}
 		if strings.Contains(outputStr, "conflicts") || strings.Contains(outputStr, "CONFLICT") {
-			return vcs.ErrConflicts
+			return fmt.Errorf("%w\n%s", vcs.ErrConflicts, outputStr)
 		}
 
 		return fmt.Errorf("git pull failed: %w\n%s", err, outputStr)
@@ -173,14 +174,17 @@ func (g *Git) Push(ctx context.Context, opts vcs.PushOptions) error {
 
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = g.repoRoot
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		outputStr := string(output)
 
-		// Check for push rejection
+		// Check for push rejection, keeping git's output for the caller
 		if strings.Contains(outputStr, "rejected") || strings.Contains(outputStr, "non-fast-forward") {
-			return vcs.ErrPushRejected
+			return fmt.Errorf("%w\n%s", vcs.ErrPushRejected, outputStr)
 		}
 
 		return fmt.Errorf("git push failed: %w\n%s", err, outputStr)
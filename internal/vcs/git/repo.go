@@ -21,12 +21,13 @@ func (g *Git) detect(path string) error {
 	cmd := exec.Command("git", "rev-parse", "--git-dir", "--git-common-dir", "--show-toplevel")
 	cmd.Dir = absPath
 
-	output, err := cmd.Output()
-	if err != nil {
+	var lines []string
+	if output, err := cmd.Output(); err == nil {
+		lines = strings.Split(strings.TrimSpace(string(output)), "\n")
+	} else if lines = bareRepoInfo(absPath); lines == nil {
 		return vcs.ErrNotInVCS
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	if len(lines) < 3 {
 		return fmt.Errorf("unexpected git rev-parse output: got %d lines, expected 3", len(lines))
 	}
@@ -44,6 +45,7 @@ func (g *Git) detect(path string) error {
 	}
 
 	g.vcsDir = gitDir
+	g.commonDir = filepath.Clean(commonDir)
 	g.repoRoot = normalizeRepoRoot(repoRoot)
 
 	// Detect worktree by comparing git-dir and common-dir
@@ -61,6 +63,24 @@ func (g *Git) detect(path string) error {
 	return nil
 }
 
+// bareRepoInfo returns the git dir, common dir and root of a bare repository,
+// or nil if path isn't in one. --show-toplevel fails in a bare repository,
+// which is its own root.
+func bareRepoInfo(path string) []string {
+	cmd := exec.Command("git", "rev-parse", "--is-bare-repository", "--git-dir", "--git-common-dir")
+	cmd.Dir = path
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 3 || strings.TrimSpace(lines[0]) != "true" {
+		return nil
+	}
+	return []string{lines[1], lines[2], path}
+}
+
 // normalizeRepoRoot normalizes the repository root path
 // Resolves symlinks and canonicalizes case on case-insensitive filesystems
 func normalizeRepoRoot(path string) string {
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RemoteRef returns the remote-tracking branch name, e.g. "origin/main"
+func (g *Git) RemoteRef(remote, name string) string {
+	return remote + "/" + name
+}
+
+// Upstream returns the remote the branch pulls from and pushes to
+// (branch.<name>.remote), or "" if none is configured
+func (g *Git) Upstream(name string) (string, error) {
+	return g.configValue("branch." + name + ".remote")
+}
+
+// configValue reads a git config value, returning "" if it is unset
+func (g *Git) configValue(key string) (string, error) {
+	cmd := exec.Command("git", "config", "--get", key)
+	cmd.Dir = g.repoRoot
+
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means the key is not set
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("git config --get %s failed: %w", key, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// MergeBase returns the best common ancestor of two refs
+func (g *Git) MergeBase(a, b string) (string, error) {
+	cmd := exec.Command("git", "merge-base", a, b)
+	cmd.Dir = g.repoRoot
+
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means the refs have no common ancestor
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("git merge-base failed: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ResetRef points the branch at target: reset --hard when the branch is
+// checked out here, branch -f otherwise
+func (g *Git) ResetRef(ctx context.Context, name, target string) error {
+	current, _ := g.CurrentRef()
+
+	args := []string{"branch", "-f", name, target}
+	if current == name {
+		args = []string{"reset", "--hard", target}
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.repoRoot
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s failed: %w\n%s", args[0], err, string(output))
+	}
+
+	return nil
+}
+
+// Restore checks out the given paths from HEAD. Paths that are not tracked
+// are skipped.
+func (g *Git) Restore(ctx context.Context, paths ...string) error {
+	var tracked []string
+	for _, path := range paths {
+		cmd := exec.CommandContext(ctx, "git", "ls-files", "--error-unmatch", path)
+		cmd.Dir = g.repoRoot
+		if cmd.Run() == nil {
+			tracked = append(tracked, path)
+		}
+	}
+	if len(tracked) == 0 {
+		return nil
+	}
+
+	args := append([]string{"checkout", "HEAD", "--"}, tracked...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.repoRoot
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git checkout failed: %w\n%s", err, string(output))
+	}
+
+	return nil
+}
+
+// RestoreFrom checks out the given paths from ref
+func (g *Git) RestoreFrom(ctx context.Context, ref string, paths ...string) error {
+	args := append([]string{"checkout", ref, "--"}, paths...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.repoRoot
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git checkout %s failed: %w\n%s", ref, err, string(output))
+	}
+
+	return nil
+}
+
+// Log returns `git log --oneline base..ref`
+func (g *Git) Log(base, ref string) ([]string, error) {
+	cmd := exec.Command("git", "log", "--oneline", base+".."+ref)
+	cmd.Dir = g.repoRoot
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w\n%s", err, string(output))
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// Diff returns `git diff base...ref -- paths`
+func (g *Git) Diff(base, ref string, paths ...string) (string, error) {
+	args := append([]string{"diff", base + "..." + ref, "--"}, paths...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = g.repoRoot
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w\n%s", err, string(output))
+	}
+
+	return string(output), nil
+}
+
+// Merge merges ref into the checked-out branch
+func (g *Git) Merge(ctx context.Context, ref, message string) error {
+	cmd := exec.CommandContext(ctx, "git", "merge", ref, "-m", message)
+	cmd.Dir = g.repoRoot
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git merge %s failed: %w\n%s", ref, err, string(output))
+	}
+
+	return nil
+}
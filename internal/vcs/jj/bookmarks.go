@@ -13,42 +13,21 @@ import (
 // In jj, references are called "bookmarks" (similar to git branches).
 // Unlike git, bookmarks are optional - you can work without them.
 
-// CurrentRef returns the current bookmark name.
-// Returns empty string if no bookmark is set (normal in jj).
+// CurrentRef returns the current bookmark name: the nearest bookmark on
+// the working copy's ancestry (usually on @-, since @ is the change being
+// worked on). Returns empty string if no bookmark is set (normal in jj).
 func (j *JJ) CurrentRef() (string, error) {
 	ctx := context.Background()
 
-	// Get the current change info
-	output, err := j.execWithOutput(ctx, "log", "-r", "@", "-n", "1", "--no-graph")
+	bookmarks, err := j.bookmarksAt(ctx, "heads(::@ & bookmarks())")
 	if err != nil {
 		return "", err
 	}
-
-	// Parse bookmarks from the output
-	// Format: @ changeID user@host timestamp commitID [bookmarks]
-	// Bookmarks appear after the @ line if present
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		// Look for bookmark indicators
-		if strings.Contains(line, "bookmark:") || strings.Contains(line, "(") {
-			// Extract bookmark name
-			// This is simplified - real parsing would be more robust
-			parts := strings.Fields(line)
-			for i, part := range parts {
-				if strings.HasPrefix(part, "(") && strings.HasSuffix(part, ")") {
-					// Remove parentheses
-					bookmark := strings.Trim(part, "()")
-					return bookmark, nil
-				}
-				if part == "bookmark:" && i+1 < len(parts) {
-					return parts[i+1], nil
-				}
-			}
-		}
+	if len(bookmarks) == 0 {
+		// No bookmark set - this is normal in jj
+		return "", nil
 	}
-
-	// No bookmark set - this is normal in jj
-	return "", nil
+	return bookmarks[0], nil
 }
 
 // RefExists returns true if the named bookmark exists.
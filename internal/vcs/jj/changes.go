@@ -20,10 +20,11 @@ func (j *JJ) Add(paths []string) error {
 }
 
 // Status returns the status of files in the working directory.
+// These are the changes in the working-copy change (@) relative to its parent.
 func (j *JJ) Status(paths ...string) ([]vcs.FileStatus, error) {
 	ctx := context.Background()
 
-	args := []string{"status"}
+	args := []string{"diff", "--summary"}
 	args = append(args, paths...)
 
 	output, err := j.execWithOutput(ctx, args...)
@@ -34,51 +35,45 @@ func (j *JJ) Status(paths ...string) ([]vcs.FileStatus, error) {
 	return parseStatus(output), nil
 }
 
-// parseStatus parses the output of `jj status`.
+// parseStatus parses the output of `jj diff --summary`.
 // Format:
-//   Working copy changes:
-//   M file1.go
-//   A file2.go
-//   D file3.go
+//
+//	M file1.go
+//	A file2.go
+//	D file3.go
 func parseStatus(output string) []vcs.FileStatus {
 	var statuses []vcs.FileStatus
 	lines := strings.Split(output, "\n")
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		if line == "" || strings.HasSuffix(line, ":") {
-			continue
-		}
 
 		// Parse status line: "M file.go"
-		fields := strings.Fields(line)
-		if len(fields) < 2 {
+		code, path, ok := strings.Cut(line, " ")
+		if !ok || len(code) != 1 {
 			continue
 		}
 
-		statusCode := fields[0]
-		path := strings.Join(fields[1:], " ")
-
 		// Map jj status codes to VCS status codes
-		var code vcs.StatusCode
-		switch statusCode {
+		var status vcs.StatusCode
+		switch code {
 		case "M":
-			code = vcs.StatusModified
+			status = vcs.StatusModified
 		case "A":
-			code = vcs.StatusAdded
+			status = vcs.StatusAdded
 		case "D":
-			code = vcs.StatusDeleted
+			status = vcs.StatusDeleted
 		case "R":
-			code = vcs.StatusRenamed
+			status = vcs.StatusRenamed
 		case "C":
-			code = vcs.StatusCopied
+			status = vcs.StatusCopied
 		default:
-			code = vcs.StatusUnmodified
+			continue
 		}
 
 		statuses = append(statuses, vcs.FileStatus{
-			Path:       path,
-			Status:     code,
+			Path:       strings.TrimSpace(path),
+			Status:     status,
 			StagedCode: vcs.StatusUnmodified, // jj has no staging area
 		})
 	}
@@ -101,11 +96,15 @@ func (j *JJ) HasChanges(paths ...string) (bool, error) {
 
 // Commit creates a commit with the specified options.
 //
-// In jj, this uses `jj describe` to update the current change description,
-// and optionally `jj new` to create a new change if CreateNew is true.
+// In jj, this uses `jj describe` to update the current change description.
+// If CreateNew is true it uses `jj commit` instead, which describes the
+// change (only opts.Paths, if given) and starts a new one on top. Bookmarks
+// on the parent then advance to the new commit, the way a git branch
+// advances on commit.
 func (j *JJ) Commit(ctx context.Context, opts vcs.CommitOptions) error {
-	// In jj, changes are automatically tracked, so we just need to describe them
-	args := []string{"describe", "-m", opts.Message}
+	if opts.Message == "" {
+		return fmt.Errorf("commit message is required")
+	}
 
 	// Handle author override
 	if opts.Author != "" {
@@ -114,17 +113,26 @@ func (j *JJ) Commit(ctx context.Context, opts vcs.CommitOptions) error {
 		// For now, we'll skip this feature
 	}
 
-	// Execute describe command
-	_, err := j.Exec(ctx, args...)
+	if !opts.CreateNew {
+		// In jj, changes are automatically tracked, so we just need to describe them
+		_, err := j.Exec(ctx, "describe", "-m", opts.Message)
+		return err
+	}
+
+	bookmarks, err := j.bookmarksAt(ctx, "@-")
 	if err != nil {
 		return err
 	}
 
-	// If CreateNew is true, create a new change after describing
-	if opts.CreateNew {
-		_, err = j.Exec(ctx, "new")
-		if err != nil {
-			return fmt.Errorf("failed to create new change: %w", err)
+	args := []string{"commit", "-m", opts.Message}
+	args = append(args, opts.Paths...)
+	if _, err := j.Exec(ctx, args...); err != nil {
+		return err
+	}
+
+	for _, bookmark := range bookmarks {
+		if _, err := j.Exec(ctx, "bookmark", "set", bookmark, "-r", "@-"); err != nil {
+			return fmt.Errorf("failed to advance bookmark %s: %w", bookmark, err)
 		}
 	}
 
@@ -136,21 +144,17 @@ func (j *JJ) Commit(ctx context.Context, opts vcs.CommitOptions) error {
 func (j *JJ) GetCommitHash(ref string) (string, error) {
 	ctx := context.Background()
 
-	// Use log to get the commit ID for a revision
-	output, err := j.execWithOutput(ctx, "log", "-r", ref, "-n", "1", "--no-graph")
+	// Use log to get the full commit ID for a revision
+	output, err := j.execWithOutput(ctx, "log", "-r", ref, "-n", "1", "--no-graph", "-T", "commit_id")
 	if err != nil {
 		return "", err
 	}
 
-	// Parse the commit ID from output
-	// Format: @ changeID user@host timestamp commitID
-	fields := strings.Fields(output)
-	if len(fields) >= 5 {
-		// Commit ID is typically the 5th field
-		return fields[4], nil
+	commitID := strings.TrimSpace(output)
+	if commitID == "" {
+		return "", fmt.Errorf("could not parse commit ID from output")
 	}
-
-	return "", fmt.Errorf("could not parse commit ID from output")
+	return commitID, nil
 }
 
 // ===================
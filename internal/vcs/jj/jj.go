@@ -16,6 +16,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/steveyegge/beads/internal/vcs"
 )
@@ -130,6 +131,27 @@ func (j *JJ) VCSDir() (string, error) {
 	return j.jjDir, nil
 }
 
+// CommonDir returns the .jj directory that holds the repo store.
+// A secondary workspace's .jj/repo is a file pointing at the store of the
+// workspace it was added from.
+func (j *JJ) CommonDir() (string, error) {
+	repoPath := filepath.Join(j.jjDir, "repo")
+	info, err := os.Stat(repoPath)
+	if err != nil || info.IsDir() {
+		return j.jjDir, nil
+	}
+
+	content, err := os.ReadFile(repoPath) // #nosec G304 - path is inside the .jj directory
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", repoPath, err)
+	}
+	storePath := strings.TrimSpace(string(content))
+	if !filepath.IsAbs(storePath) {
+		storePath = filepath.Join(j.jjDir, storePath)
+	}
+	return filepath.Dir(filepath.Clean(storePath)), nil
+}
+
 // IsInVCS returns true if we're inside a jj repository.
 func (j *JJ) IsInVCS() bool {
 	return j.jjDir != ""
@@ -142,6 +164,18 @@ func (j *JJ) IsInVCS() bool {
 // Exec executes a raw jj command.
 // This is the internal command runner used by all other methods.
 func (j *JJ) Exec(ctx context.Context, args ...string) ([]byte, error) {
+	output, err := j.exec(ctx, args...)
+	if err != nil && strings.Contains(err.Error(), "working copy is stale") {
+		// Another workspace rewrote this one's working-copy commit (e.g. the
+		// sync workspace moved a shared bookmark); catch up and retry.
+		if _, updateErr := j.exec(ctx, "workspace", "update-stale"); updateErr == nil {
+			return j.exec(ctx, args...)
+		}
+	}
+	return output, err
+}
+
+func (j *JJ) exec(ctx context.Context, args ...string) ([]byte, error) {
 	cmd := exec.CommandContext(ctx, "jj", args...)
 	cmd.Dir = j.repoRoot
 
@@ -195,11 +229,17 @@ func (j *JJ) Undo(ctx context.Context) error {
 	return err
 }
 
-// GetOperationLog returns recent VCS operations from jj's operation log.
+// opLogTemplate renders one operation per line as tab-separated fields:
+// id, end time, user, first line of the description, first line of the tags
+// (which holds "args: jj ..." for operations started from the CLI).
+const opLogTemplate = `self.id() ++ "\t" ++ self.time().end().format("%Y-%m-%dT%H:%M:%S%:z") ++ "\t" ++ self.user() ++ "\t" ++ self.description().first_line() ++ "\t" ++ self.tags().first_line() ++ "\n"`
+
+// GetOperationLog returns recent VCS operations from jj's operation log,
+// newest first.
 func (j *JJ) GetOperationLog(limit int) ([]vcs.OperationInfo, error) {
 	ctx := context.Background()
 
-	args := []string{"op", "log"}
+	args := []string{"op", "log", "--no-graph", "-T", opLogTemplate}
 	if limit > 0 {
 		args = append(args, "-n", fmt.Sprintf("%d", limit))
 	}
@@ -209,29 +249,41 @@ func (j *JJ) GetOperationLog(limit int) ([]vcs.OperationInfo, error) {
 		return nil, err
 	}
 
-	// Parse operation log output
-	// Format is complex, so this is a basic implementation
-	// Real implementation would need more robust parsing
 	return parseOperationLog(output), nil
 }
 
-// parseOperationLog parses the output of `jj op log`.
-// This is a simplified parser - real implementation would be more robust.
+// ExtractFileAtOperation returns a file's content in the working-copy commit
+// as recorded by the given operation.
+func (j *JJ) ExtractFileAtOperation(opID, path string) ([]byte, error) {
+	return j.Exec(context.Background(), "--at-op", opID, "--ignore-working-copy", "file", "show", "-r", "@", path)
+}
+
+// parseOperationLog parses `jj op log` output rendered with opLogTemplate.
 func parseOperationLog(output string) []vcs.OperationInfo {
 	var ops []vcs.OperationInfo
-	lines := strings.Split(output, "\n")
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
 			continue
 		}
 
-		// Very basic parsing - just capture the description
-		// Real implementation would parse timestamp, user, etc.
-		ops = append(ops, vcs.OperationInfo{
-			Description: line,
-		})
+		fields := strings.SplitN(line, "\t", 5)
+		for len(fields) < 5 {
+			fields = append(fields, "")
+		}
+
+		op := vcs.OperationInfo{
+			ID:          strings.TrimSpace(fields[0]),
+			User:        fields[2],
+			Description: fields[3],
+		}
+		if ts, err := time.Parse(time.RFC3339, fields[1]); err == nil {
+			op.Timestamp = ts
+		}
+		if args, ok := strings.CutPrefix(strings.TrimSpace(fields[4]), "args: "); ok {
+			op.Args = strings.Fields(args)
+		}
+		ops = append(ops, op)
 	}
 
 	return ops
@@ -273,8 +273,9 @@ func TestWorkspace(t *testing.T) {
 	}
 
 	// Verify workspace path
-	if ws.Path() != tmpDir {
-		t.Errorf("Expected workspace path %s, got %s", tmpDir, ws.Path())
+	expectedPath := filepath.Join(tmpDir, ".jj", "beads-workspaces", wsOpts.Name)
+	if ws.Path() != expectedPath {
+		t.Errorf("Expected workspace path %s, got %s", expectedPath, ws.Path())
 	}
 
 	// Verify workspace ref
@@ -387,3 +388,29 @@ func TestIsColocated(t *testing.T) {
 		t.Error("Colocated repo should be detected as colocated")
 	}
 }
+
+// TestParseOperationLog verifies parsing of the templated op log.
+func TestParseOperationLog(t *testing.T) {
+	output := "abc123\t2026-01-02T03:04:05+00:00\talice@host\tcreate bookmark agent-47 pointing to commit 1234\targs: jj bookmark create agent-47\n" +
+		"def456\t2026-01-02T03:00:00+00:00\talice@host\tsnapshot working copy\t\n"
+
+	ops := parseOperationLog(output)
+	if len(ops) != 2 {
+		t.Fatalf("Expected 2 operations, got %d", len(ops))
+	}
+	if ops[0].ID != "abc123" {
+		t.Errorf("Expected ID abc123, got %s", ops[0].ID)
+	}
+	if ops[0].Timestamp.IsZero() {
+		t.Error("Expected timestamp to be parsed")
+	}
+	if ops[0].User != "alice@host" {
+		t.Errorf("Expected user alice@host, got %s", ops[0].User)
+	}
+	if len(ops[0].Args) != 4 || ops[0].Args[3] != "agent-47" {
+		t.Errorf("Expected args [jj bookmark create agent-47], got %v", ops[0].Args)
+	}
+	if ops[1].Description != "snapshot working copy" || len(ops[1].Args) != 0 {
+		t.Errorf("Unexpected second operation: %+v", ops[1])
+	}
+}
@@ -22,26 +22,16 @@ func (j *JJ) HasRemote() bool {
 }
 
 // GetRemotes returns information about configured remotes.
-// For jj, this queries the git remotes (since jj uses git as backend).
+// jj stores remotes in its git backend; `jj git remote list` works for
+// both colocated and non-colocated repos.
 func (j *JJ) GetRemotes() ([]vcs.RemoteInfo, error) {
 	ctx := context.Background()
 
-	// jj doesn't have a direct "remote list" command
-	// In colocated mode, we can use git config
-	// For non-colocated, we need to query the git backend
-	if j.isColocated {
-		// Use git to get remotes
-		output, err := j.execGit(ctx, "remote", "-v")
-		if err != nil {
-			return []vcs.RemoteInfo{}, nil
-		}
-		return parseGitRemotes(output), nil
+	output, err := j.execWithOutput(ctx, "git", "remote", "list")
+	if err != nil {
+		return []vcs.RemoteInfo{}, nil
 	}
-
-	// For non-colocated repos, remotes are stored in .jj/repo/store/git/config
-	// This is more complex - for now return empty
-	// TODO: Parse git config from .jj/repo/store/git/config
-	return []vcs.RemoteInfo{}, nil
+	return parseGitRemotes(output), nil
 }
 
 // execGit executes a git command (for colocated repos).
@@ -57,8 +47,9 @@ func (j *JJ) execGit(ctx context.Context, args ...string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// parseGitRemotes parses the output of `git remote -v`.
-// Format: "origin  https://github.com/user/repo.git (fetch)"
+// parseGitRemotes parses the output of `git remote -v` or `jj git remote list`.
+// Format: "origin  https://github.com/user/repo.git (fetch)" or
+// "origin https://github.com/user/repo.git"
 func parseGitRemotes(output string) []vcs.RemoteInfo {
 	var remotes []vcs.RemoteInfo
 	seen := make(map[string]bool)
@@ -106,14 +97,75 @@ func (j *JJ) Fetch(ctx context.Context, remote, ref string) error {
 }
 
 // Pull pulls changes from the remote.
-// In jj, this is equivalent to fetch (no auto-merge).
+// jj has no pull: this fetches, then rebases the local bookmark's commits
+// (and the working copy) onto the remote bookmark. With FFOnly, it only
+// moves the bookmark if it hasn't diverged.
 func (j *JJ) Pull(ctx context.Context, opts vcs.PullOptions) error {
-	// jj doesn't have a pull command - use fetch
-	return j.Fetch(ctx, opts.Remote, opts.Ref)
+	if !j.HasRemote() {
+		return nil
+	}
+
+	remote := opts.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+
+	if err := j.Fetch(ctx, remote, opts.Ref); err != nil {
+		return err
+	}
+
+	ref := opts.Ref
+	if ref == "" {
+		var err error
+		if ref, err = j.CurrentRef(); err != nil {
+			return err
+		}
+		if ref == "" {
+			// Nothing to update without a bookmark
+			return nil
+		}
+	}
+
+	remoteRef := j.RemoteRef(remote, ref)
+	if !j.hasRevisions(ctx, remoteRef) {
+		// The remote doesn't have the bookmark yet
+		return nil
+	}
+
+	if opts.FFOnly {
+		if j.hasRevisions(ctx, fmt.Sprintf("(%s)..(%s)", remoteRef, ref)) {
+			return fmt.Errorf("%w: %s has diverged from %s", vcs.ErrMergeRequired, ref, remoteRef)
+		}
+		onBookmark := j.hasRevisions(ctx, fmt.Sprintf("@- & (%s)", ref))
+		if _, err := j.Exec(ctx, "bookmark", "set", ref, "-r", remoteRef); err != nil {
+			return err
+		}
+		if onBookmark {
+			_, err := j.Exec(ctx, "rebase", "-r", "@", "-d", ref)
+			return err
+		}
+		return nil
+	}
+
+	// Bookmarks follow their commits when rebased
+	if _, err := j.Exec(ctx, "rebase", "-b", ref, "-d", remoteRef); err != nil {
+		return err
+	}
+	if j.hasRevisions(ctx, fmt.Sprintf("conflicts() & ::(%s)", ref)) {
+		return fmt.Errorf("%w: rebasing %s onto %s", vcs.ErrConflicts, ref, remoteRef)
+	}
+
+	return nil
 }
 
 // Push pushes changes to the remote.
+// SetUpstream allows pushing a bookmark the remote doesn't have yet.
+// Env is ignored; jj runs no push hooks.
 func (j *JJ) Push(ctx context.Context, opts vcs.PushOptions) error {
+	if !j.HasRemote() {
+		return nil
+	}
+
 	args := []string{"git", "push"}
 
 	if opts.Remote != "" {
@@ -124,17 +176,21 @@ func (j *JJ) Push(ctx context.Context, opts vcs.PushOptions) error {
 		args = append(args, "-b", opts.Ref)
 	}
 
+	if opts.SetUpstream {
+		args = append(args, "--allow-new")
+	}
+
 	if opts.Force {
 		// jj git push doesn't have a force flag
 		// Would need to use --allow-backwards or similar
 	}
 
-	_, err := j.Exec(ctx, args...)
-	if err != nil {
+	if _, err := j.Exec(ctx, args...); err != nil {
 		// Check if push was rejected
 		if strings.Contains(err.Error(), "rejected") ||
-			strings.Contains(err.Error(), "non-fast-forward") {
-			return vcs.ErrPushRejected
+			strings.Contains(err.Error(), "non-fast-forward") ||
+			strings.Contains(err.Error(), "unexpectedly moved") {
+			return fmt.Errorf("%w\n%s", vcs.ErrPushRejected, err.Error())
 		}
 		return err
 	}
@@ -150,28 +206,17 @@ func (j *JJ) Push(ctx context.Context, opts vcs.PushOptions) error {
 func (j *JJ) HasDivergence(local, remote string) (vcs.DivergenceInfo, error) {
 	ctx := context.Background()
 
-	// Use jj log to check divergence
-	// This is a simplified implementation
-	// Real implementation would need to count commits ahead/behind
-
-	// Get commits in local but not in remote
-	aheadOutput, err := j.execWithOutput(ctx, "log", "-r",
-		fmt.Sprintf("%s..%s", remote, local), "-n", "100")
+	// Count commits in local but not in remote, and vice versa
+	aheadCount, err := j.countRevisions(ctx, fmt.Sprintf("(%s)..(%s)", remote, local))
 	if err != nil {
 		return vcs.DivergenceInfo{}, err
 	}
 
-	// Get commits in remote but not in local
-	behindOutput, err := j.execWithOutput(ctx, "log", "-r",
-		fmt.Sprintf("%s..%s", local, remote), "-n", "100")
+	behindCount, err := j.countRevisions(ctx, fmt.Sprintf("(%s)..(%s)", local, remote))
 	if err != nil {
 		return vcs.DivergenceInfo{}, err
 	}
 
-	// Count commits (simplified - count non-empty lines)
-	aheadCount := countCommits(aheadOutput)
-	behindCount := countCommits(behindOutput)
-
 	info := vcs.DivergenceInfo{
 		LocalAhead:  aheadCount,
 		RemoteAhead: behindCount,
@@ -184,31 +229,24 @@ func (j *JJ) HasDivergence(local, remote string) (vcs.DivergenceInfo, error) {
 	return info, nil
 }
 
-// countCommits counts the number of commits in log output.
-func countCommits(output string) int {
-	if strings.TrimSpace(output) == "" {
-		return 0
+// countRevisions counts the commits in a revset.
+func (j *JJ) countRevisions(ctx context.Context, revset string) (int, error) {
+	output, err := j.execWithOutput(ctx, "log", "--no-graph", "-r", revset, "-T", `"x\n"`)
+	if err != nil {
+		return 0, err
 	}
-
-	count := 0
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		// Lines starting with @ or ○ indicate a commit
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "@") || strings.HasPrefix(line, "○") ||
-			strings.HasPrefix(line, "◆") {
-			count++
-		}
+	if output == "" {
+		return 0, nil
 	}
-	return count
+	return strings.Count(output, "\n") + 1, nil
 }
 
 // ExtractFileFromRef extracts a file's content from a specific ref.
 func (j *JJ) ExtractFileFromRef(ref, path string) ([]byte, error) {
 	ctx := context.Background()
 
-	// Use jj cat to get file content at a specific revision
-	output, err := j.Exec(ctx, "cat", "-r", ref, path)
+	// Use jj file show to get file content at a specific revision
+	output, err := j.Exec(ctx, "file", "show", "-r", ref, path)
 	if err != nil {
 		return nil, err
 	}
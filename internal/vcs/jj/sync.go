@@ -0,0 +1,193 @@
+package jj
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ===================
+// Sync Operations
+// ===================
+
+// RemoteRef returns the remote bookmark name, e.g. "main@origin".
+func (j *JJ) RemoteRef(remote, name string) string {
+	return name + "@" + remote
+}
+
+// Upstream returns the remote the bookmark tracks.
+// jj's internal "git" remote (colocated repos) doesn't count.
+func (j *JJ) Upstream(name string) (string, error) {
+	ctx := context.Background()
+
+	output, err := j.execWithOutput(ctx, "bookmark", "list", "--tracked", name,
+		"-T", `if(remote, remote ++ "\n")`)
+	if err != nil {
+		return "", err
+	}
+
+	for _, remote := range strings.Split(output, "\n") {
+		remote = strings.TrimSpace(remote)
+		if remote != "" && remote != "git" {
+			return remote, nil
+		}
+	}
+	return "", nil
+}
+
+// MergeBase returns the commit ID of the best common ancestor of two revisions.
+func (j *JJ) MergeBase(a, b string) (string, error) {
+	ctx := context.Background()
+
+	revset := fmt.Sprintf("heads(::(%s) & ::(%s))", a, b)
+	output, err := j.execWithOutput(ctx, "log", "--no-graph", "-r", revset,
+		"-T", `commit_id ++ "\n"`)
+	if err != nil {
+		return "", err
+	}
+
+	// root() is a common ancestor of everything; treat it as none
+	base, _, _ := strings.Cut(output, "\n")
+	if base == "" || strings.Trim(base, "0") == "" {
+		return "", nil
+	}
+	return base, nil
+}
+
+// ResetRef moves the bookmark to target, backwards if need be. If the
+// working copy is on the bookmark (its parent is the bookmarked commit),
+// the working copy's changes are discarded and it is moved onto target.
+func (j *JJ) ResetRef(ctx context.Context, name, target string) error {
+	onBookmark := j.hasRevisions(ctx, fmt.Sprintf("@- & (%s)", name))
+
+	if onBookmark {
+		if _, err := j.Exec(ctx, "restore"); err != nil {
+			return fmt.Errorf("failed to discard working copy changes: %w", err)
+		}
+	}
+
+	if _, err := j.Exec(ctx, "bookmark", "set", name, "-r", target, "--allow-backwards"); err != nil {
+		return err
+	}
+
+	if onBookmark {
+		if _, err := j.Exec(ctx, "rebase", "-r", "@", "-d", name); err != nil {
+			return fmt.Errorf("failed to move working copy to %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Restore restores paths from the working copy's parent. Paths the parent
+// doesn't have are skipped, so new files are kept.
+func (j *JJ) Restore(ctx context.Context, paths ...string) error {
+	var existing []string
+	for _, path := range paths {
+		output, err := j.execWithOutput(ctx, "file", "list", "-r", "@-", path)
+		if err == nil && output != "" {
+			existing = append(existing, path)
+		}
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+
+	args := append([]string{"restore", "--from", "@-"}, existing...)
+	_, err := j.Exec(ctx, args...)
+	return err
+}
+
+// RestoreFrom restores paths in the working copy from ref.
+func (j *JJ) RestoreFrom(ctx context.Context, ref string, paths ...string) error {
+	args := append([]string{"restore", "--from", ref}, paths...)
+	_, err := j.Exec(ctx, args...)
+	return err
+}
+
+// Log returns the commits in base..ref, newest first.
+func (j *JJ) Log(base, ref string) ([]string, error) {
+	ctx := context.Background()
+
+	output, err := j.execWithOutput(ctx, "log", "--no-graph", "-r", fmt.Sprintf("(%s)..(%s)", base, ref),
+		"-T", `commit_id.short() ++ " " ++ description.first_line() ++ "\n"`)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// Diff returns the git-format diff of paths between the common ancestor of
+// base and ref, and ref.
+func (j *JJ) Diff(base, ref string, paths ...string) (string, error) {
+	ctx := context.Background()
+
+	args := []string{"diff", "--git",
+		"--from", fmt.Sprintf("heads(::(%s) & ::(%s))", base, ref),
+		"--to", ref}
+	args = append(args, paths...)
+	return j.execWithOutput(ctx, args...)
+}
+
+// Merge creates a merge commit of the current bookmark and ref, moves the
+// bookmark to it and starts a new working-copy change on top. If the
+// bookmark is an ancestor of ref it is fast-forwarded instead.
+func (j *JJ) Merge(ctx context.Context, ref, message string) error {
+	current, err := j.CurrentRef()
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		return fmt.Errorf("no bookmark on the working copy to merge into")
+	}
+
+	if j.hasRevisions(ctx, fmt.Sprintf("(%s) & ::(%s)", current, ref)) {
+		if _, err := j.Exec(ctx, "bookmark", "set", current, "-r", ref); err != nil {
+			return err
+		}
+	} else {
+		if _, err := j.Exec(ctx, "new", current, ref, "-m", message); err != nil {
+			return fmt.Errorf("failed to create merge commit: %w", err)
+		}
+		if conflicted, err := j.HasConflicts(); err == nil && conflicted {
+			return fmt.Errorf("merge of %s into %s has conflicts; resolve them in the working copy", ref, current)
+		}
+		if _, err := j.Exec(ctx, "bookmark", "set", current, "-r", "@"); err != nil {
+			return err
+		}
+	}
+
+	_, err = j.Exec(ctx, "new", current)
+	return err
+}
+
+// hasRevisions returns true if the revset is non-empty.
+func (j *JJ) hasRevisions(ctx context.Context, revset string) bool {
+	output, err := j.execWithOutput(ctx, "log", "--no-graph", "-r", revset,
+		"-T", `commit_id ++ "\n"`)
+	return err == nil && output != ""
+}
+
+// bookmarksAt returns the local bookmarks pointing at a revision.
+func (j *JJ) bookmarksAt(ctx context.Context, rev string) ([]string, error) {
+	output, err := j.execWithOutput(ctx, "log", "--no-graph", "-r", rev,
+		"-T", `local_bookmarks.map(|b| b.name() ++ "\n").join("")`)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range strings.Split(output, "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -7,7 +7,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/steveyegge/beads/internal/vcs"
 )
@@ -15,129 +14,162 @@ import (
 // ===================
 // Workspace Operations
 // ===================
-// In jj, workspaces are implemented using the change model rather than
-// separate working directories (like git worktrees). We create a temporary
-// change for sync operations without affecting the user's working directory.
+// In jj, sync workspaces are jj workspaces: separate working copies of the
+// same repo (like git worktrees). The workspace's working-copy change sits
+// on top of the sync bookmark; committing moves the bookmark forward, so the
+// user's own working copy is never touched.
 
-// jjWorkspace implements the Workspace interface using jj's change model.
+// jjWorkspace implements the Workspace interface using a jj workspace.
 type jjWorkspace struct {
-	jj       *JJ
-	ref      string // Bookmark for this workspace
-	changeID string // The change ID for this workspace
-	name     string // Workspace identifier
+	jj   *JJ    // The main repo
+	ws   *JJ    // The workspace's working copy
+	name string // Workspace name (as in `jj workspace list`)
+	ref  string // Bookmark for this workspace
 }
 
 // CreateWorkspace creates an isolated workspace for sync operations.
 //
-// Unlike git worktrees which create separate directories, jj workspaces
-// use the change model:
-//  1. Create a new change with a descriptive message
-//  2. Create a bookmark pointing to this change
-//  3. Return workspace that manages this change
+//  1. Create the bookmark if needed, tracking the remote bookmark when the
+//     remote has one, otherwise at the current change's parent
+//  2. Add a jj workspace whose working copy sits on the bookmark
 //
-// The workspace allows operations without affecting the user's current work.
+// An existing healthy workspace is reused.
 func (j *JJ) CreateWorkspace(opts vcs.WorkspaceOptions) (vcs.Workspace, error) {
 	ctx := context.Background()
 
-	// Check if bookmark already exists
-	if j.RefExists(opts.Ref) {
-		return nil, vcs.ErrWorkspaceExists
+	if opts.Name == "" {
+		return nil, fmt.Errorf("workspace name is required")
 	}
 
-	// Get current change ID to return to it later
-	currentChange, err := j.getCurrentChangeID()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current change: %w", err)
+	if opts.Ref == "" {
+		return nil, fmt.Errorf("workspace ref is required")
 	}
 
-	// Create a new change for the workspace
-	syncMsg := fmt.Sprintf("Sync: %s", time.Now().Format(time.RFC3339))
-	if err := j.Commit(ctx, vcs.CommitOptions{
-		Message:   syncMsg,
-		CreateNew: true,
-	}); err != nil {
-		return nil, fmt.Errorf("failed to create workspace change: %w", err)
+	// Default path if not specified
+	path := opts.Path
+	if path == "" {
+		path = filepath.Join(j.jjDir, "beads-workspaces", opts.Name)
 	}
 
-	// Get the new change ID
-	newChangeID, err := j.getCurrentChangeID()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get new change ID: %w", err)
+	// Reuse the workspace if it already exists
+	if _, err := os.Stat(filepath.Join(path, ".jj")); err == nil {
+		if ws, err := New(path); err == nil {
+			w := &jjWorkspace{jj: j, ws: ws, name: opts.Name, ref: opts.Ref}
+			if err := w.IsHealthy(); err == nil {
+				return w, nil
+			}
+		}
+		// Unhealthy, remove and recreate
+		_, _ = j.Exec(ctx, "workspace", "forget", opts.Name)
+		if err := os.RemoveAll(path); err != nil {
+			return nil, fmt.Errorf("failed to remove unhealthy workspace: %w", err)
+		}
+	} else if j.workspaceExists(opts.Name) {
+		// Registered but its directory is gone
+		_, _ = j.Exec(ctx, "workspace", "forget", opts.Name)
 	}
 
-	// Create bookmark at this change
-	if err := j.CreateRef(opts.Ref, "@"); err != nil {
-		// Try to return to original change
-		_ = j.editChange(ctx, currentChange)
-		return nil, fmt.Errorf("failed to create workspace bookmark: %w", err)
+	// Create the bookmark if it doesn't exist
+	if !j.RefExists(opts.Ref) {
+		if err := j.createWorkspaceRef(ctx, opts.Ref); err != nil {
+			return nil, fmt.Errorf("failed to create workspace bookmark: %w", err)
+		}
 	}
 
-	// Return to original change (user's work is undisturbed)
-	if err := j.editChange(ctx, currentChange); err != nil {
-		return nil, fmt.Errorf("failed to return to original change: %w", err)
+	// Ensure parent directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create parent directory: %w", err)
 	}
 
-	return &jjWorkspace{
-		jj:       j,
-		ref:      opts.Ref,
-		changeID: newChangeID,
-		name:     opts.Name,
-	}, nil
-}
-
-// getCurrentChangeID gets the current change ID.
-func (j *JJ) getCurrentChangeID() (string, error) {
-	ctx := context.Background()
+	if _, err := j.Exec(ctx, "workspace", "add", "--name", opts.Name, "-r", opts.Ref, path); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
 
-	output, err := j.execWithOutput(ctx, "log", "-r", "@", "-n", "1", "--no-graph")
+	ws, err := New(path)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to open workspace: %w", err)
 	}
 
-	// Parse change ID from output
-	// Format: changeID user@host timestamp [bookmark] commitID
-	// Example: szvoputy setup@jj-dev.local 2026-01-08 00:09:30 test-bookmark e89402e0
-	fields := strings.Fields(output)
-	if len(fields) >= 1 {
-		return fields[0], nil
+	return &jjWorkspace{jj: j, ws: ws, name: opts.Name, ref: opts.Ref}, nil
+}
+
+// createWorkspaceRef creates a workspace bookmark, tracking the remote
+// bookmark of the same name if any remote has one.
+func (j *JJ) createWorkspaceRef(ctx context.Context, name string) error {
+	remotes, _ := j.GetRemotes()
+	for _, remote := range remotes {
+		remoteRef := j.RemoteRef(remote.Name, name)
+		if !j.hasRevisions(ctx, remoteRef) {
+			continue
+		}
+		if _, err := j.Exec(ctx, "bookmark", "track", remoteRef); err == nil {
+			return nil
+		}
 	}
+	return j.CreateRef(name, "@-")
+}
 
-	return "", fmt.Errorf("could not parse change ID")
+// workspaceExists returns true if jj knows a workspace with this name.
+func (j *JJ) workspaceExists(name string) bool {
+	names, err := j.workspaceNames()
+	if err != nil {
+		return false
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
 }
 
-// editChange moves to a different change.
-func (j *JJ) editChange(ctx context.Context, changeID string) error {
-	_, err := j.Exec(ctx, "edit", changeID)
-	return err
+// workspaceNames parses `jj workspace list`.
+// Format: "default: kxqpqtlm 3a6c2b1e (no description set)"
+func (j *JJ) workspaceNames() ([]string, error) {
+	output, err := j.execWithOutput(context.Background(), "workspace", "list")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		if name, _, ok := strings.Cut(line, ":"); ok && name != "" {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	return names, nil
 }
 
 // ListWorkspaces returns information about existing workspaces.
-// For jj, this lists bookmarks that match workspace naming pattern.
+// The default workspace (the user's working copy) is not included.
 func (j *JJ) ListWorkspaces() ([]vcs.WorkspaceInfo, error) {
-	refs, err := j.ListRefs()
+	ctx := context.Background()
+
+	names, err := j.workspaceNames()
 	if err != nil {
 		return nil, err
 	}
 
 	var workspaces []vcs.WorkspaceInfo
-
-	// Filter for workspace bookmarks (typically named "beads-sync" or similar)
-	for _, ref := range refs {
-		// Only include local bookmarks
-		if ref.IsRemote {
+	for _, name := range names {
+		if name == "default" {
 			continue
 		}
 
-		// Check if this looks like a workspace bookmark
-		if strings.Contains(ref.Name, "sync") ||
-			strings.Contains(ref.Name, "workspace") {
-			workspaces = append(workspaces, vcs.WorkspaceInfo{
-				Name:    ref.Name,
-				Path:    j.repoRoot, // jj workspaces work in-place
-				Ref:     ref.Name,
-				IsValid: true,
-			})
+		info := vcs.WorkspaceInfo{Name: name}
+
+		// Workspaces created elsewhere don't have a known path
+		path := filepath.Join(j.jjDir, "beads-workspaces", name)
+		if _, err := os.Stat(filepath.Join(path, ".jj")); err == nil {
+			info.Path = path
+			info.IsValid = true
+		}
+
+		if bookmarks, err := j.bookmarksAt(ctx, name+"@-"); err == nil && len(bookmarks) > 0 {
+			info.Ref = bookmarks[0]
 		}
+
+		workspaces = append(workspaces, info)
 	}
 
 	return workspaces, nil
@@ -148,9 +180,8 @@ func (j *JJ) ListWorkspaces() ([]vcs.WorkspaceInfo, error) {
 // ===================
 
 // Path returns the filesystem path to the workspace.
-// For jj, this is always the repository root (changes work in-place).
 func (w *jjWorkspace) Path() string {
-	return w.jj.repoRoot
+	return w.ws.repoRoot
 }
 
 // Ref returns the reference (bookmark) this workspace is based on.
@@ -159,9 +190,8 @@ func (w *jjWorkspace) Ref() string {
 }
 
 // SyncToWorkspace copies a file from the main repo to the workspace.
-// For jj, this is a simple file copy since we work in-place.
 func (w *jjWorkspace) SyncToWorkspace(srcPath, dstRelPath string) error {
-	dstPath := filepath.Join(w.jj.repoRoot, dstRelPath)
+	dstPath := filepath.Join(w.ws.repoRoot, dstRelPath)
 
 	// Ensure destination directory exists
 	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
@@ -173,106 +203,55 @@ func (w *jjWorkspace) SyncToWorkspace(srcPath, dstRelPath string) error {
 }
 
 // SyncFromWorkspace copies a file from the workspace to the main repo.
-// For jj, this is a simple file copy since we work in-place.
 func (w *jjWorkspace) SyncFromWorkspace(srcRelPath, dstPath string) error {
-	srcPath := filepath.Join(w.jj.repoRoot, srcRelPath)
+	srcPath := filepath.Join(w.ws.repoRoot, srcRelPath)
 	return copyFile(srcPath, dstPath)
 }
 
 // HasChanges returns true if there are uncommitted changes in the workspace.
 func (w *jjWorkspace) HasChanges(paths ...string) (bool, error) {
-	ctx := context.Background()
-
-	// Switch to workspace change
-	currentChange, err := w.jj.getCurrentChangeID()
-	if err != nil {
-		return false, err
-	}
-	defer func() { _ = w.jj.editChange(ctx, currentChange) }()
-
-	if err := w.jj.editChange(ctx, w.changeID); err != nil {
-		return false, err
-	}
-
-	// Check for changes
-	return w.jj.HasChanges(paths...)
+	return w.ws.HasChanges(paths...)
 }
 
 // Commit commits changes in this workspace with the given message.
+// The bookmark advances to the new commit.
 func (w *jjWorkspace) Commit(ctx context.Context, message string, paths []string) error {
-	// Switch to workspace change
-	currentChange, err := w.jj.getCurrentChangeID()
-	if err != nil {
-		return err
-	}
-	defer func() { _ = w.jj.editChange(ctx, currentChange) }()
-
-	if err := w.jj.editChange(ctx, w.changeID); err != nil {
-		return err
-	}
-
-	// Commit changes
-	return w.jj.Commit(ctx, vcs.CommitOptions{
+	return w.ws.Commit(ctx, vcs.CommitOptions{
 		Message:   message,
 		Paths:     paths,
-		CreateNew: false, // Don't create new change - update current
+		CreateNew: true,
 	})
 }
 
 // Push pushes the workspace's reference to the remote.
 func (w *jjWorkspace) Push(ctx context.Context, remote string) error {
 	// Push the workspace bookmark
-	return w.jj.Push(ctx, vcs.PushOptions{
-		Remote: remote,
-		Ref:    w.ref,
+	return w.ws.Push(ctx, vcs.PushOptions{
+		Remote:      remote,
+		Ref:         w.ref,
+		SetUpstream: true,
 	})
 }
 
 // Pull pulls changes from the remote into the workspace.
 func (w *jjWorkspace) Pull(ctx context.Context, remote string) error {
-	// Fetch changes
-	if err := w.jj.Fetch(ctx, remote, w.ref); err != nil {
-		return err
-	}
-
-	// Update workspace bookmark to track remote
-	remoteName := remote
-	if remoteName == "" {
-		remoteName = "origin"
-	}
-
-	remoteRef := fmt.Sprintf("%s/%s", remoteName, w.ref)
-	if err := w.jj.MoveRef(w.ref, remoteRef); err != nil {
-		return err
-	}
-
-	return nil
+	return w.ws.Pull(ctx, vcs.PullOptions{
+		Remote: remote,
+		Ref:    w.ref,
+	})
 }
 
-// Cleanup removes the workspace and cleans up resources.
+// Cleanup forgets the workspace and removes its directory.
+// The bookmark and its commits are kept.
 func (w *jjWorkspace) Cleanup() error {
 	ctx := context.Background()
 
-	// Check if workspace has uncommitted changes
-	hasChanges, err := w.HasChanges()
-	if err != nil {
-		return err
-	}
-
-	// If workspace is empty, abandon the change
-	if !hasChanges {
-		// Switch to a different change first
-		currentChange, err := w.jj.getCurrentChangeID()
-		if err == nil && currentChange != w.changeID {
-			// Abandon the workspace change
-			_, _ = w.jj.Exec(ctx, "abandon", w.changeID)
-		}
+	if _, err := w.jj.Exec(ctx, "workspace", "forget", w.name); err != nil {
+		return fmt.Errorf("failed to forget workspace: %w", err)
 	}
 
-	// Delete the bookmark
-	if err := w.jj.DeleteRef(w.ref); err != nil {
-		// Don't fail cleanup if bookmark deletion fails
-		return nil
+	if err := os.RemoveAll(w.ws.repoRoot); err != nil {
+		return fmt.Errorf("failed to remove workspace directory: %w", err)
 	}
 
 	return nil
@@ -280,16 +259,19 @@ func (w *jjWorkspace) Cleanup() error {
 
 // IsHealthy verifies the workspace is in a good state.
 func (w *jjWorkspace) IsHealthy() error {
+	// Check if the working copy still exists
+	if _, err := os.Stat(filepath.Join(w.ws.repoRoot, ".jj")); err != nil {
+		return fmt.Errorf("workspace directory %s is missing: %w", w.ws.repoRoot, err)
+	}
+
 	// Check if bookmark still exists
 	if !w.jj.RefExists(w.ref) {
 		return fmt.Errorf("workspace bookmark %s no longer exists", w.ref)
 	}
 
-	// Check if change still exists
-	ctx := context.Background()
-	_, err := w.jj.execWithOutput(ctx, "log", "-r", w.changeID, "-n", "1")
-	if err != nil {
-		return fmt.Errorf("workspace change %s no longer exists: %w", w.changeID, err)
+	// Check if jj still knows the workspace
+	if !w.jj.workspaceExists(w.name) {
+		return fmt.Errorf("workspace %s is not registered", w.name)
 	}
 
 	return nil
@@ -17,6 +17,7 @@ func (m *mockVCS) Name() Type                          { return m.name }
 func (m *mockVCS) Version() (string, error)            { return "mock-1.0.0", nil }
 func (m *mockVCS) RepoRoot() (string, error)           { return m.repoRoot, nil }
 func (m *mockVCS) VCSDir() (string, error)             { return m.repoRoot + "/.mock", nil }
+func (m *mockVCS) CommonDir() (string, error)          { return m.repoRoot + "/.mock", nil }
 func (m *mockVCS) IsInVCS() bool                       { return true }
 func (m *mockVCS) CurrentRef() (string, error)         { return "main", nil }
 func (m *mockVCS) RefExists(name string) bool          { return name == "main" }
@@ -40,6 +41,15 @@ func (m *mockVCS) HasDivergence(local, remote string) (DivergenceInfo, error) {
 	return DivergenceInfo{}, nil
 }
 func (m *mockVCS) ExtractFileFromRef(ref, path string) ([]byte, error) { return nil, nil }
+func (m *mockVCS) RemoteRef(remote, name string) string                   { return remote + "/" + name }
+func (m *mockVCS) Upstream(name string) (string, error)                   { return "", nil }
+func (m *mockVCS) MergeBase(a, b string) (string, error)                  { return "", nil }
+func (m *mockVCS) ResetRef(ctx context.Context, name, target string) error { return nil }
+func (m *mockVCS) Restore(ctx context.Context, paths ...string) error     { return nil }
+func (m *mockVCS) RestoreFrom(ctx context.Context, ref string, paths ...string) error { return nil }
+func (m *mockVCS) Log(base, ref string) ([]string, error)                 { return nil, nil }
+func (m *mockVCS) Diff(base, ref string, paths ...string) (string, error) { return "", nil }
+func (m *mockVCS) Merge(ctx context.Context, ref, message string) error   { return nil }
 func (m *mockVCS) CreateWorkspace(opts WorkspaceOptions) (Workspace, error) { return nil, nil }
 func (m *mockVCS) ListWorkspaces() ([]WorkspaceInfo, error) { return nil, nil }
 func (m *mockVCS) HasConflicts() (bool, error)         { return false, nil }
@@ -47,6 +57,7 @@ func (m *mockVCS) GetConflictedFiles() ([]string, error) { return nil, nil }
 func (m *mockVCS) CanUndo() bool                       { return false }
 func (m *mockVCS) Undo(ctx context.Context) error      { return nil }
 func (m *mockVCS) GetOperationLog(limit int) ([]OperationInfo, error) { return nil, nil }
+func (m *mockVCS) ExtractFileAtOperation(opID, path string) ([]byte, error) { return nil, nil }
 func (m *mockVCS) Exec(ctx context.Context, args ...string) ([]byte, error) { return nil, nil }
 
 // newMockVCS creates a mock VCS instance
@@ -32,7 +32,7 @@
 // # Implementations
 //
 //   - internal/vcs/git: Git implementation using worktrees
-//   - internal/vcs/jj: Jujutsu implementation using changes/bookmarks
+//   - internal/vcs/jj: Jujutsu implementation using bookmarks/workspaces
 //
 // See architecture-design.md for full design documentation.
 package vcs
@@ -90,6 +90,12 @@ type VCS interface {
 	// For jj: the .jj directory
 	VCSDir() (string, error)
 
+	// CommonDir returns the metadata directory shared by every worktree or
+	// workspace of the repository.
+	// For git: the common .git directory (git rev-parse --git-common-dir)
+	// For jj: the .jj directory that holds the repo store
+	CommonDir() (string, error)
+
 	// IsInVCS returns true if the current directory is inside a VCS repository
 	IsInVCS() bool
 
@@ -173,7 +179,7 @@ type VCS interface {
 	Fetch(ctx context.Context, remote, ref string) error
 
 	// Pull pulls changes from the remote.
-	// In jj, this is equivalent to fetch (no auto-merge).
+	// In jj, this fetches and rebases the local bookmark onto its remote counterpart.
 	Pull(ctx context.Context, opts PullOptions) error
 
 	// Push pushes changes to the remote.
@@ -191,11 +197,55 @@ type VCS interface {
 	// Used for 3-way merge operations.
 	ExtractFileFromRef(ref, path string) ([]byte, error)
 
+	// ===================
+	// Sync Operations
+	// ===================
+	// Used by bd sync and the sync branch to move between local and
+	// remote state without backend-specific commands.
+
+	// RemoteRef returns the name of the remote-tracking reference for a
+	// remote's copy of name: "origin/main" in git, "main@origin" in jj.
+	RemoteRef(remote, name string) string
+
+	// Upstream returns the remote the named reference tracks,
+	// or empty string if it has no upstream.
+	Upstream(name string) (string, error)
+
+	// MergeBase returns the best common ancestor of two refs,
+	// or empty string if they have none.
+	MergeBase(a, b string) (string, error)
+
+	// ResetRef points the named reference at target, dropping commits that
+	// are only on name. If name is checked out, the working copy is reset to
+	// target too and uncommitted changes are discarded.
+	ResetRef(ctx context.Context, name, target string) error
+
+	// Restore discards uncommitted changes to paths, restoring them from the
+	// last commit (HEAD in git, @- in jj). Paths the last commit doesn't
+	// have are left alone.
+	Restore(ctx context.Context, paths ...string) error
+
+	// RestoreFrom sets paths in the working copy to their content at ref.
+	RestoreFrom(ctx context.Context, ref string, paths ...string) error
+
+	// Log returns one-line summaries ("<short id> <subject>") of the
+	// commits reachable from ref but not from base, newest first.
+	Log(base, ref string) ([]string, error)
+
+	// Diff returns a git-style patch of the changes to paths on ref since
+	// it forked from base (git's base...ref).
+	Diff(base, ref string, paths ...string) (string, error)
+
+	// Merge merges ref into the current branch (bookmark in jj) with a
+	// merge commit, or fast-forwards when the branch has no commits of its
+	// own.
+	Merge(ctx context.Context, ref, message string) error
+
 	// ===================
 	// Workspace Operations
 	// ===================
 	// Workspaces provide isolated operations for sync branch functionality.
-	// In git, this uses worktrees. In jj, this uses changes.
+	// In git, this uses worktrees. In jj, this uses jj workspaces.
 
 	// CreateWorkspace creates an isolated workspace for sync operations.
 	// The workspace allows working on a different branch/bookmark without
@@ -232,6 +282,11 @@ type VCS interface {
 	// Full support in jj, limited in git.
 	GetOperationLog(limit int) ([]OperationInfo, error)
 
+	// ExtractFileAtOperation returns a file's content as of an operation
+	// from GetOperationLog. In jj this is the working copy recorded by the
+	// operation; in git it is the commit the reflog entry points at.
+	ExtractFileAtOperation(opID, path string) ([]byte, error)
+
 	// ===================
 	// Raw Command Execution
 	// ===================
@@ -246,16 +301,15 @@ type VCS interface {
 // In git, a workspace is implemented as a worktree - a separate working
 // directory that shares the same .git directory.
 //
-// In jj, a workspace is implemented using the change model - creating
-// a temporary change for sync operations without affecting the user's
-// working directory.
+// In jj, a workspace is a jj workspace - a separate working copy of the
+// same repo, kept on a bookmark that advances as the workspace commits.
 //
 // The workspace abstraction allows the sync daemon to commit to a
 // separate branch without disturbing the user's current work.
 type Workspace interface {
 	// Path returns the filesystem path to the workspace.
 	// For git: the worktree directory path.
-	// For jj: the repository root (changes work in-place).
+	// For jj: the jj workspace directory.
 	Path() string
 
 	// Ref returns the reference (branch/bookmark) this workspace is based on
@@ -287,7 +341,7 @@ type Workspace interface {
 
 	// Cleanup removes the workspace and cleans up resources.
 	// For git: removes the worktree.
-	// For jj: abandons the sync change if empty.
+	// For jj: forgets the jj workspace and removes its directory.
 	Cleanup() error
 
 	// IsHealthy verifies the workspace is in a good state.
@@ -405,6 +459,10 @@ type PushOptions struct {
 
 	// Force enables force push (use with caution!)
 	Force bool
+
+	// Env adds KEY=value variables to the push's environment, for hooks
+	// (git only; jj doesn't run hooks)
+	Env []string
 }
 
 // DivergenceInfo describes divergence between local and remote refs
@@ -428,8 +486,8 @@ type WorkspaceOptions struct {
 	// Name is the workspace identifier (e.g., "beads-sync")
 	Name string
 
-	// Path is the filesystem path for the workspace (git worktree path)
-	// For jj, this may be ignored as workspaces work in-place.
+	// Path is the filesystem path for the workspace (git worktree or
+	// jj workspace directory). Empty uses a directory under .git or .jj.
 	Path string
 
 	// Ref is the branch/bookmark to base the workspace on.